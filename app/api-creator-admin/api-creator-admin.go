@@ -1,51 +1,372 @@
 package main
 
 import (
-	_apiHandler "github.com/Hajime3778/api-creator-backend/pkg/admin/api/handler"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin"
 	_apiRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/api/repository"
-	_apiUsecase "github.com/Hajime3778/api-creator-backend/pkg/admin/api/usecase"
-	_methodHandler "github.com/Hajime3778/api-creator-backend/pkg/admin/method/handler"
+	_auditRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/audit/repository"
+	_backupUsecase "github.com/Hajime3778/api-creator-backend/pkg/admin/backup/usecase"
+	_bundleUsecase "github.com/Hajime3778/api-creator-backend/pkg/admin/bundle/usecase"
+	_fixtureRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/fixture/repository"
+	_historyRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/history/repository"
 	_methodRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/method/repository"
-	_methodUsecase "github.com/Hajime3778/api-creator-backend/pkg/admin/method/usecase"
-	_modelHandler "github.com/Hajime3778/api-creator-backend/pkg/admin/model/handler"
 	_modelRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/model/repository"
-	_modelUsecase "github.com/Hajime3778/api-creator-backend/pkg/admin/model/usecase"
+	_webhookRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/webhook/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver"
+	_apiserverRepository "github.com/Hajime3778/api-creator-backend/pkg/apiserver/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/definition"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
 	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/config"
 	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/database"
 	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/logger"
+	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/memory"
+	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/migration"
 	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/server"
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/jinzhu/gorm"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "sync":
+			os.Exit(runSync(os.Args[2:]))
+		case "backup":
+			os.Exit(runBackup(os.Args[2:]))
+		case "restore":
+			os.Exit(runRestore(os.Args[2:]))
+		case "migrate":
+			os.Exit(runMigrate(os.Args[2:]))
+		case "--dev", "-dev":
+			os.Exit(runDev(os.Args[2:]))
+		}
+	}
+
 	logger.LoggingSetting("./log/")
 	adminCfg := config.NewConfig("./admin.config.json")
-	db := database.NewDB(adminCfg)
-	apiServerBaseurl := config.NewConfig("./apiserver.config.json").ServerBaseURL()
+	apiserverCfg := config.NewConfig("./apiserver.config.json")
+	apiServerBaseurl := apiserverCfg.ServerBaseURL()
 
 	adminServer := server.NewServer(adminCfg)
 	router := adminServer.Router
 
 	// Group v1
 	apiV1 := router.Group("api/v1")
-	conn := db.NewMysqlConnection()
+	conn := newConnection(adminCfg)
+
+	admin.NewHandlers(apiV1, admin.Repositories{
+		API:     _apiRepository.NewAPIRepository(conn),
+		Method:  _methodRepository.NewMethodRepository(conn),
+		Model:   _modelRepository.NewModelRepository(conn),
+		Fixture: _fixtureRepository.NewFixtureRepository(conn),
+		Webhook: _webhookRepository.NewWebhookRepository(conn),
+		Audit:   _auditRepository.NewAuditRepository(conn),
+		History: _historyRepository.NewHistoryRepository(conn),
+		// ドキュメントの保存先(apiserver.config.jsonのstorage)
+		APIServer: _apiserverRepository.NewAPIServerRepositoryFromConfig(apiserverCfg),
+	}, apiServerBaseurl, adminCfg.BackupDir())
+
+	adminServer.Run()
+}
+
+// newConnection 管理画面のDBに接続します
+// DBのバージョンが異なる(未適用のマイグレーションがある等)場合は起動しません
+func newConnection(c *config.Config) *gorm.DB {
+	conn := database.NewDB(c).NewConnection()
+	if err := migration.Check(conn, migration.Migrations); err != nil {
+		panic(err.Error())
+	}
+	return conn
+}
+
+// runMigrate 管理画面のDBのマイグレーションを適用、取り消し、または適用状況を表示します
+// 例：api-creator-admin migrate down -steps 1
+func runMigrate(args []string) int {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	steps := flags.Int("steps", 1, "downで取り消すマイグレーションの数")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: api-creator-admin migrate up|down|status [-steps <n>]")
+		flags.PrintDefaults()
+	}
+	if len(args) == 0 {
+		flags.Usage()
+		return 2
+	}
+	command := args[0]
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if flags.NArg() != 0 || *steps < 1 {
+		flags.Usage()
+		return 2
+	}
+
+	adminCfg := config.NewConfig("./admin.config.json")
+	conn := database.NewDB(adminCfg).NewConnection()
+	defer conn.Close()
+
+	switch command {
+	case "up":
+		migrations, err := migration.Up(conn, migration.Migrations)
+		for _, m := range migrations {
+			fmt.Printf("applied %d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 1
+		}
+		if len(migrations) == 0 {
+			fmt.Println("database is up to date")
+		}
+	case "down":
+		migrations, err := migration.Down(conn, migration.Migrations, *steps)
+		for _, m := range migrations {
+			fmt.Printf("reverted %d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 1
+		}
+	case "status":
+		statuses, err := migration.GetStatus(conn, migration.Migrations)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 1
+		}
+		for _, s := range statuses {
+			state, appliedAt := "pending", ""
+			if s.Applied {
+				state, appliedAt = "applied", s.AppliedAt.Format(time.RFC3339)
+			}
+			if s.Modified {
+				state = "modified"
+			}
+			if s.Unknown {
+				state = "unknown"
+			}
+			fmt.Printf("%-8s %d_%s %s\n", state, s.Version, s.Name, appliedAt)
+		}
+	default:
+		flags.Usage()
+		return 2
+	}
+	return 0
+}
+
+// runSync ディレクトリのYAMLファイルに定義されたAPIを、DBに同期します
+// 例：api-creator-admin sync -prune ./definitions
+func runSync(args []string) int {
+	flags := flag.NewFlagSet("sync", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "変更内容を表示するのみで、反映しない")
+	prune := flags.Bool("prune", false, "定義ファイルに存在しないAPIを削除する")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: api-creator-admin sync [-dry-run] [-prune] <dir>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	bundles, err := definition.Load(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+
+	adminCfg := config.NewConfig("./admin.config.json")
+	conn := newConnection(adminCfg)
+	apiserverCfg := config.NewConfig("./apiserver.config.json")
+
+	bundleUsecase := _bundleUsecase.NewBundleUsecase(
+		_apiRepository.NewAPIRepository(conn),
+		_methodRepository.NewMethodRepository(conn),
+		_modelRepository.NewModelRepository(conn),
+		_apiserverRepository.NewAPIServerRepositoryFromConfig(apiserverCfg),
+	)
 
-	//repositories
-	apiRepository := _apiRepository.NewAPIRepository(conn, apiServerBaseurl)
-	methodRepository := _methodRepository.NewMethodRepository(conn)
-	modelRepository := _modelRepository.NewModelRepository(conn)
+	results, _, err := bundleUsecase.Sync(bundles, domain.SyncRequest{Prune: *prune, DryRun: *dryRun})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
 
-	// APIs
-	apiUsecase := _apiUsecase.NewAPIUsecase(apiRepository, methodRepository, modelRepository)
-	_apiHandler.NewAPIHandler(apiV1, apiUsecase)
+	definition.PrintPlan(os.Stdout, results)
+	if *dryRun {
+		fmt.Println("Dry run: no changes were applied.")
+	}
+	return 0
+}
+
+// newBackupUsecase 設定ファイルからBackupUsecaseを作成し、バックアップを保存するディレクトリと共に返却します
+// dirが空の場合は設定ファイルのディレクトリにバックアップを保存します
+func newBackupUsecase(dir string) (_backupUsecase.BackupUsecase, string) {
+	adminCfg := config.NewConfig("./admin.config.json")
+	conn := newConnection(adminCfg)
+	apiserverCfg := config.NewConfig("./apiserver.config.json")
+	if dir == "" {
+		dir = adminCfg.BackupDir()
+	}
+
+	return _backupUsecase.NewBackupUsecase(
+		_apiRepository.NewAPIRepository(conn),
+		_methodRepository.NewMethodRepository(conn),
+		_modelRepository.NewModelRepository(conn),
+		_apiserverRepository.NewAPIServerRepositoryFromConfig(apiserverCfg),
+		dir,
+	), dir
+}
+
+// runBackup すべてのAPIの定義とコレクションのバックアップを作成し、ファイルのパスを出力します
+// 例：api-creator-admin backup -label before-release
+func runBackup(args []string) int {
+	flags := flag.NewFlagSet("backup", flag.ContinueOnError)
+	label := flags.String("label", "", "バックアップを識別するためのラベル")
+	dir := flags.String("dir", "", "バックアップを保存するディレクトリ(省略時は設定ファイルのbackup.dir)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: api-creator-admin backup [-label <label>] [-dir <dir>]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+
+	backupUsecase, backupDir := newBackupUsecase(*dir)
+	info, _, err := backupUsecase.Create(domain.BackupRequest{Label: *label})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+	fmt.Println(filepath.Join(backupDir, info.Name))
+	return 0
+}
+
+// runRestore バックアップのファイルからAPIの定義とコレクションを復元します
+// 例：api-creator-admin restore -api /users ./backups/20200101T000000Z-before-release.tar.gz
+func runRestore(args []string) int {
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	apiID := flags.String("api", "", "復元するAPIのIDまたはURL(省略時はすべてのAPI)")
+	prune := flags.Bool("prune", false, "バックアップに存在しないAPIを削除する")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: api-creator-admin restore [-api <id|url>] [-prune] <file>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
 
-	// Methods
-	methodUsecase := _methodUsecase.NewMethodUsecase(apiRepository, methodRepository, modelRepository)
-	_methodHandler.NewMethodHandler(apiV1, methodUsecase)
+	backupUsecase, _ := newBackupUsecase("")
+	result, _, err := backupUsecase.RestoreFile(flags.Arg(0), domain.RestoreRequest{APIID: *apiID, Prune: *prune})
+	for _, api := range result.APIs {
+		fmt.Printf("%-6s %s (%s) %d documents\n", api.Action, api.URL, api.ID, api.Documents)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+	return 0
+}
+
+// runDev 管理画面とAPIサーバーを1つのプロセスで実行します(MySQL、MongoDBは使用しません)
+// データはメモリ上(-dataを指定した場合はファイル)に保持し、空の場合はseedの定義ファイルを読み込みます
+// 例：api-creator-admin --dev -data ./dev.json
+func runDev(args []string) int {
+	flags := flag.NewFlagSet("dev", flag.ContinueOnError)
+	data := flags.String("data", "", "データを保存するファイル(省略時はメモリ上のみで、終了すると破棄する)")
+	seed := flags.String("seed", "./docker/seed", "データが空の場合に読み込む定義ファイルのディレクトリ(空の場合は読み込まない)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: api-creator-admin --dev [-data <file>] [-seed <dir>]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+
+	store, err := memory.NewStore(*data)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+
+	adminCfg := config.NewConfig("./admin.config.json")
+	apiserverCfg := config.NewConfig("./apiserver.config.json")
+	// 同じホストで実行するため、設定ファイルのホスト名(コンテナ名)は使用しない
+	apiServerBaseurl := fmt.Sprintf("http://localhost%s/", apiserverCfg.Server.Port)
+
+	apiRepository := _apiRepository.NewMemoryAPIRepository(store)
+	methodRepository := _methodRepository.NewMemoryMethodRepository(store)
+	modelRepository := _modelRepository.NewMemoryModelRepository(store)
+	webhookRepository := _webhookRepository.NewMemoryWebhookRepository(store)
+	auditRepository := _auditRepository.NewMemoryAuditRepository(store)
+	apiserverRepository := _apiserverRepository.NewMemoryRepository(store)
+
+	if *seed != "" {
+		apis, err := apiRepository.GetAll()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 1
+		}
+		if len(apis) == 0 {
+			bundles, err := definition.Load(*seed)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				return 1
+			}
+			bundleUsecase := _bundleUsecase.NewBundleUsecase(apiRepository, methodRepository, modelRepository, apiserverRepository)
+			results, _, err := bundleUsecase.Sync(bundles, domain.SyncRequest{})
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				return 1
+			}
+			definition.PrintPlan(os.Stdout, results)
+		}
+	}
+
+	adminServer := server.NewServer(adminCfg)
+	admin.NewHandlers(adminServer.Router.Group("api/v1"), admin.Repositories{
+		API:       apiRepository,
+		Method:    methodRepository,
+		Model:     modelRepository,
+		Fixture:   _fixtureRepository.NewMemoryFixtureRepository(store),
+		Webhook:   webhookRepository,
+		Audit:     auditRepository,
+		History:   _historyRepository.NewMemoryHistoryRepository(store),
+		APIServer: apiserverRepository,
+	}, apiServerBaseurl, adminCfg.BackupDir())
 
-	// Models
-	modelUsecase := _modelUsecase.NewModelUsecase(modelRepository)
-	_modelHandler.NewModelHandler(apiV1, modelUsecase)
+	apiServer := server.NewServer(apiserverCfg)
+	apiserver.NewHandlers(context.Background(), apiServer.Router, apiserver.Repositories{
+		API:       apiRepository,
+		Method:    methodRepository,
+		Model:     modelRepository,
+		Webhook:   webhookRepository,
+		Audit:     auditRepository,
+		APIServer: apiserverRepository,
+	}, apiserverCfg)
 
+	fmt.Printf("admin: http://localhost%s/api/v1/\n", adminCfg.Server.Port)
+	fmt.Printf("apiserver: %s\n", apiServerBaseurl)
+	go apiServer.Run()
 	adminServer.Run()
+	return 0
 }
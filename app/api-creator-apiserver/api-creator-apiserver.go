@@ -1,39 +1,42 @@
 package main
 
 import (
+	"context"
+
 	_apiRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/api/repository"
+	_auditRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/audit/repository"
 	_methodRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/method/repository"
 	_modelRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/model/repository"
-	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/handler"
+	_webhookRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/webhook/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver"
 	_apiserverRepository "github.com/Hajime3778/api-creator-backend/pkg/apiserver/repository"
-	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/usecase"
 	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/config"
 	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/database"
+	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/migration"
 	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/server"
-	_ "github.com/go-sql-driver/mysql"
 )
 
 func main() {
-	// API Server側のMongoDB接続
+	// API Server側の設定(ドキュメントの保存先はstorage)
 	apiserverCfg := config.NewConfig("./apiserver.config.json")
-	apiServerBaseurl := apiserverCfg.ServerBaseURL()
-	mongoDB := database.NewDB(apiserverCfg)
 	apiServer := server.NewServer(apiserverCfg)
 
-	// 管理画面で設定されたMysql接続
-	mysqlCfg := config.NewConfig("./admin.config.json")
-	mysqlDB := database.NewDB(mysqlCfg)
-	mysqlConn := mysqlDB.NewMysqlConnection()
-
-	apiRepository := _apiRepository.NewAPIRepository(mysqlConn, apiServerBaseurl)
-	methodRepository := _methodRepository.NewMethodRepository(mysqlConn)
-	modelRepository := _modelRepository.NewModelRepository(mysqlConn)
-	apiserverRepository := _apiserverRepository.NewAPIServerRepository(mongoDB)
-
-	router := apiServer.Router
+	// 管理画面のDBの接続(テーブルはapi-creator-admin migrate upで作成します)
+	// DBのバージョンが異なる場合は起動しません
+	adminCfg := config.NewConfig("./admin.config.json")
+	conn := database.NewDB(adminCfg).NewConnection()
+	if err := migration.Check(conn, migration.Migrations); err != nil {
+		panic(err.Error())
+	}
 
-	apiserverUsecase := usecase.NewAPIServerUsecase(apiRepository, methodRepository, modelRepository, apiserverRepository)
-	handler.NewAPIServerHandler(router, apiserverUsecase)
+	apiserver.NewHandlers(context.Background(), apiServer.Router, apiserver.Repositories{
+		API:       _apiRepository.NewAPIRepository(conn),
+		Method:    _methodRepository.NewMethodRepository(conn),
+		Model:     _modelRepository.NewModelRepository(conn),
+		Webhook:   _webhookRepository.NewWebhookRepository(conn),
+		Audit:     _auditRepository.NewAuditRepository(conn),
+		APIServer: _apiserverRepository.NewAPIServerRepositoryFromConfig(apiserverCfg),
+	}, apiserverCfg)
 
 	apiServer.Run()
 }
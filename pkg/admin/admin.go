@@ -0,0 +1,112 @@
+// Package admin 管理画面のAPIのハンドラを登録します
+package admin
+
+import (
+	_apiHandler "github.com/Hajime3778/api-creator-backend/pkg/admin/api/handler"
+	_apiRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/api/repository"
+	_apiUsecase "github.com/Hajime3778/api-creator-backend/pkg/admin/api/usecase"
+	_auditHandler "github.com/Hajime3778/api-creator-backend/pkg/admin/audit/handler"
+	_auditRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/audit/repository"
+	_auditUsecase "github.com/Hajime3778/api-creator-backend/pkg/admin/audit/usecase"
+	_backupHandler "github.com/Hajime3778/api-creator-backend/pkg/admin/backup/handler"
+	_backupUsecase "github.com/Hajime3778/api-creator-backend/pkg/admin/backup/usecase"
+	_bundleHandler "github.com/Hajime3778/api-creator-backend/pkg/admin/bundle/handler"
+	_bundleUsecase "github.com/Hajime3778/api-creator-backend/pkg/admin/bundle/usecase"
+	_dataHandler "github.com/Hajime3778/api-creator-backend/pkg/admin/data/handler"
+	_dataUsecase "github.com/Hajime3778/api-creator-backend/pkg/admin/data/usecase"
+	_fixtureHandler "github.com/Hajime3778/api-creator-backend/pkg/admin/fixture/handler"
+	_fixtureRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/fixture/repository"
+	_fixtureUsecase "github.com/Hajime3778/api-creator-backend/pkg/admin/fixture/usecase"
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/history"
+	_historyHandler "github.com/Hajime3778/api-creator-backend/pkg/admin/history/handler"
+	_historyRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/history/repository"
+	_historyUsecase "github.com/Hajime3778/api-creator-backend/pkg/admin/history/usecase"
+	_methodHandler "github.com/Hajime3778/api-creator-backend/pkg/admin/method/handler"
+	_methodRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/method/repository"
+	_methodUsecase "github.com/Hajime3778/api-creator-backend/pkg/admin/method/usecase"
+	_modelHandler "github.com/Hajime3778/api-creator-backend/pkg/admin/model/handler"
+	_modelRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/model/repository"
+	_modelUsecase "github.com/Hajime3778/api-creator-backend/pkg/admin/model/usecase"
+	_openapiHandler "github.com/Hajime3778/api-creator-backend/pkg/admin/openapi/handler"
+	_openapiUsecase "github.com/Hajime3778/api-creator-backend/pkg/admin/openapi/usecase"
+	_sdkHandler "github.com/Hajime3778/api-creator-backend/pkg/admin/sdk/handler"
+	_sdkUsecase "github.com/Hajime3778/api-creator-backend/pkg/admin/sdk/usecase"
+	_webhookHandler "github.com/Hajime3778/api-creator-backend/pkg/admin/webhook/handler"
+	_webhookRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/webhook/repository"
+	_webhookUsecase "github.com/Hajime3778/api-creator-backend/pkg/admin/webhook/usecase"
+	_apiserverRepository "github.com/Hajime3778/api-creator-backend/pkg/apiserver/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// Repositories 管理画面で使用するリポジトリ
+type Repositories struct {
+	API     _apiRepository.APIRepository
+	Method  _methodRepository.MethodRepository
+	Model   _modelRepository.ModelRepository
+	Fixture _fixtureRepository.FixtureRepository
+	Webhook _webhookRepository.WebhookRepository
+	Audit   _auditRepository.AuditRepository
+	// History 設定の変更の履歴(nilの場合は記録しません)
+	History   _historyRepository.HistoryRepository
+	APIServer _apiserverRepository.APIServerRepository
+}
+
+// NewHandlers 管理画面のAPIのハンドラをapiV1に登録します
+func NewHandlers(apiV1 *gin.RouterGroup, repos Repositories, apiServerBaseURL string, backupDir string) {
+	// API、Method、Modelの変更を、操作者とともに履歴に記録する
+	var historyRecorder history.Recorder
+	if repos.History != nil {
+		historyRecorder = history.NewRecorder(repos.History)
+	}
+	apiV1.Use(_historyHandler.SetActor)
+
+	// APIs
+	apiUsecase := _apiUsecase.NewAPIUsecase(repos.API, repos.Method, repos.Model, repos.APIServer, historyRecorder)
+	_apiHandler.NewAPIHandler(apiV1, apiUsecase)
+
+	// Methods
+	methodUsecase := _methodUsecase.NewMethodUsecase(repos.API, repos.Method, repos.Model, historyRecorder)
+	_methodHandler.NewMethodHandler(apiV1, methodUsecase)
+
+	// Models
+	modelUsecase := _modelUsecase.NewModelUsecase(repos.Model, repos.APIServer, historyRecorder)
+	_modelHandler.NewModelHandler(apiV1, modelUsecase)
+
+	// History(設定の変更の履歴と、以前のバージョンへの復元)
+	if repos.History != nil {
+		historyUsecase := _historyUsecase.NewHistoryUsecase(repos.History, repos.API, methodUsecase, modelUsecase)
+		_historyHandler.NewHistoryHandler(apiV1, historyUsecase)
+	}
+
+	// Fixtures
+	fixtureUsecase := _fixtureUsecase.NewFixtureUsecase(repos.Fixture, repos.Model, repos.APIServer)
+	_fixtureHandler.NewFixtureHandler(apiV1, fixtureUsecase)
+
+	// Data(コレクションの取り込み、出力)
+	dataUsecase := _dataUsecase.NewDataUsecase(repos.Model, repos.APIServer)
+	_dataHandler.NewDataHandler(apiV1, dataUsecase)
+
+	// Webhooks
+	webhookUsecase := _webhookUsecase.NewWebhookUsecase(repos.Webhook, repos.API)
+	_webhookHandler.NewWebhookHandler(apiV1, webhookUsecase)
+
+	// Audit logs(APIサーバーの書き込みの監査ログ)
+	auditUsecase := _auditUsecase.NewAuditUsecase(repos.Audit)
+	_auditHandler.NewAuditHandler(apiV1, auditUsecase)
+
+	// SDKs
+	sdkUsecase := _sdkUsecase.NewSDKUsecase(repos.API, repos.Method, repos.Model)
+	_sdkHandler.NewSDKHandler(apiV1, sdkUsecase)
+
+	// OpenAPI
+	openapiUsecase := _openapiUsecase.NewOpenAPIUsecase(repos.API, repos.Method, repos.Model, apiServerBaseURL)
+	_openapiHandler.NewOpenAPIHandler(apiV1, openapiUsecase)
+
+	// Bundles(APIのエクスポート、インポート)
+	bundleUsecase := _bundleUsecase.NewBundleUsecase(repos.API, repos.Method, repos.Model, repos.APIServer)
+	_bundleHandler.NewBundleHandler(apiV1, bundleUsecase)
+
+	// Backups
+	backupUsecase := _backupUsecase.NewBackupUsecase(repos.API, repos.Method, repos.Model, repos.APIServer, backupDir)
+	_backupHandler.NewBackupHandler(apiV1, backupUsecase)
+}
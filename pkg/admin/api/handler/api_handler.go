@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"fmt"
 	"log"
 	"net/http"
 
@@ -27,7 +28,9 @@ func NewAPIHandler(r *gin.RouterGroup, u usecase.APIUsecase) {
 		apiRoutes.GET("/:id", handler.GetByID)
 		apiRoutes.POST("", handler.Create)
 		apiRoutes.PUT("", handler.Update)
+		apiRoutes.PUT("/:id/url", handler.ChangeURL)
 		apiRoutes.DELETE("/:id", handler.Delete)
+		apiRoutes.GET("/:id/proto", handler.GetProto)
 	}
 }
 
@@ -72,7 +75,7 @@ func (h *APIHandler) Create(c *gin.Context) {
 	var api domain.API
 	c.BindJSON(&api)
 
-	status, id, err := h.usecase.Create(api)
+	status, id, err := h.usecase.Create(c.Request.Context(), api)
 	if err != nil {
 		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
 		log.Println(err.Error())
@@ -86,7 +89,7 @@ func (h *APIHandler) Update(c *gin.Context) {
 	var api domain.API
 	c.BindJSON(&api)
 
-	status, err := h.usecase.Update(api)
+	status, err := h.usecase.Update(c.Request.Context(), api)
 
 	if err != nil {
 		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
@@ -97,11 +100,28 @@ func (h *APIHandler) Update(c *gin.Context) {
 	c.JSON(http.StatusOK, nil)
 }
 
+// ChangeURL APIのURLを変更します
+func (h *APIHandler) ChangeURL(c *gin.Context) {
+	id := c.Param("id")
+
+	var request domain.ChangeURLRequest
+	c.BindJSON(&request)
+
+	status, err := h.usecase.ChangeURL(c.Request.Context(), id, request)
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, nil)
+}
+
 // Delete APIを削除します
 func (h *APIHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 
-	status, err := h.usecase.Delete(id)
+	status, err := h.usecase.Delete(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
 		log.Println(err.Error())
@@ -110,3 +130,18 @@ func (h *APIHandler) Delete(c *gin.Context) {
 
 	c.JSON(http.StatusNoContent, nil)
 }
+
+// GetProto APIのprotoの定義をファイルとしてダウンロードします
+func (h *APIHandler) GetProto(c *gin.Context) {
+	id := c.Param("id")
+
+	file, status, err := h.usecase.GetProto(id)
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, file.Name))
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", file.Content)
+}
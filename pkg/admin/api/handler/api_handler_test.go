@@ -153,3 +153,24 @@ func TestDelete(t *testing.T) {
 
 	assert.Equal(t, 204, res.Code)
 }
+
+func TestGetProto(t *testing.T) {
+	apiId, _ := uuid.NewRandom()
+
+	gin.SetMode(gin.TestMode)
+
+	mockFile := domain.GeneratedFile{Name: "users.proto", Content: []byte(`syntax = "proto3";`)}
+	mockAPIUsecase := new(mocks.APIUsecase)
+	mockAPIUsecase.On("GetProto", apiId.String()).Return(mockFile, http.StatusOK, nil).Once()
+
+	router, rg := newMockRouter()
+	handler.NewAPIHandler(rg, mockAPIUsecase)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/apis/"+apiId.String()+"/proto", nil)
+	router.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Code)
+	assert.Equal(t, `attachment; filename="users.proto"`, res.Header().Get("Content-Disposition"))
+	assert.Equal(t, `syntax = "proto3";`, res.Body.String())
+}
@@ -1,9 +1,7 @@
 package repository
 
 import (
-	"io/ioutil"
-	"log"
-	"net/http"
+	"strings"
 
 	"github.com/Hajime3778/api-creator-backend/pkg/domain"
 	"github.com/jinzhu/gorm"
@@ -14,21 +12,23 @@ type APIRepository interface {
 	GetAll() ([]domain.API, error)
 	GetByID(id string) (domain.API, error)
 	GetByURL(url string) (domain.API, error)
+	GetAliasByURL(url string) (domain.APIAlias, error)
 	Create(api domain.API) (string, error)
 	Update(api domain.API) error
+	ChangeURL(api domain.API, alias *domain.APIAlias) error
 	Delete(id string, methods []domain.Method, model domain.Model) error
+	Import(plan domain.ImportPlan) error
+	ImportAll(plans []domain.ImportPlan) error
 }
 
 type apiRepository struct {
-	db               *gorm.DB
-	apiServerBaseURL string
+	db *gorm.DB
 }
 
 // NewAPIRepository APIRepositoryインターフェイスを表すオブジェクトを作成します
-func NewAPIRepository(db *gorm.DB, apiServerBaseURL string) APIRepository {
+func NewAPIRepository(db *gorm.DB) APIRepository {
 	return &apiRepository{
-		db:               db,
-		apiServerBaseURL: apiServerBaseURL,
+		db: db,
 	}
 }
 
@@ -48,12 +48,36 @@ func (r *apiRepository) GetByID(id string) (domain.API, error) {
 	return api, err
 }
 
-// GetByURL APIを1件取得します
+// GetByURL リクエストされたURLに合致するAPIを1件取得します
+// リクエストされたURLのパスの区切りごとの前方部分をパラメータで渡して候補を取得し(LIKEの%や_も解釈しない)、
+// 取得後に絞り込みます(照合順序で大文字、小文字を区別しないDBもあるため)
 func (r *apiRepository) GetByURL(url string) (domain.API, error) {
-	api := domain.API{}
-	err := r.db.Raw("SELECT * FROM `apis` WHERE '" + url + "' like CONCAT('%', url, '%')").Scan(&api).Error
+	candidates := candidateURLs(url)
+	if len(candidates) == 0 {
+		return domain.API{}, gorm.ErrRecordNotFound
+	}
 
-	return api, err
+	apis := []domain.API{}
+	if err := r.db.Where("url IN (?)", candidates).Find(&apis).Error; err != nil {
+		return domain.API{}, err
+	}
+
+	return findByURL(apis, url)
+}
+
+// GetAliasByURL リクエストされたURLに合致する別名を1件取得します
+func (r *apiRepository) GetAliasByURL(url string) (domain.APIAlias, error) {
+	candidates := candidateURLs(url)
+	if len(candidates) == 0 {
+		return domain.APIAlias{}, gorm.ErrRecordNotFound
+	}
+
+	aliases := []domain.APIAlias{}
+	if err := r.db.Where("url IN (?)", candidates).Find(&aliases).Error; err != nil {
+		return domain.APIAlias{}, err
+	}
+
+	return findAliasByURL(aliases, url)
 }
 
 // Create APIを作成します
@@ -75,47 +99,173 @@ func (r *apiRepository) Update(api domain.API) error {
 	return r.db.Save(&api).Error
 }
 
+// ChangeURL APIのURLを変更します(aliasが指定された場合、変更前のURLを別名として登録します)
+func (r *apiRepository) ChangeURL(api domain.API, alias *domain.APIAlias) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// 変更後のURLと同じ別名は不要になるため削除する
+		if err := tx.Where("api_id = ? AND url = ?", api.ID, api.URL).Delete(domain.APIAlias{}).Error; err != nil {
+			return err
+		}
+		if alias != nil {
+			if err := tx.Create(alias).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&api).Update("url", api.URL).Error
+	})
+}
+
 // Delete APIを削除します(関連するメソッド、モデルも含めて)
+// モデルのコレクションは削除しません(APIServerRepositoryで削除します)
 func (r *apiRepository) Delete(id string, methods []domain.Method, model domain.Model) error {
 	api := domain.API{}
 	api.ID = id
 
-	r.db.Transaction(func(tx *gorm.DB) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
 		for _, m := range methods {
 			method := domain.Method{ID: m.ID}
 			if err := tx.Delete(&method).Error; err != nil {
 				return err
 			}
 		}
+		if model.ID != "" {
+			if err := tx.Delete(&model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("api_id = ?", id).Delete(domain.APIAlias{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&api).Error
+	})
+}
+
+// Import インポートするAPIとMethod、Modelの作成、更新、削除を1つのトランザクションで反映します
+func (r *apiRepository) Import(plan domain.ImportPlan) error {
+	return r.ImportAll([]domain.ImportPlan{plan})
+}
+
+// ImportAll 複数のAPIのインポートを1つのトランザクションで反映します
+func (r *apiRepository) ImportAll(plans []domain.ImportPlan) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, plan := range plans {
+			if err := importPlan(tx, plan); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// importPlan APIとMethod、Modelの作成、更新、削除を反映します
+func importPlan(tx *gorm.DB, plan domain.ImportPlan) error {
+	api := plan.API
+	if plan.CreateAPI {
+		if err := tx.Create(&api).Error; err != nil {
+			return err
+		}
+	} else if !plan.DeleteAPI {
+		if err := tx.Save(&api).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, m := range plan.DeleteMethods {
+		method := domain.Method{ID: m.ID}
+		if err := tx.Delete(&method).Error; err != nil {
+			return err
+		}
+	}
+	for _, m := range plan.DeleteModels {
+		model := domain.Model{ID: m.ID}
 		if err := tx.Delete(&model).Error; err != nil {
 			return err
 		}
-		if err := tx.Delete(&api).Error; err != nil {
+	}
+	if plan.DeleteAPI {
+		if err := tx.Where("api_id = ?", api.ID).Delete(domain.APIAlias{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.API{ID: api.ID}).Error
+	}
+
+	for _, m := range plan.CreateModels {
+		model := m
+		if err := tx.Create(&model).Error; err != nil {
 			return err
 		}
-		// localで実行する場合 removeCollectionRequest("http://localhost:9000/" + model.Name)
-		if err := removeCollectionRequest(r.apiServerBaseURL + model.Name); err != nil {
+	}
+	for _, m := range plan.UpdateModels {
+		model := m
+		if err := tx.Save(&model).Error; err != nil {
 			return err
 		}
-		return nil
-	})
+	}
+	for _, m := range plan.CreateMethods {
+		method := m
+		if err := tx.Create(&method).Error; err != nil {
+			return err
+		}
+	}
+	for _, m := range plan.UpdateMethods {
+		method := m
+		if err := tx.Save(&method).Error; err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func removeCollectionRequest(url string) error {
-	request, err := http.NewRequest("DELETE", url+"/remove-target-collection", nil)
+// findByURL リクエストされたURLに合致するAPIを返却します
+// 複数のAPIが合致する場合は、URLが最も長いAPIを返却します
+func findByURL(apis []domain.API, url string) (domain.API, error) {
+	found := -1
+	for i, api := range apis {
+		if matchURL(url, api.URL) && (found < 0 || len(api.URL) > len(apis[found].URL)) {
+			found = i
+		}
+	}
+	if found < 0 {
+		return domain.API{}, gorm.ErrRecordNotFound
+	}
+	return apis[found], nil
+}
 
-	client := &http.Client{}
-	response, err := client.Do(request)
-	if err != nil {
-		return err
+// findAliasByURL リクエストされたURLに合致する別名を返却します
+// 複数の別名が合致する場合は、URLが最も長い別名を返却します
+func findAliasByURL(aliases []domain.APIAlias, url string) (domain.APIAlias, error) {
+	found := -1
+	for i, alias := range aliases {
+		if matchURL(url, alias.URL) && (found < 0 || len(alias.URL) > len(aliases[found].URL)) {
+			found = i
+		}
+	}
+	if found < 0 {
+		return domain.APIAlias{}, gorm.ErrRecordNotFound
 	}
+	return aliases[found], nil
+}
 
-	b, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		return err
+// candidateURLs リクエストされたURLに合致しうるAPIのURL(URL自身と、パスの区切りより前の部分)を返却します
+// 例：a/b/c の場合は a/b/c、a/b、a
+func candidateURLs(url string) []string {
+	if url == "" {
+		return nil
 	}
+	candidates := []string{url}
+	for i := len(url) - 1; i > 0; i-- {
+		if url[i] == '/' {
+			candidates = append(candidates, url[:i])
+		}
+	}
+	return candidates
+}
 
-	log.Println(string(b))
-	return nil
+// matchURL リクエストされたURLが、APIのURLと完全一致またはAPIのURL配下のパスであればtrueを返却します
+// URLの途中の一致(例：other/users)や、パスの一部のみの一致(例：users-admin)は合致としません
+func matchURL(url string, apiURL string) bool {
+	if apiURL == "" {
+		return false
+	}
+	return url == apiURL || strings.HasPrefix(url, apiURL+"/")
 }
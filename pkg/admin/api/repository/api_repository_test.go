@@ -1,6 +1,7 @@
 package repository_test
 
 import (
+	"errors"
 	"regexp"
 	"strings"
 	"testing"
@@ -35,7 +36,7 @@ func TestGetAll(t *testing.T) {
 		AddRow(apiId.String(), "name", "url", "description", time.Now(), time.Now())
 	mock.ExpectQuery(query).WillReturnRows(rows)
 
-	apiRepository := repository.NewAPIRepository(db, "")
+	apiRepository := repository.NewAPIRepository(db)
 
 	api, err := apiRepository.GetAll()
 	assert.NoError(t, err)
@@ -51,7 +52,7 @@ func TestGetByID(t *testing.T) {
 		AddRow(apiId.String(), "name", "url", "description", time.Now(), time.Now())
 	mock.ExpectQuery(query).WillReturnRows(rows)
 
-	apiRepository := repository.NewAPIRepository(db, "")
+	apiRepository := repository.NewAPIRepository(db)
 
 	api, err := apiRepository.GetByID(apiId.String())
 	assert.NoError(t, err)
@@ -61,17 +62,87 @@ func TestGetByID(t *testing.T) {
 func TestGetByURL(t *testing.T) {
 	mock, db := setUpMockDB()
 	apiId, _ := uuid.NewRandom()
+	otherId, _ := uuid.NewRandom()
 
-	query := regexp.QuoteMeta("SELECT * FROM `apis` WHERE '" + apiId.String() + "' like CONCAT('%', url, '%')")
+	query := regexp.QuoteMeta("SELECT * FROM `apis` WHERE (url IN (?,?,?,?))")
 	rows := sqlmock.NewRows([]string{"id", "name", "url", "description", "created_at", "updated_at"}).
-		AddRow(apiId.String(), "name", "url", "description", time.Now(), time.Now())
-	mock.ExpectQuery(query).WillReturnRows(rows)
+		AddRow(otherId.String(), "name", "my-project/api/users", "description", time.Now(), time.Now()).
+		AddRow(apiId.String(), "name", "my-project/api/users-admin", "description", time.Now(), time.Now())
+	// パスの区切りごとの前方部分のみを候補として取得する
+	mock.ExpectQuery(query).
+		WithArgs("my-project/api/users-admin/1", "my-project/api/users-admin", "my-project/api", "my-project").
+		WillReturnRows(rows)
 
-	apiRepository := repository.NewAPIRepository(db, "")
+	apiRepository := repository.NewAPIRepository(db)
 
-	api, err := apiRepository.GetByURL(apiId.String())
+	// URLが最も長いAPIを優先する
+	api, err := apiRepository.GetByURL("my-project/api/users-admin/1")
 	assert.NoError(t, err)
-	assert.NotNil(t, api)
+	assert.Equal(t, apiId.String(), api.ID)
+
+	t.Run("合致しない", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "name", "url", "description", "created_at", "updated_at"})
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `apis` WHERE (url IN (?,?,?))")).
+			WithArgs("my-project/api/posts", "my-project/api", "my-project").
+			WillReturnRows(rows)
+
+		_, err := apiRepository.GetByURL("my-project/api/posts")
+		assert.True(t, gorm.IsRecordNotFoundError(err))
+	})
+	t.Run("空のURLは検索しない", func(t *testing.T) {
+		_, err := apiRepository.GetByURL("")
+		assert.True(t, gorm.IsRecordNotFoundError(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetAliasByURL(t *testing.T) {
+	mock, db := setUpMockDB()
+	apiId, _ := uuid.NewRandom()
+	aliasId, _ := uuid.NewRandom()
+
+	rows := sqlmock.NewRows([]string{"id", "api_id", "url", "created_at", "updated_at"}).
+		AddRow(aliasId.String(), apiId.String(), "old/users", time.Now(), time.Now())
+
+	apiRepository := repository.NewAPIRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `api_aliases` WHERE (url IN (?,?,?))")).
+		WithArgs("old/users/1", "old/users", "old").
+		WillReturnRows(rows)
+	alias, err := apiRepository.GetAliasByURL("old/users/1")
+	assert.NoError(t, err)
+	assert.Equal(t, apiId.String(), alias.APIID)
+
+	rows = sqlmock.NewRows([]string{"id", "api_id", "url", "created_at", "updated_at"})
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `api_aliases` WHERE (url IN (?,?))")).
+		WithArgs("old/users-other", "old").
+		WillReturnRows(rows)
+	_, err = apiRepository.GetAliasByURL("old/users-other")
+	assert.True(t, gorm.IsRecordNotFoundError(err))
+}
+
+func TestChangeURL(t *testing.T) {
+	mock, db := setUpMockDB()
+	apiId, _ := uuid.NewRandom()
+	aliasId, _ := uuid.NewRandom()
+
+	mockAPI := domain.API{ID: apiId.String(), URL: "new/users"}
+	alias := &domain.APIAlias{ID: aliasId.String(), APIID: apiId.String(), URL: "old/users"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `api_aliases` WHERE (api_id = ? AND url = ?)")).
+		WithArgs(apiId.String(), "new/users").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `api_aliases` (`id`,`api_id`,`url`,`created_at`,`updated_at`) VALUES (?,?,?,?,?)")).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `apis` SET `updated_at` = ?, `url` = ? WHERE `apis`.`id` = ?")).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	apiRepository := repository.NewAPIRepository(db)
+
+	err := apiRepository.ChangeURL(mockAPI, alias)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestCreate(t *testing.T) {
@@ -86,12 +157,12 @@ func TestCreate(t *testing.T) {
 	mockAPI.UpdatedAt = time.Time{}
 
 	mock.ExpectBegin()
-	query := regexp.QuoteMeta("INSERT INTO `apis` (`id`,`name`,`url`,`description`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?)")
+	query := regexp.QuoteMeta("INSERT INTO `apis` (`id`,`name`,`url`,`description`,`stream_enabled`,`rate_limit`,`rate_burst`,`monthly_quota`,`cors_allowed_origins`,`cors_allowed_methods`,`cors_allowed_headers`,`cors_allow_credentials`,`cors_max_age`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)")
 	mock.ExpectExec(query).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
-	apiRepository := repository.NewAPIRepository(db, "")
+	apiRepository := repository.NewAPIRepository(db)
 
 	_, err := apiRepository.Create(mockAPI)
 	assert.NoError(t, err)
@@ -112,19 +183,18 @@ func TestUpdate(t *testing.T) {
 	mock.ExpectQuery(selectQuery).WillReturnRows(selectRows)
 
 	mock.ExpectBegin()
-	query := regexp.QuoteMeta("UPDATE `apis` SET `name` = ?, `url` = ?, `description` = ?,  `updated_at` = ? WHERE `apis`.`id` = ?")
+	query := regexp.QuoteMeta("UPDATE `apis` SET `name` = ?, `url` = ?, `description` = ?, `stream_enabled` = ?, `rate_limit` = ?, `rate_burst` = ?, `monthly_quota` = ?, `cors_allowed_origins` = ?, `cors_allowed_methods` = ?, `cors_allowed_headers` = ?, `cors_allow_credentials` = ?, `cors_max_age` = ?, `updated_at` = ? WHERE `apis`.`id` = ?")
 	mock.ExpectExec(query).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
-	apiRepository := repository.NewAPIRepository(db, "")
+	apiRepository := repository.NewAPIRepository(db)
 
 	err := apiRepository.Update(mockAPI)
 	assert.NoError(t, err)
 }
 
 func TestDelete(t *testing.T) {
-	mock, db := setUpMockDB()
 	apiId, _ := uuid.NewRandom()
 	methodId, _ := uuid.NewRandom()
 	modelId, _ := uuid.NewRandom()
@@ -133,13 +203,129 @@ func TestDelete(t *testing.T) {
 	methods = append(methods, domain.Method{ID: methodId.String()})
 	model := domain.Model{ID: modelId.String()}
 
+	t.Run("関連するメソッド、モデル、別名も削除する", func(t *testing.T) {
+		mock, db := setUpMockDB()
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `methods` WHERE `methods`.`id` = ?")).WithArgs(methodId.String()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `models` WHERE `models`.`id` = ?")).WithArgs(modelId.String()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `api_aliases` WHERE (api_id = ?)")).WithArgs(apiId.String()).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `apis` WHERE `apis`.`id` = ?")).WithArgs(apiId.String()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repository.NewAPIRepository(db).Delete(apiId.String(), methods, model)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("失敗した場合はエラーを返却する", func(t *testing.T) {
+		mock, db := setUpMockDB()
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `methods`")).WillReturnError(errors.New("delete failed"))
+		mock.ExpectRollback()
+
+		err := repository.NewAPIRepository(db).Delete(apiId.String(), methods, model)
+		assert.Error(t, err)
+	})
+}
+
+func TestImport(t *testing.T) {
+	apiId, _ := uuid.NewRandom()
+	modelId, _ := uuid.NewRandom()
+	methodId, _ := uuid.NewRandom()
+	deletedMethodId, _ := uuid.NewRandom()
+
+	t.Run("作成", func(t *testing.T) {
+		mock, db := setUpMockDB()
+		plan := domain.ImportPlan{
+			API:           domain.API{ID: apiId.String(), Name: "users", URL: "users"},
+			CreateAPI:     true,
+			CreateModels:  []domain.Model{{ID: modelId.String(), APIID: apiId.String()}},
+			CreateMethods: []domain.Method{{ID: methodId.String(), APIID: apiId.String()}},
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `apis`")).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `models`")).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `methods`")).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		apiRepository := repository.NewAPIRepository(db)
+
+		err := apiRepository.Import(plan)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("上書き", func(t *testing.T) {
+		mock, db := setUpMockDB()
+		plan := domain.ImportPlan{
+			API:           domain.API{ID: apiId.String(), Name: "users", URL: "users"},
+			UpdateModels:  []domain.Model{{ID: modelId.String(), APIID: apiId.String()}},
+			UpdateMethods: []domain.Method{{ID: methodId.String(), APIID: apiId.String()}},
+			DeleteMethods: []domain.Method{{ID: deletedMethodId.String(), APIID: apiId.String()}},
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `apis`")).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `methods` WHERE `methods`.`id` = ?")).
+			WithArgs(deletedMethodId.String()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `models`")).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `methods`")).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		apiRepository := repository.NewAPIRepository(db)
+
+		err := apiRepository.Import(plan)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("エラー時はロールバック", func(t *testing.T) {
+		mock, db := setUpMockDB()
+		plan := domain.ImportPlan{
+			API:          domain.API{ID: apiId.String(), Name: "users", URL: "users"},
+			CreateAPI:    true,
+			CreateModels: []domain.Model{{ID: modelId.String(), APIID: apiId.String()}},
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `apis`")).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `models`")).WillReturnError(errors.New("duplicate"))
+		mock.ExpectRollback()
+
+		apiRepository := repository.NewAPIRepository(db)
+
+		err := apiRepository.Import(plan)
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestImportAll(t *testing.T) {
+	mock, db := setUpMockDB()
+	apiId, _ := uuid.NewRandom()
+	deletedApiId, _ := uuid.NewRandom()
+	deletedModelId, _ := uuid.NewRandom()
+
+	plans := []domain.ImportPlan{
+		{API: domain.API{ID: apiId.String(), URL: "users"}, CreateAPI: true},
+		{
+			API:          domain.API{ID: deletedApiId.String(), URL: "items"},
+			DeleteAPI:    true,
+			DeleteModels: []domain.Model{{ID: deletedModelId.String()}},
+		},
+	}
+
 	mock.ExpectBegin()
-	query := regexp.QuoteMeta("DELETE FROM `apis` WHERE `apis`.`id` = ?")
-	mock.ExpectExec(query).WithArgs(apiId.String()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `apis`")).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `models` WHERE `models`.`id` = ?")).
+		WithArgs(deletedModelId.String()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `api_aliases` WHERE (api_id = ?)")).
+		WithArgs(deletedApiId.String()).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `apis` WHERE `apis`.`id` = ?")).
+		WithArgs(deletedApiId.String()).WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectCommit()
 
-	apiRepository := repository.NewAPIRepository(db, "")
+	apiRepository := repository.NewAPIRepository(db)
 
-	err := apiRepository.Delete(apiId.String(), methods, model)
+	err := apiRepository.ImportAll(plans)
 	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/memory"
+	"github.com/jinzhu/gorm"
+)
+
+type memoryAPIRepository struct {
+	store *memory.Store
+}
+
+// NewMemoryAPIRepository メモリ上のStoreを使用するAPIRepositoryを作成します(開発用)
+func NewMemoryAPIRepository(store *memory.Store) APIRepository {
+	return &memoryAPIRepository{
+		store: store,
+	}
+}
+
+// GetAll すべてのAPIを取得します
+func (r *memoryAPIRepository) GetAll() ([]domain.API, error) {
+	apis := []domain.API{}
+	r.store.View(func(t *memory.Tables) {
+		apis = append(apis, t.APIs...)
+	})
+	return apis, nil
+}
+
+// GetByID APIを1件取得します
+func (r *memoryAPIRepository) GetByID(id string) (domain.API, error) {
+	api := domain.API{}
+	err := gorm.ErrRecordNotFound
+	r.store.View(func(t *memory.Tables) {
+		if i := indexOfAPI(t.APIs, id); i >= 0 {
+			api, err = t.APIs[i], nil
+		}
+	})
+	return api, err
+}
+
+// GetByURL リクエストされたURLに合致するAPIを1件取得します
+func (r *memoryAPIRepository) GetByURL(url string) (domain.API, error) {
+	var api domain.API
+	var err error
+	r.store.View(func(t *memory.Tables) {
+		api, err = findByURL(t.APIs, url)
+	})
+	return api, err
+}
+
+// GetAliasByURL リクエストされたURLに合致する別名を1件取得します
+func (r *memoryAPIRepository) GetAliasByURL(url string) (domain.APIAlias, error) {
+	var alias domain.APIAlias
+	var err error
+	r.store.View(func(t *memory.Tables) {
+		alias, err = findAliasByURL(t.APIAliases, url)
+	})
+	return alias, err
+}
+
+// Create APIを作成します
+func (r *memoryAPIRepository) Create(api domain.API) (string, error) {
+	err := r.store.Update(func(t *memory.Tables) error {
+		return createAPI(t, api)
+	})
+	return api.ID, err
+}
+
+// Update APIを更新します
+func (r *memoryAPIRepository) Update(api domain.API) error {
+	return r.store.Update(func(t *memory.Tables) error {
+		i := indexOfAPI(t.APIs, api.ID)
+		if i < 0 {
+			return gorm.ErrRecordNotFound
+		}
+		saveAPI(t, api)
+		return nil
+	})
+}
+
+// ChangeURL APIのURLを変更します(aliasが指定された場合、変更前のURLを別名として登録します)
+func (r *memoryAPIRepository) ChangeURL(api domain.API, alias *domain.APIAlias) error {
+	return r.store.Update(func(t *memory.Tables) error {
+		// 変更後のURLと同じ別名は不要になるため削除する
+		aliases := t.APIAliases[:0]
+		for _, a := range t.APIAliases {
+			if a.APIID != api.ID || a.URL != api.URL {
+				aliases = append(aliases, a)
+			}
+		}
+		t.APIAliases = aliases
+
+		if alias != nil {
+			for _, a := range t.APIAliases {
+				if a.ID == alias.ID {
+					return memory.ErrDuplicateKey
+				}
+			}
+			created := *alias
+			memory.Created(&created.CommonColumn)
+			t.APIAliases = append(t.APIAliases, created)
+		}
+
+		if i := indexOfAPI(t.APIs, api.ID); i >= 0 {
+			t.APIs[i].URL = api.URL
+			memory.Saved(&t.APIs[i].CommonColumn, t.APIs[i].CommonColumn)
+		}
+		return nil
+	})
+}
+
+// Delete APIを削除します(関連するメソッド、モデルも含めて)
+// モデルのコレクションは削除しません(APIServerRepositoryで削除します)
+func (r *memoryAPIRepository) Delete(id string, methods []domain.Method, model domain.Model) error {
+	return r.store.Update(func(t *memory.Tables) error {
+		plan := domain.ImportPlan{
+			API:           domain.API{ID: id},
+			DeleteAPI:     true,
+			DeleteMethods: methods,
+		}
+		if model.ID != "" {
+			plan.DeleteModels = []domain.Model{model}
+		}
+		return importPlanMemory(t, plan)
+	})
+}
+
+// Import インポートするAPIとMethod、Modelの作成、更新、削除を1つのトランザクションで反映します
+func (r *memoryAPIRepository) Import(plan domain.ImportPlan) error {
+	return r.ImportAll([]domain.ImportPlan{plan})
+}
+
+// ImportAll 複数のAPIのインポートを1つのトランザクションで反映します
+func (r *memoryAPIRepository) ImportAll(plans []domain.ImportPlan) error {
+	return r.store.Update(func(t *memory.Tables) error {
+		for _, plan := range plans {
+			if err := importPlanMemory(t, plan); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// importPlanMemory APIとMethod、Modelの作成、更新、削除を反映します(importPlanと同じ順序)
+func importPlanMemory(t *memory.Tables, plan domain.ImportPlan) error {
+	api := plan.API
+	if plan.CreateAPI {
+		if err := createAPI(t, api); err != nil {
+			return err
+		}
+	} else if !plan.DeleteAPI {
+		saveAPI(t, api)
+	}
+
+	for _, m := range plan.DeleteMethods {
+		for i := range t.Methods {
+			if t.Methods[i].ID == m.ID {
+				t.Methods = append(t.Methods[:i], t.Methods[i+1:]...)
+				break
+			}
+		}
+	}
+	for _, m := range plan.DeleteModels {
+		for i := range t.Models {
+			if t.Models[i].ID == m.ID {
+				t.Models = append(t.Models[:i], t.Models[i+1:]...)
+				break
+			}
+		}
+	}
+	if plan.DeleteAPI {
+		aliases := t.APIAliases[:0]
+		for _, a := range t.APIAliases {
+			if a.APIID != api.ID {
+				aliases = append(aliases, a)
+			}
+		}
+		t.APIAliases = aliases
+		if i := indexOfAPI(t.APIs, api.ID); i >= 0 {
+			t.APIs = append(t.APIs[:i], t.APIs[i+1:]...)
+		}
+		return nil
+	}
+
+	for _, m := range plan.CreateModels {
+		for _, current := range t.Models {
+			if current.ID == m.ID {
+				return memory.ErrDuplicateKey
+			}
+		}
+		model := m
+		memory.Created(&model.CommonColumn)
+		t.Models = append(t.Models, model)
+	}
+	for _, m := range plan.UpdateModels {
+		model := m
+		saveModel(t, model)
+	}
+	for _, m := range plan.CreateMethods {
+		for _, current := range t.Methods {
+			if current.ID == m.ID {
+				return memory.ErrDuplicateKey
+			}
+		}
+		method := m
+		memory.Created(&method.CommonColumn)
+		t.Methods = append(t.Methods, method)
+	}
+	for _, m := range plan.UpdateMethods {
+		method := m
+		saveMethod(t, method)
+	}
+	return nil
+}
+
+// createAPI APIを追加します
+func createAPI(t *memory.Tables, api domain.API) error {
+	if indexOfAPI(t.APIs, api.ID) >= 0 {
+		return memory.ErrDuplicateKey
+	}
+	memory.Created(&api.CommonColumn)
+	t.APIs = append(t.APIs, api)
+	return nil
+}
+
+// saveAPI APIを更新します。存在しない場合は追加します(gormのSaveと同じ)
+func saveAPI(t *memory.Tables, api domain.API) {
+	if i := indexOfAPI(t.APIs, api.ID); i >= 0 {
+		memory.Saved(&api.CommonColumn, t.APIs[i].CommonColumn)
+		t.APIs[i] = api
+		return
+	}
+	memory.Created(&api.CommonColumn)
+	t.APIs = append(t.APIs, api)
+}
+
+// saveModel Modelを更新します。存在しない場合は追加します(gormのSaveと同じ)
+func saveModel(t *memory.Tables, model domain.Model) {
+	for i := range t.Models {
+		if t.Models[i].ID == model.ID {
+			memory.Saved(&model.CommonColumn, t.Models[i].CommonColumn)
+			t.Models[i] = model
+			return
+		}
+	}
+	memory.Created(&model.CommonColumn)
+	t.Models = append(t.Models, model)
+}
+
+// saveMethod Methodを更新します。存在しない場合は追加します(gormのSaveと同じ)
+func saveMethod(t *memory.Tables, method domain.Method) {
+	for i := range t.Methods {
+		if t.Methods[i].ID == method.ID {
+			memory.Saved(&method.CommonColumn, t.Methods[i].CommonColumn)
+			t.Methods[i] = method
+			return
+		}
+	}
+	memory.Created(&method.CommonColumn)
+	t.Methods = append(t.Methods, method)
+}
+
+// indexOfAPI IDが一致するAPIの位置を返却します。存在しない場合は-1です
+func indexOfAPI(apis []domain.API, id string) int {
+	for i := range apis {
+		if apis[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,162 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/api/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/memory"
+	"github.com/jinzhu/gorm"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setUpMemoryStore(t *testing.T, apis ...domain.API) *memory.Store {
+	store, err := memory.NewStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Update(func(tables *memory.Tables) error {
+		tables.APIs = append(tables.APIs, apis...)
+		return nil
+	})
+	return store
+}
+
+func TestMemoryGetByURL(t *testing.T) {
+	store := setUpMemoryStore(t,
+		domain.API{ID: "1", URL: "my-project/api/users"},
+		domain.API{ID: "2", URL: "my-project/api/users-admin"},
+	)
+	apiRepository := repository.NewMemoryAPIRepository(store)
+
+	t.Run("URLが長いAPIを優先する", func(t *testing.T) {
+		api, err := apiRepository.GetByURL("my-project/api/users-admin/1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "2", api.ID)
+	})
+	t.Run("合致する", func(t *testing.T) {
+		api, err := apiRepository.GetByURL("my-project/api/users/1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "1", api.ID)
+	})
+	t.Run("合致しない", func(t *testing.T) {
+		_, err := apiRepository.GetByURL("my-project/api/posts")
+
+		assert.True(t, gorm.IsRecordNotFoundError(err))
+	})
+}
+
+func TestMemoryCreateAndUpdate(t *testing.T) {
+	apiRepository := repository.NewMemoryAPIRepository(setUpMemoryStore(t))
+
+	id, err := apiRepository.Create(domain.API{ID: "1", Name: "Users"})
+	assert.NoError(t, err)
+	assert.Equal(t, "1", id)
+
+	_, err = apiRepository.Create(domain.API{ID: "1"})
+	assert.Equal(t, memory.ErrDuplicateKey, err)
+
+	created, _ := apiRepository.GetByID("1")
+	err = apiRepository.Update(domain.API{ID: "1", Name: "Members"})
+	assert.NoError(t, err)
+	updated, _ := apiRepository.GetByID("1")
+	assert.Equal(t, "Members", updated.Name)
+	assert.Equal(t, created.CreatedAt, updated.CreatedAt)
+
+	err = apiRepository.Update(domain.API{ID: "2"})
+	assert.True(t, gorm.IsRecordNotFoundError(err))
+}
+
+func TestMemoryChangeURL(t *testing.T) {
+	store := setUpMemoryStore(t, domain.API{ID: "1", URL: "users"})
+	store.Update(func(tables *memory.Tables) error {
+		tables.APIAliases = append(tables.APIAliases, domain.APIAlias{ID: "a", APIID: "1", URL: "members"})
+		return nil
+	})
+	apiRepository := repository.NewMemoryAPIRepository(store)
+
+	err := apiRepository.ChangeURL(domain.API{ID: "1", URL: "members"}, &domain.APIAlias{ID: "b", APIID: "1", URL: "users"})
+
+	assert.NoError(t, err)
+	api, _ := apiRepository.GetByID("1")
+	assert.Equal(t, "members", api.URL)
+	// 変更後のURLと同じ別名は削除される
+	_, err = apiRepository.GetAliasByURL("members")
+	assert.True(t, gorm.IsRecordNotFoundError(err))
+	alias, err := apiRepository.GetAliasByURL("users/1")
+	assert.NoError(t, err)
+	assert.Equal(t, "b", alias.ID)
+}
+
+func TestMemoryDelete(t *testing.T) {
+	store := setUpMemoryStore(t, domain.API{ID: "1", URL: "users"}, domain.API{ID: "2", URL: "posts"})
+	model := domain.Model{ID: "m1", APIID: "1", CollectionName: "m1"}
+	methods := []domain.Method{{ID: "get", APIID: "1"}}
+	store.Update(func(tables *memory.Tables) error {
+		tables.Models = append(tables.Models, model)
+		tables.Methods = append(tables.Methods, methods...)
+		return nil
+	})
+	store.UpdateCollections(func(c memory.Collections) error {
+		c["m1"] = []bson.M{{"id": "1"}}
+		return nil
+	})
+	apiRepository := repository.NewMemoryAPIRepository(store)
+
+	err := apiRepository.Delete("1", methods, model)
+
+	assert.NoError(t, err)
+	apis, _ := apiRepository.GetAll()
+	assert.Equal(t, []string{"2"}, []string{apis[0].ID})
+	store.View(func(tables *memory.Tables) {
+		assert.Len(t, tables.Models, 0)
+		assert.Len(t, tables.Methods, 0)
+	})
+	// モデルのコレクションは削除しない(APIUsecaseがAPIServerRepositoryで削除する)
+	store.ViewCollections(func(c memory.Collections) {
+		_, ok := c["m1"]
+		assert.True(t, ok)
+	})
+}
+
+func TestMemoryImportAll(t *testing.T) {
+	store := setUpMemoryStore(t, domain.API{ID: "1", URL: "users"})
+	apiRepository := repository.NewMemoryAPIRepository(store)
+
+	t.Run("作成と更新", func(t *testing.T) {
+		err := apiRepository.ImportAll([]domain.ImportPlan{
+			{
+				API:           domain.API{ID: "2", URL: "posts"},
+				CreateAPI:     true,
+				CreateModels:  []domain.Model{{ID: "m2", APIID: "2"}},
+				CreateMethods: []domain.Method{{ID: "get", APIID: "2", ResponseModelID: "m2"}},
+			},
+			{
+				API: domain.API{ID: "1", URL: "users", Name: "Users"},
+			},
+		})
+
+		assert.NoError(t, err)
+		api, _ := apiRepository.GetByID("1")
+		assert.Equal(t, "Users", api.Name)
+		store.View(func(tables *memory.Tables) {
+			assert.Len(t, tables.APIs, 2)
+			assert.Len(t, tables.Models, 1)
+			assert.Len(t, tables.Methods, 1)
+		})
+	})
+	t.Run("エラーの場合はすべて反映しない", func(t *testing.T) {
+		err := apiRepository.ImportAll([]domain.ImportPlan{
+			{API: domain.API{ID: "3", URL: "photos"}, CreateAPI: true},
+			{API: domain.API{ID: "1", URL: "users"}, CreateAPI: true},
+		})
+
+		assert.Equal(t, memory.ErrDuplicateKey, err)
+		_, err = apiRepository.GetByID("3")
+		assert.True(t, gorm.IsRecordNotFoundError(err))
+	})
+}
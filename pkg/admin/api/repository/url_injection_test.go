@@ -0,0 +1,100 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/api/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+// GetByURLのURLは公開されたAPIサーバーのパスをそのまま使用するため、
+// SQLの構文(クォート、コメント)やLIKEのワイルドカード(%、_)として解釈されないことを検証します
+// (以前は "SELECT * FROM apis WHERE '<url>' like CONCAT('%', url, '%')" でURLを連結していました)
+
+// injectionAPIs 登録済みのAPI(URLに_、%、'を含む)
+var injectionAPIs = []domain.API{
+	{ID: "users", URL: "my-project/api/users"},
+	{ID: "items", URL: "my_project/api/items"},
+	{ID: "percent", URL: "my-project/api/100%"},
+	{ID: "notes", URL: "it's/api/notes"},
+}
+
+// injectionCases リクエストされたURLと、合致するAPIのID(空の場合は合致しない)
+var injectionCases = []struct {
+	name string
+	url  string
+	want string
+}{
+	{"常に真となる条件", "' OR '1'='1", ""},
+	{"常に真となる条件(APIのURL配下)", "my-project/api/users/1' OR '1'='1", "users"},
+	{"LIKEの条件を閉じる", "x' OR url LIKE '%", ""},
+	{"行末までのコメント", "my-project/api/users' -- ", ""},
+	{"ブロックコメント", "/**/my-project/api/users", ""},
+	{"複数の文", "my-project/api/users/1; DROP TABLE apis; --", "users"},
+	{"UNION", "' UNION SELECT * FROM api_aliases -- ", ""},
+	{"%のみ", "%", ""},
+	{"%で始まる", "%/api/users/1", ""},
+	{"_のみ", "_", ""},
+	{"_を任意の1文字として解釈しない", "myXproject/api/items/1", ""},
+	{"_を含むURL", "my_project/api/items/1", "items"},
+	{"%を任意の文字列として解釈しない", "my-project/api/100x/1", ""},
+	{"%を含むURL", "my-project/api/100%/1", "percent"},
+	{"%より前のみ一致", "my-project/api/100", ""},
+	{"'を含むURL", "it's/api/notes/1", "notes"},
+	{"'をエスケープしたURL", "it''s/api/notes", ""},
+	{"URLの途中で一致", "other/my-project/api/users", ""},
+	{"パスの一部のみ一致", "my-project/api/users-admin", ""},
+	{"空のURL", "", ""},
+}
+
+// assertInjectionCase 合致するAPIが期待どおりか検証します
+func assertInjectionCase(t *testing.T, api domain.API, err error, want string) {
+	if want == "" {
+		assert.True(t, gorm.IsRecordNotFoundError(err), "expected record not found, got %v (%s)", err, api.ID)
+		return
+	}
+	if assert.NoError(t, err) {
+		assert.Equal(t, want, api.ID)
+	}
+}
+
+func TestGetByURLInjection(t *testing.T) {
+	// リクエストされたURLはSQLに含めず、パラメータで渡す
+	query := "^" + regexp.QuoteMeta("SELECT * FROM `apis` WHERE (url IN (") + `(\?,)*\?` + regexp.QuoteMeta("))") + "$"
+
+	for _, c := range injectionCases {
+		t.Run(c.name, func(t *testing.T) {
+			mock, db := setUpMockDB()
+			rows := sqlmock.NewRows([]string{"id", "name", "url", "description", "created_at", "updated_at"})
+			for _, api := range injectionAPIs {
+				rows.AddRow(api.ID, "name", api.URL, "description", time.Now(), time.Now())
+			}
+			// 空のURLは検索しない
+			if c.url != "" {
+				mock.ExpectQuery(query).WillReturnRows(rows)
+			}
+
+			api, err := repository.NewAPIRepository(db).GetByURL(c.url)
+
+			assertInjectionCase(t, api, err, c.want)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMemoryGetByURLInjection(t *testing.T) {
+	apiRepository := repository.NewMemoryAPIRepository(setUpMemoryStore(t, injectionAPIs...))
+
+	for _, c := range injectionCases {
+		t.Run(c.name, func(t *testing.T) {
+			api, err := apiRepository.GetByURL(c.url)
+
+			assertInjectionCase(t, api, err, c.want)
+		})
+	}
+}
@@ -1,13 +1,20 @@
 package usecase
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"path"
 
 	_apiRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/api/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/history"
 	_methodRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/method/repository"
 	_modelRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/model/repository"
+	_apiserverRepository "github.com/Hajime3778/api-creator-backend/pkg/apiserver/repository"
 	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/rpc"
 	"github.com/Hajime3778/api-creator-backend/pkg/validation"
 	"github.com/google/uuid"
 	"github.com/jinzhu/gorm"
@@ -17,23 +24,31 @@ import (
 type APIUsecase interface {
 	GetAll() ([]domain.API, error)
 	GetByID(id string) (domain.API, error)
-	Create(api domain.API) (int, string, error)
-	Update(api domain.API) (int, error)
-	Delete(id string) (int, error)
+	// 変更するメソッドのctxには操作者(domain.WithActor)を保持し、変更の履歴に記録します
+	Create(ctx context.Context, api domain.API) (int, string, error)
+	Update(ctx context.Context, api domain.API) (int, error)
+	ChangeURL(ctx context.Context, id string, request domain.ChangeURLRequest) (int, error)
+	Delete(ctx context.Context, id string) (int, error)
+	GetProto(id string) (domain.GeneratedFile, int, error)
 }
 
 type apiUsecase struct {
-	apiRepo    _apiRepository.APIRepository
-	methodRepo _methodRepository.MethodRepository
-	modelRepo  _modelRepository.ModelRepository
+	apiRepo       _apiRepository.APIRepository
+	methodRepo    _methodRepository.MethodRepository
+	modelRepo     _modelRepository.ModelRepository
+	apiserverRepo _apiserverRepository.APIServerRepository
+	recorder      history.Recorder
 }
 
 // NewAPIUsecase APIUsecaseインターフェイスを表すオブジェクトを作成します
-func NewAPIUsecase(apiRepo _apiRepository.APIRepository, methodRepo _methodRepository.MethodRepository, modelRepo _modelRepository.ModelRepository) APIUsecase {
+// recorderがnilの場合は、変更の履歴を記録しません
+func NewAPIUsecase(apiRepo _apiRepository.APIRepository, methodRepo _methodRepository.MethodRepository, modelRepo _modelRepository.ModelRepository, apiserverRepo _apiserverRepository.APIServerRepository, recorder history.Recorder) APIUsecase {
 	return &apiUsecase{
-		apiRepo:    apiRepo,
-		methodRepo: methodRepo,
-		modelRepo:  modelRepo,
+		apiRepo:       apiRepo,
+		methodRepo:    methodRepo,
+		modelRepo:     modelRepo,
+		apiserverRepo: apiserverRepo,
+		recorder:      recorder,
 	}
 }
 
@@ -48,7 +63,7 @@ func (u *apiUsecase) GetByID(id string) (domain.API, error) {
 }
 
 // Create APIを作成します
-func (u *apiUsecase) Create(api domain.API) (int, string, error) {
+func (u *apiUsecase) Create(ctx context.Context, api domain.API) (int, string, error) {
 	if api.ID == "" {
 		id, _ := uuid.NewRandom()
 		api.ID = id.String()
@@ -56,15 +71,34 @@ func (u *apiUsecase) Create(api domain.API) (int, string, error) {
 	if !validation.IsHalfWidthOnly(api.URL) {
 		return http.StatusBadRequest, "", errors.New("url is halfwidth only")
 	}
+	if err := validateRateLimit(api); err != nil {
+		return http.StatusBadRequest, "", err
+	}
+	if err := validateCORS(api); err != nil {
+		return http.StatusBadRequest, "", err
+	}
 	id, err := u.apiRepo.Create(api)
 	if err != nil {
 		return http.StatusInternalServerError, "", nil
 	}
+	u.record(ctx, domain.EntityTypeAPI, domain.ChangeActionCreate, api.ID, api.ID, nil, api)
 	return http.StatusCreated, id, nil
 }
 
 // Update APIを更新します
-func (u *apiUsecase) Update(api domain.API) (int, error) {
+func (u *apiUsecase) Update(ctx context.Context, api domain.API) (int, error) {
+	if err := validateRateLimit(api); err != nil {
+		return http.StatusBadRequest, err
+	}
+	if err := validateCORS(api); err != nil {
+		return http.StatusBadRequest, err
+	}
+	var before interface{}
+	if u.recorder != nil {
+		if current, err := u.apiRepo.GetByID(api.ID); err == nil {
+			before = current
+		}
+	}
 	err := u.apiRepo.Update(api)
 	if !validation.IsHalfWidthOnly(api.URL) {
 		return http.StatusBadRequest, errors.New("url is halfwidth only")
@@ -72,12 +106,55 @@ func (u *apiUsecase) Update(api domain.API) (int, error) {
 	if err != nil {
 		return http.StatusInternalServerError, nil
 	}
+	u.record(ctx, domain.EntityTypeAPI, domain.ChangeActionUpdate, api.ID, api.ID, before, api)
+	return http.StatusOK, nil
+}
+
+// ChangeURL APIのURLを変更します
+func (u *apiUsecase) ChangeURL(ctx context.Context, id string, request domain.ChangeURLRequest) (int, error) {
+	if request.URL == "" || !validation.IsHalfWidthOnly(request.URL) {
+		return http.StatusBadRequest, errors.New("url is halfwidth only")
+	}
+
+	api, err := u.apiRepo.GetByID(id)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return http.StatusNotFound, err
+		}
+		return http.StatusInternalServerError, err
+	}
+
+	if api.URL == request.URL {
+		return http.StatusOK, nil
+	}
+	if status, err := u.validateURLConflict(api.ID, request.URL); err != nil {
+		return status, err
+	}
+
+	var alias *domain.APIAlias
+	if request.KeepAlias {
+		aliasID, _ := uuid.NewRandom()
+		alias = &domain.APIAlias{
+			ID:    aliasID.String(),
+			APIID: api.ID,
+			URL:   api.URL,
+		}
+	}
+
+	before := api
+	api.URL = request.URL
+	if err := u.apiRepo.ChangeURL(api, alias); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	u.record(ctx, domain.EntityTypeAPI, domain.ChangeActionUpdate, api.ID, api.ID, before, api)
+
 	return http.StatusOK, nil
 }
 
 // Delete APIを削除します(関連するメソッド、モデルも含めて)
-func (u *apiUsecase) Delete(id string) (int, error) {
-	if _, err := u.apiRepo.GetByID(id); err != nil {
+func (u *apiUsecase) Delete(ctx context.Context, id string) (int, error) {
+	api, err := u.apiRepo.GetByID(id)
+	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return http.StatusNotFound, err
 		}
@@ -103,5 +180,128 @@ func (u *apiUsecase) Delete(id string) (int, error) {
 		return http.StatusInternalServerError, err
 	}
 
+	// 関連するメソッド、モデルも削除したものとして記録する
+	for _, method := range methods {
+		u.record(ctx, domain.EntityTypeMethod, domain.ChangeActionDelete, method.ID, id, method, nil)
+	}
+	if model.ID != "" {
+		u.record(ctx, domain.EntityTypeModel, domain.ChangeActionDelete, model.ID, id, model, nil)
+	}
+	u.record(ctx, domain.EntityTypeAPI, domain.ChangeActionDelete, id, id, api, nil)
+
+	// モデルのコレクションは、APIの削除が確定してから削除する
+	if model.ID != "" {
+		if _, status, err := u.apiserverRepo.RemoveCollection(model.GetCollectionName()); err != nil {
+			return status, err
+		}
+	}
+
 	return http.StatusNoContent, nil
 }
+
+// GetProto APIのModelとMethodから作成した、protoの定義を取得します
+func (u *apiUsecase) GetProto(id string) (domain.GeneratedFile, int, error) {
+	api, err := u.apiRepo.GetByID(id)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return domain.GeneratedFile{}, http.StatusNotFound, err
+		}
+		return domain.GeneratedFile{}, http.StatusInternalServerError, err
+	}
+
+	model, err := u.modelRepo.GetByAPIID(id)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return domain.GeneratedFile{}, http.StatusBadRequest, rpc.ErrModelNotDeclare
+		}
+		return domain.GeneratedFile{}, http.StatusInternalServerError, err
+	}
+
+	methods, err := u.methodRepo.GetListByAPIID(id)
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return domain.GeneratedFile{}, http.StatusInternalServerError, err
+	}
+
+	service, err := rpc.NewService(api, model, methods)
+	if err != nil {
+		return domain.GeneratedFile{}, http.StatusBadRequest, err
+	}
+
+	return domain.GeneratedFile{
+		Name:    path.Base(service.FileName()),
+		Content: []byte(service.Proto()),
+	}, http.StatusOK, nil
+}
+
+// validateURLConflict 変更後のURLへのリクエストが、他のAPIまたは別名に合致しないことを検証します
+// 他のAPIのURL配下のURLに変更すると、そのAPIへのリクエストの一部を奪ってしまうため、変更できません
+func (u *apiUsecase) validateURLConflict(id string, newURL string) (int, error) {
+	other, err := u.apiRepo.GetByURL(newURL)
+	if err == nil && other.ID != id {
+		return http.StatusConflict, fmt.Errorf("url %s conflicts with api %s", newURL, other.URL)
+	}
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return http.StatusInternalServerError, err
+	}
+
+	// 自身の別名は、URLの変更時に削除される
+	alias, err := u.apiRepo.GetAliasByURL(newURL)
+	if err == nil && alias.APIID != id {
+		return http.StatusConflict, fmt.Errorf("url %s conflicts with alias %s", newURL, alias.URL)
+	}
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+// record API(関連するメソッド、モデルを含む)の変更を履歴に記録します(recorderがnilの場合は記録しません)
+func (u *apiUsecase) record(ctx context.Context, entityType string, action string, id string, apiID string, before interface{}, after interface{}) {
+	if u.recorder == nil {
+		return
+	}
+	u.recorder.Record(ctx, history.Change{
+		EntityType: entityType,
+		EntityID:   id,
+		APIID:      apiID,
+		Action:     action,
+		Before:     before,
+		After:      after,
+	})
+}
+
+// validateRateLimit リクエスト数の制限を検証します
+func validateRateLimit(api domain.API) error {
+	if api.RateLimit < 0 || api.RateBurst < 0 || api.MonthlyQuota < 0 {
+		return errors.New("rateLimit, rateBurst and monthlyQuota must not be negative")
+	}
+	return nil
+}
+
+// validateCORS CORSの設定を検証します
+func validateCORS(api domain.API) error {
+	for _, origin := range api.CORSOrigins() {
+		if origin == domain.CORSAllowAll {
+			// すべてのオリジンに資格情報を含むリクエストを許可しない
+			if api.CORSAllowCredentials {
+				return errors.New("corsAllowCredentials cannot be used with all origins")
+			}
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" || u.RawQuery != "" {
+			return fmt.Errorf("corsAllowedOrigins %q must be scheme://host[:port]", origin)
+		}
+	}
+	for _, method := range api.CORSMethods() {
+		switch method {
+		case "GET", "POST", "PUT", "DELETE", "PATCH", "HEAD":
+		default:
+			return fmt.Errorf("corsAllowedMethods %q is not supported", method)
+		}
+	}
+	if api.CORSMaxAge < 0 {
+		return errors.New("corsMaxAge must not be negative")
+	}
+	return nil
+}
@@ -1,16 +1,21 @@
 package usecase_test
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"testing"
 	"time"
 
 	"github.com/Hajime3778/api-creator-backend/pkg/admin/api/usecase"
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/history"
 	"github.com/Hajime3778/api-creator-backend/pkg/domain"
 	"github.com/Hajime3778/api-creator-backend/test/mocks"
 	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestGetAll(t *testing.T) {
@@ -33,7 +38,7 @@ func TestGetAll(t *testing.T) {
 
 	t.Run("test1", func(t *testing.T) {
 		mockAPIRepo.On("GetAll").Return(mockAPIs, nil).Once()
-		usecase := usecase.NewAPIUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo)
+		usecase := usecase.NewAPIUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, new(mocks.APIServerRepository), nil)
 
 		apis, err := usecase.GetAll()
 
@@ -63,7 +68,7 @@ func TestGetByID(t *testing.T) {
 
 	t.Run("test1", func(t *testing.T) {
 		mockAPIRepo.On("GetByID", mockAPI.ID).Return(mockAPI, nil).Once()
-		usecase := usecase.NewAPIUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo)
+		usecase := usecase.NewAPIUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, new(mocks.APIServerRepository), nil)
 
 		api, err := usecase.GetByID(mockAPI.ID)
 
@@ -90,15 +95,43 @@ func TestCreate(t *testing.T) {
 
 	t.Run("test1", func(t *testing.T) {
 		mockAPIRepo.On("Create", mockAPI).Return(nil).Once()
-		usecase := usecase.NewAPIUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo)
+		usecase := usecase.NewAPIUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, new(mocks.APIServerRepository), nil)
 
-		status, _, err := usecase.Create(mockAPI)
+		status, _, err := usecase.Create(context.Background(), mockAPI)
 
 		assert.NoError(t, err)
 		assert.Equal(t, status, http.StatusCreated)
 
 		mockAPIRepo.AssertExpectations(t)
 	})
+	t.Run("リクエスト数の制限が負の値", func(t *testing.T) {
+		invalidAPI := mockAPI
+		invalidAPI.MonthlyQuota = -1
+		usecase := usecase.NewAPIUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, new(mocks.APIServerRepository), nil)
+
+		status, _, err := usecase.Create(context.Background(), invalidAPI)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+		mockAPIRepo.AssertNotCalled(t, "Create", invalidAPI)
+	})
+	t.Run("CORSの設定が不正", func(t *testing.T) {
+		invalidAPIs := []domain.API{
+			{ID: mockAPI.ID, URL: "url", CORSAllowedOrigins: "example.com"},
+			{ID: mockAPI.ID, URL: "url", CORSAllowedOrigins: "https://example.com/path"},
+			{ID: mockAPI.ID, URL: "url", CORSAllowedOrigins: "*", CORSAllowCredentials: true},
+			{ID: mockAPI.ID, URL: "url", CORSAllowedMethods: "GET,CONNECT"},
+			{ID: mockAPI.ID, URL: "url", CORSMaxAge: -1},
+		}
+		usecase := usecase.NewAPIUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, new(mocks.APIServerRepository), nil)
+
+		for _, invalidAPI := range invalidAPIs {
+			status, _, err := usecase.Create(context.Background(), invalidAPI)
+
+			assert.Error(t, err)
+			assert.Equal(t, http.StatusBadRequest, status)
+		}
+	})
 }
 
 func TestUpdate(t *testing.T) {
@@ -117,9 +150,9 @@ func TestUpdate(t *testing.T) {
 
 	t.Run("test1", func(t *testing.T) {
 		mockAPIRepo.On("Update", mockAPI).Return(nil).Once()
-		usecase := usecase.NewAPIUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo)
+		usecase := usecase.NewAPIUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, new(mocks.APIServerRepository), nil)
 
-		status, err := usecase.Update(mockAPI)
+		status, err := usecase.Update(context.Background(), mockAPI)
 
 		assert.NoError(t, err)
 		assert.Equal(t, status, http.StatusOK)
@@ -128,6 +161,89 @@ func TestUpdate(t *testing.T) {
 	})
 }
 
+func TestChangeURL(t *testing.T) {
+	apiId, _ := uuid.NewRandom()
+
+	mockAPI := domain.API{}
+	mockAPI.ID = apiId.String()
+	mockAPI.Name = "name"
+	mockAPI.URL = "url"
+	mockAPI.Description = "test"
+
+	// モック
+	mockAPIRepo := new(mocks.APIRepository)
+	mockMethodRepo := new(mocks.MethodRepository)
+	mockModelRepo := new(mocks.ModelRepository)
+
+	t.Run("別名を残さない", func(t *testing.T) {
+		changedAPI := mockAPI
+		changedAPI.URL = "new-url"
+
+		mockAPIRepo.On("GetByID", apiId.String()).Return(mockAPI, nil).Once()
+		mockAPIRepo.On("GetByURL", "new-url").Return(domain.API{}, gorm.ErrRecordNotFound).Once()
+		mockAPIRepo.On("GetAliasByURL", "new-url").Return(domain.APIAlias{}, gorm.ErrRecordNotFound).Once()
+		mockAPIRepo.On("ChangeURL", changedAPI, (*domain.APIAlias)(nil)).Return(nil).Once()
+		usecase := usecase.NewAPIUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, new(mocks.APIServerRepository), nil)
+
+		status, err := usecase.ChangeURL(context.Background(), apiId.String(), domain.ChangeURLRequest{URL: "new-url"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+
+		mockAPIRepo.AssertExpectations(t)
+	})
+	t.Run("変更前のURLを別名として残す", func(t *testing.T) {
+		changedAPI := mockAPI
+		changedAPI.URL = "new-url"
+
+		mockAPIRepo.On("GetByID", apiId.String()).Return(mockAPI, nil).Once()
+		mockAPIRepo.On("GetByURL", "new-url").Return(domain.API{}, gorm.ErrRecordNotFound).Once()
+		mockAPIRepo.On("GetAliasByURL", "new-url").Return(domain.APIAlias{}, gorm.ErrRecordNotFound).Once()
+		mockAPIRepo.On("ChangeURL", changedAPI, mock.MatchedBy(func(alias *domain.APIAlias) bool {
+			return alias != nil && alias.APIID == mockAPI.ID && alias.URL == mockAPI.URL
+		})).Return(nil).Once()
+		usecase := usecase.NewAPIUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, new(mocks.APIServerRepository), nil)
+
+		status, err := usecase.ChangeURL(context.Background(), apiId.String(), domain.ChangeURLRequest{URL: "new-url", KeepAlias: true})
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+
+		mockAPIRepo.AssertExpectations(t)
+	})
+	t.Run("他のAPIのURL配下のURLには変更できない", func(t *testing.T) {
+		mockAPIRepo.On("GetByID", apiId.String()).Return(mockAPI, nil).Once()
+		mockAPIRepo.On("GetByURL", "users/admin").Return(domain.API{ID: "other", URL: "users"}, nil).Once()
+		usecase := usecase.NewAPIUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, new(mocks.APIServerRepository), nil)
+
+		status, err := usecase.ChangeURL(context.Background(), apiId.String(), domain.ChangeURLRequest{URL: "users/admin"})
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusConflict, status)
+		// 前のテストでの2回のみ
+		mockAPIRepo.AssertNumberOfCalls(t, "ChangeURL", 2)
+	})
+	t.Run("他のAPIの別名には変更できない", func(t *testing.T) {
+		mockAPIRepo.On("GetByID", apiId.String()).Return(mockAPI, nil).Once()
+		mockAPIRepo.On("GetByURL", "old-users").Return(domain.API{}, gorm.ErrRecordNotFound).Once()
+		mockAPIRepo.On("GetAliasByURL", "old-users").Return(domain.APIAlias{APIID: "other", URL: "old-users"}, nil).Once()
+		usecase := usecase.NewAPIUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, new(mocks.APIServerRepository), nil)
+
+		status, err := usecase.ChangeURL(context.Background(), apiId.String(), domain.ChangeURLRequest{URL: "old-users"})
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusConflict, status)
+	})
+	t.Run("URLが半角でない", func(t *testing.T) {
+		usecase := usecase.NewAPIUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, new(mocks.APIServerRepository), nil)
+
+		status, err := usecase.ChangeURL(context.Background(), apiId.String(), domain.ChangeURLRequest{URL: "ユーザー"})
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+}
+
 func TestDelete(t *testing.T) {
 	apiId, _ := uuid.NewRandom()
 
@@ -147,13 +263,105 @@ func TestDelete(t *testing.T) {
 		mockMethodRepo.On("GetListByAPIID", apiId.String()).Return([]domain.Method{}, nil).Once()
 		mockModelRepo.On("GetByAPIID", apiId.String()).Return(domain.Model{}, nil).Once()
 		mockAPIRepo.On("Delete", apiId.String()).Return(nil).Once()
-		usecase := usecase.NewAPIUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo)
+		usecase := usecase.NewAPIUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, new(mocks.APIServerRepository), nil)
 
-		status, err := usecase.Delete(apiId.String())
+		status, err := usecase.Delete(context.Background(), apiId.String())
 
 		assert.NoError(t, err)
 		assert.Equal(t, status, http.StatusNoContent)
 
 		mockAPIRepo.AssertExpectations(t)
 	})
+	t.Run("関連するメソッド、モデルも削除を履歴に記録する", func(t *testing.T) {
+		mockMethod := domain.Method{ID: "method-1", APIID: apiId.String()}
+		mockModel := domain.Model{ID: "model-1", APIID: apiId.String(), CollectionName: "users"}
+		mockAPIRepo.On("GetByID", apiId.String()).Return(mockAPI, nil).Once()
+		mockMethodRepo.On("GetListByAPIID", apiId.String()).Return([]domain.Method{mockMethod}, nil).Once()
+		mockModelRepo.On("GetByAPIID", apiId.String()).Return(mockModel, nil).Once()
+		mockAPIRepo.On("Delete", apiId.String()).Return(nil).Once()
+
+		mockHistoryRepo := new(mocks.HistoryRepository)
+		for _, entity := range []struct{ entityType, id string }{
+			{domain.EntityTypeMethod, mockMethod.ID},
+			{domain.EntityTypeModel, mockModel.ID},
+			{domain.EntityTypeAPI, mockAPI.ID},
+		} {
+			entity := entity
+			mockHistoryRepo.On("Create", mock.MatchedBy(func(e domain.ChangeEvent) bool {
+				return e.EntityType == entity.entityType && e.EntityID == entity.id && e.APIID == mockAPI.ID &&
+					e.Action == domain.ChangeActionDelete && e.Actor == "alice" && e.Before != "" && e.After == ""
+			})).Return(nil).Once()
+		}
+		mockAPIServerRepo := new(mocks.APIServerRepository)
+		mockAPIServerRepo.On("RemoveCollection", "users").Return("", http.StatusNoContent, nil).Once()
+		usecase := usecase.NewAPIUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, mockAPIServerRepo, history.NewRecorder(mockHistoryRepo))
+
+		_, err := usecase.Delete(domain.WithActor(context.Background(), "alice"), apiId.String())
+
+		assert.NoError(t, err)
+		mockHistoryRepo.AssertExpectations(t)
+		mockAPIServerRepo.AssertExpectations(t)
+	})
+	t.Run("コレクションを削除できない場合はエラーを返却する", func(t *testing.T) {
+		mockModel := domain.Model{ID: "model-1", APIID: apiId.String(), CollectionName: "users"}
+		mockAPIRepo.On("GetByID", apiId.String()).Return(mockAPI, nil).Once()
+		mockMethodRepo.On("GetListByAPIID", apiId.String()).Return([]domain.Method{}, nil).Once()
+		mockModelRepo.On("GetByAPIID", apiId.String()).Return(mockModel, nil).Once()
+		mockAPIRepo.On("Delete", apiId.String()).Return(nil).Once()
+		mockAPIServerRepo := new(mocks.APIServerRepository)
+		mockAPIServerRepo.On("RemoveCollection", "users").Return("", http.StatusInternalServerError, errors.New("drop failed")).Once()
+		usecase := usecase.NewAPIUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, mockAPIServerRepo, nil)
+
+		status, err := usecase.Delete(context.Background(), apiId.String())
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusInternalServerError, status)
+	})
+}
+
+func TestGetProto(t *testing.T) {
+	apiId, _ := uuid.NewRandom()
+
+	mockAPI := domain.API{}
+	mockAPI.ID = apiId.String()
+	mockAPI.Name = "name"
+	mockAPI.URL = "users"
+	mockAPI.Description = "test"
+
+	mockModel := domain.Model{
+		ID:     "model",
+		APIID:  mockAPI.ID,
+		Name:   "User",
+		Schema: `{"type": "object", "keys": ["id"], "properties": {"id": {"type": "string"}}}`,
+	}
+	mockMethods := []domain.Method{{ID: "method", APIID: mockAPI.ID, Type: "GET", URL: "/{id}"}}
+
+	// モック
+	mockAPIRepo := new(mocks.APIRepository)
+	mockMethodRepo := new(mocks.MethodRepository)
+	mockModelRepo := new(mocks.ModelRepository)
+
+	t.Run("正常系", func(t *testing.T) {
+		mockAPIRepo.On("GetByID", mockAPI.ID).Return(mockAPI, nil).Once()
+		mockModelRepo.On("GetByAPIID", mockAPI.ID).Return(mockModel, nil).Once()
+		mockMethodRepo.On("GetListByAPIID", mockAPI.ID).Return(mockMethods, nil).Once()
+		usecase := usecase.NewAPIUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, new(mocks.APIServerRepository), nil)
+
+		file, status, err := usecase.GetProto(mockAPI.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "users.proto", file.Name)
+		assert.Contains(t, string(file.Content), "rpc GetUser(GetUserRequest) returns (User);")
+	})
+	t.Run("Modelが未定義", func(t *testing.T) {
+		mockAPIRepo.On("GetByID", mockAPI.ID).Return(mockAPI, nil).Once()
+		mockModelRepo.On("GetByAPIID", mockAPI.ID).Return(domain.Model{}, gorm.ErrRecordNotFound).Once()
+		usecase := usecase.NewAPIUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, new(mocks.APIServerRepository), nil)
+
+		_, status, err := usecase.GetProto(mockAPI.ID)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
 }
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/audit/usecase"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+// AuditHandler 監査ログのAPIに対するリクエストハンドラ
+type AuditHandler struct {
+	usecase usecase.AuditUsecase
+}
+
+// NewAuditHandler AuditHandlerを作成します
+func NewAuditHandler(r *gin.RouterGroup, u usecase.AuditUsecase) {
+	handler := &AuditHandler{
+		usecase: u,
+	}
+	auditRoutes := r.Group("/audit-logs")
+	{
+		auditRoutes.GET("", handler.Search)
+		auditRoutes.GET("/:id", handler.GetByID)
+	}
+	// apiに紐づいた監査ログのルート
+	r.GET("/apis/:id/audit-logs", handler.GetListByAPIID)
+}
+
+// GetByID 監査ログを1件取得します
+func (h *AuditHandler) GetByID(c *gin.Context) {
+	id := c.Param("id")
+
+	result, err := h.usecase.GetByID(id)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			c.JSON(http.StatusNotFound, domain.ErrorResponse{Error: err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Error: err.Error()})
+		}
+		log.Println(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Search 監査ログを検索します
+// クエリ：apiId、key、caller、from、to(RFC3339)、limit、offset
+func (h *AuditHandler) Search(c *gin.Context) {
+	h.search(c, c.Query("apiId"))
+}
+
+// GetListByAPIID APIの監査ログを検索します(クエリはSearchと同じです)
+func (h *AuditHandler) GetListByAPIID(c *gin.Context) {
+	h.search(c, c.Param("id"))
+}
+
+func (h *AuditHandler) search(c *gin.Context, apiID string) {
+	query, err := parseQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: err.Error()})
+		return
+	}
+	query.APIID = apiID
+
+	result, status, err := h.usecase.Search(query)
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// parseQuery クエリから検索条件を作成します
+func parseQuery(c *gin.Context) (domain.AuditQuery, error) {
+	query := domain.AuditQuery{
+		Key:    c.Query("key"),
+		Caller: c.Query("caller"),
+	}
+
+	var err error
+	if query.From, err = parseTime(c.Query("from")); err != nil {
+		return query, fmt.Errorf("from is invalid: %s", err)
+	}
+	if query.To, err = parseTime(c.Query("to")); err != nil {
+		return query, fmt.Errorf("to is invalid: %s", err)
+	}
+	if query.Limit, err = parseInt(c.Query("limit")); err != nil {
+		return query, fmt.Errorf("limit is invalid: %s", err)
+	}
+	if query.Offset, err = parseInt(c.Query("offset")); err != nil {
+		return query, fmt.Errorf("offset is invalid: %s", err)
+	}
+	return query, nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func parseInt(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
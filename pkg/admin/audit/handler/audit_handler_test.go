@@ -0,0 +1,59 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/audit/handler"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func newMockRouter() (*gin.Engine, *gin.RouterGroup) {
+	router := gin.Default()
+	apiV1 := router.Group("api/v1")
+
+	return router, apiV1
+}
+
+func TestGetListByAPIID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	from := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := domain.AuditQuery{APIID: "a1", Key: "1", Caller: "key:k1", From: from, Limit: 10, Offset: 20}
+	mockAuditUsecase := new(mocks.AuditUsecase)
+	mockAuditUsecase.On("Search", query).Return([]domain.AuditLog{{ID: "l1"}}, nil).Once()
+
+	router, rg := newMockRouter()
+	handler.NewAuditHandler(rg, mockAuditUsecase)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/apis/a1/audit-logs?key=1&caller=key:k1&from=2020-01-01T00:00:00Z&limit=10&offset=20", nil)
+	router.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Code)
+	mockAuditUsecase.AssertExpectations(t)
+}
+
+func TestSearchInvalidQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockAuditUsecase := new(mocks.AuditUsecase)
+
+	router, rg := newMockRouter()
+	handler.NewAuditHandler(rg, mockAuditUsecase)
+
+	for _, query := range []string{"from=2020-01-01", "to=yesterday", "limit=ten", "offset=x"} {
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/audit-logs?"+query, nil)
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, 400, res.Code)
+	}
+	assert.Equal(t, 0, len(mockAuditUsecase.Calls))
+}
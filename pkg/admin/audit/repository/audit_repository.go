@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	"github.com/jinzhu/gorm"
+)
+
+// AuditRepository Interface
+type AuditRepository interface {
+	GetByID(id string) (domain.AuditLog, error)
+	Search(query domain.AuditQuery) ([]domain.AuditLog, error)
+	Create(log domain.AuditLog) error
+	DeleteBefore(t time.Time) (int64, error)
+}
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository AuditRepositoryインターフェイスを表すオブジェクトを作成します
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{
+		db: db,
+	}
+}
+
+// GetByID 監査ログを1件取得します
+func (r *auditRepository) GetByID(id string) (domain.AuditLog, error) {
+	log := domain.AuditLog{}
+	err := r.db.Where("id = ?", id).First(&log).Error
+
+	return log, err
+}
+
+// Search 監査ログを条件で新しい順に複数取得します
+func (r *auditRepository) Search(query domain.AuditQuery) ([]domain.AuditLog, error) {
+	logs := []domain.AuditLog{}
+	db := r.db
+	if query.APIID != "" {
+		db = db.Where("api_id = ?", query.APIID)
+	}
+	if query.Key != "" {
+		db = db.Where("document_key = ?", query.Key)
+	}
+	if query.Caller != "" {
+		db = db.Where("caller = ?", query.Caller)
+	}
+	if !query.From.IsZero() {
+		db = db.Where("created_at >= ?", query.From)
+	}
+	if !query.To.IsZero() {
+		db = db.Where("created_at < ?", query.To)
+	}
+	if query.Limit > 0 {
+		db = db.Limit(query.Limit)
+	}
+	if query.Offset > 0 {
+		db = db.Offset(query.Offset)
+	}
+	err := db.Order("created_at desc").Order("id").Find(&logs).Error
+
+	return logs, err
+}
+
+// Create 監査ログを追加します
+func (r *auditRepository) Create(log domain.AuditLog) error {
+	return r.db.Create(&log).Error
+}
+
+// DeleteBefore 記録日時がtより前の監査ログを削除し、削除した件数を返却します
+func (r *auditRepository) DeleteBefore(t time.Time) (int64, error) {
+	result := r.db.Where("created_at < ?", t).Delete(domain.AuditLog{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,45 @@
+package repository_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/audit/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func setUpMockDB() (sqlmock.Sqlmock, *gorm.DB) {
+	gorm.DefaultTableNameHandler = func(db *gorm.DB, defaultTableName string) string {
+		return strings.Replace(defaultTableName, "_data_table", "", 1)
+	}
+	d, mock, _ := sqlmock.New()
+	conn, _ := gorm.Open("mysql", d)
+
+	return mock, conn
+}
+
+func TestSearch(t *testing.T) {
+	mock, db := setUpMockDB()
+	from := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	query := regexp.QuoteMeta("SELECT * FROM `audit_logs` WHERE (api_id = ?) AND (caller = ?) AND (created_at >= ?) ORDER BY created_at desc,`id` LIMIT 10 OFFSET 20")
+	rows := sqlmock.NewRows([]string{"id", "api_id", "document_key", "caller", "status", "created_at"}).
+		AddRow("l1", "a1", "1", "key:k1", 200, from)
+	mock.ExpectQuery(query).WithArgs("a1", "key:k1", from).WillReturnRows(rows)
+
+	auditRepository := repository.NewAuditRepository(db)
+
+	logs, err := auditRepository.Search(domain.AuditQuery{APIID: "a1", Caller: "key:k1", From: from, Limit: 10, Offset: 20})
+	assert.NoError(t, err)
+	if assert.Len(t, logs, 1) {
+		assert.Equal(t, "1", logs[0].Key)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
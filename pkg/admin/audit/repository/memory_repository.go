@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/memory"
+	"github.com/jinzhu/gorm"
+)
+
+type memoryAuditRepository struct {
+	store *memory.Store
+}
+
+// NewMemoryAuditRepository メモリ上のStoreを使用するAuditRepositoryを作成します(開発用)
+func NewMemoryAuditRepository(store *memory.Store) AuditRepository {
+	return &memoryAuditRepository{
+		store: store,
+	}
+}
+
+// GetByID 監査ログを1件取得します
+func (r *memoryAuditRepository) GetByID(id string) (domain.AuditLog, error) {
+	log := domain.AuditLog{}
+	err := gorm.ErrRecordNotFound
+	r.store.View(func(t *memory.Tables) {
+		for _, l := range t.AuditLogs {
+			if l.ID == id {
+				log, err = l, nil
+				return
+			}
+		}
+	})
+	return log, err
+}
+
+// Search 監査ログを条件で新しい順に複数取得します
+func (r *memoryAuditRepository) Search(query domain.AuditQuery) ([]domain.AuditLog, error) {
+	logs := []domain.AuditLog{}
+	r.store.View(func(t *memory.Tables) {
+		for _, l := range t.AuditLogs {
+			if matchAuditQuery(l, query) {
+				logs = append(logs, l)
+			}
+		}
+	})
+	sort.SliceStable(logs, func(i, j int) bool {
+		if !logs[i].CreatedAt.Equal(logs[j].CreatedAt) {
+			return logs[i].CreatedAt.After(logs[j].CreatedAt)
+		}
+		return logs[i].ID < logs[j].ID
+	})
+
+	if query.Offset > 0 {
+		if query.Offset >= len(logs) {
+			return []domain.AuditLog{}, nil
+		}
+		logs = logs[query.Offset:]
+	}
+	if query.Limit > 0 && len(logs) > query.Limit {
+		logs = logs[:query.Limit]
+	}
+	return logs, nil
+}
+
+// Create 監査ログを追加します
+func (r *memoryAuditRepository) Create(log domain.AuditLog) error {
+	return r.store.Update(func(t *memory.Tables) error {
+		memory.Created(&log.CommonColumn)
+		t.AuditLogs = append(t.AuditLogs, log)
+		return nil
+	})
+}
+
+// DeleteBefore 記録日時がtより前の監査ログを削除し、削除した件数を返却します
+func (r *memoryAuditRepository) DeleteBefore(before time.Time) (int64, error) {
+	var deleted int64
+	err := r.store.Update(func(t *memory.Tables) error {
+		logs := []domain.AuditLog{}
+		for _, l := range t.AuditLogs {
+			if l.CreatedAt.Before(before) {
+				deleted++
+				continue
+			}
+			logs = append(logs, l)
+		}
+		t.AuditLogs = logs
+		return nil
+	})
+	return deleted, err
+}
+
+// matchAuditQuery 監査ログが検索条件に合致する場合はtrueを返却します
+func matchAuditQuery(l domain.AuditLog, query domain.AuditQuery) bool {
+	if query.APIID != "" && l.APIID != query.APIID {
+		return false
+	}
+	if query.Key != "" && l.Key != query.Key {
+		return false
+	}
+	if query.Caller != "" && l.Caller != query.Caller {
+		return false
+	}
+	if !query.From.IsZero() && l.CreatedAt.Before(query.From) {
+		return false
+	}
+	if !query.To.IsZero() && !l.CreatedAt.Before(query.To) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/audit/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/memory"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemorySearch(t *testing.T) {
+	store, err := memory.NewStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditRepository := repository.NewMemoryAuditRepository(store)
+	now := time.Now()
+
+	for _, l := range []domain.AuditLog{
+		{ID: "l1", APIID: "a1", Key: "1", Caller: "key:k1", CommonColumn: domain.CommonColumn{CreatedAt: now.Add(-3 * time.Hour)}},
+		{ID: "l2", APIID: "a1", Key: "2", Caller: "key:k2", CommonColumn: domain.CommonColumn{CreatedAt: now.Add(-2 * time.Hour)}},
+		{ID: "l3", APIID: "a1", Key: "1", Caller: "key:k1", CommonColumn: domain.CommonColumn{CreatedAt: now.Add(-time.Hour)}},
+		{ID: "l4", APIID: "a2", Key: "1", Caller: "key:k1", CommonColumn: domain.CommonColumn{CreatedAt: now}},
+	} {
+		assert.NoError(t, auditRepository.Create(l))
+	}
+	ids := func(logs []domain.AuditLog) []string {
+		result := []string{}
+		for _, l := range logs {
+			result = append(result, l.ID)
+		}
+		return result
+	}
+
+	t.Run("条件に合致する監査ログを新しい順に取得する", func(t *testing.T) {
+		logs, err := auditRepository.Search(domain.AuditQuery{APIID: "a1"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"l3", "l2", "l1"}, ids(logs))
+
+		logs, _ = auditRepository.Search(domain.AuditQuery{APIID: "a1", Key: "1"})
+		assert.Equal(t, []string{"l3", "l1"}, ids(logs))
+
+		logs, _ = auditRepository.Search(domain.AuditQuery{Caller: "key:k1"})
+		assert.Equal(t, []string{"l4", "l3", "l1"}, ids(logs))
+	})
+	t.Run("期間はfromを含み、toを含まない", func(t *testing.T) {
+		logs, _ := auditRepository.Search(domain.AuditQuery{From: now.Add(-2 * time.Hour), To: now})
+		assert.Equal(t, []string{"l3", "l2"}, ids(logs))
+	})
+	t.Run("件数と開始位置を指定する", func(t *testing.T) {
+		logs, _ := auditRepository.Search(domain.AuditQuery{Limit: 2, Offset: 1})
+		assert.Equal(t, []string{"l3", "l2"}, ids(logs))
+
+		logs, _ = auditRepository.Search(domain.AuditQuery{Offset: 4})
+		assert.Empty(t, logs)
+	})
+	t.Run("指定した日時より前の監査ログを削除する", func(t *testing.T) {
+		deleted, err := auditRepository.DeleteBefore(now.Add(-90 * time.Minute))
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), deleted)
+		logs, _ := auditRepository.Search(domain.AuditQuery{})
+		assert.Equal(t, []string{"l4", "l3"}, ids(logs))
+	})
+}
@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"errors"
+	"net/http"
+
+	_auditRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/audit/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+)
+
+const (
+	// defaultLimit 件数の指定がない場合に取得する監査ログの件数
+	defaultLimit = 100
+	// maxLimit 1回で取得できる監査ログの件数の上限
+	maxLimit = 1000
+)
+
+// AuditUsecase Interface
+type AuditUsecase interface {
+	GetByID(id string) (domain.AuditLog, error)
+	Search(query domain.AuditQuery) ([]domain.AuditLog, int, error)
+}
+
+type auditUsecase struct {
+	auditRepo _auditRepository.AuditRepository
+}
+
+// NewAuditUsecase AuditUsecaseインターフェイスを表すオブジェクトを作成します
+func NewAuditUsecase(auditRepo _auditRepository.AuditRepository) AuditUsecase {
+	return &auditUsecase{
+		auditRepo: auditRepo,
+	}
+}
+
+// GetByID 監査ログを1件取得します
+func (u *auditUsecase) GetByID(id string) (domain.AuditLog, error) {
+	return u.auditRepo.GetByID(id)
+}
+
+// Search 監査ログを条件で新しい順に取得します。件数の指定がない場合は100件とします
+func (u *auditUsecase) Search(query domain.AuditQuery) ([]domain.AuditLog, int, error) {
+	if query.Limit < 0 || query.Limit > maxLimit || query.Offset < 0 {
+		return nil, http.StatusBadRequest, errors.New("limit must be between 0 and 1000, and offset must not be negative")
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		return nil, http.StatusBadRequest, errors.New("from must be before to")
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultLimit
+	}
+
+	logs, err := u.auditRepo.Search(query)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return logs, http.StatusOK, nil
+}
@@ -0,0 +1,47 @@
+package usecase_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/audit/usecase"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearch(t *testing.T) {
+	mockAuditRepo := new(mocks.AuditRepository)
+
+	t.Run("件数の指定がない場合は100件取得する", func(t *testing.T) {
+		mockAuditRepo.On("Search", domain.AuditQuery{APIID: "a1", Limit: 100}).Return([]domain.AuditLog{{ID: "l1"}}, nil).Once()
+		usecase := usecase.NewAuditUsecase(mockAuditRepo)
+
+		logs, status, err := usecase.Search(domain.AuditQuery{APIID: "a1"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Len(t, logs, 1)
+		mockAuditRepo.AssertExpectations(t)
+	})
+	t.Run("検索条件が不正", func(t *testing.T) {
+		now := time.Now()
+		usecase := usecase.NewAuditUsecase(mockAuditRepo)
+
+		for _, query := range []domain.AuditQuery{
+			{Limit: -1},
+			{Limit: 1001},
+			{Offset: -1},
+			{From: now, To: now},
+			{From: now, To: now.Add(-time.Hour)},
+		} {
+			_, status, err := usecase.Search(query)
+
+			assert.Error(t, err)
+			assert.Equal(t, http.StatusBadRequest, status)
+		}
+		mockAuditRepo.AssertNotCalled(t, "Search", domain.AuditQuery{Limit: 1001})
+	})
+}
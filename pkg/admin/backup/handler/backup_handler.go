@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/backup/usecase"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BackupHandler バックアップに対するリクエストハンドラ
+type BackupHandler struct {
+	usecase usecase.BackupUsecase
+}
+
+// NewBackupHandler BackupHandlerを作成します
+func NewBackupHandler(r *gin.RouterGroup, u usecase.BackupUsecase) {
+	handler := &BackupHandler{
+		usecase: u,
+	}
+	backupRoutes := r.Group("/backups")
+	{
+		backupRoutes.GET("", handler.GetAll)
+		backupRoutes.POST("", handler.Create)
+		backupRoutes.GET("/:name", handler.Get)
+		backupRoutes.PUT("/:name", handler.Upload)
+		backupRoutes.DELETE("/:name", handler.Delete)
+		backupRoutes.GET("/:name/download", handler.Download)
+		backupRoutes.POST("/:name/restore", handler.Restore)
+	}
+}
+
+// GetAll 保存されているバックアップを新しい順に取得します
+func (h *BackupHandler) GetAll(c *gin.Context) {
+	backups, status, err := h.usecase.GetAll()
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+	c.JSON(status, backups)
+}
+
+// Create すべてのAPIの定義とコレクションのバックアップを作成します
+func (h *BackupHandler) Create(c *gin.Context) {
+	var request domain.BackupRequest
+	// ボディは省略できる
+	if c.Request.ContentLength != 0 {
+		if err := c.BindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: err.Error()})
+			log.Println(err.Error())
+			return
+		}
+	}
+
+	info, status, err := h.usecase.Create(request)
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+	c.JSON(status, info)
+}
+
+// Get バックアップの内容を取得します
+func (h *BackupHandler) Get(c *gin.Context) {
+	info, status, err := h.usecase.Get(c.Param("name"))
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+	c.JSON(status, info)
+}
+
+// Upload 他の環境で作成したバックアップを保存します
+func (h *BackupHandler) Upload(c *gin.Context) {
+	info, status, err := h.usecase.Save(c.Param("name"), c.Request.Body)
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+	c.JSON(status, info)
+}
+
+// Delete バックアップを削除します
+func (h *BackupHandler) Delete(c *gin.Context) {
+	status, err := h.usecase.Delete(c.Param("name"))
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+	c.Status(status)
+}
+
+// Download バックアップのファイルをダウンロードします
+func (h *BackupHandler) Download(c *gin.Context) {
+	name := c.Param("name")
+	file, status, err := h.usecase.Open(name)
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+	defer file.Close()
+
+	c.Header("Content-Type", "application/gzip")
+	c.Header("Content-Disposition", `attachment; filename="`+name+`"`)
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, file); err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// Restore バックアップからAPIの定義とコレクションを復元します
+func (h *BackupHandler) Restore(c *gin.Context) {
+	var request domain.RestoreRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.BindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: err.Error()})
+			log.Println(err.Error())
+			return
+		}
+	}
+
+	result, status, err := h.usecase.Restore(c.Param("name"), request)
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+	c.JSON(status, result)
+}
@@ -0,0 +1,106 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/backup/handler"
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/backup/usecase"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func newMockRouter() (*gin.Engine, *gin.RouterGroup) {
+	router := gin.Default()
+	apiV1 := router.Group("api/v1")
+
+	return router, apiV1
+}
+
+func TestCreate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("test1", func(t *testing.T) {
+		mockBackupUsecase := new(mocks.BackupUsecase)
+		mockBackupUsecase.On("Create", domain.BackupRequest{Label: "before-release"}).Return(domain.BackupInfo{Name: "20200101T000000Z-before-release.tar.gz"}, http.StatusCreated, nil).Once()
+
+		router, rg := newMockRouter()
+		handler.NewBackupHandler(rg, mockBackupUsecase)
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/backups", strings.NewReader(`{"label": "before-release"}`))
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusCreated, res.Code)
+		mockBackupUsecase.AssertExpectations(t)
+	})
+	t.Run("ボディを省略", func(t *testing.T) {
+		mockBackupUsecase := new(mocks.BackupUsecase)
+		mockBackupUsecase.On("Create", domain.BackupRequest{}).Return(domain.BackupInfo{}, http.StatusCreated, nil).Once()
+
+		router, rg := newMockRouter()
+		handler.NewBackupHandler(rg, mockBackupUsecase)
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/backups", nil)
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusCreated, res.Code)
+	})
+}
+
+func TestDownload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("test1", func(t *testing.T) {
+		mockBackupUsecase := new(mocks.BackupUsecase)
+		mockBackupUsecase.On("Open", "backup.tar.gz").Return("archive", http.StatusOK, nil).Once()
+
+		router, rg := newMockRouter()
+		handler.NewBackupHandler(rg, mockBackupUsecase)
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/backups/backup.tar.gz/download", nil)
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, `attachment; filename="backup.tar.gz"`, res.Header().Get("Content-Disposition"))
+		assert.Equal(t, "archive", res.Body.String())
+	})
+	t.Run("存在しない", func(t *testing.T) {
+		mockBackupUsecase := new(mocks.BackupUsecase)
+		mockBackupUsecase.On("Open", "unknown.tar.gz").Return("", http.StatusNotFound, usecase.ErrBackupNotFound).Once()
+
+		router, rg := newMockRouter()
+		handler.NewBackupHandler(rg, mockBackupUsecase)
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/backups/unknown.tar.gz/download", nil)
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusNotFound, res.Code)
+	})
+}
+
+func TestRestore(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockBackupUsecase := new(mocks.BackupUsecase)
+	request := domain.RestoreRequest{APIID: "/users"}
+	mockBackupUsecase.On("Restore", "backup.tar.gz", request).Return(domain.RestoreResult{}, http.StatusOK, nil).Once()
+
+	router, rg := newMockRouter()
+	handler.NewBackupHandler(rg, mockBackupUsecase)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/backups/backup.tar.gz/restore", strings.NewReader(`{"apiId": "/users"}`))
+	router.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	mockBackupUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,640 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	_apiRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/api/repository"
+	_methodRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/method/repository"
+	_modelRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/model/repository"
+	_apiserverRepository "github.com/Hajime3778/api-creator-backend/pkg/apiserver/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/archive"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	// insertBatchSize 復元時に1回で追加するドキュメントの件数
+	insertBatchSize = 1000
+	// maxLineSize コレクションのファイルの1行(1ドキュメント)の最大サイズ
+	maxLineSize = 16 * 1024 * 1024
+)
+
+var (
+	// ErrBackupNotFound "backup is not found"
+	ErrBackupNotFound = errors.New("backup is not found")
+	// ErrInvalidBackupName "backup name must be <name>.tar.gz"
+	ErrInvalidBackupName = errors.New("backup name must be <name>.tar.gz")
+
+	// backupNamePattern ディレクトリの外を指定できないよう、英数字と"-"、"_"、"."のみを許可する
+	backupNamePattern = regexp.MustCompile(`^[0-9A-Za-z][0-9A-Za-z_.-]*\.tar\.gz$`)
+)
+
+// BackupUsecase Interface
+type BackupUsecase interface {
+	Create(request domain.BackupRequest) (domain.BackupInfo, int, error)
+	GetAll() ([]domain.BackupInfo, int, error)
+	Get(name string) (domain.BackupInfo, int, error)
+	Open(name string) (io.ReadCloser, int, error)
+	Save(name string, r io.Reader) (domain.BackupInfo, int, error)
+	Delete(name string) (int, error)
+	Restore(name string, request domain.RestoreRequest) (domain.RestoreResult, int, error)
+	RestoreFile(path string, request domain.RestoreRequest) (domain.RestoreResult, int, error)
+}
+
+type backupUsecase struct {
+	apiRepo       _apiRepository.APIRepository
+	methodRepo    _methodRepository.MethodRepository
+	modelRepo     _modelRepository.ModelRepository
+	apiserverRepo _apiserverRepository.APIServerRepository
+	dir           string
+}
+
+// NewBackupUsecase BackupUsecaseインターフェイスを表すオブジェクトを作成します
+// バックアップはdirのディレクトリに保存します
+func NewBackupUsecase(apiRepo _apiRepository.APIRepository, methodRepo _methodRepository.MethodRepository, modelRepo _modelRepository.ModelRepository, apiserverRepo _apiserverRepository.APIServerRepository, dir string) BackupUsecase {
+	return &backupUsecase{
+		apiRepo:       apiRepo,
+		methodRepo:    methodRepo,
+		modelRepo:     modelRepo,
+		apiserverRepo: apiserverRepo,
+		dir:           dir,
+	}
+}
+
+// Create すべてのAPIの定義と、Modelのコレクションのドキュメントをバックアップします
+// 定義を取得してからドキュメントを出力するため、バックアップ中に登録されたドキュメントは含まれない場合があります
+func (u *backupUsecase) Create(request domain.BackupRequest) (domain.BackupInfo, int, error) {
+	apis, err := u.apiRepo.GetAll()
+	if err != nil {
+		return domain.BackupInfo{}, http.StatusInternalServerError, err
+	}
+	models, err := u.modelRepo.GetAll()
+	if err != nil {
+		return domain.BackupInfo{}, http.StatusInternalServerError, err
+	}
+	methods, err := u.methodRepo.GetAll()
+	if err != nil {
+		return domain.BackupInfo{}, http.StatusInternalServerError, err
+	}
+
+	manifest := &domain.BackupManifest{
+		Version:   domain.BackupVersion,
+		Label:     request.Label,
+		CreatedAt: time.Now(),
+		APIs:      []domain.BackupAPI{},
+	}
+	writer, err := archive.NewWriter(manifest)
+	if err != nil {
+		return domain.BackupInfo{}, http.StatusInternalServerError, err
+	}
+	defer writer.Close()
+
+	for _, api := range apis {
+		bundle := domain.Bundle{
+			Version:    domain.BundleVersion,
+			ExportedAt: manifest.CreatedAt,
+			API:        api,
+			Models:     []domain.Model{},
+			Methods:    []domain.Method{},
+		}
+		for _, model := range models {
+			if model.APIID == api.ID {
+				bundle.Models = append(bundle.Models, model)
+			}
+		}
+		for _, method := range methods {
+			if method.APIID == api.ID {
+				bundle.Methods = append(bundle.Methods, method)
+			}
+		}
+
+		backupAPI := domain.BackupAPI{
+			ID:          api.ID,
+			Name:        api.Name,
+			URL:         api.URL,
+			Definition:  definitionPath(api.ID),
+			Collections: []string{},
+		}
+		err := writer.Add(backupAPI.Definition, func(w io.Writer) (int, error) {
+			encoder := json.NewEncoder(w)
+			encoder.SetIndent("", "  ")
+			return 0, encoder.Encode(bundle)
+		})
+		if err != nil {
+			return domain.BackupInfo{}, http.StatusInternalServerError, err
+		}
+
+		for _, model := range bundle.Models {
+			path := collectionPath(model.ID)
+			if err := writer.Add(path, u.dumpCollection(model)); err != nil {
+				return domain.BackupInfo{}, http.StatusInternalServerError, err
+			}
+			backupAPI.Collections = append(backupAPI.Collections, path)
+		}
+		manifest.APIs = append(manifest.APIs, backupAPI)
+	}
+
+	name := archive.FileName(manifest.CreatedAt, request.Label)
+	if err := u.write(name, writer.WriteArchive); err != nil {
+		return domain.BackupInfo{}, http.StatusInternalServerError, err
+	}
+	info, status, err := u.Get(name)
+	if err != nil {
+		return info, status, err
+	}
+	return info, http.StatusCreated, nil
+}
+
+// dumpCollection コレクションのドキュメントを、型を保持するためCanonical Extended JSONで1行ずつ出力します
+func (u *backupUsecase) dumpCollection(model domain.Model) func(w io.Writer) (int, error) {
+	return func(w io.Writer) (int, error) {
+		count := 0
+		_, err := u.apiserverRepo.Each(model.GetCollectionName(), nil, func(document bson.M) error {
+			b, err := bson.MarshalExtJSON(document, true, false)
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(append(b, '\n')); err != nil {
+				return err
+			}
+			count++
+			return nil
+		})
+		return count, err
+	}
+}
+
+// GetAll 保存されているバックアップを新しい順に取得します
+func (u *backupUsecase) GetAll() ([]domain.BackupInfo, int, error) {
+	backups := []domain.BackupInfo{}
+	files, err := ioutil.ReadDir(u.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return backups, http.StatusOK, nil
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	for _, file := range files {
+		if file.IsDir() || !backupNamePattern.MatchString(file.Name()) {
+			continue
+		}
+		info, _, err := u.Get(file.Name())
+		if err != nil {
+			// 読み込めないファイルは一覧に含めない
+			log.Println(file.Name() + ": " + err.Error())
+			continue
+		}
+		backups = append(backups, info)
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].CreatedAt.After(backups[j].CreatedAt) })
+	return backups, http.StatusOK, nil
+}
+
+// Get バックアップのマニフェストを取得します
+func (u *backupUsecase) Get(name string) (domain.BackupInfo, int, error) {
+	file, status, err := u.Open(name)
+	if err != nil {
+		return domain.BackupInfo{}, status, err
+	}
+	defer file.Close()
+
+	manifest, err := archive.ReadManifest(file)
+	if err != nil {
+		return domain.BackupInfo{}, http.StatusInternalServerError, err
+	}
+	stat, err := os.Stat(filepath.Join(u.dir, name))
+	if err != nil {
+		return domain.BackupInfo{}, http.StatusInternalServerError, err
+	}
+	return domain.BackupInfo{
+		Name:      name,
+		Size:      stat.Size(),
+		Label:     manifest.Label,
+		CreatedAt: manifest.CreatedAt,
+		APIs:      manifest.APIs,
+	}, http.StatusOK, nil
+}
+
+// Open バックアップのファイルを開きます
+func (u *backupUsecase) Open(name string) (io.ReadCloser, int, error) {
+	path, err := u.path(name)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, http.StatusNotFound, ErrBackupNotFound
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+	return file, http.StatusOK, nil
+}
+
+// Save 他の環境で作成したバックアップを保存します。チェックサムが一致しない場合は保存しません
+func (u *backupUsecase) Save(name string, r io.Reader) (domain.BackupInfo, int, error) {
+	path, err := u.path(name)
+	if err != nil {
+		return domain.BackupInfo{}, http.StatusBadRequest, err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return domain.BackupInfo{}, http.StatusConflict, fmt.Errorf("backup %s already exists", name)
+	}
+
+	err = u.write(name, func(w io.Writer) error {
+		_, err := io.Copy(w, r)
+		return err
+	})
+	if err != nil {
+		return domain.BackupInfo{}, http.StatusInternalServerError, err
+	}
+
+	file, _, err := u.Open(name)
+	if err != nil {
+		return domain.BackupInfo{}, http.StatusInternalServerError, err
+	}
+	_, err = archive.Verify(file)
+	file.Close()
+	if err != nil {
+		os.Remove(path)
+		return domain.BackupInfo{}, http.StatusBadRequest, err
+	}
+
+	info, status, err := u.Get(name)
+	if err != nil {
+		return info, status, err
+	}
+	return info, http.StatusCreated, nil
+}
+
+// Delete バックアップを削除します
+func (u *backupUsecase) Delete(name string) (int, error) {
+	path, err := u.path(name)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			return http.StatusNotFound, ErrBackupNotFound
+		}
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusNoContent, nil
+}
+
+// Restore 保存されているバックアップから復元します
+func (u *backupUsecase) Restore(name string, request domain.RestoreRequest) (domain.RestoreResult, int, error) {
+	path, err := u.path(name)
+	if err != nil {
+		return domain.RestoreResult{}, http.StatusBadRequest, err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return domain.RestoreResult{}, http.StatusNotFound, ErrBackupNotFound
+	}
+
+	result, status, err := u.RestoreFile(path, request)
+	result.Name = name
+	return result, status, err
+}
+
+// RestoreFile バックアップのファイルから、APIの定義とコレクションのドキュメントを復元します
+// すべてのファイルのチェックサムを検証してから復元を開始します
+// APIの定義は1つのトランザクションで反映し、対象のModelのコレクションはバックアップの内容に置き換えます
+func (u *backupUsecase) RestoreFile(path string, request domain.RestoreRequest) (domain.RestoreResult, int, error) {
+	if request.APIID != "" && request.Prune {
+		return domain.RestoreResult{}, http.StatusBadRequest, errors.New("prune can not be used with apiId")
+	}
+
+	manifest, err := readManifest(path)
+	if err != nil {
+		return domain.RestoreResult{}, http.StatusBadRequest, err
+	}
+	targets, err := restoreTargets(manifest, request.APIID)
+	if err != nil {
+		return domain.RestoreResult{}, http.StatusNotFound, err
+	}
+
+	// 検証と同時に、対象のAPIの定義を読み込む
+	bundles := map[string]domain.Bundle{}
+	err = walk(path, func(file domain.BackupFile, r io.Reader) error {
+		for _, target := range targets {
+			if file.Path == target.Definition {
+				var bundle domain.Bundle
+				if err := json.NewDecoder(r).Decode(&bundle); err != nil {
+					return fmt.Errorf("%s: %s", file.Path, err.Error())
+				}
+				bundles[target.ID] = bundle
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.RestoreResult{}, http.StatusBadRequest, err
+	}
+
+	result := domain.RestoreResult{
+		Name:      filepath.Base(path),
+		Label:     manifest.Label,
+		CreatedAt: manifest.CreatedAt,
+		APIs:      []domain.RestoredAPI{},
+	}
+	plans, status, err := u.planRestore(manifest, targets, bundles, request.Prune, &result)
+	if err != nil {
+		return result, status, err
+	}
+	if err := u.apiRepo.ImportAll(plans); err != nil {
+		return result, http.StatusInternalServerError, err
+	}
+
+	// 定義の反映後に、コレクションのドキュメントを置き換える
+	models := map[string]domain.Model{}
+	documents := map[string]int{}
+	for _, target := range targets {
+		for _, model := range bundles[target.ID].Models {
+			models[collectionPath(model.ID)] = model
+		}
+	}
+	err = walk(path, func(file domain.BackupFile, r io.Reader) error {
+		model, ok := models[file.Path]
+		if !ok {
+			return nil
+		}
+		count, err := u.restoreCollection(model, r)
+		documents[model.APIID] += count
+		return err
+	})
+	for i, api := range result.APIs {
+		result.APIs[i].Documents = documents[api.ID]
+	}
+	if err != nil {
+		return result, http.StatusInternalServerError, err
+	}
+	return result, http.StatusOK, nil
+}
+
+// readManifest バックアップのファイルのマニフェストを読み込みます
+func readManifest(path string) (domain.BackupManifest, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return domain.BackupManifest{}, err
+	}
+	defer file.Close()
+	return archive.ReadManifest(file)
+}
+
+// walk バックアップのファイルを検証しながら、ファイルを1つずつfnに渡します
+func walk(path string, fn func(file domain.BackupFile, r io.Reader) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = archive.Walk(file, fn)
+	return err
+}
+
+// restoreTargets 復元するAPIを取得します
+func restoreTargets(manifest domain.BackupManifest, apiID string) ([]domain.BackupAPI, error) {
+	if apiID == "" {
+		return manifest.APIs, nil
+	}
+	for _, api := range manifest.APIs {
+		if api.ID == apiID || api.URL == apiID {
+			return []domain.BackupAPI{api}, nil
+		}
+	}
+	return nil, fmt.Errorf("api %s is not found in backup", apiID)
+}
+
+// planRestore 現在の定義をバックアップの定義にする変更を作成します
+// pruneの場合はバックアップに含まれないAPIを削除します(削除したAPIのコレクションは残します)
+func (u *backupUsecase) planRestore(manifest domain.BackupManifest, targets []domain.BackupAPI, bundles map[string]domain.Bundle, prune bool, result *domain.RestoreResult) ([]domain.ImportPlan, int, error) {
+	apis, err := u.apiRepo.GetAll()
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	models, err := u.modelRepo.GetAll()
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	methods, err := u.methodRepo.GetAll()
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	inBackup := map[string]bool{}
+	for _, api := range manifest.APIs {
+		inBackup[api.ID] = true
+	}
+
+	plans := []domain.ImportPlan{}
+	deleted := map[string]bool{}
+	if prune {
+		// 削除によってURLが空く場合があるため、削除を先に反映する
+		for _, api := range apis {
+			if inBackup[api.ID] {
+				continue
+			}
+			plan := domain.ImportPlan{API: api, DeleteAPI: true}
+			plan.DeleteModels, _ = modelsOf(models, api.ID, nil)
+			plan.DeleteMethods, _ = methodsOf(methods, api.ID, nil)
+			plans = append(plans, plan)
+			deleted[api.ID] = true
+			result.APIs = append(result.APIs, domain.RestoredAPI{ID: api.ID, URL: api.URL, Action: domain.ImportActionDelete})
+		}
+	}
+
+	existingAPIs := map[string]bool{}
+	for _, api := range apis {
+		existingAPIs[api.ID] = true
+	}
+	existingModels := map[string]bool{}
+	for _, model := range models {
+		existingModels[model.ID] = true
+	}
+	existingMethods := map[string]bool{}
+	for _, method := range methods {
+		existingMethods[method.ID] = true
+	}
+
+	for _, target := range targets {
+		bundle, ok := bundles[target.ID]
+		if !ok {
+			return nil, http.StatusBadRequest, fmt.Errorf("%s is not found in backup", target.Definition)
+		}
+		for _, api := range apis {
+			if api.URL == bundle.API.URL && api.ID != bundle.API.ID && !deleted[api.ID] {
+				return nil, http.StatusConflict, fmt.Errorf("api url %s is used by another api %s", api.URL, api.ID)
+			}
+		}
+
+		plan := domain.ImportPlan{API: bundle.API, CreateAPI: !existingAPIs[bundle.API.ID]}
+		for _, model := range bundle.Models {
+			if existingModels[model.ID] {
+				plan.UpdateModels = append(plan.UpdateModels, model)
+			} else {
+				plan.CreateModels = append(plan.CreateModels, model)
+			}
+		}
+		for _, method := range bundle.Methods {
+			if existingMethods[method.ID] {
+				plan.UpdateMethods = append(plan.UpdateMethods, method)
+			} else {
+				plan.CreateMethods = append(plan.CreateMethods, method)
+			}
+		}
+		// バックアップの後に追加されたModel、Methodは削除する
+		_, plan.DeleteModels = modelsOf(models, bundle.API.ID, bundle.Models)
+		_, plan.DeleteMethods = methodsOf(methods, bundle.API.ID, bundle.Methods)
+		plans = append(plans, plan)
+
+		action := domain.ImportActionUpdate
+		if plan.CreateAPI {
+			action = domain.ImportActionCreate
+		}
+		result.APIs = append(result.APIs, domain.RestoredAPI{ID: bundle.API.ID, URL: bundle.API.URL, Action: action})
+	}
+	return plans, http.StatusOK, nil
+}
+
+// modelsOf APIのModelと、そのうちkeepに含まれないModelを取得します
+func modelsOf(models []domain.Model, apiID string, keep []domain.Model) ([]domain.Model, []domain.Model) {
+	kept := map[string]bool{}
+	for _, model := range keep {
+		kept[model.ID] = true
+	}
+	var all, others []domain.Model
+	for _, model := range models {
+		if model.APIID != apiID {
+			continue
+		}
+		all = append(all, model)
+		if !kept[model.ID] {
+			others = append(others, model)
+		}
+	}
+	return all, others
+}
+
+// methodsOf APIのMethodと、そのうちkeepに含まれないMethodを取得します
+func methodsOf(methods []domain.Method, apiID string, keep []domain.Method) ([]domain.Method, []domain.Method) {
+	kept := map[string]bool{}
+	for _, method := range keep {
+		kept[method.ID] = true
+	}
+	var all, others []domain.Method
+	for _, method := range methods {
+		if method.APIID != apiID {
+			continue
+		}
+		all = append(all, method)
+		if !kept[method.ID] {
+			others = append(others, method)
+		}
+	}
+	return all, others
+}
+
+// restoreCollection コレクションを空にしてから、バックアップのドキュメントを追加します
+func (u *backupUsecase) restoreCollection(model domain.Model, r io.Reader) (int, error) {
+	collectionName := model.GetCollectionName()
+	if _, _, err := u.apiserverRepo.RemoveCollection(collectionName); err != nil {
+		return 0, err
+	}
+	// Keyが定義されていないModelは、Keyを指定せずに追加する
+	keyName := ""
+	if keys, err := model.GetKeyNames(); err == nil {
+		keyName = keys[0]
+	}
+
+	count := 0
+	batch := make([]bson.M, 0, insertBatchSize)
+	flush := func() error {
+		if _, err := u.apiserverRepo.InsertMany(collectionName, keyName, batch); err != nil {
+			return err
+		}
+		count += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var document bson.M
+		if err := bson.UnmarshalExtJSON(line, true, &document); err != nil {
+			return count, err
+		}
+		batch = append(batch, document)
+		if len(batch) == insertBatchSize {
+			if err := flush(); err != nil {
+				return count, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return count, err
+	}
+	if len(batch) > 0 {
+		if err := flush(); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// path バックアップ名からファイルのパスを取得します
+func (u *backupUsecase) path(name string) (string, error) {
+	if !backupNamePattern.MatchString(name) || strings.Contains(name, "..") {
+		return "", ErrInvalidBackupName
+	}
+	return filepath.Join(u.dir, name), nil
+}
+
+// write 一時ファイルに書き込んでから名前を変更し、書き込み途中のファイルが一覧に表示されないようにします
+func (u *backupUsecase) write(name string, write func(w io.Writer) error) error {
+	if err := os.MkdirAll(u.dir, 0755); err != nil {
+		return err
+	}
+	file, err := ioutil.TempFile(u.dir, "."+name+".")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), filepath.Join(u.dir, name))
+}
+
+func definitionPath(apiID string) string {
+	return "definitions/" + apiID + ".json"
+}
+
+func collectionPath(modelID string) string {
+	return "collections/" + modelID + ".ndjson"
+}
@@ -0,0 +1,218 @@
+package usecase_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/backup/usecase"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	mockAPI    = domain.API{ID: "api-1", Name: "users", URL: "/users"}
+	mockModel  = domain.Model{ID: "model-1", APIID: "api-1", Name: "User", Schema: `{"type": "object", "keys": ["id"], "properties": {"id": {"type": "integer"}}}`, CollectionName: "model-1"}
+	mockMethod = domain.Method{ID: "method-1", APIID: "api-1", Type: "GET", ResponseModelID: "model-1"}
+	otherAPI   = domain.API{ID: "api-2", Name: "items", URL: "/items"}
+)
+
+func newTempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "backup-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+// createBackup mockAPIとotherAPIを含むバックアップを作成します
+func createBackup(t *testing.T, dir string, documents []bson.M) domain.BackupInfo {
+	mockAPIRepo := new(mocks.APIRepository)
+	mockMethodRepo := new(mocks.MethodRepository)
+	mockModelRepo := new(mocks.ModelRepository)
+	mockAPIServerRepo := new(mocks.APIServerRepository)
+	mockAPIRepo.On("GetAll").Return([]domain.API{mockAPI, otherAPI}, nil).Once()
+	mockModelRepo.On("GetAll").Return([]domain.Model{mockModel}, nil).Once()
+	mockMethodRepo.On("GetAll").Return([]domain.Method{mockMethod}, nil).Once()
+	mockAPIServerRepo.On("Each", mockModel.CollectionName, map[string]interface{}(nil)).Return(documents, http.StatusOK, nil).Once()
+	u := usecase.NewBackupUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, mockAPIServerRepo, dir)
+
+	info, status, err := u.Create(domain.BackupRequest{Label: "before release"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, status)
+	return info
+}
+
+func TestCreate(t *testing.T) {
+	dir := newTempDir(t)
+	documents := []bson.M{{"id": int32(1), "name": "foo"}, {"id": int32(2), "name": "bar"}}
+
+	info := createBackup(t, dir, documents)
+
+	assert.True(t, strings.HasSuffix(info.Name, "-before_release.tar.gz"))
+	assert.Equal(t, "before release", info.Label)
+	assert.Len(t, info.APIs, 2)
+	assert.Equal(t, []string{"collections/model-1.ndjson"}, info.APIs[0].Collections)
+	assert.Equal(t, []string{}, info.APIs[1].Collections)
+
+	u := usecase.NewBackupUsecase(new(mocks.APIRepository), new(mocks.MethodRepository), new(mocks.ModelRepository), new(mocks.APIServerRepository), dir)
+	backups, status, err := u.GetAll()
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []domain.BackupInfo{info}, backups)
+}
+
+func TestRestore(t *testing.T) {
+	dir := newTempDir(t)
+	objectID := primitive.NewObjectID()
+	documents := []bson.M{{"_id": objectID, "id": int32(1), "price": 1.5}, {"id": int64(2), "tags": bson.A{"a"}}}
+	info := createBackup(t, dir, documents)
+
+	t.Run("すべてのAPI", func(t *testing.T) {
+		mockAPIRepo := new(mocks.APIRepository)
+		mockMethodRepo := new(mocks.MethodRepository)
+		mockModelRepo := new(mocks.ModelRepository)
+		mockAPIServerRepo := new(mocks.APIServerRepository)
+		// api-1は存在し、バックアップの後にModelが追加されている
+		addedModel := domain.Model{ID: "model-2", APIID: "api-1", Name: "Added"}
+		mockAPIRepo.On("GetAll").Return([]domain.API{mockAPI}, nil).Once()
+		mockModelRepo.On("GetAll").Return([]domain.Model{mockModel, addedModel}, nil).Once()
+		mockMethodRepo.On("GetAll").Return([]domain.Method{}, nil).Once()
+		mockAPIRepo.On("ImportAll", []domain.ImportPlan{
+			{API: mockAPI, UpdateModels: []domain.Model{mockModel}, DeleteModels: []domain.Model{addedModel}, CreateMethods: []domain.Method{mockMethod}},
+			{API: otherAPI, CreateAPI: true},
+		}).Return(nil).Once()
+		mockAPIServerRepo.On("RemoveCollection", mockModel.CollectionName).Return(nil, http.StatusOK, nil).Once()
+		mockAPIServerRepo.On("InsertMany", mockModel.CollectionName, "id", documents).Return(http.StatusCreated, nil).Once()
+		u := usecase.NewBackupUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, mockAPIServerRepo, dir)
+
+		result, status, err := u.Restore(info.Name, domain.RestoreRequest{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, []domain.RestoredAPI{
+			{ID: "api-1", URL: "/users", Action: domain.ImportActionUpdate, Documents: 2},
+			{ID: "api-2", URL: "/items", Action: domain.ImportActionCreate},
+		}, result.APIs)
+		mockAPIRepo.AssertExpectations(t)
+		mockAPIServerRepo.AssertExpectations(t)
+	})
+	t.Run("APIを指定", func(t *testing.T) {
+		mockAPIRepo := new(mocks.APIRepository)
+		mockMethodRepo := new(mocks.MethodRepository)
+		mockModelRepo := new(mocks.ModelRepository)
+		mockAPIServerRepo := new(mocks.APIServerRepository)
+		mockAPIRepo.On("GetAll").Return([]domain.API{}, nil).Once()
+		mockModelRepo.On("GetAll").Return([]domain.Model{}, nil).Once()
+		mockMethodRepo.On("GetAll").Return([]domain.Method{}, nil).Once()
+		mockAPIRepo.On("ImportAll", []domain.ImportPlan{{API: otherAPI, CreateAPI: true}}).Return(nil).Once()
+		u := usecase.NewBackupUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, mockAPIServerRepo, dir)
+
+		result, status, err := u.Restore(info.Name, domain.RestoreRequest{APIID: "/items"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Len(t, result.APIs, 1)
+		mockAPIRepo.AssertExpectations(t)
+	})
+	t.Run("prune", func(t *testing.T) {
+		mockAPIRepo := new(mocks.APIRepository)
+		mockMethodRepo := new(mocks.MethodRepository)
+		mockModelRepo := new(mocks.ModelRepository)
+		mockAPIServerRepo := new(mocks.APIServerRepository)
+		// バックアップに含まれないAPIが、復元するAPIのURLを使用している
+		newAPI := domain.API{ID: "api-3", URL: "/users"}
+		mockAPIRepo.On("GetAll").Return([]domain.API{newAPI}, nil).Once()
+		mockModelRepo.On("GetAll").Return([]domain.Model{}, nil).Once()
+		mockMethodRepo.On("GetAll").Return([]domain.Method{}, nil).Once()
+		mockAPIRepo.On("ImportAll", mock.MatchedBy(func(plans []domain.ImportPlan) bool {
+			return len(plans) == 3 && plans[0].DeleteAPI && plans[0].API.ID == "api-3"
+		})).Return(nil).Once()
+		mockAPIServerRepo.On("RemoveCollection", mockModel.CollectionName).Return(nil, http.StatusOK, nil).Once()
+		mockAPIServerRepo.On("InsertMany", mockModel.CollectionName, "id", mock.Anything).Return(http.StatusCreated, nil).Once()
+		u := usecase.NewBackupUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, mockAPIServerRepo, dir)
+
+		result, status, err := u.Restore(info.Name, domain.RestoreRequest{Prune: true})
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, domain.ImportActionDelete, result.APIs[0].Action)
+		mockAPIRepo.AssertExpectations(t)
+	})
+	t.Run("URLが他のAPIで使用されている", func(t *testing.T) {
+		mockAPIRepo := new(mocks.APIRepository)
+		mockMethodRepo := new(mocks.MethodRepository)
+		mockModelRepo := new(mocks.ModelRepository)
+		mockAPIRepo.On("GetAll").Return([]domain.API{{ID: "api-3", URL: "/users"}}, nil).Once()
+		mockModelRepo.On("GetAll").Return([]domain.Model{}, nil).Once()
+		mockMethodRepo.On("GetAll").Return([]domain.Method{}, nil).Once()
+		u := usecase.NewBackupUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, new(mocks.APIServerRepository), dir)
+
+		_, status, err := u.Restore(info.Name, domain.RestoreRequest{})
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusConflict, status)
+		mockAPIRepo.AssertNotCalled(t, "ImportAll", mock.Anything)
+	})
+	t.Run("APIがバックアップに存在しない", func(t *testing.T) {
+		u := usecase.NewBackupUsecase(new(mocks.APIRepository), new(mocks.MethodRepository), new(mocks.ModelRepository), new(mocks.APIServerRepository), dir)
+
+		_, status, err := u.Restore(info.Name, domain.RestoreRequest{APIID: "/unknown"})
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+	t.Run("バックアップが存在しない", func(t *testing.T) {
+		u := usecase.NewBackupUsecase(new(mocks.APIRepository), new(mocks.MethodRepository), new(mocks.ModelRepository), new(mocks.APIServerRepository), dir)
+
+		_, status, err := u.Restore("unknown.tar.gz", domain.RestoreRequest{})
+
+		assert.Equal(t, usecase.ErrBackupNotFound, err)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+}
+
+func TestSave(t *testing.T) {
+	src := newTempDir(t)
+	info := createBackup(t, src, nil)
+	data, err := ioutil.ReadFile(filepath.Join(src, info.Name))
+	assert.NoError(t, err)
+
+	t.Run("test1", func(t *testing.T) {
+		u := usecase.NewBackupUsecase(new(mocks.APIRepository), new(mocks.MethodRepository), new(mocks.ModelRepository), new(mocks.APIServerRepository), newTempDir(t))
+
+		saved, status, err := u.Save("uploaded.tar.gz", strings.NewReader(string(data)))
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, status)
+		assert.Equal(t, info.Label, saved.Label)
+	})
+	t.Run("改ざんされている", func(t *testing.T) {
+		dir := newTempDir(t)
+		u := usecase.NewBackupUsecase(new(mocks.APIRepository), new(mocks.MethodRepository), new(mocks.ModelRepository), new(mocks.APIServerRepository), dir)
+
+		_, status, err := u.Save("uploaded.tar.gz", strings.NewReader(string(data[:len(data)/2])))
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+		files, _ := ioutil.ReadDir(dir)
+		assert.Len(t, files, 0)
+	})
+	t.Run("不正な名前", func(t *testing.T) {
+		u := usecase.NewBackupUsecase(new(mocks.APIRepository), new(mocks.MethodRepository), new(mocks.ModelRepository), new(mocks.APIServerRepository), newTempDir(t))
+
+		_, status, err := u.Save("../uploaded.tar.gz", strings.NewReader(string(data)))
+
+		assert.Equal(t, usecase.ErrInvalidBackupName, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+}
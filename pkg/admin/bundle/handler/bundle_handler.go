@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/bundle/usecase"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/yamljson"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	formatJSON = "json"
+	formatYAML = "yaml"
+)
+
+// BundleHandler BundleAPIに対するリクエストハンドラ
+type BundleHandler struct {
+	usecase usecase.BundleUsecase
+}
+
+// NewBundleHandler BundleHandlerを作成します
+func NewBundleHandler(r *gin.RouterGroup, u usecase.BundleUsecase) {
+	handler := &BundleHandler{
+		usecase: u,
+	}
+	r.GET("/apis/:id/export", handler.Export)
+	// "/apis/:id/..."のルートと競合するため、"/apis/import"は":id"が"import"の場合として登録する
+	r.POST("/apis/:id", handler.Import)
+}
+
+// Export APIの定義をBundleとしてダウンロードします
+// クエリストリングの"format"で"json"(デフォルト)か"yaml"、"documents=true"でドキュメントを含めるか指定できます
+func (h *BundleHandler) Export(c *gin.Context) {
+	format := c.DefaultQuery("format", formatJSON)
+	if format != formatJSON && format != formatYAML {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "format must be json or yaml"})
+		return
+	}
+	withDocuments, _ := strconv.ParseBool(c.Query("documents"))
+
+	bundle, status, err := h.usecase.Export(c.Param("id"), withDocuments)
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+
+	var content []byte
+	contentType := "application/json"
+	if format == formatYAML {
+		content, err = yamljson.Marshal(bundle)
+		contentType = "application/x-yaml"
+	} else {
+		content, err = json.MarshalIndent(bundle, "", "  ")
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+
+	fileName := strings.Replace(bundle.API.URL, "/", "-", -1) + ".bundle." + format
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	c.Data(http.StatusOK, contentType, content)
+}
+
+// Import BundleからAPIを作成します
+// クエリストリングの"conflict"で同じURLのAPIが存在する場合の処理("skip", "overwrite", "rename")、
+// "dryRun=true"で反映せずに変更内容のみ返却するか指定できます
+// BundleはContent-Typeがyamlを含む場合、またはクエリストリングの"format=yaml"でYAMLとして読み込みます
+func (h *BundleHandler) Import(c *gin.Context) {
+	if c.Param("id") != "import" {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Error: "not found"})
+		return
+	}
+
+	body, _ := c.GetRawData()
+	var bundle domain.Bundle
+	var err error
+	if c.Query("format") == formatYAML || strings.Contains(c.ContentType(), formatYAML) {
+		err = yamljson.Unmarshal(body, &bundle)
+	} else {
+		err = json.Unmarshal(body, &bundle)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(c.Query("dryRun"))
+	request := domain.ImportRequest{
+		Conflict: c.Query("conflict"),
+		DryRun:   dryRun,
+	}
+
+	result, status, err := h.usecase.Import(bundle, request)
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+
+	c.JSON(status, result)
+}
@@ -0,0 +1,150 @@
+package handler_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/bundle/handler"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+var mockBundle = domain.Bundle{
+	Version: domain.BundleVersion,
+	API:     domain.API{ID: "api", Name: "users", URL: "v1/users"},
+	Models:  []domain.Model{},
+	Methods: []domain.Method{},
+}
+
+func newMockRouter() (*gin.Engine, *gin.RouterGroup) {
+	router := gin.Default()
+	apiV1 := router.Group("api/v1")
+
+	return router, apiV1
+}
+
+func TestExport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("JSON", func(t *testing.T) {
+		mockUsecase := new(mocks.BundleUsecase)
+		mockUsecase.On("Export", "api", true).Return(mockBundle, http.StatusOK, nil).Once()
+
+		router, rg := newMockRouter()
+		handler.NewBundleHandler(rg, mockUsecase)
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/apis/api/export?documents=true", nil)
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, "application/json", res.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="v1-users.bundle.json"`, res.Header().Get("Content-Disposition"))
+		assert.Contains(t, res.Body.String(), `"url": "v1/users"`)
+	})
+	t.Run("YAML", func(t *testing.T) {
+		mockUsecase := new(mocks.BundleUsecase)
+		mockUsecase.On("Export", "api", false).Return(mockBundle, http.StatusOK, nil).Once()
+
+		router, rg := newMockRouter()
+		handler.NewBundleHandler(rg, mockUsecase)
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/apis/api/export?format=yaml", nil)
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, `attachment; filename="v1-users.bundle.yaml"`, res.Header().Get("Content-Disposition"))
+		assert.Contains(t, res.Body.String(), "  url: v1/users\n")
+	})
+	t.Run("不正なformat", func(t *testing.T) {
+		router, rg := newMockRouter()
+		handler.NewBundleHandler(rg, new(mocks.BundleUsecase))
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/apis/api/export?format=xml", nil)
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+	})
+}
+
+func TestImport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockResult := domain.ImportResult{APIID: "new", URL: "v1/users"}
+
+	t.Run("JSON", func(t *testing.T) {
+		mockUsecase := new(mocks.BundleUsecase)
+		request := domain.ImportRequest{Conflict: domain.ImportConflictRename, DryRun: true}
+		mockUsecase.On("Import", mockBundle, request).Return(mockResult, http.StatusOK, nil).Once()
+
+		router, rg := newMockRouter()
+		handler.NewBundleHandler(rg, mockUsecase)
+
+		body := []byte(`{"version": 1, "api": {"id": "api", "name": "users", "url": "v1/users"}, "models": [], "methods": []}`)
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/apis/import?conflict=rename&dryRun=true", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `{"dryRun": false, "apiId": "new", "url": "v1/users", "changes": null}`, res.Body.String())
+	})
+	t.Run("YAML", func(t *testing.T) {
+		mockUsecase := new(mocks.BundleUsecase)
+		mockUsecase.On("Import", mockBundle, domain.ImportRequest{}).Return(mockResult, http.StatusCreated, nil).Once()
+
+		router, rg := newMockRouter()
+		handler.NewBundleHandler(rg, mockUsecase)
+
+		body := []byte("version: 1\napi:\n  id: api\n  name: users\n  url: v1/users\nmodels: []\nmethods: []\n")
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/apis/import", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-yaml")
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusCreated, res.Code)
+	})
+	t.Run("競合", func(t *testing.T) {
+		mockUsecase := new(mocks.BundleUsecase)
+		mockUsecase.On("Import", mockBundle, domain.ImportRequest{}).Return(domain.ImportResult{}, http.StatusConflict, errors.New("api url v1/users already exists")).Once()
+
+		router, rg := newMockRouter()
+		handler.NewBundleHandler(rg, mockUsecase)
+
+		body := []byte(`{"version": 1, "api": {"id": "api", "name": "users", "url": "v1/users"}, "models": [], "methods": []}`)
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/apis/import", bytes.NewReader(body))
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusConflict, res.Code)
+		assert.JSONEq(t, `{"error": "api url v1/users already exists"}`, res.Body.String())
+	})
+	t.Run("不正なJSON", func(t *testing.T) {
+		router, rg := newMockRouter()
+		handler.NewBundleHandler(rg, new(mocks.BundleUsecase))
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/apis/import", bytes.NewReader([]byte("{")))
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+	})
+	t.Run("import以外", func(t *testing.T) {
+		router, rg := newMockRouter()
+		handler.NewBundleHandler(rg, new(mocks.BundleUsecase))
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/apis/other", bytes.NewReader([]byte("{}")))
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusNotFound, res.Code)
+	})
+}
@@ -0,0 +1,677 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"time"
+
+	_apiRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/api/repository"
+	_methodRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/method/repository"
+	_modelRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/model/repository"
+	_apiserverRepository "github.com/Hajime3778/api-creator-backend/pkg/apiserver/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/validation"
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ignoreDiffFields 差分に含めない項目(インポート先で決まる項目)
+var ignoreDiffFields = map[string]bool{
+	"id":             true,
+	"apiId":          true,
+	"collectionName": true,
+	"created_at":     true,
+	"updated_at":     true,
+}
+
+// BundleUsecase Interface
+type BundleUsecase interface {
+	Export(id string, withDocuments bool) (domain.Bundle, int, error)
+	Import(bundle domain.Bundle, request domain.ImportRequest) (domain.ImportResult, int, error)
+	Sync(bundles []domain.Bundle, request domain.SyncRequest) ([]domain.ImportResult, int, error)
+}
+
+type bundleUsecase struct {
+	apiRepo       _apiRepository.APIRepository
+	methodRepo    _methodRepository.MethodRepository
+	modelRepo     _modelRepository.ModelRepository
+	apiserverRepo _apiserverRepository.APIServerRepository
+}
+
+// NewBundleUsecase BundleUsecaseインターフェイスを表すオブジェクトを作成します
+func NewBundleUsecase(apiRepo _apiRepository.APIRepository, methodRepo _methodRepository.MethodRepository, modelRepo _modelRepository.ModelRepository, apiserverRepo _apiserverRepository.APIServerRepository) BundleUsecase {
+	return &bundleUsecase{
+		apiRepo:       apiRepo,
+		methodRepo:    methodRepo,
+		modelRepo:     modelRepo,
+		apiserverRepo: apiserverRepo,
+	}
+}
+
+// Export APIとMethod、ModelをBundleとして取得します
+// withDocumentsがtrueの場合、Modelのコレクションのドキュメントも含めます
+func (u *bundleUsecase) Export(id string, withDocuments bool) (domain.Bundle, int, error) {
+	api, err := u.apiRepo.GetByID(id)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return domain.Bundle{}, http.StatusNotFound, err
+		}
+		return domain.Bundle{}, http.StatusInternalServerError, err
+	}
+
+	models, err := u.getModels(id)
+	if err != nil {
+		return domain.Bundle{}, http.StatusInternalServerError, err
+	}
+
+	methods, err := u.methodRepo.GetListByAPIID(id)
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return domain.Bundle{}, http.StatusInternalServerError, err
+	}
+	if methods == nil {
+		methods = []domain.Method{}
+	}
+
+	bundle := domain.Bundle{
+		Version:    domain.BundleVersion,
+		ExportedAt: time.Now(),
+		API:        api,
+		Models:     models,
+		Methods:    methods,
+	}
+
+	if withDocuments {
+		bundle.Documents = map[string][]json.RawMessage{}
+		for _, model := range models {
+			documents, status, err := u.getDocuments(model)
+			if err != nil {
+				return domain.Bundle{}, status, err
+			}
+			bundle.Documents[model.ID] = documents
+		}
+	}
+
+	return bundle, http.StatusOK, nil
+}
+
+// Import BundleからAPIとMethod、Modelを作成します
+// IDはすべてインポート先で新しく採番し、Bundle内の参照(MethodのModelIDなど)も置き換えます
+func (u *bundleUsecase) Import(bundle domain.Bundle, request domain.ImportRequest) (domain.ImportResult, int, error) {
+	switch request.Conflict {
+	case "", domain.ImportConflictSkip, domain.ImportConflictOverwrite, domain.ImportConflictRename:
+	default:
+		return domain.ImportResult{}, http.StatusBadRequest, fmt.Errorf("conflict must be one of %s, %s, %s", domain.ImportConflictSkip, domain.ImportConflictOverwrite, domain.ImportConflictRename)
+	}
+
+	if err := validateBundle(bundle); err != nil {
+		return domain.ImportResult{}, http.StatusBadRequest, err
+	}
+
+	apis, err := u.apiRepo.GetAll()
+	if err != nil {
+		return domain.ImportResult{}, http.StatusInternalServerError, err
+	}
+	urls := map[string]domain.API{}
+	for _, api := range apis {
+		urls[api.URL] = api
+	}
+
+	var plan domain.ImportPlan
+	var changes []domain.ImportChange
+	modelIDs := map[string]string{}
+
+	existing, conflicted := urls[bundle.API.URL]
+	switch {
+	case !conflicted:
+		plan, changes, modelIDs = planCreate(bundle, bundle.API.URL)
+	case request.Conflict == domain.ImportConflictSkip:
+		result := domain.ImportResult{
+			DryRun: request.DryRun,
+			APIID:  existing.ID,
+			URL:    existing.URL,
+			Changes: []domain.ImportChange{
+				{Kind: "api", Action: domain.ImportActionSkip, ID: existing.ID, SourceID: bundle.API.ID, Name: bundle.API.Name},
+			},
+		}
+		return result, http.StatusOK, nil
+	case request.Conflict == domain.ImportConflictRename:
+		url := bundle.API.URL
+		for i := 2; ; i++ {
+			url = fmt.Sprintf("%s-%d", bundle.API.URL, i)
+			if _, ok := urls[url]; !ok {
+				break
+			}
+		}
+		plan, changes, modelIDs = planCreate(bundle, url)
+	case request.Conflict == domain.ImportConflictOverwrite:
+		plan, changes, modelIDs, err = u.planOverwrite(bundle, existing)
+		if err != nil {
+			return domain.ImportResult{}, http.StatusInternalServerError, err
+		}
+	default:
+		return domain.ImportResult{}, http.StatusConflict, fmt.Errorf("api url %s already exists", bundle.API.URL)
+	}
+
+	changes = append(changes, documentChanges(bundle, plan, modelIDs)...)
+
+	result := domain.ImportResult{
+		DryRun:  request.DryRun,
+		APIID:   plan.API.ID,
+		URL:     plan.API.URL,
+		Changes: changes,
+	}
+	if request.DryRun {
+		return result, http.StatusOK, nil
+	}
+
+	if err := u.apiRepo.Import(plan); err != nil {
+		return domain.ImportResult{}, http.StatusInternalServerError, err
+	}
+	if status, err := u.loadBundleDocuments(bundle, plan, modelIDs); err != nil {
+		return domain.ImportResult{}, status, err
+	}
+
+	if plan.CreateAPI {
+		return result, http.StatusCreated, nil
+	}
+	return result, http.StatusOK, nil
+}
+
+// Sync 定義ファイルのBundleとURLが同じAPIを上書きし、存在しないAPIを作成します
+// request.Pruneがtrueの場合、Bundleに存在しないAPIを削除します(コレクションのドキュメントは削除しません)
+// すべてのAPIの変更は1つのトランザクションで反映します
+func (u *bundleUsecase) Sync(bundles []domain.Bundle, request domain.SyncRequest) ([]domain.ImportResult, int, error) {
+	urls := map[string]bool{}
+	for _, bundle := range bundles {
+		if err := validateBundle(bundle); err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("%s: %s", bundle.API.URL, err.Error())
+		}
+		if urls[bundle.API.URL] {
+			return nil, http.StatusBadRequest, fmt.Errorf("api url %s is duplicated", bundle.API.URL)
+		}
+		urls[bundle.API.URL] = true
+	}
+
+	apis, err := u.apiRepo.GetAll()
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	existing := map[string]domain.API{}
+	for _, api := range apis {
+		existing[api.URL] = api
+	}
+
+	type syncPlan struct {
+		bundle   domain.Bundle
+		plan     domain.ImportPlan
+		modelIDs map[string]string
+	}
+	var syncPlans []syncPlan
+	var plans []domain.ImportPlan
+	var results []domain.ImportResult
+	for _, bundle := range bundles {
+		var plan domain.ImportPlan
+		var changes []domain.ImportChange
+		var modelIDs map[string]string
+		if api, ok := existing[bundle.API.URL]; ok {
+			plan, changes, modelIDs, err = u.planOverwrite(bundle, api)
+			if err != nil {
+				return nil, http.StatusInternalServerError, err
+			}
+		} else {
+			plan, changes, modelIDs = planCreate(bundle, bundle.API.URL)
+		}
+		changes = append(changes, documentChanges(bundle, plan, modelIDs)...)
+
+		results = append(results, domain.ImportResult{DryRun: request.DryRun, APIID: plan.API.ID, URL: plan.API.URL, Changes: changes})
+		// 変更がないAPIは反映しない
+		if hasChanges(changes) {
+			syncPlans = append(syncPlans, syncPlan{bundle: bundle, plan: plan, modelIDs: modelIDs})
+			plans = append(plans, plan)
+		}
+	}
+
+	if request.Prune {
+		for _, api := range apis {
+			if urls[api.URL] {
+				continue
+			}
+			plan, changes, err := u.planDelete(api)
+			if err != nil {
+				return nil, http.StatusInternalServerError, err
+			}
+			results = append(results, domain.ImportResult{DryRun: request.DryRun, APIID: api.ID, URL: api.URL, Changes: changes})
+			plans = append(plans, plan)
+		}
+	}
+
+	if request.DryRun || len(plans) == 0 {
+		return results, http.StatusOK, nil
+	}
+
+	if err := u.apiRepo.ImportAll(plans); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	for _, p := range syncPlans {
+		if status, err := u.loadBundleDocuments(p.bundle, p.plan, p.modelIDs); err != nil {
+			return nil, status, err
+		}
+	}
+
+	return results, http.StatusOK, nil
+}
+
+// getModels APIのModelを取得します(Modelが存在しない場合は空)
+func (u *bundleUsecase) getModels(apiID string) ([]domain.Model, error) {
+	model, err := u.modelRepo.GetByAPIID(apiID)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return []domain.Model{}, nil
+		}
+		return nil, err
+	}
+	return []domain.Model{model}, nil
+}
+
+// getDocuments Modelのコレクションのドキュメントを取得します
+func (u *bundleUsecase) getDocuments(model domain.Model) ([]json.RawMessage, int, error) {
+	documents, status, err := u.apiserverRepo.GetList(model.GetCollectionName(), "", "")
+	if err != nil {
+		if status == http.StatusNotFound {
+			return []json.RawMessage{}, http.StatusOK, nil
+		}
+		return nil, status, err
+	}
+
+	b, err := json.Marshal(documents)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	var result []json.RawMessage
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return result, http.StatusOK, nil
+}
+
+// loadBundleDocuments Bundleのドキュメントを、インポート先のModelのコレクションに投入します
+func (u *bundleUsecase) loadBundleDocuments(bundle domain.Bundle, plan domain.ImportPlan, modelIDs map[string]string) (int, error) {
+	models := importedModels(plan)
+	for _, source := range bundle.Models {
+		if status, err := u.loadDocuments(models[modelIDs[source.ID]], bundle.Documents[source.ID]); err != nil {
+			return status, err
+		}
+	}
+	return http.StatusOK, nil
+}
+
+// loadDocuments ドキュメントをコレクションに投入します(Keyが同じドキュメントは上書きします)
+func (u *bundleUsecase) loadDocuments(model domain.Model, documents []json.RawMessage) (int, error) {
+	if len(documents) == 0 {
+		return http.StatusOK, nil
+	}
+	keys, err := model.GetKeyNames()
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	collectionName := model.GetCollectionName()
+	for _, document := range documents {
+		var b bson.M
+		if err := bson.UnmarshalExtJSON(document, false, &b); err != nil {
+			return http.StatusBadRequest, err
+		}
+
+		if _, status, _ := u.apiserverRepo.Get(collectionName, keys[0], b[keys[0]]); status == http.StatusNotFound {
+			if _, status, err := u.apiserverRepo.Create(collectionName, keys[0], document); err != nil {
+				return status, err
+			}
+			continue
+		}
+		if _, status, err := u.apiserverRepo.Update(collectionName, keys[0], document); err != nil {
+			return status, err
+		}
+	}
+	return http.StatusOK, nil
+}
+
+// planOverwrite 既存のAPIをBundleの内容で上書きする内容を作成します
+// ModelはName、MethodはTypeとURLが同じものを更新し、Bundleに存在しないものは削除します
+func (u *bundleUsecase) planOverwrite(bundle domain.Bundle, existing domain.API) (domain.ImportPlan, []domain.ImportChange, map[string]string, error) {
+	currentModels, err := u.getModels(existing.ID)
+	if err != nil {
+		return domain.ImportPlan{}, nil, nil, err
+	}
+	currentMethods, err := u.methodRepo.GetListByAPIID(existing.ID)
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return domain.ImportPlan{}, nil, nil, err
+	}
+
+	api := bundle.API
+	api.ID = existing.ID
+	api.URL = existing.URL
+	api.CommonColumn = existing.CommonColumn
+	plan := domain.ImportPlan{API: api}
+	changes := []domain.ImportChange{updateChange("api", existing, api, bundle.API.ID, api.Name)}
+
+	// Model
+	modelIDs := map[string]string{}
+	matched := matchModels(bundle.Models, currentModels)
+	for _, source := range bundle.Models {
+		current, ok := matched[source.ID]
+		if !ok {
+			model := newModel(source, api.ID)
+			modelIDs[source.ID] = model.ID
+			plan.CreateModels = append(plan.CreateModels, model)
+			changes = append(changes, domain.ImportChange{Kind: "model", Action: domain.ImportActionCreate, ID: model.ID, SourceID: source.ID, Name: model.Name})
+			continue
+		}
+		model := source
+		model.ID = current.ID
+		model.APIID = api.ID
+		model.CollectionName = current.GetCollectionName()
+		model.CommonColumn = current.CommonColumn
+		modelIDs[source.ID] = model.ID
+		plan.UpdateModels = append(plan.UpdateModels, model)
+		changes = append(changes, updateChange("model", current, model, source.ID, model.Name))
+	}
+	for _, current := range currentModels {
+		if !containsModel(matched, current.ID) {
+			plan.DeleteModels = append(plan.DeleteModels, current)
+			changes = append(changes, domain.ImportChange{Kind: "model", Action: domain.ImportActionDelete, ID: current.ID, Name: current.Name})
+		}
+	}
+
+	// Method
+	methods := map[string]domain.Method{}
+	for _, current := range currentMethods {
+		methods[current.Type+" "+current.URL] = current
+	}
+	for _, source := range bundle.Methods {
+		name := source.Type + " " + source.URL
+		current, ok := methods[name]
+		if !ok {
+			method := newMethod(source, api.ID, modelIDs)
+			plan.CreateMethods = append(plan.CreateMethods, method)
+			changes = append(changes, domain.ImportChange{Kind: "method", Action: domain.ImportActionCreate, ID: method.ID, SourceID: source.ID, Name: name})
+			continue
+		}
+		delete(methods, name)
+		method := newMethod(source, api.ID, modelIDs)
+		method.ID = current.ID
+		method.CommonColumn = current.CommonColumn
+		plan.UpdateMethods = append(plan.UpdateMethods, method)
+		changes = append(changes, updateChange("method", current, method, source.ID, name))
+	}
+	for _, current := range currentMethods {
+		if _, ok := methods[current.Type+" "+current.URL]; ok {
+			plan.DeleteMethods = append(plan.DeleteMethods, current)
+			changes = append(changes, domain.ImportChange{Kind: "method", Action: domain.ImportActionDelete, ID: current.ID, Name: current.Type + " " + current.URL})
+		}
+	}
+
+	return plan, changes, modelIDs, nil
+}
+
+// planDelete APIとMethod、Modelを削除する内容を作成します
+func (u *bundleUsecase) planDelete(api domain.API) (domain.ImportPlan, []domain.ImportChange, error) {
+	models, err := u.getModels(api.ID)
+	if err != nil {
+		return domain.ImportPlan{}, nil, err
+	}
+	methods, err := u.methodRepo.GetListByAPIID(api.ID)
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return domain.ImportPlan{}, nil, err
+	}
+
+	plan := domain.ImportPlan{API: api, DeleteAPI: true, DeleteModels: models, DeleteMethods: methods}
+	changes := []domain.ImportChange{{Kind: "api", Action: domain.ImportActionDelete, ID: api.ID, Name: api.Name}}
+	for _, model := range models {
+		changes = append(changes, domain.ImportChange{Kind: "model", Action: domain.ImportActionDelete, ID: model.ID, Name: model.Name})
+	}
+	for _, method := range methods {
+		changes = append(changes, domain.ImportChange{Kind: "method", Action: domain.ImportActionDelete, ID: method.ID, Name: method.Type + " " + method.URL})
+	}
+	return plan, changes, nil
+}
+
+// planCreate Bundleから新しいAPIを作成する内容を作成します
+func planCreate(bundle domain.Bundle, url string) (domain.ImportPlan, []domain.ImportChange, map[string]string) {
+	api := bundle.API
+	id, _ := uuid.NewRandom()
+	api.ID = id.String()
+	api.URL = url
+	api.CommonColumn = domain.CommonColumn{}
+
+	plan := domain.ImportPlan{API: api, CreateAPI: true}
+	changes := []domain.ImportChange{{Kind: "api", Action: domain.ImportActionCreate, ID: api.ID, SourceID: bundle.API.ID, Name: api.Name}}
+
+	modelIDs := map[string]string{}
+	for _, source := range bundle.Models {
+		model := newModel(source, api.ID)
+		modelIDs[source.ID] = model.ID
+		plan.CreateModels = append(plan.CreateModels, model)
+		changes = append(changes, domain.ImportChange{Kind: "model", Action: domain.ImportActionCreate, ID: model.ID, SourceID: source.ID, Name: model.Name})
+	}
+	for _, source := range bundle.Methods {
+		method := newMethod(source, api.ID, modelIDs)
+		plan.CreateMethods = append(plan.CreateMethods, method)
+		changes = append(changes, domain.ImportChange{Kind: "method", Action: domain.ImportActionCreate, ID: method.ID, SourceID: source.ID, Name: method.Type + " " + method.URL})
+	}
+
+	return plan, changes, modelIDs
+}
+
+// documentChanges Bundleのドキュメントを投入する変更内容を作成します
+func documentChanges(bundle domain.Bundle, plan domain.ImportPlan, modelIDs map[string]string) []domain.ImportChange {
+	models := importedModels(plan)
+	var changes []domain.ImportChange
+	for _, source := range bundle.Models {
+		documents := bundle.Documents[source.ID]
+		if len(documents) == 0 {
+			continue
+		}
+		model := models[modelIDs[source.ID]]
+		changes = append(changes, domain.ImportChange{
+			Kind:     "documents",
+			Action:   domain.ImportActionCreate,
+			ID:       model.ID,
+			SourceID: source.ID,
+			Name:     model.Name,
+			Count:    len(documents),
+		})
+	}
+	return changes
+}
+
+// importedModels インポート先のModelを、IDごとに取得します
+func importedModels(plan domain.ImportPlan) map[string]domain.Model {
+	models := map[string]domain.Model{}
+	for _, model := range append(plan.CreateModels, plan.UpdateModels...) {
+		models[model.ID] = model
+	}
+	return models
+}
+
+// hasChanges 変更なし以外の変更が含まれるか判定します
+func hasChanges(changes []domain.ImportChange) bool {
+	for _, change := range changes {
+		if change.Action != domain.ImportActionUnchanged {
+			return true
+		}
+	}
+	return false
+}
+
+// newModel BundleのModelから、新しいIDのModelを作成します
+func newModel(source domain.Model, apiID string) domain.Model {
+	model := source
+	id, _ := uuid.NewRandom()
+	model.ID = id.String()
+	model.APIID = apiID
+	// コレクション名はModel名を変更しても変わらないよう、IDから作成する
+	model.CollectionName = model.ID
+	model.CommonColumn = domain.CommonColumn{}
+	return model
+}
+
+// newMethod BundleのMethodから、新しいIDのMethodを作成します
+func newMethod(source domain.Method, apiID string, modelIDs map[string]string) domain.Method {
+	method := source
+	id, _ := uuid.NewRandom()
+	method.ID = id.String()
+	method.APIID = apiID
+	method.RequestModelID = modelIDs[source.RequestModelID]
+	method.ResponseModelID = modelIDs[source.ResponseModelID]
+	method.CommonColumn = domain.CommonColumn{}
+	return method
+}
+
+// matchModels BundleのModelに対応する既存のModelを、BundleのModelのIDごとに取得します
+func matchModels(sources []domain.Model, currents []domain.Model) map[string]domain.Model {
+	matched := map[string]domain.Model{}
+	var unmatchedSources, unmatchedCurrents []domain.Model
+	for _, current := range currents {
+		found := false
+		for _, source := range sources {
+			if _, ok := matched[source.ID]; !ok && source.Name == current.Name {
+				matched[source.ID] = current
+				found = true
+				break
+			}
+		}
+		if !found {
+			unmatchedCurrents = append(unmatchedCurrents, current)
+		}
+	}
+	for _, source := range sources {
+		if _, ok := matched[source.ID]; !ok {
+			unmatchedSources = append(unmatchedSources, source)
+		}
+	}
+	// Modelが1つずつの場合は、Model名が変更されていても同じModelとして扱う(コレクションを引き継ぐため)
+	if len(unmatchedSources) == 1 && len(unmatchedCurrents) == 1 {
+		matched[unmatchedSources[0].ID] = unmatchedCurrents[0]
+	}
+	return matched
+}
+
+func containsModel(matched map[string]domain.Model, id string) bool {
+	for _, model := range matched {
+		if model.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// updateChange 更新の変更内容を作成します(差分がない場合は変更なしとします)
+func updateChange(kind string, before interface{}, after interface{}, sourceID string, name string) domain.ImportChange {
+	var id string
+	switch v := after.(type) {
+	case domain.API:
+		id = v.ID
+	case domain.Model:
+		id = v.ID
+	case domain.Method:
+		id = v.ID
+	}
+
+	change := domain.ImportChange{Kind: kind, Action: domain.ImportActionUpdate, ID: id, SourceID: sourceID, Name: name}
+	change.Diff = diff(before, after)
+	if len(change.Diff) == 0 {
+		change.Action = domain.ImportActionUnchanged
+	}
+	return change
+}
+
+// diff jsonの項目ごとに、変更前と変更後の値を比較します
+func diff(before interface{}, after interface{}) []domain.FieldDiff {
+	beforeMap := toMap(before)
+	afterMap := toMap(after)
+
+	var fields []string
+	for field := range afterMap {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var result []domain.FieldDiff
+	for _, field := range fields {
+		if ignoreDiffFields[field] || reflect.DeepEqual(beforeMap[field], afterMap[field]) {
+			continue
+		}
+		result = append(result, domain.FieldDiff{Field: field, Before: beforeMap[field], After: afterMap[field]})
+	}
+	return result
+}
+
+func toMap(v interface{}) map[string]interface{} {
+	b, _ := json.Marshal(v)
+	m := map[string]interface{}{}
+	json.Unmarshal(b, &m)
+	return m
+}
+
+// validateBundle Bundleの内容を検証します
+func validateBundle(bundle domain.Bundle) error {
+	if bundle.Version != domain.BundleVersion {
+		return fmt.Errorf("unsupported bundle version %d", bundle.Version)
+	}
+	if bundle.API.URL == "" || !validation.IsHalfWidthOnly(bundle.API.URL) {
+		return errors.New("url is halfwidth only")
+	}
+
+	models := map[string]domain.Model{}
+	for i, model := range bundle.Models {
+		if model.ID == "" {
+			return fmt.Errorf("models[%d]: id is required", i)
+		}
+		if _, ok := models[model.ID]; ok {
+			return fmt.Errorf("models[%d]: id %s is duplicated", i, model.ID)
+		}
+		if err := model.ValidateSchema(); err != nil {
+			return fmt.Errorf("models[%d]: %s", i, err.Error())
+		}
+		models[model.ID] = model
+	}
+
+	methods := map[string]bool{}
+	for i, method := range bundle.Methods {
+		if method.URL != "" && !validation.IsHalfWidthOnly(method.URL) {
+			return fmt.Errorf("methods[%d]: url is halfwidth only", i)
+		}
+		if methods[method.Type+" "+method.URL] {
+			return fmt.Errorf("methods[%d]: %s %s is duplicated", i, method.Type, method.URL)
+		}
+		methods[method.Type+" "+method.URL] = true
+		for _, modelID := range []string{method.RequestModelID, method.ResponseModelID} {
+			if _, ok := models[modelID]; modelID != "" && !ok {
+				return fmt.Errorf("methods[%d]: model %s is not found in bundle", i, modelID)
+			}
+		}
+	}
+
+	for modelID, documents := range bundle.Documents {
+		model, ok := models[modelID]
+		if !ok {
+			return fmt.Errorf("documents: model %s is not found in bundle", modelID)
+		}
+		data, err := json.Marshal(documents)
+		if err != nil {
+			return err
+		}
+		// Fixtureと同じく、ドキュメントがModelに則っているか検証する
+		fixture := domain.Fixture{Data: string(data)}
+		if err := fixture.Validate(model); err != nil {
+			return fmt.Errorf("documents[%s]: %s", modelID, err.Error())
+		}
+	}
+
+	return nil
+}
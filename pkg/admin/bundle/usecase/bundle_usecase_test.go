@@ -0,0 +1,346 @@
+package usecase_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/bundle/usecase"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+	"github.com/jinzhu/gorm"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const mockSchema = `{"type": "object", "keys": ["id"], "properties": {"id": {"type": "string"}, "name": {"type": "string"}}}`
+
+var mockAPI = domain.API{ID: "api", Name: "users", URL: "users"}
+
+var mockModel = domain.Model{ID: "model", APIID: "api", Name: "User", Schema: mockSchema, CollectionName: "model"}
+
+var mockMethods = []domain.Method{
+	{ID: "get", APIID: "api", Type: "GET", URL: "/{id}", ResponseModelID: "model"},
+	{ID: "post", APIID: "api", Type: "POST", RequestModelID: "model"},
+}
+
+func newBundle() domain.Bundle {
+	return domain.Bundle{
+		Version: domain.BundleVersion,
+		API:     mockAPI,
+		Models:  []domain.Model{mockModel},
+		Methods: mockMethods,
+	}
+}
+
+func TestExport(t *testing.T) {
+	t.Run("ドキュメントを含める", func(t *testing.T) {
+		mockAPIRepo := new(mocks.APIRepository)
+		mockMethodRepo := new(mocks.MethodRepository)
+		mockModelRepo := new(mocks.ModelRepository)
+		mockAPIServerRepo := new(mocks.APIServerRepository)
+
+		mockAPIRepo.On("GetByID", "api").Return(mockAPI, nil).Once()
+		mockModelRepo.On("GetByAPIID", "api").Return(mockModel, nil).Once()
+		mockMethodRepo.On("GetListByAPIID", "api").Return(mockMethods, nil).Once()
+		mockAPIServerRepo.On("GetList", "model", "", "").Return([]bson.M{{"id": "1", "name": "a"}}, http.StatusOK, nil).Once()
+		usecase := usecase.NewBundleUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, mockAPIServerRepo)
+
+		bundle, status, err := usecase.Export("api", true)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, domain.BundleVersion, bundle.Version)
+		assert.Equal(t, mockAPI, bundle.API)
+		assert.Equal(t, []domain.Model{mockModel}, bundle.Models)
+		assert.Equal(t, mockMethods, bundle.Methods)
+		assert.Len(t, bundle.Documents["model"], 1)
+		assert.JSONEq(t, `{"id": "1", "name": "a"}`, string(bundle.Documents["model"][0]))
+	})
+	t.Run("Modelが未定義", func(t *testing.T) {
+		mockAPIRepo := new(mocks.APIRepository)
+		mockMethodRepo := new(mocks.MethodRepository)
+		mockModelRepo := new(mocks.ModelRepository)
+
+		mockAPIRepo.On("GetByID", "api").Return(mockAPI, nil).Once()
+		mockModelRepo.On("GetByAPIID", "api").Return(domain.Model{}, gorm.ErrRecordNotFound).Once()
+		mockMethodRepo.On("GetListByAPIID", "api").Return([]domain.Method{}, nil).Once()
+		usecase := usecase.NewBundleUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, nil)
+
+		bundle, status, err := usecase.Export("api", false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Empty(t, bundle.Models)
+		assert.Nil(t, bundle.Documents)
+	})
+	t.Run("APIが存在しない", func(t *testing.T) {
+		mockAPIRepo := new(mocks.APIRepository)
+		mockAPIRepo.On("GetByID", "api").Return(domain.API{}, gorm.ErrRecordNotFound).Once()
+		usecase := usecase.NewBundleUsecase(mockAPIRepo, nil, nil, nil)
+
+		_, status, err := usecase.Export("api", false)
+
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+}
+
+func TestImport(t *testing.T) {
+	t.Run("新規作成(IDの振り直し)", func(t *testing.T) {
+		mockAPIRepo := new(mocks.APIRepository)
+		mockAPIServerRepo := new(mocks.APIServerRepository)
+
+		bundle := newBundle()
+		bundle.Documents = map[string][]json.RawMessage{"model": {json.RawMessage(`{"id": "1", "name": "a"}`)}}
+
+		var plan domain.ImportPlan
+		mockAPIRepo.On("GetAll").Return([]domain.API{{ID: "other", URL: "others"}}, nil).Once()
+		mockAPIRepo.On("Import", mock.AnythingOfType("domain.ImportPlan")).Run(func(args mock.Arguments) {
+			plan = args.Get(0).(domain.ImportPlan)
+		}).Return(nil).Once()
+		mockAPIServerRepo.On("Get", mock.Anything, "id", "1").Return(nil, http.StatusNotFound, nil).Once()
+		mockAPIServerRepo.On("Create", mock.Anything, "id", []byte(`{"id": "1", "name": "a"}`)).Return(nil, http.StatusCreated, nil).Once()
+		usecase := usecase.NewBundleUsecase(mockAPIRepo, nil, nil, mockAPIServerRepo)
+
+		result, status, err := usecase.Import(bundle, domain.ImportRequest{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, status)
+		assert.True(t, plan.CreateAPI)
+		assert.NotEqual(t, "api", plan.API.ID)
+		assert.Equal(t, "users", plan.API.URL)
+		assert.Equal(t, plan.API.ID, result.APIID)
+
+		assert.Len(t, plan.CreateModels, 1)
+		model := plan.CreateModels[0]
+		assert.NotEqual(t, "model", model.ID)
+		assert.Equal(t, plan.API.ID, model.APIID)
+		assert.Equal(t, model.ID, model.CollectionName)
+
+		assert.Len(t, plan.CreateMethods, 2)
+		for _, method := range plan.CreateMethods {
+			assert.Equal(t, plan.API.ID, method.APIID)
+		}
+		assert.Equal(t, model.ID, plan.CreateMethods[0].ResponseModelID)
+		assert.Equal(t, model.ID, plan.CreateMethods[1].RequestModelID)
+
+		assert.Len(t, result.Changes, 5)
+		assert.Equal(t, domain.ImportChange{Kind: "documents", Action: domain.ImportActionCreate, ID: model.ID, SourceID: "model", Name: "User", Count: 1}, result.Changes[4])
+		mockAPIServerRepo.AssertCalled(t, "Create", model.ID, "id", []byte(`{"id": "1", "name": "a"}`))
+	})
+	t.Run("dry run", func(t *testing.T) {
+		mockAPIRepo := new(mocks.APIRepository)
+		mockAPIRepo.On("GetAll").Return([]domain.API{}, nil).Once()
+		usecase := usecase.NewBundleUsecase(mockAPIRepo, nil, nil, nil)
+
+		result, status, err := usecase.Import(newBundle(), domain.ImportRequest{DryRun: true})
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.True(t, result.DryRun)
+		assert.Len(t, result.Changes, 4)
+		mockAPIRepo.AssertNotCalled(t, "Import", mock.Anything)
+	})
+	t.Run("URLが競合(指定なし)", func(t *testing.T) {
+		mockAPIRepo := new(mocks.APIRepository)
+		mockAPIRepo.On("GetAll").Return([]domain.API{{ID: "existing", URL: "users"}}, nil).Once()
+		usecase := usecase.NewBundleUsecase(mockAPIRepo, nil, nil, nil)
+
+		_, status, err := usecase.Import(newBundle(), domain.ImportRequest{})
+
+		assert.EqualError(t, err, "api url users already exists")
+		assert.Equal(t, http.StatusConflict, status)
+	})
+	t.Run("URLが競合(skip)", func(t *testing.T) {
+		mockAPIRepo := new(mocks.APIRepository)
+		mockAPIRepo.On("GetAll").Return([]domain.API{{ID: "existing", URL: "users"}}, nil).Once()
+		usecase := usecase.NewBundleUsecase(mockAPIRepo, nil, nil, nil)
+
+		result, status, err := usecase.Import(newBundle(), domain.ImportRequest{Conflict: domain.ImportConflictSkip})
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "existing", result.APIID)
+		assert.Equal(t, []domain.ImportChange{{Kind: "api", Action: domain.ImportActionSkip, ID: "existing", SourceID: "api", Name: "users"}}, result.Changes)
+		mockAPIRepo.AssertNotCalled(t, "Import", mock.Anything)
+	})
+	t.Run("URLが競合(rename)", func(t *testing.T) {
+		mockAPIRepo := new(mocks.APIRepository)
+		mockAPIRepo.On("GetAll").Return([]domain.API{{ID: "existing", URL: "users"}, {ID: "existing2", URL: "users-2"}}, nil).Once()
+		usecase := usecase.NewBundleUsecase(mockAPIRepo, nil, nil, nil)
+
+		result, _, err := usecase.Import(newBundle(), domain.ImportRequest{Conflict: domain.ImportConflictRename, DryRun: true})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "users-3", result.URL)
+	})
+	t.Run("URLが競合(overwrite)", func(t *testing.T) {
+		mockAPIRepo := new(mocks.APIRepository)
+		mockMethodRepo := new(mocks.MethodRepository)
+		mockModelRepo := new(mocks.ModelRepository)
+
+		existing := domain.API{ID: "existing", Name: "old", URL: "users"}
+		existingModel := domain.Model{ID: "existing-model", APIID: "existing", Name: "User", Schema: mockSchema, CollectionName: "collection"}
+		existingMethods := []domain.Method{
+			{ID: "existing-get", APIID: "existing", Type: "GET", URL: "/{id}", ResponseModelID: "existing-model"},
+			{ID: "existing-delete", APIID: "existing", Type: "DELETE", URL: "/{id}"},
+		}
+
+		var plan domain.ImportPlan
+		mockAPIRepo.On("GetAll").Return([]domain.API{existing}, nil).Once()
+		mockModelRepo.On("GetByAPIID", "existing").Return(existingModel, nil).Once()
+		mockMethodRepo.On("GetListByAPIID", "existing").Return(existingMethods, nil).Once()
+		mockAPIRepo.On("Import", mock.AnythingOfType("domain.ImportPlan")).Run(func(args mock.Arguments) {
+			plan = args.Get(0).(domain.ImportPlan)
+		}).Return(nil).Once()
+		usecase := usecase.NewBundleUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, nil)
+
+		result, status, err := usecase.Import(newBundle(), domain.ImportRequest{Conflict: domain.ImportConflictOverwrite})
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.False(t, plan.CreateAPI)
+		assert.Equal(t, "existing", plan.API.ID)
+		assert.Equal(t, "users", plan.API.Name)
+
+		// 既存のModelのIDとコレクションを引き継ぐ
+		assert.Equal(t, []domain.Model{{ID: "existing-model", APIID: "existing", Name: "User", Schema: mockSchema, CollectionName: "collection"}}, plan.UpdateModels)
+		assert.Len(t, plan.UpdateMethods, 1)
+		assert.Equal(t, "existing-get", plan.UpdateMethods[0].ID)
+		assert.Equal(t, "existing-model", plan.UpdateMethods[0].ResponseModelID)
+		assert.Len(t, plan.CreateMethods, 1)
+		assert.Equal(t, "existing-model", plan.CreateMethods[0].RequestModelID)
+		assert.Equal(t, []domain.Method{existingMethods[1]}, plan.DeleteMethods)
+
+		assert.Equal(t, []domain.ImportChange{
+			{Kind: "api", Action: domain.ImportActionUpdate, ID: "existing", SourceID: "api", Name: "users", Diff: []domain.FieldDiff{{Field: "name", Before: "old", After: "users"}}},
+			{Kind: "model", Action: domain.ImportActionUnchanged, ID: "existing-model", SourceID: "model", Name: "User"},
+			{Kind: "method", Action: domain.ImportActionUnchanged, ID: "existing-get", SourceID: "get", Name: "GET /{id}"},
+			{Kind: "method", Action: domain.ImportActionCreate, ID: plan.CreateMethods[0].ID, SourceID: "post", Name: "POST "},
+			{Kind: "method", Action: domain.ImportActionDelete, ID: "existing-delete", Name: "DELETE /{id}"},
+		}, result.Changes)
+	})
+	t.Run("不正なBundle", func(t *testing.T) {
+		cases := []struct {
+			name   string
+			modify func(bundle *domain.Bundle)
+			err    string
+		}{
+			{"バージョン", func(b *domain.Bundle) { b.Version = 99 }, "unsupported bundle version 99"},
+			{"存在しないModelの参照", func(b *domain.Bundle) { b.Methods[0].ResponseModelID = "unknown" }, "methods[0]: model unknown is not found in bundle"},
+			{"Methodの重複", func(b *domain.Bundle) { b.Methods = append(b.Methods, b.Methods[0]) }, "methods[2]: GET /{id} is duplicated"},
+			{"Modelに則っていないドキュメント", func(b *domain.Bundle) {
+				b.Documents = map[string][]json.RawMessage{"model": {json.RawMessage(`{"id": 1}`)}}
+			}, "documents[model]: data[0]: id: Invalid type. Expected: string, given: integer"},
+		}
+		for _, c := range cases {
+			t.Run(c.name, func(t *testing.T) {
+				bundle := newBundle()
+				bundle.Methods = append([]domain.Method{}, mockMethods...)
+				c.modify(&bundle)
+				usecase := usecase.NewBundleUsecase(nil, nil, nil, nil)
+
+				_, status, err := usecase.Import(bundle, domain.ImportRequest{})
+
+				assert.EqualError(t, err, c.err)
+				assert.Equal(t, http.StatusBadRequest, status)
+			})
+		}
+	})
+	t.Run("不正なconflict", func(t *testing.T) {
+		usecase := usecase.NewBundleUsecase(nil, nil, nil, nil)
+
+		_, status, err := usecase.Import(newBundle(), domain.ImportRequest{Conflict: "merge"})
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+}
+
+func TestSync(t *testing.T) {
+	t.Run("作成、変更なし、削除", func(t *testing.T) {
+		mockAPIRepo := new(mocks.APIRepository)
+		mockMethodRepo := new(mocks.MethodRepository)
+		mockModelRepo := new(mocks.ModelRepository)
+
+		existing := domain.API{ID: "existing", Name: "items", URL: "items"}
+		existingModel := domain.Model{ID: "existing-model", APIID: "existing", Name: "Item", Schema: mockSchema, CollectionName: "existing-model"}
+		existingMethods := []domain.Method{{ID: "existing-get", APIID: "existing", Type: "GET", URL: "/{id}", ResponseModelID: "existing-model"}}
+		pruned := domain.API{ID: "pruned", Name: "old", URL: "old"}
+		prunedMethods := []domain.Method{{ID: "pruned-get", APIID: "pruned", Type: "GET"}}
+
+		items := domain.Bundle{
+			Version: domain.BundleVersion,
+			API:     domain.API{Name: "items", URL: "items"},
+			Models:  []domain.Model{{ID: "Item", Name: "Item", Schema: mockSchema}},
+			Methods: []domain.Method{{Type: "GET", URL: "/{id}", ResponseModelID: "Item"}},
+		}
+
+		var plans []domain.ImportPlan
+		mockAPIRepo.On("GetAll").Return([]domain.API{existing, pruned}, nil).Once()
+		mockModelRepo.On("GetByAPIID", "existing").Return(existingModel, nil).Once()
+		mockMethodRepo.On("GetListByAPIID", "existing").Return(existingMethods, nil).Once()
+		mockModelRepo.On("GetByAPIID", "pruned").Return(domain.Model{}, gorm.ErrRecordNotFound).Once()
+		mockMethodRepo.On("GetListByAPIID", "pruned").Return(prunedMethods, nil).Once()
+		mockAPIRepo.On("ImportAll", mock.Anything).Run(func(args mock.Arguments) {
+			plans = args.Get(0).([]domain.ImportPlan)
+		}).Return(nil).Once()
+		usecase := usecase.NewBundleUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, nil)
+
+		results, status, err := usecase.Sync([]domain.Bundle{newBundle(), items}, domain.SyncRequest{Prune: true})
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Len(t, results, 3)
+		assert.Equal(t, "users", results[0].URL)
+		assert.Equal(t, domain.ImportActionCreate, results[0].Changes[0].Action)
+		for _, change := range results[1].Changes {
+			assert.Equal(t, domain.ImportActionUnchanged, change.Action)
+		}
+		assert.Equal(t, []domain.ImportChange{
+			{Kind: "api", Action: domain.ImportActionDelete, ID: "pruned", Name: "old"},
+			{Kind: "method", Action: domain.ImportActionDelete, ID: "pruned-get", Name: "GET "},
+		}, results[2].Changes)
+
+		// 変更がないAPIは反映しない
+		assert.Len(t, plans, 2)
+		assert.True(t, plans[0].CreateAPI)
+		assert.True(t, plans[1].DeleteAPI)
+		assert.Equal(t, "pruned", plans[1].API.ID)
+		assert.Equal(t, prunedMethods, plans[1].DeleteMethods)
+	})
+	t.Run("dry run", func(t *testing.T) {
+		mockAPIRepo := new(mocks.APIRepository)
+		mockAPIRepo.On("GetAll").Return([]domain.API{{ID: "other", URL: "others"}}, nil).Once()
+		usecase := usecase.NewBundleUsecase(mockAPIRepo, nil, nil, nil)
+
+		results, status, err := usecase.Sync([]domain.Bundle{newBundle()}, domain.SyncRequest{DryRun: true})
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Len(t, results, 1)
+		assert.True(t, results[0].DryRun)
+		mockAPIRepo.AssertNotCalled(t, "ImportAll", mock.Anything)
+	})
+	t.Run("URLが重複", func(t *testing.T) {
+		usecase := usecase.NewBundleUsecase(nil, nil, nil, nil)
+
+		_, status, err := usecase.Sync([]domain.Bundle{newBundle(), newBundle()}, domain.SyncRequest{})
+
+		assert.EqualError(t, err, "api url users is duplicated")
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+	t.Run("不正なBundle", func(t *testing.T) {
+		bundle := newBundle()
+		bundle.Version = 99
+		usecase := usecase.NewBundleUsecase(nil, nil, nil, nil)
+
+		_, status, err := usecase.Sync([]domain.Bundle{bundle}, domain.SyncRequest{})
+
+		assert.EqualError(t, err, "users: unsupported bundle version 99")
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+}
@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/data/usecase"
+	"github.com/Hajime3778/api-creator-backend/pkg/dataio"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DataHandler コレクションの取り込み、出力に対するリクエストハンドラ
+type DataHandler struct {
+	usecase usecase.DataUsecase
+}
+
+// NewDataHandler DataHandlerを作成します
+func NewDataHandler(r *gin.RouterGroup, u usecase.DataUsecase) {
+	handler := &DataHandler{
+		usecase: u,
+	}
+	r.POST("/models/:id/import", handler.Import)
+	r.GET("/models/:id/export", handler.Export)
+	r.POST("/models/:id/export", handler.StartExport)
+
+	jobRoutes := r.Group("/data-jobs")
+	{
+		jobRoutes.GET("", handler.GetJobs)
+		jobRoutes.GET("/:id", handler.GetJob)
+		jobRoutes.GET("/:id/download", handler.Download)
+	}
+}
+
+// Import ファイルのドキュメントをModelのコレクションに取り込みます
+// ファイルはリクエストのボディ、またはmultipart/form-dataの"file"で送信します
+// クエリ(またはフォーム)のformatで形式、mappingで列名と項目名の対応(JSON)、async=trueで非同期での取り込みを指定します
+func (h *DataHandler) Import(c *gin.Context) {
+	modelID := c.Param("id")
+
+	body, size := io.Reader(c.Request.Body), c.Request.ContentLength
+	format := c.Query("format")
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: err.Error()})
+			log.Println(err.Error())
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: err.Error()})
+			log.Println(err.Error())
+			return
+		}
+		defer file.Close()
+		body, size = file, fileHeader.Size
+		if format == "" {
+			format = formatFromFileName(fileHeader.Filename)
+		}
+	}
+	if format == "" {
+		format = c.PostForm("format")
+	}
+	if format == "" {
+		format = dataio.FormatFromContentType(c.ContentType())
+	}
+
+	request := domain.DataImportRequest{Format: format}
+	request.Async, _ = strconv.ParseBool(c.DefaultQuery("async", c.PostForm("async")))
+	if mapping := c.DefaultQuery("mapping", c.PostForm("mapping")); mapping != "" {
+		if err := json.Unmarshal([]byte(mapping), &request.Mapping); err != nil {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "mapping must be json object"})
+			log.Println(err.Error())
+			return
+		}
+	}
+
+	result, status, err := h.usecase.Import(modelID, request, body, size)
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+
+	c.JSON(status, result)
+}
+
+// Export Modelのコレクションのドキュメントをダウンロードします
+// クエリのformatで形式、columns(カンマ区切り)でCSVの項目、filter[項目名]=値で条件を指定します
+func (h *DataHandler) Export(c *gin.Context) {
+	modelID := c.Param("id")
+	request := exportRequest(c)
+
+	w := &downloadWriter{context: c, format: request.Format, fileName: modelID + "." + request.Format}
+	_, status, err := h.usecase.Export(modelID, request, w)
+	if err != nil {
+		log.Println(err.Error())
+		if !w.started {
+			c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		}
+		// ダウンロードの途中でエラーになった場合は、ステータスを変更できないため中断する
+		return
+	}
+	if !w.started {
+		// NDJSONでドキュメントが0件の場合は何も書き込まれないため、ヘッダーのみ設定する
+		w.writeHeader()
+	}
+}
+
+// StartExport 非同期で出力するJobを作成します。完了後に/data-jobs/:id/downloadでダウンロードします
+// 条件はExportと同じクエリ、またはボディのJSONで指定します
+func (h *DataHandler) StartExport(c *gin.Context) {
+	modelID := c.Param("id")
+
+	request := exportRequest(c)
+	if c.Request.ContentLength > 0 {
+		if err := c.BindJSON(&request); err != nil {
+			log.Println(err.Error())
+			return
+		}
+	}
+
+	result, status, err := h.usecase.StartExport(modelID, request)
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+
+	c.JSON(status, result)
+}
+
+// GetJobs 取り込み、出力のJobを新しい順に取得します
+func (h *DataHandler) GetJobs(c *gin.Context) {
+	c.JSON(http.StatusOK, h.usecase.GetJobs())
+}
+
+// GetJob Jobの進捗と結果を取得します
+func (h *DataHandler) GetJob(c *gin.Context) {
+	id := c.Param("id")
+
+	result, status, err := h.usecase.GetJob(id)
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Download 完了した出力のJobのファイルをダウンロードします
+func (h *DataHandler) Download(c *gin.Context) {
+	id := c.Param("id")
+
+	file, fileName, result, status, err := h.usecase.OpenJobFile(id)
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+	defer file.Close()
+
+	c.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	c.DataFromReader(http.StatusOK, -1, dataio.ContentType(result.Format), file, nil)
+}
+
+// exportRequest クエリから出力の条件を取得します
+func exportRequest(c *gin.Context) domain.DataExportRequest {
+	request := domain.DataExportRequest{
+		Format: c.DefaultQuery("format", domain.DataFormatCSV),
+		Filter: c.QueryMap("filter"),
+	}
+	if columns := c.Query("columns"); columns != "" {
+		for _, column := range strings.Split(columns, ",") {
+			request.Columns = append(request.Columns, strings.TrimSpace(column))
+		}
+	}
+	return request
+}
+
+// formatFromFileName ファイルの拡張子から形式を判定します
+func formatFromFileName(fileName string) string {
+	switch {
+	case strings.HasSuffix(fileName, ".csv"):
+		return domain.DataFormatCSV
+	case strings.HasSuffix(fileName, ".ndjson"), strings.HasSuffix(fileName, ".jsonl"):
+		return domain.DataFormatNDJSON
+	case strings.HasSuffix(fileName, ".json"):
+		return domain.DataFormatJSON
+	default:
+		return ""
+	}
+}
+
+// downloadWriter 最初の書き込み時にダウンロードのヘッダーを設定し、レスポンスに逐次書き込みます
+// 書き込みが始まるまではエラーのステータスを返却できます
+type downloadWriter struct {
+	context  *gin.Context
+	format   string
+	fileName string
+	started  bool
+}
+
+func (w *downloadWriter) writeHeader() {
+	w.started = true
+	w.context.Header("Content-Disposition", `attachment; filename="`+w.fileName+`"`)
+	w.context.Header("Content-Type", dataio.ContentType(w.format))
+	w.context.Status(http.StatusOK)
+}
+
+func (w *downloadWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.writeHeader()
+	}
+	return w.context.Writer.Write(p)
+}
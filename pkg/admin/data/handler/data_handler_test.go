@@ -0,0 +1,173 @@
+package handler_test
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/data/handler"
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/data/usecase"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func newMockRouter() (*gin.Engine, *gin.RouterGroup) {
+	router := gin.Default()
+	apiV1 := router.Group("api/v1")
+
+	return router, apiV1
+}
+
+func TestImport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	csv := "ID,Name\n1,foo\n"
+
+	t.Run("ボディ", func(t *testing.T) {
+		request := domain.DataImportRequest{
+			Format:  domain.DataFormatCSV,
+			Mapping: map[string]string{"ID": "id", "Name": "name"},
+		}
+		mockDataUsecase := new(mocks.DataUsecase)
+		mockDataUsecase.On("Import", "model-1", request, csv).Return(domain.DataJob{ID: "job-1", Created: 1}, http.StatusOK, nil).Once()
+
+		router, rg := newMockRouter()
+		handler.NewDataHandler(rg, mockDataUsecase)
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", `/api/v1/models/model-1/import?mapping={"ID":"id","Name":"name"}`, strings.NewReader(csv))
+		req.Header.Set("Content-Type", "text/csv")
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		mockDataUsecase.AssertExpectations(t)
+	})
+	t.Run("multipart/form-data", func(t *testing.T) {
+		request := domain.DataImportRequest{Format: domain.DataFormatCSV, Async: true}
+		mockDataUsecase := new(mocks.DataUsecase)
+		mockDataUsecase.On("Import", "model-1", request, csv).Return(domain.DataJob{ID: "job-1"}, http.StatusAccepted, nil).Once()
+
+		router, rg := newMockRouter()
+		handler.NewDataHandler(rg, mockDataUsecase)
+
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, _ := form.CreateFormFile("file", "users.csv")
+		part.Write([]byte(csv))
+		form.WriteField("async", "true")
+		form.Close()
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/models/model-1/import", &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusAccepted, res.Code)
+		mockDataUsecase.AssertExpectations(t)
+	})
+	t.Run("mappingがJSONでない", func(t *testing.T) {
+		router, rg := newMockRouter()
+		handler.NewDataHandler(rg, new(mocks.DataUsecase))
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/models/model-1/import?format=csv&mapping=ID:id", strings.NewReader(csv))
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+	})
+}
+
+func TestExport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("test1", func(t *testing.T) {
+		request := domain.DataExportRequest{
+			Format:  domain.DataFormatCSV,
+			Filter:  map[string]string{"name": "foo"},
+			Columns: []string{"id", "name"},
+		}
+		mockDataUsecase := new(mocks.DataUsecase)
+		mockDataUsecase.On("Export", "model-1", request).Return(domain.DataJob{}, "id,name\n1,foo\n", http.StatusOK, nil).Once()
+
+		router, rg := newMockRouter()
+		handler.NewDataHandler(rg, mockDataUsecase)
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/models/model-1/export?filter[name]=foo&columns=id,name", nil)
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", res.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="model-1.csv"`, res.Header().Get("Content-Disposition"))
+		assert.Equal(t, "id,name\n1,foo\n", res.Body.String())
+	})
+	t.Run("Modelが存在しない", func(t *testing.T) {
+		mockDataUsecase := new(mocks.DataUsecase)
+		mockDataUsecase.On("Export", "model-1", domain.DataExportRequest{Format: domain.DataFormatJSON, Filter: map[string]string{}}).Return(domain.DataJob{}, "", http.StatusNotFound, errors.New("record not found")).Once()
+
+		router, rg := newMockRouter()
+		handler.NewDataHandler(rg, mockDataUsecase)
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/models/model-1/export?format=json", nil)
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusNotFound, res.Code)
+		assert.Equal(t, "", res.Header().Get("Content-Disposition"))
+	})
+}
+
+func TestDownload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("test1", func(t *testing.T) {
+		mockDataUsecase := new(mocks.DataUsecase)
+		mockDataUsecase.On("OpenJobFile", "job-1").Return("[]\n", "users.json", domain.DataJob{Format: domain.DataFormatJSON}, http.StatusOK, nil).Once()
+
+		router, rg := newMockRouter()
+		handler.NewDataHandler(rg, mockDataUsecase)
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/data-jobs/job-1/download", nil)
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, `attachment; filename="users.json"`, res.Header().Get("Content-Disposition"))
+		assert.Equal(t, "[]\n", res.Body.String())
+	})
+	t.Run("実行中", func(t *testing.T) {
+		mockDataUsecase := new(mocks.DataUsecase)
+		mockDataUsecase.On("OpenJobFile", "job-1").Return("", "", domain.DataJob{}, http.StatusConflict, errors.New("job is running")).Once()
+
+		router, rg := newMockRouter()
+		handler.NewDataHandler(rg, mockDataUsecase)
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/data-jobs/job-1/download", nil)
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusConflict, res.Code)
+	})
+}
+
+func TestGetJob(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockDataUsecase := new(mocks.DataUsecase)
+	mockDataUsecase.On("GetJob", "unknown").Return(domain.DataJob{}, http.StatusNotFound, usecase.ErrJobNotFound).Once()
+
+	router, rg := newMockRouter()
+	handler.NewDataHandler(rg, mockDataUsecase)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/data-jobs/unknown", nil)
+	router.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusNotFound, res.Code)
+}
@@ -0,0 +1,468 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	_modelRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/model/repository"
+	_apiserverRepository "github.com/Hajime3778/api-creator-backend/pkg/apiserver/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/dataio"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	// maxRowErrors Jobに保持する行ごとのエラーの最大件数
+	maxRowErrors = 100
+	// maxJobs 保持するJobの最大件数(超えた場合は古い完了済みのJobから削除します)
+	maxJobs = 100
+)
+
+// ErrJobNotFound "job is not found"
+var ErrJobNotFound = errors.New("job is not found")
+
+// DataUsecase Interface
+type DataUsecase interface {
+	Import(modelID string, request domain.DataImportRequest, body io.Reader, size int64) (domain.DataJob, int, error)
+	Export(modelID string, request domain.DataExportRequest, w io.Writer) (domain.DataJob, int, error)
+	StartExport(modelID string, request domain.DataExportRequest) (domain.DataJob, int, error)
+	GetJobs() []domain.DataJob
+	GetJob(id string) (domain.DataJob, int, error)
+	OpenJobFile(id string) (io.ReadCloser, string, domain.DataJob, int, error)
+}
+
+// job 実行中、実行済みのJob
+type job struct {
+	domain.DataJob
+	// file 出力のJobの出力先の一時ファイル
+	file string
+	// fileName ダウンロード時のファイル名
+	fileName string
+}
+
+type dataUsecase struct {
+	modelRepo     _modelRepository.ModelRepository
+	apiserverRepo _apiserverRepository.APIServerRepository
+	mu            sync.Mutex
+	jobs          map[string]*job
+}
+
+// NewDataUsecase DataUsecaseインターフェイスを表すオブジェクトを作成します
+// Jobはメモリ上に保持するため、再起動すると失われます
+func NewDataUsecase(modelRepo _modelRepository.ModelRepository, apiserverRepo _apiserverRepository.APIServerRepository) DataUsecase {
+	return &dataUsecase{
+		modelRepo:     modelRepo,
+		apiserverRepo: apiserverRepo,
+		jobs:          map[string]*job{},
+	}
+}
+
+// Import ドキュメントをModelのコレクションに取り込みます(Keyが同じドキュメントは上書きします)
+// 非同期の場合はbodyを一時ファイルに保存してから、Jobを作成して返却します
+func (u *dataUsecase) Import(modelID string, request domain.DataImportRequest, body io.Reader, size int64) (domain.DataJob, int, error) {
+	model, schema, status, err := u.getModel(modelID)
+	if err != nil {
+		return domain.DataJob{}, status, err
+	}
+	if err := dataio.ValidateFormat(request.Format); err != nil {
+		return domain.DataJob{}, http.StatusBadRequest, err
+	}
+	if schema.Key == "" {
+		return domain.DataJob{}, http.StatusBadRequest, errors.New("keys is not specified")
+	}
+
+	j := u.newJob(domain.DataJobImport, model.ID, request.Format)
+	if !request.Async {
+		u.runImport(j, model, schema, request, body, size)
+		result := u.snapshot(j)
+		if result.Status == domain.DataJobFailed {
+			return result, http.StatusBadRequest, errors.New(result.Error)
+		}
+		return result, http.StatusOK, nil
+	}
+
+	file, err := ioutil.TempFile("", "api-creator-import-")
+	if err != nil {
+		u.finish(j, err)
+		return u.snapshot(j), http.StatusInternalServerError, err
+	}
+	size, err = io.Copy(file, body)
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		u.finish(j, err)
+		return u.snapshot(j), http.StatusBadRequest, err
+	}
+
+	go func() {
+		defer os.Remove(file.Name())
+		defer file.Close()
+		u.runImport(j, model, schema, request, file, size)
+	}()
+	return u.snapshot(j), http.StatusAccepted, nil
+}
+
+// runImport ドキュメントを1件ずつ検証し、コレクションに取り込みます
+func (u *dataUsecase) runImport(j *job, model domain.Model, schema *dataio.Schema, request domain.DataImportRequest, body io.Reader, size int64) {
+	counter := &countingReader{reader: body}
+	reader, _ := dataio.NewReader(request.Format, counter)
+	collectionName := model.GetCollectionName()
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if rowErr, ok := err.(*dataio.RowError); ok {
+			u.update(j, counter.read, size, func(result *domain.DataJob) {
+				addRowError(result, rowErr.Row, rowErr.Err)
+			})
+			continue
+		}
+		if err != nil {
+			u.finish(j, err)
+			return
+		}
+
+		created, err := u.importRecord(collectionName, model, schema, request.Mapping, record)
+		u.update(j, counter.read, size, func(result *domain.DataJob) {
+			if err != nil {
+				addRowError(result, record.Row, err)
+			} else if created {
+				result.Created++
+			} else {
+				result.Updated++
+			}
+		})
+	}
+	u.finish(j, nil)
+}
+
+// importRecord 1件のドキュメントを型変換、検証してコレクションに取り込みます。作成した場合はtrueを返却します
+func (u *dataUsecase) importRecord(collectionName string, model domain.Model, schema *dataio.Schema, mapping map[string]string, record dataio.Record) (bool, error) {
+	document, err := schema.Coerce(dataio.Map(record.Values, mapping))
+	if err != nil {
+		return false, err
+	}
+	if _, ok := document[schema.Key]; !ok {
+		return false, fmt.Errorf("key %s is not found", schema.Key)
+	}
+
+	b, err := json.Marshal(document)
+	if err != nil {
+		return false, err
+	}
+	if err := model.ValidateDocument(b); err != nil {
+		return false, err
+	}
+
+	var keyDocument bson.M
+	if err := bson.UnmarshalExtJSON(b, false, &keyDocument); err != nil {
+		return false, err
+	}
+	if _, status, _ := u.apiserverRepo.Get(collectionName, schema.Key, keyDocument[schema.Key]); status == http.StatusNotFound {
+		_, _, err := u.apiserverRepo.Create(collectionName, schema.Key, b)
+		return true, err
+	}
+	_, _, err = u.apiserverRepo.Update(collectionName, schema.Key, b)
+	return false, err
+}
+
+// Export Modelのコレクションのドキュメントをwに出力します
+// wへの書き込みは、Modelと条件の検証が済んでから開始します
+func (u *dataUsecase) Export(modelID string, request domain.DataExportRequest, w io.Writer) (domain.DataJob, int, error) {
+	model, schema, status, err := u.getModel(modelID)
+	if err != nil {
+		return domain.DataJob{}, status, err
+	}
+	filter, columns, status, err := exportOptions(schema, request)
+	if err != nil {
+		return domain.DataJob{}, status, err
+	}
+
+	j := u.newJob(domain.DataJobExport, model.ID, request.Format)
+	status, err = u.runExport(j, model, filter, columns, 0, w)
+	return u.snapshot(j), status, err
+}
+
+// StartExport 一時ファイルに出力するJobを作成し、非同期で出力します
+func (u *dataUsecase) StartExport(modelID string, request domain.DataExportRequest) (domain.DataJob, int, error) {
+	model, schema, status, err := u.getModel(modelID)
+	if err != nil {
+		return domain.DataJob{}, status, err
+	}
+	filter, columns, status, err := exportOptions(schema, request)
+	if err != nil {
+		return domain.DataJob{}, status, err
+	}
+	total, status, err := u.apiserverRepo.Count(model.GetCollectionName(), filter)
+	if err != nil {
+		return domain.DataJob{}, status, err
+	}
+
+	file, err := ioutil.TempFile("", "api-creator-export-")
+	if err != nil {
+		return domain.DataJob{}, http.StatusInternalServerError, err
+	}
+	j := u.newJob(domain.DataJobExport, model.ID, request.Format)
+	u.mu.Lock()
+	j.file = file.Name()
+	j.fileName = model.GetCollectionName() + "." + request.Format
+	u.mu.Unlock()
+
+	go func() {
+		defer file.Close()
+		u.runExport(j, model, filter, columns, total, file)
+	}()
+	return u.snapshot(j), http.StatusAccepted, nil
+}
+
+// runExport ドキュメントを1件ずつ出力します。totalが0の場合は進捗を算出しません
+func (u *dataUsecase) runExport(j *job, model domain.Model, filter map[string]interface{}, columns []string, total int64, w io.Writer) (int, error) {
+	writer, err := dataio.NewWriter(j.Format, w, columns)
+	if err != nil {
+		u.finish(j, err)
+		return http.StatusBadRequest, err
+	}
+
+	status, err := u.apiserverRepo.Each(model.GetCollectionName(), filter, func(document bson.M) error {
+		values, err := toJSONMap(document)
+		if err != nil {
+			return err
+		}
+		if err := writer.Write(values); err != nil {
+			return err
+		}
+		u.update(j, 0, 0, func(result *domain.DataJob) {
+			if total > 0 {
+				result.Progress = float64(result.Processed) / float64(total)
+			}
+		})
+		return nil
+	})
+	if err == nil {
+		err = writer.Close()
+	}
+	u.finish(j, err)
+	if err != nil && status < http.StatusBadRequest {
+		status = http.StatusInternalServerError
+	}
+	return status, err
+}
+
+// GetJobs Jobを作成日時の新しい順に取得します
+func (u *dataUsecase) GetJobs() []domain.DataJob {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	jobs := make([]domain.DataJob, 0, len(u.jobs))
+	for _, j := range u.jobs {
+		jobs = append(jobs, copyJob(j))
+	}
+	sort.Slice(jobs, func(i, k int) bool { return jobs[i].CreatedAt.After(jobs[k].CreatedAt) })
+	return jobs
+}
+
+// GetJob Jobを取得します
+func (u *dataUsecase) GetJob(id string) (domain.DataJob, int, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	j, ok := u.jobs[id]
+	if !ok {
+		return domain.DataJob{}, http.StatusNotFound, ErrJobNotFound
+	}
+	return copyJob(j), http.StatusOK, nil
+}
+
+// OpenJobFile 完了した出力のJobのファイルと、ダウンロード時のファイル名を返却します
+func (u *dataUsecase) OpenJobFile(id string) (io.ReadCloser, string, domain.DataJob, int, error) {
+	u.mu.Lock()
+	j, ok := u.jobs[id]
+	if !ok || j.file == "" {
+		u.mu.Unlock()
+		return nil, "", domain.DataJob{}, http.StatusNotFound, ErrJobNotFound
+	}
+	result, path, fileName := copyJob(j), j.file, j.fileName
+	u.mu.Unlock()
+
+	switch result.Status {
+	case domain.DataJobRunning:
+		return nil, "", result, http.StatusConflict, errors.New("job is running")
+	case domain.DataJobFailed:
+		return nil, "", result, http.StatusConflict, errors.New("job is failed: " + result.Error)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", result, http.StatusInternalServerError, err
+	}
+	return file, fileName, result, http.StatusOK, nil
+}
+
+// getModel ModelとSchemaの項目を取得します
+func (u *dataUsecase) getModel(modelID string) (domain.Model, *dataio.Schema, int, error) {
+	model, err := u.modelRepo.GetByID(modelID)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return model, nil, http.StatusNotFound, err
+		}
+		return model, nil, http.StatusInternalServerError, err
+	}
+	schema, err := dataio.ParseSchema(model.Schema)
+	if err != nil {
+		return model, nil, http.StatusBadRequest, err
+	}
+	return model, schema, http.StatusOK, nil
+}
+
+// exportOptions 出力の条件(値はSchemaの型に変換します)と、CSVに出力する項目を取得します
+func exportOptions(schema *dataio.Schema, request domain.DataExportRequest) (map[string]interface{}, []string, int, error) {
+	if err := dataio.ValidateFormat(request.Format); err != nil {
+		return nil, nil, http.StatusBadRequest, err
+	}
+
+	values := map[string]interface{}{}
+	for name, value := range request.Filter {
+		values[name] = value
+	}
+	filter, err := schema.Coerce(values)
+	if err != nil {
+		return nil, nil, http.StatusBadRequest, fmt.Errorf("filter %s", err.Error())
+	}
+
+	columns := request.Columns
+	if len(columns) == 0 {
+		columns = schema.Properties
+	}
+	return filter, columns, http.StatusOK, nil
+}
+
+// newJob 実行中のJobを作成して保持します
+func (u *dataUsecase) newJob(jobType string, modelID string, format string) *job {
+	id, _ := uuid.NewRandom()
+	j := &job{DataJob: domain.DataJob{
+		ID:        id.String(),
+		Type:      jobType,
+		ModelID:   modelID,
+		Format:    format,
+		Status:    domain.DataJobRunning,
+		Errors:    []domain.DataRowError{},
+		CreatedAt: time.Now(),
+	}}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.jobs[j.ID] = j
+	u.evict()
+	return j
+}
+
+// evict 保持するJobの件数を超えた場合、古い完了済みのJobと出力したファイルを削除します
+func (u *dataUsecase) evict() {
+	for len(u.jobs) > maxJobs {
+		var oldest *job
+		for _, j := range u.jobs {
+			if j.Status != domain.DataJobRunning && (oldest == nil || j.CreatedAt.Before(oldest.CreatedAt)) {
+				oldest = j
+			}
+		}
+		if oldest == nil {
+			return
+		}
+		if oldest.file != "" {
+			os.Remove(oldest.file)
+		}
+		delete(u.jobs, oldest.ID)
+	}
+}
+
+// update 1件処理するごとにJobの件数と進捗を更新します。取り込みの場合は読み込んだバイト数から進捗を算出します
+func (u *dataUsecase) update(j *job, read int64, size int64, fn func(result *domain.DataJob)) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	j.Processed++
+	if size > 0 {
+		j.Progress = float64(read) / float64(size)
+	}
+	fn(&j.DataJob)
+}
+
+// finish Jobを終了します。errがある場合は失敗とします
+func (u *dataUsecase) finish(j *job, err error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	now := time.Now()
+	j.FinishedAt = &now
+	if err != nil {
+		j.Status = domain.DataJobFailed
+		j.Error = err.Error()
+		log.Println(err.Error())
+		return
+	}
+	j.Status = domain.DataJobCompleted
+	j.Progress = 1
+}
+
+// snapshot Jobの現在の状態を取得します
+func (u *dataUsecase) snapshot(j *job) domain.DataJob {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return copyJob(j)
+}
+
+// copyJob 実行中のJobの更新の影響を受けないよう、Jobを複製します
+func copyJob(j *job) domain.DataJob {
+	result := j.DataJob
+	result.Errors = append([]domain.DataRowError{}, j.Errors...)
+	return result
+}
+
+// addRowError 行のエラーを追加します
+func addRowError(result *domain.DataJob, row int, err error) {
+	result.Failed++
+	if len(result.Errors) < maxRowErrors {
+		result.Errors = append(result.Errors, domain.DataRowError{Row: row, Error: err.Error()})
+	}
+}
+
+// toJSONMap コレクションのドキュメントをJSONと同じ値の型に変換します(数値はjson.Numberとします)
+func toJSONMap(document bson.M) (map[string]interface{}, error) {
+	b, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var values map[string]interface{}
+	err = decoder.Decode(&values)
+	return values, err
+}
+
+// countingReader 読み込んだバイト数を数えます
+type countingReader struct {
+	reader io.Reader
+	read   int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+	return n, err
+}
@@ -0,0 +1,215 @@
+package usecase_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/data/usecase"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testSchema = `{
+	"type": "object",
+	"keys": ["id"],
+	"properties": {
+		"id": {"type": "integer"},
+		"name": {"type": "string"},
+		"age": {"type": "integer", "minimum": 0}
+	},
+	"required": ["id", "name"]
+}`
+
+func newMockModel() domain.Model {
+	modelId, _ := uuid.NewRandom()
+
+	mockModel := domain.Model{}
+	mockModel.ID = modelId.String()
+	mockModel.Name = "User"
+	mockModel.Schema = testSchema
+	mockModel.CollectionName = modelId.String()
+	return mockModel
+}
+
+// waitJob Jobが完了するまで待機します
+func waitJob(t *testing.T, u usecase.DataUsecase, id string) domain.DataJob {
+	for i := 0; i < 100; i++ {
+		job, status, err := u.GetJob(id)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		if job.Status != domain.DataJobRunning {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("job is not finished")
+	return domain.DataJob{}
+}
+
+func TestImport(t *testing.T) {
+	mockModel := newMockModel()
+	csv := "ID,Name,Age,Memo\n1,foo,20,x\n2,bar,,y\nabc,baz,,\n3,qux,-1,\n,quux,,\n"
+	request := domain.DataImportRequest{
+		Format:  domain.DataFormatCSV,
+		Mapping: map[string]string{"ID": "id", "Name": "name", "Age": "age", "Memo": ""},
+	}
+
+	t.Run("test1", func(t *testing.T) {
+		mockModelRepo := new(mocks.ModelRepository)
+		mockAPIServerRepo := new(mocks.APIServerRepository)
+		mockModelRepo.On("GetByID", mockModel.ID).Return(mockModel, nil).Once()
+		mockAPIServerRepo.On("Get", mockModel.CollectionName, "id", int32(1)).Return("", http.StatusNotFound, errors.New("record not found")).Once()
+		mockAPIServerRepo.On("Create", mockModel.CollectionName, "id", []byte(`{"age":20,"id":1,"name":"foo"}`)).Return("", http.StatusCreated, nil).Once()
+		mockAPIServerRepo.On("Get", mockModel.CollectionName, "id", int32(2)).Return(bson.M{"id": 2}, http.StatusOK, nil).Once()
+		mockAPIServerRepo.On("Update", mockModel.CollectionName, "id", []byte(`{"id":2,"name":"bar"}`)).Return("", http.StatusOK, nil).Once()
+		u := usecase.NewDataUsecase(mockModelRepo, mockAPIServerRepo)
+
+		job, status, err := u.Import(mockModel.ID, request, strings.NewReader(csv), int64(len(csv)))
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, domain.DataJobCompleted, job.Status)
+		assert.Equal(t, 5, job.Processed)
+		assert.Equal(t, 1, job.Created)
+		assert.Equal(t, 1, job.Updated)
+		assert.Equal(t, 3, job.Failed)
+		assert.Equal(t, float64(1), job.Progress)
+		assert.Equal(t, 4, job.Errors[0].Row)
+		assert.Equal(t, `id: cannot convert "abc" to integer`, job.Errors[0].Error)
+		assert.Equal(t, 5, job.Errors[1].Row)
+		assert.Equal(t, "key id is not found", job.Errors[2].Error)
+
+		mockAPIServerRepo.AssertExpectations(t)
+	})
+	t.Run("非同期", func(t *testing.T) {
+		mockModelRepo := new(mocks.ModelRepository)
+		mockAPIServerRepo := new(mocks.APIServerRepository)
+		mockModelRepo.On("GetByID", mockModel.ID).Return(mockModel, nil).Once()
+		mockAPIServerRepo.On("Get", mockModel.CollectionName, "id", mock.Anything).Return("", http.StatusNotFound, errors.New("record not found"))
+		mockAPIServerRepo.On("Create", mockModel.CollectionName, "id", mock.Anything).Return("", http.StatusCreated, nil)
+		u := usecase.NewDataUsecase(mockModelRepo, mockAPIServerRepo)
+
+		asyncRequest := domain.DataImportRequest{Format: domain.DataFormatNDJSON, Async: true}
+		job, status, err := u.Import(mockModel.ID, asyncRequest, strings.NewReader("{\"id\": 1, \"name\": \"foo\"}\n{\"id\": \"2\", \"name\": \"bar\"}\n"), -1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, status)
+		job = waitJob(t, u, job.ID)
+		assert.Equal(t, domain.DataJobCompleted, job.Status)
+		assert.Equal(t, 2, job.Created)
+		assert.Len(t, u.GetJobs(), 1)
+	})
+	t.Run("JSONの構文エラー", func(t *testing.T) {
+		mockModelRepo := new(mocks.ModelRepository)
+		mockAPIServerRepo := new(mocks.APIServerRepository)
+		mockModelRepo.On("GetByID", mockModel.ID).Return(mockModel, nil).Once()
+		u := usecase.NewDataUsecase(mockModelRepo, mockAPIServerRepo)
+
+		job, status, err := u.Import(mockModel.ID, domain.DataImportRequest{Format: domain.DataFormatJSON}, strings.NewReader(`[{"id": 1`), -1)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Equal(t, domain.DataJobFailed, job.Status)
+	})
+	t.Run("対応していない形式", func(t *testing.T) {
+		mockModelRepo := new(mocks.ModelRepository)
+		mockModelRepo.On("GetByID", mockModel.ID).Return(mockModel, nil).Once()
+		u := usecase.NewDataUsecase(mockModelRepo, new(mocks.APIServerRepository))
+
+		_, status, err := u.Import(mockModel.ID, domain.DataImportRequest{Format: "xlsx"}, strings.NewReader(""), 0)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+	t.Run("Modelが存在しない", func(t *testing.T) {
+		mockModelRepo := new(mocks.ModelRepository)
+		mockModelRepo.On("GetByID", mockModel.ID).Return(domain.Model{}, gorm.ErrRecordNotFound).Once()
+		u := usecase.NewDataUsecase(mockModelRepo, new(mocks.APIServerRepository))
+
+		_, status, err := u.Import(mockModel.ID, request, strings.NewReader(csv), 0)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+}
+
+func TestExport(t *testing.T) {
+	mockModel := newMockModel()
+	documents := []bson.M{
+		{"id": int32(1), "name": "foo", "age": int32(20)},
+		{"id": int32(2), "name": "bar, baz"},
+	}
+
+	t.Run("test1", func(t *testing.T) {
+		mockModelRepo := new(mocks.ModelRepository)
+		mockAPIServerRepo := new(mocks.APIServerRepository)
+		mockModelRepo.On("GetByID", mockModel.ID).Return(mockModel, nil).Once()
+		mockAPIServerRepo.On("Each", mockModel.CollectionName, map[string]interface{}{"age": int64(20)}).Return(documents[:1], http.StatusOK, nil).Once()
+		u := usecase.NewDataUsecase(mockModelRepo, mockAPIServerRepo)
+
+		var buf bytes.Buffer
+		request := domain.DataExportRequest{Format: domain.DataFormatCSV, Filter: map[string]string{"age": "20"}}
+		job, status, err := u.Export(mockModel.ID, request, &buf)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, 1, job.Processed)
+		assert.Equal(t, "id,age,name\n1,20,foo\n", buf.String())
+	})
+	t.Run("条件の型が正しくない", func(t *testing.T) {
+		mockModelRepo := new(mocks.ModelRepository)
+		mockModelRepo.On("GetByID", mockModel.ID).Return(mockModel, nil).Once()
+		u := usecase.NewDataUsecase(mockModelRepo, new(mocks.APIServerRepository))
+
+		var buf bytes.Buffer
+		request := domain.DataExportRequest{Format: domain.DataFormatCSV, Filter: map[string]string{"age": "x"}}
+		_, status, err := u.Export(mockModel.ID, request, &buf)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Equal(t, 0, buf.Len())
+	})
+	t.Run("非同期", func(t *testing.T) {
+		mockModelRepo := new(mocks.ModelRepository)
+		mockAPIServerRepo := new(mocks.APIServerRepository)
+		mockModelRepo.On("GetByID", mockModel.ID).Return(mockModel, nil).Once()
+		mockAPIServerRepo.On("Count", mockModel.CollectionName, map[string]interface{}{}).Return(int64(2), http.StatusOK, nil).Once()
+		mockAPIServerRepo.On("Each", mockModel.CollectionName, map[string]interface{}{}).Return(documents, http.StatusOK, nil).Once()
+		u := usecase.NewDataUsecase(mockModelRepo, mockAPIServerRepo)
+
+		job, status, err := u.StartExport(mockModel.ID, domain.DataExportRequest{Format: domain.DataFormatNDJSON})
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, status)
+		job = waitJob(t, u, job.ID)
+		assert.Equal(t, domain.DataJobCompleted, job.Status)
+		assert.Equal(t, 2, job.Processed)
+
+		file, fileName, _, status, err := u.OpenJobFile(job.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, mockModel.CollectionName+".ndjson", fileName)
+		data, _ := ioutil.ReadAll(file)
+		file.Close()
+		assert.Equal(t, "{\"age\":20,\"id\":1,\"name\":\"foo\"}\n{\"id\":2,\"name\":\"bar, baz\"}\n", string(data))
+	})
+	t.Run("Jobが存在しない", func(t *testing.T) {
+		u := usecase.NewDataUsecase(new(mocks.ModelRepository), new(mocks.APIServerRepository))
+
+		_, _, _, status, err := u.OpenJobFile("unknown")
+
+		assert.Equal(t, usecase.ErrJobNotFound, err)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+}
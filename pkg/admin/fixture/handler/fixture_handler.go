@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/fixture/usecase"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+// FixtureHandler FixtureAPIに対するリクエストハンドラ
+type FixtureHandler struct {
+	usecase usecase.FixtureUsecase
+}
+
+// NewFixtureHandler FixtureHandlerを作成します
+func NewFixtureHandler(r *gin.RouterGroup, u usecase.FixtureUsecase) {
+	handler := &FixtureHandler{
+		usecase: u,
+	}
+	fixtureRoutes := r.Group("/fixtures")
+	{
+		fixtureRoutes.GET("", handler.GetAll)
+		fixtureRoutes.GET("/:id", handler.GetByID)
+		fixtureRoutes.POST("", handler.Create)
+		fixtureRoutes.PUT("", handler.Update)
+		fixtureRoutes.DELETE("/:id", handler.Delete)
+		fixtureRoutes.POST("/:id/load", handler.Load)
+		fixtureRoutes.POST("/:id/reset", handler.Reset)
+	}
+	// modelに紐づいたfixtureのルート
+	r.GET("/models/:id/fixtures", handler.GetListByModelID)
+	r.POST("/models/:id/snapshot", handler.Snapshot)
+}
+
+// GetAll 複数のFixtureを取得します
+func (h *FixtureHandler) GetAll(c *gin.Context) {
+	result, err := h.usecase.GetAll()
+
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			c.JSON(http.StatusNotFound, domain.ErrorResponse{Error: err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Error: err.Error()})
+		}
+		log.Println(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetByID Fixtureを1件取得します
+func (h *FixtureHandler) GetByID(c *gin.Context) {
+	id := c.Param("id")
+
+	result, err := h.usecase.GetByID(id)
+
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			c.JSON(http.StatusNotFound, domain.ErrorResponse{Error: err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Error: err.Error()})
+		}
+		log.Println(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetListByModelID FixtureをModelIDで複数取得します
+func (h *FixtureHandler) GetListByModelID(c *gin.Context) {
+	modelID := c.Param("id")
+
+	result, err := h.usecase.GetListByModelID(modelID)
+
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			c.JSON(http.StatusNotFound, domain.ErrorResponse{Error: err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Error: err.Error()})
+		}
+		log.Println(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Create Fixtureを作成します
+func (h *FixtureHandler) Create(c *gin.Context) {
+	var fixture domain.Fixture
+	c.BindJSON(&fixture)
+
+	status, id, err := h.usecase.Create(fixture)
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+	c.JSON(http.StatusCreated, domain.CreatedResponse{ID: id})
+}
+
+// Update Fixtureを更新します
+func (h *FixtureHandler) Update(c *gin.Context) {
+	var fixture domain.Fixture
+	c.BindJSON(&fixture)
+
+	status, err := h.usecase.Update(fixture)
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, nil)
+}
+
+// Delete Fixtureを削除します
+func (h *FixtureHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	err := h.usecase.Delete(id)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			c.JSON(http.StatusNotFound, domain.ErrorResponse{Error: err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Error: err.Error()})
+		}
+		log.Println(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// Load Fixtureのドキュメントをコレクションに投入します
+func (h *FixtureHandler) Load(c *gin.Context) {
+	id := c.Param("id")
+
+	status, result, err := h.usecase.Load(id)
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Reset コレクションをFixtureの状態に戻します
+func (h *FixtureHandler) Reset(c *gin.Context) {
+	id := c.Param("id")
+
+	status, result, err := h.usecase.Reset(id)
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Snapshot 現在のコレクションの内容からFixtureを作成します
+func (h *FixtureHandler) Snapshot(c *gin.Context) {
+	modelID := c.Param("id")
+
+	var request domain.SnapshotRequest
+	c.BindJSON(&request)
+
+	status, id, err := h.usecase.Snapshot(modelID, request)
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, domain.CreatedResponse{ID: id})
+}
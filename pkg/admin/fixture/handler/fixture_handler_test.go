@@ -0,0 +1,129 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/fixture/handler"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+	"github.com/google/uuid"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func newMockRouter() (*gin.Engine, *gin.RouterGroup) {
+	router := gin.Default()
+	apiV1 := router.Group("api/v1")
+
+	return router, apiV1
+}
+
+func newMockFixture() domain.Fixture {
+	fixtureId, _ := uuid.NewRandom()
+	modelId, _ := uuid.NewRandom()
+
+	mockFixture := domain.Fixture{}
+	mockFixture.ID = fixtureId.String()
+	mockFixture.ModelID = modelId.String()
+	mockFixture.Name = "name"
+	mockFixture.Data = `[{"id": "1"}]`
+	return mockFixture
+}
+
+func TestGetListByModelID(t *testing.T) {
+	mockFixture := newMockFixture()
+
+	gin.SetMode(gin.TestMode)
+
+	mockFixtureUsecase := new(mocks.FixtureUsecase)
+	mockFixtureUsecase.On("GetListByModelID", mockFixture.ModelID).Return([]domain.Fixture{mockFixture}, nil).Once()
+
+	router, rg := newMockRouter()
+	handler.NewFixtureHandler(rg, mockFixtureUsecase)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/models/"+mockFixture.ModelID+"/fixtures", nil)
+	router.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Code)
+}
+
+func TestCreate(t *testing.T) {
+	mockFixture := newMockFixture()
+
+	gin.SetMode(gin.TestMode)
+
+	mockFixtureUsecase := new(mocks.FixtureUsecase)
+	mockFixtureUsecase.On("Create", mockFixture).Return(nil).Once()
+
+	router, rg := newMockRouter()
+	handler.NewFixtureHandler(rg, mockFixtureUsecase)
+
+	fixtureJSON, _ := json.Marshal(mockFixture)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/fixtures", bytes.NewReader(fixtureJSON))
+	router.ServeHTTP(res, req)
+
+	assert.Equal(t, 201, res.Code)
+}
+
+func TestLoad(t *testing.T) {
+	mockFixture := newMockFixture()
+
+	gin.SetMode(gin.TestMode)
+
+	mockFixtureUsecase := new(mocks.FixtureUsecase)
+	mockFixtureUsecase.On("Load", mockFixture.ID).Return(domain.FixtureLoadResult{Created: 1}, nil).Once()
+
+	router, rg := newMockRouter()
+	handler.NewFixtureHandler(rg, mockFixtureUsecase)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/fixtures/"+mockFixture.ID+"/load", nil)
+	router.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Code)
+}
+
+func TestReset(t *testing.T) {
+	mockFixture := newMockFixture()
+
+	gin.SetMode(gin.TestMode)
+
+	mockFixtureUsecase := new(mocks.FixtureUsecase)
+	mockFixtureUsecase.On("Reset", mockFixture.ID).Return(domain.FixtureLoadResult{Created: 1}, nil).Once()
+
+	router, rg := newMockRouter()
+	handler.NewFixtureHandler(rg, mockFixtureUsecase)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/fixtures/"+mockFixture.ID+"/reset", nil)
+	router.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Code)
+}
+
+func TestSnapshot(t *testing.T) {
+	mockFixture := newMockFixture()
+	request := domain.SnapshotRequest{Name: "snapshot"}
+
+	gin.SetMode(gin.TestMode)
+
+	mockFixtureUsecase := new(mocks.FixtureUsecase)
+	mockFixtureUsecase.On("Snapshot", mockFixture.ModelID, request).Return(mockFixture.ID, nil).Once()
+
+	router, rg := newMockRouter()
+	handler.NewFixtureHandler(rg, mockFixtureUsecase)
+
+	requestJSON, _ := json.Marshal(request)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/models/"+mockFixture.ModelID+"/snapshot", bytes.NewReader(requestJSON))
+	router.ServeHTTP(res, req)
+
+	assert.Equal(t, 201, res.Code)
+}
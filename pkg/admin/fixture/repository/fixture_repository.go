@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	"github.com/jinzhu/gorm"
+)
+
+// FixtureRepository Interface
+type FixtureRepository interface {
+	GetAll() ([]domain.Fixture, error)
+	GetByID(id string) (domain.Fixture, error)
+	GetListByModelID(modelID string) ([]domain.Fixture, error)
+	Create(fixture domain.Fixture) (string, error)
+	Update(fixture domain.Fixture) error
+	Delete(id string) error
+}
+
+type fixtureRepository struct {
+	db *gorm.DB
+}
+
+// NewFixtureRepository FixtureRepositoryインターフェイスを表すオブジェクトを作成します
+func NewFixtureRepository(db *gorm.DB) FixtureRepository {
+	return &fixtureRepository{
+		db: db,
+	}
+}
+
+// GetAll すべてのFixtureを取得します
+func (r *fixtureRepository) GetAll() ([]domain.Fixture, error) {
+	fixtures := []domain.Fixture{}
+	err := r.db.Find(&fixtures).Error
+
+	return fixtures, err
+}
+
+// GetByID Fixtureを1件取得します
+func (r *fixtureRepository) GetByID(id string) (domain.Fixture, error) {
+	fixture := domain.Fixture{}
+	err := r.db.Where("id = ?", id).First(&fixture).Error
+
+	return fixture, err
+}
+
+// GetListByModelID FixtureをModelIDで複数取得します
+func (r *fixtureRepository) GetListByModelID(modelID string) ([]domain.Fixture, error) {
+	fixtures := []domain.Fixture{}
+	err := r.db.Where("model_id = ?", modelID).Find(&fixtures).Error
+
+	return fixtures, err
+}
+
+// Create Fixtureを追加します
+func (r *fixtureRepository) Create(fixture domain.Fixture) (string, error) {
+	err := r.db.Create(&fixture).Error
+	id := fixture.ID
+	return id, err
+}
+
+// Update Fixtureを更新します
+func (r *fixtureRepository) Update(fixture domain.Fixture) error {
+	targetFixture := domain.Fixture{}
+
+	err := r.db.Where("id = ?", fixture.ID).First(&targetFixture).Error
+	if err != nil {
+		return err
+	}
+
+	return r.db.Save(&fixture).Error
+}
+
+// Delete Fixtureを削除します
+func (r *fixtureRepository) Delete(id string) error {
+	fixture := domain.Fixture{}
+
+	fixture.ID = id
+	result := r.db.Delete(&fixture)
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return result.Error
+}
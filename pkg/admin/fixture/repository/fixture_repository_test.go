@@ -0,0 +1,115 @@
+package repository_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/fixture/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/google/uuid"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func setUpMockDB() (sqlmock.Sqlmock, *gorm.DB) {
+	gorm.DefaultTableNameHandler = func(db *gorm.DB, defaultTableName string) string {
+		return strings.Replace(defaultTableName, "_data_table", "", 1)
+	}
+	d, mock, _ := sqlmock.New()
+	conn, _ := gorm.Open("mysql", d)
+
+	return mock, conn
+}
+
+func TestGetAll(t *testing.T) {
+	mock, db := setUpMockDB()
+	fixtureId, _ := uuid.NewRandom()
+	modelId, _ := uuid.NewRandom()
+
+	query := regexp.QuoteMeta("SELECT * FROM `fixtures`")
+	rows := sqlmock.NewRows([]string{"id", "model_id", "name", "description", "data", "created_at", "updated_at"}).
+		AddRow(fixtureId.String(), modelId.String(), "name", "description", "[]", time.Now(), time.Now())
+	mock.ExpectQuery(query).WillReturnRows(rows)
+
+	fixtureRepository := repository.NewFixtureRepository(db)
+
+	fixtures, err := fixtureRepository.GetAll()
+	assert.NoError(t, err)
+	assert.Len(t, fixtures, 1)
+}
+
+func TestGetByID(t *testing.T) {
+	mock, db := setUpMockDB()
+	fixtureId, _ := uuid.NewRandom()
+	modelId, _ := uuid.NewRandom()
+
+	query := regexp.QuoteMeta("SELECT * FROM `fixtures` WHERE (id = ?) ORDER BY `fixtures`.`id` ASC LIMIT 1")
+	rows := sqlmock.NewRows([]string{"id", "model_id", "name", "description", "data", "created_at", "updated_at"}).
+		AddRow(fixtureId.String(), modelId.String(), "name", "description", "[]", time.Now(), time.Now())
+	mock.ExpectQuery(query).WithArgs(fixtureId.String()).WillReturnRows(rows)
+
+	fixtureRepository := repository.NewFixtureRepository(db)
+
+	fixture, err := fixtureRepository.GetByID(fixtureId.String())
+	assert.NoError(t, err)
+	assert.Equal(t, fixtureId.String(), fixture.ID)
+}
+
+func TestGetListByModelID(t *testing.T) {
+	mock, db := setUpMockDB()
+	fixtureId, _ := uuid.NewRandom()
+	modelId, _ := uuid.NewRandom()
+
+	query := regexp.QuoteMeta("SELECT * FROM `fixtures` WHERE (model_id = ?)")
+	rows := sqlmock.NewRows([]string{"id", "model_id", "name", "description", "data", "created_at", "updated_at"}).
+		AddRow(fixtureId.String(), modelId.String(), "name", "description", "[]", time.Now(), time.Now())
+	mock.ExpectQuery(query).WithArgs(modelId.String()).WillReturnRows(rows)
+
+	fixtureRepository := repository.NewFixtureRepository(db)
+
+	fixtures, err := fixtureRepository.GetListByModelID(modelId.String())
+	assert.NoError(t, err)
+	assert.Len(t, fixtures, 1)
+}
+
+func TestCreate(t *testing.T) {
+	mock, db := setUpMockDB()
+	fixtureId, _ := uuid.NewRandom()
+
+	mockFixture := domain.Fixture{}
+	mockFixture.ID = fixtureId.String()
+	mockFixture.Name = "name"
+	mockFixture.Data = "[]"
+
+	mock.ExpectBegin()
+	query := regexp.QuoteMeta("INSERT INTO `fixtures` (`id`,`model_id`,`name`,`description`,`data`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?)")
+	mock.ExpectExec(query).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	fixtureRepository := repository.NewFixtureRepository(db)
+
+	id, err := fixtureRepository.Create(mockFixture)
+	assert.NoError(t, err)
+	assert.Equal(t, fixtureId.String(), id)
+}
+
+func TestDelete(t *testing.T) {
+	mock, db := setUpMockDB()
+	fixtureId, _ := uuid.NewRandom()
+
+	mock.ExpectBegin()
+	query := regexp.QuoteMeta("DELETE FROM `fixtures` WHERE `fixtures`.`id` = ?")
+	mock.ExpectExec(query).WithArgs(fixtureId.String()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	fixtureRepository := repository.NewFixtureRepository(db)
+
+	err := fixtureRepository.Delete(fixtureId.String())
+	assert.NoError(t, err)
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/memory"
+	"github.com/jinzhu/gorm"
+)
+
+type memoryFixtureRepository struct {
+	store *memory.Store
+}
+
+// NewMemoryFixtureRepository メモリ上のStoreを使用するFixtureRepositoryを作成します(開発用)
+func NewMemoryFixtureRepository(store *memory.Store) FixtureRepository {
+	return &memoryFixtureRepository{
+		store: store,
+	}
+}
+
+// GetAll すべてのFixtureを取得します
+func (r *memoryFixtureRepository) GetAll() ([]domain.Fixture, error) {
+	fixtures := []domain.Fixture{}
+	r.store.View(func(t *memory.Tables) {
+		fixtures = append(fixtures, t.Fixtures...)
+	})
+	return fixtures, nil
+}
+
+// GetByID Fixtureを1件取得します
+func (r *memoryFixtureRepository) GetByID(id string) (domain.Fixture, error) {
+	fixture := domain.Fixture{}
+	err := gorm.ErrRecordNotFound
+	r.store.View(func(t *memory.Tables) {
+		if i := indexOfFixture(t.Fixtures, id); i >= 0 {
+			fixture, err = t.Fixtures[i], nil
+		}
+	})
+	return fixture, err
+}
+
+// GetListByModelID FixtureをModelIDで複数取得します
+func (r *memoryFixtureRepository) GetListByModelID(modelID string) ([]domain.Fixture, error) {
+	fixtures := []domain.Fixture{}
+	r.store.View(func(t *memory.Tables) {
+		for _, f := range t.Fixtures {
+			if f.ModelID == modelID {
+				fixtures = append(fixtures, f)
+			}
+		}
+	})
+	return fixtures, nil
+}
+
+// Create Fixtureを追加します
+func (r *memoryFixtureRepository) Create(fixture domain.Fixture) (string, error) {
+	err := r.store.Update(func(t *memory.Tables) error {
+		if indexOfFixture(t.Fixtures, fixture.ID) >= 0 {
+			return memory.ErrDuplicateKey
+		}
+		memory.Created(&fixture.CommonColumn)
+		t.Fixtures = append(t.Fixtures, fixture)
+		return nil
+	})
+	return fixture.ID, err
+}
+
+// Update Fixtureを更新します
+func (r *memoryFixtureRepository) Update(fixture domain.Fixture) error {
+	return r.store.Update(func(t *memory.Tables) error {
+		i := indexOfFixture(t.Fixtures, fixture.ID)
+		if i < 0 {
+			return gorm.ErrRecordNotFound
+		}
+		memory.Saved(&fixture.CommonColumn, t.Fixtures[i].CommonColumn)
+		t.Fixtures[i] = fixture
+		return nil
+	})
+}
+
+// Delete Fixtureを削除します
+func (r *memoryFixtureRepository) Delete(id string) error {
+	return r.store.Update(func(t *memory.Tables) error {
+		i := indexOfFixture(t.Fixtures, id)
+		if i < 0 {
+			return gorm.ErrRecordNotFound
+		}
+		t.Fixtures = append(t.Fixtures[:i], t.Fixtures[i+1:]...)
+		return nil
+	})
+}
+
+// indexOfFixture IDが一致するFixtureの位置を返却します。存在しない場合は-1です
+func indexOfFixture(fixtures []domain.Fixture, id string) int {
+	for i := range fixtures {
+		if fixtures[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
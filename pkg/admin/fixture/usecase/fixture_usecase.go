@@ -0,0 +1,256 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	_fixtureRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/fixture/repository"
+	_modelRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/model/repository"
+	_apiserverRepository "github.com/Hajime3778/api-creator-backend/pkg/apiserver/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// FixtureUsecase Interface
+type FixtureUsecase interface {
+	GetAll() ([]domain.Fixture, error)
+	GetByID(id string) (domain.Fixture, error)
+	GetListByModelID(modelID string) ([]domain.Fixture, error)
+	Create(fixture domain.Fixture) (int, string, error)
+	Update(fixture domain.Fixture) (int, error)
+	Delete(id string) error
+	Load(id string) (int, domain.FixtureLoadResult, error)
+	Reset(id string) (int, domain.FixtureLoadResult, error)
+	Snapshot(modelID string, request domain.SnapshotRequest) (int, string, error)
+}
+
+type fixtureUsecase struct {
+	fixtureRepo   _fixtureRepository.FixtureRepository
+	modelRepo     _modelRepository.ModelRepository
+	apiserverRepo _apiserverRepository.APIServerRepository
+}
+
+// NewFixtureUsecase FixtureUsecaseインターフェイスを表すオブジェクトを作成します
+func NewFixtureUsecase(fixtureRepo _fixtureRepository.FixtureRepository, modelRepo _modelRepository.ModelRepository, apiserverRepo _apiserverRepository.APIServerRepository) FixtureUsecase {
+	return &fixtureUsecase{
+		fixtureRepo:   fixtureRepo,
+		modelRepo:     modelRepo,
+		apiserverRepo: apiserverRepo,
+	}
+}
+
+// GetAll 複数のFixtureを取得します
+func (u *fixtureUsecase) GetAll() ([]domain.Fixture, error) {
+	return u.fixtureRepo.GetAll()
+}
+
+// GetByID 1件のFixtureを取得します
+func (u *fixtureUsecase) GetByID(id string) (domain.Fixture, error) {
+	return u.fixtureRepo.GetByID(id)
+}
+
+// GetListByModelID FixtureをModelIDで複数取得します
+func (u *fixtureUsecase) GetListByModelID(modelID string) ([]domain.Fixture, error) {
+	return u.fixtureRepo.GetListByModelID(modelID)
+}
+
+// Create Fixtureを作成します
+func (u *fixtureUsecase) Create(fixture domain.Fixture) (int, string, error) {
+	if fixture.ID == "" {
+		id, _ := uuid.NewRandom()
+		fixture.ID = id.String()
+	}
+
+	if status, err := u.validate(fixture); err != nil {
+		return status, "", err
+	}
+
+	id, err := u.fixtureRepo.Create(fixture)
+	if err != nil {
+		return http.StatusInternalServerError, "", err
+	}
+	return http.StatusCreated, id, nil
+}
+
+// Update Fixtureを更新します
+func (u *fixtureUsecase) Update(fixture domain.Fixture) (int, error) {
+	if status, err := u.validate(fixture); err != nil {
+		return status, err
+	}
+
+	err := u.fixtureRepo.Update(fixture)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return http.StatusNotFound, err
+		}
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+// Delete Fixtureを削除します
+func (u *fixtureUsecase) Delete(id string) error {
+	return u.fixtureRepo.Delete(id)
+}
+
+// Load Fixtureのドキュメントをコレクションに投入します(Keyが同じドキュメントは上書きします)
+func (u *fixtureUsecase) Load(id string) (int, domain.FixtureLoadResult, error) {
+	fixture, model, status, err := u.getFixtureAndModel(id)
+	if err != nil {
+		return status, domain.FixtureLoadResult{}, err
+	}
+	return u.load(fixture, model, model.GetCollectionName())
+}
+
+// Reset コレクションを、Fixtureのドキュメントのみのコレクションに置き換えます
+// 一時的なコレクションに投入してから置き換えるため、投入中や投入に失敗した場合も、元のコレクションは変更されません
+func (u *fixtureUsecase) Reset(id string) (int, domain.FixtureLoadResult, error) {
+	fixture, model, status, err := u.getFixtureAndModel(id)
+	if err != nil {
+		return status, domain.FixtureLoadResult{}, err
+	}
+
+	collectionName := model.GetCollectionName()
+	temporary := collectionName + "_reset_" + strings.Replace(uuid.New().String(), "-", "", -1)[:8]
+	status, result, err := u.load(fixture, model, temporary)
+	if err != nil {
+		u.apiserverRepo.RemoveCollection(temporary)
+		return status, result, err
+	}
+
+	if status, err := u.apiserverRepo.ReplaceCollection(temporary, collectionName); err != nil {
+		u.apiserverRepo.RemoveCollection(temporary)
+		return status, domain.FixtureLoadResult{}, err
+	}
+	return http.StatusOK, result, nil
+}
+
+// Snapshot 現在のコレクションの内容から、Fixtureを作成します
+func (u *fixtureUsecase) Snapshot(modelID string, request domain.SnapshotRequest) (int, string, error) {
+	if request.Name == "" {
+		return http.StatusBadRequest, "", errors.New("name is required")
+	}
+
+	model, err := u.modelRepo.GetByID(modelID)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return http.StatusNotFound, "", err
+		}
+		return http.StatusInternalServerError, "", err
+	}
+
+	documents, status, err := u.apiserverRepo.GetList(model.GetCollectionName(), "", "")
+	if err != nil {
+		if status != http.StatusNotFound {
+			return status, "", err
+		}
+		// ドキュメントが存在しない場合は空のFixtureとする
+		documents = []interface{}{}
+	}
+
+	data, err := json.Marshal(documents)
+	if err != nil {
+		return http.StatusInternalServerError, "", err
+	}
+
+	id, _ := uuid.NewRandom()
+	fixture := domain.Fixture{
+		ID:          id.String(),
+		ModelID:     model.ID,
+		Name:        request.Name,
+		Description: request.Description,
+		Data:        string(data),
+	}
+
+	createdID, err := u.fixtureRepo.Create(fixture)
+	if err != nil {
+		return http.StatusInternalServerError, "", err
+	}
+	return http.StatusCreated, createdID, nil
+}
+
+// validate Fixtureが対象のModelに則っているか検証します
+func (u *fixtureUsecase) validate(fixture domain.Fixture) (int, error) {
+	if fixture.Name == "" {
+		return http.StatusBadRequest, errors.New("name is required")
+	}
+
+	model, err := u.modelRepo.GetByID(fixture.ModelID)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return http.StatusBadRequest, errors.New("model is not found")
+		}
+		return http.StatusInternalServerError, err
+	}
+
+	if err := fixture.Validate(model); err != nil {
+		return http.StatusBadRequest, err
+	}
+	return http.StatusOK, nil
+}
+
+// getFixtureAndModel FixtureとFixtureが対象とするModelを取得します
+func (u *fixtureUsecase) getFixtureAndModel(id string) (domain.Fixture, domain.Model, int, error) {
+	fixture, err := u.fixtureRepo.GetByID(id)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return fixture, domain.Model{}, http.StatusNotFound, err
+		}
+		return fixture, domain.Model{}, http.StatusInternalServerError, err
+	}
+
+	model, err := u.modelRepo.GetByID(fixture.ModelID)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return fixture, model, http.StatusNotFound, err
+		}
+		return fixture, model, http.StatusInternalServerError, err
+	}
+
+	// 登録後にModelのSchemaが変更されている可能性があるため、投入前に再度検証する
+	if err := fixture.Validate(model); err != nil {
+		return fixture, model, http.StatusBadRequest, err
+	}
+
+	return fixture, model, http.StatusOK, nil
+}
+
+// load Fixtureのドキュメントを1件ずつcollectionNameのコレクションに投入します
+func (u *fixtureUsecase) load(fixture domain.Fixture, model domain.Model, collectionName string) (int, domain.FixtureLoadResult, error) {
+	result := domain.FixtureLoadResult{}
+
+	documents, err := fixture.GetDocuments()
+	if err != nil {
+		return http.StatusBadRequest, result, err
+	}
+	keys, err := model.GetKeyNames()
+	if err != nil {
+		return http.StatusBadRequest, result, err
+	}
+
+	for _, document := range documents {
+		var b bson.M
+		if err := bson.UnmarshalExtJSON(document, false, &b); err != nil {
+			return http.StatusBadRequest, result, err
+		}
+
+		if _, status, _ := u.apiserverRepo.Get(collectionName, keys[0], b[keys[0]]); status == http.StatusNotFound {
+			if _, status, err := u.apiserverRepo.Create(collectionName, keys[0], document); err != nil {
+				return status, result, err
+			}
+			result.Created++
+			continue
+		}
+
+		if _, status, err := u.apiserverRepo.Update(collectionName, keys[0], document); err != nil {
+			return status, result, err
+		}
+		result.Updated++
+	}
+
+	return http.StatusOK, result, nil
+}
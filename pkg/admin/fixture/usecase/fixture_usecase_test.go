@@ -0,0 +1,241 @@
+package usecase_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/fixture/usecase"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testSchema = `{
+	"type": "object",
+	"keys": ["id"],
+	"properties": {
+		"id": {"type": "string"},
+		"name": {"type": "string"}
+	},
+	"required": ["id", "name"]
+}`
+
+func newMockModel() domain.Model {
+	modelId, _ := uuid.NewRandom()
+
+	mockModel := domain.Model{}
+	mockModel.ID = modelId.String()
+	mockModel.Name = "User"
+	mockModel.Schema = testSchema
+	mockModel.CollectionName = modelId.String()
+	return mockModel
+}
+
+func TestCreate(t *testing.T) {
+	fixtureId, _ := uuid.NewRandom()
+	mockModel := newMockModel()
+
+	mockFixture := domain.Fixture{}
+	mockFixture.ID = fixtureId.String()
+	mockFixture.ModelID = mockModel.ID
+	mockFixture.Name = "name"
+	mockFixture.Data = `[{"id": "1", "name": "foo"}, {"id": "2", "name": "bar"}]`
+
+	mockFixtureRepo := new(mocks.FixtureRepository)
+	mockModelRepo := new(mocks.ModelRepository)
+	mockAPIServerRepo := new(mocks.APIServerRepository)
+
+	t.Run("test1", func(t *testing.T) {
+		mockModelRepo.On("GetByID", mockModel.ID).Return(mockModel, nil).Once()
+		mockFixtureRepo.On("Create", mockFixture).Return(nil).Once()
+		usecase := usecase.NewFixtureUsecase(mockFixtureRepo, mockModelRepo, mockAPIServerRepo)
+
+		status, id, err := usecase.Create(mockFixture)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, status)
+		assert.Equal(t, mockFixture.ID, id)
+
+		mockFixtureRepo.AssertExpectations(t)
+	})
+	t.Run("JSON配列でない", func(t *testing.T) {
+		invalidFixture := mockFixture
+		invalidFixture.Data = `{"id": "1", "name": "foo"}`
+
+		mockModelRepo.On("GetByID", mockModel.ID).Return(mockModel, nil).Once()
+		usecase := usecase.NewFixtureUsecase(mockFixtureRepo, mockModelRepo, mockAPIServerRepo)
+
+		status, _, err := usecase.Create(invalidFixture)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+	t.Run("Schemaに則っていない", func(t *testing.T) {
+		invalidFixture := mockFixture
+		invalidFixture.Data = `[{"id": "1"}]`
+
+		mockModelRepo.On("GetByID", mockModel.ID).Return(mockModel, nil).Once()
+		usecase := usecase.NewFixtureUsecase(mockFixtureRepo, mockModelRepo, mockAPIServerRepo)
+
+		status, _, err := usecase.Create(invalidFixture)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+	t.Run("Keyが重複している", func(t *testing.T) {
+		invalidFixture := mockFixture
+		invalidFixture.Data = `[{"id": "1", "name": "foo"}, {"id": "1", "name": "bar"}]`
+
+		mockModelRepo.On("GetByID", mockModel.ID).Return(mockModel, nil).Once()
+		usecase := usecase.NewFixtureUsecase(mockFixtureRepo, mockModelRepo, mockAPIServerRepo)
+
+		status, _, err := usecase.Create(invalidFixture)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+	t.Run("Modelが存在しない", func(t *testing.T) {
+		mockModelRepo.On("GetByID", mockModel.ID).Return(domain.Model{}, gorm.ErrRecordNotFound).Once()
+		usecase := usecase.NewFixtureUsecase(mockFixtureRepo, mockModelRepo, mockAPIServerRepo)
+
+		status, _, err := usecase.Create(mockFixture)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+}
+
+func TestLoad(t *testing.T) {
+	fixtureId, _ := uuid.NewRandom()
+	mockModel := newMockModel()
+
+	mockFixture := domain.Fixture{}
+	mockFixture.ID = fixtureId.String()
+	mockFixture.ModelID = mockModel.ID
+	mockFixture.Name = "name"
+	mockFixture.Data = `[{"id": "1", "name": "foo"}, {"id": "2", "name": "bar"}]`
+
+	mockFixtureRepo := new(mocks.FixtureRepository)
+	mockModelRepo := new(mocks.ModelRepository)
+	mockAPIServerRepo := new(mocks.APIServerRepository)
+
+	t.Run("存在するKeyは更新、存在しないKeyは作成", func(t *testing.T) {
+		mockFixtureRepo.On("GetByID", mockFixture.ID).Return(mockFixture, nil).Once()
+		mockModelRepo.On("GetByID", mockModel.ID).Return(mockModel, nil).Once()
+		mockAPIServerRepo.On("Get", mockModel.CollectionName, "id", "1").Return("", http.StatusOK, nil).Once()
+		mockAPIServerRepo.On("Update", mockModel.CollectionName, "id", mock.Anything).Return("", http.StatusOK, nil).Once()
+		mockAPIServerRepo.On("Get", mockModel.CollectionName, "id", "2").Return("", http.StatusNotFound, nil).Once()
+		mockAPIServerRepo.On("Create", mockModel.CollectionName, "id", mock.Anything).Return("", http.StatusCreated, nil).Once()
+		usecase := usecase.NewFixtureUsecase(mockFixtureRepo, mockModelRepo, mockAPIServerRepo)
+
+		status, result, err := usecase.Load(mockFixture.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, domain.FixtureLoadResult{Created: 1, Updated: 1}, result)
+
+		mockAPIServerRepo.AssertExpectations(t)
+	})
+}
+
+func TestReset(t *testing.T) {
+	fixtureId, _ := uuid.NewRandom()
+	mockModel := newMockModel()
+
+	mockFixture := domain.Fixture{}
+	mockFixture.ID = fixtureId.String()
+	mockFixture.ModelID = mockModel.ID
+	mockFixture.Name = "name"
+	mockFixture.Data = `[{"id": "1", "name": "foo"}]`
+
+	mockFixtureRepo := new(mocks.FixtureRepository)
+	mockModelRepo := new(mocks.ModelRepository)
+	mockAPIServerRepo := new(mocks.APIServerRepository)
+
+	isTemporary := mock.MatchedBy(func(name string) bool {
+		return strings.HasPrefix(name, mockModel.CollectionName+"_reset_")
+	})
+
+	t.Run("一時的なコレクションに作成してから置き換える", func(t *testing.T) {
+		mockFixtureRepo.On("GetByID", mockFixture.ID).Return(mockFixture, nil).Once()
+		mockModelRepo.On("GetByID", mockModel.ID).Return(mockModel, nil).Once()
+		mockAPIServerRepo.On("Get", isTemporary, "id", "1").Return("", http.StatusNotFound, nil).Once()
+		mockAPIServerRepo.On("Create", isTemporary, "id", mock.Anything).Return("", http.StatusCreated, nil).Once()
+		mockAPIServerRepo.On("ReplaceCollection", isTemporary, mockModel.CollectionName).Return(http.StatusNoContent, nil).Once()
+		usecase := usecase.NewFixtureUsecase(mockFixtureRepo, mockModelRepo, mockAPIServerRepo)
+
+		status, result, err := usecase.Reset(mockFixture.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, domain.FixtureLoadResult{Created: 1}, result)
+
+		mockAPIServerRepo.AssertExpectations(t)
+		mockAPIServerRepo.AssertNotCalled(t, "RemoveCollection", mockModel.CollectionName)
+	})
+	t.Run("投入に失敗した場合は元のコレクションを変更しない", func(t *testing.T) {
+		mockAPIServerRepo := new(mocks.APIServerRepository)
+		mockFixtureRepo.On("GetByID", mockFixture.ID).Return(mockFixture, nil).Once()
+		mockModelRepo.On("GetByID", mockModel.ID).Return(mockModel, nil).Once()
+		mockAPIServerRepo.On("Get", isTemporary, "id", "1").Return("", http.StatusNotFound, nil).Once()
+		mockAPIServerRepo.On("Create", isTemporary, "id", mock.Anything).Return("", http.StatusInternalServerError, errors.New("insert failed")).Once()
+		mockAPIServerRepo.On("RemoveCollection", isTemporary).Return("", http.StatusNoContent, nil).Once()
+		usecase := usecase.NewFixtureUsecase(mockFixtureRepo, mockModelRepo, mockAPIServerRepo)
+
+		status, _, err := usecase.Reset(mockFixture.ID)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusInternalServerError, status)
+		mockAPIServerRepo.AssertExpectations(t)
+		mockAPIServerRepo.AssertNotCalled(t, "ReplaceCollection", mock.Anything, mock.Anything)
+	})
+	t.Run("Fixtureが存在しない", func(t *testing.T) {
+		mockFixtureRepo.On("GetByID", mockFixture.ID).Return(domain.Fixture{}, gorm.ErrRecordNotFound).Once()
+		usecase := usecase.NewFixtureUsecase(mockFixtureRepo, mockModelRepo, mockAPIServerRepo)
+
+		status, _, err := usecase.Reset(mockFixture.ID)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+}
+
+func TestSnapshot(t *testing.T) {
+	mockModel := newMockModel()
+
+	mockFixtureRepo := new(mocks.FixtureRepository)
+	mockModelRepo := new(mocks.ModelRepository)
+	mockAPIServerRepo := new(mocks.APIServerRepository)
+
+	t.Run("コレクションの内容からFixtureを作成", func(t *testing.T) {
+		documents := []map[string]interface{}{{"id": "1", "name": "foo"}}
+
+		mockModelRepo.On("GetByID", mockModel.ID).Return(mockModel, nil).Once()
+		mockAPIServerRepo.On("GetList", mockModel.CollectionName, "", "").Return(documents, http.StatusOK, nil).Once()
+		mockFixtureRepo.On("Create", mock.MatchedBy(func(fixture domain.Fixture) bool {
+			return fixture.ModelID == mockModel.ID && fixture.Name == "snapshot" && fixture.Data == `[{"id":"1","name":"foo"}]`
+		})).Return(nil).Once()
+		usecase := usecase.NewFixtureUsecase(mockFixtureRepo, mockModelRepo, mockAPIServerRepo)
+
+		status, _, err := usecase.Snapshot(mockModel.ID, domain.SnapshotRequest{Name: "snapshot"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, status)
+
+		mockFixtureRepo.AssertExpectations(t)
+	})
+	t.Run("名前が指定されていない", func(t *testing.T) {
+		usecase := usecase.NewFixtureUsecase(mockFixtureRepo, mockModelRepo, mockAPIServerRepo)
+
+		status, _, err := usecase.Snapshot(mockModel.ID, domain.SnapshotRequest{})
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/history/usecase"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+// ActorHeader 操作者を指定するリクエストヘッダー
+// 管理画面は認証しないため、クライアントが任意の値を指定できます。履歴には参考情報として記録し、送信元のIPアドレスも記録します
+const ActorHeader = "X-Actor"
+
+// HistoryHandler 設定の変更の履歴のAPIに対するリクエストハンドラ
+type HistoryHandler struct {
+	usecase usecase.HistoryUsecase
+}
+
+// NewHistoryHandler HistoryHandlerを作成します
+func NewHistoryHandler(r *gin.RouterGroup, u usecase.HistoryUsecase) {
+	handler := &HistoryHandler{
+		usecase: u,
+	}
+	r.GET("/history/:id", handler.GetByID)
+	// api、method、modelに紐づいた変更の履歴のルート
+	r.GET("/apis/:id/history", handler.GetListByAPIID)
+	r.GET("/methods/:id/history", handler.getListByEntity(domain.EntityTypeMethod))
+	r.GET("/models/:id/history", handler.getListByEntity(domain.EntityTypeModel))
+	r.POST("/methods/:id/history/:version/revert", handler.revert(domain.EntityTypeMethod))
+	r.POST("/models/:id/history/:version/revert", handler.revert(domain.EntityTypeModel))
+}
+
+// SetActor 操作者と送信元のIPアドレスをリクエストのcontextに保持します
+// X-Actorヘッダーがない場合は、"ip:<IPアドレス>"を操作者とします
+func SetActor(c *gin.Context) {
+	ip := c.ClientIP()
+	actor := c.GetHeader(ActorHeader)
+	if actor == "" {
+		actor = "ip:" + ip
+	}
+	ctx := domain.WithActorIP(domain.WithActor(c.Request.Context(), actor), ip)
+	c.Request = c.Request.WithContext(ctx)
+	c.Next()
+}
+
+// GetByID 変更の履歴を1件取得します
+func (h *HistoryHandler) GetByID(c *gin.Context) {
+	id := c.Param("id")
+
+	result, err := h.usecase.GetByID(id)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			c.JSON(http.StatusNotFound, domain.ErrorResponse{Error: err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Error: err.Error()})
+		}
+		log.Println(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetListByAPIID APIと、APIのMethod、Modelの変更の履歴を取得します
+func (h *HistoryHandler) GetListByAPIID(c *gin.Context) {
+	apiID := c.Param("id")
+
+	result, err := h.usecase.GetListByAPIID(apiID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// getListByEntity MethodまたはModelの変更の履歴を取得するハンドラを作成します
+func (h *HistoryHandler) getListByEntity(entityType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		result, err := h.usecase.GetListByEntity(entityType, id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Error: err.Error()})
+			log.Println(err.Error())
+			return
+		}
+
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// revert MethodまたはModelを指定したバージョンに戻すハンドラを作成します
+func (h *HistoryHandler) revert(entityType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		version, err := strconv.Atoi(c.Param("version"))
+		if err != nil || version < 1 {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "version is invalid"})
+			return
+		}
+
+		status, err := h.usecase.Revert(c.Request.Context(), entityType, id, version)
+		if err != nil {
+			c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+			log.Println(err.Error())
+			return
+		}
+
+		c.JSON(http.StatusOK, nil)
+	}
+}
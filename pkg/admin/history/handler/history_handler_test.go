@@ -0,0 +1,89 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/history/handler"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func newMockRouter() (*gin.Engine, *gin.RouterGroup) {
+	router := gin.Default()
+	apiV1 := router.Group("api/v1")
+
+	return router, apiV1
+}
+
+func TestGetListByEntity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockHistoryUsecase := new(mocks.HistoryUsecase)
+	mockHistoryUsecase.On("GetListByEntity", domain.EntityTypeModel, "model-1").Return([]domain.ChangeEvent{{ID: "event-1"}}, nil).Once()
+
+	router, rg := newMockRouter()
+	handler.NewHistoryHandler(rg, mockHistoryUsecase)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/models/model-1/history", nil)
+	router.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Code)
+	mockHistoryUsecase.AssertExpectations(t)
+}
+
+func TestRevert(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockHistoryUsecase := new(mocks.HistoryUsecase)
+	mockHistoryUsecase.On("Revert", domain.EntityTypeMethod, "method-1", 2).Return(nil).Once()
+
+	router, rg := newMockRouter()
+	handler.NewHistoryHandler(rg, mockHistoryUsecase)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/methods/method-1/history/2/revert", nil)
+	router.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Code)
+	mockHistoryUsecase.AssertExpectations(t)
+
+	// バージョンが不正
+	for _, version := range []string{"0", "latest"} {
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/methods/method-1/history/"+version+"/revert", nil)
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, 400, res.Code)
+	}
+}
+
+func TestSetActor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(handler.SetActor)
+	router.GET("/actor", func(c *gin.Context) {
+		c.String(http.StatusOK, domain.ActorFromContext(c.Request.Context())+" "+domain.ActorIPFromContext(c.Request.Context()))
+	})
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/actor", nil)
+	req.Header.Set(handler.ActorHeader, "alice")
+	req.RemoteAddr = "192.0.2.1:1234"
+	router.ServeHTTP(res, req)
+	// ヘッダーの値は任意に指定できるため、IPアドレスも保持する
+	assert.Equal(t, "alice 192.0.2.1", res.Body.String())
+
+	// ヘッダーがない場合はIPアドレス
+	res = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/actor", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	router.ServeHTTP(res, req)
+	assert.Equal(t, "ip:192.0.2.1 192.0.2.1", res.Body.String())
+}
@@ -0,0 +1,86 @@
+// Package history 管理画面での設定(API、Method、Model)の変更を、操作者と変更前後の設定とともに記録します
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	_historyRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/history/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/google/uuid"
+)
+
+// revertKey contextに戻すバージョンを保持するキー
+type revertKey struct{}
+
+// Change 記録する変更
+type Change struct {
+	EntityType string
+	EntityID   string
+	APIID      string
+	Action     string
+	// Before 変更前の設定(作成の場合はnil)
+	Before interface{}
+	// After 変更後の設定(削除の場合はnil)
+	After interface{}
+}
+
+// Recorder Interface
+type Recorder interface {
+	Record(ctx context.Context, change Change)
+}
+
+type recorder struct {
+	historyRepo _historyRepository.HistoryRepository
+}
+
+// NewRecorder 変更の履歴をhistoryRepoに記録するRecorderを作成します
+func NewRecorder(historyRepo _historyRepository.HistoryRepository) Recorder {
+	return &recorder{
+		historyRepo: historyRepo,
+	}
+}
+
+// Record 変更を記録します。操作者はctxから取得します
+// 記録に失敗しても変更は取り消しません
+func (r *recorder) Record(ctx context.Context, change Change) {
+	id, _ := uuid.NewRandom()
+	event := domain.ChangeEvent{
+		ID:         id.String(),
+		EntityType: change.EntityType,
+		EntityID:   change.EntityID,
+		APIID:      change.APIID,
+		Action:     change.Action,
+		Actor:      domain.ActorFromContext(ctx),
+		ActorIP:    domain.ActorIPFromContext(ctx),
+		Before:     marshal(change.Before),
+		After:      marshal(change.After),
+	}
+	if version, ok := ctx.Value(revertKey{}).(int); ok {
+		event.Action = domain.ChangeActionRevert
+		event.RevertedVersion = version
+	}
+
+	if _, err := r.historyRepo.Create(event); err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// WithRevert 以前のバージョンに戻す変更であることを保持したcontextを作成します
+func WithRevert(ctx context.Context, version int) context.Context {
+	return context.WithValue(ctx, revertKey{}, version)
+}
+
+// marshal 設定をJSONにします(設定がない場合は空)
+func marshal(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		log.Println(err.Error())
+		return ""
+	}
+	return string(b)
+}
@@ -0,0 +1,53 @@
+package history_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/history"
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/history/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/memory"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecord(t *testing.T) {
+	store, err := memory.NewStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	historyRepository := repository.NewMemoryHistoryRepository(store)
+	recorder := history.NewRecorder(historyRepository)
+	ctx := domain.WithActorIP(domain.WithActor(context.Background(), "alice"), "192.0.2.1")
+
+	before := domain.Method{ID: "method-1", APIID: "api-1", URL: "/{id}"}
+	after := before
+	after.URL = "/{userId}"
+	recorder.Record(ctx, history.Change{EntityType: domain.EntityTypeMethod, EntityID: before.ID, APIID: before.APIID, Action: domain.ChangeActionCreate, After: before})
+	recorder.Record(ctx, history.Change{EntityType: domain.EntityTypeMethod, EntityID: before.ID, APIID: before.APIID, Action: domain.ChangeActionUpdate, Before: before, After: after})
+	recorder.Record(history.WithRevert(ctx, 1), history.Change{EntityType: domain.EntityTypeMethod, EntityID: before.ID, APIID: before.APIID, Action: domain.ChangeActionUpdate, Before: after, After: before})
+
+	events, err := historyRepository.GetListByEntity(domain.EntityTypeMethod, before.ID)
+	assert.NoError(t, err)
+	if !assert.Len(t, events, 3) {
+		return
+	}
+
+	update := events[1]
+	assert.Equal(t, 2, update.Version)
+	assert.Equal(t, domain.ChangeActionUpdate, update.Action)
+	assert.Equal(t, "alice", update.Actor)
+	assert.Equal(t, "192.0.2.1", update.ActorIP)
+	assert.Contains(t, update.Before, `"url":"/{id}"`)
+	assert.Contains(t, update.After, `"url":"/{userId}"`)
+
+	// 作成の場合、変更前は空
+	assert.Empty(t, events[2].Before)
+
+	// 以前のバージョンに戻した変更
+	revert := events[0]
+	assert.Equal(t, domain.ChangeActionRevert, revert.Action)
+	assert.Equal(t, 1, revert.RevertedVersion)
+	assert.Equal(t, events[2].After, revert.After)
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	"github.com/jinzhu/gorm"
+)
+
+// HistoryRepository Interface
+type HistoryRepository interface {
+	GetByID(id string) (domain.ChangeEvent, error)
+	GetListByEntity(entityType string, entityID string) ([]domain.ChangeEvent, error)
+	GetListByAPIID(apiID string) ([]domain.ChangeEvent, error)
+	GetVersion(entityType string, entityID string, version int) (domain.ChangeEvent, error)
+	Create(event domain.ChangeEvent) (domain.ChangeEvent, error)
+}
+
+type historyRepository struct {
+	db *gorm.DB
+}
+
+// NewHistoryRepository HistoryRepositoryインターフェイスを表すオブジェクトを作成します
+func NewHistoryRepository(db *gorm.DB) HistoryRepository {
+	return &historyRepository{
+		db: db,
+	}
+}
+
+// GetByID 変更の履歴を1件取得します
+func (r *historyRepository) GetByID(id string) (domain.ChangeEvent, error) {
+	event := domain.ChangeEvent{}
+	err := r.db.Where("id = ?", id).First(&event).Error
+
+	return event, err
+}
+
+// GetListByEntity 対象の変更の履歴を新しいバージョン順に取得します
+func (r *historyRepository) GetListByEntity(entityType string, entityID string) ([]domain.ChangeEvent, error) {
+	events := []domain.ChangeEvent{}
+	err := r.db.Where("entity_type = ? AND entity_id = ?", entityType, entityID).Order("version desc").Find(&events).Error
+
+	return events, err
+}
+
+// GetListByAPIID APIと、APIのMethod、Modelの変更の履歴を新しい順に取得します
+func (r *historyRepository) GetListByAPIID(apiID string) ([]domain.ChangeEvent, error) {
+	events := []domain.ChangeEvent{}
+	err := r.db.Where("api_id = ?", apiID).Order("created_at desc").Order("version desc").Find(&events).Error
+
+	return events, err
+}
+
+// GetVersion 対象の指定したバージョンの変更の履歴を取得します
+func (r *historyRepository) GetVersion(entityType string, entityID string, version int) (domain.ChangeEvent, error) {
+	event := domain.ChangeEvent{}
+	err := r.db.Where("entity_type = ? AND entity_id = ? AND version = ?", entityType, entityID, version).First(&event).Error
+
+	return event, err
+}
+
+// maxCreateAttempts バージョンが重複した場合に、採番し直して追加する回数の上限
+const maxCreateAttempts = 5
+
+// Create 変更の履歴を追加します。バージョンは対象の最新のバージョンの次の番号とします
+// 同時に追加してバージョンが重複した場合(一意のインデックスで拒否されます)は、採番し直して追加します
+func (r *historyRepository) Create(event domain.ChangeEvent) (domain.ChangeEvent, error) {
+	var err error
+	for attempt := 0; attempt < maxCreateAttempts; attempt++ {
+		if err = r.create(&event); err == nil || !r.versionExists(event) {
+			return event, err
+		}
+	}
+	return event, err
+}
+
+// create 最新のバージョンの次の番号で、変更の履歴を追加します
+func (r *historyRepository) create(event *domain.ChangeEvent) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var latest struct{ Version int }
+		err := tx.Model(&domain.ChangeEvent{}).
+			Select("COALESCE(MAX(version), 0) AS version").
+			Where("entity_type = ? AND entity_id = ?", event.EntityType, event.EntityID).
+			Scan(&latest).Error
+		if err != nil {
+			return err
+		}
+		event.Version = latest.Version + 1
+		return tx.Create(event).Error
+	})
+}
+
+// versionExists 対象の同じバージョンの履歴が、既に存在するか確認します(追加に失敗した原因の判定に使用します)
+func (r *historyRepository) versionExists(event domain.ChangeEvent) bool {
+	count := 0
+	err := r.db.Model(&domain.ChangeEvent{}).
+		Where("entity_type = ? AND entity_id = ? AND version = ?", event.EntityType, event.EntityID, event.Version).
+		Count(&count).Error
+	return err == nil && count > 0
+}
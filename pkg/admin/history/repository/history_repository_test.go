@@ -0,0 +1,104 @@
+package repository_test
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/history/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func setUpMockDB() (sqlmock.Sqlmock, *gorm.DB) {
+	gorm.DefaultTableNameHandler = func(db *gorm.DB, defaultTableName string) string {
+		return strings.Replace(defaultTableName, "_data_table", "", 1)
+	}
+	d, mock, _ := sqlmock.New()
+	conn, _ := gorm.Open("mysql", d)
+
+	return mock, conn
+}
+
+func TestCreate(t *testing.T) {
+	mock, db := setUpMockDB()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(MAX(version), 0) AS version FROM `change_events` WHERE (entity_type = ? AND entity_id = ?)")).
+		WithArgs(domain.EntityTypeMethod, "method-1").
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `change_events`")).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	historyRepository := repository.NewHistoryRepository(db)
+
+	event, err := historyRepository.Create(domain.ChangeEvent{ID: "event-1", EntityType: domain.EntityTypeMethod, EntityID: "method-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, event.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateVersionConflict(t *testing.T) {
+	mock, db := setUpMockDB()
+
+	latest := regexp.QuoteMeta("SELECT COALESCE(MAX(version), 0) AS version FROM `change_events` WHERE (entity_type = ? AND entity_id = ?)")
+	exists := regexp.QuoteMeta("SELECT count(*) FROM `change_events` WHERE (entity_type = ? AND entity_id = ? AND version = ?)")
+
+	// 同時に追加された履歴とバージョンが重複する
+	mock.ExpectBegin()
+	mock.ExpectQuery(latest).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `change_events`")).WillReturnError(errors.New("Error 1062: Duplicate entry"))
+	mock.ExpectRollback()
+	mock.ExpectQuery(exists).WithArgs(domain.EntityTypeMethod, "method-1", 3).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	// 採番し直して追加する
+	mock.ExpectBegin()
+	mock.ExpectQuery(latest).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `change_events`")).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	historyRepository := repository.NewHistoryRepository(db)
+
+	event, err := historyRepository.Create(domain.ChangeEvent{ID: "event-1", EntityType: domain.EntityTypeMethod, EntityID: "method-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, event.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateError(t *testing.T) {
+	mock, db := setUpMockDB()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(MAX(version), 0) AS version")).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `change_events`")).WillReturnError(errors.New("connection refused"))
+	mock.ExpectRollback()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `change_events`")).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	historyRepository := repository.NewHistoryRepository(db)
+
+	_, err := historyRepository.Create(domain.ChangeEvent{ID: "event-1", EntityType: domain.EntityTypeMethod, EntityID: "method-1"})
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetListByEntity(t *testing.T) {
+	mock, db := setUpMockDB()
+
+	query := regexp.QuoteMeta("SELECT * FROM `change_events` WHERE (entity_type = ? AND entity_id = ?) ORDER BY version desc")
+	rows := sqlmock.NewRows([]string{"id", "entity_type", "entity_id", "version", "action"}).
+		AddRow("event-2", domain.EntityTypeModel, "model-1", 2, domain.ChangeActionUpdate).
+		AddRow("event-1", domain.EntityTypeModel, "model-1", 1, domain.ChangeActionCreate)
+	mock.ExpectQuery(query).WithArgs(domain.EntityTypeModel, "model-1").WillReturnRows(rows)
+
+	historyRepository := repository.NewHistoryRepository(db)
+
+	events, err := historyRepository.GetListByEntity(domain.EntityTypeModel, "model-1")
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
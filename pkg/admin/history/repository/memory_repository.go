@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"sort"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/memory"
+	"github.com/jinzhu/gorm"
+)
+
+type memoryHistoryRepository struct {
+	store *memory.Store
+}
+
+// NewMemoryHistoryRepository メモリ上のStoreを使用するHistoryRepositoryを作成します(開発用)
+func NewMemoryHistoryRepository(store *memory.Store) HistoryRepository {
+	return &memoryHistoryRepository{
+		store: store,
+	}
+}
+
+// GetByID 変更の履歴を1件取得します
+func (r *memoryHistoryRepository) GetByID(id string) (domain.ChangeEvent, error) {
+	return r.find(func(e domain.ChangeEvent) bool {
+		return e.ID == id
+	})
+}
+
+// GetListByEntity 対象の変更の履歴を新しいバージョン順に取得します
+func (r *memoryHistoryRepository) GetListByEntity(entityType string, entityID string) ([]domain.ChangeEvent, error) {
+	events := r.filter(func(e domain.ChangeEvent) bool {
+		return e.EntityType == entityType && e.EntityID == entityID
+	})
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Version > events[j].Version
+	})
+	return events, nil
+}
+
+// GetListByAPIID APIと、APIのMethod、Modelの変更の履歴を新しい順に取得します
+func (r *memoryHistoryRepository) GetListByAPIID(apiID string) ([]domain.ChangeEvent, error) {
+	events := r.filter(func(e domain.ChangeEvent) bool {
+		return e.APIID == apiID
+	})
+	// 記録順に保持しているため、逆順にする
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+	return events, nil
+}
+
+// GetVersion 対象の指定したバージョンの変更の履歴を取得します
+func (r *memoryHistoryRepository) GetVersion(entityType string, entityID string, version int) (domain.ChangeEvent, error) {
+	return r.find(func(e domain.ChangeEvent) bool {
+		return e.EntityType == entityType && e.EntityID == entityID && e.Version == version
+	})
+}
+
+// Create 変更の履歴を追加します。バージョンは対象の最新のバージョンの次の番号とします
+func (r *memoryHistoryRepository) Create(event domain.ChangeEvent) (domain.ChangeEvent, error) {
+	err := r.store.Update(func(t *memory.Tables) error {
+		event.Version = 1
+		for _, e := range t.ChangeEvents {
+			if e.EntityType == event.EntityType && e.EntityID == event.EntityID && e.Version >= event.Version {
+				event.Version = e.Version + 1
+			}
+		}
+		memory.Created(&event.CommonColumn)
+		t.ChangeEvents = append(t.ChangeEvents, event)
+		return nil
+	})
+	return event, err
+}
+
+func (r *memoryHistoryRepository) find(match func(domain.ChangeEvent) bool) (domain.ChangeEvent, error) {
+	event := domain.ChangeEvent{}
+	err := gorm.ErrRecordNotFound
+	r.store.View(func(t *memory.Tables) {
+		for _, e := range t.ChangeEvents {
+			if match(e) {
+				event, err = e, nil
+				return
+			}
+		}
+	})
+	return event, err
+}
+
+func (r *memoryHistoryRepository) filter(match func(domain.ChangeEvent) bool) []domain.ChangeEvent {
+	events := []domain.ChangeEvent{}
+	r.store.View(func(t *memory.Tables) {
+		for _, e := range t.ChangeEvents {
+			if match(e) {
+				events = append(events, e)
+			}
+		}
+	})
+	return events
+}
@@ -0,0 +1,55 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/history/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/memory"
+	"github.com/jinzhu/gorm"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryHistory(t *testing.T) {
+	store, err := memory.NewStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	historyRepository := repository.NewMemoryHistoryRepository(store)
+
+	for _, e := range []domain.ChangeEvent{
+		{ID: "e1", EntityType: domain.EntityTypeAPI, EntityID: "a1", APIID: "a1", Action: domain.ChangeActionCreate},
+		{ID: "e2", EntityType: domain.EntityTypeMethod, EntityID: "m1", APIID: "a1", Action: domain.ChangeActionCreate},
+		{ID: "e3", EntityType: domain.EntityTypeMethod, EntityID: "m1", APIID: "a1", Action: domain.ChangeActionUpdate},
+		{ID: "e4", EntityType: domain.EntityTypeMethod, EntityID: "m2", APIID: "a2", Action: domain.ChangeActionCreate},
+	} {
+		_, err := historyRepository.Create(e)
+		assert.NoError(t, err)
+	}
+
+	t.Run("対象ごとにバージョンを採番する", func(t *testing.T) {
+		events, err := historyRepository.GetListByEntity(domain.EntityTypeMethod, "m1")
+
+		assert.NoError(t, err)
+		if assert.Len(t, events, 2) {
+			assert.Equal(t, "e3", events[0].ID)
+			assert.Equal(t, 2, events[0].Version)
+			assert.Equal(t, 1, events[1].Version)
+		}
+		event, err := historyRepository.GetVersion(domain.EntityTypeMethod, "m2", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "e4", event.ID)
+	})
+	t.Run("APIと、APIのMethodの変更の履歴を新しい順に取得する", func(t *testing.T) {
+		events, err := historyRepository.GetListByAPIID("a1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"e3", "e2", "e1"}, []string{events[0].ID, events[1].ID, events[2].ID})
+	})
+	t.Run("存在しないバージョン", func(t *testing.T) {
+		_, err := historyRepository.GetVersion(domain.EntityTypeMethod, "m1", 3)
+
+		assert.True(t, gorm.IsRecordNotFoundError(err))
+	})
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	_apiRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/api/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/history"
+	_historyRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/history/repository"
+	_methodUsecase "github.com/Hajime3778/api-creator-backend/pkg/admin/method/usecase"
+	_modelUsecase "github.com/Hajime3778/api-creator-backend/pkg/admin/model/usecase"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/jinzhu/gorm"
+)
+
+// HistoryUsecase Interface
+type HistoryUsecase interface {
+	GetByID(id string) (domain.ChangeEvent, error)
+	GetListByEntity(entityType string, entityID string) ([]domain.ChangeEvent, error)
+	GetListByAPIID(apiID string) ([]domain.ChangeEvent, error)
+	Revert(ctx context.Context, entityType string, entityID string, version int) (int, error)
+}
+
+type historyUsecase struct {
+	historyRepo   _historyRepository.HistoryRepository
+	apiRepo       _apiRepository.APIRepository
+	methodUsecase _methodUsecase.MethodUsecase
+	modelUsecase  _modelUsecase.ModelUsecase
+}
+
+// NewHistoryUsecase HistoryUsecaseインターフェイスを表すオブジェクトを作成します
+// 以前のバージョンへの変更は、methodUsecase、modelUsecaseで検証して適用します
+func NewHistoryUsecase(historyRepo _historyRepository.HistoryRepository, apiRepo _apiRepository.APIRepository, methodUsecase _methodUsecase.MethodUsecase, modelUsecase _modelUsecase.ModelUsecase) HistoryUsecase {
+	return &historyUsecase{
+		historyRepo:   historyRepo,
+		apiRepo:       apiRepo,
+		methodUsecase: methodUsecase,
+		modelUsecase:  modelUsecase,
+	}
+}
+
+// GetByID 変更の履歴を1件取得します
+func (u *historyUsecase) GetByID(id string) (domain.ChangeEvent, error) {
+	return u.historyRepo.GetByID(id)
+}
+
+// GetListByEntity 対象の変更の履歴を新しいバージョン順に取得します
+func (u *historyUsecase) GetListByEntity(entityType string, entityID string) ([]domain.ChangeEvent, error) {
+	return u.historyRepo.GetListByEntity(entityType, entityID)
+}
+
+// GetListByAPIID APIと、APIのMethod、Modelの変更の履歴を新しい順に取得します
+func (u *historyUsecase) GetListByAPIID(apiID string) ([]domain.ChangeEvent, error) {
+	return u.historyRepo.GetListByAPIID(apiID)
+}
+
+// Revert MethodまたはModelを、指定したバージョンの変更後の設定に戻します
+// 削除されている場合は再作成します。戻した変更も新しいバージョンとして記録します
+func (u *historyUsecase) Revert(ctx context.Context, entityType string, entityID string, version int) (int, error) {
+	if entityType != domain.EntityTypeMethod && entityType != domain.EntityTypeModel {
+		return http.StatusBadRequest, fmt.Errorf("%s cannot be reverted", entityType)
+	}
+
+	event, err := u.historyRepo.GetVersion(entityType, entityID, version)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return http.StatusNotFound, err
+		}
+		return http.StatusInternalServerError, err
+	}
+	if event.After == "" {
+		return http.StatusBadRequest, fmt.Errorf("version %d is a deletion", version)
+	}
+
+	// 削除されたAPIのMethod、Modelは戻せない
+	if _, err := u.apiRepo.GetByID(event.APIID); err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return http.StatusConflict, fmt.Errorf("api %s has been deleted", event.APIID)
+		}
+		return http.StatusInternalServerError, err
+	}
+
+	ctx = history.WithRevert(ctx, version)
+	if entityType == domain.EntityTypeMethod {
+		return u.revertMethod(ctx, event)
+	}
+	return u.revertModel(ctx, event)
+}
+
+// revertMethod Methodを変更の履歴の変更後の設定に戻します
+func (u *historyUsecase) revertMethod(ctx context.Context, event domain.ChangeEvent) (int, error) {
+	var method domain.Method
+	if err := json.Unmarshal([]byte(event.After), &method); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	method.CommonColumn = domain.CommonColumn{}
+
+	if _, err := u.methodUsecase.GetByID(method.ID); err != nil {
+		if !gorm.IsRecordNotFoundError(err) {
+			return http.StatusInternalServerError, err
+		}
+		if status, _, err := u.methodUsecase.Create(ctx, method); err != nil {
+			return status, err
+		}
+		return http.StatusOK, nil
+	}
+	return u.methodUsecase.Update(ctx, method)
+}
+
+// revertModel Modelを変更の履歴の変更後の設定に戻します
+// コレクション名は戻さず、現在のコレクションをそのまま使用します
+func (u *historyUsecase) revertModel(ctx context.Context, event domain.ChangeEvent) (int, error) {
+	var model domain.Model
+	if err := json.Unmarshal([]byte(event.After), &model); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	model.CommonColumn = domain.CommonColumn{}
+
+	if _, err := u.modelUsecase.GetByID(model.ID); err != nil {
+		if !gorm.IsRecordNotFoundError(err) {
+			return http.StatusInternalServerError, err
+		}
+		// 削除したModelは、保存していたコレクションのドキュメントを参照できるよう、同じコレクション名で作成し直す
+		if status, err := u.modelUsecase.Restore(ctx, model); err != nil {
+			return status, err
+		}
+		return http.StatusOK, nil
+	}
+	return u.modelUsecase.Update(ctx, model)
+}
@@ -0,0 +1,125 @@
+package usecase_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/history/usecase"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+	"github.com/jinzhu/gorm"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevert(t *testing.T) {
+	mockHistoryRepo := new(mocks.HistoryRepository)
+	mockAPIRepo := new(mocks.APIRepository)
+	mockMethodUsecase := new(mocks.MethodUsecase)
+	mockModelUsecase := new(mocks.ModelUsecase)
+	newUsecase := func() usecase.HistoryUsecase {
+		return usecase.NewHistoryUsecase(mockHistoryRepo, mockAPIRepo, mockMethodUsecase, mockModelUsecase)
+	}
+
+	method := domain.Method{ID: "method-1", APIID: "api-1", Type: "GET", URL: "/{id}", RequestParameter: "id"}
+	event := domain.ChangeEvent{
+		EntityType: domain.EntityTypeMethod,
+		EntityID:   method.ID,
+		APIID:      method.APIID,
+		Version:    2,
+		After:      `{"id":"method-1","apiId":"api-1","type":"GET","url":"/{id}","requestParameter":"id","created_at":"2020-01-01T00:00:00Z"}`,
+	}
+
+	t.Run("変更後の設定でMethodを更新する", func(t *testing.T) {
+		mockHistoryRepo.On("GetVersion", domain.EntityTypeMethod, method.ID, 2).Return(event, nil).Once()
+		mockAPIRepo.On("GetByID", method.APIID).Return(domain.API{ID: method.APIID}, nil).Once()
+		mockMethodUsecase.On("GetByID", method.ID).Return(method, nil).Once()
+		mockMethodUsecase.On("Update", method).Return(nil).Once()
+
+		status, err := newUsecase().Revert(context.Background(), domain.EntityTypeMethod, method.ID, 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		mockMethodUsecase.AssertExpectations(t)
+	})
+	t.Run("削除されたMethodは再作成する", func(t *testing.T) {
+		mockHistoryRepo.On("GetVersion", domain.EntityTypeMethod, method.ID, 2).Return(event, nil).Once()
+		mockAPIRepo.On("GetByID", method.APIID).Return(domain.API{ID: method.APIID}, nil).Once()
+		mockMethodUsecase.On("GetByID", method.ID).Return(domain.Method{}, gorm.ErrRecordNotFound).Once()
+		mockMethodUsecase.On("Create", method).Return(nil).Once()
+
+		status, err := newUsecase().Revert(context.Background(), domain.EntityTypeMethod, method.ID, 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		mockMethodUsecase.AssertExpectations(t)
+	})
+	t.Run("変更後の設定でModelを更新する", func(t *testing.T) {
+		model := domain.Model{ID: "model-1", APIID: "api-1", Name: "User", Schema: `{"type": "object"}`}
+		mockHistoryRepo.On("GetVersion", domain.EntityTypeModel, model.ID, 1).Return(domain.ChangeEvent{
+			EntityType: domain.EntityTypeModel,
+			EntityID:   model.ID,
+			APIID:      model.APIID,
+			Version:    1,
+			After:      `{"id":"model-1","apiId":"api-1","name":"User","schema":"{\"type\": \"object\"}"}`,
+		}, nil).Once()
+		mockAPIRepo.On("GetByID", model.APIID).Return(domain.API{ID: model.APIID}, nil).Once()
+		mockModelUsecase.On("GetByID", model.ID).Return(model, nil).Once()
+		mockModelUsecase.On("Update", model).Return(nil).Once()
+
+		status, err := newUsecase().Revert(context.Background(), domain.EntityTypeModel, model.ID, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		mockModelUsecase.AssertExpectations(t)
+	})
+	t.Run("削除されたModelは、同じコレクション名で再作成する", func(t *testing.T) {
+		model := domain.Model{ID: "model-2", APIID: "api-1", Name: "Post", Schema: `{"type": "object"}`, CollectionName: "posts"}
+		mockHistoryRepo.On("GetVersion", domain.EntityTypeModel, model.ID, 1).Return(domain.ChangeEvent{
+			EntityType: domain.EntityTypeModel,
+			EntityID:   model.ID,
+			APIID:      model.APIID,
+			Version:    1,
+			After:      `{"id":"model-2","apiId":"api-1","name":"Post","schema":"{\"type\": \"object\"}","collectionName":"posts"}`,
+		}, nil).Once()
+		mockAPIRepo.On("GetByID", model.APIID).Return(domain.API{ID: model.APIID}, nil).Once()
+		mockModelUsecase.On("GetByID", model.ID).Return(domain.Model{}, gorm.ErrRecordNotFound).Once()
+		mockModelUsecase.On("Restore", model).Return(nil).Once()
+
+		status, err := newUsecase().Revert(context.Background(), domain.EntityTypeModel, model.ID, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		mockModelUsecase.AssertExpectations(t)
+		mockModelUsecase.AssertNotCalled(t, "Create", model)
+	})
+	t.Run("戻せないバージョン", func(t *testing.T) {
+		deleted := event
+		deleted.Version = 3
+		deleted.After = ""
+		mockHistoryRepo.On("GetVersion", domain.EntityTypeMethod, method.ID, 3).Return(deleted, nil).Once()
+		mockHistoryRepo.On("GetVersion", domain.EntityTypeMethod, method.ID, 9).Return(domain.ChangeEvent{}, gorm.ErrRecordNotFound).Once()
+
+		status, err := newUsecase().Revert(context.Background(), domain.EntityTypeMethod, method.ID, 3)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+
+		status, err = newUsecase().Revert(context.Background(), domain.EntityTypeMethod, method.ID, 9)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, status)
+
+		status, err = newUsecase().Revert(context.Background(), domain.EntityTypeAPI, "api-1", 1)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+	t.Run("APIが削除されている", func(t *testing.T) {
+		mockHistoryRepo.On("GetVersion", domain.EntityTypeMethod, method.ID, 2).Return(event, nil).Once()
+		mockAPIRepo.On("GetByID", method.APIID).Return(domain.API{}, gorm.ErrRecordNotFound).Once()
+
+		status, err := newUsecase().Revert(context.Background(), domain.EntityTypeMethod, method.ID, 2)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusConflict, status)
+	})
+}
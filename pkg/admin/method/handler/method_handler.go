@@ -27,6 +27,7 @@ func NewMethodHandler(r *gin.RouterGroup, u usecase.MethodUsecase) {
 		methodRoutes.GET("/:id", handler.GetByID)
 		methodRoutes.POST("", handler.Create)
 		methodRoutes.PUT("", handler.Update)
+		methodRoutes.PUT("/:id/mode", handler.ChangeMode)
 		methodRoutes.DELETE("/:id", handler.Delete)
 	}
 	// apiに紐づいたmethodのルート(ルーティングまとめる箇所の検討余地あり)
@@ -94,7 +95,7 @@ func (h *MethodHandler) Create(c *gin.Context) {
 	var method domain.Method
 	c.BindJSON(&method)
 
-	status, id, err := h.usecase.Create(method)
+	status, id, err := h.usecase.Create(c.Request.Context(), method)
 	if err != nil {
 		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
 		log.Println(err.Error())
@@ -107,7 +108,7 @@ func (h *MethodHandler) Create(c *gin.Context) {
 func (h *MethodHandler) CreateDefaultMethod(c *gin.Context) {
 	apiID := c.Param("id")
 
-	status, methods, err := h.usecase.CreateDefaultMethods(apiID)
+	status, methods, err := h.usecase.CreateDefaultMethods(c.Request.Context(), apiID)
 	if err != nil {
 		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
 		log.Println(err.Error())
@@ -121,7 +122,7 @@ func (h *MethodHandler) Update(c *gin.Context) {
 	var method domain.Method
 	c.BindJSON(&method)
 
-	status, err := h.usecase.Update(method)
+	status, err := h.usecase.Update(c.Request.Context(), method)
 
 	if err != nil {
 		if gorm.IsRecordNotFoundError(err) {
@@ -136,11 +137,28 @@ func (h *MethodHandler) Update(c *gin.Context) {
 	c.JSON(http.StatusOK, nil)
 }
 
+// ChangeMode Methodのモードを切り替えます
+func (h *MethodHandler) ChangeMode(c *gin.Context) {
+	id := c.Param("id")
+
+	var request domain.ChangeModeRequest
+	c.BindJSON(&request)
+
+	status, err := h.usecase.ChangeMode(c.Request.Context(), id, request)
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, nil)
+}
+
 // Delete Methodを削除します
 func (h *MethodHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 
-	err := h.usecase.Delete(id)
+	err := h.usecase.Delete(c.Request.Context(), id)
 	if err != nil {
 		if gorm.IsRecordNotFoundError(err) {
 			c.JSON(http.StatusNotFound, domain.ErrorResponse{Error: err.Error()})
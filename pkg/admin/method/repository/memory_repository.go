@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/memory"
+	"github.com/jinzhu/gorm"
+)
+
+type memoryMethodRepository struct {
+	store *memory.Store
+}
+
+// NewMemoryMethodRepository メモリ上のStoreを使用するMethodRepositoryを作成します(開発用)
+func NewMemoryMethodRepository(store *memory.Store) MethodRepository {
+	return &memoryMethodRepository{
+		store: store,
+	}
+}
+
+// GetAll すべてのMethodを取得します
+func (r *memoryMethodRepository) GetAll() ([]domain.Method, error) {
+	methods := []domain.Method{}
+	r.store.View(func(t *memory.Tables) {
+		methods = append(methods, t.Methods...)
+	})
+	return methods, nil
+}
+
+// GetByID Methodを1件取得します
+func (r *memoryMethodRepository) GetByID(id string) (domain.Method, error) {
+	method := domain.Method{}
+	err := gorm.ErrRecordNotFound
+	r.store.View(func(t *memory.Tables) {
+		if i := indexOfMethod(t.Methods, id); i >= 0 {
+			method, err = t.Methods[i], nil
+		}
+	})
+	return method, err
+}
+
+// GetListByAPIID MethodをAPIIDで複数取得します
+func (r *memoryMethodRepository) GetListByAPIID(apiID string) ([]domain.Method, error) {
+	methods := []domain.Method{}
+	r.store.View(func(t *memory.Tables) {
+		for _, m := range t.Methods {
+			if m.APIID == apiID {
+				methods = append(methods, m)
+			}
+		}
+	})
+	return methods, nil
+}
+
+// GetListByAPIIDAndType MethodをAPIIDとTypeで複数取得します
+func (r *memoryMethodRepository) GetListByAPIIDAndType(apiID string, methodType string) ([]domain.Method, error) {
+	methods := []domain.Method{}
+	r.store.View(func(t *memory.Tables) {
+		for _, m := range t.Methods {
+			if m.APIID == apiID && m.Type == methodType {
+				methods = append(methods, m)
+			}
+		}
+	})
+	return methods, nil
+}
+
+// Create Methodを追加します
+func (r *memoryMethodRepository) Create(method domain.Method) (string, error) {
+	err := r.store.Update(func(t *memory.Tables) error {
+		if indexOfMethod(t.Methods, method.ID) >= 0 {
+			return memory.ErrDuplicateKey
+		}
+		memory.Created(&method.CommonColumn)
+		t.Methods = append(t.Methods, method)
+		return nil
+	})
+	return method.ID, err
+}
+
+// Update Methodを更新します
+func (r *memoryMethodRepository) Update(method domain.Method) error {
+	return r.store.Update(func(t *memory.Tables) error {
+		i := indexOfMethod(t.Methods, method.ID)
+		if i < 0 {
+			return gorm.ErrRecordNotFound
+		}
+		memory.Saved(&method.CommonColumn, t.Methods[i].CommonColumn)
+		t.Methods[i] = method
+		return nil
+	})
+}
+
+// Delete Methodを削除します
+func (r *memoryMethodRepository) Delete(id string) error {
+	return r.store.Update(func(t *memory.Tables) error {
+		i := indexOfMethod(t.Methods, id)
+		if i < 0 {
+			return gorm.ErrRecordNotFound
+		}
+		t.Methods = append(t.Methods[:i], t.Methods[i+1:]...)
+		return nil
+	})
+}
+
+// indexOfMethod IDが一致するMethodの位置を返却します。存在しない場合は-1です
+func indexOfMethod(methods []domain.Method, id string) int {
+	for i := range methods {
+		if methods[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
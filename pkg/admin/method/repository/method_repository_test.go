@@ -87,7 +87,7 @@ func TestCreate(t *testing.T) {
 	mockMethod.UpdatedAt = time.Time{}
 
 	mock.ExpectBegin()
-	query := regexp.QuoteMeta("INSERT INTO `methods` (`id`,`api_id`,`type`,`url`,`description`,`request_parameter`,`request_model_id`,`response_model_id`,`is_array`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?)")
+	query := regexp.QuoteMeta("INSERT INTO `methods` (`id`,`api_id`,`type`,`url`,`description`,`request_parameter`,`request_model_id`,`response_model_id`,`is_array`,`mode`,`mock_response`,`mock_status`,`mock_latency`,`before_script`,`after_script`,`rate_limit`,`rate_burst`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)")
 	mock.ExpectExec(query).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
@@ -126,7 +126,7 @@ func TestUpdate(t *testing.T) {
 	mock.ExpectQuery(selectQuery).WillReturnRows(selectRows)
 
 	mock.ExpectBegin()
-	query := regexp.QuoteMeta("UPDATE `methods` SET `api_id` = ?, `type` = ?, `url` = ?, `description` = ?, `request_parameter` = ?, `request_model_id` = ?, `response_model_id` = ?, `is_array` = ?, `updated_at` = ? WHERE `methods`.`id` = ?")
+	query := regexp.QuoteMeta("UPDATE `methods` SET `api_id` = ?, `type` = ?, `url` = ?, `description` = ?, `request_parameter` = ?, `request_model_id` = ?, `response_model_id` = ?, `is_array` = ?, `mode` = ?, `mock_response` = ?, `mock_status` = ?, `mock_latency` = ?, `before_script` = ?, `after_script` = ?, `rate_limit` = ?, `rate_burst` = ?, `updated_at` = ? WHERE `methods`.`id` = ?")
 	mock.ExpectExec(query).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
@@ -1,43 +1,57 @@
 package usecase
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"regexp"
 	"strings"
 
 	_apiRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/api/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/history"
 	_methodRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/method/repository"
 	_modelRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/model/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/script"
 	"github.com/Hajime3778/api-creator-backend/pkg/domain"
 	"github.com/Hajime3778/api-creator-backend/pkg/validation"
 	"github.com/google/uuid"
 	"github.com/jinzhu/gorm"
 )
 
+// maxMockLatency mockの場合に設定できる待ち時間の上限(ミリ秒)
+// APIサーバーの既定の書き込みタイムアウト(10秒)より前にレスポンスを返却できる時間にします
+const maxMockLatency = 9000
+
 // MethodUsecase Interface
 type MethodUsecase interface {
 	GetAll() ([]domain.Method, error)
 	GetByID(id string) (domain.Method, error)
 	GetListByAPIID(apiID string) ([]domain.Method, error)
-	Create(method domain.Method) (int, string, error)
-	CreateDefaultMethods(apiID string) (int, []domain.Method, error)
-	Update(method domain.Method) (int, error)
-	Delete(id string) error
+	// 変更するメソッドのctxには操作者(domain.WithActor)を保持し、変更の履歴に記録します
+	Create(ctx context.Context, method domain.Method) (int, string, error)
+	CreateDefaultMethods(ctx context.Context, apiID string) (int, []domain.Method, error)
+	Update(ctx context.Context, method domain.Method) (int, error)
+	ChangeMode(ctx context.Context, id string, request domain.ChangeModeRequest) (int, error)
+	Delete(ctx context.Context, id string) error
 }
 
 type methodUsecase struct {
 	apiRepo    _apiRepository.APIRepository
 	methodRepo _methodRepository.MethodRepository
 	modelRepo  _modelRepository.ModelRepository
+	recorder   history.Recorder
 }
 
 // NewMethodUsecase MethodUsecaseインターフェイスを表すオブジェクトを作成します
-func NewMethodUsecase(apiRepo _apiRepository.APIRepository, methodRepo _methodRepository.MethodRepository, modelRepo _modelRepository.ModelRepository) MethodUsecase {
+// recorderがnilの場合は、変更の履歴を記録しません
+func NewMethodUsecase(apiRepo _apiRepository.APIRepository, methodRepo _methodRepository.MethodRepository, modelRepo _modelRepository.ModelRepository, recorder history.Recorder) MethodUsecase {
 	return &methodUsecase{
 		apiRepo:    apiRepo,
 		modelRepo:  modelRepo,
 		methodRepo: methodRepo,
+		recorder:   recorder,
 	}
 }
 
@@ -57,7 +71,7 @@ func (u *methodUsecase) GetListByAPIID(apiID string) ([]domain.Method, error) {
 }
 
 // Create Methodを作成します
-func (u *methodUsecase) Create(method domain.Method) (int, string, error) {
+func (u *methodUsecase) Create(ctx context.Context, method domain.Method) (int, string, error) {
 	if method.ID == "" {
 		id, _ := uuid.NewRandom()
 		method.ID = id.String()
@@ -66,15 +80,25 @@ func (u *methodUsecase) Create(method domain.Method) (int, string, error) {
 	if err != nil {
 		return http.StatusBadRequest, "", err
 	}
+	if err := validateMockSetting(method); err != nil {
+		return http.StatusBadRequest, "", err
+	}
+	if err := validateScripts(method); err != nil {
+		return http.StatusBadRequest, "", err
+	}
+	if err := validateRateLimit(method.RateLimit, method.RateBurst); err != nil {
+		return http.StatusBadRequest, "", err
+	}
 	id, err := u.methodRepo.Create(method)
 	if err != nil {
 		return http.StatusInternalServerError, "", err
 	}
+	u.record(ctx, domain.ChangeActionCreate, method.ID, method.APIID, nil, method)
 	return http.StatusCreated, id, nil
 }
 
 // CreateDefaultMethods デフォルトのCRUDMethodを作成します
-func (u *methodUsecase) CreateDefaultMethods(apiID string) (int, []domain.Method, error) {
+func (u *methodUsecase) CreateDefaultMethods(ctx context.Context, apiID string) (int, []domain.Method, error) {
 	_, err := u.apiRepo.GetByID(apiID)
 	if err != nil {
 		if gorm.IsRecordNotFoundError(err) {
@@ -136,6 +160,7 @@ func (u *methodUsecase) CreateDefaultMethods(apiID string) (int, []domain.Method
 		if err != nil {
 			return http.StatusInternalServerError, nil, err
 		}
+		u.record(ctx, domain.ChangeActionCreate, method.ID, method.APIID, nil, method)
 	}
 
 	createdMethods, err := u.methodRepo.GetListByAPIID(apiID)
@@ -147,21 +172,88 @@ func (u *methodUsecase) CreateDefaultMethods(apiID string) (int, []domain.Method
 }
 
 // Update Methodを更新します。
-func (u *methodUsecase) Update(method domain.Method) (int, error) {
+func (u *methodUsecase) Update(ctx context.Context, method domain.Method) (int, error) {
 	err := u.validateMethodURL(method)
 	if err != nil {
 		return http.StatusBadRequest, err
 	}
+	if err := validateMockSetting(method); err != nil {
+		return http.StatusBadRequest, err
+	}
+	if err := validateScripts(method); err != nil {
+		return http.StatusBadRequest, err
+	}
+	if err := validateRateLimit(method.RateLimit, method.RateBurst); err != nil {
+		return http.StatusBadRequest, err
+	}
+	var before interface{}
+	if u.recorder != nil {
+		if current, err := u.methodRepo.GetByID(method.ID); err == nil {
+			before = current
+		}
+	}
 	err = u.methodRepo.Update(method)
 	if err != nil {
 		return http.StatusInternalServerError, err
 	}
+	u.record(ctx, domain.ChangeActionUpdate, method.ID, method.APIID, before, method)
 	return http.StatusOK, err
 }
 
+// ChangeMode Methodのモード(live、mock)を切り替えます。URLは変更しません
+func (u *methodUsecase) ChangeMode(ctx context.Context, id string, request domain.ChangeModeRequest) (int, error) {
+	method, err := u.methodRepo.GetByID(id)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return http.StatusNotFound, err
+		}
+		return http.StatusInternalServerError, err
+	}
+
+	before := method
+	method.Mode = request.Mode
+	if err := validateMockSetting(method); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	if err := u.methodRepo.Update(method); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	u.record(ctx, domain.ChangeActionUpdate, method.ID, method.APIID, before, method)
+	return http.StatusOK, nil
+}
+
 // Delete Methodを削除します
-func (u *methodUsecase) Delete(id string) error {
-	return u.methodRepo.Delete(id)
+func (u *methodUsecase) Delete(ctx context.Context, id string) error {
+	var before interface{}
+	var apiID string
+	if u.recorder != nil {
+		if method, err := u.methodRepo.GetByID(id); err == nil {
+			before, apiID = method, method.APIID
+		}
+	}
+	if err := u.methodRepo.Delete(id); err != nil {
+		return err
+	}
+	if before != nil {
+		u.record(ctx, domain.ChangeActionDelete, id, apiID, before, nil)
+	}
+	return nil
+}
+
+// record Methodの変更を履歴に記録します(recorderがnilの場合は記録しません)
+func (u *methodUsecase) record(ctx context.Context, action string, id string, apiID string, before interface{}, after interface{}) {
+	if u.recorder == nil {
+		return
+	}
+	u.recorder.Record(ctx, history.Change{
+		EntityType: domain.EntityTypeMethod,
+		EntityID:   id,
+		APIID:      apiID,
+		Action:     action,
+		Before:     before,
+		After:      after,
+	})
 }
 
 // validateMethodURL メソッドURLを検証します
@@ -217,3 +309,45 @@ func (u *methodUsecase) validateMethodURL(method domain.Method) error {
 	}
 	return nil
 }
+
+// validateMockSetting モードと、mockの場合に返却するレスポンスの設定を検証します
+func validateMockSetting(method domain.Method) error {
+	if method.Mode != "" && method.Mode != domain.MethodModeLive && method.Mode != domain.MethodModeMock {
+		return errors.New("mode is live or mock")
+	}
+	// 1xxは最終的なレスポンスとして返却できないため、200から599のみ指定できる
+	if method.MockStatus != 0 && (method.MockStatus < 200 || method.MockStatus > 599) {
+		return errors.New("mockStatus must be between 200 and 599")
+	}
+	if method.MockLatency < 0 || method.MockLatency > maxMockLatency {
+		return fmt.Errorf("mockLatency must be between 0 and %d", maxMockLatency)
+	}
+	if method.MockResponse != "" && !json.Valid([]byte(method.MockResponse)) {
+		return errors.New("mockResponse is not json")
+	}
+	return nil
+}
+
+// validateRateLimit リクエスト数の制限を検証します
+func validateRateLimit(rateLimit int, rateBurst int) error {
+	if rateLimit < 0 || rateBurst < 0 {
+		return errors.New("rateLimit and rateBurst must not be negative")
+	}
+	return nil
+}
+
+// validateScripts Methodに設定されたスクリプトの構文を検証します
+func validateScripts(method domain.Method) error {
+	runner := script.NewRunner(0, 0, 0)
+	if method.BeforeScript != "" {
+		if err := runner.Compile(method.BeforeScript); err != nil {
+			return fmt.Errorf("beforeScript is invalid: %s", err)
+		}
+	}
+	if method.AfterScript != "" {
+		if err := runner.Compile(method.AfterScript); err != nil {
+			return fmt.Errorf("afterScript is invalid: %s", err)
+		}
+	}
+	return nil
+}
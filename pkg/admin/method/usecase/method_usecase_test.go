@@ -1,15 +1,21 @@
 package usecase_test
 
 import (
+	"context"
+	"net/http"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/history"
 	"github.com/Hajime3778/api-creator-backend/pkg/admin/method/usecase"
 	"github.com/Hajime3778/api-creator-backend/pkg/domain"
 	"github.com/Hajime3778/api-creator-backend/test/mocks"
 	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestGetAll(t *testing.T) {
@@ -38,7 +44,7 @@ func TestGetAll(t *testing.T) {
 
 	t.Run("test1", func(t *testing.T) {
 		mockMethodRepo.On("GetAll").Return(mockMethods, nil).Once()
-		usecase := usecase.NewMethodUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo)
+		usecase := usecase.NewMethodUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, nil)
 
 		methods, err := usecase.GetAll()
 
@@ -72,7 +78,7 @@ func TestGetByID(t *testing.T) {
 
 	t.Run("test1", func(t *testing.T) {
 		mockMethodRepo.On("GetByID", mockMethod.ID).Return(mockMethod, nil).Once()
-		usecase := usecase.NewMethodUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo)
+		usecase := usecase.NewMethodUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, nil)
 
 		method, err := usecase.GetByID(mockMethod.ID)
 
@@ -105,9 +111,9 @@ func TestCreate(t *testing.T) {
 	t.Run("test1", func(t *testing.T) {
 		mockMethodRepo.On("Create", mockMethod).Return(nil).Once()
 		mockMethodRepo.On("GetListByAPIIDAndType", mockMethod.APIID, mockMethod.Type).Return([]domain.Method{}, nil).Once()
-		usecase := usecase.NewMethodUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo)
+		usecase := usecase.NewMethodUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, nil)
 
-		_, _, err := usecase.Create(mockMethod)
+		_, _, err := usecase.Create(context.Background(), mockMethod)
 
 		assert.NoError(t, err)
 
@@ -118,12 +124,41 @@ func TestCreate(t *testing.T) {
 		mockMethod.URL = "url"
 		mockMethodRepo.On("Create", mockMethod).Return(nil).Once()
 		mockMethodRepo.On("GetListByAPIIDAndType", mockMethod.APIID, mockMethod.Type).Return([]domain.Method{}, nil).Once()
-		usecase := usecase.NewMethodUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo)
+		usecase := usecase.NewMethodUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, nil)
 
-		_, err := usecase.Update(mockMethod)
+		_, err := usecase.Update(context.Background(), mockMethod)
 
 		assert.Error(t, err)
 	})
+
+	t.Run("スクリプトの構文エラー", func(t *testing.T) {
+		invalidMethod := mockMethod
+		invalidMethod.URL = "/url"
+		invalidMethod.BeforeScript = "document.status = ;"
+		mockMethodRepo.On("GetListByAPIIDAndType", mockMethod.APIID, mockMethod.Type).Return([]domain.Method{}, nil).Once()
+		usecase := usecase.NewMethodUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, nil)
+
+		status, err := usecase.Update(context.Background(), invalidMethod)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("mockのステータスは200から599のみ指定できる", func(t *testing.T) {
+		for _, mockStatus := range []int{100, 101, 199, 600} {
+			invalidMethod := mockMethod
+			invalidMethod.URL = "/url"
+			invalidMethod.Mode = domain.MethodModeMock
+			invalidMethod.MockStatus = mockStatus
+			mockMethodRepo.On("GetListByAPIIDAndType", mockMethod.APIID, mockMethod.Type).Return([]domain.Method{}, nil).Once()
+			usecase := usecase.NewMethodUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, nil)
+
+			status, _, err := usecase.Create(context.Background(), invalidMethod)
+
+			assert.Error(t, err, mockStatus)
+			assert.Equal(t, http.StatusBadRequest, status, mockStatus)
+		}
+	})
 }
 
 func TestUpdate(t *testing.T) {
@@ -148,24 +183,117 @@ func TestUpdate(t *testing.T) {
 	t.Run("test1", func(t *testing.T) {
 		mockMethodRepo.On("Update", mockMethod).Return(nil).Once()
 		mockMethodRepo.On("GetListByAPIIDAndType", mockMethod.APIID, mockMethod.Type).Return([]domain.Method{}, nil).Once()
-		usecase := usecase.NewMethodUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo)
+		usecase := usecase.NewMethodUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, nil)
 
-		_, err := usecase.Update(mockMethod)
+		_, err := usecase.Update(context.Background(), mockMethod)
 
 		assert.NoError(t, err)
 
 		mockMethodRepo.AssertExpectations(t)
 	})
 
+	t.Run("変更前後の設定を履歴に記録する", func(t *testing.T) {
+		current := mockMethod
+		current.URL = "/old"
+		mockMethodRepo.On("Update", mockMethod).Return(nil).Once()
+		mockMethodRepo.On("GetListByAPIIDAndType", mockMethod.APIID, mockMethod.Type).Return([]domain.Method{}, nil).Once()
+		mockMethodRepo.On("GetByID", mockMethod.ID).Return(current, nil).Once()
+		mockHistoryRepo := new(mocks.HistoryRepository)
+		mockHistoryRepo.On("Create", mock.MatchedBy(func(e domain.ChangeEvent) bool {
+			return e.EntityType == domain.EntityTypeMethod && e.EntityID == mockMethod.ID && e.Action == domain.ChangeActionUpdate &&
+				e.Actor == "alice" && strings.Contains(e.Before, `"url":"/old"`) && strings.Contains(e.After, `"url":"/url"`)
+		})).Return(nil).Once()
+		usecase := usecase.NewMethodUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, history.NewRecorder(mockHistoryRepo))
+
+		_, err := usecase.Update(domain.WithActor(context.Background(), "alice"), mockMethod)
+
+		assert.NoError(t, err)
+		mockHistoryRepo.AssertExpectations(t)
+	})
+
 	t.Run("test2", func(t *testing.T) {
 		mockMethod.URL = "url"
 		mockMethodRepo.On("Update", mockMethod).Return(nil).Once()
 		mockMethodRepo.On("GetListByAPIIDAndType", mockMethod.APIID, mockMethod.Type).Return([]domain.Method{}, nil).Once()
-		usecase := usecase.NewMethodUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo)
+		usecase := usecase.NewMethodUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, nil)
+
+		_, err := usecase.Update(context.Background(), mockMethod)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestChangeMode(t *testing.T) {
+	methodId, _ := uuid.NewRandom()
+	apiId, _ := uuid.NewRandom()
+
+	mockMethod := domain.Method{}
+	mockMethod.ID = methodId.String()
+	mockMethod.APIID = apiId.String()
+	mockMethod.Type = "GET"
+	mockMethod.URL = "/url"
+	mockMethod.MockResponse = "{\"id\": \"1\"}"
+
+	mockAPIRepo := new(mocks.APIRepository)
+	mockModelRepo := new(mocks.ModelRepository)
+	mockMethodRepo := new(mocks.MethodRepository)
+
+	t.Run("mockに切り替え", func(t *testing.T) {
+		mockedMethod := mockMethod
+		mockedMethod.Mode = domain.MethodModeMock
+
+		mockMethodRepo.On("GetByID", mockMethod.ID).Return(mockMethod, nil).Once()
+		mockMethodRepo.On("Update", mockedMethod).Return(nil).Once()
+		usecase := usecase.NewMethodUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, nil)
+
+		status, err := usecase.ChangeMode(context.Background(), mockMethod.ID, domain.ChangeModeRequest{Mode: domain.MethodModeMock})
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+
+		mockMethodRepo.AssertExpectations(t)
+	})
+	t.Run("存在しないモード", func(t *testing.T) {
+		mockMethodRepo.On("GetByID", mockMethod.ID).Return(mockMethod, nil).Once()
+		usecase := usecase.NewMethodUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, nil)
+
+		status, err := usecase.ChangeMode(context.Background(), mockMethod.ID, domain.ChangeModeRequest{Mode: "foo"})
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+	t.Run("mockのレスポンスがJSONでない", func(t *testing.T) {
+		invalidMethod := mockMethod
+		invalidMethod.MockResponse = "foo"
+
+		mockMethodRepo.On("GetByID", mockMethod.ID).Return(invalidMethod, nil).Once()
+		usecase := usecase.NewMethodUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, nil)
+
+		status, err := usecase.ChangeMode(context.Background(), mockMethod.ID, domain.ChangeModeRequest{Mode: domain.MethodModeMock})
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+	t.Run("mockの待ち時間がサーバーのタイムアウトより長い", func(t *testing.T) {
+		slowMethod := mockMethod
+		slowMethod.MockLatency = 10000
+
+		mockMethodRepo.On("GetByID", mockMethod.ID).Return(slowMethod, nil).Once()
+		usecase := usecase.NewMethodUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, nil)
+
+		status, err := usecase.ChangeMode(context.Background(), mockMethod.ID, domain.ChangeModeRequest{Mode: domain.MethodModeMock})
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+	t.Run("Methodが存在しない", func(t *testing.T) {
+		mockMethodRepo.On("GetByID", mockMethod.ID).Return(domain.Method{}, gorm.ErrRecordNotFound).Once()
+		usecase := usecase.NewMethodUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, nil)
 
-		_, err := usecase.Update(mockMethod)
+		status, err := usecase.ChangeMode(context.Background(), mockMethod.ID, domain.ChangeModeRequest{Mode: domain.MethodModeLive})
 
 		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, status)
 	})
 }
 
@@ -190,9 +318,9 @@ func TestDelete(t *testing.T) {
 
 	t.Run("test1", func(t *testing.T) {
 		mockMethodRepo.On("Delete", mockMethod.ID).Return(nil).Once()
-		usecase := usecase.NewMethodUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo)
+		usecase := usecase.NewMethodUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, nil)
 
-		err := usecase.Delete(mockMethod.ID)
+		err := usecase.Delete(context.Background(), mockMethod.ID)
 
 		assert.NoError(t, err)
 
@@ -27,6 +27,8 @@ func NewModelHandler(r *gin.RouterGroup, u usecase.ModelUsecase) {
 		modelRoutes.GET("/:id", handler.GetByID)
 		modelRoutes.POST("", handler.Create)
 		modelRoutes.PUT("", handler.Update)
+		modelRoutes.POST("/:id/rename", handler.Rename)
+		modelRoutes.POST("/:id/generate", handler.Generate)
 		modelRoutes.DELETE("/:id", handler.Delete)
 	}
 	// apiに紐づいたmodel(ルーティングまとめる箇所の検討余地あり)
@@ -93,7 +95,7 @@ func (h *ModelHandler) Create(c *gin.Context) {
 	var model domain.Model
 	c.BindJSON(&model)
 
-	status, id, err := h.usecase.Create(model)
+	status, id, err := h.usecase.Create(c.Request.Context(), model)
 	if err != nil {
 		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
 		log.Println(err.Error())
@@ -107,7 +109,7 @@ func (h *ModelHandler) Update(c *gin.Context) {
 	var model domain.Model
 	c.BindJSON(&model)
 
-	status, err := h.usecase.Update(model)
+	status, err := h.usecase.Update(c.Request.Context(), model)
 
 	if err != nil {
 		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
@@ -118,11 +120,45 @@ func (h *ModelHandler) Update(c *gin.Context) {
 	c.JSON(http.StatusOK, nil)
 }
 
+// Rename Model名を変更します
+func (h *ModelHandler) Rename(c *gin.Context) {
+	id := c.Param("id")
+
+	var request domain.RenameModelRequest
+	c.BindJSON(&request)
+
+	status, err := h.usecase.Rename(c.Request.Context(), id, request)
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, nil)
+}
+
+// Generate jsonschemaからダミーのドキュメントを生成します
+func (h *ModelHandler) Generate(c *gin.Context) {
+	id := c.Param("id")
+
+	var request domain.GenerateRequest
+	c.BindJSON(&request)
+
+	status, result, err := h.usecase.Generate(id, request)
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 // Delete Modelを削除します
 func (h *ModelHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 
-	err := h.usecase.Delete(id)
+	err := h.usecase.Delete(c.Request.Context(), id)
 	if err != nil {
 		if gorm.IsRecordNotFoundError(err) {
 			c.JSON(http.StatusNotFound, domain.ErrorResponse{Error: err.Error()})
@@ -151,3 +151,24 @@ func TestDelete(t *testing.T) {
 
 	assert.Equal(t, 204, res.Code)
 }
+
+func TestGenerate(t *testing.T) {
+	modelId, _ := uuid.NewRandom()
+	seed := int64(1)
+	request := domain.GenerateRequest{Count: 1, Seed: &seed}
+
+	gin.SetMode(gin.TestMode)
+
+	mockModelUsecase := new(mocks.ModelUsecase)
+	mockModelUsecase.On("Generate", modelId.String(), request).Return(domain.GenerateResponse{Seed: seed}, nil).Once()
+
+	router, rg := newMockRouter()
+	handler.NewModelHandler(rg, mockModelUsecase)
+
+	requestJSON, _ := json.Marshal(request)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/model/v1/models/"+modelId.String()+"/generate", bytes.NewReader(requestJSON))
+	router.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Code)
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/memory"
+	"github.com/jinzhu/gorm"
+)
+
+type memoryModelRepository struct {
+	store *memory.Store
+}
+
+// NewMemoryModelRepository メモリ上のStoreを使用するModelRepositoryを作成します(開発用)
+func NewMemoryModelRepository(store *memory.Store) ModelRepository {
+	return &memoryModelRepository{
+		store: store,
+	}
+}
+
+// GetAll すべてのModelを取得します
+func (r *memoryModelRepository) GetAll() ([]domain.Model, error) {
+	models := []domain.Model{}
+	r.store.View(func(t *memory.Tables) {
+		models = append(models, t.Models...)
+	})
+	return models, nil
+}
+
+// GetByID Modelを1件取得します
+func (r *memoryModelRepository) GetByID(id string) (domain.Model, error) {
+	model := domain.Model{}
+	err := gorm.ErrRecordNotFound
+	r.store.View(func(t *memory.Tables) {
+		if i := indexOfModel(t.Models, id); i >= 0 {
+			model, err = t.Models[i], nil
+		}
+	})
+	return model, err
+}
+
+// GetByAPIID ModelをAPIIDで1件取得します
+func (r *memoryModelRepository) GetByAPIID(apiID string) (domain.Model, error) {
+	model := domain.Model{}
+	err := gorm.ErrRecordNotFound
+	r.store.View(func(t *memory.Tables) {
+		for _, m := range t.Models {
+			if m.APIID == apiID {
+				model, err = m, nil
+				return
+			}
+		}
+	})
+	return model, err
+}
+
+// Create Modelを追加します
+func (r *memoryModelRepository) Create(model domain.Model) (string, error) {
+	err := r.store.Update(func(t *memory.Tables) error {
+		if indexOfModel(t.Models, model.ID) >= 0 {
+			return memory.ErrDuplicateKey
+		}
+		memory.Created(&model.CommonColumn)
+		t.Models = append(t.Models, model)
+		return nil
+	})
+	return model.ID, err
+}
+
+// Update Modelを更新します
+func (r *memoryModelRepository) Update(model domain.Model) error {
+	return r.store.Update(func(t *memory.Tables) error {
+		i := indexOfModel(t.Models, model.ID)
+		if i < 0 {
+			return gorm.ErrRecordNotFound
+		}
+		memory.Saved(&model.CommonColumn, t.Models[i].CommonColumn)
+		t.Models[i] = model
+		return nil
+	})
+}
+
+// Delete Modelを削除します
+func (r *memoryModelRepository) Delete(id string) error {
+	return r.store.Update(func(t *memory.Tables) error {
+		i := indexOfModel(t.Models, id)
+		if i < 0 {
+			return gorm.ErrRecordNotFound
+		}
+		t.Models = append(t.Models[:i], t.Models[i+1:]...)
+		return nil
+	})
+}
+
+// indexOfModel IDが一致するModelの位置を返却します。存在しない場合は-1です
+func indexOfModel(models []domain.Model, id string) int {
+	for i := range models {
+		if models[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -71,7 +71,7 @@ func TestCreate(t *testing.T) {
 	mockModel.UpdatedAt = time.Time{}
 
 	mock.ExpectBegin()
-	query := regexp.QuoteMeta("INSERT INTO `models` (`id`,`api_id`,`name`,`description`,`schema`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?)")
+	query := regexp.QuoteMeta("INSERT INTO `models` (`id`,`api_id`,`name`,`description`,`schema`,`collection_name`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?)")
 	mock.ExpectExec(query).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
@@ -101,7 +101,7 @@ func TestUpdate(t *testing.T) {
 	mock.ExpectQuery(selectQuery).WillReturnRows(selectRows)
 
 	mock.ExpectBegin()
-	query := regexp.QuoteMeta("UPDATE `models` SET `api_id` = ?, `name` = ?, `description` = ?, `schema` = ?, `updated_at` = ? WHERE `models`.`id` = ?")
+	query := regexp.QuoteMeta("UPDATE `models` SET `api_id` = ?, `name` = ?, `description` = ?, `schema` = ?, `collection_name` = ?, `updated_at` = ? WHERE `models`.`id` = ?")
 	mock.ExpectExec(query).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
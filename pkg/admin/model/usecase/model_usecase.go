@@ -1,11 +1,30 @@
 package usecase
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/history"
 	"github.com/Hajime3778/api-creator-backend/pkg/admin/model/repository"
+	_apiserverRepository "github.com/Hajime3778/api-creator-backend/pkg/apiserver/repository"
 	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/generator"
+	"github.com/Hajime3778/api-creator-backend/pkg/validation"
 	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	// defaultGenerateCount ダミーデータ生成時の件数が未指定の場合の件数
+	defaultGenerateCount = 10
+	// maxGenerateCount 1回のリクエストで生成できるダミーデータの最大件数
+	maxGenerateCount = 1000
 )
 
 // ModelUsecase Interface
@@ -13,19 +32,28 @@ type ModelUsecase interface {
 	GetAll() ([]domain.Model, error)
 	GetByID(id string) (domain.Model, error)
 	GetByAPIID(apiID string) (domain.Model, error)
-	Create(model domain.Model) (int, string, error)
-	Update(model domain.Model) (int, error)
-	Delete(id string) error
+	// 変更するメソッドのctxには操作者(domain.WithActor)を保持し、変更の履歴に記録します
+	Create(ctx context.Context, model domain.Model) (int, string, error)
+	Update(ctx context.Context, model domain.Model) (int, error)
+	Restore(ctx context.Context, model domain.Model) (int, error)
+	Rename(ctx context.Context, id string, request domain.RenameModelRequest) (int, error)
+	Generate(id string, request domain.GenerateRequest) (int, domain.GenerateResponse, error)
+	Delete(ctx context.Context, id string) error
 }
 
 type modelUsecase struct {
-	repo repository.ModelRepository
+	repo          repository.ModelRepository
+	apiserverRepo _apiserverRepository.APIServerRepository
+	recorder      history.Recorder
 }
 
 // NewModelUsecase ModelUsecaseインターフェイスを表すオブジェクトを作成します
-func NewModelUsecase(repo repository.ModelRepository) ModelUsecase {
+// recorderがnilの場合は、変更の履歴を記録しません
+func NewModelUsecase(repo repository.ModelRepository, apiserverRepo _apiserverRepository.APIServerRepository, recorder history.Recorder) ModelUsecase {
 	return &modelUsecase{
-		repo: repo,
+		repo:          repo,
+		apiserverRepo: apiserverRepo,
+		recorder:      recorder,
 	}
 }
 
@@ -45,7 +73,7 @@ func (u *modelUsecase) GetByAPIID(apiID string) (domain.Model, error) {
 }
 
 // Create Modelを作成します
-func (u *modelUsecase) Create(model domain.Model) (int, string, error) {
+func (u *modelUsecase) Create(ctx context.Context, model domain.Model) (int, string, error) {
 	if model.ID == "" {
 		id, _ := uuid.NewRandom()
 		model.ID = id.String()
@@ -56,31 +84,269 @@ func (u *modelUsecase) Create(model domain.Model) (int, string, error) {
 		return http.StatusBadRequest, "", err
 	}
 
+	// コレクション名はModel名を変更しても変わらないよう、IDから作成する
+	model.CollectionName = model.ID
+
 	id, err := u.repo.Create(model)
 	if err != nil {
 		return http.StatusInternalServerError, "", err
 	}
+	u.record(ctx, domain.ChangeActionCreate, model.ID, model.APIID, nil, model)
 
 	return http.StatusCreated, id, nil
 }
 
+// Restore 削除したModelを、IDとコレクション名を変えずに作成し直します(変更の履歴から戻す場合に使用します)
+// コレクション名が保存される前に作成されたModelは、コレクション名が空のまま(Model名のコレクション)とします
+func (u *modelUsecase) Restore(ctx context.Context, model domain.Model) (int, error) {
+	if err := model.ValidateSchema(); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	if _, err := u.repo.Create(model); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	u.record(ctx, domain.ChangeActionCreate, model.ID, model.APIID, nil, model)
+
+	return http.StatusCreated, nil
+}
+
 // Update Modelを更新します。
-func (u *modelUsecase) Update(model domain.Model) (int, error) {
+func (u *modelUsecase) Update(ctx context.Context, model domain.Model) (int, error) {
 	// JsonSchemaが正しい形式か検証
 	err := model.ValidateSchema()
 	if err != nil {
 		return http.StatusBadRequest, err
 	}
 
+	current, err := u.repo.GetByID(model.ID)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return http.StatusNotFound, err
+		}
+		return http.StatusInternalServerError, err
+	}
+	// コレクション名はRenameでのみ変更できる
+	// (コレクション名が保存される前に作成されたModelは、変更前のModel名を確定させる)
+	model.CollectionName = current.GetCollectionName()
+
 	err = u.repo.Update(model)
 	if err != nil {
 		return http.StatusInternalServerError, err
 	}
+	u.record(ctx, domain.ChangeActionUpdate, model.ID, model.APIID, current, model)
 
 	return http.StatusOK, nil
 }
 
+// Rename Model名を変更します。指定された場合はコレクション名も変更します
+func (u *modelUsecase) Rename(ctx context.Context, id string, request domain.RenameModelRequest) (int, error) {
+	if request.Name == "" {
+		return http.StatusBadRequest, errors.New("name is required")
+	}
+
+	model, err := u.repo.GetByID(id)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return http.StatusNotFound, err
+		}
+		return http.StatusInternalServerError, err
+	}
+
+	before := model
+	// 既存のドキュメントが失われないよう、変更前のコレクション名を確定させておく
+	currentCollectionName := model.GetCollectionName()
+	model.CollectionName = currentCollectionName
+	model.Name = request.Name
+
+	if request.RenameCollection {
+		newCollectionName := request.CollectionName
+		if newCollectionName == "" {
+			newCollectionName = request.Name
+		}
+		if err := validateCollectionName(newCollectionName); err != nil {
+			return http.StatusBadRequest, err
+		}
+		if newCollectionName != currentCollectionName {
+			if _, status, err := u.apiserverRepo.RenameCollection(currentCollectionName, newCollectionName); err != nil {
+				return status, err
+			}
+			model.CollectionName = newCollectionName
+		}
+	}
+
+	if err := u.repo.Update(model); err != nil {
+		// Modelが変更前のコレクションを参照したままになるため、コレクション名を元に戻す
+		if model.CollectionName != currentCollectionName {
+			if _, _, renameErr := u.apiserverRepo.RenameCollection(model.CollectionName, currentCollectionName); renameErr != nil {
+				log.Println(renameErr.Error())
+			}
+		}
+		return http.StatusInternalServerError, err
+	}
+	u.record(ctx, domain.ChangeActionUpdate, model.ID, model.APIID, before, model)
+
+	return http.StatusOK, nil
+}
+
+// Generate jsonschemaからダミーのドキュメントを生成します。指定された場合はコレクションに登録します
+func (u *modelUsecase) Generate(id string, request domain.GenerateRequest) (int, domain.GenerateResponse, error) {
+	response := domain.GenerateResponse{}
+
+	if request.Count == 0 {
+		request.Count = defaultGenerateCount
+	}
+	if request.Count < 0 || request.Count > maxGenerateCount {
+		return http.StatusBadRequest, response, fmt.Errorf("count must be between 1 and %d", maxGenerateCount)
+	}
+
+	response.Seed = time.Now().UnixNano()
+	if request.Seed != nil {
+		response.Seed = *request.Seed
+	}
+
+	model, err := u.repo.GetByID(id)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return http.StatusNotFound, response, err
+		}
+		return http.StatusInternalServerError, response, err
+	}
+
+	keys, err := model.GetKeyNames()
+	if err != nil {
+		return http.StatusBadRequest, response, err
+	}
+
+	existingKeys, err := u.getKeyValues(model)
+	if err != nil {
+		return http.StatusInternalServerError, response, err
+	}
+
+	documents, err := generator.Generate(model.Schema, generator.Options{
+		Count:        request.Count,
+		Seed:         response.Seed,
+		ExistingKeys: existingKeys,
+		ResolveRef:   u.resolveRef,
+	})
+	if err != nil {
+		return http.StatusBadRequest, response, err
+	}
+
+	bodies := make([][]byte, 0, len(documents))
+	for i, document := range documents {
+		body, err := json.Marshal(document)
+		if err != nil {
+			return http.StatusInternalServerError, response, err
+		}
+		// 生成できない制約がSchemaに含まれている場合
+		if err := model.ValidateDocument(body); err != nil {
+			return http.StatusBadRequest, response, fmt.Errorf("documents[%d]: %s", i, err.Error())
+		}
+		bodies = append(bodies, body)
+	}
+	response.Documents = documents
+
+	if request.Insert {
+		for _, body := range bodies {
+			if _, status, err := u.apiserverRepo.Create(model.GetCollectionName(), keys[0], body); err != nil {
+				return status, response, err
+			}
+			response.Inserted++
+		}
+	}
+
+	return http.StatusOK, response, nil
+}
+
+// resolveRef x-refで参照されたModel(IDまたは名前)の、登録済みドキュメントのKeyの値を取得します
+func (u *modelUsecase) resolveRef(ref string) ([]interface{}, error) {
+	models, err := u.repo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, model := range models {
+		if model.ID == ref || model.Name == ref {
+			return u.getKeyValues(model)
+		}
+	}
+	return nil, fmt.Errorf("referenced model %s is not found", ref)
+}
+
+// getKeyValues コレクションに登録済みのドキュメントから、Keyの値を取得します
+func (u *modelUsecase) getKeyValues(model domain.Model) ([]interface{}, error) {
+	keys, err := model.GetKeyNames()
+	if err != nil {
+		return nil, err
+	}
+
+	result, status, err := u.apiserverRepo.GetList(model.GetCollectionName(), "", "")
+	if err != nil {
+		if status == http.StatusNotFound {
+			return []interface{}{}, nil
+		}
+		return nil, err
+	}
+
+	// リポジトリの返却値の型に依存しないよう、JSONを経由して変換する
+	b, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	var documents []map[string]interface{}
+	if err := json.Unmarshal(b, &documents); err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, 0, len(documents))
+	for _, document := range documents {
+		if value, ok := document[keys[0]]; ok {
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
+
 // Delete Modelを削除します
-func (u *modelUsecase) Delete(id string) error {
-	return u.repo.Delete(id)
+func (u *modelUsecase) Delete(ctx context.Context, id string) error {
+	var before interface{}
+	var apiID string
+	if u.recorder != nil {
+		if model, err := u.repo.GetByID(id); err == nil {
+			before, apiID = model, model.APIID
+		}
+	}
+	if err := u.repo.Delete(id); err != nil {
+		return err
+	}
+	if before != nil {
+		u.record(ctx, domain.ChangeActionDelete, id, apiID, before, nil)
+	}
+	return nil
+}
+
+// record Modelの変更を履歴に記録します(recorderがnilの場合は記録しません)
+func (u *modelUsecase) record(ctx context.Context, action string, id string, apiID string, before interface{}, after interface{}) {
+	if u.recorder == nil {
+		return
+	}
+	u.recorder.Record(ctx, history.Change{
+		EntityType: domain.EntityTypeModel,
+		EntityID:   id,
+		APIID:      apiID,
+		Action:     action,
+		Before:     before,
+		After:      after,
+	})
+}
+
+// validateCollectionName MongoDBのコレクション名として使用できるか検証します
+func validateCollectionName(name string) error {
+	if !validation.IsHalfWidthOnly(name) {
+		return errors.New("collection name is halfwidth only")
+	}
+	if strings.Contains(name, "$") || strings.HasPrefix(name, "system.") {
+		return errors.New("collection name is invalid")
+	}
+	return nil
 }
@@ -1,16 +1,20 @@
 package usecase_test
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"testing"
 	"time"
 
 	"github.com/Hajime3778/api-creator-backend/pkg/admin/model/usecase"
 	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/generator"
 	"github.com/Hajime3778/api-creator-backend/test/mocks"
 	"github.com/google/uuid"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestGetAll(t *testing.T) {
@@ -26,10 +30,11 @@ func TestGetAll(t *testing.T) {
 
 	// モック
 	mockModelRepo := new(mocks.ModelRepository)
+	mockAPIServerRepo := new(mocks.APIServerRepository)
 
 	t.Run("test1", func(t *testing.T) {
 		mockModelRepo.On("GetAll").Return(mockModels, nil).Once()
-		usecase := usecase.NewModelUsecase(mockModelRepo)
+		usecase := usecase.NewModelUsecase(mockModelRepo, mockAPIServerRepo, nil)
 
 		models, err := usecase.GetAll()
 
@@ -52,10 +57,11 @@ func TestGetByID(t *testing.T) {
 	mockModel.UpdatedAt = time.Now()
 
 	mockModelRepo := new(mocks.ModelRepository)
+	mockAPIServerRepo := new(mocks.APIServerRepository)
 
 	t.Run("test1", func(t *testing.T) {
 		mockModelRepo.On("GetByID", mockModel.ID).Return(mockModel, nil).Once()
-		usecase := usecase.NewModelUsecase(mockModelRepo)
+		usecase := usecase.NewModelUsecase(mockModelRepo, mockAPIServerRepo, nil)
 
 		model, err := usecase.GetByID(mockModel.ID)
 
@@ -73,15 +79,17 @@ func TestCreate(t *testing.T) {
 	mockModel.ID = modelId.String()
 	mockModel.Name = "name"
 	mockModel.Description = "description"
+	mockModel.CollectionName = modelId.String()
 	mockModel.Schema = "{\"type\": \"object\", \"keys\": [\"id\"], \"properties\": {\"id\": {\"type\":\"string\"}}}"
 
 	mockModelRepo := new(mocks.ModelRepository)
+	mockAPIServerRepo := new(mocks.APIServerRepository)
 
 	t.Run("test1", func(t *testing.T) {
 		mockModelRepo.On("Create", mockModel).Return(nil).Once()
-		usecase := usecase.NewModelUsecase(mockModelRepo)
+		usecase := usecase.NewModelUsecase(mockModelRepo, mockAPIServerRepo, nil)
 
-		status, _, err := usecase.Create(mockModel)
+		status, _, err := usecase.Create(context.Background(), mockModel)
 
 		assert.NoError(t, err)
 		assert.Equal(t, status, http.StatusCreated)
@@ -90,33 +98,33 @@ func TestCreate(t *testing.T) {
 	})
 	t.Run("jsonschema形式でない", func(t *testing.T) {
 		mockModelRepo.On("Create", mockModel).Return(nil).Once()
-		usecase := usecase.NewModelUsecase(mockModelRepo)
+		usecase := usecase.NewModelUsecase(mockModelRepo, mockAPIServerRepo, nil)
 
 		mockModel.Schema = "test"
 
-		status, _, err := usecase.Create(mockModel)
+		status, _, err := usecase.Create(context.Background(), mockModel)
 
 		assert.Error(t, err)
 		assert.Equal(t, status, http.StatusBadRequest)
 	})
 	t.Run("keysがnil", func(t *testing.T) {
 		mockModelRepo.On("Create", mockModel).Return(nil).Once()
-		usecase := usecase.NewModelUsecase(mockModelRepo)
+		usecase := usecase.NewModelUsecase(mockModelRepo, mockAPIServerRepo, nil)
 
 		mockModel.Schema = "{\"type\": \"object\", \"properties\": {\"id\": {\"type\":\"string\"}}}"
 
-		status, _, err := usecase.Create(mockModel)
+		status, _, err := usecase.Create(context.Background(), mockModel)
 
 		assert.Error(t, err)
 		assert.Equal(t, status, http.StatusBadRequest)
 	})
 	t.Run("keysにpropertyが指定されていない", func(t *testing.T) {
 		mockModelRepo.On("Create", mockModel).Return(nil).Once()
-		usecase := usecase.NewModelUsecase(mockModelRepo)
+		usecase := usecase.NewModelUsecase(mockModelRepo, mockAPIServerRepo, nil)
 
 		mockModel.Schema = "{\"type\": \"object\", \"keys\": [], \"properties\": {\"id\": {\"type\":\"string\"}}}"
 
-		status, _, err := usecase.Create(mockModel)
+		status, _, err := usecase.Create(context.Background(), mockModel)
 
 		assert.Error(t, err)
 		assert.Equal(t, status, http.StatusBadRequest)
@@ -124,15 +132,49 @@ func TestCreate(t *testing.T) {
 	t.Run("存在しないプロパティをkeysで指定している", func(t *testing.T) {
 		mockModel.Schema = "{\"type\": \"object\", \"keys\": [\"id\", \"foo\"], \"properties\": {\"id\": {\"type\":\"string\"}}}"
 		mockModelRepo.On("Create", mockModel).Return(nil).Once()
-		usecase := usecase.NewModelUsecase(mockModelRepo)
+		usecase := usecase.NewModelUsecase(mockModelRepo, mockAPIServerRepo, nil)
 
-		status, _, err := usecase.Create(mockModel)
+		status, _, err := usecase.Create(context.Background(), mockModel)
 
 		assert.Error(t, err)
 		assert.Equal(t, status, http.StatusBadRequest)
 	})
 }
 
+func TestRestore(t *testing.T) {
+	mockModel := domain.Model{
+		ID:             "model-1",
+		Name:           "User",
+		CollectionName: "users",
+		Schema:         "{\"type\": \"object\", \"keys\": [\"id\"], \"properties\": {\"id\": {\"type\":\"string\"}}}",
+	}
+
+	t.Run("コレクション名を変えずに作成する", func(t *testing.T) {
+		mockModelRepo := new(mocks.ModelRepository)
+		mockModelRepo.On("Create", mockModel).Return(nil).Once()
+		usecase := usecase.NewModelUsecase(mockModelRepo, new(mocks.APIServerRepository), nil)
+
+		status, err := usecase.Restore(context.Background(), mockModel)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, status)
+		mockModelRepo.AssertExpectations(t)
+	})
+	t.Run("コレクション名が保存される前のModelは、コレクション名を空のまま作成する", func(t *testing.T) {
+		legacy := mockModel
+		legacy.CollectionName = ""
+		mockModelRepo := new(mocks.ModelRepository)
+		mockModelRepo.On("Create", legacy).Return(nil).Once()
+		usecase := usecase.NewModelUsecase(mockModelRepo, new(mocks.APIServerRepository), nil)
+
+		status, err := usecase.Restore(context.Background(), legacy)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, status)
+		mockModelRepo.AssertExpectations(t)
+	})
+}
+
 func TestUpdate(t *testing.T) {
 	modelId, _ := uuid.NewRandom()
 
@@ -141,49 +183,70 @@ func TestUpdate(t *testing.T) {
 	mockModel.Name = "name"
 	mockModel.Description = "description"
 	mockModel.Schema = "{\"type\": \"object\", \"keys\": [\"id\"], \"properties\": {\"id\": {\"type\":\"string\"}}}"
+	mockModel.CollectionName = modelId.String()
 
 	mockModelRepo := new(mocks.ModelRepository)
+	mockAPIServerRepo := new(mocks.APIServerRepository)
 
 	t.Run("test1", func(t *testing.T) {
+		mockModelRepo.On("GetByID", mockModel.ID).Return(mockModel, nil).Once()
 		mockModelRepo.On("Update", mockModel).Return(nil).Once()
-		usecase := usecase.NewModelUsecase(mockModelRepo)
+		usecase := usecase.NewModelUsecase(mockModelRepo, mockAPIServerRepo, nil)
 
-		status, err := usecase.Update(mockModel)
+		status, err := usecase.Update(context.Background(), mockModel)
 
 		assert.NoError(t, err)
 		assert.Equal(t, status, http.StatusOK)
 
 		mockModelRepo.AssertExpectations(t)
 	})
+	t.Run("コレクション名が保存されていないModelの名前を変更しても、コレクションは変わらない", func(t *testing.T) {
+		current := mockModel
+		current.CollectionName = ""
+		renamed := current
+		renamed.Name = "renamed"
+		expected := renamed
+		expected.CollectionName = current.Name
+
+		mockModelRepo.On("GetByID", mockModel.ID).Return(current, nil).Once()
+		mockModelRepo.On("Update", expected).Return(nil).Once()
+		usecase := usecase.NewModelUsecase(mockModelRepo, mockAPIServerRepo, nil)
+
+		status, err := usecase.Update(context.Background(), renamed)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		mockModelRepo.AssertExpectations(t)
+	})
 	t.Run("jsonschema形式でない", func(t *testing.T) {
 		mockModelRepo.On("Create", mockModel).Return(nil).Once()
-		usecase := usecase.NewModelUsecase(mockModelRepo)
+		usecase := usecase.NewModelUsecase(mockModelRepo, mockAPIServerRepo, nil)
 
 		mockModel.Schema = "test"
 
-		status, err := usecase.Update(mockModel)
+		status, err := usecase.Update(context.Background(), mockModel)
 
 		assert.Error(t, err)
 		assert.Equal(t, status, http.StatusBadRequest)
 	})
 	t.Run("keysがnil", func(t *testing.T) {
 		mockModelRepo.On("Create", mockModel).Return(nil).Once()
-		usecase := usecase.NewModelUsecase(mockModelRepo)
+		usecase := usecase.NewModelUsecase(mockModelRepo, mockAPIServerRepo, nil)
 
 		mockModel.Schema = "{\"type\": \"object\", \"properties\": {\"id\": {\"type\":\"string\"}}}"
 
-		status, err := usecase.Update(mockModel)
+		status, err := usecase.Update(context.Background(), mockModel)
 
 		assert.Error(t, err)
 		assert.Equal(t, status, http.StatusBadRequest)
 	})
 	t.Run("keysにpropertyが指定されていない", func(t *testing.T) {
 		mockModelRepo.On("Create", mockModel).Return(nil).Once()
-		usecase := usecase.NewModelUsecase(mockModelRepo)
+		usecase := usecase.NewModelUsecase(mockModelRepo, mockAPIServerRepo, nil)
 
 		mockModel.Schema = "{\"type\": \"object\", \"keys\": [], \"properties\": {\"id\": {\"type\":\"string\"}}}"
 
-		status, err := usecase.Update(mockModel)
+		status, err := usecase.Update(context.Background(), mockModel)
 
 		assert.Error(t, err)
 		assert.Equal(t, status, http.StatusBadRequest)
@@ -191,9 +254,9 @@ func TestUpdate(t *testing.T) {
 	t.Run("存在しないプロパティをkeysで指定している", func(t *testing.T) {
 		mockModel.Schema = "{\"type\": \"object\", \"keys\": [\"id\", \"foo\"], \"properties\": {\"id\": {\"type\":\"string\"}}}"
 		mockModelRepo.On("Create", mockModel).Return(nil).Once()
-		usecase := usecase.NewModelUsecase(mockModelRepo)
+		usecase := usecase.NewModelUsecase(mockModelRepo, mockAPIServerRepo, nil)
 
-		status, err := usecase.Update(mockModel)
+		status, err := usecase.Update(context.Background(), mockModel)
 
 		assert.Error(t, err)
 		assert.Equal(t, status, http.StatusBadRequest)
@@ -210,15 +273,188 @@ func TestDelete(t *testing.T) {
 	mockModel.Schema = "schema"
 
 	mockModelRepo := new(mocks.ModelRepository)
+	mockAPIServerRepo := new(mocks.APIServerRepository)
 
 	t.Run("test1", func(t *testing.T) {
 		mockModelRepo.On("Delete", mockModel.ID).Return(nil).Once()
-		usecase := usecase.NewModelUsecase(mockModelRepo)
+		usecase := usecase.NewModelUsecase(mockModelRepo, mockAPIServerRepo, nil)
+
+		err := usecase.Delete(context.Background(), mockModel.ID)
+
+		assert.NoError(t, err)
+
+		mockModelRepo.AssertExpectations(t)
+	})
+}
+
+func TestRename(t *testing.T) {
+	modelId, _ := uuid.NewRandom()
+
+	mockModel := domain.Model{}
+	mockModel.ID = modelId.String()
+	mockModel.Name = "name"
+	mockModel.Description = "description"
+	mockModel.CollectionName = modelId.String()
+
+	mockModelRepo := new(mocks.ModelRepository)
+	mockAPIServerRepo := new(mocks.APIServerRepository)
+
+	t.Run("名前のみ変更", func(t *testing.T) {
+		renamedModel := mockModel
+		renamedModel.Name = "renamed"
+
+		mockModelRepo.On("GetByID", mockModel.ID).Return(mockModel, nil).Once()
+		mockModelRepo.On("Update", renamedModel).Return(nil).Once()
+		usecase := usecase.NewModelUsecase(mockModelRepo, mockAPIServerRepo, nil)
+
+		status, err := usecase.Rename(context.Background(), mockModel.ID, domain.RenameModelRequest{Name: "renamed"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+
+		mockModelRepo.AssertExpectations(t)
+		mockAPIServerRepo.AssertNotCalled(t, "RenameCollection", mock.Anything, mock.Anything)
+	})
+	t.Run("コレクション名も変更", func(t *testing.T) {
+		renamedModel := mockModel
+		renamedModel.Name = "renamed"
+		renamedModel.CollectionName = "renamed"
+
+		mockModelRepo.On("GetByID", mockModel.ID).Return(mockModel, nil).Once()
+		mockAPIServerRepo.On("RenameCollection", mockModel.CollectionName, "renamed").Return("", http.StatusNoContent, nil).Once()
+		mockModelRepo.On("Update", renamedModel).Return(nil).Once()
+		usecase := usecase.NewModelUsecase(mockModelRepo, mockAPIServerRepo, nil)
 
-		err := usecase.Delete(mockModel.ID)
+		status, err := usecase.Rename(context.Background(), mockModel.ID, domain.RenameModelRequest{Name: "renamed", RenameCollection: true})
 
 		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
 
 		mockModelRepo.AssertExpectations(t)
+		mockAPIServerRepo.AssertExpectations(t)
+	})
+	t.Run("Modelの更新に失敗した場合は、コレクション名を元に戻す", func(t *testing.T) {
+		renamedModel := mockModel
+		renamedModel.Name = "renamed"
+		renamedModel.CollectionName = "renamed"
+
+		mockModelRepo.On("GetByID", mockModel.ID).Return(mockModel, nil).Once()
+		mockAPIServerRepo.On("RenameCollection", mockModel.CollectionName, "renamed").Return("", http.StatusNoContent, nil).Once()
+		mockModelRepo.On("Update", renamedModel).Return(errors.New("update failed")).Once()
+		mockAPIServerRepo.On("RenameCollection", "renamed", mockModel.CollectionName).Return("", http.StatusNoContent, nil).Once()
+		usecase := usecase.NewModelUsecase(mockModelRepo, mockAPIServerRepo, nil)
+
+		status, err := usecase.Rename(context.Background(), mockModel.ID, domain.RenameModelRequest{Name: "renamed", RenameCollection: true})
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusInternalServerError, status)
+		mockAPIServerRepo.AssertExpectations(t)
+	})
+	t.Run("コレクション名を持たない既存のModel", func(t *testing.T) {
+		legacyModel := mockModel
+		legacyModel.CollectionName = ""
+		renamedModel := legacyModel
+		renamedModel.Name = "renamed"
+		// 変更前の名前をコレクション名として引き継ぐ
+		renamedModel.CollectionName = "name"
+
+		mockModelRepo.On("GetByID", mockModel.ID).Return(legacyModel, nil).Once()
+		mockModelRepo.On("Update", renamedModel).Return(nil).Once()
+		usecase := usecase.NewModelUsecase(mockModelRepo, mockAPIServerRepo, nil)
+
+		status, err := usecase.Rename(context.Background(), mockModel.ID, domain.RenameModelRequest{Name: "renamed"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+
+		mockModelRepo.AssertExpectations(t)
+	})
+	t.Run("コレクション名が不正", func(t *testing.T) {
+		mockModelRepo.On("GetByID", mockModel.ID).Return(mockModel, nil).Once()
+		usecase := usecase.NewModelUsecase(mockModelRepo, mockAPIServerRepo, nil)
+
+		status, err := usecase.Rename(context.Background(), mockModel.ID, domain.RenameModelRequest{Name: "renamed", RenameCollection: true, CollectionName: "$foo"})
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+}
+
+func TestGenerate(t *testing.T) {
+	modelId, _ := uuid.NewRandom()
+	seed := int64(1)
+
+	mockModel := domain.Model{}
+	mockModel.ID = modelId.String()
+	mockModel.Name = "Post"
+	mockModel.CollectionName = modelId.String()
+	mockModel.Schema = "{\"type\": \"object\", \"keys\": [\"id\"], \"properties\": {\"id\": {\"type\":\"string\"}, \"postedUserId\": {\"type\":\"string\", \"x-ref\": \"User\"}}, \"required\": [\"id\", \"postedUserId\"]}"
+
+	userModel := domain.Model{}
+	userModel.ID = "user-model-id"
+	userModel.Name = "User"
+	userModel.CollectionName = "user-collection"
+	userModel.Schema = "{\"type\": \"object\", \"keys\": [\"id\"], \"properties\": {\"id\": {\"type\":\"string\"}}}"
+
+	users := []map[string]interface{}{{"id": "user-1"}, {"id": "user-2"}}
+
+	mockModelRepo := new(mocks.ModelRepository)
+	mockAPIServerRepo := new(mocks.APIServerRepository)
+
+	t.Run("生成のみ", func(t *testing.T) {
+		mockModelRepo.On("GetByID", mockModel.ID).Return(mockModel, nil).Once()
+		mockModelRepo.On("GetAll").Return([]domain.Model{mockModel, userModel}, nil).Once()
+		mockAPIServerRepo.On("GetList", mockModel.CollectionName, "", "").Return("", http.StatusNotFound, errors.New("record not found")).Once()
+		mockAPIServerRepo.On("GetList", userModel.CollectionName, "", "").Return(users, http.StatusOK, nil).Once()
+		usecase := usecase.NewModelUsecase(mockModelRepo, mockAPIServerRepo, nil)
+
+		status, result, err := usecase.Generate(mockModel.ID, domain.GenerateRequest{Count: 3, Seed: &seed})
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, seed, result.Seed)
+		assert.Len(t, result.Documents, 3)
+		assert.Equal(t, 0, result.Inserted)
+		for _, document := range result.Documents {
+			assert.Contains(t, []interface{}{"user-1", "user-2"}, document["postedUserId"])
+		}
+
+		mockAPIServerRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("生成してコレクションに登録", func(t *testing.T) {
+		mockModelRepo.On("GetByID", mockModel.ID).Return(mockModel, nil).Once()
+		mockModelRepo.On("GetAll").Return([]domain.Model{mockModel, userModel}, nil).Once()
+		mockAPIServerRepo.On("GetList", mockModel.CollectionName, "", "").Return("", http.StatusNotFound, errors.New("record not found")).Once()
+		mockAPIServerRepo.On("GetList", userModel.CollectionName, "", "").Return(users, http.StatusOK, nil).Once()
+		mockAPIServerRepo.On("Create", mockModel.CollectionName, "id", mock.Anything).Return("", http.StatusCreated, nil).Times(2)
+		usecase := usecase.NewModelUsecase(mockModelRepo, mockAPIServerRepo, nil)
+
+		status, result, err := usecase.Generate(mockModel.ID, domain.GenerateRequest{Count: 2, Seed: &seed, Insert: true})
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, 2, result.Inserted)
+
+		mockAPIServerRepo.AssertExpectations(t)
+	})
+	t.Run("件数が上限を超えている", func(t *testing.T) {
+		usecase := usecase.NewModelUsecase(mockModelRepo, mockAPIServerRepo, nil)
+
+		status, _, err := usecase.Generate(mockModel.ID, domain.GenerateRequest{Count: 100000})
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+	t.Run("生成できないpattern", func(t *testing.T) {
+		invalidModel := mockModel
+		invalidModel.Schema = "{\"type\": \"object\", \"keys\": [\"id\"], \"properties\": {\"id\": {\"type\":\"string\", \"pattern\": \"[^\\\\s\\\\S]\"}}}"
+		mockModelRepo.On("GetByID", mockModel.ID).Return(invalidModel, nil).Once()
+		mockAPIServerRepo.On("GetList", mockModel.CollectionName, "", "").Return("", http.StatusNotFound, errors.New("record not found")).Once()
+		usecase := usecase.NewModelUsecase(mockModelRepo, mockAPIServerRepo, nil)
+
+		status, _, err := usecase.Generate(mockModel.ID, domain.GenerateRequest{Count: 1, Seed: &seed})
+
+		assert.Contains(t, err.Error(), generator.ErrPatternNoMatch.Error())
+		assert.Equal(t, http.StatusBadRequest, status)
 	})
 }
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/openapi/usecase"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/openapi"
+	"github.com/Hajime3778/api-creator-backend/pkg/yamljson"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OpenAPIHandler OpenAPIAPIに対するリクエストハンドラ
+type OpenAPIHandler struct {
+	usecase usecase.OpenAPIUsecase
+}
+
+// NewOpenAPIHandler OpenAPIHandlerを作成します
+func NewOpenAPIHandler(r *gin.RouterGroup, u usecase.OpenAPIUsecase) {
+	handler := &OpenAPIHandler{
+		usecase: u,
+	}
+	r.GET("/openapi", handler.GetAll)
+	// apiに紐づいたOpenAPIのルート
+	r.GET("/apis/:id/openapi", handler.GetByAPIID)
+}
+
+// GetAll すべてのAPIのOpenAPIのドキュメントを取得します
+// クエリストリングの"format=yaml"でYAMLとして取得できます
+func (h *OpenAPIHandler) GetAll(c *gin.Context) {
+	doc, status, err := h.usecase.GetAll()
+	respondDocument(c, doc, status, err)
+}
+
+// GetByAPIID APIのOpenAPIのドキュメントを取得します
+func (h *OpenAPIHandler) GetByAPIID(c *gin.Context) {
+	doc, status, err := h.usecase.GetByAPIID(c.Param("id"))
+	respondDocument(c, doc, status, err)
+}
+
+func respondDocument(c *gin.Context, doc *openapi.Document, status int, err error) {
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+
+	if c.Query("format") != "yaml" {
+		c.JSON(http.StatusOK, doc)
+		return
+	}
+	b, err := yamljson.Marshal(doc)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+	c.Data(http.StatusOK, "application/x-yaml", b)
+}
@@ -0,0 +1,79 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/openapi/handler"
+	"github.com/Hajime3778/api-creator-backend/pkg/openapi"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+	"github.com/jinzhu/gorm"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+var mockDocument = &openapi.Document{
+	OpenAPI: openapi.Version,
+	Info:    openapi.Info{Title: "api-creator", Version: "1.0.0"},
+	Paths:   map[string]openapi.PathItem{},
+}
+
+func newMockRouter() (*gin.Engine, *gin.RouterGroup) {
+	router := gin.Default()
+	apiV1 := router.Group("api/v1")
+
+	return router, apiV1
+}
+
+func TestGetAll(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("JSON", func(t *testing.T) {
+		mockUsecase := new(mocks.OpenAPIUsecase)
+		mockUsecase.On("GetAll").Return(mockDocument, http.StatusOK, nil).Once()
+
+		router, rg := newMockRouter()
+		handler.NewOpenAPIHandler(rg, mockUsecase)
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/openapi", nil)
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `{"openapi": "3.0.3", "info": {"title": "api-creator", "version": "1.0.0"}, "paths": {}, "components": {"schemas": null}}`, res.Body.String())
+	})
+	t.Run("YAML", func(t *testing.T) {
+		mockUsecase := new(mocks.OpenAPIUsecase)
+		mockUsecase.On("GetAll").Return(mockDocument, http.StatusOK, nil).Once()
+
+		router, rg := newMockRouter()
+		handler.NewOpenAPIHandler(rg, mockUsecase)
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/openapi?format=yaml", nil)
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, "application/x-yaml", res.Header().Get("Content-Type"))
+		assert.Contains(t, res.Body.String(), "openapi: 3.0.3\n")
+	})
+}
+
+func TestGetByAPIID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUsecase := new(mocks.OpenAPIUsecase)
+	mockUsecase.On("GetByAPIID", "none").Return(nil, http.StatusNotFound, gorm.ErrRecordNotFound).Once()
+
+	router, rg := newMockRouter()
+	handler.NewOpenAPIHandler(rg, mockUsecase)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/apis/none/openapi", nil)
+	router.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusNotFound, res.Code)
+	assert.JSONEq(t, `{"error": "record not found"}`, res.Body.String())
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"net/http"
+
+	_apiRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/api/repository"
+	_methodRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/method/repository"
+	_modelRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/model/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/openapi"
+	"github.com/jinzhu/gorm"
+)
+
+// documentTitle OpenAPIのドキュメントのタイトル
+const documentTitle = "api-creator"
+
+// documentVersion OpenAPIのドキュメントのバージョン(APIにバージョンがないため固定)
+const documentVersion = "1.0.0"
+
+// OpenAPIUsecase Interface
+type OpenAPIUsecase interface {
+	GetAll() (*openapi.Document, int, error)
+	GetByAPIID(id string) (*openapi.Document, int, error)
+}
+
+type openAPIUsecase struct {
+	apiRepo          _apiRepository.APIRepository
+	methodRepo       _methodRepository.MethodRepository
+	modelRepo        _modelRepository.ModelRepository
+	apiServerBaseURL string
+}
+
+// NewOpenAPIUsecase OpenAPIUsecaseインターフェイスを表すオブジェクトを作成します
+func NewOpenAPIUsecase(apiRepo _apiRepository.APIRepository, methodRepo _methodRepository.MethodRepository, modelRepo _modelRepository.ModelRepository, apiServerBaseURL string) OpenAPIUsecase {
+	return &openAPIUsecase{
+		apiRepo:          apiRepo,
+		methodRepo:       methodRepo,
+		modelRepo:        modelRepo,
+		apiServerBaseURL: apiServerBaseURL,
+	}
+}
+
+// GetAll すべてのAPIのOpenAPIのドキュメントを取得します
+func (u *openAPIUsecase) GetAll() (*openapi.Document, int, error) {
+	apis, err := u.apiRepo.GetAll()
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return u.generate(documentTitle, apis)
+}
+
+// GetByAPIID APIのOpenAPIのドキュメントを取得します
+func (u *openAPIUsecase) GetByAPIID(id string) (*openapi.Document, int, error) {
+	api, err := u.apiRepo.GetByID(id)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, http.StatusNotFound, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+	return u.generate(api.Name, []domain.API{api})
+}
+
+// generate APIのModelとMethodを取得し、ドキュメントを作成します
+func (u *openAPIUsecase) generate(title string, apis []domain.API) (*openapi.Document, int, error) {
+	definitions := []openapi.Definition{}
+	for _, api := range apis {
+		model, err := u.modelRepo.GetByAPIID(api.ID)
+		if err != nil && !gorm.IsRecordNotFoundError(err) {
+			return nil, http.StatusInternalServerError, err
+		}
+		methods, err := u.methodRepo.GetListByAPIID(api.ID)
+		if err != nil && !gorm.IsRecordNotFoundError(err) {
+			return nil, http.StatusInternalServerError, err
+		}
+		definitions = append(definitions, openapi.Definition{API: api, Model: model, Methods: methods})
+	}
+
+	doc, err := openapi.Generate(openapi.Info{Title: title, Version: documentVersion}, u.apiServerBaseURL, definitions)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	return doc, http.StatusOK, nil
+}
@@ -0,0 +1,77 @@
+package usecase_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/openapi/usecase"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+	"github.com/jinzhu/gorm"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var mockAPI = domain.API{ID: "api", Name: "users", URL: "users"}
+
+var mockModel = domain.Model{
+	ID:     "model",
+	APIID:  "api",
+	Name:   "User",
+	Schema: `{"type": "object", "keys": ["id"], "properties": {"id": {"type": "string"}}}`,
+}
+
+var mockMethods = []domain.Method{{ID: "method", APIID: "api", Type: "GET", URL: "/{id}"}}
+
+func TestGetAll(t *testing.T) {
+	mockAPIRepo := new(mocks.APIRepository)
+	mockMethodRepo := new(mocks.MethodRepository)
+	mockModelRepo := new(mocks.ModelRepository)
+
+	mockAPIRepo.On("GetAll").Return([]domain.API{mockAPI, {ID: "no-model", Name: "mock", URL: "mock"}}, nil).Once()
+	mockModelRepo.On("GetByAPIID", "api").Return(mockModel, nil).Once()
+	mockMethodRepo.On("GetListByAPIID", "api").Return(mockMethods, nil).Once()
+	mockModelRepo.On("GetByAPIID", "no-model").Return(domain.Model{}, gorm.ErrRecordNotFound).Once()
+	mockMethodRepo.On("GetListByAPIID", "no-model").Return([]domain.Method{{Type: "POST"}}, nil).Once()
+	usecase := usecase.NewOpenAPIUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, "http://localhost:9000/")
+
+	doc, status, err := usecase.GetAll()
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "api-creator", doc.Info.Title)
+	assert.Equal(t, "http://localhost:9000", doc.Servers[0].URL)
+	assert.Contains(t, doc.Paths, "/users/{id}")
+	// Modelが未定義のAPIも含める
+	assert.Contains(t, doc.Paths, "/mock")
+	assert.Contains(t, doc.Components.Schemas, "User")
+}
+
+func TestGetByAPIID(t *testing.T) {
+	mockAPIRepo := new(mocks.APIRepository)
+	mockMethodRepo := new(mocks.MethodRepository)
+	mockModelRepo := new(mocks.ModelRepository)
+
+	t.Run("正常系", func(t *testing.T) {
+		mockAPIRepo.On("GetByID", "api").Return(mockAPI, nil).Once()
+		mockModelRepo.On("GetByAPIID", "api").Return(mockModel, nil).Once()
+		mockMethodRepo.On("GetListByAPIID", "api").Return(mockMethods, nil).Once()
+		usecase := usecase.NewOpenAPIUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, "")
+
+		doc, status, err := usecase.GetByAPIID("api")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "users", doc.Info.Title)
+		assert.Len(t, doc.Paths, 1)
+	})
+	t.Run("APIが存在しない", func(t *testing.T) {
+		mockAPIRepo.On("GetByID", "none").Return(domain.API{}, gorm.ErrRecordNotFound).Once()
+		usecase := usecase.NewOpenAPIUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, "")
+
+		_, status, err := usecase.GetByAPIID("none")
+
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+}
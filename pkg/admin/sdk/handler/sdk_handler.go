@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/sdk/usecase"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SDKHandler SDKAPIに対するリクエストハンドラ
+type SDKHandler struct {
+	usecase usecase.SDKUsecase
+}
+
+// NewSDKHandler SDKHandlerを作成します
+func NewSDKHandler(r *gin.RouterGroup, u usecase.SDKUsecase) {
+	handler := &SDKHandler{
+		usecase: u,
+	}
+	r.GET("/sdk/:language", handler.GetAll)
+	// apiに紐づいたSDKのルート
+	r.GET("/apis/:id/sdk/:language", handler.GetByAPIID)
+}
+
+// GetAll すべてのAPIのSDKをzipでダウンロードします
+// languageは"go"または"typescript"、クエリストリングの"package"でパッケージ名を指定できます
+func (h *SDKHandler) GetAll(c *gin.Context) {
+	file, status, err := h.usecase.GetAll(c.Param("language"), c.Query("package"))
+	respondFile(c, file, status, err)
+}
+
+// GetByAPIID APIのSDKをzipでダウンロードします
+func (h *SDKHandler) GetByAPIID(c *gin.Context) {
+	file, status, err := h.usecase.GetByAPIID(c.Param("id"), c.Param("language"), c.Query("package"))
+	respondFile(c, file, status, err)
+}
+
+func respondFile(c *gin.Context, file domain.GeneratedFile, status int, err error) {
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, file.Name))
+	c.Data(http.StatusOK, "application/zip", file.Content)
+}
@@ -0,0 +1,76 @@
+package handler_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/sdk/handler"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMockRouter() (*gin.Engine, *gin.RouterGroup) {
+	router := gin.Default()
+	apiV1 := router.Group("api/v1")
+
+	return router, apiV1
+}
+
+func TestGetAll(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockFile := domain.GeneratedFile{Name: "apicreator-go-sdk.zip", Content: []byte("zip")}
+	mockUsecase := new(mocks.SDKUsecase)
+	mockUsecase.On("GetAll", "go", "myapi").Return(mockFile, http.StatusOK, nil).Once()
+
+	router, rg := newMockRouter()
+	handler.NewSDKHandler(rg, mockUsecase)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/sdk/go?package=myapi", nil)
+	router.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "application/zip", res.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="apicreator-go-sdk.zip"`, res.Header().Get("Content-Disposition"))
+	assert.Equal(t, "zip", res.Body.String())
+}
+
+func TestGetByAPIID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("正常系", func(t *testing.T) {
+		mockFile := domain.GeneratedFile{Name: "users-typescript-sdk.zip", Content: []byte("zip")}
+		mockUsecase := new(mocks.SDKUsecase)
+		mockUsecase.On("GetByAPIID", "api", "typescript", "").Return(mockFile, http.StatusOK, nil).Once()
+
+		router, rg := newMockRouter()
+		handler.NewSDKHandler(rg, mockUsecase)
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/apis/api/sdk/typescript", nil)
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, `attachment; filename="users-typescript-sdk.zip"`, res.Header().Get("Content-Disposition"))
+	})
+	t.Run("エラー", func(t *testing.T) {
+		mockUsecase := new(mocks.SDKUsecase)
+		mockUsecase.On("GetByAPIID", "api", "java", "").Return(domain.GeneratedFile{}, http.StatusBadRequest, errors.New("unsupported language")).Once()
+
+		router, rg := newMockRouter()
+		handler.NewSDKHandler(rg, mockUsecase)
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/apis/api/sdk/java", nil)
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.JSONEq(t, `{"error": "unsupported language"}`, res.Body.String())
+	})
+}
@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"net/http"
+
+	_apiRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/api/repository"
+	_methodRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/method/repository"
+	_modelRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/model/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/sdk"
+	"github.com/jinzhu/gorm"
+)
+
+// projectPackageName すべてのAPIのSDKを作成する場合の、既定のパッケージ名
+const projectPackageName = "apicreator"
+
+// SDKUsecase Interface
+type SDKUsecase interface {
+	GetByAPIID(id string, language string, packageName string) (domain.GeneratedFile, int, error)
+	GetAll(language string, packageName string) (domain.GeneratedFile, int, error)
+}
+
+type sdkUsecase struct {
+	apiRepo    _apiRepository.APIRepository
+	methodRepo _methodRepository.MethodRepository
+	modelRepo  _modelRepository.ModelRepository
+}
+
+// NewSDKUsecase SDKUsecaseインターフェイスを表すオブジェクトを作成します
+func NewSDKUsecase(apiRepo _apiRepository.APIRepository, methodRepo _methodRepository.MethodRepository, modelRepo _modelRepository.ModelRepository) SDKUsecase {
+	return &sdkUsecase{
+		apiRepo:    apiRepo,
+		methodRepo: methodRepo,
+		modelRepo:  modelRepo,
+	}
+}
+
+// GetByAPIID APIのSDKをzipで取得します。packageNameが未指定の場合はAPIのURLから作成します
+func (u *sdkUsecase) GetByAPIID(id string, language string, packageName string) (domain.GeneratedFile, int, error) {
+	api, err := u.apiRepo.GetByID(id)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return domain.GeneratedFile{}, http.StatusNotFound, err
+		}
+		return domain.GeneratedFile{}, http.StatusInternalServerError, err
+	}
+
+	definition, err := u.getDefinition(api)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return domain.GeneratedFile{}, http.StatusBadRequest, sdk.ErrModelNotDeclare
+		}
+		return domain.GeneratedFile{}, http.StatusInternalServerError, err
+	}
+
+	if packageName == "" {
+		packageName = api.URL
+	}
+	return generate(language, packageName, []sdk.Definition{definition})
+}
+
+// GetAll すべてのAPIのSDKを1つのパッケージとしてzipで取得します
+// Modelが定義されていないAPIは含めません
+func (u *sdkUsecase) GetAll(language string, packageName string) (domain.GeneratedFile, int, error) {
+	apis, err := u.apiRepo.GetAll()
+	if err != nil {
+		return domain.GeneratedFile{}, http.StatusInternalServerError, err
+	}
+
+	definitions := []sdk.Definition{}
+	for _, api := range apis {
+		definition, err := u.getDefinition(api)
+		if err != nil {
+			if gorm.IsRecordNotFoundError(err) {
+				continue
+			}
+			return domain.GeneratedFile{}, http.StatusInternalServerError, err
+		}
+		definitions = append(definitions, definition)
+	}
+
+	if packageName == "" {
+		packageName = projectPackageName
+	}
+	return generate(language, packageName, definitions)
+}
+
+// getDefinition APIのModelとMethodを取得します
+func (u *sdkUsecase) getDefinition(api domain.API) (sdk.Definition, error) {
+	model, err := u.modelRepo.GetByAPIID(api.ID)
+	if err != nil {
+		return sdk.Definition{}, err
+	}
+	methods, err := u.methodRepo.GetListByAPIID(api.ID)
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return sdk.Definition{}, err
+	}
+	return sdk.Definition{API: api, Model: model, Methods: methods}, nil
+}
+
+// generate SDKを作成し、zipにまとめます
+func generate(language string, packageName string, definitions []sdk.Definition) (domain.GeneratedFile, int, error) {
+	files, err := sdk.Generate(language, packageName, definitions)
+	if err != nil {
+		return domain.GeneratedFile{}, http.StatusBadRequest, err
+	}
+
+	var b bytes.Buffer
+	w := zip.NewWriter(&b)
+	for _, file := range files {
+		f, err := w.Create(file.Name)
+		if err != nil {
+			return domain.GeneratedFile{}, http.StatusInternalServerError, err
+		}
+		if _, err := f.Write(file.Content); err != nil {
+			return domain.GeneratedFile{}, http.StatusInternalServerError, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return domain.GeneratedFile{}, http.StatusInternalServerError, err
+	}
+
+	return domain.GeneratedFile{
+		Name:    fmt.Sprintf("%s-%s-sdk.zip", sdk.PackageName(packageName), language),
+		Content: b.Bytes(),
+	}, http.StatusOK, nil
+}
@@ -0,0 +1,112 @@
+package usecase_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/sdk/usecase"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/sdk"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+	"github.com/jinzhu/gorm"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var mockAPI = domain.API{ID: "api", Name: "users", URL: "users"}
+
+var mockModel = domain.Model{
+	ID:     "model",
+	APIID:  "api",
+	Name:   "User",
+	Schema: `{"type": "object", "keys": ["id"], "properties": {"id": {"type": "string"}}}`,
+}
+
+var mockMethods = []domain.Method{{ID: "method", APIID: "api", Type: "GET", URL: "/{id}"}}
+
+// zipFileNames zipに含まれるファイル名を取得します
+func zipFileNames(t *testing.T, content []byte) []string {
+	r, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	assert.NoError(t, err)
+	names := []string{}
+	for _, f := range r.File {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func TestGetByAPIID(t *testing.T) {
+	mockAPIRepo := new(mocks.APIRepository)
+	mockMethodRepo := new(mocks.MethodRepository)
+	mockModelRepo := new(mocks.ModelRepository)
+
+	t.Run("Go", func(t *testing.T) {
+		mockAPIRepo.On("GetByID", "api").Return(mockAPI, nil).Once()
+		mockModelRepo.On("GetByAPIID", "api").Return(mockModel, nil).Once()
+		mockMethodRepo.On("GetListByAPIID", "api").Return(mockMethods, nil).Once()
+		usecase := usecase.NewSDKUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo)
+
+		file, status, err := usecase.GetByAPIID("api", sdk.LanguageGo, "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "users-go-sdk.zip", file.Name)
+		assert.Equal(t, []string{"users/client.go", "users/users.go"}, zipFileNames(t, file.Content))
+	})
+	t.Run("パッケージ名を指定", func(t *testing.T) {
+		mockAPIRepo.On("GetByID", "api").Return(mockAPI, nil).Once()
+		mockModelRepo.On("GetByAPIID", "api").Return(mockModel, nil).Once()
+		mockMethodRepo.On("GetListByAPIID", "api").Return(mockMethods, nil).Once()
+		usecase := usecase.NewSDKUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo)
+
+		file, _, err := usecase.GetByAPIID("api", sdk.LanguageTypeScript, "client")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "client-typescript-sdk.zip", file.Name)
+		assert.Equal(t, []string{"client.ts"}, zipFileNames(t, file.Content))
+	})
+	t.Run("対応していない言語", func(t *testing.T) {
+		mockAPIRepo.On("GetByID", "api").Return(mockAPI, nil).Once()
+		mockModelRepo.On("GetByAPIID", "api").Return(mockModel, nil).Once()
+		mockMethodRepo.On("GetListByAPIID", "api").Return(mockMethods, nil).Once()
+		usecase := usecase.NewSDKUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo)
+
+		_, status, err := usecase.GetByAPIID("api", "java", "")
+
+		assert.Equal(t, sdk.ErrUnsupportedLanguage, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+	t.Run("Modelが未定義", func(t *testing.T) {
+		mockAPIRepo.On("GetByID", "api").Return(mockAPI, nil).Once()
+		mockModelRepo.On("GetByAPIID", "api").Return(domain.Model{}, gorm.ErrRecordNotFound).Once()
+		usecase := usecase.NewSDKUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo)
+
+		_, status, err := usecase.GetByAPIID("api", sdk.LanguageGo, "")
+
+		assert.Equal(t, sdk.ErrModelNotDeclare, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+}
+
+func TestGetAll(t *testing.T) {
+	mockAPIRepo := new(mocks.APIRepository)
+	mockMethodRepo := new(mocks.MethodRepository)
+	mockModelRepo := new(mocks.ModelRepository)
+
+	mockAPIRepo.On("GetAll").Return([]domain.API{mockAPI, {ID: "no-model", URL: "mock"}}, nil).Once()
+	mockModelRepo.On("GetByAPIID", "api").Return(mockModel, nil).Once()
+	mockModelRepo.On("GetByAPIID", "no-model").Return(domain.Model{}, gorm.ErrRecordNotFound).Once()
+	mockMethodRepo.On("GetListByAPIID", "api").Return(mockMethods, nil).Once()
+	usecase := usecase.NewSDKUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo)
+
+	file, status, err := usecase.GetAll(sdk.LanguageGo, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "apicreator-go-sdk.zip", file.Name)
+	// Modelが未定義のAPIは含めない
+	assert.Equal(t, []string{"apicreator/client.go", "apicreator/users.go"}, zipFileNames(t, file.Content))
+	mockModelRepo.AssertExpectations(t)
+}
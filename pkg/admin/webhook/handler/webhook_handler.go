@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/webhook/usecase"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+// WebhookHandler WebhookAPIに対するリクエストハンドラ
+type WebhookHandler struct {
+	usecase usecase.WebhookUsecase
+}
+
+// NewWebhookHandler WebhookHandlerを作成します
+func NewWebhookHandler(r *gin.RouterGroup, u usecase.WebhookUsecase) {
+	handler := &WebhookHandler{
+		usecase: u,
+	}
+	webhookRoutes := r.Group("/webhooks")
+	{
+		webhookRoutes.GET("/:id", handler.GetByID)
+		webhookRoutes.POST("", handler.Create)
+		webhookRoutes.PUT("", handler.Update)
+		webhookRoutes.DELETE("/:id", handler.Delete)
+		webhookRoutes.GET("/:id/deliveries", handler.GetDeliveries)
+	}
+	deliveryRoutes := r.Group("/webhook-deliveries")
+	{
+		deliveryRoutes.GET("/:id/logs", handler.GetDeliveryLogs)
+		deliveryRoutes.POST("/:id/retry", handler.Retry)
+	}
+	r.GET("/webhook-dead-letters", handler.GetDeadLetters)
+	// apiに紐づいたwebhookのルート
+	r.GET("/apis/:id/webhooks", handler.GetListByAPIID)
+}
+
+// GetByID Webhookを1件取得します
+func (h *WebhookHandler) GetByID(c *gin.Context) {
+	id := c.Param("id")
+
+	result, err := h.usecase.GetByID(id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetListByAPIID WebhookをAPIIDで複数取得します
+func (h *WebhookHandler) GetListByAPIID(c *gin.Context) {
+	apiID := c.Param("id")
+
+	result, err := h.usecase.GetListByAPIID(apiID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Create Webhookを作成します
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var webhook domain.Webhook
+	c.BindJSON(&webhook)
+
+	status, id, err := h.usecase.Create(webhook)
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+	c.JSON(http.StatusCreated, domain.CreatedResponse{ID: id})
+}
+
+// Update Webhookを更新します
+func (h *WebhookHandler) Update(c *gin.Context) {
+	var webhook domain.Webhook
+	c.BindJSON(&webhook)
+
+	status, err := h.usecase.Update(webhook)
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, nil)
+}
+
+// Delete Webhookを削除します
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	err := h.usecase.Delete(id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetDeliveries Webhookへの送信履歴を取得します
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	id := c.Param("id")
+
+	result, err := h.usecase.GetDeliveries(id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetDeadLetters 再送の上限に達したWebhookDeliveryを取得します
+func (h *WebhookHandler) GetDeadLetters(c *gin.Context) {
+	result, err := h.usecase.GetDeadLetters()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetDeliveryLogs WebhookDeliveryの試行結果を取得します
+func (h *WebhookHandler) GetDeliveryLogs(c *gin.Context) {
+	id := c.Param("id")
+
+	result, err := h.usecase.GetDeliveryLogs(id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Retry 再送の上限に達したWebhookDeliveryを再送します
+func (h *WebhookHandler) Retry(c *gin.Context) {
+	id := c.Param("id")
+
+	status, err := h.usecase.Retry(id)
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, nil)
+}
+
+// respondError エラーの種類に応じたステータスでエラーを返却します
+func respondError(c *gin.Context, err error) {
+	if gorm.IsRecordNotFoundError(err) {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Error: err.Error()})
+	} else {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Error: err.Error()})
+	}
+	log.Println(err.Error())
+}
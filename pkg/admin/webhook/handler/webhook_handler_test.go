@@ -0,0 +1,125 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/webhook/handler"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+	"github.com/google/uuid"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func newMockRouter() (*gin.Engine, *gin.RouterGroup) {
+	router := gin.Default()
+	apiV1 := router.Group("api/v1")
+
+	return router, apiV1
+}
+
+func newMockWebhook() domain.Webhook {
+	webhookId, _ := uuid.NewRandom()
+	apiId, _ := uuid.NewRandom()
+
+	mockWebhook := domain.Webhook{}
+	mockWebhook.ID = webhookId.String()
+	mockWebhook.APIID = apiId.String()
+	mockWebhook.URL = "https://example.com/hooks"
+	mockWebhook.Secret = "secret"
+	return mockWebhook
+}
+
+func TestGetListByAPIID(t *testing.T) {
+	mockWebhook := newMockWebhook()
+
+	gin.SetMode(gin.TestMode)
+
+	mockWebhookUsecase := new(mocks.WebhookUsecase)
+	mockWebhookUsecase.On("GetListByAPIID", mockWebhook.APIID).Return([]domain.Webhook{mockWebhook}, nil).Once()
+
+	router, rg := newMockRouter()
+	handler.NewWebhookHandler(rg, mockWebhookUsecase)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/apis/"+mockWebhook.APIID+"/webhooks", nil)
+	router.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Code)
+}
+
+func TestCreate(t *testing.T) {
+	mockWebhook := newMockWebhook()
+
+	gin.SetMode(gin.TestMode)
+
+	mockWebhookUsecase := new(mocks.WebhookUsecase)
+	mockWebhookUsecase.On("Create", mockWebhook).Return(nil).Once()
+
+	router, rg := newMockRouter()
+	handler.NewWebhookHandler(rg, mockWebhookUsecase)
+
+	webhookJSON, _ := json.Marshal(mockWebhook)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/webhooks", bytes.NewReader(webhookJSON))
+	router.ServeHTTP(res, req)
+
+	assert.Equal(t, 201, res.Code)
+}
+
+func TestGetDeliveries(t *testing.T) {
+	mockWebhook := newMockWebhook()
+
+	gin.SetMode(gin.TestMode)
+
+	mockWebhookUsecase := new(mocks.WebhookUsecase)
+	mockWebhookUsecase.On("GetDeliveries", mockWebhook.ID).Return([]domain.WebhookDelivery{}, nil).Once()
+
+	router, rg := newMockRouter()
+	handler.NewWebhookHandler(rg, mockWebhookUsecase)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/webhooks/"+mockWebhook.ID+"/deliveries", nil)
+	router.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Code)
+}
+
+func TestGetDeadLetters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockWebhookUsecase := new(mocks.WebhookUsecase)
+	mockWebhookUsecase.On("GetDeadLetters").Return([]domain.WebhookDelivery{}, nil).Once()
+
+	router, rg := newMockRouter()
+	handler.NewWebhookHandler(rg, mockWebhookUsecase)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/webhook-dead-letters", nil)
+	router.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Code)
+}
+
+func TestRetry(t *testing.T) {
+	deliveryId, _ := uuid.NewRandom()
+
+	gin.SetMode(gin.TestMode)
+
+	mockWebhookUsecase := new(mocks.WebhookUsecase)
+	mockWebhookUsecase.On("Retry", deliveryId.String()).Return(nil).Once()
+
+	router, rg := newMockRouter()
+	handler.NewWebhookHandler(rg, mockWebhookUsecase)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/webhook-deliveries/"+deliveryId.String()+"/retry", nil)
+	router.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Code)
+}
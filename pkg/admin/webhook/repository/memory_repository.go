@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/memory"
+	"github.com/jinzhu/gorm"
+)
+
+type memoryWebhookRepository struct {
+	store *memory.Store
+}
+
+// NewMemoryWebhookRepository メモリ上のStoreを使用するWebhookRepositoryを作成します(開発用)
+func NewMemoryWebhookRepository(store *memory.Store) WebhookRepository {
+	return &memoryWebhookRepository{
+		store: store,
+	}
+}
+
+// GetByID Webhookを1件取得します
+func (r *memoryWebhookRepository) GetByID(id string) (domain.Webhook, error) {
+	webhook := domain.Webhook{}
+	err := gorm.ErrRecordNotFound
+	r.store.View(func(t *memory.Tables) {
+		if i := indexOfWebhook(t.Webhooks, id); i >= 0 {
+			webhook, err = t.Webhooks[i], nil
+		}
+	})
+	return webhook, err
+}
+
+// GetListByAPIID WebhookをAPIIDで複数取得します
+func (r *memoryWebhookRepository) GetListByAPIID(apiID string) ([]domain.Webhook, error) {
+	webhooks := []domain.Webhook{}
+	r.store.View(func(t *memory.Tables) {
+		for _, w := range t.Webhooks {
+			if w.APIID == apiID {
+				webhooks = append(webhooks, w)
+			}
+		}
+	})
+	return webhooks, nil
+}
+
+// Create Webhookを追加します
+func (r *memoryWebhookRepository) Create(webhook domain.Webhook) (string, error) {
+	err := r.store.Update(func(t *memory.Tables) error {
+		if indexOfWebhook(t.Webhooks, webhook.ID) >= 0 {
+			return memory.ErrDuplicateKey
+		}
+		memory.Created(&webhook.CommonColumn)
+		t.Webhooks = append(t.Webhooks, webhook)
+		return nil
+	})
+	return webhook.ID, err
+}
+
+// Update Webhookを更新します
+func (r *memoryWebhookRepository) Update(webhook domain.Webhook) error {
+	return r.store.Update(func(t *memory.Tables) error {
+		i := indexOfWebhook(t.Webhooks, webhook.ID)
+		if i < 0 {
+			return gorm.ErrRecordNotFound
+		}
+		memory.Saved(&webhook.CommonColumn, t.Webhooks[i].CommonColumn)
+		t.Webhooks[i] = webhook
+		return nil
+	})
+}
+
+// Delete Webhookと、送信待ちのWebhookDeliveryを削除します
+func (r *memoryWebhookRepository) Delete(id string) error {
+	return r.store.Update(func(t *memory.Tables) error {
+		i := indexOfWebhook(t.Webhooks, id)
+		if i < 0 {
+			return gorm.ErrRecordNotFound
+		}
+		t.Webhooks = append(t.Webhooks[:i], t.Webhooks[i+1:]...)
+
+		deliveries := t.WebhookDeliveries[:0]
+		for _, d := range t.WebhookDeliveries {
+			if d.WebhookID != id || d.Status != domain.DeliveryStatusPending {
+				deliveries = append(deliveries, d)
+			}
+		}
+		t.WebhookDeliveries = deliveries
+		return nil
+	})
+}
+
+// GetDeliveryByID WebhookDeliveryを1件取得します
+func (r *memoryWebhookRepository) GetDeliveryByID(id string) (domain.WebhookDelivery, error) {
+	delivery := domain.WebhookDelivery{}
+	err := gorm.ErrRecordNotFound
+	r.store.View(func(t *memory.Tables) {
+		if i := indexOfDelivery(t.WebhookDeliveries, id); i >= 0 {
+			delivery, err = t.WebhookDeliveries[i], nil
+		}
+	})
+	return delivery, err
+}
+
+// GetDeliveriesByWebhookID WebhookDeliveryをWebhookIDで新しい順に複数取得します
+func (r *memoryWebhookRepository) GetDeliveriesByWebhookID(webhookID string) ([]domain.WebhookDelivery, error) {
+	deliveries := []domain.WebhookDelivery{}
+	r.store.View(func(t *memory.Tables) {
+		for _, d := range t.WebhookDeliveries {
+			if d.WebhookID == webhookID {
+				deliveries = append(deliveries, d)
+			}
+		}
+	})
+	sortDeliveriesByNewest(deliveries)
+	return deliveries, nil
+}
+
+// GetDeliveriesByStatus WebhookDeliveryをステータスで新しい順に複数取得します
+func (r *memoryWebhookRepository) GetDeliveriesByStatus(status string) ([]domain.WebhookDelivery, error) {
+	deliveries := []domain.WebhookDelivery{}
+	r.store.View(func(t *memory.Tables) {
+		for _, d := range t.WebhookDeliveries {
+			if d.Status == status {
+				deliveries = append(deliveries, d)
+			}
+		}
+	})
+	sortDeliveriesByNewest(deliveries)
+	return deliveries, nil
+}
+
+// GetPendingDeliveries 送信時刻を過ぎた送信待ちのWebhookDeliveryを古い順に取得します
+func (r *memoryWebhookRepository) GetPendingDeliveries(now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	deliveries := []domain.WebhookDelivery{}
+	r.store.View(func(t *memory.Tables) {
+		for _, d := range t.WebhookDeliveries {
+			if d.Status == domain.DeliveryStatusPending && !d.NextAttemptAt.After(now) {
+				deliveries = append(deliveries, d)
+			}
+		}
+	})
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].NextAttemptAt.Before(deliveries[j].NextAttemptAt)
+	})
+	if limit > 0 && len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+// ClaimDelivery 取得時から変更されていない送信待ちのWebhookDeliveryの送信時刻をuntilまで延ばし、送信する権利を得ます
+// 他のDispatcherが先に送信時刻を変更していた場合はfalseを返却します
+func (r *memoryWebhookRepository) ClaimDelivery(delivery domain.WebhookDelivery, until time.Time) (bool, error) {
+	claimed := false
+	err := r.store.Update(func(t *memory.Tables) error {
+		i := indexOfDelivery(t.WebhookDeliveries, delivery.ID)
+		if i < 0 || t.WebhookDeliveries[i].Status != domain.DeliveryStatusPending || !t.WebhookDeliveries[i].NextAttemptAt.Equal(delivery.NextAttemptAt) {
+			return nil
+		}
+		t.WebhookDeliveries[i].NextAttemptAt = until
+		claimed = true
+		return nil
+	})
+	return claimed, err
+}
+
+// CreateDeliveries WebhookDeliveryをまとめて追加します
+func (r *memoryWebhookRepository) CreateDeliveries(deliveries []domain.WebhookDelivery) error {
+	return r.store.Update(func(t *memory.Tables) error {
+		for _, d := range deliveries {
+			if indexOfDelivery(t.WebhookDeliveries, d.ID) >= 0 {
+				return memory.ErrDuplicateKey
+			}
+			delivery := d
+			memory.Created(&delivery.CommonColumn)
+			t.WebhookDeliveries = append(t.WebhookDeliveries, delivery)
+		}
+		return nil
+	})
+}
+
+// UpdateDelivery 送信結果でWebhookDeliveryを更新し、試行結果を記録します
+func (r *memoryWebhookRepository) UpdateDelivery(delivery domain.WebhookDelivery, log domain.WebhookDeliveryLog) error {
+	return r.store.Update(func(t *memory.Tables) error {
+		if i := indexOfDelivery(t.WebhookDeliveries, delivery.ID); i >= 0 {
+			memory.Saved(&delivery.CommonColumn, t.WebhookDeliveries[i].CommonColumn)
+			t.WebhookDeliveries[i] = delivery
+		} else {
+			memory.Created(&delivery.CommonColumn)
+			t.WebhookDeliveries = append(t.WebhookDeliveries, delivery)
+		}
+		if log.ID == "" {
+			return nil
+		}
+		for _, l := range t.WebhookDeliveryLogs {
+			if l.ID == log.ID {
+				return memory.ErrDuplicateKey
+			}
+		}
+		memory.Created(&log.CommonColumn)
+		t.WebhookDeliveryLogs = append(t.WebhookDeliveryLogs, log)
+		return nil
+	})
+}
+
+// GetDeliveryLogs WebhookDeliveryの試行結果を古い順に取得します
+func (r *memoryWebhookRepository) GetDeliveryLogs(deliveryID string) ([]domain.WebhookDeliveryLog, error) {
+	logs := []domain.WebhookDeliveryLog{}
+	r.store.View(func(t *memory.Tables) {
+		for _, l := range t.WebhookDeliveryLogs {
+			if l.DeliveryID == deliveryID {
+				logs = append(logs, l)
+			}
+		}
+	})
+	sort.SliceStable(logs, func(i, j int) bool {
+		return logs[i].Attempt < logs[j].Attempt
+	})
+	return logs, nil
+}
+
+// sortDeliveriesByNewest WebhookDeliveryを作成日時の新しい順に並べ替えます
+func sortDeliveriesByNewest(deliveries []domain.WebhookDelivery) {
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+	})
+}
+
+// indexOfWebhook IDが一致するWebhookの位置を返却します。存在しない場合は-1です
+func indexOfWebhook(webhooks []domain.Webhook, id string) int {
+	for i := range webhooks {
+		if webhooks[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// indexOfDelivery IDが一致するWebhookDeliveryの位置を返却します。存在しない場合は-1です
+func indexOfDelivery(deliveries []domain.WebhookDelivery, id string) int {
+	for i := range deliveries {
+		if deliveries[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
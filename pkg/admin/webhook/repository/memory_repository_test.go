@@ -0,0 +1,87 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/webhook/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/memory"
+	"github.com/jinzhu/gorm"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryDeliveries(t *testing.T) {
+	store, err := memory.NewStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	webhookRepository := repository.NewMemoryWebhookRepository(store)
+	now := time.Now()
+
+	_, err = webhookRepository.Create(domain.Webhook{ID: "w1", APIID: "a1"})
+	assert.NoError(t, err)
+	err = webhookRepository.CreateDeliveries([]domain.WebhookDelivery{
+		{ID: "d1", WebhookID: "w1", Status: domain.DeliveryStatusPending, NextAttemptAt: now.Add(-time.Minute)},
+		{ID: "d2", WebhookID: "w1", Status: domain.DeliveryStatusPending, NextAttemptAt: now.Add(-time.Hour)},
+		{ID: "d3", WebhookID: "w1", Status: domain.DeliveryStatusPending, NextAttemptAt: now.Add(time.Hour)},
+		{ID: "d4", WebhookID: "w1", Status: domain.DeliveryStatusSucceeded, NextAttemptAt: now.Add(-time.Hour)},
+	})
+	assert.NoError(t, err)
+
+	t.Run("送信時刻を過ぎた送信待ちを古い順に取得する", func(t *testing.T) {
+		deliveries, err := webhookRepository.GetPendingDeliveries(now, 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"d2", "d1"}, []string{deliveries[0].ID, deliveries[1].ID})
+		assert.Len(t, deliveries, 2)
+
+		deliveries, _ = webhookRepository.GetPendingDeliveries(now, 1)
+		assert.Len(t, deliveries, 1)
+	})
+	t.Run("送信時刻を延ばして送信する権利を得る", func(t *testing.T) {
+		deliveries, _ := webhookRepository.GetPendingDeliveries(now, 10)
+		d2 := deliveries[0]
+
+		claimed, err := webhookRepository.ClaimDelivery(d2, now.Add(time.Minute))
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+
+		// 取得後に送信時刻が変更されている場合は、権利を得られない
+		claimed, err = webhookRepository.ClaimDelivery(d2, now.Add(time.Minute))
+		assert.NoError(t, err)
+		assert.False(t, claimed)
+
+		deliveries, _ = webhookRepository.GetPendingDeliveries(now, 10)
+		assert.Len(t, deliveries, 1)
+		assert.Equal(t, "d1", deliveries[0].ID)
+	})
+	t.Run("送信結果を記録する", func(t *testing.T) {
+		delivery, _ := webhookRepository.GetDeliveryByID("d1")
+		delivery.Status = domain.DeliveryStatusSucceeded
+		delivery.Attempts = 1
+
+		err := webhookRepository.UpdateDelivery(delivery, domain.WebhookDeliveryLog{ID: "l1", DeliveryID: "d1", Attempt: 1, StatusCode: 200})
+
+		assert.NoError(t, err)
+		updated, _ := webhookRepository.GetDeliveryByID("d1")
+		assert.Equal(t, domain.DeliveryStatusSucceeded, updated.Status)
+		assert.Equal(t, delivery.CreatedAt, updated.CreatedAt)
+		logs, _ := webhookRepository.GetDeliveryLogs("d1")
+		assert.Len(t, logs, 1)
+	})
+	t.Run("削除すると送信待ちも削除する", func(t *testing.T) {
+		err := webhookRepository.Delete("w1")
+
+		assert.NoError(t, err)
+		deliveries, _ := webhookRepository.GetDeliveriesByWebhookID("w1")
+		assert.Len(t, deliveries, 2)
+		for _, d := range deliveries {
+			assert.Equal(t, domain.DeliveryStatusSucceeded, d.Status)
+		}
+
+		err = webhookRepository.Delete("w1")
+		assert.True(t, gorm.IsRecordNotFoundError(err))
+	})
+}
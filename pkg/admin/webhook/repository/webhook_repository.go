@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	"github.com/jinzhu/gorm"
+)
+
+// WebhookRepository Interface
+type WebhookRepository interface {
+	GetByID(id string) (domain.Webhook, error)
+	GetListByAPIID(apiID string) ([]domain.Webhook, error)
+	Create(webhook domain.Webhook) (string, error)
+	Update(webhook domain.Webhook) error
+	Delete(id string) error
+	GetDeliveryByID(id string) (domain.WebhookDelivery, error)
+	GetDeliveriesByWebhookID(webhookID string) ([]domain.WebhookDelivery, error)
+	GetDeliveriesByStatus(status string) ([]domain.WebhookDelivery, error)
+	GetPendingDeliveries(now time.Time, limit int) ([]domain.WebhookDelivery, error)
+	ClaimDelivery(delivery domain.WebhookDelivery, until time.Time) (bool, error)
+	CreateDeliveries(deliveries []domain.WebhookDelivery) error
+	UpdateDelivery(delivery domain.WebhookDelivery, log domain.WebhookDeliveryLog) error
+	GetDeliveryLogs(deliveryID string) ([]domain.WebhookDeliveryLog, error)
+}
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository WebhookRepositoryインターフェイスを表すオブジェクトを作成します
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{
+		db: db,
+	}
+}
+
+// GetByID Webhookを1件取得します
+func (r *webhookRepository) GetByID(id string) (domain.Webhook, error) {
+	webhook := domain.Webhook{}
+	err := r.db.Where("id = ?", id).First(&webhook).Error
+
+	return webhook, err
+}
+
+// GetListByAPIID WebhookをAPIIDで複数取得します
+func (r *webhookRepository) GetListByAPIID(apiID string) ([]domain.Webhook, error) {
+	webhooks := []domain.Webhook{}
+	err := r.db.Where("api_id = ?", apiID).Find(&webhooks).Error
+
+	return webhooks, err
+}
+
+// Create Webhookを追加します
+func (r *webhookRepository) Create(webhook domain.Webhook) (string, error) {
+	err := r.db.Create(&webhook).Error
+	id := webhook.ID
+	return id, err
+}
+
+// Update Webhookを更新します
+func (r *webhookRepository) Update(webhook domain.Webhook) error {
+	targetWebhook := domain.Webhook{}
+
+	err := r.db.Where("id = ?", webhook.ID).First(&targetWebhook).Error
+	if err != nil {
+		return err
+	}
+
+	return r.db.Save(&webhook).Error
+}
+
+// Delete Webhookと、送信待ちのWebhookDeliveryを削除します
+func (r *webhookRepository) Delete(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		webhook := domain.Webhook{}
+		webhook.ID = id
+		result := tx.Delete(&webhook)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Where("webhook_id = ? AND status = ?", id, domain.DeliveryStatusPending).Delete(domain.WebhookDelivery{}).Error
+	})
+}
+
+// GetDeliveryByID WebhookDeliveryを1件取得します
+func (r *webhookRepository) GetDeliveryByID(id string) (domain.WebhookDelivery, error) {
+	delivery := domain.WebhookDelivery{}
+	err := r.db.Where("id = ?", id).First(&delivery).Error
+
+	return delivery, err
+}
+
+// GetDeliveriesByWebhookID WebhookDeliveryをWebhookIDで新しい順に複数取得します
+func (r *webhookRepository) GetDeliveriesByWebhookID(webhookID string) ([]domain.WebhookDelivery, error) {
+	deliveries := []domain.WebhookDelivery{}
+	err := r.db.Where("webhook_id = ?", webhookID).Order("created_at desc").Find(&deliveries).Error
+
+	return deliveries, err
+}
+
+// GetDeliveriesByStatus WebhookDeliveryをステータスで新しい順に複数取得します
+func (r *webhookRepository) GetDeliveriesByStatus(status string) ([]domain.WebhookDelivery, error) {
+	deliveries := []domain.WebhookDelivery{}
+	err := r.db.Where("status = ?", status).Order("created_at desc").Find(&deliveries).Error
+
+	return deliveries, err
+}
+
+// GetPendingDeliveries 送信時刻を過ぎた送信待ちのWebhookDeliveryを古い順に取得します
+func (r *webhookRepository) GetPendingDeliveries(now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	deliveries := []domain.WebhookDelivery{}
+	err := r.db.Where("status = ? AND next_attempt_at <= ?", domain.DeliveryStatusPending, now).
+		Order("next_attempt_at").Limit(limit).Find(&deliveries).Error
+
+	return deliveries, err
+}
+
+// ClaimDelivery 取得時から変更されていない送信待ちのWebhookDeliveryの送信時刻をuntilまで延ばし、送信する権利を得ます
+// 他のDispatcherが先に送信時刻を変更していた場合はfalseを返却します
+func (r *webhookRepository) ClaimDelivery(delivery domain.WebhookDelivery, until time.Time) (bool, error) {
+	result := r.db.Model(&domain.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND next_attempt_at = ?", delivery.ID, domain.DeliveryStatusPending, delivery.NextAttemptAt).
+		UpdateColumn("next_attempt_at", until)
+
+	return result.RowsAffected == 1, result.Error
+}
+
+// CreateDeliveries WebhookDeliveryをまとめて追加します
+func (r *webhookRepository) CreateDeliveries(deliveries []domain.WebhookDelivery) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range deliveries {
+			if err := tx.Create(&deliveries[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateDelivery 送信結果でWebhookDeliveryを更新し、試行結果を記録します
+func (r *webhookRepository) UpdateDelivery(delivery domain.WebhookDelivery, log domain.WebhookDeliveryLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&delivery).Error; err != nil {
+			return err
+		}
+		if log.ID == "" {
+			return nil
+		}
+		return tx.Create(&log).Error
+	})
+}
+
+// GetDeliveryLogs WebhookDeliveryの試行結果を古い順に取得します
+func (r *webhookRepository) GetDeliveryLogs(deliveryID string) ([]domain.WebhookDeliveryLog, error) {
+	logs := []domain.WebhookDeliveryLog{}
+	err := r.db.Where("delivery_id = ?", deliveryID).Order("attempt").Find(&logs).Error
+
+	return logs, err
+}
@@ -0,0 +1,153 @@
+package repository_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/webhook/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/google/uuid"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func setUpMockDB() (sqlmock.Sqlmock, *gorm.DB) {
+	gorm.DefaultTableNameHandler = func(db *gorm.DB, defaultTableName string) string {
+		return strings.Replace(defaultTableName, "_data_table", "", 1)
+	}
+	d, mock, _ := sqlmock.New()
+	conn, _ := gorm.Open("mysql", d)
+
+	return mock, conn
+}
+
+func TestGetListByAPIID(t *testing.T) {
+	mock, db := setUpMockDB()
+	webhookId, _ := uuid.NewRandom()
+	apiId, _ := uuid.NewRandom()
+
+	query := regexp.QuoteMeta("SELECT * FROM `webhooks` WHERE (api_id = ?)")
+	rows := sqlmock.NewRows([]string{"id", "api_id", "url", "events", "secret", "disabled", "created_at", "updated_at"}).
+		AddRow(webhookId.String(), apiId.String(), "https://example.com", "create", "secret", false, time.Now(), time.Now())
+	mock.ExpectQuery(query).WithArgs(apiId.String()).WillReturnRows(rows)
+
+	webhookRepository := repository.NewWebhookRepository(db)
+
+	webhooks, err := webhookRepository.GetListByAPIID(apiId.String())
+	assert.NoError(t, err)
+	assert.Len(t, webhooks, 1)
+}
+
+func TestCreate(t *testing.T) {
+	mock, db := setUpMockDB()
+	webhookId, _ := uuid.NewRandom()
+	apiId, _ := uuid.NewRandom()
+
+	mockWebhook := domain.Webhook{ID: webhookId.String(), APIID: apiId.String(), URL: "https://example.com", Secret: "secret"}
+
+	mock.ExpectBegin()
+	query := regexp.QuoteMeta("INSERT INTO `webhooks` (`id`,`api_id`,`url`,`events`,`secret`,`disabled`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?)")
+	mock.ExpectExec(query).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	webhookRepository := repository.NewWebhookRepository(db)
+
+	id, err := webhookRepository.Create(mockWebhook)
+	assert.NoError(t, err)
+	assert.Equal(t, webhookId.String(), id)
+}
+
+func TestDelete(t *testing.T) {
+	mock, db := setUpMockDB()
+	webhookId, _ := uuid.NewRandom()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `webhooks` WHERE `webhooks`.`id` = ?")).
+		WithArgs(webhookId.String()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `webhook_deliveries` WHERE (webhook_id = ? AND status = ?)")).
+		WithArgs(webhookId.String(), domain.DeliveryStatusPending).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	webhookRepository := repository.NewWebhookRepository(db)
+
+	err := webhookRepository.Delete(webhookId.String())
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPendingDeliveries(t *testing.T) {
+	mock, db := setUpMockDB()
+	deliveryId, _ := uuid.NewRandom()
+	webhookId, _ := uuid.NewRandom()
+	now := time.Now()
+
+	query := regexp.QuoteMeta("SELECT * FROM `webhook_deliveries` WHERE (status = ? AND next_attempt_at <= ?) ORDER BY next_attempt_at LIMIT 100")
+	rows := sqlmock.NewRows([]string{"id", "webhook_id", "event_id", "event_type", "payload", "status", "attempts", "next_attempt_at", "last_error", "created_at", "updated_at"}).
+		AddRow(deliveryId.String(), webhookId.String(), "event", "create", "{}", "pending", 0, now, "", now, now)
+	mock.ExpectQuery(query).WithArgs(domain.DeliveryStatusPending, now).WillReturnRows(rows)
+
+	webhookRepository := repository.NewWebhookRepository(db)
+
+	deliveries, err := webhookRepository.GetPendingDeliveries(now, 100)
+	assert.NoError(t, err)
+	assert.Len(t, deliveries, 1)
+}
+
+func TestClaimDelivery(t *testing.T) {
+	deliveryId, _ := uuid.NewRandom()
+	now := time.Now()
+	delivery := domain.WebhookDelivery{ID: deliveryId.String(), Status: domain.DeliveryStatusPending, NextAttemptAt: now}
+	query := regexp.QuoteMeta("UPDATE `webhook_deliveries` SET `next_attempt_at` = ? WHERE (id = ? AND status = ? AND next_attempt_at = ?)")
+
+	t.Run("送信時刻を延ばせた場合は権利を得る", func(t *testing.T) {
+		mock, db := setUpMockDB()
+		mock.ExpectBegin()
+		mock.ExpectExec(query).
+			WithArgs(now.Add(time.Minute), deliveryId.String(), domain.DeliveryStatusPending, now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		claimed, err := repository.NewWebhookRepository(db).ClaimDelivery(delivery, now.Add(time.Minute))
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("他のDispatcherが先に変更した場合は権利を得ない", func(t *testing.T) {
+		mock, db := setUpMockDB()
+		mock.ExpectBegin()
+		mock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		claimed, err := repository.NewWebhookRepository(db).ClaimDelivery(delivery, now.Add(time.Minute))
+		assert.NoError(t, err)
+		assert.False(t, claimed)
+	})
+}
+
+func TestUpdateDelivery(t *testing.T) {
+	mock, db := setUpMockDB()
+	deliveryId, _ := uuid.NewRandom()
+	logId, _ := uuid.NewRandom()
+
+	delivery := domain.WebhookDelivery{ID: deliveryId.String(), Status: domain.DeliveryStatusSucceeded, Attempts: 1}
+	deliveryLog := domain.WebhookDeliveryLog{ID: logId.String(), DeliveryID: deliveryId.String(), Attempt: 1, StatusCode: 200}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `webhook_deliveries` SET")).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `webhook_delivery_logs` (`id`,`delivery_id`,`attempt`,`status_code`,`error`,`duration`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?)")).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	webhookRepository := repository.NewWebhookRepository(db)
+
+	err := webhookRepository.UpdateDelivery(delivery, deliveryLog)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	_apiRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/api/repository"
+	_webhookRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/webhook/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+)
+
+// secretLength 自動生成する署名用の鍵のバイト数
+const secretLength = 32
+
+// WebhookUsecase Interface
+type WebhookUsecase interface {
+	GetByID(id string) (domain.Webhook, error)
+	GetListByAPIID(apiID string) ([]domain.Webhook, error)
+	Create(webhook domain.Webhook) (int, string, error)
+	Update(webhook domain.Webhook) (int, error)
+	Delete(id string) error
+	GetDeliveries(webhookID string) ([]domain.WebhookDelivery, error)
+	GetDeadLetters() ([]domain.WebhookDelivery, error)
+	GetDeliveryLogs(deliveryID string) ([]domain.WebhookDeliveryLog, error)
+	Retry(deliveryID string) (int, error)
+}
+
+type webhookUsecase struct {
+	webhookRepo _webhookRepository.WebhookRepository
+	apiRepo     _apiRepository.APIRepository
+}
+
+// NewWebhookUsecase WebhookUsecaseインターフェイスを表すオブジェクトを作成します
+func NewWebhookUsecase(webhookRepo _webhookRepository.WebhookRepository, apiRepo _apiRepository.APIRepository) WebhookUsecase {
+	return &webhookUsecase{
+		webhookRepo: webhookRepo,
+		apiRepo:     apiRepo,
+	}
+}
+
+// GetByID 1件のWebhookを取得します
+func (u *webhookUsecase) GetByID(id string) (domain.Webhook, error) {
+	return u.webhookRepo.GetByID(id)
+}
+
+// GetListByAPIID WebhookをAPIIDで複数取得します
+func (u *webhookUsecase) GetListByAPIID(apiID string) ([]domain.Webhook, error) {
+	return u.webhookRepo.GetListByAPIID(apiID)
+}
+
+// Create Webhookを作成します。署名用の鍵が未指定の場合は自動生成します
+func (u *webhookUsecase) Create(webhook domain.Webhook) (int, string, error) {
+	if webhook.ID == "" {
+		id, _ := uuid.NewRandom()
+		webhook.ID = id.String()
+	}
+	if webhook.Secret == "" {
+		secret, err := generateSecret()
+		if err != nil {
+			return http.StatusInternalServerError, "", err
+		}
+		webhook.Secret = secret
+	}
+
+	if status, err := u.validate(webhook); err != nil {
+		return status, "", err
+	}
+
+	id, err := u.webhookRepo.Create(webhook)
+	if err != nil {
+		return http.StatusInternalServerError, "", err
+	}
+	return http.StatusCreated, id, nil
+}
+
+// Update Webhookを更新します
+func (u *webhookUsecase) Update(webhook domain.Webhook) (int, error) {
+	if webhook.Secret == "" {
+		return http.StatusBadRequest, errors.New("secret is required")
+	}
+	if status, err := u.validate(webhook); err != nil {
+		return status, err
+	}
+
+	err := u.webhookRepo.Update(webhook)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return http.StatusNotFound, err
+		}
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+// Delete Webhookを削除します
+func (u *webhookUsecase) Delete(id string) error {
+	return u.webhookRepo.Delete(id)
+}
+
+// GetDeliveries Webhookへの送信履歴を取得します
+func (u *webhookUsecase) GetDeliveries(webhookID string) ([]domain.WebhookDelivery, error) {
+	if _, err := u.webhookRepo.GetByID(webhookID); err != nil {
+		return nil, err
+	}
+	return u.webhookRepo.GetDeliveriesByWebhookID(webhookID)
+}
+
+// GetDeadLetters 再送の上限に達したWebhookDeliveryを取得します
+func (u *webhookUsecase) GetDeadLetters() ([]domain.WebhookDelivery, error) {
+	return u.webhookRepo.GetDeliveriesByStatus(domain.DeliveryStatusDead)
+}
+
+// GetDeliveryLogs WebhookDeliveryの試行結果を取得します
+func (u *webhookUsecase) GetDeliveryLogs(deliveryID string) ([]domain.WebhookDeliveryLog, error) {
+	if _, err := u.webhookRepo.GetDeliveryByID(deliveryID); err != nil {
+		return nil, err
+	}
+	return u.webhookRepo.GetDeliveryLogs(deliveryID)
+}
+
+// Retry 再送の上限に達したWebhookDeliveryを、送信待ちに戻します
+func (u *webhookUsecase) Retry(deliveryID string) (int, error) {
+	delivery, err := u.webhookRepo.GetDeliveryByID(deliveryID)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return http.StatusNotFound, err
+		}
+		return http.StatusInternalServerError, err
+	}
+	if delivery.Status != domain.DeliveryStatusDead {
+		return http.StatusBadRequest, errors.New("delivery is not dead letter")
+	}
+
+	delivery.Status = domain.DeliveryStatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+
+	if err := u.webhookRepo.UpdateDelivery(delivery, domain.WebhookDeliveryLog{}); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+// validate Webhookの設定を検証します
+func (u *webhookUsecase) validate(webhook domain.Webhook) (int, error) {
+	if _, err := u.apiRepo.GetByID(webhook.APIID); err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return http.StatusBadRequest, errors.New("api is not found")
+		}
+		return http.StatusInternalServerError, err
+	}
+
+	target, err := url.Parse(webhook.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return http.StatusBadRequest, errors.New("url is invalid")
+	}
+
+	if strings.TrimSpace(webhook.Events) == "" {
+		return http.StatusOK, nil
+	}
+	for _, event := range strings.Split(webhook.Events, ",") {
+		switch strings.TrimSpace(event) {
+		case domain.EventCreate, domain.EventUpdate, domain.EventDelete:
+		default:
+			return http.StatusBadRequest, errors.New("events is create, update or delete")
+		}
+	}
+	return http.StatusOK, nil
+}
+
+// generateSecret 署名用の鍵を生成します
+func generateSecret() (string, error) {
+	b := make([]byte, secretLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
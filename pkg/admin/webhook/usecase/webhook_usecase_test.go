@@ -0,0 +1,158 @@
+package usecase_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/admin/webhook/usecase"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newMockWebhook() domain.Webhook {
+	webhookId, _ := uuid.NewRandom()
+	apiId, _ := uuid.NewRandom()
+
+	mockWebhook := domain.Webhook{}
+	mockWebhook.ID = webhookId.String()
+	mockWebhook.APIID = apiId.String()
+	mockWebhook.URL = "https://example.com/hooks"
+	mockWebhook.Events = "create,delete"
+	mockWebhook.Secret = "secret"
+	return mockWebhook
+}
+
+func TestCreate(t *testing.T) {
+	mockWebhook := newMockWebhook()
+
+	mockWebhookRepo := new(mocks.WebhookRepository)
+	mockAPIRepo := new(mocks.APIRepository)
+
+	t.Run("test1", func(t *testing.T) {
+		mockAPIRepo.On("GetByID", mockWebhook.APIID).Return(domain.API{ID: mockWebhook.APIID}, nil).Once()
+		mockWebhookRepo.On("Create", mockWebhook).Return(nil).Once()
+		usecase := usecase.NewWebhookUsecase(mockWebhookRepo, mockAPIRepo)
+
+		status, id, err := usecase.Create(mockWebhook)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, status)
+		assert.Equal(t, mockWebhook.ID, id)
+		mockWebhookRepo.AssertExpectations(t)
+	})
+	t.Run("署名用の鍵を自動生成", func(t *testing.T) {
+		noSecretWebhook := mockWebhook
+		noSecretWebhook.Secret = ""
+
+		mockAPIRepo.On("GetByID", mockWebhook.APIID).Return(domain.API{ID: mockWebhook.APIID}, nil).Once()
+		mockWebhookRepo.On("Create", mock.MatchedBy(func(w domain.Webhook) bool {
+			return len(w.Secret) == 64
+		})).Return(nil).Once()
+		usecase := usecase.NewWebhookUsecase(mockWebhookRepo, mockAPIRepo)
+
+		_, _, err := usecase.Create(noSecretWebhook)
+
+		assert.NoError(t, err)
+		mockWebhookRepo.AssertExpectations(t)
+	})
+	t.Run("URLが不正", func(t *testing.T) {
+		invalidWebhook := mockWebhook
+		invalidWebhook.URL = "ftp://example.com"
+
+		mockAPIRepo.On("GetByID", mockWebhook.APIID).Return(domain.API{ID: mockWebhook.APIID}, nil).Once()
+		usecase := usecase.NewWebhookUsecase(mockWebhookRepo, mockAPIRepo)
+
+		status, _, err := usecase.Create(invalidWebhook)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+	t.Run("存在しないイベント", func(t *testing.T) {
+		invalidWebhook := mockWebhook
+		invalidWebhook.Events = "create,read"
+
+		mockAPIRepo.On("GetByID", mockWebhook.APIID).Return(domain.API{ID: mockWebhook.APIID}, nil).Once()
+		usecase := usecase.NewWebhookUsecase(mockWebhookRepo, mockAPIRepo)
+
+		status, _, err := usecase.Create(invalidWebhook)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+	t.Run("APIが存在しない", func(t *testing.T) {
+		mockAPIRepo.On("GetByID", mockWebhook.APIID).Return(domain.API{}, gorm.ErrRecordNotFound).Once()
+		usecase := usecase.NewWebhookUsecase(mockWebhookRepo, mockAPIRepo)
+
+		status, _, err := usecase.Create(mockWebhook)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+}
+
+func TestUpdate(t *testing.T) {
+	mockWebhook := newMockWebhook()
+
+	mockWebhookRepo := new(mocks.WebhookRepository)
+	mockAPIRepo := new(mocks.APIRepository)
+
+	t.Run("test1", func(t *testing.T) {
+		mockAPIRepo.On("GetByID", mockWebhook.APIID).Return(domain.API{ID: mockWebhook.APIID}, nil).Once()
+		mockWebhookRepo.On("Update", mockWebhook).Return(nil).Once()
+		usecase := usecase.NewWebhookUsecase(mockWebhookRepo, mockAPIRepo)
+
+		status, err := usecase.Update(mockWebhook)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+	})
+	t.Run("Webhookが存在しない", func(t *testing.T) {
+		mockAPIRepo.On("GetByID", mockWebhook.APIID).Return(domain.API{ID: mockWebhook.APIID}, nil).Once()
+		mockWebhookRepo.On("Update", mockWebhook).Return(gorm.ErrRecordNotFound).Once()
+		usecase := usecase.NewWebhookUsecase(mockWebhookRepo, mockAPIRepo)
+
+		status, err := usecase.Update(mockWebhook)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+}
+
+func TestRetry(t *testing.T) {
+	deliveryId, _ := uuid.NewRandom()
+
+	mockWebhookRepo := new(mocks.WebhookRepository)
+	mockAPIRepo := new(mocks.APIRepository)
+
+	t.Run("test1", func(t *testing.T) {
+		deadDelivery := domain.WebhookDelivery{ID: deliveryId.String(), Status: domain.DeliveryStatusDead, Attempts: 8}
+
+		mockWebhookRepo.On("GetDeliveryByID", deliveryId.String()).Return(deadDelivery, nil).Once()
+		mockWebhookRepo.On("UpdateDelivery", mock.MatchedBy(func(d domain.WebhookDelivery) bool {
+			return d.Status == domain.DeliveryStatusPending && d.Attempts == 0
+		}), domain.WebhookDeliveryLog{}).Return(nil).Once()
+		usecase := usecase.NewWebhookUsecase(mockWebhookRepo, mockAPIRepo)
+
+		status, err := usecase.Retry(deliveryId.String())
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		mockWebhookRepo.AssertExpectations(t)
+	})
+	t.Run("dead letterではない", func(t *testing.T) {
+		succeededDelivery := domain.WebhookDelivery{ID: deliveryId.String(), Status: domain.DeliveryStatusSucceeded}
+
+		mockWebhookRepo.On("GetDeliveryByID", deliveryId.String()).Return(succeededDelivery, nil).Once()
+		usecase := usecase.NewWebhookUsecase(mockWebhookRepo, mockAPIRepo)
+
+		status, err := usecase.Retry(deliveryId.String())
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+}
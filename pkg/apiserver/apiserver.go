@@ -0,0 +1,112 @@
+// Package apiserver 作成したAPIを提供するハンドラを登録します
+package apiserver
+
+import (
+	"context"
+	"time"
+
+	_apiRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/api/repository"
+	_auditRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/audit/repository"
+	_methodRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/method/repository"
+	_modelRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/model/repository"
+	_webhookRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/webhook/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/audit"
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/cors"
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/event"
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/handler"
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/ratelimit"
+	_apiserverRepository "github.com/Hajime3778/api-creator-backend/pkg/apiserver/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/script"
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/usecase"
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/webhook"
+	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/config"
+	"github.com/gin-gonic/gin"
+)
+
+// Repositories APIサーバーで使用するリポジトリ
+type Repositories struct {
+	API       _apiRepository.APIRepository
+	Method    _methodRepository.MethodRepository
+	Model     _modelRepository.ModelRepository
+	Webhook   _webhookRepository.WebhookRepository
+	APIServer _apiserverRepository.APIServerRepository
+	// Audit 書き込みの監査ログ(nilの場合は記録しません)
+	Audit _auditRepository.AuditRepository
+	// RateLimit リクエスト数の制限を保持するStore(nilの場合はメモリ上で保持します)
+	RateLimit ratelimit.Store
+}
+
+// NewHandlers APIサーバーのハンドラをrouterに登録し、Webhookの送信と監査ログの削除をバックグラウンドで開始します
+// バックグラウンドの処理はctxがキャンセルされるまで続けます
+func NewHandlers(ctx context.Context, router *gin.Engine, repos Repositories, cfg *config.Config) {
+	scriptRunner := script.NewRunner(cfg.Script.Timeout, cfg.Script.MaxSize, cfg.Script.MaxMemory)
+
+	// Webhookの送信待ちをoutboxに保存し、バックグラウンドで送信する
+	webhookOutbox := webhook.NewOutbox(repos.Webhook)
+	webhookDispatcher := webhook.NewDispatcher(repos.Webhook, webhook.Options{
+		MaxAttempts:   cfg.Webhook.MaxAttempts,
+		RetryInterval: time.Duration(cfg.Webhook.RetryInterval) * time.Second,
+		Timeout:       time.Duration(cfg.Webhook.Timeout) * time.Second,
+	})
+	go webhookDispatcher.Run(ctx)
+
+	// Change Streamが利用できない場合に、"<api-url>/_events"へ配信するプロセス内のイベント
+	eventBus := event.NewBus(0)
+	publisher := event.NewPublishers(webhookOutbox, eventBus)
+
+	// 書き込みの監査ログを記録し、保持期間を過ぎたものをバックグラウンドで削除する
+	var auditRecorder audit.Recorder
+	if repos.Audit != nil {
+		auditRecorder = audit.NewRecorder(repos.Audit, audit.Options{
+			Retention:    time.Duration(cfg.Audit.RetentionDays) * 24 * time.Hour,
+			RedactFields: cfg.Audit.RedactFields,
+		})
+		go auditRecorder.Run(ctx)
+	}
+
+	apiserverUsecase := usecase.NewAPIServerUsecase(repos.API, repos.Method, repos.Model, repos.APIServer, scriptRunner, publisher, eventBus, auditRecorder, responseTimeout(cfg.Server.Timeout))
+	graphqlUsecase := usecase.NewGraphQLUsecase(repos.API, repos.Method, repos.Model, repos.APIServer, apiserverUsecase)
+	rpcUsecase := usecase.NewRPCUsecase(repos.API, repos.Method, repos.Model, apiserverUsecase)
+
+	// プリフライトリクエストはリクエスト数の制限より前に応答し、制限したレスポンスにもCORSの設定を適用する
+	router.Use(cors.NewPolicy(apiserverUsecase).Handle)
+
+	rateLimitStore := repos.RateLimit
+	if rateLimitStore == nil {
+		rateLimitStore = ratelimit.NewMemoryStore()
+	}
+	router.Use(ratelimit.NewLimiter(rateLimitStore, apiserverUsecase, rateLimitOptions(cfg)).Handle)
+	handler.NewAPIServerHandler(router, apiserverUsecase, graphqlUsecase, rpcUsecase, responseTimeout(cfg.Server.Timeout))
+}
+
+// rateLimitOptions 設定ファイルのリクエスト数の制限を、Limiterの設定にします
+func rateLimitOptions(cfg *config.Config) ratelimit.Options {
+	keys := make([]ratelimit.Key, len(cfg.RateLimit.APIKeys))
+	for i, key := range cfg.RateLimit.APIKeys {
+		keys[i] = ratelimit.Key{
+			Name:         key.Name,
+			Key:          key.Key,
+			Limit:        ratelimit.Limit{PerMinute: key.Rate, Burst: key.Burst},
+			MonthlyQuota: key.MonthlyQuota,
+		}
+	}
+	return ratelimit.Options{
+		Default:      ratelimit.Limit{PerMinute: cfg.RateLimit.Rate, Burst: cfg.RateLimit.Burst},
+		APIKeyHeader: cfg.RateLimit.APIKeyHeader,
+		Keys:         keys,
+		JWTSecret:    cfg.RateLimit.JWTSecret,
+		TrustProxy:   cfg.RateLimit.TrustProxy,
+	}
+}
+
+// responseTimeout サーバーの書き込みタイムアウトより前に、レスポンス(イベントの配信、mockの待ち時間)を終える時間を返却します
+func responseTimeout(serverTimeout int) time.Duration {
+	if serverTimeout <= 0 {
+		return 0
+	}
+	timeout := time.Duration(serverTimeout)*time.Second - time.Second
+	if timeout < time.Second {
+		return time.Second
+	}
+	return timeout
+}
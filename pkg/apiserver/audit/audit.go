@@ -0,0 +1,199 @@
+// Package audit APIサーバーで処理した書き込み(POST、PUT、DELETE)を監査ログに記録します
+// ModelのJSON Schemaで"x-sensitive": trueを指定した項目と、設定で指定した項目の値は伏せて記録します
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	_auditRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/audit/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/google/uuid"
+)
+
+const (
+	// Redacted 伏せた項目の値
+	Redacted = "[REDACTED]"
+	// SensitiveKeyword 機密の項目を表すJSON Schemaのキーワード
+	SensitiveKeyword = "x-sensitive"
+
+	defaultPurgeInterval = time.Hour
+	// maxLogErrorLength 監査ログに記録するエラーの文字数の上限
+	maxLogErrorLength = 255
+)
+
+// Entry 記録する書き込み
+type Entry struct {
+	API    domain.API
+	Method domain.Method
+	// Schema 対象のModelのJSON Schema(機密の項目の判定に使用します)
+	Schema     string
+	HTTPMethod string
+	URL        string
+	Key        interface{}
+	Caller     string
+	Before     interface{}
+	After      interface{}
+	Status     int
+	Err        error
+	Latency    time.Duration
+}
+
+// Options Recorderの設定
+type Options struct {
+	// Retention 監査ログを保持する期間(0の場合は削除しません)
+	Retention time.Duration
+	// RedactFields Schemaの指定に関わらず、値を伏せる項目名
+	RedactFields []string
+	// PurgeInterval 保持期間を過ぎた監査ログを削除する間隔
+	PurgeInterval time.Duration
+}
+
+// Recorder Interface
+type Recorder interface {
+	Record(e Entry)
+	Run(ctx context.Context)
+	Purge() (int64, error)
+}
+
+type recorder struct {
+	auditRepo _auditRepository.AuditRepository
+	options   Options
+	now       func() time.Time
+}
+
+// NewRecorder 監査ログをauditRepoに記録するRecorderを作成します
+func NewRecorder(auditRepo _auditRepository.AuditRepository, options Options) Recorder {
+	if options.PurgeInterval <= 0 {
+		options.PurgeInterval = defaultPurgeInterval
+	}
+	return &recorder{
+		auditRepo: auditRepo,
+		options:   options,
+		now:       time.Now,
+	}
+}
+
+// Record 書き込みを監査ログに記録します。記録に失敗してもリクエストは失敗させません
+func (r *recorder) Record(e Entry) {
+	id, _ := uuid.NewRandom()
+	l := domain.AuditLog{
+		ID:         id.String(),
+		APIID:      e.API.ID,
+		MethodID:   e.Method.ID,
+		HTTPMethod: e.HTTPMethod,
+		URL:        e.URL,
+		Caller:     e.Caller,
+		Before:     r.marshal(e.Schema, e.Before),
+		After:      r.marshal(e.Schema, e.After),
+		Status:     e.Status,
+		Latency:    int(e.Latency / time.Millisecond),
+	}
+	if e.Key != nil {
+		l.Key = fmt.Sprint(e.Key)
+	}
+	if e.Err != nil {
+		l.Error = truncate(e.Err.Error())
+	}
+
+	if err := r.auditRepo.Create(l); err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// Run 保持期間を過ぎた監査ログを、ctxがキャンセルされるまで定期的に削除します
+func (r *recorder) Run(ctx context.Context) {
+	if r.options.Retention <= 0 {
+		return
+	}
+	ticker := time.NewTicker(r.options.PurgeInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := r.Purge(); err != nil {
+			log.Println(err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge 保持期間を過ぎた監査ログを削除し、削除した件数を返却します
+func (r *recorder) Purge() (int64, error) {
+	if r.options.Retention <= 0 {
+		return 0, nil
+	}
+	return r.auditRepo.DeleteBefore(r.now().Add(-r.options.Retention))
+}
+
+// marshal ドキュメントの機密の項目を伏せて、JSONにします(ドキュメントがない場合は空)
+func (r *recorder) marshal(schema string, document interface{}) string {
+	if document == nil {
+		return ""
+	}
+	b, err := json.Marshal(Redact(schema, document, r.options.RedactFields))
+	if err != nil {
+		log.Println(err.Error())
+		return ""
+	}
+	return string(b)
+}
+
+// Redact ドキュメントを複製し、schemaで"x-sensitive": trueを指定した項目とfieldsの項目の値を伏せます
+// ドキュメントはJSONで表現できる型(map[string]interface{}、[]interface{}等)とします
+func Redact(schema string, document interface{}, fields []string) interface{} {
+	var root map[string]interface{}
+	if schema != "" {
+		json.Unmarshal([]byte(schema), &root)
+	}
+	names := map[string]bool{}
+	for _, field := range fields {
+		names[field] = true
+	}
+	return redact(document, root, names)
+}
+
+func redact(value interface{}, schema map[string]interface{}, names map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+		redacted := make(map[string]interface{}, len(v))
+		for name, item := range v {
+			property, _ := properties[name].(map[string]interface{})
+			if names[name] || sensitive(property) {
+				redacted[name] = Redacted
+				continue
+			}
+			redacted[name] = redact(item, property, names)
+		}
+		return redacted
+
+	case []interface{}:
+		items, _ := schema["items"].(map[string]interface{})
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = redact(item, items, names)
+		}
+		return redacted
+	}
+	return value
+}
+
+// sensitive 項目のSchemaで"x-sensitive": trueが指定されている場合はtrueを返却します
+func sensitive(property map[string]interface{}) bool {
+	s, _ := property[SensitiveKeyword].(bool)
+	return s
+}
+
+// truncate エラーメッセージを記録できる長さに切り詰めます
+func truncate(message string) string {
+	if len(message) <= maxLogErrorLength {
+		return message
+	}
+	return message[:maxLogErrorLength]
+}
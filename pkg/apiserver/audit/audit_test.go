@@ -0,0 +1,97 @@
+package audit_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/audit"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const schema = `{
+	"type": "object",
+	"properties": {
+		"name": {"type": "string"},
+		"password": {"type": "string", "x-sensitive": true},
+		"cards": {"type": "array", "items": {"type": "object", "properties": {"number": {"type": "string", "x-sensitive": true}}}}
+	}
+}`
+
+func TestRedact(t *testing.T) {
+	document := map[string]interface{}{
+		"name":     "foo",
+		"password": "secret",
+		"token":    "abc",
+		"cards":    []interface{}{map[string]interface{}{"number": "4111", "brand": "visa"}},
+	}
+
+	redacted := audit.Redact(schema, document, []string{"token"})
+
+	assert.Equal(t, map[string]interface{}{
+		"name":     "foo",
+		"password": audit.Redacted,
+		"token":    audit.Redacted,
+		"cards":    []interface{}{map[string]interface{}{"number": audit.Redacted, "brand": "visa"}},
+	}, redacted)
+	// 元のドキュメントは変更しない
+	assert.Equal(t, "secret", document["password"])
+}
+
+func TestRecord(t *testing.T) {
+	mockAuditRepo := new(mocks.AuditRepository)
+	mockAuditRepo.On("Create", mock.MatchedBy(func(l domain.AuditLog) bool {
+		return l.APIID == "a1" && l.MethodID == "m1" && l.Key == "1" && l.Caller == "key:k1" &&
+			l.Before == `{"name":"foo","password":"[REDACTED]"}` && l.After == "" &&
+			l.Status == http.StatusBadRequest && len(l.Error) == 255 && l.Latency == 12
+	})).Return(nil).Once()
+	recorder := audit.NewRecorder(mockAuditRepo, audit.Options{})
+
+	recorder.Record(audit.Entry{
+		API:        domain.API{ID: "a1"},
+		Method:     domain.Method{ID: "m1"},
+		Schema:     schema,
+		HTTPMethod: "DELETE",
+		Key:        1,
+		Caller:     "key:k1",
+		Before:     map[string]interface{}{"name": "foo", "password": "secret"},
+		Status:     http.StatusBadRequest,
+		Err:        errors.New(strings.Repeat("e", 300)),
+		Latency:    12 * time.Millisecond,
+	})
+
+	mockAuditRepo.AssertExpectations(t)
+}
+
+func TestPurge(t *testing.T) {
+	mockAuditRepo := new(mocks.AuditRepository)
+
+	t.Run("保持期間を過ぎた監査ログを削除する", func(t *testing.T) {
+		mockAuditRepo.On("DeleteBefore", mock.MatchedBy(func(before time.Time) bool {
+			expected := time.Now().Add(-24 * time.Hour)
+			return before.Sub(expected) < time.Minute && expected.Sub(before) < time.Minute
+		})).Return(int64(3), nil).Once()
+		recorder := audit.NewRecorder(mockAuditRepo, audit.Options{Retention: 24 * time.Hour})
+
+		deleted, err := recorder.Purge()
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), deleted)
+		mockAuditRepo.AssertExpectations(t)
+	})
+	t.Run("保持期間の指定がない場合は削除しない", func(t *testing.T) {
+		recorder := audit.NewRecorder(mockAuditRepo, audit.Options{})
+
+		deleted, err := recorder.Purge()
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), deleted)
+		mockAuditRepo.AssertNumberOfCalls(t, "DeleteBefore", 1)
+	})
+}
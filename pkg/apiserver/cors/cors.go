@@ -0,0 +1,120 @@
+// Package cors リクエストされたAPIのCORSの設定(domain.API)に従って、クロスオリジンのリクエストを許可します
+package cors
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/resolve"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// exposeHeaders ブラウザのスクリプトから参照できるレスポンスヘッダー(リクエスト数の制限)
+var exposeHeaders = []string{
+	"Retry-After",
+	"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset",
+	"X-Quota-Limit", "X-Quota-Remaining", "X-Quota-Reset",
+}
+
+// Resolver リクエストされたAPIを取得します
+type Resolver interface {
+	ResolveMethod(httpMethod string, url string) (domain.API, domain.Method, error)
+}
+
+// Policy APIごとのCORSの設定を適用するミドルウェア
+type Policy struct {
+	resolver Resolver
+}
+
+// NewPolicy Policyを作成します
+func NewPolicy(resolver Resolver) *Policy {
+	return &Policy{resolver: resolver}
+}
+
+// Handle Originヘッダーのあるリクエストに、APIのCORSの設定を適用します
+// プリフライトリクエストには、許可する場合は204、許可しない場合は403を返却し、以降のハンドラを実行しません
+// 許可しないオリジンからの通常のリクエストは、Access-Control-*ヘッダーを設定せずに処理します(ブラウザが結果を破棄します)
+func (p *Policy) Handle(c *gin.Context) {
+	origin := c.GetHeader("Origin")
+	if origin == "" {
+		c.Next()
+		return
+	}
+	c.Writer.Header().Add("Vary", "Origin")
+
+	preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+	api, _, err := resolve.Method(c, p.resolver)
+	allowed := err == nil && AllowOrigin(api, origin)
+
+	if !preflight {
+		if allowed {
+			setOriginHeaders(c, api, origin)
+			c.Header("Access-Control-Expose-Headers", strings.Join(exposeHeaders, ", "))
+		}
+		c.Next()
+		return
+	}
+
+	c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+	c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+	if !allowed ||
+		!contains(api.CORSMethods(), c.GetHeader("Access-Control-Request-Method"), false) ||
+		!allowHeaders(api, c.GetHeader("Access-Control-Request-Headers")) {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	setOriginHeaders(c, api, origin)
+	c.Header("Access-Control-Allow-Methods", strings.Join(api.CORSMethods(), ", "))
+	c.Header("Access-Control-Allow-Headers", strings.Join(api.CORSHeaders(), ", "))
+	if api.CORSMaxAge > 0 {
+		c.Header("Access-Control-Max-Age", strconv.Itoa(api.CORSMaxAge))
+	}
+	c.AbortWithStatus(http.StatusNoContent)
+}
+
+// AllowOrigin APIがoriginからのリクエストを許可する場合はtrueを返却します
+func AllowOrigin(api domain.API, origin string) bool {
+	for _, allowed := range api.CORSOrigins() {
+		if allowed == domain.CORSAllowAll || allowed == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// allowHeaders プリフライトリクエストで指定されたヘッダーを、すべて許可する場合はtrueを返却します
+func allowHeaders(api domain.API, requested string) bool {
+	for _, header := range strings.Split(requested, ",") {
+		if header = strings.TrimSpace(header); header != "" && !contains(api.CORSHeaders(), header, true) {
+			return false
+		}
+	}
+	return true
+}
+
+// setOriginHeaders 許可するオリジンと、資格情報の許可を設定します
+// 資格情報を許可する場合は、"*"ではなくリクエストのオリジンを返却します
+func setOriginHeaders(c *gin.Context, api domain.API, origin string) {
+	if api.CORSAllowCredentials {
+		c.Header("Access-Control-Allow-Origin", origin)
+		c.Header("Access-Control-Allow-Credentials", "true")
+		return
+	}
+	if contains(api.CORSOrigins(), domain.CORSAllowAll, false) {
+		c.Header("Access-Control-Allow-Origin", domain.CORSAllowAll)
+		return
+	}
+	c.Header("Access-Control-Allow-Origin", origin)
+}
+
+func contains(list []string, value string, ignoreCase bool) bool {
+	for _, item := range list {
+		if item == value || (ignoreCase && strings.EqualFold(item, value)) {
+			return true
+		}
+	}
+	return false
+}
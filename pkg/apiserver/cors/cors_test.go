@@ -0,0 +1,128 @@
+package cors_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/cors"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setUpRouter Policyを登録し、許可されたリクエストに200を返却するルーターを作成します
+func setUpRouter(api domain.API) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	resolver := new(mocks.APIServerUsecase)
+	resolver.On("ResolveMethod", mock.Anything, "other").Return(domain.API{}, domain.Method{}, errors.New("api not found"))
+	resolver.On("ResolveMethod", mock.Anything, mock.Anything).Return(api, domain.Method{}, nil)
+
+	router := gin.New()
+	router.Use(cors.NewPolicy(resolver).Handle)
+	router.Any("/*path", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+func request(router *gin.Engine, method string, path string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+	return res
+}
+
+func preflight(origin string, method string, headers string) map[string]string {
+	return map[string]string{
+		"Origin":                         origin,
+		"Access-Control-Request-Method":  method,
+		"Access-Control-Request-Headers": headers,
+	}
+}
+
+func TestHandle(t *testing.T) {
+	api := domain.API{
+		ID:                 "api-1",
+		URL:                "users",
+		CORSAllowedOrigins: "https://example.com, https://app.example.com",
+		CORSAllowedMethods: "GET,POST",
+		CORSAllowedHeaders: "Content-Type,Authorization",
+		CORSMaxAge:         600,
+	}
+
+	t.Run("許可するオリジン", func(t *testing.T) {
+		res := request(setUpRouter(api), "GET", "/users", map[string]string{"Origin": "https://app.example.com"})
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, "https://app.example.com", res.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "Origin", res.Header().Get("Vary"))
+		assert.Contains(t, res.Header().Get("Access-Control-Expose-Headers"), "Retry-After")
+		assert.Empty(t, res.Header().Get("Access-Control-Allow-Credentials"))
+	})
+	t.Run("許可しないオリジン", func(t *testing.T) {
+		res := request(setUpRouter(api), "GET", "/users", map[string]string{"Origin": "https://evil.example.com"})
+
+		// レスポンスは返却するが、ブラウザには許可しない
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Empty(t, res.Header().Get("Access-Control-Allow-Origin"))
+	})
+	t.Run("存在しないAPI", func(t *testing.T) {
+		res := request(setUpRouter(api), "OPTIONS", "/other", preflight("https://example.com", "GET", ""))
+
+		assert.Equal(t, http.StatusForbidden, res.Code)
+		assert.Empty(t, res.Header().Get("Access-Control-Allow-Origin"))
+	})
+	t.Run("プリフライトリクエスト", func(t *testing.T) {
+		res := request(setUpRouter(api), "OPTIONS", "/users/1", preflight("https://example.com", "POST", "content-type, authorization"))
+
+		assert.Equal(t, http.StatusNoContent, res.Code)
+		assert.Equal(t, "https://example.com", res.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "GET, POST", res.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "Content-Type, Authorization", res.Header().Get("Access-Control-Allow-Headers"))
+		assert.Equal(t, "600", res.Header().Get("Access-Control-Max-Age"))
+	})
+	t.Run("許可しないHTTPメソッドのプリフライトリクエスト", func(t *testing.T) {
+		res := request(setUpRouter(api), "OPTIONS", "/users", preflight("https://example.com", "DELETE", ""))
+
+		assert.Equal(t, http.StatusForbidden, res.Code)
+	})
+	t.Run("許可しないヘッダーのプリフライトリクエスト", func(t *testing.T) {
+		res := request(setUpRouter(api), "OPTIONS", "/users", preflight("https://example.com", "GET", "X-Custom"))
+
+		assert.Equal(t, http.StatusForbidden, res.Code)
+	})
+	t.Run("オリジンの指定がないAPIは許可しない", func(t *testing.T) {
+		res := request(setUpRouter(domain.API{ID: "api-1", URL: "users"}), "OPTIONS", "/users", preflight("https://example.com", "GET", ""))
+
+		assert.Equal(t, http.StatusForbidden, res.Code)
+	})
+	t.Run("すべてのオリジンを許可する", func(t *testing.T) {
+		router := setUpRouter(domain.API{ID: "api-1", URL: "users", CORSAllowedOrigins: "*"})
+
+		res := request(router, "OPTIONS", "/users", preflight("https://any.example.com", "DELETE", "Content-Type"))
+		assert.Equal(t, http.StatusNoContent, res.Code)
+		assert.Equal(t, "*", res.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "GET, POST, PUT, DELETE", res.Header().Get("Access-Control-Allow-Methods"))
+		assert.Empty(t, res.Header().Get("Access-Control-Max-Age"))
+	})
+	t.Run("資格情報を許可する場合はオリジンを返却する", func(t *testing.T) {
+		router := setUpRouter(domain.API{ID: "api-1", URL: "users", CORSAllowedOrigins: "https://example.com", CORSAllowCredentials: true})
+
+		res := request(router, "GET", "/users", map[string]string{"Origin": "https://example.com"})
+		assert.Equal(t, "https://example.com", res.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "true", res.Header().Get("Access-Control-Allow-Credentials"))
+	})
+	t.Run("Originのないリクエスト", func(t *testing.T) {
+		res := request(setUpRouter(api), "OPTIONS", "/users", nil)
+
+		// プリフライトリクエストではないため、APIサーバーのハンドラで処理する
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Empty(t, res.Header().Get("Vary"))
+	})
+}
@@ -0,0 +1,140 @@
+package event
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+)
+
+const (
+	// defaultHistorySize 配信の再開のために保持するイベントの件数(未指定の場合)
+	defaultHistorySize = 1000
+	// subscriptionBufferSize 購読者ごとに配信待ちにできるイベントの件数
+	subscriptionBufferSize = 64
+)
+
+// ErrResumeTokenNotFound "resume token not found"
+var ErrResumeTokenNotFound = errors.New("resume token not found")
+
+// Subscriber ドキュメントの変更イベントを購読します
+type Subscriber interface {
+	// Subscribe APIのイベントを購読します。lastEventIDを指定した場合は、そのイベントより後のイベントから配信します
+	// 返却された関数を呼び出すと購読を終了します
+	Subscribe(apiID string, lastEventID string) (<-chan domain.Event, func(), error)
+}
+
+// Bus プロセス内でイベントを通知、購読します
+type Bus interface {
+	Publisher
+	Subscriber
+}
+
+type subscription struct {
+	apiID  string
+	events chan domain.Event
+}
+
+type bus struct {
+	mu            sync.Mutex
+	subscriptions map[*subscription]struct{}
+	history       []domain.Event
+	historySize   int
+}
+
+// NewBus Busインターフェイスを表すオブジェクトを作成します
+// historySizeは配信の再開のために保持するイベントの件数で、0の場合は既定値を使用します
+func NewBus(historySize int) Bus {
+	if historySize <= 0 {
+		historySize = defaultHistorySize
+	}
+	return &bus{
+		subscriptions: map[*subscription]struct{}{},
+		historySize:   historySize,
+	}
+}
+
+// Publish イベントを購読者に配信します
+// 配信待ちがいっぱいの購読者(受信が遅いクライアント)には配信せず、購読を終了します
+func (b *bus) Publish(e domain.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.history = append(b.history, e)
+	if len(b.history) > b.historySize {
+		b.history = b.history[len(b.history)-b.historySize:]
+	}
+
+	for s := range b.subscriptions {
+		if s.apiID != e.APIID {
+			continue
+		}
+		select {
+		case s.events <- e:
+		default:
+			delete(b.subscriptions, s)
+			close(s.events)
+		}
+	}
+}
+
+// Subscribe APIのイベントを購読します
+func (b *bus) Subscribe(apiID string, lastEventID string) (<-chan domain.Event, func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	missed := []domain.Event{}
+	if lastEventID != "" {
+		index := -1
+		for i, e := range b.history {
+			if e.ID == lastEventID {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return nil, nil, ErrResumeTokenNotFound
+		}
+		for _, e := range b.history[index+1:] {
+			if e.APIID == apiID {
+				missed = append(missed, e)
+			}
+		}
+	}
+
+	s := &subscription{
+		apiID:  apiID,
+		events: make(chan domain.Event, subscriptionBufferSize+len(missed)),
+	}
+	for _, e := range missed {
+		s.events <- e
+	}
+	b.subscriptions[s] = struct{}{}
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if _, ok := b.subscriptions[s]; ok {
+				delete(b.subscriptions, s)
+				close(s.events)
+			}
+		})
+	}
+	return s.events, cancel, nil
+}
+
+type publishers []Publisher
+
+// NewPublishers 複数のPublisherに順に通知するPublisherを作成します
+func NewPublishers(p ...Publisher) Publisher {
+	return publishers(p)
+}
+
+// Publish すべてのPublisherにイベントを通知します
+func (p publishers) Publish(e domain.Event) {
+	for _, publisher := range p {
+		publisher.Publish(e)
+	}
+}
@@ -0,0 +1,87 @@
+package event_test
+
+import (
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/event"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribe(t *testing.T) {
+	t.Run("購読しているAPIのイベントのみ配信", func(t *testing.T) {
+		bus := event.NewBus(0)
+		events, cancel, err := bus.Subscribe("api-1", "")
+		assert.NoError(t, err)
+		defer cancel()
+
+		bus.Publish(domain.Event{ID: "1", APIID: "api-2"})
+		bus.Publish(domain.Event{ID: "2", APIID: "api-1"})
+
+		e := <-events
+		assert.Equal(t, "2", e.ID)
+		assert.Len(t, events, 0)
+	})
+	t.Run("lastEventIDより後のイベントから再開", func(t *testing.T) {
+		bus := event.NewBus(0)
+		bus.Publish(domain.Event{ID: "1", APIID: "api-1"})
+		bus.Publish(domain.Event{ID: "2", APIID: "api-1"})
+		bus.Publish(domain.Event{ID: "3", APIID: "api-2"})
+		bus.Publish(domain.Event{ID: "4", APIID: "api-1"})
+
+		events, cancel, err := bus.Subscribe("api-1", "1")
+		assert.NoError(t, err)
+		defer cancel()
+
+		assert.Equal(t, "2", (<-events).ID)
+		assert.Equal(t, "4", (<-events).ID)
+		assert.Len(t, events, 0)
+	})
+	t.Run("保持していないlastEventID", func(t *testing.T) {
+		bus := event.NewBus(2)
+		bus.Publish(domain.Event{ID: "1", APIID: "api-1"})
+		bus.Publish(domain.Event{ID: "2", APIID: "api-1"})
+		bus.Publish(domain.Event{ID: "3", APIID: "api-1"})
+
+		_, _, err := bus.Subscribe("api-1", "1")
+		assert.Equal(t, event.ErrResumeTokenNotFound, err)
+	})
+	t.Run("購読の終了", func(t *testing.T) {
+		bus := event.NewBus(0)
+		events, cancel, _ := bus.Subscribe("api-1", "")
+
+		cancel()
+		cancel()
+		bus.Publish(domain.Event{ID: "1", APIID: "api-1"})
+
+		_, ok := <-events
+		assert.False(t, ok)
+	})
+	t.Run("受信が遅い購読者は購読を終了", func(t *testing.T) {
+		bus := event.NewBus(0)
+		events, cancel, _ := bus.Subscribe("api-1", "")
+		defer cancel()
+
+		for i := 0; i < 100; i++ {
+			bus.Publish(domain.Event{APIID: "api-1"})
+		}
+
+		count := 0
+		for range events {
+			count++
+		}
+		assert.True(t, count < 100)
+	})
+}
+
+func TestEventFilter(t *testing.T) {
+	e := domain.Event{Type: domain.EventCreate, Document: map[string]interface{}{"status": "draft", "count": float64(3)}}
+
+	assert.True(t, (&domain.EventFilter{}).Match(e))
+	assert.True(t, (&domain.EventFilter{Types: []string{"create", "update"}}).Match(e))
+	assert.False(t, (&domain.EventFilter{Types: []string{"delete"}}).Match(e))
+	assert.True(t, (&domain.EventFilter{Fields: map[string]string{"status": "draft", "count": "3"}}).Match(e))
+	assert.False(t, (&domain.EventFilter{Fields: map[string]string{"status": "published"}}).Match(e))
+	assert.False(t, (&domain.EventFilter{Fields: map[string]string{"owner": "1"}}).Match(e))
+}
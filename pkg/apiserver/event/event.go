@@ -0,0 +1,8 @@
+package event
+
+import "github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+// Publisher ドキュメントの変更イベントを通知します
+type Publisher interface {
+	Publish(event domain.Event)
+}
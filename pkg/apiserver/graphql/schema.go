@@ -0,0 +1,533 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	_apiserverRepository "github.com/Hajime3778/api-creator-backend/pkg/apiserver/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// maxDepth 入れ子のオブジェクトを型にする深さの上限
+const maxDepth = 8
+
+var (
+	// invalidNameChars GraphQLの名前に使用できない文字
+	invalidNameChars = regexp.MustCompile(`[^_0-9A-Za-z]`)
+
+	// ErrNoModels "no models are defined"
+	ErrNoModels = errors.New("no models are defined")
+	// ErrMethodNotDefined "method is not defined"
+	ErrMethodNotDefined = errors.New("method is not defined")
+)
+
+// JSON 型を特定できない値を、そのまま返却するスカラー型
+var JSON = gql.NewScalar(gql.ScalarConfig{
+	Name:        "JSON",
+	Description: "型を特定できないJSONの値",
+	Serialize: func(value interface{}) interface{} {
+		return value
+	},
+	ParseValue: func(value interface{}) interface{} {
+		return value
+	},
+	ParseLiteral: parseLiteral,
+})
+
+// Writer Mutationでドキュメントを書き込みます
+type Writer interface {
+	// Write Modelを使用するAPIの、httpMethodのMethodを実行します(keyはDELETEでURLのパラメータに使用します)
+	// Methodが定義されていない場合は、ErrMethodNotDefinedを返却します
+	// REST APIと同じく、スクリプト、mock、Webhook、変更イベント、監査ログ等も動作します
+	Write(ctx context.Context, model domain.Model, httpMethod string, key interface{}, body []byte) (interface{}, int, error)
+}
+
+// NewSchema ModelのJSON Schemaから、GraphQLのスキーマを作成します
+// Modelごとに、オブジェクト型とKeyでの取得、一覧の取得、作成、更新、削除を定義します
+// x-refで他のModelを参照している項目には、参照先のドキュメントを取得する"<項目名>Ref"を追加します
+// 取得はコレクションを直接参照し、作成、更新、削除はwriterで実行します
+func NewSchema(models []domain.Model, apiserverRepo _apiserverRepository.APIServerRepository, writer Writer) (gql.Schema, error) {
+	b := &builder{
+		apiserverRepo: apiserverRepo,
+		writer:        writer,
+		refs:          map[string]*modelType{},
+		typeNames:     map[string]bool{"JSON": true, "Query": true, "Mutation": true},
+	}
+
+	// 名前の重複を避けるため、順序を固定して作成する
+	sorted := append([]domain.Model{}, models...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	for _, model := range sorted {
+		if err := b.addModel(model); err != nil {
+			return gql.Schema{}, fmt.Errorf("model %s: %s", model.Name, err)
+		}
+	}
+	if len(b.order) == 0 {
+		return gql.Schema{}, ErrNoModels
+	}
+
+	query := gql.Fields{}
+	mutation := gql.Fields{}
+	for _, m := range b.order {
+		b.addRefFields(m)
+		b.addOperations(m, query, mutation)
+	}
+
+	return gql.NewSchema(gql.SchemaConfig{
+		Query:    gql.NewObject(gql.ObjectConfig{Name: "Query", Fields: query}),
+		Mutation: gql.NewObject(gql.ObjectConfig{Name: "Mutation", Fields: mutation}),
+	})
+}
+
+// modelType Modelから作成したGraphQLの型
+type modelType struct {
+	model      domain.Model
+	name       string
+	schema     map[string]interface{}
+	object     *gql.Object
+	input      *gql.InputObject
+	keyName    string
+	keyType    gql.Input
+	collection string
+}
+
+type builder struct {
+	apiserverRepo _apiserverRepository.APIServerRepository
+	writer        Writer
+	// refs x-refで参照される、ModelのIDと名前から型
+	refs      map[string]*modelType
+	order     []*modelType
+	typeNames map[string]bool
+}
+
+// addModel Modelのオブジェクト型と入力型を作成します
+func (b *builder) addModel(model domain.Model) error {
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(model.Schema), &schema); err != nil {
+		return err
+	}
+	keys, err := model.GetKeyNames()
+	if err != nil {
+		return err
+	}
+	properties, _ := schema["properties"].(map[string]interface{})
+	keyProperty, _ := properties[keys[0]].(map[string]interface{})
+
+	name := b.uniqueTypeName(toTypeName(model.Name))
+	m := &modelType{
+		model:      model,
+		name:       name,
+		schema:     schema,
+		keyName:    keys[0],
+		keyType:    gql.NewNonNull(scalarType(keyProperty)),
+		collection: model.GetCollectionName(),
+	}
+	m.object = gql.NewObject(gql.ObjectConfig{
+		Name:        name,
+		Description: model.Description,
+		Fields:      b.outputFields(name, schema, 0),
+	})
+	m.input = gql.NewInputObject(gql.InputObjectConfig{
+		Name:   b.uniqueTypeName(name + "Input"),
+		Fields: b.inputFields(name+"Input", schema, 0),
+	})
+
+	b.refs[model.ID] = m
+	b.refs[model.Name] = m
+	b.order = append(b.order, m)
+	return nil
+}
+
+// addRefFields x-refで他のModelを参照している項目に、参照先のドキュメントを取得する項目を追加します
+func (b *builder) addRefFields(m *modelType) {
+	properties, _ := m.schema["properties"].(map[string]interface{})
+	for _, propertyName := range sortedKeys(properties) {
+		property, _ := properties[propertyName].(map[string]interface{})
+		ref, _ := property["x-ref"].(string)
+		target, ok := b.refs[ref]
+		if ref == "" || !ok {
+			continue
+		}
+		fieldName := toFieldName(propertyName) + "Ref"
+		if _, exists := m.object.Fields()[fieldName]; exists {
+			continue
+		}
+		source := propertyName
+		m.object.AddFieldConfig(fieldName, &gql.Field{
+			Type:        target.object,
+			Description: fmt.Sprintf("%sで参照している%s", propertyName, target.name),
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				document, _ := p.Source.(map[string]interface{})
+				if document[source] == nil {
+					return nil, nil
+				}
+				return b.get(target, document[source], true)
+			},
+		})
+	}
+}
+
+// addOperations Modelの取得、一覧の取得、作成、更新、削除を追加します
+func (b *builder) addOperations(m *modelType, query gql.Fields, mutation gql.Fields) {
+	fieldName := toQueryName(m.name)
+	keyArg := toFieldName(m.keyName)
+	keyArgs := gql.FieldConfigArgument{
+		keyArg: &gql.ArgumentConfig{Type: m.keyType},
+	}
+	inputArgs := gql.FieldConfigArgument{
+		"input": &gql.ArgumentConfig{Type: gql.NewNonNull(m.input)},
+	}
+
+	query[fieldName] = &gql.Field{
+		Type:        m.object,
+		Description: fmt.Sprintf("%sを%sで1件取得します", m.name, m.keyName),
+		Args:        keyArgs,
+		Resolve: func(p gql.ResolveParams) (interface{}, error) {
+			return b.get(m, p.Args[keyArg], true)
+		},
+	}
+	query[fieldName+"List"] = &gql.Field{
+		Type:        gql.NewNonNull(gql.NewList(gql.NewNonNull(m.object))),
+		Description: fmt.Sprintf("すべての%sを取得します", m.name),
+		Resolve: func(p gql.ResolveParams) (interface{}, error) {
+			return b.list(m)
+		},
+	}
+	mutation["create"+m.name] = &gql.Field{
+		Type:        m.object,
+		Description: fmt.Sprintf("%sを作成します", m.name),
+		Args:        inputArgs,
+		Resolve: func(p gql.ResolveParams) (interface{}, error) {
+			return b.write(p.Context, m, http.MethodPost, p.Args["input"])
+		},
+	}
+	mutation["update"+m.name] = &gql.Field{
+		Type:        m.object,
+		Description: fmt.Sprintf("%sを更新します", m.name),
+		Args:        inputArgs,
+		Resolve: func(p gql.ResolveParams) (interface{}, error) {
+			return b.write(p.Context, m, http.MethodPut, p.Args["input"])
+		},
+	}
+	mutation["delete"+m.name] = &gql.Field{
+		Type:        gql.Boolean,
+		Description: fmt.Sprintf("%sを%sで削除します", m.name, m.keyName),
+		Args:        keyArgs,
+		Resolve: func(p gql.ResolveParams) (interface{}, error) {
+			_, status, err := b.writer.Write(p.Context, m.model, http.MethodDelete, p.Args[keyArg], nil)
+			// 削除するドキュメントが存在しない場合は、エラーとせずfalseを返却する
+			if status == http.StatusNotFound && err != ErrMethodNotDefined {
+				return false, nil
+			}
+			if err := writeError(status, err); err != nil {
+				return nil, err
+			}
+			return true, nil
+		},
+	}
+}
+
+// get Keyからドキュメントを1件取得します。allowNotFoundの場合、存在しなければnullを返却します
+func (b *builder) get(m *modelType, key interface{}, allowNotFound bool) (interface{}, error) {
+	document, status, err := b.apiserverRepo.Get(m.collection, m.keyName, key)
+	if status == http.StatusNotFound && allowNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toPlain(document)
+}
+
+// list ドキュメントをすべて取得します
+func (b *builder) list(m *modelType) (interface{}, error) {
+	documents, status, err := b.apiserverRepo.GetList(m.collection, "", "")
+	if status == http.StatusNotFound {
+		return []interface{}{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toPlain(documents)
+}
+
+// write 入力値でドキュメントを作成、更新し、書き込んだドキュメントを返却します
+// スキーマの検証はREST APIと同じく、Methodの実行時(前処理のスクリプトの後)に行います
+func (b *builder) write(ctx context.Context, m *modelType, httpMethod string, input interface{}) (interface{}, error) {
+	document, ok := input.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("input is not object")
+	}
+	body, err := json.Marshal(removeNull(document))
+	if err != nil {
+		return nil, err
+	}
+
+	response, status, err := b.writer.Write(ctx, m.model, httpMethod, nil, body)
+	if err := writeError(status, err); err != nil {
+		return nil, err
+	}
+
+	// レスポンスがドキュメントの場合(作成、mock、後処理のスクリプト等)はそのまま返却する
+	written, err := toPlain(response)
+	if err != nil {
+		return nil, err
+	}
+	if w, ok := written.(map[string]interface{}); ok && w[m.keyName] != nil {
+		return w, nil
+	}
+	if document[m.keyName] == nil {
+		return nil, nil
+	}
+	return b.get(m, document[m.keyName], false)
+}
+
+// writeError Methodの実行に失敗した場合のエラーを返却します
+func writeError(status int, err error) error {
+	if err != nil {
+		return err
+	}
+	if status >= http.StatusBadRequest {
+		return fmt.Errorf("request failed with status %d", status)
+	}
+	return nil
+}
+
+// outputFields JSON Schemaのpropertiesから、オブジェクト型の項目を作成します
+func (b *builder) outputFields(typeName string, schema map[string]interface{}, depth int) gql.Fields {
+	fields := gql.Fields{}
+	properties, _ := schema["properties"].(map[string]interface{})
+	for _, propertyName := range sortedKeys(properties) {
+		property, _ := properties[propertyName].(map[string]interface{})
+		fieldName := toFieldName(propertyName)
+		if _, exists := fields[fieldName]; exists {
+			continue
+		}
+		source := propertyName
+		fields[fieldName] = &gql.Field{
+			Type:        b.outputType(typeName+toTypeName(propertyName), property, depth),
+			Description: description(property),
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				document, _ := p.Source.(map[string]interface{})
+				return document[source], nil
+			},
+		}
+	}
+	if len(fields) == 0 {
+		// 項目のないオブジェクト型は定義できないため、値全体を返却する項目を設ける
+		fields["_value"] = &gql.Field{
+			Type: JSON,
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return p.Source, nil
+			},
+		}
+	}
+	return fields
+}
+
+// outputType JSON Schemaの型から、GraphQLの出力型を作成します
+func (b *builder) outputType(typeName string, property map[string]interface{}, depth int) gql.Output {
+	switch property["type"] {
+	case "object":
+		if _, ok := property["properties"].(map[string]interface{}); !ok || depth >= maxDepth {
+			return JSON
+		}
+		return gql.NewObject(gql.ObjectConfig{
+			Name:   b.uniqueTypeName(typeName),
+			Fields: b.outputFields(typeName, property, depth+1),
+		})
+	case "array":
+		items, ok := property["items"].(map[string]interface{})
+		if !ok {
+			return gql.NewList(JSON)
+		}
+		return gql.NewList(b.outputType(typeName+"Item", items, depth+1))
+	default:
+		return scalarType(property)
+	}
+}
+
+// inputFields JSON Schemaのpropertiesから、入力型の項目を作成します
+func (b *builder) inputFields(typeName string, schema map[string]interface{}, depth int) gql.InputObjectConfigFieldMap {
+	fields := gql.InputObjectConfigFieldMap{}
+	properties, _ := schema["properties"].(map[string]interface{})
+	required := map[string]bool{}
+	if list, ok := schema["required"].([]interface{}); ok {
+		for _, name := range list {
+			if s, ok := name.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+	for _, propertyName := range sortedKeys(properties) {
+		// 入力値はJSONの項目名のまま受け取るため、GraphQLで使用できない名前の項目は入力できない
+		if toFieldName(propertyName) != propertyName {
+			continue
+		}
+		property, _ := properties[propertyName].(map[string]interface{})
+		var fieldType gql.Input = b.inputType(typeName+toTypeName(propertyName), property, depth)
+		if required[propertyName] {
+			fieldType = gql.NewNonNull(fieldType)
+		}
+		fields[propertyName] = &gql.InputObjectFieldConfig{
+			Type:        fieldType,
+			Description: description(property),
+		}
+	}
+	if len(fields) == 0 {
+		fields["_value"] = &gql.InputObjectFieldConfig{Type: JSON}
+	}
+	return fields
+}
+
+// inputType JSON Schemaの型から、GraphQLの入力型を作成します
+func (b *builder) inputType(typeName string, property map[string]interface{}, depth int) gql.Input {
+	switch property["type"] {
+	case "object":
+		if _, ok := property["properties"].(map[string]interface{}); !ok || depth >= maxDepth {
+			return JSON
+		}
+		return gql.NewInputObject(gql.InputObjectConfig{
+			Name:   b.uniqueTypeName(typeName),
+			Fields: b.inputFields(typeName, property, depth+1),
+		})
+	case "array":
+		items, ok := property["items"].(map[string]interface{})
+		if !ok {
+			return gql.NewList(JSON)
+		}
+		return gql.NewList(b.inputType(typeName+"Item", items, depth+1))
+	default:
+		return scalarType(property)
+	}
+}
+
+// uniqueTypeName 重複しない型名を返却します
+func (b *builder) uniqueTypeName(name string) string {
+	unique := name
+	for i := 2; b.typeNames[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	b.typeNames[unique] = true
+	return unique
+}
+
+// scalarType JSON Schemaの型から、GraphQLのスカラー型を返却します
+func scalarType(property map[string]interface{}) *gql.Scalar {
+	switch property["type"] {
+	case "string":
+		return gql.String
+	case "integer":
+		return gql.Int
+	case "number":
+		return gql.Float
+	case "boolean":
+		return gql.Boolean
+	default:
+		return JSON
+	}
+}
+
+// toTypeName 名前をGraphQLの型名(先頭が大文字)に変換します
+func toTypeName(name string) string {
+	name = toFieldName(name)
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// toQueryName 型名をクエリの項目名(先頭が小文字)に変換します
+func toQueryName(typeName string) string {
+	runes := []rune(typeName)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+// toFieldName 名前をGraphQLの項目名に変換します
+func toFieldName(name string) string {
+	name = invalidNameChars.ReplaceAllString(name, "_")
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "_" + name
+	}
+	if strings.HasPrefix(name, "__") {
+		// "__"で始まる名前はGraphQLで予約されている
+		name = "x" + name
+	}
+	return name
+}
+
+// description JSON Schemaのdescriptionを返却します
+func description(property map[string]interface{}) string {
+	d, _ := property["description"].(string)
+	return d
+}
+
+// sortedKeys mapのキーを順に並べて返却します
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// removeNull 値がnullの項目を削除します(任意の項目が未入力の場合)
+func removeNull(document map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for key, value := range document {
+		if value != nil {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// toPlain ドキュメントをJSONで表現できる型(map[string]interface{}等)に変換します
+func toPlain(document interface{}) (interface{}, error) {
+	b, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	var plain interface{}
+	err = json.Unmarshal(b, &plain)
+	return plain, err
+}
+
+// parseLiteral クエリに直接記述されたJSONの値を変換します
+func parseLiteral(value ast.Value) interface{} {
+	switch v := value.(type) {
+	case *ast.StringValue:
+		return v.Value
+	case *ast.BooleanValue:
+		return v.Value
+	case *ast.IntValue, *ast.FloatValue:
+		var number float64
+		fmt.Sscan(v.GetValue().(string), &number)
+		return number
+	case *ast.ListValue:
+		list := make([]interface{}, 0, len(v.Values))
+		for _, item := range v.Values {
+			list = append(list, parseLiteral(item))
+		}
+		return list
+	case *ast.ObjectValue:
+		object := map[string]interface{}{}
+		for _, field := range v.Fields {
+			object[field.Name.Value] = parseLiteral(field.Value)
+		}
+		return object
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,222 @@
+package graphql_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/graphql"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var testModels = []domain.Model{
+	{
+		ID:             "user-model",
+		Name:           "User",
+		CollectionName: "users",
+		Schema: `{
+			"type": "object",
+			"keys": ["id"],
+			"properties": {
+				"id": {"type": "string"},
+				"name": {"type": "string"},
+				"age": {"type": "integer"},
+				"profile": {"type": "object", "properties": {"bio": {"type": "string"}}},
+				"tags": {"type": "array", "items": {"type": "string"}}
+			},
+			"required": ["id", "name"]
+		}`,
+	},
+	{
+		ID:             "post-model",
+		Name:           "Post",
+		CollectionName: "posts",
+		Schema: `{
+			"type": "object",
+			"keys": ["id"],
+			"properties": {
+				"id": {"type": "integer"},
+				"title": {"type": "string"},
+				"postedUserId": {"type": "string", "x-ref": "User"}
+			},
+			"required": ["id", "title"]
+		}`,
+	},
+}
+
+func execute(t *testing.T, repo *mocks.APIServerRepository, writer *mocks.GraphQLWriter, query string) map[string]interface{} {
+	schema, err := graphql.NewSchema(testModels, repo, writer)
+	assert.NoError(t, err)
+
+	result := gql.Do(gql.Params{Schema: schema, RequestString: query})
+	b, _ := json.Marshal(result)
+	var response map[string]interface{}
+	json.Unmarshal(b, &response)
+	return response
+}
+
+func TestQuery(t *testing.T) {
+	user := map[string]interface{}{
+		"id": "1", "name": "foo", "age": 20,
+		"profile": map[string]interface{}{"bio": "hello"},
+		"tags":    []interface{}{"a", "b"},
+	}
+
+	t.Run("Keyで取得", func(t *testing.T) {
+		repo := new(mocks.APIServerRepository)
+		repo.On("Get", "users", "id", "1").Return(user, http.StatusOK, nil).Once()
+
+		response := execute(t, repo, new(mocks.GraphQLWriter), `{ user(id: "1") { id name age profile { bio } tags } }`)
+
+		assert.Nil(t, response["errors"])
+		assert.Equal(t, map[string]interface{}{
+			"user": map[string]interface{}{
+				"id": "1", "name": "foo", "age": float64(20),
+				"profile": map[string]interface{}{"bio": "hello"},
+				"tags":    []interface{}{"a", "b"},
+			},
+		}, response["data"])
+	})
+	t.Run("存在しない場合はnull", func(t *testing.T) {
+		repo := new(mocks.APIServerRepository)
+		repo.On("Get", "users", "id", "2").Return("", http.StatusNotFound, nil).Once()
+
+		response := execute(t, repo, new(mocks.GraphQLWriter), `{ user(id: "2") { id } }`)
+
+		assert.Nil(t, response["errors"])
+		assert.Equal(t, map[string]interface{}{"user": nil}, response["data"])
+	})
+	t.Run("一覧と参照先のドキュメントを取得", func(t *testing.T) {
+		repo := new(mocks.APIServerRepository)
+		repo.On("GetList", "posts", "", "").Return([]interface{}{
+			map[string]interface{}{"id": 1, "title": "first", "postedUserId": "1"},
+		}, http.StatusOK, nil).Once()
+		repo.On("Get", "users", "id", "1").Return(user, http.StatusOK, nil).Once()
+
+		response := execute(t, repo, new(mocks.GraphQLWriter), `{ postList { id title postedUserIdRef { name } } }`)
+
+		assert.Nil(t, response["errors"])
+		assert.Equal(t, map[string]interface{}{
+			"postList": []interface{}{
+				map[string]interface{}{"id": float64(1), "title": "first", "postedUserIdRef": map[string]interface{}{"name": "foo"}},
+			},
+		}, response["data"])
+	})
+	t.Run("一覧が空", func(t *testing.T) {
+		repo := new(mocks.APIServerRepository)
+		repo.On("GetList", "users", "", "").Return("", http.StatusNotFound, nil).Once()
+
+		response := execute(t, repo, new(mocks.GraphQLWriter), `{ userList { id } }`)
+
+		assert.Equal(t, map[string]interface{}{"userList": []interface{}{}}, response["data"])
+	})
+}
+
+func TestMutation(t *testing.T) {
+	t.Run("作成", func(t *testing.T) {
+		repo := new(mocks.APIServerRepository)
+		writer := new(mocks.GraphQLWriter)
+		writer.On("Write", "post-model", "POST", nil, mock.MatchedBy(func(body []byte) bool {
+			return string(body) == `{"id":1,"title":"first"}`
+		})).Return(map[string]interface{}{"id": 1, "title": "first"}, http.StatusCreated, nil).Once()
+
+		response := execute(t, repo, writer, `mutation { createPost(input: {id: 1, title: "first"}) { id title } }`)
+
+		assert.Nil(t, response["errors"])
+		assert.Equal(t, map[string]interface{}{
+			"createPost": map[string]interface{}{"id": float64(1), "title": "first"},
+		}, response["data"])
+		writer.AssertExpectations(t)
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("必須項目がない", func(t *testing.T) {
+		repo := new(mocks.APIServerRepository)
+		writer := new(mocks.GraphQLWriter)
+
+		response := execute(t, repo, writer, `mutation { createPost(input: {id: 1}) { id } }`)
+
+		assert.NotNil(t, response["errors"])
+		assert.Equal(t, 0, len(writer.Calls))
+	})
+	t.Run("更新したドキュメントを取得する", func(t *testing.T) {
+		repo := new(mocks.APIServerRepository)
+		writer := new(mocks.GraphQLWriter)
+		writer.On("Write", "user-model", "PUT", nil, mock.Anything).Return("", http.StatusOK, nil).Once()
+		repo.On("Get", "users", "id", "1").Return(map[string]interface{}{"id": "1", "name": "bar"}, http.StatusOK, nil).Once()
+
+		response := execute(t, repo, writer, `mutation { updateUser(input: {id: "1", name: "bar"}) { id name } }`)
+
+		assert.Nil(t, response["errors"])
+		assert.Equal(t, map[string]interface{}{
+			"updateUser": map[string]interface{}{"id": "1", "name": "bar"},
+		}, response["data"])
+		repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("存在しないドキュメントの更新", func(t *testing.T) {
+		repo := new(mocks.APIServerRepository)
+		writer := new(mocks.GraphQLWriter)
+		writer.On("Write", "user-model", "PUT", nil, mock.Anything).Return("", http.StatusBadRequest, errors.New("record is not found")).Once()
+
+		response := execute(t, repo, writer, `mutation { updateUser(input: {id: "9", name: "bar"}) { id } }`)
+
+		assert.NotNil(t, response["errors"])
+		assert.Equal(t, 0, len(repo.Calls))
+	})
+	t.Run("スクリプトでエラーのステータスを返却した", func(t *testing.T) {
+		repo := new(mocks.APIServerRepository)
+		writer := new(mocks.GraphQLWriter)
+		writer.On("Write", "user-model", "PUT", nil, mock.Anything).Return("", http.StatusForbidden, nil).Once()
+
+		response := execute(t, repo, writer, `mutation { updateUser(input: {id: "1", name: "bar"}) { id } }`)
+
+		assert.NotNil(t, response["errors"])
+	})
+	t.Run("削除", func(t *testing.T) {
+		repo := new(mocks.APIServerRepository)
+		writer := new(mocks.GraphQLWriter)
+		writer.On("Write", "user-model", "DELETE", "1", []byte(nil)).Return("", http.StatusNoContent, nil).Once()
+
+		response := execute(t, repo, writer, `mutation { deleteUser(id: "1") }`)
+
+		assert.Equal(t, map[string]interface{}{"deleteUser": true}, response["data"])
+		repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("存在しないドキュメントの削除", func(t *testing.T) {
+		writer := new(mocks.GraphQLWriter)
+		writer.On("Write", "user-model", "DELETE", "9", []byte(nil)).Return("", http.StatusNotFound, errors.New("record not found")).Once()
+
+		response := execute(t, new(mocks.APIServerRepository), writer, `mutation { deleteUser(id: "9") }`)
+
+		assert.Nil(t, response["errors"])
+		assert.Equal(t, map[string]interface{}{"deleteUser": false}, response["data"])
+	})
+	t.Run("Methodが定義されていない", func(t *testing.T) {
+		writer := new(mocks.GraphQLWriter)
+		writer.On("Write", "user-model", "DELETE", "1", []byte(nil)).Return("", http.StatusNotFound, graphql.ErrMethodNotDefined).Once()
+
+		response := execute(t, new(mocks.APIServerRepository), writer, `mutation { deleteUser(id: "1") }`)
+
+		assert.NotNil(t, response["errors"])
+	})
+}
+
+func TestNewSchema(t *testing.T) {
+	repo := new(mocks.APIServerRepository)
+
+	_, err := graphql.NewSchema([]domain.Model{}, repo, new(mocks.GraphQLWriter))
+	assert.Equal(t, graphql.ErrNoModels, err)
+
+	// 名前が重複、GraphQLで使用できない文字を含むModel
+	models := []domain.Model{
+		{ID: "1", Name: "user", Schema: `{"type": "object", "keys": ["user-id"], "properties": {"user-id": {"type": "string"}}}`},
+		{ID: "2", Name: "User", Schema: `{"type": "object", "keys": ["id"], "properties": {"id": {"type": "string"}}}`},
+	}
+	_, err = graphql.NewSchema(models, repo, new(mocks.GraphQLWriter))
+	assert.NoError(t, err)
+}
@@ -1,6 +1,10 @@
 package handler
 
 import (
+	"net/http"
+	"strings"
+	"time"
+
 	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/usecase"
 
 	"github.com/gin-gonic/gin"
@@ -8,13 +12,20 @@ import (
 
 // APIServerHandler APIServerAPIに対するリクエストハンドラ
 type APIServerHandler struct {
-	usecase usecase.APIServerUsecase
+	usecase        usecase.APIServerUsecase
+	graphqlUsecase usecase.GraphQLUsecase
+	rpcUsecase     usecase.RPCUsecase
+	streamTimeout  time.Duration
 }
 
 // NewAPIServerHandler APIServerHandlerを作成します
-func NewAPIServerHandler(router *gin.Engine, u usecase.APIServerUsecase) {
+// streamTimeoutはイベントの配信(SSE)を1回の接続で続ける時間で、0の場合は制限しません
+func NewAPIServerHandler(router *gin.Engine, u usecase.APIServerUsecase, gu usecase.GraphQLUsecase, ru usecase.RPCUsecase, streamTimeout time.Duration) {
 	handler := &APIServerHandler{
-		usecase: u,
+		usecase:        u,
+		graphqlUsecase: gu,
+		rpcUsecase:     ru,
+		streamTimeout:  streamTimeout,
 	}
 	router.Any("/*proxyPath", handler.RequestDocumentServer)
 }
@@ -25,9 +36,32 @@ func (h *APIServerHandler) RequestDocumentServer(c *gin.Context) {
 	httpMethod := c.Request.Method
 	// 最初の文字は/なので削除する
 	url := c.Param("proxyPath")[1:]
+
+	if url == graphqlPath && (httpMethod == http.MethodGet || httpMethod == http.MethodPost) {
+		h.GraphQL(c)
+		return
+	}
+	if isRPC(httpMethod, url) {
+		h.RPC(c, "/"+url)
+		return
+	}
+	if httpMethod == http.MethodGet && strings.HasSuffix(url, "/"+usecase.EventsPath) {
+		h.StreamEvents(c, url)
+		return
+	}
+
 	body, _ := c.GetRawData()
 
-	response, httpStatus, err := h.usecase.RequestDocumentServer(httpMethod, url, body)
+	response, httpStatus, err := h.usecase.RequestDocumentServer(c.Request.Context(), httpMethod, url, body)
+
+	if httpStatus == http.StatusPermanentRedirect {
+		location := "/" + response.(string)
+		if c.Request.URL.RawQuery != "" {
+			location = location + "?" + c.Request.URL.RawQuery
+		}
+		c.Redirect(httpStatus, location)
+		return
+	}
 
 	if err != nil {
 		c.JSON(httpStatus, gin.H{
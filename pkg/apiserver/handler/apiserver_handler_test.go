@@ -0,0 +1,363 @@
+package handler_test
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+	"time"
+
+	_apiRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/api/repository"
+	_auditRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/audit/repository"
+	_methodRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/method/repository"
+	_modelRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/model/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/audit"
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/event"
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/handler"
+	_apiserverRepository "github.com/Hajime3778/api-creator-backend/pkg/apiserver/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/script"
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/usecase"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/memory"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+const fuzzAPIURL = "my-project/api/users"
+
+// fuzzFragments パスに含める断片(SQLの構文、LIKEのワイルドカード、MongoDBの演算子など)
+var fuzzFragments = []string{
+	"'", "\"", "`", "--", "/*", "*/", ";", "%", "_", "%25", "%27", "\\", "\x00",
+	" OR 1=1", "' OR '1'='1", "UNION SELECT", "$where", "$ne", "{id}", "{", "}",
+	"..", "/", "//", "1", "users", "my-project", "api", "日本語", " ",
+}
+
+// setUpFuzzRouter メモリ上のStoreに1件のAPIとドキュメントを登録し、APIサーバーのルーターを作成します
+// (ドキュメントのKeyはid、項目はnameのみで、Schemaにない項目も許可します)
+func setUpFuzzRouter(t *testing.T) (*gin.Engine, *memory.Store) {
+	return setUpFuzzRouterWithPublisher(t, nil)
+}
+
+// setUpFuzzRouterWithPublisher setUpFuzzRouterと同じルーターを、変更イベントの通知先を指定して作成します
+func setUpFuzzRouterWithPublisher(t *testing.T, publisher event.Publisher) (*gin.Engine, *memory.Store) {
+	store, err := memory.NewStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Update(func(tables *memory.Tables) error {
+		tables.APIs = append(tables.APIs, domain.API{ID: "api-1", URL: fuzzAPIURL})
+		tables.Models = append(tables.Models, domain.Model{
+			ID:             "model-1",
+			APIID:          "api-1",
+			Schema:         `{"type": "object", "keys": ["id"], "properties": {"id": {"type": "string"}, "name": {"type": "string"}, "password": {"type": "string", "x-sensitive": true}}}`,
+			CollectionName: "users",
+		})
+		tables.Methods = append(tables.Methods,
+			domain.Method{ID: "method-1", APIID: "api-1", Type: "GET", URL: "", ResponseModelID: "model-1", IsArray: true},
+			domain.Method{ID: "method-2", APIID: "api-1", Type: "GET", URL: "/{id}", RequestParameter: "id", ResponseModelID: "model-1"},
+			domain.Method{ID: "method-3", APIID: "api-1", Type: "POST", URL: "", RequestModelID: "model-1"},
+			domain.Method{ID: "method-4", APIID: "api-1", Type: "PUT", URL: "", RequestModelID: "model-1"},
+			domain.Method{ID: "method-5", APIID: "api-1", Type: "DELETE", URL: "/{id}", RequestParameter: "id", ResponseModelID: "model-1"},
+		)
+		return nil
+	})
+
+	apiserverUsecase := usecase.NewAPIServerUsecase(
+		_apiRepository.NewMemoryAPIRepository(store),
+		_methodRepository.NewMemoryMethodRepository(store),
+		_modelRepository.NewMemoryModelRepository(store),
+		_apiserverRepository.NewMemoryRepository(store),
+		script.NewRunner(0, 0, 0),
+		publisher,
+		nil,
+		audit.NewRecorder(_auditRepository.NewMemoryAuditRepository(store), audit.Options{RedactFields: []string{"token"}}),
+		100*time.Millisecond,
+	)
+	router := gin.New()
+	handler.NewAPIServerHandler(router, apiserverUsecase, nil, nil, 0)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/"+fuzzAPIURL, bytes.NewBufferString(`{"id": "1", "name": "foo"}`))
+	router.ServeHTTP(res, req)
+	if res.Code >= http.StatusBadRequest {
+		t.Fatalf("failed to create document: %d %s", res.Code, res.Body.String())
+	}
+	return router, store
+}
+
+// randomPath APIのURLで始まる(または途中に含む)パスを、断片を連結して作成します
+func randomPath(r *rand.Rand) string {
+	prefixes := []string{"", fuzzAPIURL, fuzzAPIURL + "/", "other/" + fuzzAPIURL, strings.Replace(fuzzAPIURL, "-", "_", 1)}
+	path := prefixes[r.Intn(len(prefixes))]
+	for i := r.Intn(6); i >= 0; i-- {
+		path += fuzzFragments[r.Intn(len(fuzzFragments))]
+	}
+	return path
+}
+
+// TestRequestDocumentServerFuzz 任意のパスでリクエストしても、APIのURL配下のパス以外では合致せず、
+// パニックやドキュメントの変更が起きないことを検証します
+func TestRequestDocumentServerFuzz(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router, store := setUpFuzzRouter(t)
+
+	property := func(path string) bool {
+		req := httptest.NewRequest("GET", "/", nil)
+		// パーセントエンコーディングを解釈済みのパスとして渡す
+		req.URL.Path = "/" + path
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		switch res.Code {
+		case http.StatusOK:
+			// 合致するのはAPIのURL配下のパスのみ
+			return path == fuzzAPIURL || strings.HasPrefix(path, fuzzAPIURL+"/")
+		case http.StatusNotFound, http.StatusBadRequest:
+			return true
+		}
+		t.Logf("unexpected status %d for %q: %s", res.Code, path, res.Body.String())
+		return false
+	}
+	config := &quick.Config{
+		MaxCount: 2000,
+		Values: func(values []reflect.Value, r *rand.Rand) {
+			values[0] = reflect.ValueOf(randomPath(r))
+		},
+		Rand: rand.New(rand.NewSource(1)),
+	}
+	if err := quick.Check(property, config); err != nil {
+		t.Error(err)
+	}
+
+	// ドキュメントとAPIは変更されない
+	store.ViewCollections(func(collections memory.Collections) {
+		assert.Len(t, collections["users"], 1)
+	})
+	store.View(func(tables *memory.Tables) {
+		assert.Len(t, tables.APIs, 1)
+	})
+}
+
+// TestRequestDocumentServerOperators リクエストBodyのMongoDBの演算子や拡張JSONを、ドキュメントに書き込まないことを検証します
+func TestRequestDocumentServerOperators(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router, store := setUpFuzzRouter(t)
+
+	payloads := []struct {
+		method string
+		body   string
+	}{
+		{"PUT", `{"id": "1", "$set": {"name": "bar"}}`},
+		{"PUT", `{"id": "1", "name": "bar", "$unset": {"name": ""}}`},
+		{"PUT", `{"id": "1", "name.first": "bar"}`},
+		{"POST", `{"id": "2", "name": {"$date": "2020-01-01T00:00:00Z"}}`},
+		{"POST", `{"id": "2", "extra": {"$where": "true"}}`},
+		{"POST", `{"id": "2", "extra": {"$oid": "5f0c1e2b9d3e2a1b2c3d4e5f"}}`},
+	}
+	for _, p := range payloads {
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest(p.method, "/"+fuzzAPIURL, bytes.NewBufferString(p.body))
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusBadRequest, res.Code, p.body)
+	}
+
+	store.ViewCollections(func(collections memory.Collections) {
+		if assert.Len(t, collections["users"], 1) {
+			assert.Equal(t, "foo", collections["users"][0]["name"])
+		}
+	})
+
+	t.Run("値が$で始まる文字列は許可する", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/"+fuzzAPIURL, bytes.NewBufferString(`{"id": "1", "name": "$where"}`))
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		store.ViewCollections(func(collections memory.Collections) {
+			assert.Equal(t, "$where", collections["users"][0]["name"])
+		})
+	})
+}
+
+// recordingPublisher 通知された変更イベントを保持します
+type recordingPublisher struct {
+	events []domain.Event
+}
+
+func (p *recordingPublisher) Publish(e domain.Event) {
+	p.events = append(p.events, e)
+}
+
+// TestRequestDocumentServerHookUpdate スクリプトからのドキュメントの更新も、REST APIと同じく検証し、
+// 監査ログと変更イベントに記録することを検証します
+func TestRequestDocumentServerHookUpdate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	publisher := &recordingPublisher{}
+	router, store := setUpFuzzRouterWithPublisher(t, publisher)
+	setAfterScript := func(script string) {
+		store.Update(func(tables *memory.Tables) error {
+			for i := range tables.Methods {
+				if tables.Methods[i].ID == "method-2" {
+					tables.Methods[i].AfterScript = script
+				}
+			}
+			return nil
+		})
+	}
+
+	scripts := []string{
+		// Schemaの型と異なる
+		`collection.update({id: "1", name: 1});`,
+		// MongoDBの演算子
+		`collection.update({id: "1", "$set": {name: "bar"}});`,
+		// 存在しないドキュメント
+		`collection.update({id: "2", name: "bar"});`,
+	}
+	for _, script := range scripts {
+		setAfterScript(script)
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/"+fuzzAPIURL+"/1", nil)
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusInternalServerError, res.Code, script)
+	}
+	store.ViewCollections(func(collections memory.Collections) {
+		if assert.Len(t, collections["users"], 1) {
+			assert.Equal(t, "foo", collections["users"][0]["name"])
+		}
+	})
+
+	setAfterScript(`collection.update({id: "1", name: "bar"});`)
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/"+fuzzAPIURL+"/1", nil)
+	router.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	store.ViewCollections(func(collections memory.Collections) {
+		assert.Equal(t, "bar", collections["users"][0]["name"])
+	})
+
+	// スクリプトを実行したMethodのPUTとして記録する
+	var logs []domain.AuditLog
+	store.View(func(tables *memory.Tables) {
+		logs = append(logs, tables.AuditLogs...)
+	})
+	if assert.NotEmpty(t, logs) {
+		update := logs[len(logs)-1]
+		assert.Equal(t, "PUT", update.HTTPMethod)
+		assert.Equal(t, "method-2", update.MethodID)
+		assert.Equal(t, "1", update.Key)
+		assert.Equal(t, http.StatusOK, update.Status)
+		assert.JSONEq(t, `{"id": "1", "name": "foo"}`, update.Before)
+		assert.JSONEq(t, `{"id": "1", "name": "bar"}`, update.After)
+	}
+	if assert.NotEmpty(t, publisher.events) {
+		e := publisher.events[len(publisher.events)-1]
+		assert.Equal(t, domain.EventUpdate, e.Type)
+		assert.Equal(t, "1", e.Key)
+		assert.Equal(t, "users", e.Collection)
+	}
+}
+
+// TestRequestDocumentServerAudit 書き込みを、機密の項目を伏せて監査ログに記録することを検証します
+func TestRequestDocumentServerAudit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router, store := setUpFuzzRouter(t)
+
+	requests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{"PUT", "/" + fuzzAPIURL, `{"id": "1", "name": "bar", "password": "secret", "token": "abc"}`},
+		{"GET", "/" + fuzzAPIURL + "/1", ""},
+		{"DELETE", "/" + fuzzAPIURL + "/1", ""},
+		{"PUT", "/" + fuzzAPIURL, `{"id": "1", "name": "baz"}`},
+	}
+	for _, r := range requests {
+		req, _ := http.NewRequest(r.method, r.path, bytes.NewBufferString(r.body))
+		req = req.WithContext(domain.WithCaller(req.Context(), "ip:192.0.2.1"))
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	var logs []domain.AuditLog
+	store.View(func(tables *memory.Tables) {
+		logs = append(logs, tables.AuditLogs...)
+	})
+	// setUpFuzzRouterでドキュメントを作成したPOSTと、GET以外のリクエスト
+	if !assert.Len(t, logs, 4) {
+		return
+	}
+
+	update := logs[1]
+	assert.Equal(t, "PUT", update.HTTPMethod)
+	assert.Equal(t, "method-4", update.MethodID)
+	assert.Equal(t, "1", update.Key)
+	assert.Equal(t, "ip:192.0.2.1", update.Caller)
+	assert.Equal(t, http.StatusOK, update.Status)
+	assert.JSONEq(t, `{"id": "1", "name": "foo"}`, update.Before)
+	assert.JSONEq(t, `{"id": "1", "name": "bar", "password": "[REDACTED]", "token": "[REDACTED]"}`, update.After)
+	assert.NotContains(t, update.After, "secret")
+
+	remove := logs[2]
+	assert.Equal(t, "DELETE", remove.HTTPMethod)
+	assert.Equal(t, http.StatusNoContent, remove.Status)
+	assert.Contains(t, remove.Before, `"password":"[REDACTED]"`)
+	assert.Empty(t, remove.After)
+
+	// 存在しないドキュメントの更新も、失敗として記録する
+	failed := logs[3]
+	assert.Equal(t, http.StatusBadRequest, failed.Status)
+	assert.Equal(t, "record is not found", failed.Error)
+	assert.Empty(t, failed.Before)
+}
+
+func TestRequestDocumentServerResolvedMethod(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router, _ := setUpFuzzRouter(t)
+	serve := func(path string, resolved domain.ResolvedMethod) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req = req.WithContext(domain.WithResolvedMethod(req.Context(), resolved))
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+		return res
+	}
+
+	// ミドルウェアで取得したAPIとMethodを使用し、再度取得しない
+	res := serve("/"+fuzzAPIURL+"/1", domain.ResolvedMethod{HTTPMethod: "GET", URL: fuzzAPIURL + "/1", Err: errors.New("api not found")})
+	assert.Equal(t, http.StatusNotFound, res.Code)
+
+	// 別のURLの結果は使用しない
+	res = serve("/"+fuzzAPIURL+"/1", domain.ResolvedMethod{HTTPMethod: "GET", URL: "other", Err: errors.New("api not found")})
+	assert.Equal(t, http.StatusOK, res.Code)
+}
+
+func TestRequestDocumentServerMockLatency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router, store := setUpFuzzRouter(t)
+	store.Update(func(tables *memory.Tables) error {
+		for i := range tables.Methods {
+			if tables.Methods[i].ID == "method-1" {
+				tables.Methods[i].Mode = domain.MethodModeMock
+				tables.Methods[i].MockResponse = `[]`
+				tables.Methods[i].MockLatency = 5000
+			}
+		}
+		return nil
+	})
+
+	start := time.Now()
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest("GET", "/"+fuzzAPIURL, nil))
+
+	// サーバーの書き込みタイムアウトより前に返却するため、待ち時間は上限(100ミリ秒)までにする
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.True(t, time.Since(start) < time.Second)
+}
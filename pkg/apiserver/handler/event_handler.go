@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/cors"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// keepAliveInterval 接続を維持するために、イベントがなくても送信する間隔
+	keepAliveInterval = 15 * time.Second
+	// reconnectDelay SSEのクライアントが再接続するまでの待ち時間(ミリ秒)
+	reconnectDelay = 1000
+	// writeWait WebSocketの書き込みのタイムアウト
+	writeWait = 10 * time.Second
+)
+
+// checkOrigin WebSocketの接続を、URLのAPIのCORSの設定(cors.AllowOrigin)で許可するか判定します
+// Originヘッダーのない(ブラウザ以外からの)接続は許可します
+func (h *APIServerHandler) checkOrigin(url string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		// CORSのミドルウェアで取得済みの場合は、それを使用する
+		if resolved, ok := domain.ResolvedMethodFromContext(r.Context(), r.Method, url); ok {
+			return resolved.Err == nil && cors.AllowOrigin(resolved.API, origin)
+		}
+		api, _, err := h.usecase.ResolveMethod(r.Method, url)
+		return err == nil && cors.AllowOrigin(api, origin)
+	}
+}
+
+// StreamEvents "<api-url>/_events"へのリクエストに、ドキュメントの変更イベントを配信します
+// "Upgrade: websocket"の場合はWebSocket、それ以外はServer-Sent Eventsで配信します
+func (h *APIServerHandler) StreamEvents(c *gin.Context, url string) {
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("lastEventId")
+	}
+
+	events, status, err := h.usecase.Subscribe(c.Request.Context(), url, lastEventID, parseEventFilter(c))
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if websocket.IsWebSocketUpgrade(c.Request) {
+		h.streamWebSocket(c, url, events)
+		return
+	}
+	h.streamSSE(c, events)
+}
+
+// streamSSE Server-Sent Eventsでイベントを配信します
+// サーバーの書き込みタイムアウトより前に配信を終了し、クライアントにはLast-Event-IDで再接続させます
+func (h *APIServerHandler) streamSSE(c *gin.Context, events <-chan domain.Event) {
+	w := c.Writer
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", reconnectDelay)
+	w.Flush()
+
+	var deadline <-chan time.Time
+	if h.streamTimeout > 0 {
+		timer := time.NewTimer(h.streamTimeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-deadline:
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			w.Flush()
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(e)
+			if err != nil {
+				log.Println(err.Error())
+				continue
+			}
+			fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+			w.Flush()
+		}
+	}
+}
+
+// streamWebSocket WebSocketでイベントをJSONで配信します
+// ブラウザからの接続(Originヘッダーあり)は、APIのCORSの設定で許可するオリジンのみ受け付けます
+func (h *APIServerHandler) streamWebSocket(c *gin.Context, url string, events <-chan domain.Event) {
+	upgrader := websocket.Upgrader{CheckOrigin: h.checkOrigin(url)}
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Println(err.Error())
+		return
+	}
+	defer conn.Close()
+
+	// サーバーのタイムアウトを解除し、クライアントが切断するまで配信する
+	conn.SetReadDeadline(time.Time{})
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-keepAlive.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				return
+			}
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteJSON(e); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// parseEventFilter クエリストリングから配信するイベントの条件を作成します
+// "types"はイベントの種類(カンマ区切り)、"lastEventId"以外のパラメータはドキュメントの項目との一致条件になります
+func parseEventFilter(c *gin.Context) domain.EventFilter {
+	filter := domain.EventFilter{Fields: map[string]string{}}
+	for key, values := range c.Request.URL.Query() {
+		switch key {
+		case "types":
+			filter.Types = strings.Split(values[0], ",")
+		case "lastEventId":
+		default:
+			filter.Fields[key] = values[0]
+		}
+	}
+	return filter
+}
@@ -0,0 +1,103 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/handler"
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/usecase"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func newEvents() chan domain.Event {
+	events := make(chan domain.Event, 2)
+	events <- domain.Event{ID: "token-1", Type: domain.EventCreate, Key: "1"}
+	close(events)
+	return events
+}
+
+func TestStreamEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Server-Sent Events", func(t *testing.T) {
+		filter := domain.EventFilter{Types: []string{"create"}, Fields: map[string]string{"status": "draft"}}
+		mockUsecase := new(mocks.APIServerUsecase)
+		mockUsecase.On("Subscribe", "users/_events", "token-0", filter).Return(newEvents(), http.StatusOK, nil).Once()
+
+		router := gin.New()
+		handler.NewAPIServerHandler(router, mockUsecase, nil, nil, time.Second)
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/users/_events?types=create&status=draft", nil)
+		req.Header.Set("Last-Event-ID", "token-0")
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, "text/event-stream", res.Header().Get("Content-Type"))
+		assert.Contains(t, res.Body.String(), "id: token-1\nevent: create\ndata: {\"id\":\"token-1\"")
+		mockUsecase.AssertExpectations(t)
+	})
+	t.Run("WebSocket", func(t *testing.T) {
+		mockUsecase := new(mocks.APIServerUsecase)
+		mockUsecase.On("Subscribe", "users/_events", "token-0", domain.EventFilter{Fields: map[string]string{}}).Return(newEvents(), http.StatusOK, nil).Once()
+
+		router := gin.New()
+		handler.NewAPIServerHandler(router, mockUsecase, nil, nil, 0)
+		server := httptest.NewServer(router)
+		defer server.Close()
+
+		url := "ws" + strings.TrimPrefix(server.URL, "http") + "/users/_events?lastEventId=token-0"
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		var e domain.Event
+		assert.NoError(t, conn.ReadJSON(&e))
+		assert.Equal(t, "token-1", e.ID)
+		assert.Equal(t, domain.EventCreate, e.Type)
+	})
+	t.Run("WebSocketはAPIのCORSの設定で許可するオリジンのみ接続できる", func(t *testing.T) {
+		mockUsecase := new(mocks.APIServerUsecase)
+		mockUsecase.On("Subscribe", "users/_events", "", domain.EventFilter{Fields: map[string]string{}}).Return(newEvents(), http.StatusOK, nil)
+		mockUsecase.On("ResolveMethod", "GET", "users/_events").Return(domain.API{ID: "api", URL: "users", CORSAllowedOrigins: "https://example.com"}, domain.Method{}, nil)
+
+		router := gin.New()
+		handler.NewAPIServerHandler(router, mockUsecase, nil, nil, 0)
+		server := httptest.NewServer(router)
+		defer server.Close()
+		url := "ws" + strings.TrimPrefix(server.URL, "http") + "/users/_events"
+
+		_, res, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": {"https://evil.example.com"}})
+		assert.Error(t, err)
+		if assert.NotNil(t, res) {
+			assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		}
+
+		conn, _, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": {"https://example.com"}})
+		if assert.NoError(t, err) {
+			conn.Close()
+		}
+	})
+	t.Run("配信が有効でないAPI", func(t *testing.T) {
+		mockUsecase := new(mocks.APIServerUsecase)
+		mockUsecase.On("Subscribe", "users/_events", "", domain.EventFilter{Fields: map[string]string{}}).Return(nil, http.StatusNotFound, usecase.ErrAPINotFound).Once()
+
+		router := gin.New()
+		handler.NewAPIServerHandler(router, mockUsecase, nil, nil, 0)
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/users/_events", nil)
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusNotFound, res.Code)
+		assert.Contains(t, res.Body.String(), usecase.ErrAPINotFound.Error())
+	})
+}
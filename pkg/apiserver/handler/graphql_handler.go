@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// graphqlPath GraphQLのクエリを受け付けるパス
+// 生成されたAPIのURLより優先されるため、"graphql"というURLのAPIはREST APIとして呼び出せません
+const graphqlPath = "graphql"
+
+// GraphQL GraphQLのクエリを実行します
+// GETの場合はクエリストリング(query、operationName、variables)、POSTの場合はJSONのBodyでクエリを受け取ります
+func (h *APIServerHandler) GraphQL(c *gin.Context) {
+	var request domain.GraphQLRequest
+
+	if c.Request.Method == http.MethodGet {
+		request.Query = c.Query("query")
+		request.OperationName = c.Query("operationName")
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "variables is not json"})
+				return
+			}
+		}
+	} else if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if request.Query == "" {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "query is required"})
+		return
+	}
+
+	result, status, err := h.graphqlUsecase.Execute(c.Request.Context(), request)
+	if err != nil {
+		c.JSON(status, domain.ErrorResponse{Error: err.Error()})
+		log.Println(err.Error())
+		return
+	}
+
+	c.JSON(status, result)
+}
@@ -0,0 +1,69 @@
+package handler_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/handler"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraphQL(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	result := map[string]interface{}{"data": map[string]interface{}{"user": nil}}
+
+	t.Run("POST", func(t *testing.T) {
+		request := domain.GraphQLRequest{
+			Query:     "query GetUser($id: String) { user(id: $id) { id } }",
+			Variables: map[string]interface{}{"id": "1"},
+		}
+		mockUsecase := new(mocks.GraphQLUsecase)
+		mockUsecase.On("Execute", request).Return(result, http.StatusOK, nil).Once()
+
+		router := gin.New()
+		handler.NewAPIServerHandler(router, new(mocks.APIServerUsecase), mockUsecase, nil, 0)
+
+		body := []byte(`{"query": "query GetUser($id: String) { user(id: $id) { id } }", "variables": {"id": "1"}}`)
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/graphql", bytes.NewBuffer(body))
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `{"data": {"user": null}}`, res.Body.String())
+		mockUsecase.AssertExpectations(t)
+	})
+	t.Run("GET", func(t *testing.T) {
+		request := domain.GraphQLRequest{Query: "{ user(id: \"1\") { id } }"}
+		mockUsecase := new(mocks.GraphQLUsecase)
+		mockUsecase.On("Execute", request).Return(result, http.StatusOK, nil).Once()
+
+		router := gin.New()
+		handler.NewAPIServerHandler(router, new(mocks.APIServerUsecase), mockUsecase, nil, 0)
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", `/graphql?query=%7B+user%28id%3A+%221%22%29+%7B+id+%7D+%7D`, nil)
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+	t.Run("queryがない", func(t *testing.T) {
+		mockUsecase := new(mocks.GraphQLUsecase)
+
+		router := gin.New()
+		handler.NewAPIServerHandler(router, new(mocks.APIServerUsecase), mockUsecase, nil, 0)
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/graphql", bytes.NewBuffer([]byte(`{}`)))
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		mockUsecase.AssertNotCalled(t, "Execute")
+	})
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/rpc"
+
+	"github.com/gin-gonic/gin"
+)
+
+// connectCodes HTTPステータスに対応するConnectのエラーコード
+var connectCodes = map[int]string{
+	http.StatusBadRequest:          "invalid_argument",
+	http.StatusUnauthorized:        "unauthenticated",
+	http.StatusForbidden:           "permission_denied",
+	http.StatusNotFound:            "not_found",
+	http.StatusConflict:            "already_exists",
+	http.StatusTooManyRequests:     "resource_exhausted",
+	http.StatusNotImplemented:      "unimplemented",
+	http.StatusServiceUnavailable:  "unavailable",
+	http.StatusGatewayTimeout:      "deadline_exceeded",
+	http.StatusInternalServerError: "internal",
+}
+
+// connectError Connectのエラーレスポンス
+type connectError struct {
+	Code    string `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// RPC Connectプロトコル(unary)で、APIから作成したprotoのRPCを呼び出します
+// リクエスト、レスポンスは"application/proto"(バイナリ)または"application/json"です
+func (h *APIServerHandler) RPC(c *gin.Context, procedure string) {
+	var codec string
+	switch c.ContentType() {
+	case "application/proto":
+		codec = rpc.CodecProto
+	case "application/json":
+		codec = rpc.CodecJSON
+	default:
+		c.Header("Accept-Post", "application/proto, application/json")
+		c.Status(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	// 圧縮には対応しない
+	if encoding := c.GetHeader("Content-Encoding"); encoding != "" && encoding != "identity" {
+		writeConnectError(c, http.StatusNotImplemented, "content encoding "+encoding+" is not supported")
+		return
+	}
+
+	body, _ := c.GetRawData()
+	response, status, err := h.rpcUsecase.Invoke(c.Request.Context(), procedure, codec, body)
+	if err != nil {
+		writeConnectError(c, status, err.Error())
+		log.Println(err.Error())
+		return
+	}
+
+	c.Data(http.StatusOK, c.ContentType(), response)
+}
+
+// writeConnectError HTTPステータスをConnectのエラーコードに変換して、エラーを返却します
+func writeConnectError(c *gin.Context, status int, message string) {
+	code, ok := connectCodes[status]
+	if !ok {
+		// 対応するコードがない場合は、Connectのクライアントが期待するステータスに合わせる
+		if status < http.StatusInternalServerError {
+			code, status = connectCodes[http.StatusBadRequest], http.StatusBadRequest
+		} else {
+			code, status = "unknown", http.StatusInternalServerError
+		}
+	}
+	c.JSON(status, connectError{Code: code, Message: message})
+}
+
+// isRPC Connectで呼び出すパス("<パッケージ名>.<サービス名>/<RPC名>")か判定します
+func isRPC(httpMethod string, url string) bool {
+	return httpMethod == http.MethodPost && strings.HasPrefix(url, rpc.PackagePrefix+".") && strings.Count(url, "/") == 1
+}
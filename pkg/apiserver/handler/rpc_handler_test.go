@@ -0,0 +1,87 @@
+package handler_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/handler"
+	"github.com/Hajime3778/api-creator-backend/pkg/rpc"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRPC(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	procedure := "/apicreator.users.UserService/GetUser"
+
+	t.Run("JSON", func(t *testing.T) {
+		body := []byte(`{"id": "1"}`)
+		mockUsecase := new(mocks.RPCUsecase)
+		mockUsecase.On("Invoke", procedure, rpc.CodecJSON, body).Return([]byte(`{"id":"1","name":"foo"}`), http.StatusOK, nil).Once()
+
+		router := gin.New()
+		handler.NewAPIServerHandler(router, new(mocks.APIServerUsecase), nil, mockUsecase, 0)
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", procedure, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Connect-Protocol-Version", "1")
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, "application/json", res.Header().Get("Content-Type"))
+		assert.Equal(t, `{"id":"1","name":"foo"}`, res.Body.String())
+		mockUsecase.AssertExpectations(t)
+	})
+	t.Run("protobuf", func(t *testing.T) {
+		body := []byte{0x0a, 0x01, 0x31}
+		mockUsecase := new(mocks.RPCUsecase)
+		mockUsecase.On("Invoke", procedure, rpc.CodecProto, body).Return(body, http.StatusOK, nil).Once()
+
+		router := gin.New()
+		handler.NewAPIServerHandler(router, new(mocks.APIServerUsecase), nil, mockUsecase, 0)
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", procedure, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/proto")
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, "application/proto", res.Header().Get("Content-Type"))
+		assert.Equal(t, body, res.Body.Bytes())
+	})
+	t.Run("エラー", func(t *testing.T) {
+		mockUsecase := new(mocks.RPCUsecase)
+		mockUsecase.On("Invoke", procedure, rpc.CodecJSON, []byte(`{}`)).Return(nil, http.StatusNotFound, errors.New("record not found")).Once()
+
+		router := gin.New()
+		handler.NewAPIServerHandler(router, new(mocks.APIServerUsecase), nil, mockUsecase, 0)
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", procedure, bytes.NewBuffer([]byte(`{}`)))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusNotFound, res.Code)
+		assert.JSONEq(t, `{"code": "not_found", "message": "record not found"}`, res.Body.String())
+	})
+	t.Run("対応していないContent-Type", func(t *testing.T) {
+		mockUsecase := new(mocks.RPCUsecase)
+
+		router := gin.New()
+		handler.NewAPIServerHandler(router, new(mocks.APIServerUsecase), nil, mockUsecase, 0)
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", procedure, bytes.NewBuffer([]byte(`{}`)))
+		req.Header.Set("Content-Type", "text/plain")
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, res.Code)
+		mockUsecase.AssertNotCalled(t, "Invoke")
+	})
+}
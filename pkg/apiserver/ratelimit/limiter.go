@@ -0,0 +1,380 @@
+package ratelimit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/resolve"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// UsagePath 呼び出し元の1か月の利用状況を返却するパス("<api-url>/_usage")
+const UsagePath = "_usage"
+
+// defaultAPIKeyHeader API Keyを指定するヘッダーの既定値
+const defaultAPIKeyHeader = "X-API-Key"
+
+var (
+	// ErrRateLimited "rate limit exceeded"
+	ErrRateLimited = errors.New("rate limit exceeded")
+	// ErrQuotaExceeded "monthly quota exceeded"
+	ErrQuotaExceeded = errors.New("monthly quota exceeded")
+)
+
+// Key API Keyと、API Keyごとの制限
+type Key struct {
+	// Name 呼び出し元の名前(利用状況、制限の識別に使用します)
+	Name string
+	Key  string
+	// Limit Options.Defaultの代わりに適用する制限(無効の場合はOptions.Default)
+	Limit Limit
+	// MonthlyQuota すべてのAPIの合計の1か月のリクエスト数の上限(0の場合は制限しません)
+	MonthlyQuota int
+}
+
+// Options 呼び出し元ごとの制限と、呼び出し元の識別方法
+type Options struct {
+	// Default すべてのリクエストに適用する、呼び出し元ごとの制限
+	Default Limit
+	// APIKeyHeader API Keyを指定するヘッダー(空の場合は"X-API-Key")
+	APIKeyHeader string
+	// Keys 呼び出し元を識別するAPI Key(登録されていないAPI Keyは使用しません)
+	Keys []Key
+	// JWTSecret JWT(HS256)の署名を検証する鍵(空の場合はJWTで識別しません)
+	JWTSecret string
+	// TrustProxy trueの場合は、X-Forwarded-Forの送信元のIPアドレスで識別します
+	TrustProxy bool
+}
+
+// Resolver リクエストされたAPIとMethodを取得します
+type Resolver interface {
+	ResolveMethod(httpMethod string, url string) (domain.API, domain.Method, error)
+}
+
+// Limiter リクエスト数を制限するミドルウェア
+type Limiter struct {
+	store    Store
+	resolver Resolver
+	options  Options
+	keys     map[string]Key
+	now      func() time.Time
+}
+
+// NewLimiter Limiterを作成します
+func NewLimiter(store Store, resolver Resolver, options Options) *Limiter {
+	if options.APIKeyHeader == "" {
+		options.APIKeyHeader = defaultAPIKeyHeader
+	}
+	keys := map[string]Key{}
+	for _, key := range options.Keys {
+		if key.Key != "" {
+			keys[key.Key] = key
+		}
+	}
+	return &Limiter{
+		store:    store,
+		resolver: resolver,
+		options:  options,
+		keys:     keys,
+		now:      time.Now,
+	}
+}
+
+// bucketLimit 1回のリクエストで取り出すトークンバケット
+type bucketLimit struct {
+	key   string
+	limit Limit
+}
+
+// Handle 呼び出し元、API、Methodごとのトークンバケットと、1か月のリクエスト数の上限を判定します
+// 制限を超えた場合は429(Too Many Requests)を返却し、以降のハンドラを実行しません
+func (l *Limiter) Handle(c *gin.Context) {
+	now := l.now()
+	url := strings.TrimPrefix(c.Request.URL.Path, "/")
+	caller, key := l.identify(c.Request, now)
+	// 監査ログ等で呼び出し元を参照できるよう、リクエストのcontextに保持する
+	c.Request = c.Request.WithContext(domain.WithCaller(c.Request.Context(), caller))
+	api, method, err := resolve.Method(c, l.resolver)
+	found := err == nil
+
+	if found && c.Request.Method == http.MethodGet && url == api.URL+"/"+UsagePath {
+		l.usage(c, api, caller, now)
+		return
+	}
+
+	buckets := []bucketLimit{}
+	callerLimit := l.options.Default
+	if key != nil && key.Limit.Enabled() {
+		callerLimit = key.Limit
+	}
+	if callerLimit.Enabled() {
+		buckets = append(buckets, bucketLimit{key: "caller:" + caller, limit: callerLimit})
+	}
+	if found {
+		if limit := (Limit{PerMinute: api.RateLimit, Burst: api.RateBurst}); limit.Enabled() {
+			buckets = append(buckets, bucketLimit{key: "api:" + api.ID + ":" + caller, limit: limit})
+		}
+		if limit := (Limit{PerMinute: method.RateLimit, Burst: method.RateBurst}); method.ID != "" && limit.Enabled() {
+			buckets = append(buckets, bucketLimit{key: "method:" + method.ID + ":" + caller, limit: limit})
+		}
+	}
+	if !l.take(c, buckets, now) {
+		return
+	}
+
+	// 1か月の上限を超えた場合は、取り出したトークンと増やした利用回数を戻す
+	counted := []string{}
+	rollback := func() {
+		l.refund(buckets, now)
+		for _, key := range counted {
+			if err := l.store.Decrement(key, now); err != nil {
+				log.Println(err.Error())
+			}
+		}
+	}
+	periodEnd := nextMonth(now)
+	if key != nil && key.MonthlyQuota > 0 {
+		quotaKey := "quota:key:" + key.Name + ":" + period(now)
+		if !l.increment(c, quotaKey, key.MonthlyQuota, periodEnd, now) {
+			rollback()
+			return
+		}
+		counted = append(counted, quotaKey)
+	}
+	if found {
+		if !l.increment(c, usageKey(api.ID, caller, now), api.MonthlyQuota, periodEnd, now) {
+			rollback()
+			return
+		}
+	}
+	c.Next()
+}
+
+// take すべてのトークンバケットからトークンを取り出し、最も残りが少ないバケットをヘッダーに設定します
+// 1つでもトークンがない場合は、取り出したトークンを戻し、429を返却してfalseを返却します
+// Storeでエラーが発生した場合は、リクエストを制限しません
+func (l *Limiter) take(c *gin.Context, buckets []bucketLimit, now time.Time) bool {
+	var tightest, denied *Result
+	taken := []bucketLimit{}
+	for _, b := range buckets {
+		result, err := l.store.Take(b.key, b.limit, now)
+		if err != nil {
+			log.Println(err.Error())
+			continue
+		}
+		r := result
+		if r.Allowed {
+			taken = append(taken, b)
+		} else if denied == nil || r.RetryAfter > denied.RetryAfter {
+			denied = &r
+		}
+		if tightest == nil || r.Remaining < tightest.Remaining {
+			tightest = &r
+		}
+	}
+
+	if denied != nil {
+		// 制限されたリクエストで、他のバケットのトークンを消費しない
+		l.refund(taken, now)
+		setRateLimitHeaders(c, *denied)
+		c.Header("Retry-After", strconv.Itoa(retryAfter(denied.RetryAfter)))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": ErrRateLimited.Error()})
+		return false
+	}
+	if tightest != nil {
+		setRateLimitHeaders(c, *tightest)
+	}
+	return true
+}
+
+// refund 取り出したトークンをトークンバケットに戻します
+func (l *Limiter) refund(buckets []bucketLimit, now time.Time) {
+	for _, b := range buckets {
+		if err := l.store.Refund(b.key, b.limit, now); err != nil {
+			log.Println(err.Error())
+		}
+	}
+}
+
+// increment 1か月の利用回数を増やします。quotaを超える場合は、429を返却してfalseを返却します
+func (l *Limiter) increment(c *gin.Context, key string, quota int, periodEnd time.Time, now time.Time) bool {
+	count, ok, err := l.store.Increment(key, int64(quota), periodEnd, now)
+	if err != nil {
+		log.Println(err.Error())
+		return true
+	}
+	if quota <= 0 {
+		return true
+	}
+
+	reset := strconv.Itoa(int(periodEnd.Sub(now).Seconds()))
+	c.Header("X-Quota-Limit", strconv.Itoa(quota))
+	c.Header("X-Quota-Remaining", strconv.FormatInt(int64(quota)-count, 10))
+	c.Header("X-Quota-Reset", reset)
+	if !ok {
+		c.Header("Retry-After", reset)
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": ErrQuotaExceeded.Error()})
+		return false
+	}
+	return true
+}
+
+// usage 呼び出し元の、APIの1か月の利用状況を返却します
+func (l *Limiter) usage(c *gin.Context, api domain.API, caller string, now time.Time) {
+	used, err := l.store.Count(usageKey(api.ID, caller, now), now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	usage := domain.RateLimitUsage{
+		APIID:     api.ID,
+		Caller:    caller,
+		Period:    period(now),
+		Used:      used,
+		Quota:     api.MonthlyQuota,
+		Remaining: -1,
+		ResetAt:   nextMonth(now),
+	}
+	if api.MonthlyQuota > 0 {
+		usage.Remaining = int64(api.MonthlyQuota) - used
+	}
+	c.JSON(http.StatusOK, usage)
+}
+
+// identify 呼び出し元を識別します
+// 登録されたAPI Key("key:<name>")、検証できたJWTのsub("sub:<subject>")、IPアドレス("ip:<address>")の順に使用します
+func (l *Limiter) identify(r *http.Request, now time.Time) (string, *Key) {
+	if value := r.Header.Get(l.options.APIKeyHeader); value != "" {
+		if key, ok := l.keys[value]; ok {
+			return "key:" + key.Name, &key
+		}
+	}
+
+	if l.options.JWTSecret != "" {
+		authorization := r.Header.Get("Authorization")
+		if strings.HasPrefix(authorization, "Bearer ") {
+			if subject, err := verifyJWT(strings.TrimPrefix(authorization, "Bearer "), l.options.JWTSecret, now); err == nil {
+				return "sub:" + subject, nil
+			}
+		}
+	}
+
+	if l.options.TrustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			if ip := strings.TrimSpace(strings.Split(forwarded, ",")[0]); ip != "" {
+				return "ip:" + ip, nil
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host, nil
+}
+
+// verifyJWT JWT(HS256)の署名と有効期限を検証し、subを返却します
+func verifyJWT(token string, secret string, now time.Time) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errors.New("invalid token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return "", err
+	}
+	// "none"などの署名しないアルゴリズムは受け付けない
+	if header.Alg != "HS256" {
+		return "", errors.New("unsupported algorithm " + header.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return "", errors.New("invalid signature")
+	}
+
+	var claims struct {
+		Subject   string   `json:"sub"`
+		ExpiresAt *float64 `json:"exp"`
+		NotBefore *float64 `json:"nbf"`
+	}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return "", err
+	}
+	unix := float64(now.Unix())
+	if claims.ExpiresAt != nil && unix >= *claims.ExpiresAt {
+		return "", errors.New("token is expired")
+	}
+	if claims.NotBefore != nil && unix < *claims.NotBefore {
+		return "", errors.New("token is not valid yet")
+	}
+	if claims.Subject == "" {
+		return "", errors.New("sub is required")
+	}
+	return claims.Subject, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// setRateLimitHeaders トークンバケットの状態をRateLimit-*ヘッダーに設定します
+func setRateLimitHeaders(c *gin.Context, result Result) {
+	c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+}
+
+// retryAfter 再試行までの秒数(切り上げ、1秒以上)
+// 1秒未満を切り捨てると0になり、クライアントがすぐに再試行して再び制限されるため
+func retryAfter(d time.Duration) int {
+	if seconds := ceilSeconds(d); seconds > 1 {
+		return seconds
+	}
+	return 1
+}
+
+// ceilSeconds 秒数を切り上げます
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// usageKey 呼び出し元の、APIの1か月の利用回数のキー
+func usageKey(apiID string, caller string, now time.Time) string {
+	return "usage:" + apiID + ":" + caller + ":" + period(now)
+}
+
+// period 利用回数を集計する月(UTC)
+func period(now time.Time) string {
+	return now.UTC().Format("2006-01")
+}
+
+// nextMonth 翌月の開始日時(UTC)
+func nextMonth(now time.Time) time.Time {
+	year, month, _ := now.UTC().Date()
+	return time.Date(year, month+1, 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,223 @@
+package ratelimit_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/ratelimit"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSecret = "secret"
+
+var (
+	testAPI    = domain.API{ID: "api-1", URL: "my-project/api/users", RateLimit: 60, RateBurst: 3, MonthlyQuota: 5}
+	testMethod = domain.Method{ID: "method-1", APIID: "api-1", Type: "POST", RateLimit: 1}
+)
+
+// setUpRouter Limiterを登録し、制限されなかったリクエストに200を返却するルーターを作成します
+func setUpRouter(options ratelimit.Options) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	resolver := new(mocks.APIServerUsecase)
+	resolver.On("ResolveMethod", "GET", mock.MatchedBy(func(url string) bool { return url != "other" })).Return(testAPI, domain.Method{}, nil)
+	resolver.On("ResolveMethod", "POST", mock.Anything).Return(testAPI, testMethod, nil)
+	resolver.On("ResolveMethod", "GET", "other").Return(domain.API{}, domain.Method{}, errors.New("api not found"))
+
+	router := gin.New()
+	router.Use(ratelimit.NewLimiter(ratelimit.NewMemoryStore(), resolver, options).Handle)
+	router.Any("/*path", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+func request(router *gin.Engine, method string, path string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+	return res
+}
+
+// token HS256で署名したJWTを作成します
+func token(alg string, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestHandle(t *testing.T) {
+	t.Run("APIの制限を超えると429を返却する", func(t *testing.T) {
+		router := setUpRouter(ratelimit.Options{})
+
+		for i := 0; i < 3; i++ {
+			res := request(router, "GET", "/my-project/api/users", nil)
+			assert.Equal(t, http.StatusOK, res.Code)
+			assert.Equal(t, "3", res.Header().Get("RateLimit-Limit"))
+		}
+		res := request(router, "GET", "/my-project/api/users", nil)
+
+		assert.Equal(t, http.StatusTooManyRequests, res.Code)
+		assert.Equal(t, "1", res.Header().Get("Retry-After"))
+		assert.Equal(t, "0", res.Header().Get("RateLimit-Remaining"))
+		assert.Contains(t, res.Body.String(), ratelimit.ErrRateLimited.Error())
+	})
+	t.Run("Methodの制限はAPIの制限に加えて適用する", func(t *testing.T) {
+		router := setUpRouter(ratelimit.Options{})
+
+		assert.Equal(t, http.StatusOK, request(router, "POST", "/my-project/api/users", nil).Code)
+		res := request(router, "POST", "/my-project/api/users", nil)
+
+		assert.Equal(t, http.StatusTooManyRequests, res.Code)
+		assert.Equal(t, "60", res.Header().Get("Retry-After"))
+		// GETはMethodの制限を受けない
+		assert.Equal(t, http.StatusOK, request(router, "GET", "/my-project/api/users", nil).Code)
+	})
+	t.Run("2つ目の制限で拒否した場合は1つ目の制限と1か月の利用回数を消費しない", func(t *testing.T) {
+		router := setUpRouter(ratelimit.Options{})
+
+		// APIの制限(容量3)は残り2、Methodの制限(容量1)は残り0
+		assert.Equal(t, http.StatusOK, request(router, "POST", "/my-project/api/users", nil).Code)
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, http.StatusTooManyRequests, request(router, "POST", "/my-project/api/users", nil).Code)
+		}
+
+		res := request(router, "GET", "/my-project/api/users/_usage", nil)
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Contains(t, res.Body.String(), `"used":1`)
+		assert.Equal(t, http.StatusOK, request(router, "GET", "/my-project/api/users", nil).Code)
+		assert.Equal(t, http.StatusOK, request(router, "GET", "/my-project/api/users", nil).Code)
+		assert.Equal(t, http.StatusTooManyRequests, request(router, "GET", "/my-project/api/users", nil).Code)
+	})
+	t.Run("APIの1か月の上限を超えた場合はAPI Keyの上限を消費しない", func(t *testing.T) {
+		store := ratelimit.NewMemoryStore()
+		resolver := new(mocks.APIServerUsecase)
+		resolver.On("ResolveMethod", "GET", mock.Anything).Return(domain.API{ID: "api-2", URL: "limited", MonthlyQuota: 1}, domain.Method{}, nil)
+		router := gin.New()
+		router.Use(ratelimit.NewLimiter(store, resolver, ratelimit.Options{Keys: []ratelimit.Key{{Name: "client", Key: "key-1", MonthlyQuota: 10}}}).Handle)
+		router.Any("/*path", func(c *gin.Context) { c.Status(http.StatusOK) })
+		header := map[string]string{"X-API-Key": "key-1"}
+
+		assert.Equal(t, http.StatusOK, request(router, "GET", "/limited", header).Code)
+		assert.Equal(t, http.StatusTooManyRequests, request(router, "GET", "/limited", header).Code)
+		assert.Equal(t, http.StatusTooManyRequests, request(router, "GET", "/limited", header).Code)
+
+		now := time.Now()
+		count, err := store.Count("quota:key:client:"+now.UTC().Format("2006-01"), now)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+	t.Run("呼び出し元ごとに制限する", func(t *testing.T) {
+		router := setUpRouter(ratelimit.Options{TrustProxy: true})
+
+		assert.Equal(t, http.StatusOK, request(router, "POST", "/my-project/api/users", map[string]string{"X-Forwarded-For": "198.51.100.1"}).Code)
+		assert.Equal(t, http.StatusOK, request(router, "POST", "/my-project/api/users", map[string]string{"X-Forwarded-For": "198.51.100.2, 10.0.0.1"}).Code)
+		assert.Equal(t, http.StatusTooManyRequests, request(router, "POST", "/my-project/api/users", map[string]string{"X-Forwarded-For": "198.51.100.1"}).Code)
+	})
+	t.Run("TrustProxyでない場合はX-Forwarded-Forを使用しない", func(t *testing.T) {
+		router := setUpRouter(ratelimit.Options{})
+
+		assert.Equal(t, http.StatusOK, request(router, "POST", "/my-project/api/users", map[string]string{"X-Forwarded-For": "198.51.100.1"}).Code)
+		assert.Equal(t, http.StatusTooManyRequests, request(router, "POST", "/my-project/api/users", map[string]string{"X-Forwarded-For": "198.51.100.2"}).Code)
+	})
+	t.Run("既定の制限はAPIが存在しなくても適用する", func(t *testing.T) {
+		router := setUpRouter(ratelimit.Options{Default: ratelimit.Limit{PerMinute: 1}})
+
+		assert.Equal(t, http.StatusOK, request(router, "GET", "/other", nil).Code)
+		assert.Equal(t, http.StatusTooManyRequests, request(router, "GET", "/other", nil).Code)
+	})
+	t.Run("Retry-Afterは1秒未満を切り上げる", func(t *testing.T) {
+		// 1分に90回の場合、次のトークンは約667ミリ秒後に補充される
+		router := setUpRouter(ratelimit.Options{Default: ratelimit.Limit{PerMinute: 90, Burst: 1}})
+
+		assert.Equal(t, http.StatusOK, request(router, "GET", "/other", nil).Code)
+		res := request(router, "GET", "/other", nil)
+
+		assert.Equal(t, http.StatusTooManyRequests, res.Code)
+		assert.Equal(t, "1", res.Header().Get("Retry-After"))
+		assert.Equal(t, "1", res.Header().Get("RateLimit-Reset"))
+	})
+	t.Run("1か月の上限を超えると429を返却する", func(t *testing.T) {
+		header := map[string]string{"X-API-Key": "key-1"}
+		router := setUpRouter(ratelimit.Options{Keys: []ratelimit.Key{{Name: "client", Key: "key-1", MonthlyQuota: 2}}})
+
+		assert.Equal(t, http.StatusOK, request(router, "GET", "/my-project/api/users", header).Code)
+		res := request(router, "GET", "/my-project/api/users", header)
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, "5", res.Header().Get("X-Quota-Limit"))
+		assert.Equal(t, "3", res.Header().Get("X-Quota-Remaining"))
+
+		res = request(router, "GET", "/my-project/api/users", header)
+		assert.Equal(t, http.StatusTooManyRequests, res.Code)
+		assert.Contains(t, res.Body.String(), ratelimit.ErrQuotaExceeded.Error())
+		assert.NotEmpty(t, res.Header().Get("Retry-After"))
+	})
+}
+
+func TestIdentify(t *testing.T) {
+	options := ratelimit.Options{
+		Keys:      []ratelimit.Key{{Name: "client", Key: "key-1", Limit: ratelimit.Limit{PerMinute: 100, Burst: 100}}},
+		JWTSecret: testSecret,
+	}
+	usage := func(router *gin.Engine, header map[string]string) domain.RateLimitUsage {
+		res := request(router, "GET", "/my-project/api/users/"+ratelimit.UsagePath, header)
+		var usage domain.RateLimitUsage
+		json.Unmarshal(res.Body.Bytes(), &usage)
+		return usage
+	}
+	exp := float64(time.Now().Add(time.Hour).Unix())
+
+	cases := []struct {
+		name   string
+		header map[string]string
+		caller string
+	}{
+		{"登録されたAPI Key", map[string]string{"X-API-Key": "key-1"}, "key:client"},
+		{"登録されていないAPI Key", map[string]string{"X-API-Key": "unknown"}, "ip:192.0.2.1"},
+		{"JWT", map[string]string{"Authorization": "Bearer " + token("HS256", map[string]interface{}{"sub": "user-1", "exp": exp})}, "sub:user-1"},
+		{"期限切れのJWT", map[string]string{"Authorization": "Bearer " + token("HS256", map[string]interface{}{"sub": "user-1", "exp": 1})}, "ip:192.0.2.1"},
+		{"署名しないJWT", map[string]string{"Authorization": "Bearer " + token("none", map[string]interface{}{"sub": "user-1"})}, "ip:192.0.2.1"},
+		{"改ざんされたJWT", map[string]string{"Authorization": "Bearer " + token("HS256", map[string]interface{}{"sub": "user-1"}) + "x"}, "ip:192.0.2.1"},
+		{"subのないJWT", map[string]string{"Authorization": "Bearer " + token("HS256", map[string]interface{}{"exp": exp})}, "ip:192.0.2.1"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			router := setUpRouter(options)
+
+			assert.Equal(t, c.caller, usage(router, c.header).Caller)
+		})
+	}
+
+	t.Run("利用状況", func(t *testing.T) {
+		router := setUpRouter(options)
+		header := map[string]string{"X-API-Key": "key-1"}
+		request(router, "GET", "/my-project/api/users", header)
+		request(router, "GET", "/my-project/api/users/1", header)
+
+		got := usage(router, header)
+
+		assert.Equal(t, "api-1", got.APIID)
+		assert.Equal(t, time.Now().UTC().Format("2006-01"), got.Period)
+		assert.Equal(t, int64(2), got.Used)
+		assert.Equal(t, 5, got.Quota)
+		assert.Equal(t, int64(3), got.Remaining)
+		// 利用状況の取得は利用回数に含めない
+		assert.Equal(t, int64(2), usage(router, header).Used)
+	})
+}
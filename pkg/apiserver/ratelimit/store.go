@@ -0,0 +1,206 @@
+// Package ratelimit APIサーバーのリクエスト数を、呼び出し元(API Key、JWTのsub、IPアドレス)ごとに制限します
+// API、Methodごとの制限はトークンバケットで、1か月のリクエスト数の上限は利用回数で判定します
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval メモリ上の不要なバケット、利用回数を削除する間隔
+const sweepInterval = time.Minute
+
+// Limit トークンバケットの設定
+type Limit struct {
+	// PerMinute 1分あたりに補充するトークン数(0の場合は制限しません)
+	PerMinute int
+	// Burst バケットの容量(0の場合はPerMinuteと同じ)
+	Burst int
+}
+
+// Enabled 制限する場合はtrueを返却します
+func (l Limit) Enabled() bool {
+	return l.PerMinute > 0
+}
+
+func (l Limit) capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(l.PerMinute)
+}
+
+func (l Limit) perSecond() float64 {
+	return float64(l.PerMinute) / 60
+}
+
+// Result トークンを取り出した結果
+type Result struct {
+	Allowed bool
+	// Limit バケットの容量
+	Limit int
+	// Remaining バケットに残っているトークン数
+	Remaining int
+	// RetryAfter 次のトークンが補充されるまでの時間(Allowedがfalseの場合)
+	RetryAfter time.Duration
+	// Reset バケットが満たされるまでの時間
+	Reset time.Duration
+}
+
+// Store トークンバケットと利用回数を保持します
+// APIサーバーを複数実行する場合は、共有のストレージ(Redisなど)を使用するStoreを実装します
+type Store interface {
+	// Take keyのトークンバケットからトークンを1つ取り出します
+	Take(key string, limit Limit, now time.Time) (Result, error)
+	// Refund Takeで取り出したトークンを、keyのトークンバケットに1つ戻します(容量は超えません)
+	Refund(key string, limit Limit, now time.Time) error
+	// Increment keyの利用回数がmax未満の場合(maxが0の場合は常に)1増やし、増やした後の回数とtrueを返却します
+	// 利用回数はexpiresAt以降は0から数え直します
+	Increment(key string, max int64, expiresAt time.Time, now time.Time) (int64, bool, error)
+	// Decrement Incrementで増やしたkeyの利用回数を1減らします
+	Decrement(key string, now time.Time) error
+	// Count keyの利用回数を返却します
+	Count(key string, now time.Time) (int64, error)
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	// fullAt バケットが満たされる日時(以降は削除しても結果が変わらない)
+	fullAt time.Time
+}
+
+type counter struct {
+	count     int64
+	expiresAt time.Time
+}
+
+type memoryStore struct {
+	mu       sync.Mutex
+	buckets  map[string]*bucket
+	counters map[string]*counter
+	sweptAt  time.Time
+}
+
+// NewMemoryStore メモリ上で保持するStoreを作成します(APIサーバーのプロセスごとに制限します)
+func NewMemoryStore() Store {
+	return &memoryStore{
+		buckets:  map[string]*bucket{},
+		counters: map[string]*counter{},
+	}
+}
+
+// Take keyのトークンバケットからトークンを1つ取り出します
+func (s *memoryStore) Take(key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	capacity := limit.capacity()
+	rate := limit.perSecond()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updatedAt: now}
+		s.buckets[key] = b
+	} else if now.After(b.updatedAt) {
+		b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updatedAt).Seconds()*rate)
+		b.updatedAt = now
+	}
+
+	result := Result{Limit: int(capacity)}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((capacity - b.tokens) / rate)
+	b.fullAt = now.Add(result.Reset)
+	return result, nil
+}
+
+// Refund keyのトークンバケットにトークンを1つ戻します
+func (s *memoryStore) Refund(key string, limit Limit, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		return nil
+	}
+	capacity := limit.capacity()
+	rate := limit.perSecond()
+	if now.After(b.updatedAt) {
+		b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updatedAt).Seconds()*rate)
+		b.updatedAt = now
+	}
+	b.tokens = math.Min(capacity, b.tokens+1)
+	b.fullAt = now.Add(seconds((capacity - b.tokens) / rate))
+	return nil
+}
+
+// Increment keyの利用回数がmax未満の場合に1増やします
+func (s *memoryStore) Increment(key string, max int64, expiresAt time.Time, now time.Time) (int64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	c, ok := s.counters[key]
+	if !ok || !now.Before(c.expiresAt) {
+		c = &counter{expiresAt: expiresAt}
+		s.counters[key] = c
+	}
+	if max > 0 && c.count >= max {
+		return c.count, false, nil
+	}
+	c.count++
+	return c.count, true, nil
+}
+
+// Decrement keyの利用回数を1減らします
+func (s *memoryStore) Decrement(key string, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.counters[key]; ok && now.Before(c.expiresAt) && c.count > 0 {
+		c.count--
+	}
+	return nil
+}
+
+// Count keyの利用回数を返却します
+func (s *memoryStore) Count(key string, now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.counters[key]
+	if !ok || !now.Before(c.expiresAt) {
+		return 0, nil
+	}
+	return c.count, nil
+}
+
+// sweep 満たされたバケットと、期限が過ぎた利用回数を削除します
+func (s *memoryStore) sweep(now time.Time) {
+	if now.Sub(s.sweptAt) < sweepInterval {
+		return
+	}
+	s.sweptAt = now
+	for key, b := range s.buckets {
+		if !now.Before(b.fullAt) {
+			delete(s.buckets, key)
+		}
+	}
+	for key, c := range s.counters {
+		if !now.Before(c.expiresAt) {
+			delete(s.counters, key)
+		}
+	}
+}
+
+// seconds 秒を、1秒単位に切り上げたDurationにします
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s)) * time.Second
+}
@@ -0,0 +1,140 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTake(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("容量まで連続して取り出せる", func(t *testing.T) {
+		store := ratelimit.NewMemoryStore()
+		limit := ratelimit.Limit{PerMinute: 60, Burst: 3}
+
+		for i := 2; i >= 0; i-- {
+			result, err := store.Take("key", limit, start)
+			assert.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, 3, result.Limit)
+			assert.Equal(t, i, result.Remaining)
+		}
+
+		result, err := store.Take("key", limit, start)
+		assert.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, time.Second, result.RetryAfter)
+		assert.Equal(t, 3*time.Second, result.Reset)
+	})
+	t.Run("時間の経過で補充する", func(t *testing.T) {
+		store := ratelimit.NewMemoryStore()
+		limit := ratelimit.Limit{PerMinute: 1}
+
+		result, _ := store.Take("key", limit, start)
+		assert.True(t, result.Allowed)
+		result, _ = store.Take("key", limit, start.Add(30*time.Second))
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 30*time.Second, result.RetryAfter)
+
+		result, _ = store.Take("key", limit, start.Add(time.Minute))
+		assert.True(t, result.Allowed)
+	})
+	t.Run("キーごとに制限する", func(t *testing.T) {
+		store := ratelimit.NewMemoryStore()
+		limit := ratelimit.Limit{PerMinute: 1}
+
+		result, _ := store.Take("a", limit, start)
+		assert.True(t, result.Allowed)
+		result, _ = store.Take("b", limit, start)
+		assert.True(t, result.Allowed)
+		result, _ = store.Take("a", limit, start)
+		assert.False(t, result.Allowed)
+	})
+}
+
+func TestRefund(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := ratelimit.NewMemoryStore()
+	limit := ratelimit.Limit{PerMinute: 1, Burst: 2}
+
+	store.Take("key", limit, start)
+	store.Take("key", limit, start)
+	assert.NoError(t, store.Refund("key", limit, start))
+
+	result, _ := store.Take("key", limit, start)
+	assert.True(t, result.Allowed)
+	result, _ = store.Take("key", limit, start)
+	assert.False(t, result.Allowed)
+
+	// 容量を超えて戻さない
+	store.Refund("key", limit, start)
+	store.Refund("key", limit, start)
+	store.Refund("key", limit, start)
+	result, _ = store.Take("key", limit, start)
+	assert.Equal(t, 1, result.Remaining)
+}
+
+func TestDecrement(t *testing.T) {
+	start := time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC)
+	expiresAt := start.Add(24 * time.Hour)
+	store := ratelimit.NewMemoryStore()
+
+	store.Increment("key", 2, expiresAt, start)
+	store.Increment("key", 2, expiresAt, start)
+	assert.NoError(t, store.Decrement("key", start))
+
+	count, ok, _ := store.Increment("key", 2, expiresAt, start)
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), count)
+
+	// 存在しない利用回数は変更しない
+	assert.NoError(t, store.Decrement("other", start))
+	count, _ = store.Count("other", start)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestIncrement(t *testing.T) {
+	start := time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC)
+	expiresAt := time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("上限まで増やす", func(t *testing.T) {
+		store := ratelimit.NewMemoryStore()
+
+		for i := int64(1); i <= 2; i++ {
+			count, ok, err := store.Increment("key", 2, expiresAt, start)
+			assert.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, i, count)
+		}
+		count, ok, _ := store.Increment("key", 2, expiresAt, start)
+		assert.False(t, ok)
+		assert.Equal(t, int64(2), count)
+
+		count, _ = store.Count("key", start)
+		assert.Equal(t, int64(2), count)
+	})
+	t.Run("上限なし", func(t *testing.T) {
+		store := ratelimit.NewMemoryStore()
+
+		for i := 0; i < 5; i++ {
+			_, ok, _ := store.Increment("key", 0, expiresAt, start)
+			assert.True(t, ok)
+		}
+		count, _ := store.Count("key", start)
+		assert.Equal(t, int64(5), count)
+	})
+	t.Run("期限が過ぎると0から数え直す", func(t *testing.T) {
+		store := ratelimit.NewMemoryStore()
+		store.Increment("key", 1, expiresAt, start)
+
+		count, _ := store.Count("key", expiresAt)
+		assert.Equal(t, int64(0), count)
+		count, ok, _ := store.Increment("key", 1, expiresAt.AddDate(0, 1, 0), expiresAt)
+		assert.True(t, ok)
+		assert.Equal(t, int64(1), count)
+	})
+}
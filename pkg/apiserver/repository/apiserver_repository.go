@@ -1,23 +1,41 @@
 package repository
 
 import (
+	"context"
+	"encoding/base64"
 	"errors"
+	"log"
 	"net/http"
+	"time"
 
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
 	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/database"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+const (
+	// namespaceNotFoundCode 対象のコレクションが存在しない場合のエラーコード
+	namespaceNotFoundCode = 26
+	// namespaceExistsCode 変更先のコレクションがすでに存在する場合のエラーコード
+	namespaceExistsCode = 48
+)
+
 // APIServerRepository Interface
 type APIServerRepository interface {
 	Get(modelName string, key string, param interface{}) (interface{}, int, error)
 	GetList(modelName string, key string, param interface{}) (interface{}, int, error)
+	Count(modelName string, filter map[string]interface{}) (int64, int, error)
+	Each(modelName string, filter map[string]interface{}, fn func(document bson.M) error) (int, error)
 	Create(modelName string, key string, body []byte) (interface{}, int, error)
+	InsertMany(modelName string, keyName string, documents []bson.M) (int, error)
 	Update(modelName string, key string, body []byte) (interface{}, int, error)
 	Delete(modelName string, key string, param interface{}) (interface{}, int, error)
 	RemoveCollection(modelName string) (interface{}, int, error)
+	RenameCollection(modelName string, newModelName string) (interface{}, int, error)
+	ReplaceCollection(modelName string, newModelName string) (int, error)
+	Watch(ctx context.Context, modelName string, keyName string, resumeToken string) (<-chan domain.Event, error)
 }
 
 type apiServerRepository struct {
@@ -95,6 +113,62 @@ func (r *apiServerRepository) GetList(modelName string, key string, param interf
 	return response, http.StatusOK, nil
 }
 
+// Count 条件に一致するドキュメントの件数を取得します
+func (r *apiServerRepository) Count(modelName string, filter map[string]interface{}) (int64, int, error) {
+	mongoConn, ctx, cancel := r.db.NewMongoDBConnection()
+	defer cancel()
+
+	count, err := mongoConn.Collection(modelName).CountDocuments(ctx, toFilter(filter))
+	if err != nil {
+		return 0, http.StatusInternalServerError, err
+	}
+	return count, http.StatusOK, nil
+}
+
+// Each 条件に一致するドキュメントを1件ずつfnに渡します(すべてのドキュメントをメモリに読み込みません)
+// 件数が多い場合を考慮し、接続のタイムアウトは使用しません
+func (r *apiServerRepository) Each(modelName string, filter map[string]interface{}, fn func(document bson.M) error) (int, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client, err := r.db.NewMongoDBClient(ctx)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	defer client.Disconnect(context.Background())
+
+	option := options.Find()
+	// _idを除外
+	option.SetProjection(bson.D{{Key: "_id", Value: 0}})
+
+	cur, err := client.Database(r.db.DBName).Collection(modelName).Find(ctx, toFilter(filter), option)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var doc bson.M
+		if err := cur.Decode(&doc); err != nil {
+			return http.StatusInternalServerError, err
+		}
+		if err := fn(doc); err != nil {
+			return http.StatusInternalServerError, err
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+// toFilter 条件をbsonにします(条件がない場合はすべてのドキュメント)
+func toFilter(filter map[string]interface{}) bson.M {
+	if filter == nil {
+		return bson.M{}
+	}
+	return bson.M(filter)
+}
+
 // Create APIServerを追加します
 func (r *apiServerRepository) Create(modelName string, keyName string, body []byte) (interface{}, int, error) {
 
@@ -113,6 +187,24 @@ func (r *apiServerRepository) Create(modelName string, keyName string, body []by
 	return b, http.StatusCreated, nil
 }
 
+// InsertMany 複数のドキュメントを1回で追加します(Keyの重複は検証しません)
+func (r *apiServerRepository) InsertMany(modelName string, keyName string, documents []bson.M) (int, error) {
+	if len(documents) == 0 {
+		return http.StatusCreated, nil
+	}
+	mongoConn, ctx, cancel := r.db.NewMongoDBConnection()
+	defer cancel()
+
+	values := make([]interface{}, len(documents))
+	for i, document := range documents {
+		values[i] = document
+	}
+	if _, err := mongoConn.Collection(modelName).InsertMany(ctx, values); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusCreated, nil
+}
+
 // Update APIServerを更新します
 func (r *apiServerRepository) Update(modelName string, keyName string, body []byte) (interface{}, int, error) {
 	mongoConn, ctx, cancel := r.db.NewMongoDBConnection()
@@ -128,7 +220,6 @@ func (r *apiServerRepository) Update(modelName string, keyName string, body []by
 		return "", http.StatusInternalServerError, err
 	}
 
-	// TODO: UniqueKey設定できるようにする
 	id := requestBody[keyName].(interface{})
 
 	err = bson.UnmarshalExtJSON(body, false, &updateModel)
@@ -136,7 +227,8 @@ func (r *apiServerRepository) Update(modelName string, keyName string, body []by
 		return "", http.StatusInternalServerError, err
 	}
 
-	filter := bson.D{{Key: "id", Value: id}}
+	// Keyが"id"以外のModelもあるため、ModelのKeyで検索する
+	filter := bson.D{{Key: keyName, Value: id}}
 	update := bson.D{{Key: "$set", Value: updateModel}}
 
 	_, err = collection.UpdateOne(ctx, filter, &update)
@@ -180,3 +272,132 @@ func (r *apiServerRepository) RemoveCollection(modelName string) (interface{}, i
 
 	return "", http.StatusNoContent, nil
 }
+
+// RenameCollection Collection名を変更します
+func (r *apiServerRepository) RenameCollection(modelName string, newModelName string) (interface{}, int, error) {
+	mongoConn, ctx, cancel := r.db.NewMongoDBConnection()
+	defer cancel()
+
+	// renameCollectionはadminデータベースに対して実行する必要がある
+	command := bson.D{
+		{Key: "renameCollection", Value: mongoConn.Name() + "." + modelName},
+		{Key: "to", Value: mongoConn.Name() + "." + newModelName},
+	}
+
+	err := mongoConn.Client().Database("admin").RunCommand(ctx, command).Err()
+	if cmdErr, ok := err.(mongo.CommandError); ok && cmdErr.Code == namespaceNotFoundCode {
+		// まだドキュメントが1件も登録されていない場合はコレクションが存在しない
+		return "", http.StatusNoContent, nil
+	} else if cmdErr, ok := err.(mongo.CommandError); ok && cmdErr.Code == namespaceExistsCode {
+		return "", http.StatusConflict, errors.New("collection is exists")
+	} else if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+
+	return "", http.StatusNoContent, nil
+}
+
+// ReplaceCollection Collection名を変更し、変更先のCollectionを置き換えます
+// 変更元のCollectionが存在しない場合は、変更先のCollectionを削除します(空のCollectionで置き換えます)
+func (r *apiServerRepository) ReplaceCollection(modelName string, newModelName string) (int, error) {
+	mongoConn, ctx, cancel := r.db.NewMongoDBConnection()
+	defer cancel()
+
+	// dropTargetを指定すると、変更先の削除と名前の変更を1回で行う
+	command := bson.D{
+		{Key: "renameCollection", Value: mongoConn.Name() + "." + modelName},
+		{Key: "to", Value: mongoConn.Name() + "." + newModelName},
+		{Key: "dropTarget", Value: true},
+	}
+
+	err := mongoConn.Client().Database("admin").RunCommand(ctx, command).Err()
+	if cmdErr, ok := err.(mongo.CommandError); ok && cmdErr.Code == namespaceNotFoundCode {
+		if err := mongoConn.Collection(newModelName).Drop(ctx); err != nil {
+			return http.StatusInternalServerError, err
+		}
+		return http.StatusNoContent, nil
+	} else if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusNoContent, nil
+}
+
+// changeEvent Change Streamで受信する変更
+type changeEvent struct {
+	OperationType string `bson:"operationType"`
+	FullDocument  bson.M `bson:"fullDocument"`
+	ClusterTime   struct {
+		T uint32 `bson:"t"`
+	} `bson:"clusterTime"`
+}
+
+// Watch Change Streamでコレクションの変更を監視します。ctxがキャンセルされるまで監視を続けます
+// Change Streamはレプリカセットでのみ利用でき、それ以外の場合はエラーを返却します
+// 削除イベントでは削除されたドキュメントを取得できないため、Keyは設定されません
+func (r *apiServerRepository) Watch(ctx context.Context, modelName string, keyName string, resumeToken string) (<-chan domain.Event, error) {
+	client, err := r.db.NewMongoDBClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	option := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	if resumeToken != "" {
+		token, err := base64.RawURLEncoding.DecodeString(resumeToken)
+		if err != nil {
+			client.Disconnect(context.Background())
+			return nil, err
+		}
+		option.SetResumeAfter(bson.Raw(token))
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"operationType": bson.M{"$in": bson.A{"insert", "update", "replace", "delete"}}}}},
+	}
+	stream, err := client.Database(r.db.DBName).Collection(modelName).Watch(ctx, pipeline, option)
+	if err != nil {
+		client.Disconnect(context.Background())
+		return nil, err
+	}
+
+	events := make(chan domain.Event)
+	go func() {
+		defer close(events)
+		defer client.Disconnect(context.Background())
+		defer stream.Close(context.Background())
+
+		for stream.Next(ctx) {
+			var change changeEvent
+			if err := stream.Decode(&change); err != nil {
+				log.Println(err.Error())
+				return
+			}
+
+			e := domain.Event{
+				ID:         base64.RawURLEncoding.EncodeToString(stream.ResumeToken()),
+				Collection: modelName,
+				OccurredAt: time.Unix(int64(change.ClusterTime.T), 0),
+			}
+			switch change.OperationType {
+			case "insert":
+				e.Type = domain.EventCreate
+			case "update", "replace":
+				e.Type = domain.EventUpdate
+			case "delete":
+				e.Type = domain.EventDelete
+			}
+			if change.FullDocument != nil {
+				delete(change.FullDocument, "_id")
+				e.Key = change.FullDocument[keyName]
+				e.Document = change.FullDocument
+			}
+
+			select {
+			case events <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
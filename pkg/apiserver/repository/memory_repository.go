@@ -0,0 +1,283 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/memory"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// memoryRepository メモリ上のStoreにドキュメントを保存するAPIServerRepository
+// ドキュメントはSQLのストレージと同様にRelaxed Extended JSONを経由して保存し、型を揃えます
+type memoryRepository struct {
+	store *memory.Store
+}
+
+// NewMemoryRepository メモリ上のStoreを使用するAPIServerRepositoryを作成します(開発用)
+func NewMemoryRepository(store *memory.Store) APIServerRepository {
+	return &memoryRepository{
+		store: store,
+	}
+}
+
+// Get APIServerを1件取得します
+func (r *memoryRepository) Get(modelName string, key string, param interface{}) (interface{}, int, error) {
+	documents, err := r.find(modelName, map[string]interface{}{key: param}, 1)
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	if len(documents) == 0 {
+		return "", http.StatusNotFound, errors.New("record not found")
+	}
+	return documents[0], http.StatusOK, nil
+}
+
+// GetList 複数のAPIServerを取得します
+func (r *memoryRepository) GetList(modelName string, key string, param interface{}) (interface{}, int, error) {
+	filter := map[string]interface{}{}
+	if key != "" && param != "" {
+		filter[key] = param
+	}
+
+	documents, err := r.find(modelName, filter, 0)
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	if len(documents) == 0 {
+		return "", http.StatusNotFound, errors.New("record not found")
+	}
+	return documents, http.StatusOK, nil
+}
+
+// Count 条件に一致するドキュメントの件数を取得します
+func (r *memoryRepository) Count(modelName string, filter map[string]interface{}) (int64, int, error) {
+	documents, err := r.find(modelName, filter, 0)
+	if err != nil {
+		return 0, http.StatusInternalServerError, err
+	}
+	return int64(len(documents)), http.StatusOK, nil
+}
+
+// Each 条件に一致するドキュメントを1件ずつfnに渡します
+func (r *memoryRepository) Each(modelName string, filter map[string]interface{}, fn func(document bson.M) error) (int, error) {
+	documents, err := r.find(modelName, filter, 0)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	for _, document := range documents {
+		if err := fn(document); err != nil {
+			return http.StatusInternalServerError, err
+		}
+	}
+	return http.StatusOK, nil
+}
+
+// Create APIServerを追加します
+func (r *memoryRepository) Create(modelName string, keyName string, body []byte) (interface{}, int, error) {
+	document, err := decodeDocument(body)
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	err = r.store.UpdateCollections(func(c memory.Collections) error {
+		c[modelName] = append(c[modelName], document)
+		return nil
+	})
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	return copyDocument(document), http.StatusCreated, nil
+}
+
+// InsertMany 複数のドキュメントを1回で追加します(Keyの重複は検証しません)
+func (r *memoryRepository) InsertMany(modelName string, keyName string, documents []bson.M) (int, error) {
+	if len(documents) == 0 {
+		return http.StatusCreated, nil
+	}
+	copied := make([]bson.M, 0, len(documents))
+	for _, document := range documents {
+		raw, err := bson.MarshalExtJSON(document, false, false)
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+		d, err := decodeDocument(raw)
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+		copied = append(copied, d)
+	}
+	err := r.store.UpdateCollections(func(c memory.Collections) error {
+		c[modelName] = append(c[modelName], copied...)
+		return nil
+	})
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusCreated, nil
+}
+
+// Update APIServerを更新します
+// MongoDBの$setと同様に、ボディに含まれる項目のみを置き換えます
+func (r *memoryRepository) Update(modelName string, keyName string, body []byte) (interface{}, int, error) {
+	var requestBody bson.M
+	if err := bson.UnmarshalExtJSON(body, false, &requestBody); err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	filter, err := encodeFilter(map[string]interface{}{keyName: requestBody[keyName]})
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+
+	err = r.store.UpdateCollections(func(c memory.Collections) error {
+		for _, document := range c[modelName] {
+			if matchDocument(document, filter) {
+				for name, value := range copyDocument(requestBody) {
+					document[name] = value
+				}
+				return nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	return requestBody, http.StatusOK, nil
+}
+
+// Delete APIServerを削除します
+func (r *memoryRepository) Delete(modelName string, key string, param interface{}) (interface{}, int, error) {
+	filter, err := encodeFilter(map[string]interface{}{key: param})
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+
+	err = r.store.UpdateCollections(func(c memory.Collections) error {
+		documents := c[modelName]
+		for i, document := range documents {
+			if matchDocument(document, filter) {
+				c[modelName] = append(documents[:i:i], documents[i+1:]...)
+				return nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	return "", http.StatusNoContent, nil
+}
+
+// RemoveCollection Collectionを削除します
+func (r *memoryRepository) RemoveCollection(modelName string) (interface{}, int, error) {
+	err := r.store.UpdateCollections(func(c memory.Collections) error {
+		delete(c, modelName)
+		return nil
+	})
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	return "", http.StatusNoContent, nil
+}
+
+// RenameCollection Collection名を変更します
+func (r *memoryRepository) RenameCollection(modelName string, newModelName string) (interface{}, int, error) {
+	status := http.StatusNoContent
+	err := r.store.UpdateCollections(func(c memory.Collections) error {
+		documents, ok := c[modelName]
+		if !ok {
+			// まだドキュメントが1件も登録されていない場合はコレクションが存在しない
+			return nil
+		}
+		if _, exists := c[newModelName]; exists {
+			status = http.StatusConflict
+			return errors.New("collection is exists")
+		}
+		c[newModelName] = documents
+		delete(c, modelName)
+		return nil
+	})
+	if err != nil {
+		if status == http.StatusNoContent {
+			status = http.StatusInternalServerError
+		}
+		return "", status, err
+	}
+	return "", http.StatusNoContent, nil
+}
+
+// ReplaceCollection Collection名を変更し、変更先のCollectionを置き換えます
+// 変更元のCollectionが存在しない場合は、変更先のCollectionを削除します(空のCollectionで置き換えます)
+func (r *memoryRepository) ReplaceCollection(modelName string, newModelName string) (int, error) {
+	err := r.store.UpdateCollections(func(c memory.Collections) error {
+		documents, ok := c[modelName]
+		if !ok {
+			delete(c, newModelName)
+			return nil
+		}
+		c[newModelName] = documents
+		delete(c, modelName)
+		return nil
+	})
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusNoContent, nil
+}
+
+// Watch メモリ上のStoreでは変更を監視できないため、常にErrWatchNotSupportedを返却します
+// (プロセス内のイベントで配信されます)
+func (r *memoryRepository) Watch(ctx context.Context, modelName string, keyName string, resumeToken string) (<-chan domain.Event, error) {
+	return nil, ErrWatchNotSupported
+}
+
+// find 条件に一致するドキュメントの複製を登録順に返却します。limitが0の場合はすべてのドキュメントです
+// fnの中からStoreを変更できるよう、ロックを解放してから返却します
+func (r *memoryRepository) find(modelName string, filter map[string]interface{}, limit int) ([]bson.M, error) {
+	encoded, err := encodeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	documents := []bson.M{}
+	r.store.ViewCollections(func(c memory.Collections) {
+		for _, document := range c[modelName] {
+			if !matchDocument(document, encoded) {
+				continue
+			}
+			documents = append(documents, copyDocument(document))
+			if limit > 0 && len(documents) >= limit {
+				return
+			}
+		}
+	})
+	return documents, nil
+}
+
+// encodeFilter 条件の値をJSONにします(matchDocumentの条件)
+func encodeFilter(filter map[string]interface{}) (map[string][]byte, error) {
+	encoded := map[string][]byte{}
+	for name, value := range filter {
+		raw, err := encodeValue(value)
+		if err != nil {
+			return nil, err
+		}
+		encoded[name] = raw
+	}
+	return encoded, nil
+}
+
+// copyDocument ドキュメントを複製します(返却したドキュメントを変更しても、保存したドキュメントは変更されません)
+func copyDocument(document bson.M) bson.M {
+	raw, err := bson.MarshalExtJSON(document, true, false)
+	if err != nil {
+		return document
+	}
+	var copied bson.M
+	if err := bson.UnmarshalExtJSON(raw, true, &copied); err != nil {
+		return document
+	}
+	return copied
+}
@@ -0,0 +1,343 @@
+// Package repositorytest APIServerRepositoryの実装が共通して満たすべき動作を検証するテスト
+package repositorytest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/repository"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Run APIServerRepositoryの実装を検証します
+// コレクションはテストごとに採番した名前で作成し、終了後に削除します
+func Run(t *testing.T, repo repository.APIServerRepository) {
+	t.Run("Create", func(t *testing.T) { testCreate(t, repo) })
+	t.Run("Get", func(t *testing.T) { testGet(t, repo) })
+	t.Run("GetList", func(t *testing.T) { testGetList(t, repo) })
+	t.Run("Update", func(t *testing.T) { testUpdate(t, repo) })
+	t.Run("UpdateByKey", func(t *testing.T) { testUpdateByKey(t, repo) })
+	t.Run("Delete", func(t *testing.T) { testDelete(t, repo) })
+	t.Run("CountEach", func(t *testing.T) { testCountEach(t, repo) })
+	t.Run("InsertMany", func(t *testing.T) { testInsertMany(t, repo) })
+	t.Run("RenameCollection", func(t *testing.T) { testRenameCollection(t, repo) })
+	t.Run("ReplaceCollection", func(t *testing.T) { testReplaceCollection(t, repo) })
+	t.Run("RemoveCollection", func(t *testing.T) { testRemoveCollection(t, repo) })
+}
+
+// newCollection テスト用のコレクション名を作成し、終了後に削除します
+func newCollection(t *testing.T, repo repository.APIServerRepository) string {
+	name := "test_" + strings.Replace(uuid.New().String(), "-", "", -1)
+	t.Cleanup(func() { repo.RemoveCollection(name) })
+	return name
+}
+
+// create ドキュメントを追加します
+func create(t *testing.T, repo repository.APIServerRepository, collection string, documents ...string) {
+	for _, document := range documents {
+		_, status, err := repo.Create(collection, "id", []byte(document))
+		if !assert.NoError(t, err) || !assert.Equal(t, http.StatusCreated, status) {
+			t.FailNow()
+		}
+	}
+}
+
+// assertJSON ドキュメントをJSONにして比較します(数値の型の違いは比較しません)
+func assertJSON(t *testing.T, expected string, actual interface{}) {
+	b, err := json.Marshal(actual)
+	assert.NoError(t, err)
+	assert.JSONEq(t, expected, string(b))
+}
+
+func testCreate(t *testing.T, repo repository.APIServerRepository) {
+	collection := newCollection(t, repo)
+	document := `{"id": 1, "name": "foo", "price": 1.5, "active": true, "memo": null, "tags": ["a", "b"], "address": {"city": "Tokyo"}}`
+
+	result, status, err := repo.Create(collection, "id", []byte(document))
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, status)
+	assertJSON(t, document, result)
+
+	// 型を含めて保存されている
+	got, _, err := repo.Get(collection, "id", 1)
+	assert.NoError(t, err)
+	assertJSON(t, document, got)
+	assert.Equal(t, int32(1), got.(bson.M)["id"])
+	assert.Equal(t, 1.5, got.(bson.M)["price"])
+	assert.Equal(t, true, got.(bson.M)["active"])
+}
+
+func testGet(t *testing.T, repo repository.APIServerRepository) {
+	collection := newCollection(t, repo)
+
+	t.Run("コレクションが存在しない", func(t *testing.T) {
+		_, status, err := repo.Get(collection, "id", 1)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+
+	create(t, repo, collection, `{"id": 1, "name": "foo"}`, `{"id": 2, "name": "bar"}`, `{"id": "3", "name": "baz"}`)
+
+	t.Run("Key", func(t *testing.T) {
+		result, status, err := repo.Get(collection, "id", 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assertJSON(t, `{"id": 2, "name": "bar"}`, result)
+	})
+	t.Run("Key以外の項目", func(t *testing.T) {
+		result, _, err := repo.Get(collection, "name", "foo")
+
+		assert.NoError(t, err)
+		assertJSON(t, `{"id": 1, "name": "foo"}`, result)
+	})
+	t.Run("数値の型が異なる", func(t *testing.T) {
+		result, _, err := repo.Get(collection, "id", int64(1))
+
+		assert.NoError(t, err)
+		assertJSON(t, `{"id": 1, "name": "foo"}`, result)
+	})
+	t.Run("文字列と数値は区別する", func(t *testing.T) {
+		result, _, err := repo.Get(collection, "id", "3")
+		assert.NoError(t, err)
+		assertJSON(t, `{"id": "3", "name": "baz"}`, result)
+
+		_, status, err := repo.Get(collection, "id", 3)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+}
+
+func testGetList(t *testing.T, repo repository.APIServerRepository) {
+	collection := newCollection(t, repo)
+
+	_, status, err := repo.GetList(collection, "", "")
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	create(t, repo, collection, `{"id": 1, "group": "a"}`, `{"id": 2, "group": "b"}`, `{"id": 3, "group": "a"}`)
+
+	t.Run("すべて", func(t *testing.T) {
+		result, status, err := repo.GetList(collection, "", "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assertJSON(t, `[{"id": 1, "group": "a"}, {"id": 2, "group": "b"}, {"id": 3, "group": "a"}]`, result)
+	})
+	t.Run("条件", func(t *testing.T) {
+		result, _, err := repo.GetList(collection, "group", "a")
+
+		assert.NoError(t, err)
+		assertJSON(t, `[{"id": 1, "group": "a"}, {"id": 3, "group": "a"}]`, result)
+	})
+	t.Run("一致しない", func(t *testing.T) {
+		_, status, err := repo.GetList(collection, "group", "c")
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+}
+
+func testUpdate(t *testing.T, repo repository.APIServerRepository) {
+	collection := newCollection(t, repo)
+	create(t, repo, collection, `{"id": 1, "name": "foo", "age": 20}`, `{"id": 2, "name": "bar"}`)
+
+	result, status, err := repo.Update(collection, "id", []byte(`{"id": 1, "age": 21, "tags": ["x"]}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assertJSON(t, `{"id": 1, "age": 21, "tags": ["x"]}`, result)
+
+	// ボディに含まれない項目は変更しない
+	got, _, _ := repo.Get(collection, "id", 1)
+	assertJSON(t, `{"id": 1, "name": "foo", "age": 21, "tags": ["x"]}`, got)
+	got, _, _ = repo.Get(collection, "id", 2)
+	assertJSON(t, `{"id": 2, "name": "bar"}`, got)
+}
+
+// testUpdateByKey Keyが"id"以外のModelはKeyの値で更新対象を探す
+func testUpdateByKey(t *testing.T, repo repository.APIServerRepository) {
+	collection := newCollection(t, repo)
+	for _, document := range []string{`{"id": 1, "code": "a", "name": "foo"}`, `{"id": 2, "code": "b", "name": "bar"}`} {
+		_, status, err := repo.Create(collection, "code", []byte(document))
+		if !assert.NoError(t, err) || !assert.Equal(t, http.StatusCreated, status) {
+			t.FailNow()
+		}
+	}
+
+	_, status, err := repo.Update(collection, "code", []byte(`{"code": "b", "name": "baz"}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	got, _, _ := repo.Get(collection, "code", "a")
+	assertJSON(t, `{"id": 1, "code": "a", "name": "foo"}`, got)
+	got, _, _ = repo.Get(collection, "code", "b")
+	assertJSON(t, `{"id": 2, "code": "b", "name": "baz"}`, got)
+}
+
+func testDelete(t *testing.T, repo repository.APIServerRepository) {
+	collection := newCollection(t, repo)
+	create(t, repo, collection, `{"id": 1}`, `{"id": 2}`)
+
+	_, status, err := repo.Delete(collection, "id", 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, status)
+	_, status, _ = repo.Get(collection, "id", 1)
+	assert.Equal(t, http.StatusNotFound, status)
+	_, status, _ = repo.Get(collection, "id", 2)
+	assert.Equal(t, http.StatusOK, status)
+
+	// 存在しないドキュメント
+	_, status, err = repo.Delete(collection, "id", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, status)
+}
+
+func testCountEach(t *testing.T, repo repository.APIServerRepository) {
+	collection := newCollection(t, repo)
+
+	count, _, err := repo.Count(collection, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	create(t, repo, collection, `{"id": 1, "age": 20}`, `{"id": 2, "age": 30}`, `{"id": 3, "age": 20}`)
+
+	count, status, err := repo.Count(collection, map[string]interface{}{"age": int64(20)})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, int64(2), count)
+
+	ids := []interface{}{}
+	status, err = repo.Each(collection, nil, func(document bson.M) error {
+		_, hasID := document["_id"]
+		assert.False(t, hasID)
+		ids = append(ids, document["id"])
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []interface{}{int32(1), int32(2), int32(3)}, ids)
+}
+
+func testInsertMany(t *testing.T, repo repository.APIServerRepository) {
+	collection := newCollection(t, repo)
+	documents := []bson.M{}
+	for i := 1; i <= 10; i++ {
+		documents = append(documents, bson.M{"id": int32(i), "even": i%2 == 0})
+	}
+
+	status, err := repo.InsertMany(collection, "id", documents)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, status)
+	count, _, _ := repo.Count(collection, map[string]interface{}{"even": true})
+	assert.Equal(t, int64(5), count)
+	got, _, _ := repo.Get(collection, "id", 10)
+	assertJSON(t, `{"id": 10, "even": true}`, got)
+
+	status, err = repo.InsertMany(collection, "id", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, status)
+}
+
+func testRenameCollection(t *testing.T, repo repository.APIServerRepository) {
+	collection := newCollection(t, repo)
+	renamed := newCollection(t, repo)
+	create(t, repo, collection, `{"id": 1}`)
+
+	_, status, err := repo.RenameCollection(collection, renamed)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, status)
+	_, status, _ = repo.Get(renamed, "id", 1)
+	assert.Equal(t, http.StatusOK, status)
+	_, status, _ = repo.Get(collection, "id", 1)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	t.Run("コレクションが存在しない", func(t *testing.T) {
+		_, status, err := repo.RenameCollection(newCollection(t, repo), newCollection(t, repo))
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, status)
+	})
+	t.Run("変更先が存在する", func(t *testing.T) {
+		other := newCollection(t, repo)
+		create(t, repo, other, `{"id": 2}`)
+
+		_, status, err := repo.RenameCollection(other, renamed)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusConflict, status)
+	})
+}
+
+func testReplaceCollection(t *testing.T, repo repository.APIServerRepository) {
+	t.Run("変更先を置き換える", func(t *testing.T) {
+		collection := newCollection(t, repo)
+		target := newCollection(t, repo)
+		create(t, repo, collection, `{"id": 1}`)
+		create(t, repo, target, `{"id": 2}`, `{"id": 3}`)
+
+		status, err := repo.ReplaceCollection(collection, target)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, status)
+		_, status, _ = repo.Get(target, "id", 1)
+		assert.Equal(t, http.StatusOK, status)
+		count, _, _ := repo.Count(target, nil)
+		assert.Equal(t, int64(1), count)
+		count, _, _ = repo.Count(collection, nil)
+		assert.Equal(t, int64(0), count)
+
+		// 置き換えた後も追加できる
+		create(t, repo, target, `{"id": 4}`)
+	})
+	t.Run("変更先が存在しない", func(t *testing.T) {
+		collection := newCollection(t, repo)
+		target := newCollection(t, repo)
+		create(t, repo, collection, `{"id": 1}`)
+
+		status, err := repo.ReplaceCollection(collection, target)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, status)
+		_, status, _ = repo.Get(target, "id", 1)
+		assert.Equal(t, http.StatusOK, status)
+	})
+	t.Run("変更元が存在しない場合は空にする", func(t *testing.T) {
+		target := newCollection(t, repo)
+		create(t, repo, target, `{"id": 1}`)
+
+		status, err := repo.ReplaceCollection(newCollection(t, repo), target)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, status)
+		count, _, _ := repo.Count(target, nil)
+		assert.Equal(t, int64(0), count)
+	})
+}
+
+func testRemoveCollection(t *testing.T, repo repository.APIServerRepository) {
+	collection := newCollection(t, repo)
+	create(t, repo, collection, `{"id": 1}`)
+
+	_, status, err := repo.RemoveCollection(collection)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, status)
+	count, _, _ := repo.Count(collection, nil)
+	assert.Equal(t, int64(0), count)
+
+	// 再度追加できる
+	create(t, repo, collection, `{"id": 2}`)
+	count, _, _ = repo.Count(collection, nil)
+	assert.Equal(t, int64(1), count)
+}
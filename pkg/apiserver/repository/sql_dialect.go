@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// dialect データベースごとに異なるSQL
+type dialect interface {
+	// quote 識別子をクォートします
+	quote(name string) string
+	// placeholder n番目(1から)のパラメータのプレースホルダ
+	placeholder(n int) string
+	// createTable ドキュメントを保存するテーブルを作成するSQL
+	createTable(table string) []string
+	// renameTable テーブル名を変更するSQL
+	renameTable(from string, to string) string
+	// replaceTable fromのテーブルでtoのテーブルを置き換えるSQL(置き換え前のtoはoldに変更してから削除します)
+	replaceTable(from string, to string, old string) []string
+	// equal ドキュメントのkeyの値が、value(JSON)と等しい条件を返却します
+	// SQLで比較できない場合はfalseを返却し、取得後に絞り込みます
+	equal(key string, value []byte, n int) (string, []interface{}, bool)
+}
+
+// postgresDialect PostgreSQL(ドキュメントはJSONB)
+type postgresDialect struct{}
+
+func (postgresDialect) quote(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+func (postgresDialect) placeholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+func (d postgresDialect) createTable(table string) []string {
+	return []string{
+		"CREATE TABLE IF NOT EXISTS " + d.quote(table) + " (seq BIGSERIAL PRIMARY KEY, doc_key TEXT, document JSONB NOT NULL)",
+		"CREATE INDEX IF NOT EXISTS " + d.quote(indexName()) + " ON " + d.quote(table) + " (doc_key)",
+	}
+}
+
+func (d postgresDialect) renameTable(from string, to string) string {
+	return "ALTER TABLE " + d.quote(from) + " RENAME TO " + d.quote(to)
+}
+
+func (d postgresDialect) replaceTable(from string, to string, old string) []string {
+	// PostgreSQLはDDLもトランザクション内で実行できる
+	return []string{d.renameTable(to, old), d.renameTable(from, to), "DROP TABLE " + d.quote(old)}
+}
+
+func (d postgresDialect) equal(key string, value []byte, n int) (string, []interface{}, bool) {
+	// JSONBの比較は型を区別し、数値は1と1.0を等しいとみなす(MongoDBと同じ)
+	return fmt.Sprintf("document -> %s::text = %s::jsonb", d.placeholder(n), d.placeholder(n+1)), []interface{}{key, string(value)}, true
+}
+
+// mysqlDialect MySQL(ドキュメントはJSON)
+type mysqlDialect struct{}
+
+func (mysqlDialect) quote(name string) string {
+	return "`" + strings.Replace(name, "`", "``", -1) + "`"
+}
+
+func (mysqlDialect) placeholder(n int) string {
+	return "?"
+}
+
+func (d mysqlDialect) createTable(table string) []string {
+	return []string{
+		"CREATE TABLE IF NOT EXISTS " + d.quote(table) + " (seq BIGINT AUTO_INCREMENT PRIMARY KEY, doc_key TEXT, document JSON NOT NULL, INDEX (doc_key(191)))",
+	}
+}
+
+func (d mysqlDialect) renameTable(from string, to string) string {
+	return "RENAME TABLE " + d.quote(from) + " TO " + d.quote(to)
+}
+
+func (d mysqlDialect) replaceTable(from string, to string, old string) []string {
+	// MySQLはDDLをトランザクション内で実行できないが、1回のRENAME TABLEで複数のテーブル名をまとめて変更できる
+	return []string{
+		"RENAME TABLE " + d.quote(to) + " TO " + d.quote(old) + ", " + d.quote(from) + " TO " + d.quote(to),
+		"DROP TABLE " + d.quote(old),
+	}
+}
+
+func (d mysqlDialect) equal(key string, value []byte, n int) (string, []interface{}, bool) {
+	path := `$."` + strings.Replace(strings.Replace(key, `\`, `\\`, -1), `"`, `\"`, -1) + `"`
+	return "JSON_EXTRACT(document, ?) = CAST(? AS JSON)", []interface{}{path, string(value)}, true
+}
+
+// sqliteDialect SQLite(ドキュメントはTEXT)
+// JSON1拡張はビルドタグがないと利用できないため、条件は取得後に絞り込みます
+type sqliteDialect struct{}
+
+func (sqliteDialect) quote(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+func (sqliteDialect) placeholder(n int) string {
+	return "?"
+}
+
+func (d sqliteDialect) createTable(table string) []string {
+	return []string{
+		"CREATE TABLE IF NOT EXISTS " + d.quote(table) + " (seq INTEGER PRIMARY KEY AUTOINCREMENT, doc_key TEXT, document TEXT NOT NULL)",
+		"CREATE INDEX IF NOT EXISTS " + d.quote(indexName()) + " ON " + d.quote(table) + " (doc_key)",
+	}
+}
+
+func (d sqliteDialect) renameTable(from string, to string) string {
+	return "ALTER TABLE " + d.quote(from) + " RENAME TO " + d.quote(to)
+}
+
+func (d sqliteDialect) replaceTable(from string, to string, old string) []string {
+	// SQLiteはDDLもトランザクション内で実行できる
+	return []string{d.renameTable(to, old), d.renameTable(from, to), "DROP TABLE " + d.quote(old)}
+}
+
+func (sqliteDialect) equal(key string, value []byte, n int) (string, []interface{}, bool) {
+	return "", nil, false
+}
+
+// indexName doc_keyのインデックス名を作成します
+// インデックス名はデータベース内で一意である必要があり、テーブル名の変更にも追従しないため採番します
+func indexName() string {
+	return "doc_key_" + strings.Replace(uuid.New().String(), "-", "", -1)
+}
+
+// shortID 一時的なテーブル名に付ける、採番した短い識別子を作成します
+// (テーブル名の長さの上限を超えないよう、UUIDの先頭のみを使用します)
+func shortID() string {
+	return strings.Replace(uuid.New().String(), "-", "", -1)[:8]
+}
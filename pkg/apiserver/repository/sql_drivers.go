@@ -0,0 +1,7 @@
+package repository
+
+import (
+	// PostgreSQL、MySQLのドライバ
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+)
@@ -0,0 +1,9 @@
+//go:build cgo
+// +build cgo
+
+package repository
+
+import (
+	// SQLiteのドライバはcgoが必要なため、cgoが無効な場合はstorage.driverに"sqlite"を指定できない
+	_ "github.com/mattn/go-sqlite3"
+)
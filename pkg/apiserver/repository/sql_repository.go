@@ -0,0 +1,466 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// tablePrefix コレクションのテーブル名の接頭辞(管理画面のテーブルと同じデータベースを使用しても衝突しないようにする)
+const tablePrefix = "documents_"
+
+// ErrWatchNotSupported "watch is not supported by this storage"
+var ErrWatchNotSupported = errors.New("watch is not supported by this storage")
+
+// sqlRepository コレクションを1つのテーブルとして、ドキュメントをJSONで保存するAPIServerRepository
+// テーブルはseq(登録順)、doc_key(Keyの値のJSON)、document(ドキュメントのJSON)の列を持ちます
+type sqlRepository struct {
+	db      *sql.DB
+	dialect dialect
+}
+
+// NewSQLRepository SQLのデータベースにドキュメントを保存するAPIServerRepositoryを作成します
+// driverは"postgres"、"mysql"、"sqlite3"のいずれかです
+func NewSQLRepository(driver string, dsn string) (APIServerRepository, error) {
+	var d dialect
+	switch driver {
+	case "postgres":
+		d = postgresDialect{}
+	case "mysql":
+		d = mysqlDialect{}
+	case "sqlite3":
+		d = sqliteDialect{}
+	default:
+		return nil, fmt.Errorf("unsupported storage driver %s", driver)
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if driver == "sqlite3" {
+		// SQLiteは書き込みを同時に1つしか実行できないため、接続を1つにする
+		db.SetMaxOpenConns(1)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &sqlRepository{db: db, dialect: d}, nil
+}
+
+// Get APIServerを1件取得します
+func (r *sqlRepository) Get(modelName string, key string, param interface{}) (interface{}, int, error) {
+	var response bson.M
+	err := r.find(modelName, map[string]interface{}{key: param}, 1, func(seq int64, document bson.M) error {
+		response = document
+		return nil
+	})
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	if response == nil {
+		return "", http.StatusNotFound, errors.New("record not found")
+	}
+	return response, http.StatusOK, nil
+}
+
+// GetList 複数のAPIServerを取得します
+func (r *sqlRepository) GetList(modelName string, key string, param interface{}) (interface{}, int, error) {
+	filter := map[string]interface{}{}
+	if key != "" && param != "" {
+		filter[key] = param
+	}
+
+	var response []bson.M
+	err := r.find(modelName, filter, 0, func(seq int64, document bson.M) error {
+		response = append(response, document)
+		return nil
+	})
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	if response == nil {
+		return "", http.StatusNotFound, errors.New("record not found")
+	}
+	return response, http.StatusOK, nil
+}
+
+// Count 条件に一致するドキュメントの件数を取得します
+func (r *sqlRepository) Count(modelName string, filter map[string]interface{}) (int64, int, error) {
+	var count int64
+	err := r.find(modelName, filter, 0, func(seq int64, document bson.M) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		return 0, http.StatusInternalServerError, err
+	}
+	return count, http.StatusOK, nil
+}
+
+// Each 条件に一致するドキュメントを1件ずつfnに渡します(すべてのドキュメントをメモリに読み込みません)
+func (r *sqlRepository) Each(modelName string, filter map[string]interface{}, fn func(document bson.M) error) (int, error) {
+	err := r.find(modelName, filter, 0, func(seq int64, document bson.M) error {
+		return fn(document)
+	})
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+// Create APIServerを追加します
+func (r *sqlRepository) Create(modelName string, keyName string, body []byte) (interface{}, int, error) {
+	var b bson.M
+	if err := bson.UnmarshalExtJSON(body, false, &b); err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	if err := r.insert(modelName, keyName, []bson.M{b}); err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	return b, http.StatusCreated, nil
+}
+
+// InsertMany 複数のドキュメントを1回で追加します(Keyの重複は検証しません)
+func (r *sqlRepository) InsertMany(modelName string, keyName string, documents []bson.M) (int, error) {
+	if len(documents) == 0 {
+		return http.StatusCreated, nil
+	}
+	if err := r.insert(modelName, keyName, documents); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusCreated, nil
+}
+
+// Update APIServerを更新します
+// MongoDBの$setと同様に、ボディに含まれる項目のみを置き換えます
+func (r *sqlRepository) Update(modelName string, keyName string, body []byte) (interface{}, int, error) {
+	var requestBody bson.M
+	if err := bson.UnmarshalExtJSON(body, false, &requestBody); err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+
+	var seq int64
+	var document bson.M
+	err := r.find(modelName, map[string]interface{}{keyName: requestBody[keyName]}, 1, func(s int64, d bson.M) error {
+		seq, document = s, d
+		return nil
+	})
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	if document == nil {
+		return requestBody, http.StatusOK, nil
+	}
+
+	for name, value := range requestBody {
+		document[name] = value
+	}
+	raw, docKey, err := encodeDocument(document, keyName)
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	query := fmt.Sprintf("UPDATE %s SET document = %s, doc_key = %s WHERE seq = %s",
+		r.dialect.quote(tableName(modelName)), r.dialect.placeholder(1), r.dialect.placeholder(2), r.dialect.placeholder(3))
+	if _, err := r.db.Exec(query, raw, docKey, seq); err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	return requestBody, http.StatusOK, nil
+}
+
+// Delete APIServerを削除します
+func (r *sqlRepository) Delete(modelName string, key string, param interface{}) (interface{}, int, error) {
+	var seq int64
+	found := false
+	err := r.find(modelName, map[string]interface{}{key: param}, 1, func(s int64, document bson.M) error {
+		seq, found = s, true
+		return nil
+	})
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	if !found {
+		return "", http.StatusNoContent, nil
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE seq = %s", r.dialect.quote(tableName(modelName)), r.dialect.placeholder(1))
+	if _, err := r.db.Exec(query, seq); err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	return "", http.StatusNoContent, nil
+}
+
+// RemoveCollection Collectionを削除します
+func (r *sqlRepository) RemoveCollection(modelName string) (interface{}, int, error) {
+	table := tableName(modelName)
+	if _, err := r.db.Exec("DROP TABLE IF EXISTS " + r.dialect.quote(table)); err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	return "", http.StatusNoContent, nil
+}
+
+// RenameCollection Collection名を変更します
+func (r *sqlRepository) RenameCollection(modelName string, newModelName string) (interface{}, int, error) {
+	from, to := tableName(modelName), tableName(newModelName)
+	if !r.exists(from) {
+		// まだドキュメントが1件も登録されていない場合はテーブルが存在しない
+		return "", http.StatusNoContent, nil
+	}
+	if r.exists(to) {
+		return "", http.StatusConflict, errors.New("collection is exists")
+	}
+
+	if _, err := r.db.Exec(r.dialect.renameTable(from, to)); err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	return "", http.StatusNoContent, nil
+}
+
+// ReplaceCollection Collection名を変更し、変更先のCollectionを置き換えます
+// 変更元のCollectionが存在しない場合は、変更先のCollectionを削除します(空のCollectionで置き換えます)
+func (r *sqlRepository) ReplaceCollection(modelName string, newModelName string) (int, error) {
+	from, to := tableName(modelName), tableName(newModelName)
+	if !r.exists(from) {
+		if _, status, err := r.RemoveCollection(newModelName); err != nil {
+			return status, err
+		}
+		return http.StatusNoContent, nil
+	}
+	if !r.exists(to) {
+		if _, err := r.db.Exec(r.dialect.renameTable(from, to)); err != nil {
+			return http.StatusInternalServerError, err
+		}
+		return http.StatusNoContent, nil
+	}
+
+	// 置き換える間も、変更先のテーブルが存在しない状態にならないようにする
+	tx, err := r.db.Begin()
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	for _, statement := range r.dialect.replaceTable(from, to, to+"_old_"+shortID()) {
+		if _, err := tx.Exec(statement); err != nil {
+			tx.Rollback()
+			return http.StatusInternalServerError, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusNoContent, nil
+}
+
+// Watch SQLのデータベースでは変更を監視できないため、常にErrWatchNotSupportedを返却します
+// (プロセス内のイベントで配信されます)
+func (r *sqlRepository) Watch(ctx context.Context, modelName string, keyName string, resumeToken string) (<-chan domain.Event, error) {
+	return nil, ErrWatchNotSupported
+}
+
+// find 条件に一致するドキュメントを登録順にfnに渡します。limitが0の場合はすべてのドキュメントです
+// テーブルが存在しない場合は、ドキュメントが存在しないものとします
+func (r *sqlRepository) find(modelName string, filter map[string]interface{}, limit int, fn func(seq int64, document bson.M) error) error {
+	table := tableName(modelName)
+	if !r.exists(table) {
+		return nil
+	}
+
+	// SQLで比較できない条件は、取得後に絞り込む
+	conditions := []string{}
+	args := []interface{}{}
+	rest := map[string][]byte{}
+	names := make([]string, 0, len(filter))
+	for name := range filter {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value, err := encodeValue(filter[name])
+		if err != nil {
+			return err
+		}
+		condition, conditionArgs, ok := r.dialect.equal(name, value, len(args)+1)
+		if !ok {
+			rest[name] = value
+			continue
+		}
+		conditions = append(conditions, condition)
+		args = append(args, conditionArgs...)
+	}
+
+	query := "SELECT seq, document FROM " + r.dialect.quote(table)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY seq"
+	if limit > 0 && len(rest) == 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var seq int64
+		var raw []byte
+		if err := rows.Scan(&seq, &raw); err != nil {
+			return err
+		}
+		document, err := decodeDocument(raw)
+		if err != nil {
+			return err
+		}
+		if !matchDocument(document, rest) {
+			continue
+		}
+		if err := fn(seq, document); err != nil {
+			return err
+		}
+		count++
+		if limit > 0 && count >= limit {
+			break
+		}
+	}
+	return rows.Err()
+}
+
+// insert ドキュメントを1つのトランザクションで追加します
+func (r *sqlRepository) insert(modelName string, keyName string, documents []bson.M) error {
+	table := tableName(modelName)
+	if err := r.ensureTable(table); err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("INSERT INTO %s (doc_key, document) VALUES (%s, %s)",
+		r.dialect.quote(table), r.dialect.placeholder(1), r.dialect.placeholder(2))
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, document := range documents {
+		raw, docKey, err := encodeDocument(document, keyName)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if _, err := stmt.Exec(docKey, raw); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// exists テーブルが存在するか確認します
+// 管理画面とAPIサーバーが同じテーブルを変更するため、結果はキャッシュしません
+func (r *sqlRepository) exists(table string) bool {
+	rows, err := r.db.Query("SELECT 1 FROM " + r.dialect.quote(table) + " WHERE 1 = 0")
+	if err != nil {
+		return false
+	}
+	rows.Close()
+	return true
+}
+
+// ensureTable テーブルが存在しない場合は作成します
+func (r *sqlRepository) ensureTable(table string) error {
+	if r.exists(table) {
+		return nil
+	}
+	for _, statement := range r.dialect.createTable(table) {
+		if _, err := r.db.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tableName コレクション名からテーブル名を作成します
+func tableName(modelName string) string {
+	return tablePrefix + modelName
+}
+
+// encodeDocument ドキュメントをRelaxed Extended JSONにし、Keyの値(JSON)と共に返却します
+// Keyが存在しない場合、Keyの値はNULLです
+func encodeDocument(document bson.M, keyName string) (string, interface{}, error) {
+	raw, err := bson.MarshalExtJSON(document, false, false)
+	if err != nil {
+		return "", nil, err
+	}
+	value, ok := document[keyName]
+	if !ok || keyName == "" {
+		return string(raw), nil, nil
+	}
+	docKey, err := encodeValue(value)
+	if err != nil {
+		return "", nil, err
+	}
+	return string(raw), string(docKey), nil
+}
+
+// decodeDocument 保存したJSONをドキュメントにします(MongoDBと同様に_idは除外します)
+func decodeDocument(raw []byte) (bson.M, error) {
+	var document bson.M
+	if err := bson.UnmarshalExtJSON(raw, false, &document); err != nil {
+		return nil, err
+	}
+	delete(document, "_id")
+	return document, nil
+}
+
+// encodeValue 値をドキュメントと同じ形式(Relaxed Extended JSON)にします
+func encodeValue(value interface{}) ([]byte, error) {
+	raw, err := bson.MarshalExtJSON(bson.M{"v": value}, false, false)
+	if err != nil {
+		return nil, err
+	}
+	var wrapper map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &wrapper); err != nil {
+		return nil, err
+	}
+	return wrapper["v"], nil
+}
+
+// matchDocument ドキュメントが条件(値はJSON)をすべて満たすか確認します
+func matchDocument(document bson.M, filter map[string][]byte) bool {
+	for name, expected := range filter {
+		value, ok := document[name]
+		if !ok {
+			return false
+		}
+		actual, err := encodeValue(value)
+		if err != nil || !jsonEqual(actual, expected) {
+			return false
+		}
+	}
+	return true
+}
+
+// jsonEqual JSONの値が等しいか比較します。数値は1と1.0を等しいとみなします(MongoDBと同じ)
+func jsonEqual(a []byte, b []byte) bool {
+	var x, y interface{}
+	if json.Unmarshal(a, &x) != nil || json.Unmarshal(b, &y) != nil {
+		return false
+	}
+	return reflect.DeepEqual(x, y)
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/config"
+	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/database"
+)
+
+// ドキュメントの保存先(apiserver.config.jsonのstorage.driver)
+const (
+	// StorageMongoDB MongoDB(既定)
+	StorageMongoDB = "mongodb"
+	// StoragePostgres PostgreSQL(JSONB)
+	StoragePostgres = "postgres"
+	// StorageMySQL MySQL(JSON)
+	StorageMySQL = "mysql"
+	// StorageSQLite SQLite(単一のバイナリで動作させる場合の組み込みの保存先)
+	StorageSQLite = "sqlite"
+)
+
+// NewAPIServerRepositoryFromConfig 設定のstorage.driverに応じたAPIServerRepositoryを作成します
+// MongoDBの場合はdatabaseの接続情報、それ以外の場合はstorage.dsnを使用します
+func NewAPIServerRepositoryFromConfig(c *config.Config) APIServerRepository {
+	var driver string
+	switch c.Storage.Driver {
+	case "", StorageMongoDB:
+		return NewAPIServerRepository(database.NewDB(c))
+	case StorageSQLite:
+		driver = "sqlite3"
+	default:
+		driver = c.Storage.Driver
+	}
+
+	repository, err := NewSQLRepository(driver, c.Storage.DSN)
+	if err != nil {
+		panic(err.Error())
+	}
+	return repository
+}
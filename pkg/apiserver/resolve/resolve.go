@@ -0,0 +1,34 @@
+// Package resolve リクエストされたAPIとMethodを1回だけ取得し、ミドルウェアとusecaseで共有します
+package resolve
+
+import (
+	"strings"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// Resolver リクエストされたAPIとMethodを取得します
+type Resolver interface {
+	ResolveMethod(httpMethod string, url string) (domain.API, domain.Method, error)
+}
+
+// Method リクエストされたAPIとMethodを返却します(Methodが見つからない場合はゼロ値)
+// 最初の呼び出しで取得した結果をリクエストのcontextに保持し、以降はそれを返却します
+func Method(c *gin.Context, resolver Resolver) (domain.API, domain.Method, error) {
+	httpMethod := c.Request.Method
+	url := strings.TrimPrefix(c.Request.URL.Path, "/")
+	if resolved, ok := domain.ResolvedMethodFromContext(c.Request.Context(), httpMethod, url); ok {
+		return resolved.API, resolved.Method, resolved.Err
+	}
+
+	api, method, err := resolver.ResolveMethod(httpMethod, url)
+	c.Request = c.Request.WithContext(domain.WithResolvedMethod(c.Request.Context(), domain.ResolvedMethod{
+		HTTPMethod: httpMethod,
+		URL:        url,
+		API:        api,
+		Method:     method,
+		Err:        err,
+	}))
+	return api, method, err
+}
@@ -0,0 +1,67 @@
+package resolve_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/resolve"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+	"github.com/gin-gonic/gin"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMethod(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockAPI := domain.API{ID: "api-1", URL: "users"}
+	mockMethod := domain.Method{ID: "method-1", APIID: "api-1", Type: "GET"}
+
+	t.Run("1つのリクエストでは1回だけ取得する", func(t *testing.T) {
+		resolver := new(mocks.APIServerUsecase)
+		resolver.On("ResolveMethod", "GET", "users").Return(mockAPI, mockMethod, nil).Once()
+		var resolved domain.ResolvedMethod
+		router := gin.New()
+		router.Use(func(c *gin.Context) { resolve.Method(c, resolver) })
+		router.GET("/*path", func(c *gin.Context) {
+			api, method, err := resolve.Method(c, resolver)
+			assert.NoError(t, err)
+			assert.Equal(t, mockAPI, api)
+			assert.Equal(t, mockMethod, method)
+			resolved, _ = domain.ResolvedMethodFromContext(c.Request.Context(), "GET", "users")
+			c.Status(http.StatusOK)
+		})
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, httptest.NewRequest("GET", "/users", nil))
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, mockAPI, resolved.API)
+		resolver.AssertNumberOfCalls(t, "ResolveMethod", 1)
+	})
+	t.Run("APIが見つからない場合もエラーを保持する", func(t *testing.T) {
+		resolver := new(mocks.APIServerUsecase)
+		resolver.On("ResolveMethod", "GET", "other").Return(domain.API{}, domain.Method{}, errors.New("api not found")).Once()
+		router := gin.New()
+		router.Use(func(c *gin.Context) { resolve.Method(c, resolver) })
+		router.GET("/*path", func(c *gin.Context) {
+			_, _, err := resolve.Method(c, resolver)
+			assert.Error(t, err)
+			c.Status(http.StatusOK)
+		})
+
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/other", nil))
+
+		resolver.AssertNumberOfCalls(t, "ResolveMethod", 1)
+	})
+	t.Run("別のHTTPメソッド、URLの結果は使用しない", func(t *testing.T) {
+		ctx := domain.WithResolvedMethod(httptest.NewRequest("GET", "/users", nil).Context(), domain.ResolvedMethod{HTTPMethod: "GET", URL: "users", API: mockAPI})
+
+		_, ok := domain.ResolvedMethodFromContext(ctx, "POST", "users")
+		assert.False(t, ok)
+		_, ok = domain.ResolvedMethodFromContext(ctx, "GET", "users/1")
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,371 @@
+package script
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+const (
+	// defaultTimeout スクリプトの実行時間の上限(未指定の場合)
+	defaultTimeout = 1000 * time.Millisecond
+	// defaultMaxSize スクリプト、ドキュメントのサイズの上限(未指定の場合)
+	defaultMaxSize = 1024 * 1024
+	// maxScriptLength スクリプトの文字数の上限
+	maxScriptLength = 64 * 1024
+	// maxCallStackSize 関数呼び出しの深さの上限
+	maxCallStackSize = 256
+	// defaultMaxMemory スクリプトの実行中に増加するヒープの上限(未指定の場合)
+	defaultMaxMemory = 64 * 1024 * 1024
+	// memoryCheckInterval ヒープの使用量を確認する間隔
+	// ReadMemStatsは全goroutineを停止するため、実行中のスクリプトの数によらずこの間隔で1回だけ確認する
+	memoryCheckInterval = 100 * time.Millisecond
+	// maxCachedPrograms コンパイル済みのスクリプトを保持する上限
+	maxCachedPrograms = 256
+)
+
+var (
+	// ErrTimeout "script execution timed out"
+	ErrTimeout = errors.New("script execution timed out")
+	// ErrTooLarge "script result is too large"
+	ErrTooLarge = errors.New("script result is too large")
+	// ErrMemoryLimit "script exceeded the memory limit"
+	ErrMemoryLimit = errors.New("script exceeded the memory limit")
+)
+
+// Error スクリプトの実行時エラー(HTTPステータスを持ちます)
+type Error struct {
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Request スクリプトから参照できるリクエスト情報
+type Request struct {
+	Method string                 `json:"method"`
+	URL    string                 `json:"url"`
+	Params map[string]interface{} `json:"params"`
+}
+
+// Collection スクリプトから参照できるコレクションの操作
+type Collection interface {
+	Get(key interface{}) (interface{}, error)
+	List() (interface{}, error)
+	Update(document map[string]interface{}) error
+}
+
+// Hook スクリプトの実行に必要な情報
+type Hook struct {
+	Request    Request
+	Document   interface{}
+	Status     int
+	Collection Collection
+}
+
+// Result スクリプトの実行結果
+type Result struct {
+	Document interface{}
+	Status   int
+}
+
+// Runner Interface
+type Runner interface {
+	Compile(script string) error
+	Run(script string, hook Hook) (Result, error)
+}
+
+type runner struct {
+	timeout  time.Duration
+	maxSize  int
+	memory   *memoryWatcher
+	programs *programCache
+}
+
+// NewRunner Runnerインターフェイスを表すオブジェクトを作成します
+// timeoutはミリ秒、maxSize、maxMemoryはバイト数で、0の場合は既定値を使用します
+func NewRunner(timeout int, maxSize int, maxMemory int) Runner {
+	r := &runner{
+		timeout:  time.Duration(timeout) * time.Millisecond,
+		maxSize:  maxSize,
+		programs: &programCache{programs: map[string]*goja.Program{}},
+	}
+	if r.timeout <= 0 {
+		r.timeout = defaultTimeout
+	}
+	if r.maxSize <= 0 {
+		r.maxSize = defaultMaxSize
+	}
+	if maxMemory <= 0 {
+		maxMemory = defaultMaxMemory
+	}
+	r.memory = &memoryWatcher{maxMemory: uint64(maxMemory), running: map[*goja.Runtime]struct{}{}}
+	return r
+}
+
+// Compile スクリプトの構文を検証します
+func (r *runner) Compile(script string) error {
+	if len(script) > maxScriptLength {
+		return fmt.Errorf("script must be less than %d characters", maxScriptLength)
+	}
+	_, err := goja.Compile("hook", script, true)
+	return err
+}
+
+// Run スクリプトを実行します
+// スクリプトからはrequest、document、status、collectionを参照でき、
+// documentとstatusの変更が実行結果になります。reject(status, message)でエラーを返却できます
+func (r *runner) Run(script string, hook Hook) (Result, error) {
+	result := Result{Document: hook.Document, Status: hook.Status}
+
+	program, err := r.programs.get(script)
+	if err != nil {
+		return result, &Error{Status: http.StatusInternalServerError, Message: err.Error()}
+	}
+
+	// Goの型のままだとスクリプトからの変更が反映されない場合があるため、JSONを経由して変換する
+	document, err := r.toPlain(hook.Document)
+	if err != nil {
+		return result, err
+	}
+
+	vm := goja.New()
+	vm.SetMaxCallStackSize(maxCallStackSize)
+	vm.SetFieldNameMapper(goja.TagFieldNameMapper("json", true))
+
+	vm.Set("request", hook.Request)
+	vm.Set("document", document)
+	vm.Set("status", hook.Status)
+	vm.Set("reject", func(call goja.FunctionCall) goja.Value {
+		status := int(call.Argument(0).ToInteger())
+		if status < 400 || status > 599 {
+			status = http.StatusBadRequest
+		}
+		panic(vm.ToValue(&Error{Status: status, Message: call.Argument(1).String()}))
+	})
+	vm.Set("console", map[string]interface{}{
+		"log": func(call goja.FunctionCall) goja.Value {
+			args := make([]interface{}, 0, len(call.Arguments))
+			for _, arg := range call.Arguments {
+				args = append(args, arg.Export())
+			}
+			log.Println(append([]interface{}{"[script]"}, args...)...)
+			return goja.Undefined()
+		},
+	})
+	if hook.Collection != nil {
+		vm.Set("collection", r.newCollection(vm, hook.Collection))
+	}
+
+	timer := time.AfterFunc(r.timeout, func() {
+		vm.Interrupt(ErrTimeout)
+	})
+	defer timer.Stop()
+	stop := r.memory.watch(vm)
+	defer stop()
+
+	if _, err := vm.RunProgram(program); err != nil {
+		return result, toError(err)
+	}
+
+	exported := vm.Get("document").Export()
+	plain, err := r.toPlain(exported)
+	if err != nil {
+		return result, err
+	}
+	result.Document = plain
+
+	if status := int(vm.Get("status").ToInteger()); status >= 100 && status <= 599 {
+		result.Status = status
+	}
+
+	return result, nil
+}
+
+// programCache コンパイル済みのスクリプトを、スクリプトの内容ごとに保持します
+type programCache struct {
+	mu       sync.Mutex
+	programs map[string]*goja.Program
+}
+
+// get コンパイル済みのスクリプトを返却します。保持していない場合はコンパイルします
+// 上限を超えた場合は保持しているものをすべて破棄します(スクリプトの変更で古いものが残り続けないようにするため)
+func (c *programCache) get(script string) (*goja.Program, error) {
+	c.mu.Lock()
+	program, ok := c.programs[script]
+	c.mu.Unlock()
+	if ok {
+		return program, nil
+	}
+
+	program, err := goja.Compile("hook", script, true)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if len(c.programs) >= maxCachedPrograms {
+		c.programs = map[string]*goja.Program{}
+	}
+	c.programs[script] = program
+	c.mu.Unlock()
+	return program, nil
+}
+
+// memoryWatcher 実行中のスクリプトが使用するヒープを、1つのgoroutineでまとめて確認します
+// ヒープはプロセス全体で共有するため、スクリプトを実行していない時点からの増加量で判断する概算の上限です
+type memoryWatcher struct {
+	maxMemory uint64
+
+	mu       sync.Mutex
+	running  map[*goja.Runtime]struct{}
+	baseline uint64
+	watching bool
+}
+
+// watch スクリプトの実行中にヒープの使用量を確認し、maxMemoryを超えて増加した場合は実行中のスクリプトを中断します
+// 返却する関数で確認を終了します
+func (w *memoryWatcher) watch(vm *goja.Runtime) func() {
+	w.mu.Lock()
+	if !w.watching {
+		// 最初のスクリプトの開始時点を基準にし、実行中のスクリプトがなくなるまで確認を続ける
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		w.baseline = stats.HeapAlloc
+		w.watching = true
+		go w.loop()
+	}
+	w.running[vm] = struct{}{}
+	w.mu.Unlock()
+
+	return func() {
+		w.mu.Lock()
+		delete(w.running, vm)
+		w.mu.Unlock()
+	}
+}
+
+// loop 実行中のスクリプトがなくなるまで、一定の間隔でヒープの使用量を確認します
+func (w *memoryWatcher) loop() {
+	ticker := time.NewTicker(memoryCheckInterval)
+	defer ticker.Stop()
+	var stats runtime.MemStats
+	for range ticker.C {
+		w.mu.Lock()
+		if len(w.running) == 0 {
+			w.watching = false
+			w.mu.Unlock()
+			return
+		}
+		w.mu.Unlock()
+
+		runtime.ReadMemStats(&stats)
+
+		w.mu.Lock()
+		// GCで減少した場合は基準を下げ、他の処理で増えた分を積み上げない
+		if stats.HeapAlloc < w.baseline {
+			w.baseline = stats.HeapAlloc
+		}
+		if stats.HeapAlloc-w.baseline > w.maxMemory {
+			// どのスクリプトが確保したかは区別できないため、実行中のものをすべて中断する
+			for vm := range w.running {
+				vm.Interrupt(ErrMemoryLimit)
+			}
+			w.running = map[*goja.Runtime]struct{}{}
+			// 中断したスクリプトの分はGCで解放されるまで残るため、後から開始したスクリプトの基準にしない
+			w.baseline = stats.HeapAlloc
+		}
+		w.mu.Unlock()
+	}
+}
+
+// newCollection スクリプトから呼び出すコレクションの操作を作成します
+func (r *runner) newCollection(vm *goja.Runtime, collection Collection) map[string]interface{} {
+	throw := func(err error) {
+		panic(vm.ToValue(&Error{Status: http.StatusInternalServerError, Message: err.Error()}))
+	}
+	return map[string]interface{}{
+		"get": func(key goja.Value) interface{} {
+			document, err := collection.Get(key.Export())
+			if err != nil {
+				return nil
+			}
+			plain, err := r.toPlain(document)
+			if err != nil {
+				throw(err)
+			}
+			return plain
+		},
+		"list": func() interface{} {
+			documents, err := collection.List()
+			if err != nil {
+				return []interface{}{}
+			}
+			plain, err := r.toPlain(documents)
+			if err != nil {
+				throw(err)
+			}
+			return plain
+		},
+		"update": func(document goja.Value) bool {
+			plain, err := r.toPlain(document.Export())
+			if err != nil {
+				throw(err)
+			}
+			object, ok := plain.(map[string]interface{})
+			if !ok {
+				throw(errors.New("document is not object"))
+			}
+			if err := collection.Update(object); err != nil {
+				throw(err)
+			}
+			return true
+		},
+	}
+}
+
+// toPlain 値をJSONで表現できる型(map、slice、float64等)に変換します。サイズの上限も検証します
+func (r *runner) toPlain(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, &Error{Status: http.StatusInternalServerError, Message: err.Error()}
+	}
+	if len(b) > r.maxSize {
+		return nil, &Error{Status: http.StatusInternalServerError, Message: ErrTooLarge.Error()}
+	}
+	var plain interface{}
+	if err := json.Unmarshal(b, &plain); err != nil {
+		return nil, &Error{Status: http.StatusInternalServerError, Message: err.Error()}
+	}
+	return plain, nil
+}
+
+// toError gojaのエラーを、HTTPステータスを持つErrorに変換します
+func toError(err error) error {
+	switch e := err.(type) {
+	case *goja.InterruptedError:
+		if e.Value() == ErrTimeout || e.Value() == ErrMemoryLimit {
+			return &Error{Status: http.StatusInternalServerError, Message: e.Value().(error).Error()}
+		}
+	case *goja.Exception:
+		if scriptErr, ok := e.Value().Export().(*Error); ok {
+			return scriptErr
+		}
+		return &Error{Status: http.StatusInternalServerError, Message: "script error: " + e.Error()}
+	case *goja.StackOverflowError:
+		return &Error{Status: http.StatusInternalServerError, Message: "script error: " + e.Error()}
+	}
+	return &Error{Status: http.StatusInternalServerError, Message: "script error: " + err.Error()}
+}
@@ -0,0 +1,149 @@
+package script_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/script"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockCollection struct {
+	documents map[interface{}]interface{}
+	updated   []map[string]interface{}
+}
+
+func (c *mockCollection) Get(key interface{}) (interface{}, error) {
+	return c.documents[key], nil
+}
+
+func (c *mockCollection) List() (interface{}, error) {
+	list := []interface{}{}
+	for _, document := range c.documents {
+		list = append(list, document)
+	}
+	return list, nil
+}
+
+func (c *mockCollection) Update(document map[string]interface{}) error {
+	c.updated = append(c.updated, document)
+	return nil
+}
+
+func TestRun(t *testing.T) {
+	runner := script.NewRunner(100, 0, 0)
+
+	t.Run("documentを変更", func(t *testing.T) {
+		result, err := runner.Run(`document.status = "draft";`, script.Hook{
+			Document: map[string]interface{}{"id": "1"},
+			Status:   http.StatusOK,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"id": "1", "status": "draft"}, result.Document)
+		assert.Equal(t, http.StatusOK, result.Status)
+	})
+	t.Run("documentを置き換え、statusを変更", func(t *testing.T) {
+		result, err := runner.Run(`document = {total: document.items.reduce(function(a, b) { return a + b; }, 0)}; status = 201;`, script.Hook{
+			Document: map[string]interface{}{"items": []interface{}{1, 2, 3}},
+			Status:   http.StatusOK,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"total": float64(6)}, result.Document)
+		assert.Equal(t, http.StatusCreated, result.Status)
+	})
+	t.Run("requestを参照", func(t *testing.T) {
+		result, err := runner.Run(`document = {method: request.method, id: request.params.id};`, script.Hook{
+			Request: script.Request{Method: "GET", URL: "users/1", Params: map[string]interface{}{"id": "1"}},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"method": "GET", "id": "1"}, result.Document)
+	})
+	t.Run("rejectでエラーを返却", func(t *testing.T) {
+		_, err := runner.Run(`if (!document.name) { reject(422, "name is required"); }`, script.Hook{
+			Document: map[string]interface{}{"id": "1"},
+		})
+
+		scriptErr, ok := err.(*script.Error)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusUnprocessableEntity, scriptErr.Status)
+		assert.Equal(t, "name is required", scriptErr.Message)
+	})
+	t.Run("実行時エラー", func(t *testing.T) {
+		_, err := runner.Run(`document.foo.bar = 1;`, script.Hook{Document: map[string]interface{}{}})
+
+		scriptErr, ok := err.(*script.Error)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusInternalServerError, scriptErr.Status)
+	})
+	t.Run("実行時間の上限", func(t *testing.T) {
+		_, err := runner.Run(`while (true) {}`, script.Hook{})
+
+		scriptErr, ok := err.(*script.Error)
+		assert.True(t, ok)
+		assert.Equal(t, script.ErrTimeout.Error(), scriptErr.Message)
+	})
+	t.Run("再帰呼び出しの上限", func(t *testing.T) {
+		_, err := runner.Run(`function f() { return f(); } f();`, script.Hook{})
+
+		assert.Error(t, err)
+	})
+	t.Run("結果のサイズの上限", func(t *testing.T) {
+		smallRunner := script.NewRunner(1000, 100, 0)
+
+		_, err := smallRunner.Run(`document = {text: new Array(200).join("a")};`, script.Hook{})
+
+		scriptErr, ok := err.(*script.Error)
+		assert.True(t, ok)
+		assert.Equal(t, script.ErrTooLarge.Error(), scriptErr.Message)
+	})
+	t.Run("メモリの上限", func(t *testing.T) {
+		memoryRunner := script.NewRunner(10000, 0, 8*1024*1024)
+
+		_, err := memoryRunner.Run(`var a = []; while (true) { a.push(new Array(1024).join("x") + a.length); }`, script.Hook{})
+
+		scriptErr, ok := err.(*script.Error)
+		assert.True(t, ok)
+		assert.Equal(t, script.ErrMemoryLimit.Error(), scriptErr.Message)
+	})
+	t.Run("同じスクリプトを繰り返し実行しても前回の実行結果は残らない", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			result, err := runner.Run(`var count = (typeof count === "undefined" ? 0 : count) + 1; document.count = count;`, script.Hook{
+				Document: map[string]interface{}{},
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, float64(1), result.Document.(map[string]interface{})["count"])
+		}
+	})
+	t.Run("collectionを操作", func(t *testing.T) {
+		collection := &mockCollection{documents: map[interface{}]interface{}{
+			"1": map[string]interface{}{"id": "1", "price": 100},
+		}}
+
+		result, err := runner.Run(`
+			var item = collection.get("1");
+			document.total = item.price * document.quantity;
+			collection.update(document);
+		`, script.Hook{
+			Document:   map[string]interface{}{"id": "order-1", "quantity": 2},
+			Collection: collection,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, float64(200), result.Document.(map[string]interface{})["total"])
+		assert.Len(t, collection.updated, 1)
+	})
+}
+
+func TestCompile(t *testing.T) {
+	runner := script.NewRunner(0, 0, 0)
+
+	assert.NoError(t, runner.Compile(`document.status = "draft";`))
+	assert.Error(t, runner.Compile(`document.status = ;`))
+	assert.Error(t, runner.Compile(strings.Repeat(" ", 64*1024+1)))
+}
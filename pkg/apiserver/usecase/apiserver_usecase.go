@@ -1,23 +1,34 @@
 package usecase
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	_apiRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/api/repository"
 	_methodRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/method/repository"
 	_modelRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/model/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/audit"
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/event"
 	_apiserverRepository "github.com/Hajime3778/api-creator-backend/pkg/apiserver/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/script"
+	"github.com/Hajime3778/api-creator-backend/pkg/document"
 	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/generator"
+	"github.com/google/uuid"
 	"github.com/xeipuuv/gojsonschema"
-	"go.mongodb.org/mongo-driver/bson"
 )
 
+// mockArrayCount mockで配列を返却する場合の件数
+const mockArrayCount = 3
+
 var (
 	// ErrAPINotFound "api not found"
 	ErrAPINotFound = errors.New("api not found")
@@ -27,9 +38,16 @@ var (
 	ErrModelNotDeclare = errors.New("model not declare")
 )
 
+// EventsPath ドキュメントの変更イベントを配信するパス("<api-url>/_events")
+const EventsPath = "_events"
+
 // APIServerUsecase Interface
 type APIServerUsecase interface {
-	RequestDocumentServer(httpMethod string, url string, body []byte) (interface{}, int, error)
+	// RequestDocumentServer ctxには呼び出し元(domain.WithCaller)を保持し、監査ログに記録します
+	RequestDocumentServer(ctx context.Context, httpMethod string, url string, body []byte) (interface{}, int, error)
+	Subscribe(ctx context.Context, url string, lastEventID string, filter domain.EventFilter) (<-chan domain.Event, int, error)
+	// ResolveMethod リクエストされたHTTPメソッド、URLのAPIとMethodを取得します(Methodが見つからない場合はゼロ値)
+	ResolveMethod(httpMethod string, url string) (domain.API, domain.Method, error)
 }
 
 type apiServerUsecase struct {
@@ -37,56 +55,135 @@ type apiServerUsecase struct {
 	methodRepo    _methodRepository.MethodRepository
 	modelRepo     _modelRepository.ModelRepository
 	apiserverRepo _apiserverRepository.APIServerRepository
+	runner        script.Runner
+	publisher     event.Publisher
+	subscriber    event.Subscriber
+	recorder      audit.Recorder
+	// maxMockLatency mockの待ち時間の上限(0の場合は制限しません)
+	maxMockLatency time.Duration
 }
 
 // NewAPIServerUsecase APIServerUsecaseインターフェイスを表すオブジェクトを作成します
-func NewAPIServerUsecase(apiRepo _apiRepository.APIRepository, methodRepo _methodRepository.MethodRepository, modelRepo _modelRepository.ModelRepository, apiserverRepo _apiserverRepository.APIServerRepository) APIServerUsecase {
+// maxMockLatencyはmockの待ち時間の上限で、サーバーの書き込みタイムアウトより前にレスポンスを返却するために指定します(0の場合は制限しません)
+func NewAPIServerUsecase(apiRepo _apiRepository.APIRepository, methodRepo _methodRepository.MethodRepository, modelRepo _modelRepository.ModelRepository, apiserverRepo _apiserverRepository.APIServerRepository, runner script.Runner, publisher event.Publisher, subscriber event.Subscriber, recorder audit.Recorder, maxMockLatency time.Duration) APIServerUsecase {
 	return &apiServerUsecase{
-		apiRepo:       apiRepo,
-		methodRepo:    methodRepo,
-		modelRepo:     modelRepo,
-		apiserverRepo: apiserverRepo,
+		apiRepo:        apiRepo,
+		methodRepo:     methodRepo,
+		modelRepo:      modelRepo,
+		apiserverRepo:  apiserverRepo,
+		runner:         runner,
+		publisher:      publisher,
+		subscriber:     subscriber,
+		recorder:       recorder,
+		maxMockLatency: maxMockLatency,
 	}
 }
 
 // RequestDocumentServer リクエスト情報からMethodを特定し、ドキュメントに対してCRUDします
-func (u *apiServerUsecase) RequestDocumentServer(httpMethod string, url string, body []byte) (interface{}, int, error) {
-	api, err := u.apiRepo.GetByURL(url)
+func (u *apiServerUsecase) RequestDocumentServer(ctx context.Context, httpMethod string, url string, body []byte) (interface{}, int, error) {
+	start := time.Now()
+	// ミドルウェア(CORS、リクエスト数の制限)で取得済みの場合は、それを使用する
+	resolved, ok := domain.ResolvedMethodFromContext(ctx, httpMethod, url)
+	api, err := resolved.API, resolved.Err
+	if !ok {
+		api, err = u.apiRepo.GetByURL(url)
+	}
 	if err != nil {
+		// URL変更前の別名でリクエストされた場合は、新しいURLへリダイレクトする
+		if location, ok := u.getRedirectLocation(url); ok {
+			return location, http.StatusPermanentRedirect, nil
+		}
 		// APIが見つかりません
 		return "", http.StatusNotFound, err
 	}
-	model, err := u.modelRepo.GetByAPIID(api.ID)
-	if err != nil {
-		return "", http.StatusBadRequest, ErrModelNotDeclare
-	}
+	// mockのメソッドはModelが未定義でも呼び出せるため、ここではエラーとしない
+	model, modelErr := u.modelRepo.GetByAPIID(api.ID)
 
 	// コレクションを削除する、システム規定のメソッド
 	pathParam := strings.Replace(url, api.URL+"/", "", 1)
-	if pathParam == "remove-target-collection" && httpMethod == "DELETE" {
-		return u.apiserverRepo.RemoveCollection(model.Name)
+	// RPC、GraphQLから実行する場合は、パラメータの値がシステム規定のメソッドと同じでもMethodとして実行する
+	if pathParam == "remove-target-collection" && httpMethod == "DELETE" && resolved.Params == nil {
+		if modelErr != nil {
+			return "", http.StatusBadRequest, ErrModelNotDeclare
+		}
+		trail := u.beginAudit(ctx, start, api, domain.Method{Type: httpMethod}, model, url, nil, nil)
+		response, status, err := u.apiserverRepo.RemoveCollection(model.GetCollectionName())
+		u.endAudit(trail, status, err)
+		return response, status, err
 	}
 
 	// 対象のメソッドを取得
-	method, err := u.getRequestedMethod(httpMethod, url, api)
-
-	if err != nil {
-		return "", http.StatusNotFound, ErrAPINotFound
+	method := resolved.Method
+	if !ok {
+		method, err = u.getRequestedMethod(httpMethod, url, api)
+		if err != nil {
+			return "", http.StatusNotFound, ErrAPINotFound
+		}
 	}
 
 	if method.Type != httpMethod {
 		return "", http.StatusNotFound, ErrAPINotFound
 	}
 
+	if method.IsMockMode() {
+		return u.mock(method)
+	}
+
+	if modelErr != nil {
+		return "", http.StatusBadRequest, ErrModelNotDeclare
+	}
+
 	// リクエストされたパラメータを取得
-	paramKey, paramValue, err := getRequestedURLParameter(url, api.URL, method.URL, model.Schema)
+	paramKey, paramValue, err := getRequestedURLParameter(url, api.URL, method.URL, model.Schema, resolved.Params)
 	if err != nil {
 		return "", http.StatusInternalServerError, err
 	}
 
+	request := script.Request{Method: httpMethod, URL: url, Params: map[string]interface{}{}}
+	if paramKey != "" {
+		request.Params[paramKey] = paramValue
+	}
+
+	if method.BeforeScript != "" {
+		body, err = u.runBeforeScript(ctx, api, method, model, request, body)
+		if err != nil {
+			return "", scriptErrorStatus(err), err
+		}
+	}
+
+	trail := u.beginAudit(ctx, start, api, method, model, url, paramValue, body)
+	response, status, err := u.execute(method, model, paramKey, paramValue, body)
+	u.endAudit(trail, status, err)
+	if err != nil {
+		return response, status, err
+	}
+	u.publish(api, model, method, paramValue, body, response)
+
+	if method.AfterScript == "" {
+		return response, status, nil
+	}
+
+	return u.runAfterScript(ctx, api, method, model, request, response, status)
+}
+
+// ResolveMethod リクエストされたHTTPメソッド、URLのAPIとMethodを取得します(Methodが見つからない場合はゼロ値)
+func (u *apiServerUsecase) ResolveMethod(httpMethod string, url string) (domain.API, domain.Method, error) {
+	api, err := u.apiRepo.GetByURL(url)
+	if err != nil {
+		return domain.API{}, domain.Method{}, err
+	}
+	method, err := u.getRequestedMethod(httpMethod, url, api)
+	if err != nil || method.Type != httpMethod {
+		return api, domain.Method{}, nil
+	}
+	return api, method, nil
+}
+
+// execute Methodの種類に応じて、コレクションのドキュメントを操作します
+func (u *apiServerUsecase) execute(method domain.Method, model domain.Model, paramKey string, paramValue interface{}, body []byte) (interface{}, int, error) {
 	switch method.Type {
 	case "GET":
-		return u.get(method.IsArray, model.Name, paramKey, paramValue)
+		return u.get(method.IsArray, model.GetCollectionName(), paramKey, paramValue)
 
 	case "POST":
 		return u.create(model, body)
@@ -95,13 +192,398 @@ func (u *apiServerUsecase) RequestDocumentServer(httpMethod string, url string,
 		return u.update(model, body)
 
 	case "DELETE":
-		return u.delete(model.Name, paramKey, paramValue)
+		return u.delete(model.GetCollectionName(), paramKey, paramValue)
 
 	default:
 		return "", http.StatusInternalServerError, errors.New("incorrect http method")
 	}
 }
 
+// publish 書き込みに成功した場合に、ドキュメントの変更イベントを通知します
+func (u *apiServerUsecase) publish(api domain.API, model domain.Model, method domain.Method, paramValue interface{}, body []byte, response interface{}) {
+	if u.publisher == nil {
+		return
+	}
+
+	e := domain.Event{
+		APIID:      api.ID,
+		Collection: model.GetCollectionName(),
+		OccurredAt: time.Now(),
+	}
+	switch method.Type {
+	case "POST":
+		e.Type = domain.EventCreate
+	case "PUT":
+		e.Type = domain.EventUpdate
+	case "DELETE":
+		e.Type = domain.EventDelete
+		e.Key = paramValue
+	default:
+		return
+	}
+
+	if e.Type != domain.EventDelete {
+		keys, err := model.GetKeyNames()
+		if err != nil {
+			return
+		}
+		e.Key, _ = getPropertyValue(model.Schema, keys[0], body)
+		e.Document = toPlainDocument(response)
+	}
+
+	id, _ := uuid.NewRandom()
+	e.ID = id.String()
+	u.publisher.Publish(e)
+}
+
+// auditTrail 監査ログに記録する、実行中の書き込み
+type auditTrail struct {
+	entry      audit.Entry
+	start      time.Time
+	collection string
+	keyName    string
+}
+
+// beginAudit 書き込み(POST、PUT、DELETE)の場合に、変更前のドキュメントを取得して監査ログの記録を開始します
+// 記録しない場合はnilを返却します
+func (u *apiServerUsecase) beginAudit(ctx context.Context, start time.Time, api domain.API, method domain.Method, model domain.Model, url string, paramValue interface{}, body []byte) *auditTrail {
+	if u.recorder == nil {
+		return nil
+	}
+	switch method.Type {
+	case "POST", "PUT", "DELETE":
+	default:
+		return nil
+	}
+
+	trail := &auditTrail{
+		entry: audit.Entry{
+			API:        api,
+			Method:     method,
+			Schema:     model.Schema,
+			HTTPMethod: method.Type,
+			URL:        url,
+			Key:        paramValue,
+			Caller:     domain.CallerFromContext(ctx),
+		},
+		start:      start,
+		collection: model.GetCollectionName(),
+	}
+	// コレクションの削除は、ドキュメントごとには記録しない
+	if method.ID == "" {
+		return trail
+	}
+	keys, err := model.GetKeyNames()
+	if err != nil || len(keys) == 0 {
+		return trail
+	}
+	trail.keyName = keys[0]
+	if method.Type != "DELETE" {
+		trail.entry.Key, _ = getPropertyValue(model.Schema, keys[0], body)
+	}
+	if method.Type != "POST" && trail.entry.Key != nil {
+		trail.entry.Before = u.getAuditDocument(trail)
+	}
+	return trail
+}
+
+// endAudit 書き込みの結果と、変更後のドキュメントを監査ログに記録します
+func (u *apiServerUsecase) endAudit(trail *auditTrail, status int, err error) {
+	if trail == nil {
+		return
+	}
+	trail.entry.Status = status
+	trail.entry.Err = err
+	if err == nil && trail.entry.HTTPMethod != "DELETE" && trail.keyName != "" && trail.entry.Key != nil {
+		trail.entry.After = u.getAuditDocument(trail)
+	}
+	trail.entry.Latency = time.Since(trail.start)
+	u.recorder.Record(trail.entry)
+}
+
+// getAuditDocument 監査ログに記録する対象のドキュメントを取得します(存在しない場合はnil)
+func (u *apiServerUsecase) getAuditDocument(trail *auditTrail) interface{} {
+	document, status, _ := u.apiserverRepo.Get(trail.collection, trail.keyName, trail.entry.Key)
+	if status != http.StatusOK {
+		return nil
+	}
+	return toPlainDocument(document)
+}
+
+// runBeforeScript コレクションを操作する前にスクリプトを実行し、変更後のリクエストBodyを返却します
+func (u *apiServerUsecase) runBeforeScript(ctx context.Context, api domain.API, method domain.Method, model domain.Model, request script.Request, body []byte) ([]byte, error) {
+	var document interface{}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &document); err != nil {
+			return nil, ErrInvalidRequest
+		}
+	}
+
+	result, err := u.runner.Run(method.BeforeScript, script.Hook{
+		Request:    request,
+		Document:   document,
+		Collection: u.newHookCollection(ctx, api, method, model, request.URL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Document == nil {
+		return body, nil
+	}
+	return json.Marshal(result.Document)
+}
+
+// runAfterScript コレクションを操作した後にスクリプトを実行し、変更後のレスポンスを返却します
+func (u *apiServerUsecase) runAfterScript(ctx context.Context, api domain.API, method domain.Method, model domain.Model, request script.Request, response interface{}, status int) (interface{}, int, error) {
+	result, err := u.runner.Run(method.AfterScript, script.Hook{
+		Request:    request,
+		Document:   response,
+		Status:     status,
+		Collection: u.newHookCollection(ctx, api, method, model, request.URL),
+	})
+	if err != nil {
+		return "", scriptErrorStatus(err), err
+	}
+
+	if result.Document == nil {
+		return "", result.Status, nil
+	}
+	return result.Document, result.Status, nil
+}
+
+// toPlainDocument ドキュメントをJSONで表現できる型(map[string]interface{}等)に変換します
+func toPlainDocument(document interface{}) interface{} {
+	b, err := json.Marshal(document)
+	if err != nil {
+		return document
+	}
+	var plain interface{}
+	if err := json.Unmarshal(b, &plain); err != nil {
+		return document
+	}
+	return plain
+}
+
+// scriptErrorStatus スクリプトのエラーから、レスポンスのHTTPステータスを取得します
+func scriptErrorStatus(err error) int {
+	if scriptErr, ok := err.(*script.Error); ok {
+		return scriptErr.Status
+	}
+	if err == ErrInvalidRequest {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// hookCollection スクリプトから、Methodの対象のコレクションを操作します
+type hookCollection struct {
+	usecase        *apiServerUsecase
+	apiserverRepo  _apiserverRepository.APIServerRepository
+	ctx            context.Context
+	api            domain.API
+	method         domain.Method
+	model          domain.Model
+	url            string
+	collectionName string
+	keyName        string
+	schema         string
+}
+
+// newHookCollection スクリプトから操作するコレクションを作成します
+// 更新はスクリプトを実行したMethodのリクエストとして、監査ログと変更イベントに記録します
+func (u *apiServerUsecase) newHookCollection(ctx context.Context, api domain.API, method domain.Method, model domain.Model, url string) script.Collection {
+	keys, err := model.GetKeyNames()
+	if err != nil || len(keys) == 0 {
+		return nil
+	}
+	return &hookCollection{
+		usecase:        u,
+		apiserverRepo:  u.apiserverRepo,
+		ctx:            ctx,
+		api:            api,
+		method:         method,
+		model:          model,
+		url:            url,
+		collectionName: model.GetCollectionName(),
+		keyName:        keys[0],
+		schema:         model.Schema,
+	}
+}
+
+// Get キーからドキュメントを1件取得します
+func (c *hookCollection) Get(key interface{}) (interface{}, error) {
+	document, status, err := c.apiserverRepo.Get(c.collectionName, c.keyName, key)
+	if status != http.StatusOK {
+		return nil, err
+	}
+	return document, nil
+}
+
+// List すべてのドキュメントを取得します
+func (c *hookCollection) List() (interface{}, error) {
+	documents, status, err := c.apiserverRepo.GetList(c.collectionName, "", "")
+	if status == http.StatusNotFound {
+		return []interface{}{}, nil
+	} else if status != http.StatusOK {
+		return nil, err
+	}
+	return documents, nil
+}
+
+// Update ドキュメントを更新します
+// REST APIのPUTと同じく、ModelのSchemaで検証し、存在するドキュメントのみ更新して、監査ログと変更イベントに記録します
+func (c *hookCollection) Update(document map[string]interface{}) error {
+	if document[c.keyName] == nil {
+		return errors.New("target property is not found")
+	}
+	body, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+	if err := getRequestedSchemaValidate(c.schema, body); err != nil {
+		return err
+	}
+	doc, value, err := decodeDocument(c.schema, c.keyName, body)
+	if err != nil {
+		return err
+	}
+	if _, status, _ := c.apiserverRepo.Get(c.collectionName, c.keyName, value); status == http.StatusNotFound {
+		return errors.New("record is not found")
+	}
+
+	// 監査ログと変更イベントは、PUTでの更新として記録する
+	method := c.method
+	method.Type = "PUT"
+	trail := c.usecase.beginAudit(c.ctx, time.Now(), c.api, method, c.model, c.url, nil, body)
+	response, status, err := c.apiserverRepo.Update(c.collectionName, c.keyName, doc)
+	c.usecase.endAudit(trail, status, err)
+	if err != nil {
+		return err
+	}
+	c.usecase.publish(c.api, c.model, method, nil, body, response)
+	return nil
+}
+
+// Subscribe "<api-url>/_events"でリクエストされたAPIの、ドキュメントの変更イベントを購読します
+// MongoDBのChange Streamが利用できる場合はそれを使用し、利用できない場合はプロセス内のイベントを配信します
+// ctxがキャンセルされると購読を終了し、チャネルを閉じます
+func (u *apiServerUsecase) Subscribe(ctx context.Context, url string, lastEventID string, filter domain.EventFilter) (<-chan domain.Event, int, error) {
+	apiURL := strings.TrimSuffix(url, "/"+EventsPath)
+	api, err := u.apiRepo.GetByURL(apiURL)
+	if err != nil || api.URL != apiURL || !api.StreamEnabled {
+		return nil, http.StatusNotFound, ErrAPINotFound
+	}
+	model, err := u.modelRepo.GetByAPIID(api.ID)
+	if err != nil {
+		return nil, http.StatusBadRequest, ErrModelNotDeclare
+	}
+	keys, err := model.GetKeyNames()
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	source, err := u.apiserverRepo.Watch(ctx, model.GetCollectionName(), keys[0], lastEventID)
+	if err != nil {
+		var unsubscribe func()
+		source, unsubscribe, err = u.subscriber.Subscribe(api.ID, lastEventID)
+		if err != nil {
+			cancel()
+			// 再開できないトークンの場合、クライアントは最初から購読し直す必要がある
+			return nil, http.StatusGone, err
+		}
+		go func() {
+			<-ctx.Done()
+			unsubscribe()
+		}()
+	}
+
+	events := make(chan domain.Event)
+	go func() {
+		defer close(events)
+		defer cancel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-source:
+				if !ok {
+					return
+				}
+				e.APIID = api.ID
+				if e.Document != nil {
+					e.Document = toPlainDocument(e.Document)
+				}
+				if !filter.Match(e) {
+					continue
+				}
+				select {
+				case events <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, http.StatusOK, nil
+}
+
+// mock コレクションを参照せず、Methodに設定された例のレスポンスを返却します
+func (u *apiServerUsecase) mock(method domain.Method) (interface{}, int, error) {
+	latency := time.Duration(method.MockLatency) * time.Millisecond
+	if u.maxMockLatency > 0 && latency > u.maxMockLatency {
+		latency = u.maxMockLatency
+	}
+	time.Sleep(latency)
+
+	status := method.MockStatus
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	if method.MockResponse != "" {
+		var response interface{}
+		if err := json.Unmarshal([]byte(method.MockResponse), &response); err != nil {
+			return "", http.StatusInternalServerError, err
+		}
+		return response, status, nil
+	}
+
+	// 例のレスポンスが未設定の場合は、レスポンスのModelから生成する
+	if method.ResponseModelID == "" {
+		return "", status, nil
+	}
+	responseModel, err := u.modelRepo.GetByID(method.ResponseModelID)
+	if err != nil {
+		return "", http.StatusInternalServerError, ErrModelNotDeclare
+	}
+
+	count := 1
+	if method.IsArray {
+		count = mockArrayCount
+	}
+	// 呼び出すたびに結果が変わらないよう、MethodのIDからシードを作成する
+	seed := fnv.New64a()
+	seed.Write([]byte(method.ID))
+	documents, err := generator.Generate(responseModel.Schema, generator.Options{
+		Count: count,
+		Seed:  int64(seed.Sum64()),
+		// 参照先のドキュメントはmockでは取得しない
+		ResolveRef: func(ref string) ([]interface{}, error) {
+			return []interface{}{ref}, nil
+		},
+	})
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+
+	if method.IsArray {
+		return documents, status, nil
+	}
+	return documents[0], status, nil
+}
+
 func (u *apiServerUsecase) get(isArray bool, modelName string, key string, value interface{}) (interface{}, int, error) {
 	if isArray {
 		return u.apiserverRepo.GetList(modelName, key, value)
@@ -119,16 +601,16 @@ func (u *apiServerUsecase) create(model domain.Model, body []byte) (interface{},
 		return "", http.StatusBadRequest, err
 	}
 
-	value, err := getPropertyValue(keys[0], body)
+	doc, value, err := decodeDocument(model.Schema, keys[0], body)
 	if err != nil {
 		return "", http.StatusBadRequest, err
 	}
 
-	if _, status, _ := u.apiserverRepo.Get(model.Name, keys[0], value); status != http.StatusNotFound {
+	if _, status, _ := u.apiserverRepo.Get(model.GetCollectionName(), keys[0], value); status != http.StatusNotFound {
 		return "", http.StatusBadRequest, errors.New("record is exists")
 	}
 
-	return u.apiserverRepo.Create(model.Name, keys[0], body)
+	return u.apiserverRepo.Create(model.GetCollectionName(), keys[0], doc)
 }
 
 func (u *apiServerUsecase) update(model domain.Model, body []byte) (interface{}, int, error) {
@@ -141,19 +623,36 @@ func (u *apiServerUsecase) update(model domain.Model, body []byte) (interface{},
 		return "", http.StatusBadRequest, err
 	}
 
-	value, err := getPropertyValue(keys[0], body)
+	doc, value, err := decodeDocument(model.Schema, keys[0], body)
+	if err != nil {
+		return "", http.StatusBadRequest, err
+	}
 
-	if _, status, _ := u.apiserverRepo.Get(model.Name, keys[0], value); status == http.StatusNotFound {
+	if _, status, _ := u.apiserverRepo.Get(model.GetCollectionName(), keys[0], value); status == http.StatusNotFound {
 		return "", http.StatusBadRequest, errors.New("record is not found")
 	}
 
-	return u.apiserverRepo.Update(model.Name, keys[0], body)
+	return u.apiserverRepo.Update(model.GetCollectionName(), keys[0], doc)
 }
 
 func (u *apiServerUsecase) delete(modelName string, key string, value interface{}) (interface{}, int, error) {
 	return u.apiserverRepo.Delete(modelName, key, value)
 }
 
+// getRedirectLocation URL変更前の別名から、リダイレクト先のURLを取得します
+func (u *apiServerUsecase) getRedirectLocation(url string) (string, bool) {
+	alias, err := u.apiRepo.GetAliasByURL(url)
+	if err != nil {
+		return "", false
+	}
+	api, err := u.apiRepo.GetByID(alias.APIID)
+	if err != nil {
+		return "", false
+	}
+	// 別名以降のパス(パラメータ等)はそのまま引き継ぐ
+	return api.URL + strings.TrimPrefix(url, alias.URL), true
+}
+
 // getRequestedMethod リクエストされたHTTPメソッド、URL、APIから、対象のMethodを返却します
 func (u *apiServerUsecase) getRequestedMethod(httpMethod string, requestedURL string, api domain.API) (domain.Method, error) {
 
@@ -215,7 +714,8 @@ func (u *apiServerUsecase) getRequestedMethod(httpMethod string, requestedURL st
 }
 
 // getRequestedURLParameter リクエストされたURLパラメータとKeyを取得します
-func getRequestedURLParameter(requestedURL string, apiURL string, methodURL string, modelSchema string) (string, interface{}, error) {
+// paramsにKeyの値がある場合は、URLではなくその値を使用します
+func getRequestedURLParameter(requestedURL string, apiURL string, methodURL string, modelSchema string, params map[string]string) (string, interface{}, error) {
 	var key string
 	var value string
 
@@ -241,6 +741,9 @@ func getRequestedURLParameter(requestedURL string, apiURL string, methodURL stri
 	if value != "" {
 		value = value[1:]
 	}
+	if param, ok := params[key]; ok {
+		value = param
+	}
 
     var schemaMap map[string]interface{}
     err := json.Unmarshal([]byte(modelSchema), &schemaMap)
@@ -298,17 +801,25 @@ func getRequestedSchemaValidate(modelSchema string, requestBody []byte) error {
 }
 
 // getPropertyValue リクエストBody内を、keyから項目の値を取得します
-func getPropertyValue(key string, body []byte) (interface{}, error) {
-	var bsonBody bson.M
+func getPropertyValue(modelSchema string, key string, body []byte) (interface{}, error) {
+	_, value, err := decodeDocument(modelSchema, key, body)
+	return value, err
+}
 
-	err := bson.UnmarshalExtJSON(body, false, &bsonBody)
+// decodeDocument リクエストBodyをSchemaの型に従って変換し、リポジトリに渡すドキュメントとKeyの値を返却します
+// $で始まる項目名や.を含む項目名、Keyの値がオブジェクトのドキュメントはエラーとします
+func decodeDocument(modelSchema string, key string, body []byte) ([]byte, interface{}, error) {
+	doc, err := document.Decode(modelSchema, body)
 	if err != nil {
-		return "", err
+		return nil, nil, err
 	}
-
-	if bsonBody[key] == nil {
-		return "", errors.New("target property is not found")
+	value, err := document.KeyValue(doc, key)
+	if err != nil {
+		return nil, nil, err
 	}
-
-	return bsonBody[key], nil
+	encoded, err := document.Encode(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	return encoded, value, nil
 }
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	_apiRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/api/repository"
+	_methodRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/method/repository"
+	_modelRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/model/repository"
+	_graphql "github.com/Hajime3778/api-creator-backend/pkg/apiserver/graphql"
+	_apiserverRepository "github.com/Hajime3778/api-creator-backend/pkg/apiserver/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/graphql-go/graphql"
+)
+
+// GraphQLUsecase Interface
+type GraphQLUsecase interface {
+	// Execute ctxには呼び出し元(domain.WithCaller)を保持し、Mutationの監査ログに記録します
+	Execute(ctx context.Context, request domain.GraphQLRequest) (interface{}, int, error)
+}
+
+type graphQLUsecase struct {
+	modelRepo     _modelRepository.ModelRepository
+	apiserverRepo _apiserverRepository.APIServerRepository
+	writer        _graphql.Writer
+
+	mu          sync.Mutex
+	schema      graphql.Schema
+	fingerprint string
+}
+
+// NewGraphQLUsecase GraphQLUsecaseインターフェイスを表すオブジェクトを作成します
+// Mutationは、RPCと同じくapiserverUsecaseでREST APIのMethodとして実行します
+func NewGraphQLUsecase(apiRepo _apiRepository.APIRepository, methodRepo _methodRepository.MethodRepository, modelRepo _modelRepository.ModelRepository, apiserverRepo _apiserverRepository.APIServerRepository, apiserverUsecase APIServerUsecase) GraphQLUsecase {
+	return &graphQLUsecase{
+		modelRepo:     modelRepo,
+		apiserverRepo: apiserverRepo,
+		writer: &graphQLWriter{
+			apiRepo:          apiRepo,
+			methodRepo:       methodRepo,
+			apiserverUsecase: apiserverUsecase,
+		},
+	}
+}
+
+// Execute GraphQLのクエリを実行します
+// 実行のたびにModelの定義を確認し、管理画面で変更されていればスキーマを作成し直します
+func (u *graphQLUsecase) Execute(ctx context.Context, request domain.GraphQLRequest) (interface{}, int, error) {
+	schema, err := u.getSchema()
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         schema,
+		Context:        ctx,
+		RequestString:  request.Query,
+		OperationName:  request.OperationName,
+		VariableValues: request.Variables,
+	})
+	return result, http.StatusOK, nil
+}
+
+// getSchema Modelの定義からスキーマを取得します。定義が変更されていない場合は作成済みのスキーマを返却します
+func (u *graphQLUsecase) getSchema() (graphql.Schema, error) {
+	models, err := u.modelRepo.GetAll()
+	if err != nil {
+		return graphql.Schema{}, err
+	}
+	fingerprint := modelsFingerprint(models)
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if fingerprint == u.fingerprint {
+		return u.schema, nil
+	}
+
+	schema, err := _graphql.NewSchema(models, u.apiserverRepo, u.writer)
+	if err != nil {
+		return graphql.Schema{}, err
+	}
+	u.schema = schema
+	u.fingerprint = fingerprint
+	return schema, nil
+}
+
+// modelsFingerprint スキーマに影響するModelの定義から、変更を検知するための値を作成します
+func modelsFingerprint(models []domain.Model) string {
+	sorted := append([]domain.Model{}, models...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	hash := sha256.New()
+	for _, model := range sorted {
+		for _, value := range []string{model.ID, model.Name, model.Description, model.GetCollectionName(), model.Schema} {
+			hash.Write([]byte(value))
+			hash.Write([]byte{0})
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// methodParam MethodのURLのパラメータ(例："/{id}")
+var methodParam = regexp.MustCompile(`\{.+?\}`)
+
+// graphQLWriter Mutationを、Modelを使用するAPIのMethodとして実行します
+type graphQLWriter struct {
+	apiRepo          _apiRepository.APIRepository
+	methodRepo       _methodRepository.MethodRepository
+	apiserverUsecase APIServerUsecase
+}
+
+// Write Modelを使用するAPIの、httpMethodのMethodを実行します
+// DELETEはパラメータが1つのMethod、POST、PUTはパラメータのないMethodを使用します
+func (w *graphQLWriter) Write(ctx context.Context, model domain.Model, httpMethod string, key interface{}, body []byte) (interface{}, int, error) {
+	api, err := w.apiRepo.GetByID(model.APIID)
+	if err != nil {
+		return "", http.StatusNotFound, ErrAPINotFound
+	}
+	methods, err := w.methodRepo.GetListByAPIIDAndType(api.ID, httpMethod)
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+
+	for _, method := range methods {
+		params := methodParam.FindAllString(method.URL, -1)
+		switch {
+		case httpMethod == http.MethodDelete && len(params) == 1:
+			// Keyの値はURLから取得させずに渡す(値の/や..でURLが変わらないよう、URLにはエスケープして含める)
+			value := fmt.Sprint(key)
+			requestedURL := api.URL + strings.Replace(method.URL, params[0], url.PathEscape(value), 1)
+			ctx = domain.WithResolvedMethod(ctx, domain.ResolvedMethod{
+				HTTPMethod: httpMethod,
+				URL:        requestedURL,
+				API:        api,
+				Method:     method,
+				Params:     map[string]string{strings.Trim(params[0], "{}"): value},
+			})
+			return w.apiserverUsecase.RequestDocumentServer(ctx, httpMethod, requestedURL, nil)
+		case httpMethod != http.MethodDelete && len(params) == 0:
+			return w.apiserverUsecase.RequestDocumentServer(ctx, httpMethod, api.URL+method.URL, body)
+		}
+	}
+	return "", http.StatusNotFound, _graphql.ErrMethodNotDefined
+}
@@ -0,0 +1,85 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/usecase"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExecuteMutation(t *testing.T) {
+	mockAPI := domain.API{ID: "api", Name: "users", URL: "users"}
+	mockModel := domain.Model{
+		ID:             "model",
+		APIID:          "api",
+		Name:           "User",
+		CollectionName: "users",
+		Schema:         `{"type": "object", "keys": ["id"], "properties": {"id": {"type": "integer"}, "name": {"type": "string"}}, "required": ["id"]}`,
+	}
+
+	newUsecase := func(apiserverUsecase *mocks.APIServerUsecase, methods map[string][]domain.Method) usecase.GraphQLUsecase {
+		mockAPIRepo := new(mocks.APIRepository)
+		mockMethodRepo := new(mocks.MethodRepository)
+		mockModelRepo := new(mocks.ModelRepository)
+		mockAPIRepo.On("GetByID", "api").Return(mockAPI, nil)
+		mockModelRepo.On("GetAll").Return([]domain.Model{mockModel}, nil)
+		for methodType, list := range methods {
+			mockMethodRepo.On("GetListByAPIIDAndType", "api", methodType).Return(list, nil)
+		}
+		return usecase.NewGraphQLUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, new(mocks.APIServerRepository), apiserverUsecase)
+	}
+	execute := func(u usecase.GraphQLUsecase, query string) map[string]interface{} {
+		result, _, err := u.Execute(context.Background(), domain.GraphQLRequest{Query: query})
+		assert.NoError(t, err)
+		b, _ := json.Marshal(result)
+		var response map[string]interface{}
+		json.Unmarshal(b, &response)
+		return response
+	}
+
+	t.Run("作成はパラメータのないPOSTのMethodで実行する", func(t *testing.T) {
+		apiserverUsecase := new(mocks.APIServerUsecase)
+		apiserverUsecase.On("RequestDocumentServer", "POST", "users", mock.MatchedBy(func(body []byte) bool {
+			return string(body) == `{"id":1,"name":"foo"}`
+		})).Return(map[string]interface{}{"id": 1, "name": "foo"}, http.StatusCreated, nil).Once()
+		u := newUsecase(apiserverUsecase, map[string][]domain.Method{
+			"POST": {{ID: "1", APIID: "api", Type: "POST", URL: "/{id}"}, {ID: "2", APIID: "api", Type: "POST", URL: ""}},
+		})
+
+		response := execute(u, `mutation { createUser(input: {id: 1, name: "foo"}) { id name } }`)
+
+		assert.Nil(t, response["errors"])
+		assert.Equal(t, map[string]interface{}{
+			"createUser": map[string]interface{}{"id": float64(1), "name": "foo"},
+		}, response["data"])
+		apiserverUsecase.AssertExpectations(t)
+	})
+	t.Run("削除はURLのパラメータにKeyを設定する", func(t *testing.T) {
+		apiserverUsecase := new(mocks.APIServerUsecase)
+		apiserverUsecase.On("RequestDocumentServer", "DELETE", "users/items/1", []byte(nil)).Return("", http.StatusNoContent, nil).Once()
+		u := newUsecase(apiserverUsecase, map[string][]domain.Method{
+			"DELETE": {{ID: "1", APIID: "api", Type: "DELETE", URL: "/items/{id}"}},
+		})
+
+		response := execute(u, `mutation { deleteUser(id: 1) }`)
+
+		assert.Equal(t, map[string]interface{}{"deleteUser": true}, response["data"])
+		apiserverUsecase.AssertExpectations(t)
+	})
+	t.Run("Methodが定義されていない場合は実行しない", func(t *testing.T) {
+		apiserverUsecase := new(mocks.APIServerUsecase)
+		u := newUsecase(apiserverUsecase, map[string][]domain.Method{"PUT": {}})
+
+		response := execute(u, `mutation { updateUser(input: {id: 1, name: "foo"}) { id } }`)
+
+		assert.NotNil(t, response["errors"])
+		assert.Equal(t, 0, len(apiserverUsecase.Calls))
+	})
+}
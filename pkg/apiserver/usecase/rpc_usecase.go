@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	_apiRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/api/repository"
+	_methodRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/method/repository"
+	_modelRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/model/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/rpc"
+)
+
+var (
+	// ErrProcedureNotFound "procedure not found"
+	ErrProcedureNotFound = errors.New("procedure not found")
+	// ErrUnexpectedResponse "response is not a document"
+	ErrUnexpectedResponse = errors.New("response is not a document")
+)
+
+// RPCUsecase Interface
+type RPCUsecase interface {
+	Invoke(ctx context.Context, procedure string, codec string, body []byte) ([]byte, int, error)
+}
+
+type rpcUsecase struct {
+	apiRepo          _apiRepository.APIRepository
+	methodRepo       _methodRepository.MethodRepository
+	modelRepo        _modelRepository.ModelRepository
+	apiserverUsecase APIServerUsecase
+}
+
+// NewRPCUsecase RPCUsecaseインターフェイスを表すオブジェクトを作成します
+func NewRPCUsecase(apiRepo _apiRepository.APIRepository, methodRepo _methodRepository.MethodRepository, modelRepo _modelRepository.ModelRepository, apiserverUsecase APIServerUsecase) RPCUsecase {
+	return &rpcUsecase{
+		apiRepo:          apiRepo,
+		methodRepo:       methodRepo,
+		modelRepo:        modelRepo,
+		apiserverUsecase: apiserverUsecase,
+	}
+}
+
+// Invoke "/<パッケージ名>.<サービス名>/<RPC名>"のRPCを呼び出します
+// リクエストのメッセージをJSONのドキュメントに変換し、REST APIと同様にMethodを実行します
+// (スクリプト、mock、Webhook等もREST APIと同じように動作します)
+func (u *rpcUsecase) Invoke(ctx context.Context, procedure string, codec string, body []byte) ([]byte, int, error) {
+	api, p, err := u.findProcedure(procedure)
+	if err != nil {
+		return nil, http.StatusNotFound, err
+	}
+
+	request, err := rpc.Unmarshal(codec, body, p.Input)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	document, err := rpc.ToDocument(request)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	requestedURL := api.URL + p.Method.URL
+	var params map[string]string
+	var requestBody []byte
+	switch p.Method.Type {
+	case "POST", "PUT":
+		if requestBody, err = json.Marshal(document); err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	default:
+		// パラメータの値はURLから取得させずに渡す(値の/や..でURLが変わらないよう、URLにはエスケープして含める)
+		params = map[string]string{}
+		for _, param := range p.Params {
+			value, ok := document[param]
+			if !ok {
+				return nil, http.StatusBadRequest, fmt.Errorf("%s is required", param)
+			}
+			params[param] = fmt.Sprint(value)
+			requestedURL = strings.Replace(requestedURL, "{"+param+"}", url.PathEscape(params[param]), 1)
+		}
+	}
+	ctx = domain.WithResolvedMethod(ctx, domain.ResolvedMethod{
+		HTTPMethod: p.Method.Type,
+		URL:        requestedURL,
+		API:        api,
+		Method:     p.Method,
+		Params:     params,
+	})
+
+	response, status, err := u.apiserverUsecase.RequestDocumentServer(ctx, p.Method.Type, requestedURL, requestBody)
+	if err != nil {
+		return nil, status, err
+	}
+
+	output := map[string]interface{}{}
+	switch {
+	case p.Method.Type == "DELETE":
+	case p.List:
+		output["items"] = toPlainDocument(response)
+	default:
+		document, ok := toPlainDocument(response).(map[string]interface{})
+		if !ok {
+			return nil, http.StatusInternalServerError, ErrUnexpectedResponse
+		}
+		output = document
+	}
+
+	message, err := rpc.FromDocument(output, p.Output)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	b, err := rpc.Marshal(codec, message)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return b, http.StatusOK, nil
+}
+
+// findProcedure パッケージ名が一致するAPIから、RPCを検索します
+func (u *rpcUsecase) findProcedure(procedure string) (domain.API, *rpc.Procedure, error) {
+	// "/apicreator.users.UserService/GetUser" → "apicreator.users"
+	serviceName := strings.SplitN(strings.TrimPrefix(procedure, "/"), "/", 2)[0]
+	index := strings.LastIndex(serviceName, ".")
+	if index < 0 {
+		return domain.API{}, nil, ErrProcedureNotFound
+	}
+	pkg := serviceName[:index]
+
+	apis, err := u.apiRepo.GetAll()
+	if err != nil {
+		return domain.API{}, nil, err
+	}
+	for _, api := range apis {
+		if rpc.PackageName(api) != pkg {
+			continue
+		}
+		model, err := u.modelRepo.GetByAPIID(api.ID)
+		if err != nil {
+			continue
+		}
+		methods, err := u.methodRepo.GetListByAPIID(api.ID)
+		if err != nil {
+			continue
+		}
+		service, err := rpc.NewService(api, model, methods)
+		if err != nil {
+			continue
+		}
+		if p, ok := service.Procedure(procedure); ok {
+			return api, p, nil
+		}
+	}
+	return domain.API{}, nil, ErrProcedureNotFound
+}
@@ -0,0 +1,149 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	_apiRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/api/repository"
+	_methodRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/method/repository"
+	_modelRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/model/repository"
+	_apiserverRepository "github.com/Hajime3778/api-creator-backend/pkg/apiserver/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/script"
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/usecase"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/memory"
+	"github.com/Hajime3778/api-creator-backend/pkg/rpc"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestInvoke(t *testing.T) {
+	mockAPI := domain.API{ID: "api", Name: "users", URL: "users"}
+	mockModel := domain.Model{
+		ID:     "model",
+		APIID:  "api",
+		Name:   "User",
+		Schema: `{"type": "object", "keys": ["id"], "properties": {"id": {"type": "integer"}, "name": {"type": "string"}}}`,
+	}
+	mockMethods := []domain.Method{
+		{ID: "1", APIID: "api", Type: "GET", URL: "/{id}"},
+		{ID: "2", APIID: "api", Type: "GET", URL: "", IsArray: true},
+		{ID: "3", APIID: "api", Type: "POST", URL: ""},
+	}
+
+	newUsecase := func(apiserverUsecase *mocks.APIServerUsecase) usecase.RPCUsecase {
+		mockAPIRepo := new(mocks.APIRepository)
+		mockMethodRepo := new(mocks.MethodRepository)
+		mockModelRepo := new(mocks.ModelRepository)
+		mockAPIRepo.On("GetAll").Return([]domain.API{{ID: "other", URL: "posts"}, mockAPI}, nil)
+		mockModelRepo.On("GetByAPIID", "api").Return(mockModel, nil)
+		mockMethodRepo.On("GetListByAPIID", "api").Return(mockMethods, nil)
+		return usecase.NewRPCUsecase(mockAPIRepo, mockMethodRepo, mockModelRepo, apiserverUsecase)
+	}
+
+	t.Run("URLのパラメータを設定して取得", func(t *testing.T) {
+		apiserverUsecase := new(mocks.APIServerUsecase)
+		apiserverUsecase.On("RequestDocumentServer", "GET", "users/1", []byte(nil)).
+			Return(map[string]interface{}{"_id": "x", "id": 1, "name": "foo"}, http.StatusOK, nil).Once()
+
+		response, status, err := newUsecase(apiserverUsecase).Invoke(context.Background(), "/apicreator.users.UserService/GetUser", rpc.CodecJSON, []byte(`{"id": "1"}`))
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.JSONEq(t, `{"id": "1", "name": "foo"}`, string(response))
+		apiserverUsecase.AssertExpectations(t)
+	})
+	t.Run("一覧の取得", func(t *testing.T) {
+		apiserverUsecase := new(mocks.APIServerUsecase)
+		apiserverUsecase.On("RequestDocumentServer", "GET", "users", []byte(nil)).
+			Return([]interface{}{map[string]interface{}{"id": 1}, map[string]interface{}{"id": 2}}, http.StatusOK, nil).Once()
+
+		response, _, err := newUsecase(apiserverUsecase).Invoke(context.Background(), "/apicreator.users.UserService/ListUser", rpc.CodecJSON, nil)
+
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"items": [{"id": "1"}, {"id": "2"}]}`, string(response))
+	})
+	t.Run("作成", func(t *testing.T) {
+		apiserverUsecase := new(mocks.APIServerUsecase)
+		apiserverUsecase.On("RequestDocumentServer", "POST", "users", mock.MatchedBy(func(body []byte) bool {
+			return string(body) == `{"id":1,"name":"foo"}`
+		})).Return(map[string]interface{}{"id": 1, "name": "foo"}, http.StatusCreated, nil).Once()
+
+		_, status, err := newUsecase(apiserverUsecase).Invoke(context.Background(), "/apicreator.users.UserService/CreateUser", rpc.CodecJSON, []byte(`{"id": 1, "name": "foo"}`))
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		apiserverUsecase.AssertExpectations(t)
+	})
+	t.Run("存在しないRPC", func(t *testing.T) {
+		_, status, err := newUsecase(new(mocks.APIServerUsecase)).Invoke(context.Background(), "/apicreator.users.UserService/DeleteUser", rpc.CodecJSON, nil)
+
+		assert.Equal(t, usecase.ErrProcedureNotFound, err)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+	t.Run("パラメータがない", func(t *testing.T) {
+		_, status, err := newUsecase(new(mocks.APIServerUsecase)).Invoke(context.Background(), "/apicreator.users.UserService/GetUser", rpc.CodecJSON, []byte(`{}`))
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+}
+
+// TestInvokeParamNotParsedAsURL パラメータの値の/や、システム規定のメソッド名をURLとして解釈しないことを検証します
+func TestInvokeParamNotParsedAsURL(t *testing.T) {
+	store, err := memory.NewStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Update(func(tables *memory.Tables) error {
+		tables.APIs = append(tables.APIs, domain.API{ID: "api", Name: "users", URL: "users"})
+		tables.Models = append(tables.Models, domain.Model{
+			ID:             "model",
+			APIID:          "api",
+			Name:           "User",
+			Schema:         `{"type": "object", "keys": ["id"], "properties": {"id": {"type": "string"}, "name": {"type": "string"}}}`,
+			CollectionName: "users",
+		})
+		tables.Methods = append(tables.Methods,
+			domain.Method{ID: "1", APIID: "api", Type: "GET", URL: "/{id}"},
+			domain.Method{ID: "2", APIID: "api", Type: "DELETE", URL: "/{id}"},
+		)
+		return nil
+	})
+	store.UpdateCollections(func(collections memory.Collections) error {
+		collections["users"] = []bson.M{
+			{"id": "a/b", "name": "foo"},
+			{"id": "remove-target-collection", "name": "bar"},
+			{"id": "日本語 1", "name": "baz"},
+		}
+		return nil
+	})
+
+	apiRepo := _apiRepository.NewMemoryAPIRepository(store)
+	methodRepo := _methodRepository.NewMemoryMethodRepository(store)
+	modelRepo := _modelRepository.NewMemoryModelRepository(store)
+	apiserverUsecase := usecase.NewAPIServerUsecase(apiRepo, methodRepo, modelRepo, _apiserverRepository.NewMemoryRepository(store), script.NewRunner(0, 0, 0), nil, nil, nil, 0)
+	u := usecase.NewRPCUsecase(apiRepo, methodRepo, modelRepo, apiserverUsecase)
+
+	for _, id := range []string{"a/b", "日本語 1"} {
+		body, _ := json.Marshal(map[string]string{"id": id})
+		response, status, err := u.Invoke(context.Background(), "/apicreator.users.UserService/GetUser", rpc.CodecJSON, body)
+
+		assert.NoError(t, err, id)
+		assert.Equal(t, http.StatusOK, status, id)
+		assert.Contains(t, string(response), `"name"`, id)
+	}
+
+	// コレクションは削除せず、対象のドキュメントのみ削除する
+	_, status, err := u.Invoke(context.Background(), "/apicreator.users.UserService/DeleteUser", rpc.CodecJSON, []byte(`{"id": "remove-target-collection"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	store.ViewCollections(func(collections memory.Collections) {
+		assert.Len(t, collections["users"], 2)
+	})
+}
@@ -0,0 +1,324 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	_webhookRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/webhook/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/event"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	// HeaderEvent イベントの種類を表すヘッダー
+	HeaderEvent = "X-Api-Creator-Event"
+	// HeaderDelivery WebhookDeliveryのIDを表すヘッダー(再送時も同じ値になります)
+	HeaderDelivery = "X-Api-Creator-Delivery"
+	// HeaderTimestamp 送信時刻(UNIX時間)を表すヘッダー
+	HeaderTimestamp = "X-Api-Creator-Timestamp"
+	// HeaderSignature 署名を表すヘッダー
+	HeaderSignature = "X-Api-Creator-Signature"
+)
+
+const (
+	defaultMaxAttempts   = 8
+	defaultRetryInterval = 10 * time.Second
+	defaultMaxInterval   = time.Hour
+	defaultPollInterval  = time.Second
+	defaultTimeout       = 10 * time.Second
+	defaultBatchSize     = 100
+	// claimMargin 送信中とみなす時間に、送信のタイムアウトから加える余裕
+	claimMargin = 30 * time.Second
+	// maxLogErrorLength 試行結果に記録するエラーの文字数の上限
+	maxLogErrorLength = 255
+)
+
+// SignatureTolerance Verifyで許容する、送信時刻と受信時刻の差
+// 傍受したリクエストを後から再送されても、この時間を過ぎていれば受け付けません
+const SignatureTolerance = 5 * time.Minute
+
+var (
+	// ErrInvalidSignature "invalid signature"
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrSignatureExpired "signature expired"
+	ErrSignatureExpired = errors.New("signature expired")
+)
+
+// Sign 送信時刻とペイロードから署名を作成します
+// 署名は "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + payload)) です
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type outbox struct {
+	webhookRepo _webhookRepository.WebhookRepository
+}
+
+// NewOutbox イベントを購読しているWebhookへの送信を、outboxに保存するPublisherを作成します
+func NewOutbox(webhookRepo _webhookRepository.WebhookRepository) event.Publisher {
+	return &outbox{
+		webhookRepo: webhookRepo,
+	}
+}
+
+// Publish イベントを購読しているWebhookごとに、送信待ちのWebhookDeliveryを保存します
+func (o *outbox) Publish(e domain.Event) {
+	webhooks, err := o.webhookRepo.GetListByAPIID(e.APIID)
+	if err != nil {
+		log.Println(err.Error())
+		return
+	}
+
+	payload, err := json.Marshal(e)
+	if err != nil {
+		log.Println(err.Error())
+		return
+	}
+
+	deliveries := []domain.WebhookDelivery{}
+	for _, webhook := range webhooks {
+		if !webhook.Subscribes(e.Type) {
+			continue
+		}
+		id, _ := uuid.NewRandom()
+		deliveries = append(deliveries, domain.WebhookDelivery{
+			ID:            id.String(),
+			WebhookID:     webhook.ID,
+			EventID:       e.ID,
+			EventType:     e.Type,
+			Payload:       string(payload),
+			Status:        domain.DeliveryStatusPending,
+			NextAttemptAt: e.OccurredAt,
+		})
+	}
+	if len(deliveries) == 0 {
+		return
+	}
+
+	if err := o.webhookRepo.CreateDeliveries(deliveries); err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// Options Dispatcherの設定。0の場合は既定値を使用します
+type Options struct {
+	// MaxAttempts 送信を諦めるまでの試行回数
+	MaxAttempts int
+	// RetryInterval 1回目の再送までの待ち時間。以降は2倍ずつ長くなります
+	RetryInterval time.Duration
+	// MaxInterval 再送までの待ち時間の上限
+	MaxInterval time.Duration
+	// PollInterval outboxを確認する間隔
+	PollInterval time.Duration
+	// Timeout 1回の送信のタイムアウト
+	Timeout time.Duration
+	// BatchSize 1回の確認で送信する件数の上限
+	BatchSize int
+}
+
+// Dispatcher Interface
+type Dispatcher interface {
+	Run(ctx context.Context)
+	DispatchPending() int
+}
+
+type dispatcher struct {
+	webhookRepo _webhookRepository.WebhookRepository
+	client      *http.Client
+	options     Options
+	now         func() time.Time
+}
+
+// NewDispatcher outboxのWebhookDeliveryを送信するDispatcherを作成します
+func NewDispatcher(webhookRepo _webhookRepository.WebhookRepository, options Options) Dispatcher {
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = defaultMaxAttempts
+	}
+	if options.RetryInterval <= 0 {
+		options.RetryInterval = defaultRetryInterval
+	}
+	if options.MaxInterval <= 0 {
+		options.MaxInterval = defaultMaxInterval
+	}
+	if options.PollInterval <= 0 {
+		options.PollInterval = defaultPollInterval
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = defaultTimeout
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultBatchSize
+	}
+	return &dispatcher{
+		webhookRepo: webhookRepo,
+		client:      &http.Client{Timeout: options.Timeout},
+		options:     options,
+		now:         time.Now,
+	}
+}
+
+// Run ctxがキャンセルされるまで、一定間隔でoutboxのWebhookDeliveryを送信します
+func (d *dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.options.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.DispatchPending()
+		}
+	}
+}
+
+// DispatchPending 送信時刻を過ぎたWebhookDeliveryを送信し、送信した件数を返却します
+// 複数のDispatcherが同じWebhookDeliveryを送信しないよう、送信前に送信時刻を延ばして送信する権利を得ます
+// 結果を記録する前にDispatcherが停止した場合は、延ばした送信時刻を過ぎてから再送します
+func (d *dispatcher) DispatchPending() int {
+	deliveries, err := d.webhookRepo.GetPendingDeliveries(d.now(), d.options.BatchSize)
+	if err != nil {
+		log.Println(err.Error())
+		return 0
+	}
+
+	count := 0
+	for _, delivery := range deliveries {
+		claimed, err := d.webhookRepo.ClaimDelivery(delivery, d.now().Add(d.options.Timeout+claimMargin))
+		if err != nil {
+			log.Println(err.Error())
+			continue
+		}
+		// 他のDispatcherが送信中
+		if !claimed {
+			continue
+		}
+		d.dispatch(delivery)
+		count++
+	}
+	return count
+}
+
+// dispatch WebhookDeliveryを1件送信し、結果を記録します
+func (d *dispatcher) dispatch(delivery domain.WebhookDelivery) {
+	delivery.Attempts++
+
+	logID, _ := uuid.NewRandom()
+	deliveryLog := domain.WebhookDeliveryLog{
+		ID:         logID.String(),
+		DeliveryID: delivery.ID,
+		Attempt:    delivery.Attempts,
+	}
+
+	webhook, err := d.webhookRepo.GetByID(delivery.WebhookID)
+	if err != nil {
+		if !gorm.IsRecordNotFoundError(err) {
+			log.Println(err.Error())
+			return
+		}
+		// Webhookが削除された場合は再送しない
+		delivery.Attempts = d.options.MaxAttempts
+	} else {
+		start := d.now()
+		deliveryLog.StatusCode, err = d.send(webhook, delivery)
+		deliveryLog.Duration = int(d.now().Sub(start) / time.Millisecond)
+	}
+
+	if err == nil {
+		delivery.Status = domain.DeliveryStatusSucceeded
+		delivery.LastError = ""
+	} else {
+		deliveryLog.Error = truncate(err.Error())
+		delivery.LastError = deliveryLog.Error
+		if delivery.Attempts >= d.options.MaxAttempts {
+			delivery.Status = domain.DeliveryStatusDead
+		} else {
+			delivery.NextAttemptAt = d.now().Add(d.backoff(delivery.Attempts))
+		}
+	}
+
+	if err := d.webhookRepo.UpdateDelivery(delivery, deliveryLog); err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// send 署名を付けてWebhookのURLへペイロードを送信します。2xx以外のステータスはエラーとします
+func (d *dispatcher) send(webhook domain.Webhook, delivery domain.WebhookDelivery) (int, error) {
+	payload := []byte(delivery.Payload)
+	timestamp := d.now().Unix()
+
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, payload))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+// backoff 試行回数から、次の再送までの待ち時間を計算します
+func (d *dispatcher) backoff(attempts int) time.Duration {
+	interval := d.options.RetryInterval
+	for i := 1; i < attempts; i++ {
+		interval *= 2
+		if interval >= d.options.MaxInterval {
+			return d.options.MaxInterval
+		}
+	}
+	return interval
+}
+
+// truncate エラーメッセージを記録できる長さ(文字数)に切り詰めます
+// マルチバイト文字の途中で切らないよう、文字単位で数えます
+func truncate(message string) string {
+	if utf8.RuneCountInString(message) <= maxLogErrorLength {
+		return message
+	}
+	return string([]rune(message)[:maxLogErrorLength])
+}
+
+// Verify 受信したリクエストの署名を検証します(受信側での利用を想定しています)
+// 署名が正しくても、送信時刻と現在時刻の差がSignatureToleranceを超える場合はErrSignatureExpiredを返却します
+func Verify(secret string, header http.Header, payload []byte) error {
+	timestamp, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(Sign(secret, timestamp, payload)), []byte(header.Get(HeaderSignature))) {
+		return ErrInvalidSignature
+	}
+	if diff := time.Since(time.Unix(timestamp, 0)); diff > SignatureTolerance || diff < -SignatureTolerance {
+		return ErrSignatureExpired
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package webhook_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/apiserver/webhook"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/test/mocks"
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// receiver Webhookを受信するテスト用のサーバー
+type receiver struct {
+	server   *httptest.Server
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newReceiver(status int) *receiver {
+	r := &receiver{status: status}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+		w.WriteHeader(r.status)
+	}))
+	return r
+}
+
+func newDelivery(webhookID string, attempts int) domain.WebhookDelivery {
+	id, _ := uuid.NewRandom()
+	eventID, _ := uuid.NewRandom()
+	payload, _ := json.Marshal(domain.Event{ID: eventID.String(), Type: domain.EventCreate, Key: "1"})
+	return domain.WebhookDelivery{
+		ID:        id.String(),
+		WebhookID: webhookID,
+		EventID:   eventID.String(),
+		EventType: domain.EventCreate,
+		Payload:   string(payload),
+		Status:    domain.DeliveryStatusPending,
+		Attempts:  attempts,
+	}
+}
+
+func TestPublish(t *testing.T) {
+	apiID, _ := uuid.NewRandom()
+	webhooks := []domain.Webhook{
+		{ID: "all", APIID: apiID.String()},
+		{ID: "create", APIID: apiID.String(), Events: "create"},
+		{ID: "delete", APIID: apiID.String(), Events: "update, delete"},
+		{ID: "disabled", APIID: apiID.String(), Disabled: true},
+	}
+
+	mockWebhookRepo := new(mocks.WebhookRepository)
+	mockWebhookRepo.On("GetListByAPIID", apiID.String()).Return(webhooks, nil).Once()
+	mockWebhookRepo.On("CreateDeliveries", mock.MatchedBy(func(deliveries []domain.WebhookDelivery) bool {
+		if len(deliveries) != 2 {
+			return false
+		}
+		return deliveries[0].WebhookID == "all" && deliveries[1].WebhookID == "create" &&
+			deliveries[0].Status == domain.DeliveryStatusPending && deliveries[0].EventID == "event-1"
+	})).Return(nil).Once()
+
+	outbox := webhook.NewOutbox(mockWebhookRepo)
+	outbox.Publish(domain.Event{ID: "event-1", Type: domain.EventCreate, APIID: apiID.String(), OccurredAt: time.Now()})
+
+	mockWebhookRepo.AssertExpectations(t)
+}
+
+func TestDispatchPending(t *testing.T) {
+	webhookID, _ := uuid.NewRandom()
+
+	t.Run("署名付きで送信", func(t *testing.T) {
+		r := newReceiver(http.StatusOK)
+		defer r.server.Close()
+		target := domain.Webhook{ID: webhookID.String(), URL: r.server.URL, Secret: "secret"}
+		delivery := newDelivery(target.ID, 0)
+
+		mockWebhookRepo := new(mocks.WebhookRepository)
+		mockWebhookRepo.On("GetPendingDeliveries", mock.Anything, mock.Anything).Return([]domain.WebhookDelivery{delivery}, nil).Once()
+		mockWebhookRepo.On("ClaimDelivery", delivery, mock.Anything).Return(true, nil).Once()
+		mockWebhookRepo.On("GetByID", target.ID).Return(target, nil).Once()
+		mockWebhookRepo.On("UpdateDelivery", mock.MatchedBy(func(d domain.WebhookDelivery) bool {
+			return d.Status == domain.DeliveryStatusSucceeded && d.Attempts == 1
+		}), mock.MatchedBy(func(l domain.WebhookDeliveryLog) bool {
+			return l.StatusCode == http.StatusOK && l.Attempt == 1 && l.Error == ""
+		})).Return(nil).Once()
+
+		count := webhook.NewDispatcher(mockWebhookRepo, webhook.Options{}).DispatchPending()
+
+		assert.Equal(t, 1, count)
+		assert.Len(t, r.requests, 1)
+		assert.Equal(t, delivery.Payload, string(r.bodies[0]))
+		assert.Equal(t, domain.EventCreate, r.requests[0].Header.Get(webhook.HeaderEvent))
+		assert.Equal(t, delivery.ID, r.requests[0].Header.Get(webhook.HeaderDelivery))
+		assert.NoError(t, webhook.Verify("secret", r.requests[0].Header, r.bodies[0]))
+		assert.Equal(t, webhook.ErrInvalidSignature, webhook.Verify("other", r.requests[0].Header, r.bodies[0]))
+		mockWebhookRepo.AssertExpectations(t)
+	})
+	t.Run("失敗した場合は間隔を空けて再送", func(t *testing.T) {
+		r := newReceiver(http.StatusInternalServerError)
+		defer r.server.Close()
+		target := domain.Webhook{ID: webhookID.String(), URL: r.server.URL, Secret: "secret"}
+		delivery := newDelivery(target.ID, 2)
+
+		start := time.Now()
+		mockWebhookRepo := new(mocks.WebhookRepository)
+		mockWebhookRepo.On("GetPendingDeliveries", mock.Anything, mock.Anything).Return([]domain.WebhookDelivery{delivery}, nil).Once()
+		mockWebhookRepo.On("ClaimDelivery", delivery, mock.Anything).Return(true, nil).Once()
+		mockWebhookRepo.On("GetByID", target.ID).Return(target, nil).Once()
+		mockWebhookRepo.On("UpdateDelivery", mock.MatchedBy(func(d domain.WebhookDelivery) bool {
+			// 3回目の失敗なので、次の再送は10秒 * 2 * 2 後
+			next := d.NextAttemptAt.Sub(start)
+			return d.Status == domain.DeliveryStatusPending && d.Attempts == 3 &&
+				next >= 40*time.Second && next < 41*time.Second && d.LastError != ""
+		}), mock.MatchedBy(func(l domain.WebhookDeliveryLog) bool {
+			return l.StatusCode == http.StatusInternalServerError && l.Attempt == 3
+		})).Return(nil).Once()
+
+		webhook.NewDispatcher(mockWebhookRepo, webhook.Options{RetryInterval: 10 * time.Second}).DispatchPending()
+
+		assert.Len(t, r.requests, 1)
+		mockWebhookRepo.AssertExpectations(t)
+	})
+	t.Run("再送の上限に達した場合はdead letterにする", func(t *testing.T) {
+		r := newReceiver(http.StatusBadGateway)
+		defer r.server.Close()
+		target := domain.Webhook{ID: webhookID.String(), URL: r.server.URL, Secret: "secret"}
+		delivery := newDelivery(target.ID, 2)
+
+		mockWebhookRepo := new(mocks.WebhookRepository)
+		mockWebhookRepo.On("GetPendingDeliveries", mock.Anything, mock.Anything).Return([]domain.WebhookDelivery{delivery}, nil).Once()
+		mockWebhookRepo.On("ClaimDelivery", delivery, mock.Anything).Return(true, nil).Once()
+		mockWebhookRepo.On("GetByID", target.ID).Return(target, nil).Once()
+		mockWebhookRepo.On("UpdateDelivery", mock.MatchedBy(func(d domain.WebhookDelivery) bool {
+			return d.Status == domain.DeliveryStatusDead && d.Attempts == 3
+		}), mock.Anything).Return(nil).Once()
+
+		webhook.NewDispatcher(mockWebhookRepo, webhook.Options{MaxAttempts: 3}).DispatchPending()
+
+		mockWebhookRepo.AssertExpectations(t)
+	})
+	t.Run("Webhookが削除されている場合はdead letterにする", func(t *testing.T) {
+		delivery := newDelivery(webhookID.String(), 0)
+
+		mockWebhookRepo := new(mocks.WebhookRepository)
+		mockWebhookRepo.On("GetPendingDeliveries", mock.Anything, mock.Anything).Return([]domain.WebhookDelivery{delivery}, nil).Once()
+		mockWebhookRepo.On("ClaimDelivery", delivery, mock.Anything).Return(true, nil).Once()
+		mockWebhookRepo.On("GetByID", webhookID.String()).Return(domain.Webhook{}, gorm.ErrRecordNotFound).Once()
+		mockWebhookRepo.On("UpdateDelivery", mock.MatchedBy(func(d domain.WebhookDelivery) bool {
+			return d.Status == domain.DeliveryStatusDead
+		}), mock.Anything).Return(nil).Once()
+
+		webhook.NewDispatcher(mockWebhookRepo, webhook.Options{}).DispatchPending()
+
+		mockWebhookRepo.AssertExpectations(t)
+	})
+	t.Run("他のDispatcherが送信中の場合は送信しない", func(t *testing.T) {
+		r := newReceiver(http.StatusOK)
+		defer r.server.Close()
+		delivery := newDelivery(webhookID.String(), 0)
+
+		start := time.Now()
+		mockWebhookRepo := new(mocks.WebhookRepository)
+		mockWebhookRepo.On("GetPendingDeliveries", mock.Anything, mock.Anything).Return([]domain.WebhookDelivery{delivery}, nil).Once()
+		mockWebhookRepo.On("ClaimDelivery", delivery, mock.MatchedBy(func(until time.Time) bool {
+			// 送信のタイムアウトより長く、送信中とみなす
+			return until.Sub(start) > 10*time.Second
+		})).Return(false, nil).Once()
+
+		count := webhook.NewDispatcher(mockWebhookRepo, webhook.Options{}).DispatchPending()
+
+		assert.Equal(t, 0, count)
+		assert.Len(t, r.requests, 0)
+		mockWebhookRepo.AssertExpectations(t)
+	})
+	t.Run("送信先に接続できない", func(t *testing.T) {
+		r := newReceiver(http.StatusOK)
+		r.server.Close()
+		target := domain.Webhook{ID: webhookID.String(), URL: r.server.URL, Secret: "secret"}
+		delivery := newDelivery(target.ID, 0)
+
+		mockWebhookRepo := new(mocks.WebhookRepository)
+		mockWebhookRepo.On("GetPendingDeliveries", mock.Anything, mock.Anything).Return([]domain.WebhookDelivery{delivery}, nil).Once()
+		mockWebhookRepo.On("ClaimDelivery", delivery, mock.Anything).Return(true, nil).Once()
+		mockWebhookRepo.On("GetByID", target.ID).Return(target, nil).Once()
+		mockWebhookRepo.On("UpdateDelivery", mock.MatchedBy(func(d domain.WebhookDelivery) bool {
+			return d.Status == domain.DeliveryStatusPending && d.Attempts == 1
+		}), mock.MatchedBy(func(l domain.WebhookDeliveryLog) bool {
+			return l.StatusCode == 0 && l.Error != ""
+		})).Return(nil).Once()
+
+		webhook.NewDispatcher(mockWebhookRepo, webhook.Options{}).DispatchPending()
+
+		mockWebhookRepo.AssertExpectations(t)
+	})
+	t.Run("エラーメッセージは文字単位で切り詰める", func(t *testing.T) {
+		// URLを含む、255バイトより長いマルチバイト文字のエラーになる
+		target := domain.Webhook{ID: webhookID.String(), URL: "://" + strings.Repeat("あ", 300), Secret: "secret"}
+		delivery := newDelivery(target.ID, 0)
+
+		mockWebhookRepo := new(mocks.WebhookRepository)
+		mockWebhookRepo.On("GetPendingDeliveries", mock.Anything, mock.Anything).Return([]domain.WebhookDelivery{delivery}, nil).Once()
+		mockWebhookRepo.On("ClaimDelivery", delivery, mock.Anything).Return(true, nil).Once()
+		mockWebhookRepo.On("GetByID", target.ID).Return(target, nil).Once()
+		mockWebhookRepo.On("UpdateDelivery", mock.MatchedBy(func(d domain.WebhookDelivery) bool {
+			return utf8.ValidString(d.LastError) && utf8.RuneCountInString(d.LastError) == 255
+		}), mock.MatchedBy(func(l domain.WebhookDeliveryLog) bool {
+			return utf8.ValidString(l.Error)
+		})).Return(nil).Once()
+
+		webhook.NewDispatcher(mockWebhookRepo, webhook.Options{}).DispatchPending()
+
+		mockWebhookRepo.AssertExpectations(t)
+	})
+}
+
+func TestVerify(t *testing.T) {
+	payload := []byte(`{"id": "event-1"}`)
+	header := func(timestamp time.Time, signature string) http.Header {
+		h := http.Header{}
+		h.Set(webhook.HeaderTimestamp, strconv.FormatInt(timestamp.Unix(), 10))
+		h.Set(webhook.HeaderSignature, signature)
+		return h
+	}
+	now := time.Now()
+
+	assert.NoError(t, webhook.Verify("secret", header(now, webhook.Sign("secret", now.Unix(), payload)), payload))
+	assert.Equal(t, webhook.ErrInvalidSignature, webhook.Verify("secret", header(now, webhook.Sign("other", now.Unix(), payload)), payload))
+	assert.Equal(t, webhook.ErrInvalidSignature, webhook.Verify("secret", http.Header{}, payload))
+
+	// 送信時刻から許容する時間を過ぎたものは、再送として受け付けない
+	old := now.Add(-webhook.SignatureTolerance - time.Minute)
+	assert.Equal(t, webhook.ErrSignatureExpired, webhook.Verify("secret", header(old, webhook.Sign("secret", old.Unix(), payload)), payload))
+	future := now.Add(webhook.SignatureTolerance + time.Minute)
+	assert.Equal(t, webhook.ErrSignatureExpired, webhook.Verify("secret", header(future, webhook.Sign("secret", future.Unix(), payload)), payload))
+}
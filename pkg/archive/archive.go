@@ -0,0 +1,221 @@
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+)
+
+// ManifestName アーカイブの先頭に格納するマニフェストのファイル名
+const ManifestName = "manifest.json"
+
+// ErrNoManifest "manifest.json is not found"
+var ErrNoManifest = errors.New(ManifestName + " is not found")
+
+// Writer ファイルを一時ディレクトリに書き込み、チェックサムを算出してからアーカイブにまとめます
+// マニフェストをアーカイブの先頭に格納するため、すべてのファイルを書き込んでからアーカイブを作成します
+type Writer struct {
+	dir      string
+	manifest *domain.BackupManifest
+}
+
+// NewWriter Writerを作成します。使用後はCloseで一時ディレクトリを削除する必要があります
+func NewWriter(manifest *domain.BackupManifest) (*Writer, error) {
+	dir, err := ioutil.TempDir("", "api-creator-backup-")
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{dir: dir, manifest: manifest}, nil
+}
+
+// Add ファイルを追加します。writeで書き込んだ内容のチェックサムとサイズをマニフェストに記録します
+// writeはドキュメントの件数を返却します(コレクション以外のファイルは0)
+func (w *Writer) Add(path string, write func(io.Writer) (int, error)) error {
+	file, err := os.Create(filepath.Join(w.dir, fmt.Sprintf("%d", len(w.manifest.Files))))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	counter := &countingWriter{writer: io.MultiWriter(file, hash)}
+	documents, err := write(counter)
+	if err != nil {
+		return err
+	}
+
+	w.manifest.Files = append(w.manifest.Files, domain.BackupFile{
+		Path:      path,
+		SHA256:    hex.EncodeToString(hash.Sum(nil)),
+		Size:      counter.written,
+		Documents: documents,
+	})
+	return nil
+}
+
+// WriteArchive マニフェストと追加したファイルを、tar.gzのアーカイブとしてoutに書き込みます
+func (w *Writer) WriteArchive(out io.Writer) error {
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	manifest, err := json.MarshalIndent(w.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	modTime := w.manifest.CreatedAt
+	if err := tw.WriteHeader(&tar.Header{Name: ManifestName, Mode: 0644, Size: int64(len(manifest)), ModTime: modTime}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(manifest); err != nil {
+		return err
+	}
+
+	for i, f := range w.manifest.Files {
+		if err := tw.WriteHeader(&tar.Header{Name: f.Path, Mode: 0644, Size: f.Size, ModTime: modTime}); err != nil {
+			return err
+		}
+		file, err := os.Open(filepath.Join(w.dir, fmt.Sprintf("%d", i)))
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(tw, file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// Close 一時ディレクトリを削除します
+func (w *Writer) Close() error {
+	return os.RemoveAll(w.dir)
+}
+
+// ReadManifest アーカイブの先頭のマニフェストを読み込みます(ファイルの検証は行いません)
+func ReadManifest(r io.Reader) (domain.BackupManifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return domain.BackupManifest{}, err
+	}
+	defer gz.Close()
+	return readManifest(tar.NewReader(gz))
+}
+
+func readManifest(tr *tar.Reader) (domain.BackupManifest, error) {
+	var manifest domain.BackupManifest
+	header, err := tr.Next()
+	if err == io.EOF || (err == nil && header.Name != ManifestName) {
+		return manifest, ErrNoManifest
+	} else if err != nil {
+		return manifest, err
+	}
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return manifest, fmt.Errorf("%s: %s", ManifestName, err.Error())
+	}
+	if manifest.Version != domain.BackupVersion {
+		return manifest, fmt.Errorf("unsupported backup version %d", manifest.Version)
+	}
+	return manifest, nil
+}
+
+// Walk マニフェストに記録された順にファイルをfnに渡します
+// fnが読み込まなかった残りも含めて、ファイルのチェックサムとサイズを検証し、一致しない場合はエラーを返却します
+// fnがnilの場合は検証のみ行います
+func Walk(r io.Reader, fn func(file domain.BackupFile, r io.Reader) error) (domain.BackupManifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return domain.BackupManifest{}, err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	manifest, err := readManifest(tr)
+	if err != nil {
+		return manifest, err
+	}
+
+	for _, f := range manifest.Files {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return manifest, fmt.Errorf("%s is missing", f.Path)
+		} else if err != nil {
+			return manifest, err
+		}
+		if header.Name != f.Path {
+			return manifest, fmt.Errorf("unexpected file %s (expected %s)", header.Name, f.Path)
+		}
+
+		hash := sha256.New()
+		counter := &countingWriter{writer: hash}
+		reader := io.TeeReader(tr, counter)
+		if fn != nil {
+			if err := fn(f, reader); err != nil {
+				return manifest, err
+			}
+		}
+		if _, err := io.Copy(ioutil.Discard, reader); err != nil {
+			return manifest, err
+		}
+		if counter.written != f.Size || hex.EncodeToString(hash.Sum(nil)) != f.SHA256 {
+			return manifest, fmt.Errorf("%s: checksum mismatch", f.Path)
+		}
+	}
+
+	if header, err := tr.Next(); err == nil {
+		return manifest, fmt.Errorf("unexpected file %s", header.Name)
+	} else if err != io.EOF {
+		return manifest, err
+	}
+	return manifest, nil
+}
+
+// Verify アーカイブのすべてのファイルのチェックサムを検証します
+func Verify(r io.Reader) (domain.BackupManifest, error) {
+	return Walk(r, nil)
+}
+
+// FileName バックアップのファイル名を作成します(例：20200101T000000Z-before-release.tar.gz)
+func FileName(createdAt time.Time, label string) string {
+	name := createdAt.UTC().Format("20060102T150405Z")
+	safe := make([]rune, 0, len(label))
+	for _, r := range label {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			safe = append(safe, r)
+		default:
+			safe = append(safe, '_')
+		}
+	}
+	if len(safe) > 0 {
+		name += "-" + string(safe)
+	}
+	return name + ".tar.gz"
+}
+
+// countingWriter 書き込んだバイト数を数えます
+type countingWriter struct {
+	writer  io.Writer
+	written int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.written += int64(n)
+	return n, err
+}
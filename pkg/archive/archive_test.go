@@ -0,0 +1,103 @@
+package archive_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/archive"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newArchive ファイルを2つ含むアーカイブを作成します
+func newArchive(t *testing.T) []byte {
+	manifest := &domain.BackupManifest{Version: domain.BackupVersion, Label: "test", CreatedAt: time.Now()}
+	w, err := archive.NewWriter(manifest)
+	assert.NoError(t, err)
+	defer w.Close()
+
+	assert.NoError(t, w.Add("definitions/api-1.json", func(out io.Writer) (int, error) {
+		_, err := io.WriteString(out, `{"version": 1}`)
+		return 0, err
+	}))
+	assert.NoError(t, w.Add("collections/model-1.ndjson", func(out io.Writer) (int, error) {
+		_, err := io.WriteString(out, "{\"id\":1}\n{\"id\":2}\n")
+		return 2, err
+	}))
+
+	var buf bytes.Buffer
+	assert.NoError(t, w.WriteArchive(&buf))
+	return buf.Bytes()
+}
+
+// rewrite アーカイブのpathのファイルの内容をcontentに置き換えます
+func rewrite(t *testing.T, data []byte, path string, content []byte) []byte {
+	gr, err := gzip.NewReader(bytes.NewReader(data))
+	assert.NoError(t, err)
+	tr := tar.NewReader(gr)
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		b, _ := ioutil.ReadAll(tr)
+		if header.Name == path {
+			b = content
+			header.Size = int64(len(content))
+		}
+		tw.WriteHeader(header)
+		tw.Write(b)
+	}
+	tw.Close()
+	gw.Close()
+	return buf.Bytes()
+}
+
+func TestWalk(t *testing.T) {
+	data := newArchive(t)
+
+	t.Run("test1", func(t *testing.T) {
+		contents := map[string]string{}
+		manifest, err := archive.Walk(bytes.NewReader(data), func(file domain.BackupFile, r io.Reader) error {
+			b, err := ioutil.ReadAll(r)
+			contents[file.Path] = string(b)
+			return err
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "test", manifest.Label)
+		assert.Len(t, manifest.Files, 2)
+		assert.Equal(t, 2, manifest.Files[1].Documents)
+		assert.Equal(t, "{\"id\":1}\n{\"id\":2}\n", contents["collections/model-1.ndjson"])
+	})
+	t.Run("改ざんされている", func(t *testing.T) {
+		tampered := rewrite(t, data, "collections/model-1.ndjson", []byte("{\"id\":1}\n{\"id\":3}\n"))
+
+		_, err := archive.Verify(bytes.NewReader(tampered))
+
+		assert.EqualError(t, err, "collections/model-1.ndjson: checksum mismatch")
+	})
+	t.Run("途中で切れている", func(t *testing.T) {
+		_, err := archive.Verify(bytes.NewReader(data[:len(data)-20]))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestFileName(t *testing.T) {
+	createdAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	assert.Equal(t, "20200102T030405Z.tar.gz", archive.FileName(createdAt, ""))
+	assert.Equal(t, "20200102T030405Z-before_release____x.tar.gz", archive.FileName(createdAt, "before release/../x"))
+}
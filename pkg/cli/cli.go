@@ -0,0 +1,257 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Name コマンド名
+const Name = "api-creator"
+
+// command サブコマンド
+type command struct {
+	name string
+	// usage 引数の説明
+	usage string
+	short string
+	// subcommands 子のコマンド(子のコマンドを持つ場合、runは使用しません)
+	subcommands []*command
+	run         func(c *cli, fs *flag.FlagSet, args []string) error
+	// flags コマンド固有のフラグを登録します
+	flags func(fs *flag.FlagSet)
+}
+
+// options すべてのコマンドに共通するフラグ
+type options struct {
+	configPath string
+	profile    string
+	url        string
+	output     string
+}
+
+// cli コマンドの実行に必要な状態
+type cli struct {
+	stdout     io.Writer
+	stderr     io.Writer
+	options    options
+	httpClient *http.Client
+}
+
+// usageError 引数が正しくない場合のエラー(使い方を表示します)
+type usageError struct {
+	message string
+}
+
+func (e *usageError) Error() string {
+	return e.message
+}
+
+// Run 引数(コマンド名を除く)のコマンドを実行し、終了コードを返却します
+func Run(args []string, stdout io.Writer, stderr io.Writer) int {
+	c := &cli{
+		stdout:     stdout,
+		stderr:     stderr,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}
+	return c.run(args)
+}
+
+func (c *cli) run(args []string) int {
+	fs := c.flagSet(Name)
+	fs.Usage = func() { c.printUsage(nil) }
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	args = fs.Args()
+	if len(args) == 0 {
+		c.printUsage(nil)
+		return 2
+	}
+
+	cmd := findCommand(commands(), args[0])
+	if cmd == nil {
+		fmt.Fprintf(c.stderr, "unknown command %q\n\n", args[0])
+		c.printUsage(nil)
+		return 2
+	}
+	args = args[1:]
+	path := []*command{cmd}
+
+	if cmd.subcommands != nil {
+		if len(args) == 0 {
+			c.printUsage(path)
+			return 2
+		}
+		sub := findCommand(cmd.subcommands, args[0])
+		if sub == nil {
+			fmt.Fprintf(c.stderr, "unknown command %q for %s\n\n", args[0], cmd.name)
+			c.printUsage(path)
+			return 2
+		}
+		cmd, args, path = sub, args[1:], append(path, sub)
+	}
+
+	fs = c.flagSet(Name + " " + commandName(path))
+	fs.Usage = func() { c.printUsage(path) }
+	if cmd.flags != nil {
+		cmd.flags(fs)
+	}
+	args, err := parseInterspersed(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	if err := cmd.run(c, fs, args); err != nil {
+		fmt.Fprintln(c.stderr, "Error: "+err.Error())
+		if _, ok := err.(*usageError); ok {
+			fmt.Fprintln(c.stderr)
+			c.printUsage(path)
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
+// flagSet すべてのコマンドに共通するフラグを登録したFlagSetを作成します
+// サブコマンドの後にも共通のフラグを指定できるよう、解析済みの値を既定値にします
+func (c *cli) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.StringVar(&c.options.configPath, "config", c.options.configPath, "設定ファイルのパス(既定は$"+configEnv+"、~/.api-creator.yaml)")
+	fs.StringVar(&c.options.profile, "profile", c.options.profile, "使用するプロファイル名")
+	fs.StringVar(&c.options.url, "url", c.options.url, "管理画面のAPIのURL(プロファイルより優先します)")
+	output := c.options.output
+	if output == "" {
+		output = outputTable
+	}
+	fs.StringVar(&c.options.output, "o", output, "出力形式(table, json)")
+	return fs
+}
+
+// config 設定ファイルを読み込みます
+func (c *cli) config() (*Config, string, error) {
+	path := c.options.configPath
+	if path == "" {
+		path = defaultConfigPath()
+	}
+	config, err := loadConfig(path)
+	return config, path, err
+}
+
+// client 指定されたプロファイルの管理画面のAPIのクライアントを作成します
+func (c *cli) client() (*client, error) {
+	config, _, err := c.config()
+	if err != nil {
+		return nil, err
+	}
+	profile, err := config.profile(c.options.profile)
+	if err != nil {
+		return nil, err
+	}
+	if c.options.url != "" {
+		p := *profile
+		p.URL = c.options.url
+		profile = &p
+	}
+	return newClient(profile, c.httpClient), nil
+}
+
+// print 共通のフラグで指定された形式で出力します
+func (c *cli) print(value interface{}, toTable func() *table) error {
+	return printResult(c.stdout, c.options.output, value, toTable)
+}
+
+// printUsage コマンドの使い方を出力します
+func (c *cli) printUsage(path []*command) {
+	w := c.stderr
+	if len(path) == 0 {
+		fmt.Fprintf(w, "Usage: %s [flags] <command> [args]\n\nCommands:\n", Name)
+		printCommands(w, commands(), "")
+		fmt.Fprintln(w, "\nFlags:")
+		c.flagSet(Name).PrintDefaults()
+		return
+	}
+
+	cmd := path[len(path)-1]
+	if cmd.subcommands != nil {
+		fmt.Fprintf(w, "Usage: %s %s <command> [flags] [args]\n\nCommands:\n", Name, commandName(path))
+		printCommands(w, cmd.subcommands, commandName(path)+" ")
+		return
+	}
+
+	fmt.Fprintf(w, "Usage: %s %s [flags] %s\n\n%s\n\nFlags:\n", Name, commandName(path), cmd.usage, cmd.short)
+	fs := c.flagSet("")
+	if cmd.flags != nil {
+		cmd.flags(fs)
+	}
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+}
+
+func printCommands(w io.Writer, cmds []*command, prefix string) {
+	for _, cmd := range cmds {
+		if cmd.subcommands != nil {
+			printCommands(w, cmd.subcommands, prefix+cmd.name+" ")
+			continue
+		}
+		name := strings.TrimSpace(prefix + cmd.name + " " + cmd.usage)
+		fmt.Fprintf(w, "  %-48s %s\n", name, cmd.short)
+	}
+}
+
+func findCommand(cmds []*command, name string) *command {
+	for _, cmd := range cmds {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+func commandName(path []*command) string {
+	names := make([]string, len(path))
+	for i, cmd := range path {
+		names[i] = cmd.name
+	}
+	return strings.Join(names, " ")
+}
+
+// parseInterspersed 引数の後に指定されたフラグも解析し、フラグ以外の引数を返却します
+// ("--"以降はすべて引数として扱います)
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		if args[0] == "--" {
+			return append(positional, args[1:]...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// requireArgs 引数の数を検証します
+func requireArgs(args []string, n int, usage string) error {
+	if len(args) != n {
+		return &usageError{message: fmt.Sprintf("expected arguments: %s", usage)}
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// runCLI テスト用の設定ファイルでコマンドを実行します
+func runCLI(t *testing.T, configPath string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := Run(append([]string{"-config", configPath}, args...), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "api-creator-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func TestAPIs(t *testing.T) {
+	var header string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("Authorization")
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v1/apis":
+			json.NewEncoder(w).Encode([]domain.API{{ID: "api-1", Name: "users", URL: "v1/users"}})
+		case "GET /api/v1/apis/missing":
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(domain.ErrorResponse{Error: "record not found"})
+		default:
+			w.WriteHeader(http.StatusNotImplemented)
+		}
+	}))
+	defer server.Close()
+
+	configPath := filepath.Join(tempDir(t), "config.yaml")
+	code, _, _ := runCLI(t, configPath, "config", "set", "local", "-admin-url", server.URL, "-header", "Authorization: Bearer token")
+	assert.Equal(t, 0, code)
+
+	t.Run("table", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, configPath, "apis", "list")
+		assert.Equal(t, 0, code)
+		lines := strings.Split(strings.TrimSpace(stdout), "\n")
+		assert.Len(t, lines, 2)
+		assert.True(t, strings.HasPrefix(lines[0], "ID"))
+		assert.Contains(t, lines[1], "v1/users")
+		assert.Equal(t, "Bearer token", header)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, configPath, "apis", "list", "-o", "json")
+		assert.Equal(t, 0, code)
+		var apis []domain.API
+		assert.NoError(t, json.Unmarshal([]byte(stdout), &apis))
+		assert.Equal(t, "api-1", apis[0].ID)
+	})
+
+	t.Run("api error", func(t *testing.T) {
+		code, _, stderr := runCLI(t, configPath, "apis", "get", "missing")
+		assert.Equal(t, 1, code)
+		assert.Contains(t, stderr, "404 Not Found: record not found")
+	})
+
+	t.Run("usage error", func(t *testing.T) {
+		code, _, stderr := runCLI(t, configPath, "apis", "get")
+		assert.Equal(t, 2, code)
+		assert.Contains(t, stderr, "Usage: api-creator apis get")
+	})
+
+	t.Run("unknown command", func(t *testing.T) {
+		code, _, stderr := runCLI(t, configPath, "unknown")
+		assert.Equal(t, 2, code)
+		assert.Contains(t, stderr, `unknown command "unknown"`)
+	})
+}
+
+func TestConfig(t *testing.T) {
+	configPath := filepath.Join(tempDir(t), "config.yaml")
+
+	assert.Equal(t, 0, func() int { c, _, _ := runCLI(t, configPath, "config", "set", "local"); return c }())
+	assert.Equal(t, 0, func() int {
+		c, _, _ := runCLI(t, configPath, "config", "set", "prod", "-admin-url", "https://admin.example.com")
+		return c
+	}())
+
+	config, err := loadConfig(configPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "local", config.CurrentProfile)
+	assert.Equal(t, defaultAdminURL, config.Profiles["local"].URL)
+	assert.Equal(t, "https://admin.example.com", config.Profiles["prod"].URL)
+
+	info, err := os.Stat(configPath)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	code, _, _ := runCLI(t, configPath, "config", "use", "prod")
+	assert.Equal(t, 0, code)
+	code, stdout, _ := runCLI(t, configPath, "config", "list")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, "*        prod")
+
+	code, _, stderr := runCLI(t, configPath, "config", "use", "missing")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "profile missing is not found")
+
+	code, _, _ = runCLI(t, configPath, "config", "delete", "prod")
+	assert.Equal(t, 0, code)
+	config, _ = loadConfig(configPath)
+	assert.Equal(t, "", config.CurrentProfile)
+	assert.NotContains(t, config.Profiles, "prod")
+}
+
+func TestValidateModel(t *testing.T) {
+	dir := tempDir(t)
+	schema := filepath.Join(dir, "schema.yaml")
+	valid := filepath.Join(dir, "valid.ndjson")
+	invalid := filepath.Join(dir, "invalid.json")
+	ioutil.WriteFile(schema, []byte("type: object\nkeys: [name]\nproperties:\n  name:\n    type: string\nrequired: [name]\n"), 0644)
+	ioutil.WriteFile(valid, []byte("{\"name\":\"a\"}\n\n{\"name\":\"b\"}\n"), 0644)
+	ioutil.WriteFile(invalid, []byte(`[{"name":1}]`), 0644)
+	configPath := filepath.Join(dir, "config.yaml")
+
+	code, stdout, _ := runCLI(t, configPath, "models", "validate", "-schema", schema, valid)
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, "valid.ndjson[1]")
+
+	code, stdout, stderr := runCLI(t, configPath, "models", "validate", "-schema", schema, valid, invalid)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stdout, "invalid")
+	assert.Contains(t, stderr, "validation failed")
+
+	code, _, _ = runCLI(t, configPath, "models", "validate", valid)
+	assert.Equal(t, 2, code)
+}
+
+func TestImportData(t *testing.T) {
+	var fixture domain.Fixture
+	deleted := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v1/apis/api-1/model":
+			json.NewEncoder(w).Encode(domain.Model{ID: "model-1", Schema: `{"type":"object"}`})
+		case "POST /api/v1/fixtures":
+			json.NewDecoder(r.Body).Decode(&fixture)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(domain.CreatedResponse{ID: "fixture-1"})
+		case "POST /api/v1/fixtures/fixture-1/load":
+			json.NewEncoder(w).Encode(domain.FixtureLoadResult{Created: 2})
+		case "DELETE /api/v1/fixtures/fixture-1":
+			deleted = true
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotImplemented)
+		}
+	}))
+	defer server.Close()
+
+	dir := tempDir(t)
+	file := filepath.Join(dir, "users.ndjson")
+	ioutil.WriteFile(file, []byte("{\"name\":\"a\"}\n{\"name\":\"b\"}\n"), 0644)
+
+	code, stdout, stderr := runCLI(t, filepath.Join(dir, "config.yaml"), "-url", server.URL, "data", "import", "api-1", file, "-o", "json")
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, "model-1", fixture.ModelID)
+	assert.JSONEq(t, `[{"name":"a"},{"name":"b"}]`, fixture.Data)
+	assert.True(t, deleted)
+
+	var result domain.FixtureLoadResult
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &result))
+	assert.Equal(t, 2, result.Created)
+}
+
+func TestCompletion(t *testing.T) {
+	configPath := filepath.Join(tempDir(t), "config.yaml")
+
+	code, stdout, _ := runCLI(t, configPath, "completion", "bash")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, "complete -F _api_creator api-creator")
+	assert.Contains(t, stdout, `"apis create"*) candidates="-defaults -description -name -path -stream`)
+
+	code, stdout, _ = runCLI(t, configPath, "completion", "zsh")
+	assert.Equal(t, 0, code)
+	assert.True(t, strings.HasPrefix(stdout, "autoload -U +X bashcompinit"))
+
+	code, _, _ = runCLI(t, configPath, "completion", "fish")
+	assert.Equal(t, 2, code)
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+)
+
+// apiPrefix 管理画面のAPIのパス
+const apiPrefix = "/api/v1"
+
+// client 管理画面のAPIのクライアント
+type client struct {
+	baseURL    string
+	headers    map[string]string
+	httpClient *http.Client
+}
+
+// newClient プロファイルからclientを作成します
+func newClient(profile *Profile, httpClient *http.Client) *client {
+	return &client{
+		baseURL:    strings.TrimRight(profile.URL, "/") + apiPrefix,
+		headers:    profile.Headers,
+		httpClient: httpClient,
+	}
+}
+
+// apiError 管理画面のAPIがエラーのステータスを返却した場合のエラー
+type apiError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *apiError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// get GETのリクエストを送信し、レスポンスのJSONをresultに読み込みます
+func (c *client) get(path string, result interface{}) error {
+	return c.do(http.MethodGet, path, nil, result)
+}
+
+// post POSTのリクエストを送信し、レスポンスのJSONをresultに読み込みます
+func (c *client) post(path string, body interface{}, result interface{}) error {
+	return c.do(http.MethodPost, path, body, result)
+}
+
+// delete DELETEのリクエストを送信します
+func (c *client) delete(path string) error {
+	return c.do(http.MethodDelete, path, nil, nil)
+}
+
+// do リクエストを送信し、レスポンスのJSONをresultに読み込みます
+func (c *client) do(method string, path string, body interface{}, result interface{}) error {
+	b, err := c.raw(method, path, body)
+	if err != nil {
+		return err
+	}
+	if result == nil || len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, result)
+}
+
+// raw リクエストを送信し、レスポンスのボディを返却します
+func (c *client) raw(method string, path string, body interface{}) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range c.headers {
+		req.Header.Set(key, value)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode >= http.StatusBadRequest {
+		apiErr := &apiError{StatusCode: res.StatusCode}
+		var errorResponse domain.ErrorResponse
+		if json.Unmarshal(b, &errorResponse) == nil {
+			apiErr.Message = errorResponse.Error
+		}
+		return nil, apiErr
+	}
+	return b, nil
+}
@@ -0,0 +1,538 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/yamljson"
+)
+
+// commands すべてのコマンド
+func commands() []*command {
+	return []*command{
+		{
+			name: "apis",
+			subcommands: []*command{
+				{name: "list", short: "APIの一覧を表示します", run: listAPIs},
+				{name: "get", usage: "<id>", short: "APIを表示します", run: getAPI},
+				{name: "create", short: "APIを作成します", run: createAPI, flags: createAPIFlags},
+				{name: "delete", usage: "<id>", short: "APIを削除します(Method、Modelも削除します)", run: deleteAPI},
+			},
+		},
+		{
+			name: "methods",
+			subcommands: []*command{
+				{name: "create-defaults", usage: "<api-id>", short: "APIにデフォルトのCRUDのMethodを作成します", run: createDefaultMethods},
+			},
+		},
+		{
+			name: "models",
+			subcommands: []*command{
+				{name: "validate", usage: "[document-file...]", short: "ModelのJSON Schemaと、ドキュメントがSchemaに則っているか検証します", run: validateModel, flags: validateModelFlags},
+			},
+		},
+		{
+			name: "openapi",
+			subcommands: []*command{
+				{name: "export", short: "OpenAPIのドキュメントを出力します", run: exportOpenAPI, flags: exportOpenAPIFlags},
+			},
+		},
+		{
+			name: "data",
+			subcommands: []*command{
+				{name: "import", usage: "<api-id> <file>", short: "JSON配列またはNDJSONのファイルのドキュメントを、APIのコレクションに投入します", run: importData},
+			},
+		},
+		{
+			name: "config",
+			subcommands: []*command{
+				{name: "list", short: "プロファイルの一覧を表示します", run: listProfiles},
+				{name: "set", usage: "<profile>", short: "プロファイルを作成、更新します", run: setProfile, flags: setProfileFlags},
+				{name: "use", usage: "<profile>", short: "既定のプロファイルを変更します", run: useProfile},
+				{name: "delete", usage: "<profile>", short: "プロファイルを削除します", run: deleteProfile},
+			},
+		},
+		{name: "completion", usage: "<bash|zsh>", short: "シェルの補完スクリプトを出力します", run: completion},
+	}
+}
+
+// apiTable APIの一覧を表にします
+func apiTable(apis ...domain.API) *table {
+	t := &table{header: []string{"ID", "NAME", "URL", "STREAM", "DESCRIPTION"}}
+	for _, api := range apis {
+		t.addRow(api.ID, api.Name, api.URL, api.StreamEnabled, api.Description)
+	}
+	return t
+}
+
+func listAPIs(c *cli, fs *flag.FlagSet, args []string) error {
+	if err := requireArgs(args, 0, ""); err != nil {
+		return err
+	}
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	var apis []domain.API
+	if err := client.get("/apis", &apis); err != nil {
+		return err
+	}
+	return c.print(apis, func() *table { return apiTable(apis...) })
+}
+
+func getAPI(c *cli, fs *flag.FlagSet, args []string) error {
+	if err := requireArgs(args, 1, "<id>"); err != nil {
+		return err
+	}
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	var api domain.API
+	if err := client.get("/apis/"+url.PathEscape(args[0]), &api); err != nil {
+		return err
+	}
+	return c.print(api, func() *table { return apiTable(api) })
+}
+
+func createAPIFlags(fs *flag.FlagSet) {
+	fs.String("name", "", "API名")
+	fs.String("path", "", "APIのURL(例：v1/users)")
+	fs.String("description", "", "説明")
+	fs.Bool("stream", false, "ドキュメントの変更イベントを配信する")
+	fs.Bool("defaults", false, "作成後にデフォルトのCRUDのMethodを作成する(Modelが必要です)")
+}
+
+func createAPI(c *cli, fs *flag.FlagSet, args []string) error {
+	if err := requireArgs(args, 0, ""); err != nil {
+		return err
+	}
+	api := domain.API{
+		Name:          flagString(fs, "name"),
+		URL:           flagString(fs, "path"),
+		Description:   flagString(fs, "description"),
+		StreamEnabled: flagBool(fs, "stream"),
+	}
+	if api.Name == "" || api.URL == "" {
+		return &usageError{message: "-name and -path are required"}
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+	var created domain.CreatedResponse
+	if err := client.post("/apis", api, &created); err != nil {
+		return err
+	}
+	if flagBool(fs, "defaults") {
+		if err := client.post("/apis/"+url.PathEscape(created.ID)+"/create-default-methods", nil, nil); err != nil {
+			return err
+		}
+	}
+	return c.print(created, func() *table {
+		t := &table{header: []string{"ID"}}
+		t.addRow(created.ID)
+		return t
+	})
+}
+
+func deleteAPI(c *cli, fs *flag.FlagSet, args []string) error {
+	if err := requireArgs(args, 1, "<id>"); err != nil {
+		return err
+	}
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+	if err := client.delete("/apis/" + url.PathEscape(args[0])); err != nil {
+		return err
+	}
+	deleted := domain.CreatedResponse{ID: args[0]}
+	return c.print(deleted, func() *table {
+		t := &table{header: []string{"DELETED"}}
+		t.addRow(args[0])
+		return t
+	})
+}
+
+func createDefaultMethods(c *cli, fs *flag.FlagSet, args []string) error {
+	if err := requireArgs(args, 1, "<api-id>"); err != nil {
+		return err
+	}
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	var methods []domain.Method
+	if err := client.post("/apis/"+url.PathEscape(args[0])+"/create-default-methods", nil, &methods); err != nil {
+		return err
+	}
+	return c.print(methods, func() *table {
+		t := &table{header: []string{"ID", "TYPE", "URL", "ARRAY", "DESCRIPTION"}}
+		for _, method := range methods {
+			t.addRow(method.ID, method.Type, method.URL, method.IsArray, method.Description)
+		}
+		return t
+	})
+}
+
+func validateModelFlags(fs *flag.FlagSet) {
+	fs.String("schema", "", "JSON Schemaのファイル(JSONまたはYAML)")
+	fs.String("api", "", "検証に使用するModelのAPIのID(-schemaの代わりに、管理画面のModelを使用します)")
+}
+
+// validationResult ファイルごとの検証結果
+type validationResult struct {
+	File  string `json:"file"`
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
+
+func validateModel(c *cli, fs *flag.FlagSet, args []string) error {
+	schemaFile, apiID := flagString(fs, "schema"), flagString(fs, "api")
+	if (schemaFile == "") == (apiID == "") {
+		return &usageError{message: "either -schema or -api is required"}
+	}
+
+	var model domain.Model
+	source := schemaFile
+	if apiID != "" {
+		client, err := c.client()
+		if err != nil {
+			return err
+		}
+		if err := client.get("/apis/"+url.PathEscape(apiID)+"/model", &model); err != nil {
+			return err
+		}
+		source = "model " + model.Name
+	} else {
+		schema, err := readJSON(schemaFile)
+		if err != nil {
+			return err
+		}
+		model.Schema = string(schema)
+	}
+
+	results := []validationResult{}
+	valid := true
+	add := func(file string, err error) {
+		result := validationResult{File: file, Valid: err == nil}
+		if err != nil {
+			result.Error = err.Error()
+			valid = false
+		}
+		results = append(results, result)
+	}
+
+	add(source, model.ValidateSchema())
+	if valid {
+		for _, file := range args {
+			documents, err := readDocuments(file)
+			if err != nil {
+				add(file, err)
+				continue
+			}
+			for i, document := range documents {
+				name := file
+				if len(documents) > 1 {
+					name = fmt.Sprintf("%s[%d]", file, i)
+				}
+				add(name, model.ValidateDocument(document))
+			}
+		}
+	}
+
+	if err := c.print(results, func() *table {
+		t := &table{header: []string{"FILE", "RESULT", "ERROR"}}
+		for _, result := range results {
+			status := "ok"
+			if !result.Valid {
+				status = "invalid"
+			}
+			t.addRow(result.File, status, result.Error)
+		}
+		return t
+	}); err != nil {
+		return err
+	}
+	if !valid {
+		return errors.New("validation failed")
+	}
+	return nil
+}
+
+func exportOpenAPIFlags(fs *flag.FlagSet) {
+	fs.String("api", "", "出力するAPIのID(未指定の場合はすべてのAPI)")
+	fs.String("format", "yaml", "ドキュメントの形式(json, yaml)")
+	fs.String("out", "", "出力するファイル(未指定の場合は標準出力)")
+}
+
+func exportOpenAPI(c *cli, fs *flag.FlagSet, args []string) error {
+	if err := requireArgs(args, 0, ""); err != nil {
+		return err
+	}
+	format := flagString(fs, "format")
+	if format != "json" && format != "yaml" {
+		return &usageError{message: "-format must be json or yaml"}
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+	path := "/openapi"
+	if apiID := flagString(fs, "api"); apiID != "" {
+		path = "/apis/" + url.PathEscape(apiID) + "/openapi"
+	}
+	doc, err := client.raw("GET", path+"?format="+format, nil)
+	if err != nil {
+		return err
+	}
+	if format == "json" {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, doc, "", "  "); err == nil {
+			buf.WriteString("\n")
+			doc = buf.Bytes()
+		}
+	}
+
+	if out := flagString(fs, "out"); out != "" {
+		return ioutil.WriteFile(out, doc, 0644)
+	}
+	_, err = c.stdout.Write(doc)
+	return err
+}
+
+func importData(c *cli, fs *flag.FlagSet, args []string) error {
+	if err := requireArgs(args, 2, "<api-id> <file>"); err != nil {
+		return err
+	}
+	documents, err := readDocuments(args[1])
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(documents)
+	if err != nil {
+		return err
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+	var model domain.Model
+	if err := client.get("/apis/"+url.PathEscape(args[0])+"/model", &model); err != nil {
+		return err
+	}
+
+	// Fixtureとして登録して投入する(Schemaの検証とKeyによる上書きは管理画面で行う)
+	fixture := domain.Fixture{
+		ModelID: model.ID,
+		Name:    fmt.Sprintf("%s import %s", Name, time.Now().Format(time.RFC3339)),
+		Data:    string(data),
+	}
+	var created domain.CreatedResponse
+	if err := client.post("/fixtures", fixture, &created); err != nil {
+		return err
+	}
+	var result domain.FixtureLoadResult
+	loadErr := client.post("/fixtures/"+url.PathEscape(created.ID)+"/load", nil, &result)
+	// 投入に使用したFixtureは残さない
+	if err := client.delete("/fixtures/" + url.PathEscape(created.ID)); err != nil && loadErr == nil {
+		loadErr = err
+	}
+	if loadErr != nil {
+		return loadErr
+	}
+
+	return c.print(result, func() *table {
+		t := &table{header: []string{"CREATED", "UPDATED"}}
+		t.addRow(result.Created, result.Updated)
+		return t
+	})
+}
+
+func listProfiles(c *cli, fs *flag.FlagSet, args []string) error {
+	if err := requireArgs(args, 0, ""); err != nil {
+		return err
+	}
+	config, _, err := c.config()
+	if err != nil {
+		return err
+	}
+	return c.print(config, func() *table {
+		t := &table{header: []string{"CURRENT", "NAME", "URL"}}
+		for _, name := range config.profileNames() {
+			current := ""
+			if name == config.CurrentProfile {
+				current = "*"
+			}
+			t.addRow(current, name, config.Profiles[name].URL)
+		}
+		return t
+	})
+}
+
+// headerFlag "名前: 値"の形式で複数指定できるヘッダーのフラグ
+type headerFlag map[string]string
+
+func (h headerFlag) String() string {
+	return ""
+}
+
+func (h headerFlag) Set(value string) error {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		return fmt.Errorf("header must be \"Name: value\"")
+	}
+	h[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	return nil
+}
+
+func setProfileFlags(fs *flag.FlagSet) {
+	fs.String("admin-url", "", "管理画面のAPIのURL(例："+defaultAdminURL+")")
+	fs.Var(headerFlag{}, "header", "すべてのリクエストに付与するヘッダー(\"Name: value\"、複数指定可)")
+}
+
+func setProfile(c *cli, fs *flag.FlagSet, args []string) error {
+	if err := requireArgs(args, 1, "<profile>"); err != nil {
+		return err
+	}
+	config, path, err := c.config()
+	if err != nil {
+		return err
+	}
+
+	profile, ok := config.Profiles[args[0]]
+	if !ok {
+		profile = &Profile{URL: defaultAdminURL}
+		config.Profiles[args[0]] = profile
+	}
+	if adminURL := flagString(fs, "admin-url"); adminURL != "" {
+		profile.URL = adminURL
+	}
+	if headers := fs.Lookup("header").Value.(headerFlag); len(headers) > 0 {
+		if profile.Headers == nil {
+			profile.Headers = map[string]string{}
+		}
+		for key, value := range headers {
+			profile.Headers[key] = value
+		}
+	}
+	if config.CurrentProfile == "" {
+		config.CurrentProfile = args[0]
+	}
+	return config.save(path)
+}
+
+func useProfile(c *cli, fs *flag.FlagSet, args []string) error {
+	if err := requireArgs(args, 1, "<profile>"); err != nil {
+		return err
+	}
+	config, path, err := c.config()
+	if err != nil {
+		return err
+	}
+	if _, err := config.profile(args[0]); err != nil {
+		return err
+	}
+	config.CurrentProfile = args[0]
+	return config.save(path)
+}
+
+func deleteProfile(c *cli, fs *flag.FlagSet, args []string) error {
+	if err := requireArgs(args, 1, "<profile>"); err != nil {
+		return err
+	}
+	config, path, err := c.config()
+	if err != nil {
+		return err
+	}
+	if _, err := config.profile(args[0]); err != nil {
+		return err
+	}
+	delete(config.Profiles, args[0])
+	if config.CurrentProfile == args[0] {
+		config.CurrentProfile = ""
+	}
+	return config.save(path)
+}
+
+func flagString(fs *flag.FlagSet, name string) string {
+	return fs.Lookup(name).Value.String()
+}
+
+func flagBool(fs *flag.FlagSet, name string) bool {
+	return fs.Lookup(name).Value.String() == "true"
+}
+
+// readJSON JSONまたはYAML(拡張子が.yaml、.yml)のファイルを、JSONとして読み込みます
+func readJSON(file string) ([]byte, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if ext := filepath.Ext(file); ext == ".yaml" || ext == ".yml" {
+		return yamljson.ToJSON(data)
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("%s: invalid json", file)
+	}
+	return data, nil
+}
+
+// readDocuments ファイルからドキュメントを読み込みます
+// ファイルはJSONの配列、1つのオブジェクト、NDJSON(拡張子が.ndjson、.jsonl)のいずれかです
+func readDocuments(file string) ([]json.RawMessage, error) {
+	if ext := filepath.Ext(file); ext == ".ndjson" || ext == ".jsonl" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		documents := []json.RawMessage{}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			text := bytes.TrimSpace(scanner.Bytes())
+			if len(text) == 0 {
+				continue
+			}
+			if !json.Valid(text) {
+				return nil, fmt.Errorf("%s:%d: invalid json", file, line)
+			}
+			documents = append(documents, json.RawMessage(append([]byte{}, text...)))
+		}
+		return documents, scanner.Err()
+	}
+
+	data, err := readJSON(file)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var documents []json.RawMessage
+		if err := json.Unmarshal(data, &documents); err != nil {
+			return nil, fmt.Errorf("%s: %s", file, err.Error())
+		}
+		return documents, nil
+	}
+	return []json.RawMessage{data}, nil
+}
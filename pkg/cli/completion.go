@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+func completion(c *cli, fs *flag.FlagSet, args []string) error {
+	if err := requireArgs(args, 1, "<bash|zsh>"); err != nil {
+		return err
+	}
+	switch args[0] {
+	case "bash":
+		writeBashCompletion(c.stdout, commands())
+	case "zsh":
+		// zshはbashcompinitでbashの補完スクリプトを使用する
+		fmt.Fprintln(c.stdout, "autoload -U +X bashcompinit && bashcompinit")
+		writeBashCompletion(c.stdout, commands())
+	default:
+		return &usageError{message: "shell must be bash or zsh"}
+	}
+	return nil
+}
+
+// writeBashCompletion コマンドの構成からbashの補完スクリプトを出力します
+func writeBashCompletion(w io.Writer, cmds []*command) {
+	fn := "_" + strings.Replace(Name, "-", "_", -1)
+	globalFlags := flagNames(nil)
+
+	fmt.Fprintf(w, "%s() {\n", fn)
+	fmt.Fprintln(w, `    local cur="${COMP_WORDS[COMP_CWORD]}"`)
+	fmt.Fprintln(w, `    local words=()`)
+	fmt.Fprintln(w, `    local i`)
+	fmt.Fprintln(w, `    for ((i = 1; i < COMP_CWORD; i++)); do`)
+	fmt.Fprintln(w, `        [[ "${COMP_WORDS[i]}" != -* ]] && words+=("${COMP_WORDS[i]}")`)
+	fmt.Fprintln(w, `    done`)
+	fmt.Fprintln(w, `    local candidates`)
+	fmt.Fprintln(w, `    case "${words[*]}" in`)
+	fmt.Fprintf(w, "        \"\") candidates=%q ;;\n", strings.Join(append(commandNames(cmds), globalFlags...), " "))
+	for _, cmd := range cmds {
+		if cmd.subcommands == nil {
+			fmt.Fprintf(w, "        %q*) candidates=%q ;;\n", cmd.name, strings.Join(append(flagNames(cmd), globalFlags...), " "))
+			continue
+		}
+		for _, sub := range cmd.subcommands {
+			fmt.Fprintf(w, "        %q*) candidates=%q ;;\n", cmd.name+" "+sub.name, strings.Join(append(flagNames(sub), globalFlags...), " "))
+		}
+		fmt.Fprintf(w, "        %q) candidates=%q ;;\n", cmd.name, strings.Join(commandNames(cmd.subcommands), " "))
+	}
+	fmt.Fprintln(w, `    esac`)
+	fmt.Fprintln(w, `    COMPREPLY=($(compgen -W "${candidates}" -- "${cur}"))`)
+	fmt.Fprintln(w, `    [[ ${#COMPREPLY[@]} -eq 0 ]] && COMPREPLY=($(compgen -f -- "${cur}"))`)
+	fmt.Fprintln(w, "}")
+	fmt.Fprintf(w, "complete -F %s %s\n", fn, Name)
+}
+
+func commandNames(cmds []*command) []string {
+	names := make([]string, len(cmds))
+	for i, cmd := range cmds {
+		names[i] = cmd.name
+	}
+	return names
+}
+
+// flagNames コマンド固有のフラグ名を返却します。cmdがnilの場合は共通のフラグ名を返却します
+func flagNames(cmd *command) []string {
+	fs := flag.NewFlagSet("", flag.ContinueOnError)
+	if cmd == nil {
+		(&cli{}).flagSet("").VisitAll(func(f *flag.Flag) { fs.Var(f.Value, f.Name, f.Usage) })
+	} else if cmd.flags != nil {
+		cmd.flags(fs)
+	}
+	names := []string{}
+	fs.VisitAll(func(f *flag.Flag) { names = append(names, "-"+f.Name) })
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/yamljson"
+)
+
+// defaultAdminURL プロファイルが未設定の場合に接続する管理画面のAPIのURL
+const defaultAdminURL = "http://localhost:4000"
+
+// configEnv 設定ファイルのパスを指定する環境変数
+const configEnv = "API_CREATOR_CONFIG"
+
+// Config 接続先の管理画面のAPIごとのプロファイル
+type Config struct {
+	// CurrentProfile 指定がない場合に使用するプロファイル名
+	CurrentProfile string              `json:"currentProfile"`
+	Profiles       map[string]*Profile `json:"profiles"`
+}
+
+// Profile 管理画面のAPIの接続先
+type Profile struct {
+	URL string `json:"url"`
+	// Headers すべてのリクエストに付与するヘッダー(認証情報など)
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// defaultConfigPath 設定ファイルのパスを返却します(環境変数、ホームディレクトリの.api-creator.yamlの順)
+func defaultConfigPath() string {
+	if path := os.Getenv(configEnv); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".api-creator.yaml"
+	}
+	return filepath.Join(home, ".api-creator.yaml")
+}
+
+// loadConfig 設定ファイルを読み込みます(存在しない場合は空の設定)
+func loadConfig(path string) (*Config, error) {
+	config := &Config{Profiles: map[string]*Profile{}}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return config, nil
+		}
+		return nil, err
+	}
+	if err := yamljson.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err.Error())
+	}
+	if config.Profiles == nil {
+		config.Profiles = map[string]*Profile{}
+	}
+	return config, nil
+}
+
+// save 設定ファイルに書き込みます(認証情報を含むため、所有者のみ読み書きできるようにします)
+func (c *Config) save(path string) error {
+	data, err := yamljson.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// profile 名前のプロファイルを返却します。名前が空の場合は現在のプロファイルを返却します
+func (c *Config) profile(name string) (*Profile, error) {
+	if name == "" {
+		name = c.CurrentProfile
+	}
+	if name == "" {
+		return &Profile{URL: defaultAdminURL}, nil
+	}
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile %s is not found", name)
+	}
+	return profile, nil
+}
+
+// profileNames プロファイル名を名前順に返却します
+func (c *Config) profileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// table 表形式で出力する内容
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+// addRow 行を追加します
+func (t *table) addRow(values ...interface{}) {
+	row := make([]string, len(values))
+	for i, value := range values {
+		// 表が崩れないよう、改行とタブは空白にする
+		row[i] = strings.NewReplacer("\n", " ", "\t", " ").Replace(fmt.Sprint(value))
+	}
+	t.rows = append(t.rows, row)
+}
+
+// write 表を列を揃えて出力します
+func (t *table) write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(t.header, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// printResult outputの形式でvalueを出力します。表形式の場合はtoTableで変換した表を出力します
+func printResult(w io.Writer, output string, value interface{}, toTable func() *table) error {
+	switch output {
+	case outputJSON:
+		b, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	case outputTable:
+		return toTable().write(w)
+	default:
+		return fmt.Errorf("output must be %s or %s", outputTable, outputJSON)
+	}
+}
@@ -0,0 +1,347 @@
+package dataio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+)
+
+// maxLineSize NDJSONの1行の最大サイズ
+const maxLineSize = 16 * 1024 * 1024
+
+// ErrUnsupportedFormat "format must be csv, ndjson or json"
+var ErrUnsupportedFormat = errors.New("format must be " + domain.DataFormatCSV + ", " + domain.DataFormatNDJSON + " or " + domain.DataFormatJSON)
+
+// ValidateFormat 対応している形式か検証します
+func ValidateFormat(format string) error {
+	switch format {
+	case domain.DataFormatCSV, domain.DataFormatNDJSON, domain.DataFormatJSON:
+		return nil
+	default:
+		return ErrUnsupportedFormat
+	}
+}
+
+// RowError 1行(1ドキュメント)のみのエラー。読み込みは続けられます
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %s", e.Row, e.Err.Error())
+}
+
+// Record 読み込んだ1件のドキュメント
+type Record struct {
+	// Row CSVはヘッダーを1件目としたレコードの番号(空行は数えません)、NDJSONは行番号、JSONは配列の何件目か(1から)
+	Row int
+	// Values CSVの場合、値はすべて文字列です(空のセルは含みません)
+	Values map[string]interface{}
+}
+
+// Reader ドキュメントを1件ずつ読み込みます
+type Reader interface {
+	// Read 次のドキュメントを読み込みます。終端ではio.EOF、1件のみのエラーは*RowErrorを返却します
+	Read() (Record, error)
+}
+
+// NewReader 形式に応じたReaderを作成します
+func NewReader(format string, r io.Reader) (Reader, error) {
+	switch format {
+	case domain.DataFormatCSV:
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		cr.LazyQuotes = true
+		return &csvReader{reader: cr}, nil
+	case domain.DataFormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+		return &ndjsonReader{scanner: scanner}, nil
+	case domain.DataFormatJSON:
+		decoder := json.NewDecoder(r)
+		decoder.UseNumber()
+		return &jsonReader{decoder: decoder}, nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+type csvReader struct {
+	reader *csv.Reader
+	header []string
+	row    int
+}
+
+func (r *csvReader) Read() (Record, error) {
+	if r.header == nil {
+		header, err := r.reader.Read()
+		if err == io.EOF {
+			return Record{}, io.EOF
+		} else if err != nil {
+			return Record{}, err
+		}
+		r.row++
+		for i, column := range header {
+			header[i] = strings.TrimSpace(column)
+		}
+		// Excelが出力するUTF-8のBOMを除く
+		if len(header) > 0 {
+			header[0] = strings.TrimPrefix(header[0], "\ufeff")
+		}
+		r.header = header
+	}
+
+	for {
+		fields, err := r.reader.Read()
+		if err == io.EOF {
+			return Record{}, io.EOF
+		}
+		r.row++
+		if err != nil {
+			if _, ok := err.(*csv.ParseError); ok {
+				return Record{}, &RowError{Row: r.row, Err: err}
+			}
+			return Record{}, err
+		}
+		if len(fields) == 1 && strings.TrimSpace(fields[0]) == "" {
+			// 空行は読み飛ばす
+			continue
+		}
+		if len(fields) > len(r.header) {
+			return Record{}, &RowError{Row: r.row, Err: fmt.Errorf("expected %d columns but got %d", len(r.header), len(fields))}
+		}
+
+		values := map[string]interface{}{}
+		for i, field := range fields {
+			if field == "" || r.header[i] == "" {
+				continue
+			}
+			values[r.header[i]] = field
+		}
+		return Record{Row: r.row, Values: values}, nil
+	}
+}
+
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	row     int
+}
+
+func (r *ndjsonReader) Read() (Record, error) {
+	for r.scanner.Scan() {
+		r.row++
+		line := bytes.TrimSpace(r.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		values, err := decodeObject(line)
+		if err != nil {
+			return Record{}, &RowError{Row: r.row, Err: err}
+		}
+		return Record{Row: r.row, Values: values}, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return Record{}, err
+	}
+	return Record{}, io.EOF
+}
+
+type jsonReader struct {
+	decoder *json.Decoder
+	started bool
+	row     int
+}
+
+func (r *jsonReader) Read() (Record, error) {
+	if !r.started {
+		token, err := r.decoder.Token()
+		if err == io.EOF {
+			return Record{}, io.EOF
+		} else if err != nil {
+			return Record{}, err
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			return Record{}, errors.New("data is not json array")
+		}
+		r.started = true
+	}
+
+	if !r.decoder.More() {
+		return Record{}, io.EOF
+	}
+	r.row++
+	var document json.RawMessage
+	if err := r.decoder.Decode(&document); err != nil {
+		// 構文が壊れている場合は以降を読み込めない
+		return Record{}, err
+	}
+	values, err := decodeObject(document)
+	if err != nil {
+		return Record{}, &RowError{Row: r.row, Err: err}
+	}
+	return Record{Row: r.row, Values: values}, nil
+}
+
+// decodeObject JSONのオブジェクトを読み込みます(数値はjson.Numberとします)
+func decodeObject(data []byte) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var values map[string]interface{}
+	if err := decoder.Decode(&values); err != nil || values == nil {
+		return nil, errors.New("document is not json object")
+	}
+	return values, nil
+}
+
+// Writer ドキュメントを1件ずつ書き込みます
+type Writer interface {
+	Write(document map[string]interface{}) error
+	// Close 書き込みを終了します(JSONの場合は配列を閉じます)
+	Close() error
+}
+
+// NewWriter 形式に応じたWriterを作成します。columnsはCSVに出力する項目と順序です
+func NewWriter(format string, w io.Writer, columns []string) (Writer, error) {
+	switch format {
+	case domain.DataFormatCSV:
+		return &csvWriter{writer: csv.NewWriter(w), columns: columns}, nil
+	case domain.DataFormatNDJSON:
+		return &ndjsonWriter{encoder: json.NewEncoder(w)}, nil
+	case domain.DataFormatJSON:
+		return &jsonWriter{writer: w}, nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+type csvWriter struct {
+	writer        *csv.Writer
+	columns       []string
+	headerWritten bool
+}
+
+func (w *csvWriter) Write(document map[string]interface{}) error {
+	if !w.headerWritten {
+		if err := w.writer.Write(w.columns); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+	fields := make([]string, len(w.columns))
+	for i, column := range w.columns {
+		field, err := formatCell(document[column])
+		if err != nil {
+			return err
+		}
+		fields[i] = field
+	}
+	return w.writer.Write(fields)
+}
+
+func (w *csvWriter) Close() error {
+	if !w.headerWritten {
+		// ドキュメントがない場合もヘッダーは出力する
+		if err := w.writer.Write(w.columns); err != nil {
+			return err
+		}
+	}
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// formatCell 値をCSVのセルの文字列にします(オブジェクトと配列はJSONにします)
+func formatCell(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		b, err := json.Marshal(v)
+		return string(b), err
+	}
+}
+
+type ndjsonWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *ndjsonWriter) Write(document map[string]interface{}) error {
+	return w.encoder.Encode(document)
+}
+
+func (w *ndjsonWriter) Close() error {
+	return nil
+}
+
+type jsonWriter struct {
+	writer io.Writer
+	count  int
+}
+
+func (w *jsonWriter) Write(document map[string]interface{}) error {
+	b, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+	prefix := ",\n"
+	if w.count == 0 {
+		prefix = "[\n"
+	}
+	w.count++
+	if _, err := io.WriteString(w.writer, prefix); err != nil {
+		return err
+	}
+	_, err = w.writer.Write(b)
+	return err
+}
+
+func (w *jsonWriter) Close() error {
+	suffix := "\n]\n"
+	if w.count == 0 {
+		suffix = "[]\n"
+	}
+	_, err := io.WriteString(w.writer, suffix)
+	return err
+}
+
+// ContentType 形式のContent-Typeを返却します
+func ContentType(format string) string {
+	switch format {
+	case domain.DataFormatCSV:
+		return "text/csv; charset=utf-8"
+	case domain.DataFormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/json; charset=utf-8"
+	}
+}
+
+// FormatFromContentType Content-Typeから形式を判定します(判定できない場合は空)
+func FormatFromContentType(contentType string) string {
+	switch {
+	case strings.Contains(contentType, "csv"):
+		return domain.DataFormatCSV
+	case strings.Contains(contentType, "ndjson"), strings.Contains(contentType, "jsonl"):
+		return domain.DataFormatNDJSON
+	case strings.Contains(contentType, "json"):
+		return domain.DataFormatJSON
+	default:
+		return ""
+	}
+}
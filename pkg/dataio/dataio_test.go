@@ -0,0 +1,180 @@
+package dataio_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/dataio"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSchema = `{
+	"type": "object",
+	"keys": ["id"],
+	"properties": {
+		"name": {"type": "string"},
+		"id": {"type": "integer"},
+		"score": {"type": ["number", "null"]},
+		"active": {"type": "boolean"},
+		"tags": {"type": "array", "items": {"type": "integer"}},
+		"address": {"type": "object"}
+	}
+}`
+
+// readAll すべてのドキュメントと、行ごとのエラーを読み込みます
+func readAll(t *testing.T, format string, data string) ([]dataio.Record, []error) {
+	reader, err := dataio.NewReader(format, strings.NewReader(data))
+	assert.NoError(t, err)
+
+	var records []dataio.Record
+	var rowErrors []error
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return records, rowErrors
+		}
+		if _, ok := err.(*dataio.RowError); ok {
+			rowErrors = append(rowErrors, err)
+			continue
+		}
+		if !assert.NoError(t, err) {
+			return records, rowErrors
+		}
+		records = append(records, record)
+	}
+}
+
+func TestReader(t *testing.T) {
+	t.Run("csv", func(t *testing.T) {
+		records, rowErrors := readAll(t, domain.DataFormatCSV, "\ufeffID, Name\n1,\"foo, bar\"\n\n2,\n3,baz,extra\n")
+
+		assert.Len(t, records, 2)
+		assert.Equal(t, 2, records[0].Row)
+		assert.Equal(t, map[string]interface{}{"ID": "1", "Name": "foo, bar"}, records[0].Values)
+		// 空のセルは含まない
+		assert.Equal(t, map[string]interface{}{"ID": "2"}, records[1].Values)
+		assert.Len(t, rowErrors, 1)
+		assert.Contains(t, rowErrors[0].Error(), "row 4")
+	})
+	t.Run("ndjson", func(t *testing.T) {
+		records, rowErrors := readAll(t, domain.DataFormatNDJSON, "{\"id\": 1}\n\n[1]\n{\"id\": 12345678901234567}\n")
+
+		assert.Len(t, records, 2)
+		assert.Equal(t, 4, records[1].Row)
+		assert.Equal(t, json.Number("12345678901234567"), records[1].Values["id"])
+		assert.Len(t, rowErrors, 1)
+		assert.Contains(t, rowErrors[0].Error(), "row 3")
+	})
+	t.Run("json", func(t *testing.T) {
+		records, rowErrors := readAll(t, domain.DataFormatJSON, `[{"id": 1}, "foo", {"id": 2}]`)
+
+		assert.Len(t, records, 2)
+		assert.Equal(t, 3, records[1].Row)
+		assert.Len(t, rowErrors, 1)
+	})
+	t.Run("JSON配列でない", func(t *testing.T) {
+		reader, _ := dataio.NewReader(domain.DataFormatJSON, strings.NewReader(`{"id": 1}`))
+		_, err := reader.Read()
+
+		assert.Error(t, err)
+		_, ok := err.(*dataio.RowError)
+		assert.False(t, ok)
+	})
+	t.Run("対応していない形式", func(t *testing.T) {
+		_, err := dataio.NewReader("xlsx", strings.NewReader(""))
+
+		assert.Equal(t, dataio.ErrUnsupportedFormat, err)
+	})
+}
+
+func TestWriter(t *testing.T) {
+	documents := []map[string]interface{}{
+		{"id": json.Number("1"), "name": "foo, bar", "tags": []interface{}{json.Number("1"), json.Number("2")}},
+		{"id": json.Number("2"), "active": true},
+	}
+	write := func(format string, documents []map[string]interface{}) string {
+		var buf bytes.Buffer
+		writer, err := dataio.NewWriter(format, &buf, []string{"id", "name", "active", "tags"})
+		assert.NoError(t, err)
+		for _, document := range documents {
+			assert.NoError(t, writer.Write(document))
+		}
+		assert.NoError(t, writer.Close())
+		return buf.String()
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		assert.Equal(t, "id,name,active,tags\n1,\"foo, bar\",,\"[1,2]\"\n2,,true,\n", write(domain.DataFormatCSV, documents))
+		assert.Equal(t, "id,name,active,tags\n", write(domain.DataFormatCSV, nil))
+	})
+	t.Run("ndjson", func(t *testing.T) {
+		assert.Equal(t, "{\"id\":1,\"name\":\"foo, bar\",\"tags\":[1,2]}\n{\"active\":true,\"id\":2}\n", write(domain.DataFormatNDJSON, documents))
+	})
+	t.Run("json", func(t *testing.T) {
+		assert.JSONEq(t, `[{"id":1,"name":"foo, bar","tags":[1,2]},{"active":true,"id":2}]`, write(domain.DataFormatJSON, documents))
+		assert.JSONEq(t, `[]`, write(domain.DataFormatJSON, nil))
+	})
+}
+
+func TestParseSchema(t *testing.T) {
+	schema, err := dataio.ParseSchema(testSchema)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "id", schema.Key)
+	assert.Equal(t, []string{"id", "active", "address", "name", "score", "tags"}, schema.Properties)
+}
+
+func TestCoerce(t *testing.T) {
+	schema, _ := dataio.ParseSchema(testSchema)
+
+	t.Run("文字列から変換", func(t *testing.T) {
+		values, err := schema.Coerce(map[string]interface{}{
+			"id":      "1.0",
+			"name":    "foo",
+			"score":   " 1.5 ",
+			"active":  "TRUE",
+			"tags":    "1, 2",
+			"address": `{"city": "Tokyo"}`,
+			"other":   "x",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"id":      int64(1),
+			"name":    "foo",
+			"score":   1.5,
+			"active":  true,
+			"tags":    []interface{}{int64(1), int64(2)},
+			"address": map[string]interface{}{"city": "Tokyo"},
+			"other":   "x",
+		}, values)
+	})
+	t.Run("JSONの値から変換", func(t *testing.T) {
+		values, err := schema.Coerce(map[string]interface{}{
+			"id":   json.Number("12345678901234567"),
+			"name": json.Number("10"),
+			"tags": "[3]",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(12345678901234567), values["id"])
+		assert.Equal(t, "10", values["name"])
+		assert.Equal(t, []interface{}{int64(3)}, values["tags"])
+	})
+	t.Run("変換できない", func(t *testing.T) {
+		_, err := schema.Coerce(map[string]interface{}{"id": "abc"})
+
+		assert.EqualError(t, err, `id: cannot convert "abc" to integer`)
+	})
+}
+
+func TestMap(t *testing.T) {
+	values := dataio.Map(map[string]interface{}{"ID": "1", "Name": "foo", "Memo": "x", "name2": "y"}, map[string]string{"ID": "id", "Name": "name", "Memo": ""})
+
+	assert.Equal(t, map[string]interface{}{"id": "1", "name": "foo", "name2": "y"}, values)
+}
@@ -0,0 +1,198 @@
+package dataio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Schema 型変換に使用する、ModelのJSON Schemaの項目と型
+type Schema struct {
+	// Key Keyの項目名
+	Key string
+	// Properties 項目名(Key、その他の項目を名前順)
+	Properties []string
+	types      map[string]string
+	itemTypes  map[string]string
+}
+
+// ParseSchema JSON Schemaから項目と型を読み込みます
+func ParseSchema(schema string) (*Schema, error) {
+	var schemaMap struct {
+		Keys       []string                   `json:"keys"`
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal([]byte(schema), &schemaMap); err != nil {
+		return nil, err
+	}
+
+	s := &Schema{types: map[string]string{}, itemTypes: map[string]string{}}
+	if len(schemaMap.Keys) > 0 {
+		s.Key = schemaMap.Keys[0]
+	}
+	for name, raw := range schemaMap.Properties {
+		var property struct {
+			Type  interface{} `json:"type"`
+			Items struct {
+				Type interface{} `json:"type"`
+			} `json:"items"`
+		}
+		json.Unmarshal(raw, &property)
+		s.types[name] = schemaType(property.Type)
+		s.itemTypes[name] = schemaType(property.Items.Type)
+		if name != s.Key {
+			s.Properties = append(s.Properties, name)
+		}
+	}
+	sort.Strings(s.Properties)
+	if _, ok := schemaMap.Properties[s.Key]; ok {
+		s.Properties = append([]string{s.Key}, s.Properties...)
+	}
+	return s, nil
+}
+
+// schemaType typeの値から型を取得します(["string", "null"]のような指定はnull以外の型とします)
+func schemaType(t interface{}) string {
+	switch v := t.(type) {
+	case string:
+		return v
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "null" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// Map 列名を項目名に変換します。mappingで項目名を空にした列は除きます
+func Map(values map[string]interface{}, mapping map[string]string) map[string]interface{} {
+	if len(mapping) == 0 {
+		return values
+	}
+	mapped := make(map[string]interface{}, len(values))
+	for name, value := range values {
+		if property, ok := mapping[name]; ok {
+			if property == "" {
+				continue
+			}
+			name = property
+		}
+		mapped[name] = value
+	}
+	return mapped
+}
+
+// Coerce 値をSchemaの型に変換します。Schemaにない項目はそのままとします
+func (s *Schema) Coerce(values map[string]interface{}) (map[string]interface{}, error) {
+	coerced := make(map[string]interface{}, len(values))
+	for name, value := range values {
+		v, err := coerce(value, s.types[name], s.itemTypes[name])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err.Error())
+		}
+		coerced[name] = v
+	}
+	return coerced, nil
+}
+
+func coerce(value interface{}, t string, itemType string) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return parseString(v, t, itemType)
+	case json.Number:
+		switch t {
+		case "integer":
+			if n, err := v.Int64(); err == nil {
+				return n, nil
+			}
+			return parseString(v.String(), t, itemType)
+		case "string":
+			return v.String(), nil
+		}
+	case bool:
+		if t == "string" {
+			return strconv.FormatBool(v), nil
+		}
+	case []interface{}:
+		if itemType == "" {
+			return v, nil
+		}
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			coerced, err := coerce(item, itemType, "")
+			if err != nil {
+				return nil, err
+			}
+			items[i] = coerced
+		}
+		return items, nil
+	}
+	return value, nil
+}
+
+// parseString 文字列の値を型に変換します
+func parseString(value string, t string, itemType string) (interface{}, error) {
+	trimmed := strings.TrimSpace(value)
+	switch t {
+	case "integer":
+		if n, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+			return n, nil
+		}
+		// "1.0"のような小数点以下が0の値も整数とする
+		if f, err := strconv.ParseFloat(trimmed, 64); err == nil && f == float64(int64(f)) {
+			return int64(f), nil
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(trimmed, 64); err == nil {
+			return f, nil
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(trimmed); err == nil {
+			return b, nil
+		}
+	case "null":
+		if trimmed == "" || trimmed == "null" {
+			return nil, nil
+		}
+	case "object":
+		var object map[string]interface{}
+		if err := decodeJSON(trimmed, &object); err == nil && object != nil {
+			return object, nil
+		}
+	case "array":
+		// JSONの配列、またはカンマ区切りの値
+		var items []interface{}
+		if strings.HasPrefix(trimmed, "[") {
+			if err := decodeJSON(trimmed, &items); err != nil {
+				break
+			}
+		} else if trimmed != "" {
+			for _, item := range strings.Split(trimmed, ",") {
+				items = append(items, strings.TrimSpace(item))
+			}
+		} else {
+			items = []interface{}{}
+		}
+		return coerce(items, t, itemType)
+	default:
+		return value, nil
+	}
+	return nil, fmt.Errorf("cannot convert %q to %s", value, t)
+}
+
+func decodeJSON(value string, v interface{}) error {
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return errors.New("unexpected data after json")
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package definition
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/yamljson"
+)
+
+// file 定義ファイル(1ファイルにつき1つのAPI)
+// エクスポートしたYAMLのBundleも、そのまま定義ファイルとして使用できます
+type file struct {
+	API       domain.API                   `json:"api"`
+	Models    []model                      `json:"models"`
+	Methods   []domain.Method              `json:"methods"`
+	Documents map[string][]json.RawMessage `json:"documents"`
+}
+
+// model 定義ファイルのModel
+// schemaはJSONの文字列に加えて、YAMLのオブジェクトでも記述できます
+type model struct {
+	domain.Model
+	Schema json.RawMessage `json:"schema"`
+}
+
+// Load ディレクトリ配下のYAMLファイル(.yaml, .yml)から、APIの定義をファイル名順に読み込みます
+func Load(dir string) ([]domain.Bundle, error) {
+	var paths []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		// 隠しファイル、隠しディレクトリ(.gitなど)は対象外
+		if path != dir && strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if ext := filepath.Ext(path); ext == ".yaml" || ext == ".yml" {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bundles := []domain.Bundle{}
+	for _, path := range paths {
+		bundle, err := LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		bundles = append(bundles, bundle)
+	}
+	return bundles, nil
+}
+
+// LoadFile 定義ファイルを読み込みます
+// ModelのIDを省略した場合は、Model名をIDとして扱います(MethodのModelIDからModel名で参照できます)
+func LoadFile(path string) (domain.Bundle, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return domain.Bundle{}, err
+	}
+
+	var f file
+	if err := yamljson.Unmarshal(data, &f); err != nil {
+		return domain.Bundle{}, fmt.Errorf("%s: %s", path, err.Error())
+	}
+
+	bundle := domain.Bundle{
+		Version:   domain.BundleVersion,
+		API:       f.API,
+		Models:    []domain.Model{},
+		Methods:   f.Methods,
+		Documents: f.Documents,
+	}
+	if bundle.Methods == nil {
+		bundle.Methods = []domain.Method{}
+	}
+	for i, m := range f.Models {
+		schema, err := schemaString(m.Schema)
+		if err != nil {
+			return domain.Bundle{}, fmt.Errorf("%s: models[%d].schema: %s", path, i, err.Error())
+		}
+		m.Model.Schema = schema
+		if m.Model.ID == "" {
+			m.Model.ID = m.Model.Name
+		}
+		bundle.Models = append(bundle.Models, m.Model)
+	}
+	return bundle, nil
+}
+
+// schemaString jsonschemaを文字列にします
+func schemaString(schema json.RawMessage) (string, error) {
+	schema = bytes.TrimSpace(schema)
+	if len(schema) == 0 || string(schema) == "null" {
+		return "", nil
+	}
+	if schema[0] == '"' {
+		var s string
+		if err := json.Unmarshal(schema, &s); err != nil {
+			return "", err
+		}
+		return s, nil
+	}
+	return string(schema), nil
+}
@@ -0,0 +1,159 @@
+package definition_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/definition"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const usersYAML = `api:
+  name: users
+  url: v1/users
+models:
+  - name: User
+    schema:
+      type: object
+      keys: [id]
+      properties:
+        id: {type: string}
+methods:
+  - type: GET
+    url: /{id}
+    responseModelId: User
+documents:
+  User:
+    - id: "1"
+`
+
+const itemsYAML = `version: 1
+api:
+  id: exported
+  name: items
+  url: v1/items
+models:
+  - id: item-model
+    name: Item
+    schema: '{"type": "object", "keys": ["id"], "properties": {"id": {"type": "string"}}}'
+methods: []
+`
+
+// writeFiles 一時ディレクトリに定義ファイルを作成します
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "definitions")
+	assert.NoError(t, err)
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("正常系", func(t *testing.T) {
+		dir := writeFiles(t, map[string]string{
+			"users.yaml":        usersYAML,
+			"shop/items.yml":    itemsYAML,
+			"README.md":         "# definitions",
+			".git/config.yaml":  "not: definition",
+			"shop/.hidden.yaml": "",
+		})
+		defer os.RemoveAll(dir)
+
+		bundles, err := definition.Load(dir)
+
+		assert.NoError(t, err)
+		assert.Len(t, bundles, 2)
+
+		// ファイル名順
+		items := bundles[0]
+		assert.Equal(t, "v1/items", items.API.URL)
+		assert.Equal(t, "item-model", items.Models[0].ID)
+		assert.Equal(t, `{"type": "object", "keys": ["id"], "properties": {"id": {"type": "string"}}}`, items.Models[0].Schema)
+
+		users := bundles[1]
+		assert.Equal(t, domain.BundleVersion, users.Version)
+		assert.Equal(t, "v1/users", users.API.URL)
+		assert.Equal(t, "User", users.Models[0].ID)
+		assert.JSONEq(t, `{"type": "object", "keys": ["id"], "properties": {"id": {"type": "string"}}}`, users.Models[0].Schema)
+		assert.Equal(t, "User", users.Methods[0].ResponseModelID)
+		assert.Len(t, users.Documents["User"], 1)
+	})
+	t.Run("不正なYAML", func(t *testing.T) {
+		dir := writeFiles(t, map[string]string{"users.yaml": "api: [users"})
+		defer os.RemoveAll(dir)
+
+		_, err := definition.Load(dir)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "users.yaml")
+	})
+	t.Run("ディレクトリが存在しない", func(t *testing.T) {
+		_, err := definition.Load("./not-found")
+
+		assert.Error(t, err)
+	})
+}
+
+// TestLoadSeed サンプルのAPIの定義ファイル(--devで読み込みます)
+func TestLoadSeed(t *testing.T) {
+	bundles, err := definition.Load("../../docker/seed")
+
+	assert.NoError(t, err)
+	if !assert.Len(t, bundles, 3) {
+		return
+	}
+	urls := []string{}
+	for _, bundle := range bundles {
+		urls = append(urls, bundle.API.URL)
+		assert.Len(t, bundle.Methods, 5)
+		if assert.Len(t, bundle.Models, 1) {
+			assert.Contains(t, bundle.Models[0].Schema, `"keys":["id"]`)
+		}
+	}
+	assert.Equal(t, []string{"my-project/api/photos", "my-project/api/posts", "my-project/api/users"}, urls)
+}
+
+func TestPrintPlan(t *testing.T) {
+	t.Run("変更あり", func(t *testing.T) {
+		results := []domain.ImportResult{
+			{URL: "v1/users", Changes: []domain.ImportChange{
+				{Kind: "api", Action: domain.ImportActionUnchanged, Name: "users"},
+				{Kind: "method", Action: domain.ImportActionUpdate, Name: "GET /{id}", Diff: []domain.FieldDiff{{Field: "description", Before: "a", After: "b"}}},
+				{Kind: "method", Action: domain.ImportActionCreate, Name: "POST "},
+				{Kind: "documents", Action: domain.ImportActionCreate, Name: "User", Count: 2},
+			}},
+			{URL: "v1/unchanged", Changes: []domain.ImportChange{{Kind: "api", Action: domain.ImportActionUnchanged, Name: "unchanged"}}},
+			{URL: "v1/items", Changes: []domain.ImportChange{{Kind: "api", Action: domain.ImportActionDelete, Name: "items"}}},
+		}
+		var buf bytes.Buffer
+
+		definition.PrintPlan(&buf, results)
+
+		assert.Equal(t, `v1/users
+  ~ method GET /{id}
+      description: "a" -> "b"
+  + method POST 
+  + documents User (2)
+
+v1/items
+  - api items
+
+Plan: 2 to create, 1 to update, 1 to delete.
+`, buf.String())
+	})
+	t.Run("変更なし", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		definition.PrintPlan(&buf, []domain.ImportResult{{URL: "v1/users"}})
+
+		assert.Equal(t, "No changes.\n", buf.String())
+	})
+}
@@ -0,0 +1,59 @@
+package definition
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+)
+
+// actionSymbols 変更の種類ごとの表示
+var actionSymbols = map[string]string{
+	domain.ImportActionCreate: "+",
+	domain.ImportActionUpdate: "~",
+	domain.ImportActionDelete: "-",
+	domain.ImportActionSkip:   "!",
+}
+
+// PrintPlan 同期による変更内容を出力します(変更がないものは出力しません)
+func PrintPlan(w io.Writer, results []domain.ImportResult) {
+	counts := map[string]int{}
+	for _, result := range results {
+		var changes []domain.ImportChange
+		for _, change := range result.Changes {
+			if change.Action != domain.ImportActionUnchanged {
+				changes = append(changes, change)
+			}
+		}
+		if len(changes) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(w, "%s\n", result.URL)
+		for _, change := range changes {
+			counts[change.Action]++
+			fmt.Fprintf(w, "  %s %s %s", actionSymbols[change.Action], change.Kind, change.Name)
+			if change.Count > 0 {
+				fmt.Fprintf(w, " (%d)", change.Count)
+			}
+			fmt.Fprintln(w)
+			for _, diff := range change.Diff {
+				fmt.Fprintf(w, "      %s: %s -> %s\n", diff.Field, toString(diff.Before), toString(diff.After))
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	if len(counts) == 0 {
+		fmt.Fprintln(w, "No changes.")
+		return
+	}
+	fmt.Fprintf(w, "Plan: %d to create, %d to update, %d to delete.\n",
+		counts[domain.ImportActionCreate], counts[domain.ImportActionUpdate], counts[domain.ImportActionDelete])
+}
+
+func toString(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
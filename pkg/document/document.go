@@ -0,0 +1,220 @@
+// Package document リクエストされたJSONを、ModelのJSON Schemaに従ってコレクションのドキュメントにします
+// 拡張JSON({"$date": ...}、{"$oid": ...}など)や演算子({"$where": ...}、{"$set": ...}など)は解釈せず、
+// $で始まる項目名や.を含む項目名は拒否します。値の型はJSON Schemaの型のみで決まります
+package document
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// maxDepth オブジェクト、配列の入れ子の上限
+const maxDepth = 32
+
+var (
+	// ErrNotObject "document must be a json object"
+	ErrNotObject = errors.New("document must be a json object")
+	// ErrTooDeep "document is nested too deeply"
+	ErrTooDeep = errors.New("document is nested too deeply")
+)
+
+// Decode JSONのドキュメントを、schema(ModelのJSON Schema)の型に従って変換します
+// schemaが空の場合は、JSONの値の型のみで変換します
+func Decode(schema string, body []byte) (bson.M, error) {
+	var root map[string]interface{}
+	if schema != "" {
+		if err := json.Unmarshal([]byte(schema), &root); err != nil {
+			return nil, fmt.Errorf("invalid model schema: %s", err.Error())
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, errors.New("unexpected data after json")
+	}
+	if _, ok := value.(map[string]interface{}); !ok {
+		return nil, ErrNotObject
+	}
+
+	converted, err := convert(value, root, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	return converted.(bson.M), nil
+}
+
+// Encode ドキュメントを、型を保持した拡張JSON(リポジトリに渡す形式)にします
+func Encode(document bson.M) ([]byte, error) {
+	return bson.MarshalExtJSON(document, true, false)
+}
+
+// KeyValue ドキュメントのKeyの値を取得します。Keyの値は文字列または数値のみとします
+func KeyValue(document bson.M, key string) (interface{}, error) {
+	value, ok := document[key]
+	if !ok || value == nil {
+		return nil, errors.New("target property is not found")
+	}
+	switch value.(type) {
+	case string, int32, int64, float64:
+		return value, nil
+	}
+	return nil, fmt.Errorf("key %s must be a string or number", key)
+}
+
+// ValidateKey 項目名が$で始まる場合、.を含む場合(MongoDBの演算子、入れ子の項目の指定)はエラーを返却します
+func ValidateKey(name string) error {
+	if name == "" {
+		return errors.New("empty property name is not allowed")
+	}
+	if strings.HasPrefix(name, "$") {
+		return fmt.Errorf("property name %q must not start with '$'", name)
+	}
+	if strings.Contains(name, ".") {
+		return fmt.Errorf("property name %q must not contain '.'", name)
+	}
+	if strings.Contains(name, "\x00") {
+		return fmt.Errorf("property name %q must not contain null characters", name)
+	}
+	return nil
+}
+
+// convert 値をschemaの型に変換します。pathはエラーに表示する項目の位置です
+func convert(value interface{}, schema map[string]interface{}, path string, depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, ErrTooDeep
+	}
+	t := schemaType(schema)
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if t != "" && t != "object" {
+			return nil, typeError(path, t, "object")
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		additional, _ := schema["additionalProperties"].(map[string]interface{})
+		document := make(bson.M, len(v))
+		for name, item := range v {
+			if err := ValidateKey(name); err != nil {
+				return nil, err
+			}
+			property, ok := properties[name].(map[string]interface{})
+			if !ok {
+				property = additional
+			}
+			converted, err := convert(item, property, join(path, name), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			document[name] = converted
+		}
+		return document, nil
+
+	case []interface{}:
+		if t != "" && t != "array" {
+			return nil, typeError(path, t, "array")
+		}
+		items, _ := schema["items"].(map[string]interface{})
+		array := make(bson.A, len(v))
+		for i, item := range v {
+			converted, err := convert(item, items, fmt.Sprintf("%s[%d]", path, i), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			array[i] = converted
+		}
+		return array, nil
+
+	case json.Number:
+		return convertNumber(v, t, path)
+
+	case string:
+		if t != "" && t != "string" {
+			return nil, typeError(path, t, "string")
+		}
+		return v, nil
+
+	case bool:
+		if t != "" && t != "boolean" {
+			return nil, typeError(path, t, "boolean")
+		}
+		return v, nil
+
+	case nil:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("%s: unsupported value", path)
+}
+
+// convertNumber 数値をschemaの型に変換します
+// 整数はint32(範囲外の場合はint64)、小数はfloat64とします(以前の拡張JSONの変換と同じ型)
+func convertNumber(n json.Number, t string, path string) (interface{}, error) {
+	switch t {
+	case "", "number", "integer":
+	default:
+		return nil, typeError(path, t, "number")
+	}
+
+	if i, err := n.Int64(); err == nil {
+		return integer(i), nil
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid number %s", path, n.String())
+	}
+	if t == "integer" {
+		// 1.0のような小数点以下が0の値は整数とする
+		if f != math.Trunc(f) || f < math.MinInt64 || f > math.MaxInt64 {
+			return nil, typeError(path, t, "number")
+		}
+		return integer(int64(f)), nil
+	}
+	return f, nil
+}
+
+// integer 整数をint32の範囲内であればint32にします
+func integer(i int64) interface{} {
+	if i >= math.MinInt32 && i <= math.MaxInt32 {
+		return int32(i)
+	}
+	return i
+}
+
+// schemaType JSON Schemaのtypeを取得します(["string", "null"]のような指定はnull以外の型とします)
+func schemaType(schema map[string]interface{}) string {
+	switch v := schema["type"].(type) {
+	case string:
+		return v
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "null" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+func typeError(path string, expected string, actual string) error {
+	if path == "" {
+		path = "document"
+	}
+	return fmt.Errorf("%s: expected %s, but got %s", path, expected, actual)
+}
+
+func join(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,156 @@
+package document_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/document"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSchema = `{
+	"type": "object",
+	"keys": ["id"],
+	"properties": {
+		"id": {"type": "string"},
+		"age": {"type": "integer"},
+		"score": {"type": "number"},
+		"active": {"type": "boolean"},
+		"createdAt": {"type": "string", "format": "date-time"},
+		"profile": {
+			"type": "object",
+			"properties": {"nickname": {"type": ["string", "null"]}}
+		},
+		"tags": {"type": "array", "items": {"type": "string"}}
+	}
+}`
+
+// maliciousPayloads 拒否するリクエストBody(MongoDBの演算子、拡張JSON、入れ子の項目の指定など)
+var maliciousPayloads = []struct {
+	name string
+	body string
+}{
+	{"$where", `{"id": "1", "$where": "sleep(1000) || true"}`},
+	{"$set", `{"id": "1", "$set": {"admin": true}}`},
+	{"$unset", `{"$unset": {"id": ""}}`},
+	{"Keyが演算子", `{"id": {"$ne": null}}`},
+	{"Keyが$gt", `{"id": {"$gt": ""}}`},
+	{"Keyが$regex", `{"id": {"$regex": ".*"}}`},
+	{"Keyが$in", `{"id": {"$in": ["1", "2"]}}`},
+	{"$date", `{"id": "1", "createdAt": {"$date": "2020-01-01T00:00:00Z"}}`},
+	{"$oid", `{"id": {"$oid": "5f0c1e2b9d3e2a1b2c3d4e5f"}}`},
+	{"$numberLong", `{"id": "1", "age": {"$numberLong": "9223372036854775807"}}`},
+	{"$binary", `{"id": "1", "profile": {"$binary": {"base64": "AA==", "subType": "00"}}}`},
+	{"$code", `{"id": "1", "profile": {"$code": "function() { return true }"}}`},
+	{"入れ子の演算子", `{"id": "1", "profile": {"nickname": "a", "$where": "true"}}`},
+	{"配列内の演算子", `{"id": "1", "tags": [{"$ne": null}]}`},
+	{"Schemaにない項目の演算子", `{"id": "1", "extra": {"deep": {"$expr": {"$eq": [1, 1]}}}}`},
+	{".を含む項目", `{"id": "1", "profile.admin": true}`},
+	{"入れ子の.を含む項目", `{"id": "1", "profile": {"role.name": "admin"}}`},
+	{"空の項目名", `{"id": "1", "": "empty"}`},
+	{"NUL文字を含む項目", `{"id": "1", "a\u0000b": "nul"}`},
+	{"文字列を数値として扱わない", `{"id": "1", "age": "1"}`},
+	{"数値を文字列として扱わない", `{"id": 1}`},
+	{"小数の整数", `{"id": "1", "age": 1.5}`},
+	{"オブジェクト以外", `["id", "1"]`},
+	{"文字列", `"id"`},
+	{"複数のJSON", `{"id": "1"} {"id": "2"}`},
+	{"不正なJSON", `{"id": "1"`},
+	{"入れ子が深い", strings.Repeat(`{"a":`, 100) + `1` + strings.Repeat(`}`, 100)},
+}
+
+func TestDecodeMaliciousPayloads(t *testing.T) {
+	for _, p := range maliciousPayloads {
+		t.Run(p.name, func(t *testing.T) {
+			doc, err := document.Decode(testSchema, []byte(p.body))
+			if err == nil {
+				// 項目名や型に問題がなくても、Keyの値は文字列または数値のみ
+				_, err = document.KeyValue(doc, "id")
+			}
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("Schemaの型に従って変換する", func(t *testing.T) {
+		doc, err := document.Decode(testSchema, []byte(`{
+			"id": "1",
+			"age": 20.0,
+			"score": 1.5,
+			"active": true,
+			"createdAt": "2020-01-01T00:00:00Z",
+			"profile": {"nickname": null},
+			"tags": ["a", "b"]
+		}`))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "1", doc["id"])
+		assert.Equal(t, int32(20), doc["age"])
+		assert.Equal(t, 1.5, doc["score"])
+		assert.Equal(t, true, doc["active"])
+		// 日時の形式の文字列も文字列のまま保存する
+		assert.Equal(t, "2020-01-01T00:00:00Z", doc["createdAt"])
+		assert.Equal(t, bson.M{"nickname": nil}, doc["profile"])
+		assert.Equal(t, bson.A{"a", "b"}, doc["tags"])
+	})
+	t.Run("int32の範囲外の整数", func(t *testing.T) {
+		doc, err := document.Decode(testSchema, []byte(`{"id": "1", "age": 3000000000}`))
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3000000000), doc["age"])
+	})
+	t.Run("Schemaにない項目はJSONの型のまま", func(t *testing.T) {
+		doc, err := document.Decode(testSchema, []byte(`{"id": "1", "extra": {"count": 1, "ratio": 0.5, "name": "$where"}}`))
+
+		assert.NoError(t, err)
+		// 値が$で始まる文字列は、演算子ではないため許可する
+		assert.Equal(t, bson.M{"count": int32(1), "ratio": 0.5, "name": "$where"}, doc["extra"])
+	})
+	t.Run("Schemaの指定なし", func(t *testing.T) {
+		doc, err := document.Decode("", []byte(`{"id": 1}`))
+
+		assert.NoError(t, err)
+		assert.Equal(t, int32(1), doc["id"])
+	})
+}
+
+func TestEncode(t *testing.T) {
+	doc, _ := document.Decode(testSchema, []byte(`{"id": "1", "age": 20, "score": 2}`))
+
+	encoded, err := document.Encode(doc)
+	assert.NoError(t, err)
+
+	// 拡張JSONとして読み込んだ場合に、型が変わらない
+	var decoded bson.M
+	assert.NoError(t, bson.UnmarshalExtJSON(encoded, false, &decoded))
+	assert.Equal(t, doc, decoded)
+}
+
+func TestKeyValue(t *testing.T) {
+	t.Run("文字列", func(t *testing.T) {
+		value, err := document.KeyValue(bson.M{"id": "1"}, "id")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "1", value)
+	})
+	t.Run("数値", func(t *testing.T) {
+		value, err := document.KeyValue(bson.M{"id": int32(1)}, "id")
+
+		assert.NoError(t, err)
+		assert.Equal(t, int32(1), value)
+	})
+	t.Run("オブジェクト", func(t *testing.T) {
+		_, err := document.KeyValue(bson.M{"id": bson.M{"nested": "1"}}, "id")
+
+		assert.Error(t, err)
+	})
+	t.Run("存在しない", func(t *testing.T) {
+		_, err := document.KeyValue(bson.M{"name": "1"}, "id")
+
+		assert.Error(t, err)
+	})
+}
@@ -1,10 +1,80 @@
 package domain
 
+import "strings"
+
+// CORSAllowAll すべてのオリジンを許可するCORSAllowedOriginsの値
+const CORSAllowAll = "*"
+
 //API API
 type API struct {
 	ID          string `json:"id" gorm:"column:id;primary_key"`
 	Name        string `json:"name" gorm:"column:name"`
 	URL         string `json:"url" gorm:"column:url"`
 	Description string `json:"description" gorm:"column:description"`
+	// StreamEnabled trueの場合、"<url>/_events"でドキュメントの変更イベントを配信します
+	StreamEnabled bool `json:"streamEnabled" gorm:"column:stream_enabled"`
+	// RateLimit 呼び出し元ごとの1分あたりのリクエスト数の上限(0の場合は制限しません)
+	RateLimit int `json:"rateLimit" gorm:"column:rate_limit"`
+	// RateBurst 呼び出し元ごとに連続して受け付けるリクエスト数(0の場合はRateLimitと同じ)
+	RateBurst int `json:"rateBurst" gorm:"column:rate_burst"`
+	// MonthlyQuota 呼び出し元ごとの1か月のリクエスト数の上限(0の場合は制限しません)
+	MonthlyQuota int `json:"monthlyQuota" gorm:"column:monthly_quota"`
+	// CORSAllowedOrigins クロスオリジンのリクエストを許可するオリジンをカンマ区切りで指定します("*"はすべて。未指定の場合は許可しません)
+	CORSAllowedOrigins string `json:"corsAllowedOrigins" gorm:"column:cors_allowed_origins"`
+	// CORSAllowedMethods 許可するHTTPメソッドをカンマ区切りで指定します(未指定の場合はGET、POST、PUT、DELETE)
+	CORSAllowedMethods string `json:"corsAllowedMethods" gorm:"column:cors_allowed_methods"`
+	// CORSAllowedHeaders 許可するリクエストヘッダーをカンマ区切りで指定します(未指定の場合はContent-Type)
+	CORSAllowedHeaders string `json:"corsAllowedHeaders" gorm:"column:cors_allowed_headers"`
+	// CORSAllowCredentials trueの場合、Cookieなどの資格情報を含むリクエストを許可します
+	CORSAllowCredentials bool `json:"corsAllowCredentials" gorm:"column:cors_allow_credentials"`
+	// CORSMaxAge プリフライトリクエストの結果をキャッシュする時間(秒。0の場合はブラウザの既定)
+	CORSMaxAge int `json:"corsMaxAge" gorm:"column:cors_max_age"`
 	CommonColumn
 }
+
+// CORSOrigins 許可するオリジンの一覧を取得します
+func (a *API) CORSOrigins() []string {
+	return splitList(a.CORSAllowedOrigins)
+}
+
+// CORSMethods 許可するHTTPメソッドの一覧を取得します
+func (a *API) CORSMethods() []string {
+	if methods := splitList(a.CORSAllowedMethods); len(methods) > 0 {
+		return methods
+	}
+	return []string{"GET", "POST", "PUT", "DELETE"}
+}
+
+// CORSHeaders 許可するリクエストヘッダーの一覧を取得します
+func (a *API) CORSHeaders() []string {
+	if headers := splitList(a.CORSAllowedHeaders); len(headers) > 0 {
+		return headers
+	}
+	return []string{"Content-Type"}
+}
+
+// splitList カンマ区切りの値を、前後の空白を除いて分割します(空の値は含めません)
+func splitList(value string) []string {
+	list := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// APIAlias URL変更前のURLを、新しいURLへリダイレクトするための別名
+type APIAlias struct {
+	ID    string `json:"id" gorm:"column:id;primary_key"`
+	APIID string `json:"apiId" gorm:"column:api_id"`
+	URL   string `json:"url" gorm:"column:url"`
+	CommonColumn
+}
+
+// ChangeURLRequest APIのURL変更時のリクエスト
+type ChangeURLRequest struct {
+	URL string `json:"url"`
+	// KeepAlias trueの場合、変更前のURLをリダイレクト用の別名として残します
+	KeepAlias bool `json:"keepAlias"`
+}
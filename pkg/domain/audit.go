@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// callerKey contextに呼び出し元を保持するキー
+type callerKey struct{}
+
+// AuditLog APIサーバーで処理した書き込み(POST、PUT、DELETE)の記録
+type AuditLog struct {
+	ID       string `json:"id" gorm:"column:id;primary_key"`
+	APIID    string `json:"apiId" gorm:"column:api_id"`
+	MethodID string `json:"methodId" gorm:"column:method_id"`
+	// HTTPMethod リクエストされたHTTPメソッド
+	HTTPMethod string `json:"httpMethod" gorm:"column:http_method"`
+	URL        string `json:"url" gorm:"column:url"`
+	// Key 対象のドキュメントのKeyの値(コレクションの削除の場合は空)
+	Key string `json:"key" gorm:"column:document_key"`
+	// Caller 呼び出し元("key:<API Keyの名前>"、"sub:<JWTのsub>"、"ip:<IPアドレス>")
+	Caller string `json:"caller" gorm:"column:caller"`
+	// Before 変更前のドキュメント(JSON。機密の項目は伏せて記録します)
+	Before string `json:"before" gorm:"column:before_document"`
+	// After 変更後のドキュメント(JSON。機密の項目は伏せて記録します)
+	After  string `json:"after" gorm:"column:after_document"`
+	Status int    `json:"status" gorm:"column:status"`
+	Error  string `json:"error" gorm:"column:error"`
+	// Latency 処理にかかった時間(ミリ秒)
+	Latency int `json:"latency" gorm:"column:latency"`
+	CommonColumn
+}
+
+// AuditQuery 監査ログの検索条件(空の項目は条件にしません)
+type AuditQuery struct {
+	APIID  string
+	Key    string
+	Caller string
+	// From 記録日時の下限(この日時を含みます)
+	From time.Time
+	// To 記録日時の上限(この日時を含みません)
+	To     time.Time
+	Limit  int
+	Offset int
+}
+
+// WithCaller 呼び出し元を保持したcontextを作成します
+func WithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFromContext contextに保持された呼び出し元を取得します(保持していない場合は空)
+func CallerFromContext(ctx context.Context) string {
+	caller, _ := ctx.Value(callerKey{}).(string)
+	return caller
+}
@@ -0,0 +1,78 @@
+package domain
+
+import "time"
+
+// BackupVersion バックアップのアーカイブの形式のバージョン
+const BackupVersion = 1
+
+// BackupManifest バックアップのアーカイブの内容(アーカイブの先頭にmanifest.jsonとして格納します)
+type BackupManifest struct {
+	Version int `json:"version"`
+	// Label バックアップを識別するためのラベル(例：before-release)
+	Label     string      `json:"label"`
+	CreatedAt time.Time   `json:"createdAt"`
+	APIs      []BackupAPI `json:"apis"`
+	// Files manifest.json以外のファイルとチェックサム
+	Files []BackupFile `json:"files"`
+}
+
+// BackupAPI バックアップに含まれるAPIと、そのファイル
+type BackupAPI struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Definition API、Model、Methodの定義(Bundle)のファイル
+	Definition string `json:"definition"`
+	// Collections Modelのコレクションのドキュメント(Extended JSONのNDJSON)のファイル
+	Collections []string `json:"collections"`
+}
+
+// BackupFile アーカイブ内のファイル
+type BackupFile struct {
+	Path string `json:"path"`
+	// SHA256 ファイルの内容のSHA-256(16進数)
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+	// Documents コレクションのファイルの場合、ドキュメントの件数
+	Documents int `json:"documents,omitempty"`
+}
+
+// BackupRequest バックアップを作成する際のリクエスト
+type BackupRequest struct {
+	Label string `json:"label"`
+}
+
+// BackupInfo 保存されているバックアップ
+type BackupInfo struct {
+	Name      string      `json:"name"`
+	Size      int64       `json:"size"`
+	Label     string      `json:"label"`
+	CreatedAt time.Time   `json:"createdAt"`
+	APIs      []BackupAPI `json:"apis"`
+}
+
+// RestoreRequest バックアップから復元する際のリクエスト
+type RestoreRequest struct {
+	// APIID 指定した場合、バックアップに含まれるこのAPI(IDまたはURL)のみを復元します
+	APIID string `json:"apiId"`
+	// Prune trueの場合、バックアップに含まれないAPIを削除します(APIIDを指定しない場合のみ)
+	Prune bool `json:"prune"`
+}
+
+// RestoreResult バックアップから復元した結果
+type RestoreResult struct {
+	Name      string        `json:"name"`
+	Label     string        `json:"label"`
+	CreatedAt time.Time     `json:"createdAt"`
+	APIs      []RestoredAPI `json:"apis"`
+}
+
+// RestoredAPI 復元したAPI
+type RestoredAPI struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+	// Action ImportActionCreate、ImportActionUpdate、ImportActionDeleteのいずれか
+	Action string `json:"action"`
+	// Documents 復元したドキュメントの件数
+	Documents int `json:"documents"`
+}
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// BundleVersion Bundleの形式のバージョン
+const BundleVersion = 1
+
+const (
+	// ImportConflictSkip 同じURLのAPIが存在する場合、インポートしない
+	ImportConflictSkip = "skip"
+	// ImportConflictOverwrite 同じURLのAPIが存在する場合、既存のAPIを上書きする
+	ImportConflictOverwrite = "overwrite"
+	// ImportConflictRename 同じURLのAPIが存在する場合、URLを変更して新しいAPIとしてインポートする
+	ImportConflictRename = "rename"
+)
+
+const (
+	// ImportActionCreate 作成
+	ImportActionCreate = "create"
+	// ImportActionUpdate 更新
+	ImportActionUpdate = "update"
+	// ImportActionDelete 削除
+	ImportActionDelete = "delete"
+	// ImportActionSkip 競合したためインポートしない
+	ImportActionSkip = "skip"
+	// ImportActionUnchanged 変更なし
+	ImportActionUnchanged = "unchanged"
+)
+
+// Bundle 環境間でAPIを移行するための、APIとMethod、Model(とコレクションのドキュメント)の定義
+type Bundle struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exportedAt"`
+	API        API       `json:"api"`
+	Models     []Model   `json:"models"`
+	Methods    []Method  `json:"methods"`
+	// Documents ModelのIDごとの、コレクションのドキュメント(エクスポート時に指定した場合のみ)
+	Documents map[string][]json.RawMessage `json:"documents,omitempty"`
+}
+
+// ImportRequest Bundleのインポート時の指定
+type ImportRequest struct {
+	// Conflict 同じURLのAPIが存在する場合の処理(未指定の場合はエラー)
+	Conflict string
+	// DryRun trueの場合、反映せずに変更内容のみ返却します
+	DryRun bool
+}
+
+// ImportResult Bundleのインポート結果
+type ImportResult struct {
+	DryRun bool `json:"dryRun"`
+	// APIID インポート先のAPIのID
+	APIID   string         `json:"apiId"`
+	URL     string         `json:"url"`
+	Changes []ImportChange `json:"changes"`
+}
+
+// ImportChange インポートによる1件の変更
+type ImportChange struct {
+	// Kind "api", "model", "method", "documents"のいずれか
+	Kind   string `json:"kind"`
+	Action string `json:"action"`
+	// ID インポート先のID
+	ID string `json:"id"`
+	// SourceID Bundle内のID
+	SourceID string `json:"sourceId,omitempty"`
+	Name     string `json:"name"`
+	// Diff 更新する項目の変更前と変更後の値
+	Diff []FieldDiff `json:"diff,omitempty"`
+	// Count 投入するドキュメントの件数
+	Count int `json:"count,omitempty"`
+}
+
+// FieldDiff 項目の変更前と変更後の値
+type FieldDiff struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// SyncRequest 定義ファイルとの同期時の指定
+type SyncRequest struct {
+	// Prune trueの場合、定義ファイルに存在しないAPIを削除します
+	Prune bool
+	// DryRun trueの場合、反映せずに変更内容のみ返却します
+	DryRun bool
+}
+
+// ImportPlan インポートでDBに反映する内容
+type ImportPlan struct {
+	API       API
+	CreateAPI bool
+	// DeleteAPI trueの場合、APIを削除します(DeleteModels、DeleteMethodsも指定する必要があります)
+	DeleteAPI     bool
+	CreateModels  []Model
+	UpdateModels  []Model
+	DeleteModels  []Model
+	CreateMethods []Method
+	UpdateMethods []Method
+	DeleteMethods []Method
+}
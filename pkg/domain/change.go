@@ -0,0 +1,70 @@
+package domain
+
+import "context"
+
+// 変更を記録する対象の種類
+const (
+	EntityTypeAPI    = "api"
+	EntityTypeMethod = "method"
+	EntityTypeModel  = "model"
+)
+
+// 変更の種類
+const (
+	ChangeActionCreate = "create"
+	ChangeActionUpdate = "update"
+	ChangeActionDelete = "delete"
+	// ChangeActionRevert 以前のバージョンに戻した変更
+	ChangeActionRevert = "revert"
+)
+
+// actorKey contextに操作者を保持するキー
+type actorKey struct{}
+
+// actorIPKey contextに操作者のIPアドレスを保持するキー
+type actorIPKey struct{}
+
+// ChangeEvent 管理画面での設定(API、Method、Model)の変更の履歴
+type ChangeEvent struct {
+	ID         string `json:"id" gorm:"column:id;primary_key"`
+	EntityType string `json:"entityType" gorm:"column:entity_type"`
+	EntityID   string `json:"entityId" gorm:"column:entity_id"`
+	APIID      string `json:"apiId" gorm:"column:api_id"`
+	// Version 対象ごとの通し番号(1から始まります)。変更後の状態をこのバージョンとします
+	Version int    `json:"version" gorm:"column:version"`
+	Action  string `json:"action" gorm:"column:action"`
+	// RevertedVersion 以前のバージョンに戻した場合の、戻したバージョン
+	RevertedVersion int `json:"revertedVersion,omitempty" gorm:"column:reverted_version"`
+	// Actor 変更した操作者(X-Actorヘッダーの値。ヘッダーがない場合は"ip:<IPアドレス>")
+	// X-Actorヘッダーはクライアントが任意に指定できるため、参考情報です
+	Actor string `json:"actor" gorm:"column:actor"`
+	// ActorIP 変更したリクエストの送信元のIPアドレス
+	ActorIP string `json:"actorIp" gorm:"column:actor_ip"`
+	// Before 変更前の設定(JSON。作成の場合は空)
+	Before string `json:"before" gorm:"column:before_value"`
+	// After 変更後の設定(JSON。削除の場合は空)
+	After string `json:"after" gorm:"column:after_value"`
+	CommonColumn
+}
+
+// WithActor 操作者を保持したcontextを作成します
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext contextに保持された操作者を取得します(保持していない場合は空)
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// WithActorIP 操作者のIPアドレスを保持したcontextを作成します
+func WithActorIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, actorIPKey{}, ip)
+}
+
+// ActorIPFromContext contextに保持された操作者のIPアドレスを取得します(保持していない場合は空)
+func ActorIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(actorIPKey{}).(string)
+	return ip
+}
@@ -0,0 +1,73 @@
+package domain
+
+import "time"
+
+const (
+	// DataFormatCSV 1行目をヘッダーとするCSV
+	DataFormatCSV = "csv"
+	// DataFormatNDJSON 1行に1ドキュメントのJSON
+	DataFormatNDJSON = "ndjson"
+	// DataFormatJSON ドキュメントのJSON配列
+	DataFormatJSON = "json"
+)
+
+const (
+	// DataJobImport コレクションへの取り込み
+	DataJobImport = "import"
+	// DataJobExport コレクションの出力
+	DataJobExport = "export"
+)
+
+const (
+	// DataJobRunning 実行中
+	DataJobRunning = "running"
+	// DataJobCompleted 完了(行ごとのエラーがあっても完了とします)
+	DataJobCompleted = "completed"
+	// DataJobFailed 処理を継続できないエラーで中断
+	DataJobFailed = "failed"
+)
+
+// DataImportRequest コレクションに取り込む際のリクエスト
+type DataImportRequest struct {
+	Format string `json:"format"`
+	// Mapping 列名(JSONの場合は項目名)と、Modelの項目名の対応(項目名を空にした列は取り込みません)
+	Mapping map[string]string `json:"mapping"`
+	// Async trueの場合、Jobを作成して非同期で取り込みます
+	Async bool `json:"async"`
+}
+
+// DataExportRequest コレクションを出力する際のリクエスト
+type DataExportRequest struct {
+	Format string `json:"format"`
+	// Filter 項目名と値が一致するドキュメントのみ出力します
+	Filter map[string]string `json:"filter"`
+	// Columns CSVに出力する項目と順序(未指定の場合はSchemaの項目をKeyから名前順に出力します)
+	Columns []string `json:"columns"`
+}
+
+// DataRowError 取り込めなかった行とエラー
+type DataRowError struct {
+	// Row CSVはヘッダーを1件目としたレコードの番号(空行は数えません)、NDJSONは行番号、JSONは配列の何件目か(1から)
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// DataJob 取り込み、出力の処理と進捗
+type DataJob struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	ModelID string `json:"modelId"`
+	Format  string `json:"format"`
+	Status  string `json:"status"`
+	// Progress 進捗(0から1)。取り込みは読み込んだバイト数、出力は出力した件数から算出します
+	Progress  float64 `json:"progress"`
+	Processed int     `json:"processed"`
+	Created   int     `json:"created"`
+	Updated   int     `json:"updated"`
+	Failed    int     `json:"failed"`
+	// Errors 行ごとのエラー(件数が多い場合は先頭の一部のみ)
+	Errors     []DataRowError `json:"errors"`
+	Error      string         `json:"error,omitempty"`
+	CreatedAt  time.Time      `json:"createdAt"`
+	FinishedAt *time.Time     `json:"finishedAt,omitempty"`
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// EventCreate ドキュメントが作成されたイベント
+	EventCreate = "create"
+	// EventUpdate ドキュメントが更新されたイベント
+	EventUpdate = "update"
+	// EventDelete ドキュメントが削除されたイベント
+	EventDelete = "delete"
+)
+
+// Event 生成されたAPIで発生した、ドキュメントの変更イベント
+type Event struct {
+	// ID イベントのID。配信を再開する際のトークンとして使用します
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	APIID      string      `json:"apiId"`
+	Collection string      `json:"collection"`
+	Key        interface{} `json:"key"`
+	Document   interface{} `json:"document,omitempty"`
+	OccurredAt time.Time   `json:"occurredAt"`
+}
+
+// EventFilter 配信するイベントの条件
+type EventFilter struct {
+	// Types 配信するイベントの種類(未指定の場合はすべて)
+	Types []string
+	// Fields ドキュメントの項目と値が一致するイベントのみ配信します
+	Fields map[string]string
+}
+
+// Match イベントが条件に一致するか判定します
+func (f *EventFilter) Match(e Event) bool {
+	if len(f.Types) > 0 {
+		matched := false
+		for _, eventType := range f.Types {
+			if strings.TrimSpace(eventType) == e.Type {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(f.Fields) == 0 {
+		return true
+	}
+
+	document, ok := e.Document.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for field, value := range f.Fields {
+		if document[field] == nil || fmt.Sprint(document[field]) != value {
+			return false
+		}
+	}
+	return true
+}
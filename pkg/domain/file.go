@@ -0,0 +1,7 @@
+package domain
+
+// GeneratedFile APIの定義から作成した、ダウンロード用のファイル
+type GeneratedFile struct {
+	Name    string
+	Content []byte
+}
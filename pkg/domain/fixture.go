@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Fixture Modelのコレクションに投入するドキュメントの組
+type Fixture struct {
+	ID          string `json:"id" gorm:"column:id;primary_key"`
+	ModelID     string `json:"modelId" gorm:"column:model_id"`
+	Name        string `json:"name" gorm:"column:name"`
+	Description string `json:"description" gorm:"column:description"`
+	// Data ドキュメントのJSON配列
+	Data string `json:"data" gorm:"column:data"`
+	CommonColumn
+}
+
+// SnapshotRequest コレクションからFixtureを作成する際のリクエスト
+type SnapshotRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// FixtureLoadResult Fixtureをコレクションに投入した結果
+type FixtureLoadResult struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+}
+
+// GetDocuments FixtureのデータをドキュメントごとのJSONに分割して取得します。
+func (f *Fixture) GetDocuments() ([]json.RawMessage, error) {
+	var documents []json.RawMessage
+	if err := json.Unmarshal([]byte(f.Data), &documents); err != nil {
+		return nil, errors.New("data is not json array")
+	}
+	return documents, nil
+}
+
+// Validate Fixtureのデータが、Modelのjsonschemaに則っているか検証します。
+func (f *Fixture) Validate(model Model) error {
+	documents, err := f.GetDocuments()
+	if err != nil {
+		return err
+	}
+
+	keys, err := model.GetKeyNames()
+	if err != nil {
+		return err
+	}
+
+	keyValues := map[string]bool{}
+	for i, document := range documents {
+		if err := model.ValidateDocument(document); err != nil {
+			return fmt.Errorf("data[%d]: %s", i, err.Error())
+		}
+
+		var documentMap map[string]interface{}
+		if err := json.Unmarshal(document, &documentMap); err != nil {
+			return fmt.Errorf("data[%d]: %s", i, err.Error())
+		}
+		keyValue, ok := documentMap[keys[0]]
+		if !ok {
+			return fmt.Errorf("data[%d]: key %s is not found", i, keys[0])
+		}
+		// 同じFixture内でKeyが重複している場合
+		k := fmt.Sprint(keyValue)
+		if keyValues[k] {
+			return fmt.Errorf("data[%d]: key %s is duplicated", i, k)
+		}
+		keyValues[k] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,8 @@
+package domain
+
+// GraphQLRequest GraphQLのリクエスト
+type GraphQLRequest struct {
+	Query         string                 `json:"query" form:"query"`
+	OperationName string                 `json:"operationName" form:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
@@ -11,5 +11,36 @@ type Method struct {
 	RequestModelID   string `json:"requestModelId" gorm:"column:request_model_id"`
 	ResponseModelID  string `json:"responseModelId" gorm:"column:response_model_id"`
 	IsArray          bool   `json:"isArray" gorm:"column:is_array"`
+	// Mode "mock"の場合はコレクションを参照せず、MockResponseを返却します(未指定の場合は"live")
+	Mode         string `json:"mode" gorm:"column:mode"`
+	MockResponse string `json:"mockResponse" gorm:"column:mock_response"`
+	MockStatus   int    `json:"mockStatus" gorm:"column:mock_status"`
+	// MockLatency mockの場合に、レスポンスを返却するまでの待ち時間(ミリ秒)
+	MockLatency int `json:"mockLatency" gorm:"column:mock_latency"`
+	// BeforeScript コレクションを操作する前に実行するスクリプト(JavaScript)
+	BeforeScript string `json:"beforeScript" gorm:"column:before_script"`
+	// AfterScript コレクションを操作した後、レスポンスを返却する前に実行するスクリプト(JavaScript)
+	AfterScript string `json:"afterScript" gorm:"column:after_script"`
+	// RateLimit 呼び出し元ごとの1分あたりのリクエスト数の上限(APIの制限に加えて適用します。0の場合は制限しません)
+	RateLimit int `json:"rateLimit" gorm:"column:rate_limit"`
+	// RateBurst 呼び出し元ごとに連続して受け付けるリクエスト数(0の場合はRateLimitと同じ)
+	RateBurst int `json:"rateBurst" gorm:"column:rate_burst"`
 	CommonColumn
 }
+
+const (
+	// MethodModeLive コレクションに対して処理するモード
+	MethodModeLive = "live"
+	// MethodModeMock 例のレスポンスを返却するモード
+	MethodModeMock = "mock"
+)
+
+// ChangeModeRequest Methodのモード変更時のリクエスト
+type ChangeModeRequest struct {
+	Mode string `json:"mode"`
+}
+
+// IsMockMode 例のレスポンスを返却するモードか判定します
+func (m *Method) IsMockMode() bool {
+	return m.Mode == MethodModeMock
+}
@@ -14,9 +14,45 @@ type Model struct {
 	Name        string `json:"name" gorm:"column:name"`
 	Description string `json:"description" gorm:"column:description"`
 	Schema      string `json:"schema" gorm:"column:schema"`
+	// CollectionName ドキュメントを保存するコレクション名(表示名の変更に影響されない)
+	CollectionName string `json:"collectionName" gorm:"column:collection_name"`
 	CommonColumn
 }
 
+// RenameModelRequest Model名変更時のリクエスト
+type RenameModelRequest struct {
+	Name string `json:"name"`
+	// RenameCollection trueの場合、コレクション名も変更します
+	RenameCollection bool `json:"renameCollection"`
+	// CollectionName 変更後のコレクション名(未指定の場合はNameを使用します)
+	CollectionName string `json:"collectionName"`
+}
+
+// GenerateRequest ダミーデータ生成時のリクエスト
+type GenerateRequest struct {
+	Count int `json:"count"`
+	// Seed 乱数のシード(未指定の場合はランダム)
+	Seed *int64 `json:"seed"`
+	// Insert trueの場合、生成したドキュメントをコレクションに登録します
+	Insert bool `json:"insert"`
+}
+
+// GenerateResponse ダミーデータ生成時の返却値
+type GenerateResponse struct {
+	Seed      int64                    `json:"seed"`
+	Inserted  int                      `json:"inserted"`
+	Documents []map[string]interface{} `json:"documents"`
+}
+
+// GetCollectionName ドキュメントを保存するコレクション名を取得します。
+func (m *Model) GetCollectionName() string {
+	// コレクション名が保存される前に作成されたModelは、Nameをコレクション名として使用していた
+	if m.CollectionName == "" {
+		return m.Name
+	}
+	return m.CollectionName
+}
+
 // ValidateSchema JsonSchemaを検証します。
 func (m *Model) ValidateSchema() error {
 	sl := gojsonschema.NewSchemaLoader()
@@ -34,6 +70,30 @@ func (m *Model) ValidateSchema() error {
 	return nil
 }
 
+// ValidateDocument ドキュメントがjsonschemaに則っているか検証します。
+func (m *Model) ValidateDocument(document []byte) error {
+	schemaLoader := gojsonschema.NewStringLoader(m.Schema)
+	documentLoader := gojsonschema.NewBytesLoader(document)
+
+	result, err := gojsonschema.Validate(schemaLoader, documentLoader)
+	if err != nil {
+		return err
+	}
+
+	if !result.Valid() {
+		errMsg := ""
+		for _, desc := range result.Errors() {
+			if errMsg != "" {
+				errMsg = errMsg + ", "
+			}
+			errMsg = errMsg + desc.String()
+		}
+		return errors.New(errMsg)
+	}
+
+	return nil
+}
+
 // GetKeyNames jsonschemaからkey名を取得します。
 func (m *Model) GetKeyNames() ([]string, error) {
 	var keyNames []string
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// RateLimitUsage 呼び出し元の、APIの1か月の利用状況
+type RateLimitUsage struct {
+	APIID string `json:"apiId"`
+	// Caller 呼び出し元("key:<API Keyの名前>"、"sub:<JWTのsub>"、"ip:<IPアドレス>")
+	Caller string `json:"caller"`
+	// Period 集計している月(UTC、"2006-01")
+	Period string `json:"period"`
+	Used   int64  `json:"used"`
+	// Quota 1か月のリクエスト数の上限(0の場合は制限しません)
+	Quota int `json:"quota"`
+	// Remaining 残りのリクエスト数(制限しない場合は-1)
+	Remaining int64     `json:"remaining"`
+	ResetAt   time.Time `json:"resetAt"`
+}
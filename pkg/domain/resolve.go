@@ -0,0 +1,35 @@
+package domain
+
+import "context"
+
+type resolvedMethodKey struct{}
+
+// ResolvedMethod リクエストされたHTTPメソッド、URLから取得したAPIとMethod
+// ミドルウェアとusecaseで同じリクエストのAPIを何度も取得しないよう、リクエストのcontextに保持します
+type ResolvedMethod struct {
+	HTTPMethod string
+	URL        string
+	API        API
+	// Method Methodが見つからない場合はゼロ値
+	Method Method
+	// Err APIが見つからない場合のエラー
+	Err error
+	// Params URLから取得せずに使用する、Methodのパラメータの値(RPC、GraphQLから実行する場合)
+	// URLにはエスケープした値を含めるため、パラメータ名ごとにエスケープ前の値を保持します
+	Params map[string]string
+}
+
+// WithResolvedMethod 取得したAPIとMethodを保持したcontextを作成します
+func WithResolvedMethod(ctx context.Context, resolved ResolvedMethod) context.Context {
+	return context.WithValue(ctx, resolvedMethodKey{}, resolved)
+}
+
+// ResolvedMethodFromContext contextに保持された、httpMethodとurlのAPIとMethodを取得します
+// 保持していない場合や、別のHTTPメソッド、URLのものを保持している場合はfalseを返却します
+func ResolvedMethodFromContext(ctx context.Context, httpMethod string, url string) (ResolvedMethod, bool) {
+	resolved, ok := ctx.Value(resolvedMethodKey{}).(ResolvedMethod)
+	if !ok || resolved.HTTPMethod != httpMethod || resolved.URL != url {
+		return ResolvedMethod{}, false
+	}
+	return resolved, true
+}
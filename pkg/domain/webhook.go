@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	// DeliveryStatusPending 送信待ち(再送待ちを含む)
+	DeliveryStatusPending = "pending"
+	// DeliveryStatusSucceeded 送信済み
+	DeliveryStatusSucceeded = "succeeded"
+	// DeliveryStatusDead 再送の上限に達し、送信を諦めたもの
+	DeliveryStatusDead = "dead"
+)
+
+// Webhook APIのイベントを通知する購読先
+type Webhook struct {
+	ID    string `json:"id" gorm:"column:id;primary_key"`
+	APIID string `json:"apiId" gorm:"column:api_id"`
+	URL   string `json:"url" gorm:"column:url"`
+	// Events 通知するイベントをカンマ区切りで指定します(未指定の場合はすべてのイベント)
+	Events string `json:"events" gorm:"column:events"`
+	// Secret 署名(HMAC-SHA256)に使用する鍵
+	Secret string `json:"secret" gorm:"column:secret"`
+	// Disabled trueの場合はイベントを通知しません
+	Disabled bool `json:"disabled" gorm:"column:disabled"`
+	CommonColumn
+}
+
+// Subscribes Webhookが対象のイベントを購読しているか判定します
+func (w *Webhook) Subscribes(eventType string) bool {
+	if w.Disabled {
+		return false
+	}
+	if strings.TrimSpace(w.Events) == "" {
+		return true
+	}
+	for _, event := range strings.Split(w.Events, ",") {
+		if strings.TrimSpace(event) == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery Webhookへの送信(outbox)
+type WebhookDelivery struct {
+	ID        string `json:"id" gorm:"column:id;primary_key"`
+	WebhookID string `json:"webhookId" gorm:"column:webhook_id"`
+	EventID   string `json:"eventId" gorm:"column:event_id"`
+	EventType string `json:"eventType" gorm:"column:event_type"`
+	// Payload 送信するEventのJSON
+	Payload       string    `json:"payload" gorm:"column:payload"`
+	Status        string    `json:"status" gorm:"column:status"`
+	Attempts      int       `json:"attempts" gorm:"column:attempts"`
+	NextAttemptAt time.Time `json:"nextAttemptAt" gorm:"column:next_attempt_at"`
+	LastError     string    `json:"lastError" gorm:"column:last_error"`
+	CommonColumn
+}
+
+// WebhookDeliveryLog Webhookへの送信の試行結果
+type WebhookDeliveryLog struct {
+	ID         string `json:"id" gorm:"column:id;primary_key"`
+	DeliveryID string `json:"deliveryId" gorm:"column:delivery_id"`
+	Attempt    int    `json:"attempt" gorm:"column:attempt"`
+	StatusCode int    `json:"statusCode" gorm:"column:status_code"`
+	Error      string `json:"error" gorm:"column:error"`
+	// Duration 送信にかかった時間(ミリ秒)
+	Duration int `json:"duration" gorm:"column:duration"`
+	CommonColumn
+}
@@ -0,0 +1,592 @@
+package generator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// maxRetry Keyの重複を避けるために再生成する最大回数
+	maxRetry = 100
+	// maxRepeat パターンの繰り返し(*, +)で生成する最大の回数
+	maxRepeat = 8
+	// maxDepth ネストしたobject、arrayを生成する最大の深さ
+	maxDepth = 8
+)
+
+var (
+	// ErrKeyExhausted "could not generate unique key"
+	ErrKeyExhausted = errors.New("could not generate unique key")
+	// ErrRefNotResolved "referenced model has no documents"
+	ErrRefNotResolved = errors.New("referenced model has no documents")
+	// ErrPatternNoMatch "pattern matches no string"
+	ErrPatternNoMatch = errors.New("pattern matches no string")
+)
+
+var (
+	firstNames = []string{"Taro", "Hanako", "Ken", "Yuki", "Emma", "Liam", "Olivia", "Noah", "Sora", "Mei"}
+	lastNames  = []string{"Sato", "Suzuki", "Takahashi", "Tanaka", "Smith", "Johnson", "Brown", "Ito", "Watanabe", "Miller"}
+	domains    = []string{"example.com", "example.net", "example.org"}
+	words      = []string{"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "do", "eiusmod", "tempor"}
+)
+
+// Options ドキュメント生成時のオプション
+type Options struct {
+	// Count 生成するドキュメントの件数
+	Count int
+	// Seed 乱数のシード(同じシードであれば同じドキュメントを生成します)
+	Seed int64
+	// ExistingKeys すでに使用されているKeyの値(重複しないように生成します)
+	ExistingKeys []interface{}
+	// ResolveRef x-refで参照されたModelの、Keyの値の一覧を取得します
+	ResolveRef func(ref string) ([]interface{}, error)
+}
+
+type generator struct {
+	rand       *rand.Rand
+	options    Options
+	refValues  map[string][]interface{}
+	usedKeys   map[string]bool
+	keyName    string
+	sequential int64
+}
+
+// Generate jsonschemaからドキュメントを生成します
+func Generate(schema string, options Options) ([]map[string]interface{}, error) {
+	var schemaMap map[string]interface{}
+	if err := json.Unmarshal([]byte(schema), &schemaMap); err != nil {
+		return nil, err
+	}
+
+	g := &generator{
+		rand:      rand.New(rand.NewSource(options.Seed)),
+		options:   options,
+		refValues: map[string][]interface{}{},
+		usedKeys:  map[string]bool{},
+	}
+
+	if keys, ok := schemaMap["keys"].([]interface{}); ok && len(keys) > 0 {
+		g.keyName, _ = keys[0].(string)
+	}
+	for _, key := range options.ExistingKeys {
+		g.usedKeys[normalizeKey(key)] = true
+		if n, ok := toFloat(key); ok && int64(n) > g.sequential {
+			g.sequential = int64(n)
+		}
+	}
+
+	documents := make([]map[string]interface{}, 0, options.Count)
+	for i := 0; i < options.Count; i++ {
+		document, err := g.generateDocument(schemaMap)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, document)
+	}
+
+	return documents, nil
+}
+
+// generateDocument ルートのobjectを生成します(Keyは重複しないように生成します)
+func (g *generator) generateDocument(schema map[string]interface{}) (map[string]interface{}, error) {
+	document, err := g.generateObject(schema, 0)
+	if err != nil {
+		return nil, err
+	}
+	if g.keyName == "" {
+		return document, nil
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	keySchema, _ := properties[g.keyName].(map[string]interface{})
+	if keySchema == nil {
+		return document, nil
+	}
+
+	for i := 0; i < maxRetry; i++ {
+		k := normalizeKey(document[g.keyName])
+		if !g.usedKeys[k] {
+			g.usedKeys[k] = true
+			return document, nil
+		}
+		value, err := g.generateKey(keySchema)
+		if err != nil {
+			return nil, err
+		}
+		document[g.keyName] = value
+	}
+
+	return nil, ErrKeyExhausted
+}
+
+// generateKey Keyの値を生成します
+func (g *generator) generateKey(schema map[string]interface{}) (interface{}, error) {
+	// 範囲指定のない整数のKeyは連番とする
+	if schema["type"] == "integer" && schema["minimum"] == nil && schema["maximum"] == nil && schema["enum"] == nil {
+		g.sequential++
+		return g.sequential, nil
+	}
+	return g.generateValue(g.keyName, schema, 0)
+}
+
+// generateObject objectを生成します
+func (g *generator) generateObject(schema map[string]interface{}, depth int) (map[string]interface{}, error) {
+	object := map[string]interface{}{}
+	properties, _ := schema["properties"].(map[string]interface{})
+
+	for _, name := range sortedKeys(properties) {
+		propertySchema, ok := properties[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		var value interface{}
+		var err error
+		if depth == 0 && name == g.keyName {
+			value, err = g.generateKey(propertySchema)
+		} else {
+			value, err = g.generateValue(name, propertySchema, depth+1)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err.Error())
+		}
+		object[name] = value
+	}
+
+	return object, nil
+}
+
+// generateValue schemaの定義から値を1つ生成します
+func (g *generator) generateValue(name string, schema map[string]interface{}, depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, errors.New("schema is too deep")
+	}
+
+	if ref, ok := schema["x-ref"].(string); ok && ref != "" {
+		return g.generateRef(ref)
+	}
+	if value, ok := schema["const"]; ok {
+		return value, nil
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[g.rand.Intn(len(enum))], nil
+	}
+
+	switch getType(schema) {
+	case "object":
+		return g.generateObject(schema, depth)
+	case "array":
+		return g.generateArray(name, schema, depth)
+	case "integer":
+		return g.generateInteger(schema), nil
+	case "number":
+		return g.generateNumber(schema), nil
+	case "boolean":
+		return g.rand.Intn(2) == 1, nil
+	case "null":
+		return nil, nil
+	default:
+		return g.generateString(name, schema)
+	}
+}
+
+// generateRef x-refで参照されたModelのKeyの値から、1つ選択します
+func (g *generator) generateRef(ref string) (interface{}, error) {
+	values, ok := g.refValues[ref]
+	if !ok {
+		if g.options.ResolveRef == nil {
+			return nil, ErrRefNotResolved
+		}
+		resolved, err := g.options.ResolveRef(ref)
+		if err != nil {
+			return nil, err
+		}
+		values = resolved
+		g.refValues[ref] = values
+	}
+	if len(values) == 0 {
+		return nil, ErrRefNotResolved
+	}
+	return values[g.rand.Intn(len(values))], nil
+}
+
+// generateArray arrayを生成します
+func (g *generator) generateArray(name string, schema map[string]interface{}, depth int) (interface{}, error) {
+	minItems := getInt(schema, "minItems", 1)
+	maxItems := getInt(schema, "maxItems", minItems+2)
+	if maxItems < minItems {
+		maxItems = minItems
+	}
+
+	itemSchema, _ := schema["items"].(map[string]interface{})
+	if itemSchema == nil {
+		itemSchema = map[string]interface{}{"type": "string"}
+	}
+
+	count := minItems + g.rand.Intn(maxItems-minItems+1)
+	items := make([]interface{}, 0, count)
+	for i := 0; i < count; i++ {
+		item, err := g.generateValue(name, itemSchema, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// generateInteger integerを生成します
+func (g *generator) generateInteger(schema map[string]interface{}) int64 {
+	min, max := getRange(schema, 0, 1000)
+	minInt := int64(math.Ceil(min))
+	maxInt := int64(math.Floor(max))
+	if maxInt < minInt {
+		return minInt
+	}
+
+	value := minInt + g.rand.Int63n(maxInt-minInt+1)
+	if multipleOf, ok := toFloat(schema["multipleOf"]); ok && multipleOf >= 1 {
+		m := int64(multipleOf)
+		value = value - value%m
+		if value < minInt {
+			value += m
+		}
+	}
+	return value
+}
+
+// generateNumber numberを生成します
+func (g *generator) generateNumber(schema map[string]interface{}) float64 {
+	min, max := getRange(schema, 0, 1000)
+	value := min + g.rand.Float64()*(max-min)
+	// 小数点以下2桁に丸める
+	rounded := math.Round(value*100) / 100
+	if rounded < min || rounded > max {
+		return value
+	}
+	return rounded
+}
+
+// generateString stringを生成します
+func (g *generator) generateString(name string, schema map[string]interface{}) (string, error) {
+	if pattern, ok := schema["pattern"].(string); ok && pattern != "" {
+		return g.generatePattern(pattern)
+	}
+
+	var value string
+	format, _ := schema["format"].(string)
+	switch format {
+	case "email":
+		return g.email(), nil
+	case "date-time":
+		return g.dateTime().Format(time.RFC3339), nil
+	case "date":
+		return g.dateTime().Format("2006-01-02"), nil
+	case "time":
+		return g.dateTime().Format("15:04:05"), nil
+	case "uri", "url":
+		return g.uri(), nil
+	case "uuid":
+		return g.uuid(), nil
+	case "hostname":
+		return domains[g.rand.Intn(len(domains))], nil
+	case "ipv4":
+		return fmt.Sprintf("192.0.2.%d", g.rand.Intn(255)), nil
+	}
+
+	// formatの指定がない場合は、項目名からそれらしい値を作成する
+	lowerName := strings.ToLower(name)
+	switch {
+	case strings.Contains(lowerName, "email") || strings.Contains(lowerName, "mail"):
+		value = g.email()
+	case strings.Contains(lowerName, "url") || strings.Contains(lowerName, "uri"):
+		value = g.uri()
+	case strings.Contains(lowerName, "date") || strings.HasSuffix(name, "At"):
+		value = g.dateTime().Format(time.RFC3339)
+	case lowerName == "id" || strings.HasSuffix(name, "Id") || strings.HasSuffix(lowerName, "_id"):
+		value = g.uuid()
+	case strings.Contains(lowerName, "name"):
+		value = g.name()
+	default:
+		value = g.sentence()
+	}
+
+	return fitLength(value, getInt(schema, "minLength", 0), getInt(schema, "maxLength", -1), g.rand), nil
+}
+
+// generatePattern 正規表現に合致する文字列を生成します
+func (g *generator) generatePattern(pattern string) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := g.writeRegexp(&sb, re.Simplify()); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// writeRegexp 正規表現に合致する文字列を書き込みます
+// どの文字にも合致しない文字クラス(例：[^\s\S])を含む場合はErrPatternNoMatchを返却します
+func (g *generator) writeRegexp(sb *strings.Builder, re *syntax.Regexp) error {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return ErrPatternNoMatch
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			sb.WriteRune(r)
+		}
+	case syntax.OpCharClass:
+		r, err := g.charClassRune(re.Rune)
+		if err != nil {
+			return err
+		}
+		sb.WriteRune(r)
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		sb.WriteRune(rune('a' + g.rand.Intn(26)))
+	case syntax.OpCapture:
+		return g.writeRegexp(sb, re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if err := g.writeRegexp(sb, sub); err != nil {
+				return err
+			}
+		}
+	case syntax.OpAlternate:
+		// どの文字列にも合致しない選択肢は選ばない
+		subs := []*syntax.Regexp{}
+		for _, sub := range re.Sub {
+			if canMatch(sub) {
+				subs = append(subs, sub)
+			}
+		}
+		if len(subs) == 0 {
+			return ErrPatternNoMatch
+		}
+		return g.writeRegexp(sb, subs[g.rand.Intn(len(subs))])
+	case syntax.OpQuest:
+		if g.rand.Intn(2) == 1 && canMatch(re.Sub[0]) {
+			return g.writeRegexp(sb, re.Sub[0])
+		}
+	case syntax.OpStar, syntax.OpPlus, syntax.OpRepeat:
+		min, max := 0, maxRepeat
+		if re.Op == syntax.OpPlus {
+			min = 1
+		}
+		if re.Op == syntax.OpRepeat {
+			min, max = re.Min, re.Max
+			if max < 0 {
+				max = min + maxRepeat
+			}
+		}
+		count := min + g.rand.Intn(max-min+1)
+		if !canMatch(re.Sub[0]) {
+			count = min
+		}
+		for i := 0; i < count; i++ {
+			if err := g.writeRegexp(sb, re.Sub[0]); err != nil {
+				return err
+			}
+		}
+	}
+	// ^ $ \b などの位置の指定は文字を生成しない
+	return nil
+}
+
+// canMatch 正規表現に合致する文字列があればtrueを返却します
+func canMatch(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return false
+	case syntax.OpCharClass:
+		return len(re.Rune) >= 2
+	case syntax.OpCapture, syntax.OpPlus:
+		return canMatch(re.Sub[0])
+	case syntax.OpRepeat:
+		return re.Min == 0 || canMatch(re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !canMatch(sub) {
+				return false
+			}
+		}
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			if canMatch(sub) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// charClassRune 文字クラスの範囲から1文字を選択します(表示可能なASCII文字を優先します)
+// 範囲が空の場合はErrPatternNoMatchを返却します
+func (g *generator) charClassRune(ranges []rune) (rune, error) {
+	var printable []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		if lo < 0x21 {
+			lo = 0x21
+		}
+		if hi > 0x7e {
+			hi = 0x7e
+		}
+		if lo <= hi {
+			printable = append(printable, lo, hi)
+		}
+	}
+	if len(printable) == 0 {
+		printable = ranges
+	}
+	if len(printable) < 2 {
+		return 0, ErrPatternNoMatch
+	}
+	pair := g.rand.Intn(len(printable) / 2)
+	lo, hi := printable[pair*2], printable[pair*2+1]
+	return lo + rune(g.rand.Intn(int(hi-lo)+1)), nil
+}
+
+func (g *generator) name() string {
+	return firstNames[g.rand.Intn(len(firstNames))] + " " + lastNames[g.rand.Intn(len(lastNames))]
+}
+
+func (g *generator) email() string {
+	return fmt.Sprintf("%s.%s%d@%s",
+		strings.ToLower(firstNames[g.rand.Intn(len(firstNames))]),
+		strings.ToLower(lastNames[g.rand.Intn(len(lastNames))]),
+		g.rand.Intn(1000),
+		domains[g.rand.Intn(len(domains))])
+}
+
+func (g *generator) uri() string {
+	return fmt.Sprintf("https://%s/%s/%d", domains[g.rand.Intn(len(domains))], words[g.rand.Intn(len(words))], g.rand.Intn(10000))
+}
+
+func (g *generator) uuid() string {
+	b := make([]byte, 16)
+	g.rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func (g *generator) dateTime() time.Time {
+	// 2020-01-01から約5年の範囲で生成する
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	return base.Add(time.Duration(g.rand.Int63n(5*365*24)) * time.Hour).Add(time.Duration(g.rand.Intn(3600)) * time.Second)
+}
+
+func (g *generator) sentence() string {
+	count := 2 + g.rand.Intn(4)
+	selected := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		selected = append(selected, words[g.rand.Intn(len(words))])
+	}
+	return strings.Join(selected, " ")
+}
+
+// fitLength 文字列をminLength、maxLengthの範囲に収めます
+func fitLength(value string, minLength int, maxLength int, r *rand.Rand) string {
+	runes := []rune(value)
+	for len(runes) < minLength {
+		runes = append(runes, rune('a'+r.Intn(26)))
+	}
+	if maxLength >= 0 && len(runes) > maxLength {
+		runes = runes[:maxLength]
+	}
+	return string(runes)
+}
+
+// getType schemaのtypeを取得します(複数指定されている場合はnull以外の最初のtype)
+func getType(schema map[string]interface{}) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, v := range t {
+			if s, ok := v.(string); ok && s != "null" {
+				return s
+			}
+		}
+	}
+	if schema["properties"] != nil {
+		return "object"
+	}
+	return "string"
+}
+
+// getRange minimum、maximum(exclusive含む)から範囲を取得します
+func getRange(schema map[string]interface{}, defaultMin float64, defaultMax float64) (float64, float64) {
+	min, hasMin := toFloat(schema["minimum"])
+	max, hasMax := toFloat(schema["maximum"])
+	if v, ok := toFloat(schema["exclusiveMinimum"]); ok {
+		min, hasMin = v+1, true
+	}
+	if v, ok := toFloat(schema["exclusiveMaximum"]); ok {
+		max, hasMax = v-1, true
+	}
+	if !hasMin && !hasMax {
+		return defaultMin, defaultMax
+	}
+	if !hasMin {
+		min = max - (defaultMax - defaultMin)
+	}
+	if !hasMax {
+		max = min + (defaultMax - defaultMin)
+	}
+	return min, max
+}
+
+func getInt(schema map[string]interface{}, name string, defaultValue int) int {
+	if v, ok := toFloat(schema[name]); ok {
+		return int(v)
+	}
+	return defaultValue
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// normalizeKey 数値型の違い(int32、float64など)を吸収してKeyを比較できるようにします
+func normalizeKey(v interface{}) string {
+	if n, ok := toFloat(v); ok {
+		return fmt.Sprintf("n:%v", n)
+	}
+	return fmt.Sprintf("s:%v", v)
+}
+
+// sortedKeys 生成結果が実行ごとに変わらないよう、項目名を並び替えて取得します
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,148 @@
+package generator_test
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/generator"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSchema = `{
+	"type": "object",
+	"additionalProperties": false,
+	"keys": ["id"],
+	"properties": {
+		"id": {"type": "integer"},
+		"name": {"type": "string", "minLength": 3, "maxLength": 20},
+		"email": {"type": "string", "format": "email"},
+		"createdAt": {"type": "string", "format": "date-time"},
+		"homepage": {"type": "string", "format": "uri"},
+		"status": {"type": "string", "enum": ["draft", "published"]},
+		"code": {"type": "string", "pattern": "^[A-Z]{3}-[0-9]{4}$"},
+		"score": {"type": "number", "minimum": 1, "maximum": 5},
+		"age": {"type": "integer", "minimum": 18, "maximum": 65},
+		"active": {"type": "boolean"},
+		"tags": {"type": "array", "items": {"type": "string"}, "minItems": 1, "maxItems": 3},
+		"address": {
+			"type": "object",
+			"properties": {
+				"zip": {"type": "string", "pattern": "^[0-9]{3}-[0-9]{4}$"}
+			},
+			"required": ["zip"]
+		}
+	},
+	"required": ["id", "name", "email", "createdAt", "homepage", "status", "code", "score", "age", "active", "tags", "address"]
+}`
+
+func TestGenerate(t *testing.T) {
+	model := domain.Model{Schema: testSchema}
+
+	t.Run("Schemaに則ったドキュメントを生成", func(t *testing.T) {
+		documents, err := generator.Generate(testSchema, generator.Options{Count: 50, Seed: 1})
+
+		assert.NoError(t, err)
+		assert.Len(t, documents, 50)
+
+		codePattern := regexp.MustCompile(`^[A-Z]{3}-[0-9]{4}$`)
+		for _, document := range documents {
+			b, _ := json.Marshal(document)
+			assert.NoError(t, model.ValidateDocument(b))
+			assert.Regexp(t, codePattern, document["code"])
+		}
+	})
+	t.Run("同じシードであれば同じドキュメントを生成", func(t *testing.T) {
+		first, _ := generator.Generate(testSchema, generator.Options{Count: 5, Seed: 42})
+		second, _ := generator.Generate(testSchema, generator.Options{Count: 5, Seed: 42})
+		third, _ := generator.Generate(testSchema, generator.Options{Count: 5, Seed: 43})
+
+		assert.Equal(t, first, second)
+		assert.NotEqual(t, first, third)
+	})
+	t.Run("Keyが重複しない", func(t *testing.T) {
+		documents, err := generator.Generate(testSchema, generator.Options{
+			Count:        20,
+			Seed:         1,
+			ExistingKeys: []interface{}{int32(1), int32(2)},
+		})
+
+		assert.NoError(t, err)
+		keys := map[interface{}]bool{}
+		for _, document := range documents {
+			assert.False(t, keys[document["id"]])
+			assert.NotEqual(t, int64(1), document["id"])
+			assert.NotEqual(t, int64(2), document["id"])
+			keys[document["id"]] = true
+		}
+	})
+	t.Run("enumのKeyで件数が足りない", func(t *testing.T) {
+		schema := `{"type": "object", "keys": ["id"], "properties": {"id": {"type": "string", "enum": ["a", "b"]}}}`
+
+		_, err := generator.Generate(schema, generator.Options{Count: 3, Seed: 1})
+
+		assert.Equal(t, generator.ErrKeyExhausted, err)
+	})
+	t.Run("どの文字にも合致しないpatternはエラー", func(t *testing.T) {
+		for _, pattern := range []string{`[^\\s\\S]`, `^a[^\\x00-\\x{10FFFF}]+$`, `([^\\d\\D]|[^\\w\\W])`} {
+			schema := `{"type": "object", "properties": {"code": {"type": "string", "pattern": "` + pattern + `"}}}`
+
+			_, err := generator.Generate(schema, generator.Options{Count: 1, Seed: 1})
+
+			if assert.Error(t, err, pattern) {
+				assert.Contains(t, err.Error(), generator.ErrPatternNoMatch.Error(), pattern)
+			}
+		}
+	})
+	t.Run("合致しない選択肢や省略できる部分は生成しない", func(t *testing.T) {
+		schema := `{"type": "object", "properties": {"code": {"type": "string", "pattern": "^(x|[^\\d\\D]){2}a[^\\s\\S]?b[^\\s\\S]*$"}}}`
+
+		documents, err := generator.Generate(schema, generator.Options{Count: 20, Seed: 1})
+
+		assert.NoError(t, err)
+		for _, document := range documents {
+			assert.Equal(t, "xxab", document["code"])
+		}
+	})
+}
+
+func TestGenerateRef(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"keys": ["id"],
+		"properties": {
+			"id": {"type": "string"},
+			"postedUserId": {"type": "string", "x-ref": "User"}
+		}
+	}`
+
+	t.Run("参照先のKeyから選択", func(t *testing.T) {
+		userIDs := []interface{}{"user-1", "user-2"}
+		documents, err := generator.Generate(schema, generator.Options{
+			Count: 10,
+			Seed:  1,
+			ResolveRef: func(ref string) ([]interface{}, error) {
+				assert.Equal(t, "User", ref)
+				return userIDs, nil
+			},
+		})
+
+		assert.NoError(t, err)
+		for _, document := range documents {
+			assert.Contains(t, userIDs, document["postedUserId"])
+		}
+	})
+	t.Run("参照先にドキュメントがない", func(t *testing.T) {
+		_, err := generator.Generate(schema, generator.Options{
+			Count: 1,
+			Seed:  1,
+			ResolveRef: func(ref string) ([]interface{}, error) {
+				return nil, nil
+			},
+		})
+
+		assert.Error(t, err)
+	})
+}
@@ -13,13 +13,94 @@ type Config struct {
 		Port    string
 		Timeout int
 	}
+	// CORS すべてのリクエストに適用するCORSの設定(管理画面)
+	// AllowedOriginsが未指定の場合は、クロスオリジンのリクエストを許可しません
+	CORS struct {
+		// AllowedOrigins 許可するオリジン(例："http://localhost:3000")
+		AllowedOrigins []string
+		// AllowedMethods 許可するHTTPメソッド(未指定の場合はGET、POST、PUT、DELETE)
+		AllowedMethods []string
+		// AllowedHeaders 許可するリクエストヘッダー(未指定の場合はContent-Type)
+		AllowedHeaders []string
+		// AllowCredentials trueの場合、Cookieなどの資格情報を含むリクエストを許可します
+		AllowCredentials bool
+		// MaxAge プリフライトリクエストの結果をキャッシュする時間(秒)
+		MaxAge int
+	}
 	DataBase struct {
+		// Driver 管理画面のDB。"mysql"(既定)、"postgres"、"sqlite"のいずれか
+		Driver   string
 		Host     string
 		Port     string
 		User     string
 		Password string
 		Database string
+		// DSN 接続情報(指定した場合はHost等より優先します。sqliteの場合はファイルのパス)
+		DSN string
+	}
+	// Script Methodに設定されたスクリプトの実行制限
+	Script struct {
+		// Timeout 実行時間の上限(ミリ秒)
+		Timeout int
+		// MaxSize ドキュメントのサイズの上限(バイト)
+		MaxSize int
+		// MaxMemory 実行中に増加するメモリの上限(バイト)
+		MaxMemory int
+	}
+	// Webhook Webhookの送信設定
+	Webhook struct {
+		// MaxAttempts 送信を諦めるまでの試行回数
+		MaxAttempts int
+		// RetryInterval 1回目の再送までの待ち時間(秒)
+		RetryInterval int
+		// Timeout 1回の送信のタイムアウト(秒)
+		Timeout int
+	}
+	// Storage ドキュメントの保存先
+	Storage struct {
+		// Driver "mongodb"(既定)、"postgres"、"mysql"、"sqlite"のいずれか
+		Driver string
+		// DSN mongodb以外の接続情報(sqliteの場合はファイルのパス)
+		DSN string
+	}
+	// Backup バックアップの保存設定
+	Backup struct {
+		// Dir バックアップを保存するディレクトリ
+		Dir string
 	}
+	// Audit APIサーバーの書き込みの監査ログ
+	Audit struct {
+		// RetentionDays 監査ログを保持する日数(0の場合は削除しません)
+		RetentionDays int
+		// RedactFields ModelのSchemaの指定("x-sensitive": true)に関わらず、値を伏せて記録する項目名
+		RedactFields []string
+	}
+	// RateLimit APIサーバーのリクエスト数の制限(API、Methodごとの制限に加えて、すべてのリクエストに適用します)
+	RateLimit struct {
+		// Rate 呼び出し元ごとの1分あたりのリクエスト数の上限(0の場合は制限しません)
+		Rate int
+		// Burst 呼び出し元ごとに連続して受け付けるリクエスト数(0の場合はRateと同じ)
+		Burst int
+		// APIKeyHeader API Keyを指定するヘッダー(既定は"X-API-Key")
+		APIKeyHeader string
+		// APIKeys 呼び出し元を識別するAPI Key(登録されていないAPI Keyは使用せず、IPアドレスで識別します)
+		APIKeys []RateLimitKey
+		// JWTSecret JWT(HS256)の署名を検証する鍵。指定した場合は、Authorizationヘッダーで検証できたJWTのsubで識別します
+		JWTSecret string
+		// TrustProxy trueの場合は、X-Forwarded-Forの送信元のIPアドレスで識別します(プロキシの背後で実行する場合)
+		TrustProxy bool
+	}
+}
+
+// RateLimitKey API Keyと、API Keyごとのリクエスト数の制限
+type RateLimitKey struct {
+	Name string
+	Key  string
+	// Rate 1分あたりのリクエスト数の上限(RateLimit.Rateの代わりに適用します。0の場合はRateLimit.Rate)
+	Rate  int
+	Burst int
+	// MonthlyQuota すべてのAPIの合計の1か月のリクエスト数の上限(0の場合は制限しません)
+	MonthlyQuota int
 }
 
 // NewConfig 設定ファイルを読み込みCondigを作成します
@@ -55,3 +136,11 @@ func (c *Config) ServerBaseURL() string {
 
 	return apiServerBaseurl
 }
+
+// BackupDir バックアップを保存するディレクトリを返却します。設定されていない場合は"./backups"です
+func (c *Config) BackupDir() string {
+	if c.Backup.Dir == "" {
+		return "./backups"
+	}
+	return c.Backup.Dir
+}
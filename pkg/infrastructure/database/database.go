@@ -13,8 +13,17 @@ import (
 	"github.com/jinzhu/gorm"
 )
 
+// 管理画面のDBのドライバ
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
 // DB Database
 type DB struct {
+	Driver   string
+	DSN      string
 	Host     string
 	Port     string
 	Username string
@@ -25,6 +34,8 @@ type DB struct {
 // NewDB Configから、DBオブジェクトを作成します
 func NewDB(c *config.Config) *DB {
 	return &DB{
+		Driver:   c.DataBase.Driver,
+		DSN:      c.DataBase.DSN,
 		Host:     c.DataBase.Host,
 		Port:     c.DataBase.Port,
 		Username: c.DataBase.User,
@@ -33,9 +44,50 @@ func NewDB(c *config.Config) *DB {
 	}
 }
 
-// NewMysqlConnection DBオブジェクトからMysqlの接続を作成します
-func (d *DB) NewMysqlConnection() *gorm.DB {
-	// MySQLの接続情報を作成
+// NewConnection 設定されたドライバ(未指定の場合はMySQL)で、管理画面のDBの接続を作成します
+func (d *DB) NewConnection() *gorm.DB {
+	dialect, dsn, err := d.connectionInfo()
+	if err != nil {
+		panic(err.Error())
+	}
+
+	db, err := gorm.Open(dialect, dsn)
+	if err != nil {
+		panic(err.Error())
+	}
+	if dialect == "sqlite3" {
+		// SQLiteは書き込みを同時に1つしか実行できないため、接続を1つにする
+		db.DB().SetMaxOpenConns(1)
+	}
+
+	return db
+}
+
+// connectionInfo gormのdialect名と接続情報を作成します
+func (d *DB) connectionInfo() (string, string, error) {
+	switch d.Driver {
+	case "", DriverMySQL:
+		if d.DSN != "" {
+			return "mysql", d.DSN, nil
+		}
+		return "mysql", d.mysqlDSN(), nil
+	case DriverPostgres:
+		if d.DSN != "" {
+			return "postgres", d.DSN, nil
+		}
+		return "postgres", fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+			d.Host, d.Port, d.Username, d.Password, d.DBName), nil
+	case DriverSQLite:
+		if d.DSN != "" {
+			return "sqlite3", d.DSN, nil
+		}
+		return "sqlite3", d.DBName + ".db", nil
+	}
+	return "", "", fmt.Errorf("unsupported database driver %s", d.Driver)
+}
+
+// mysqlDSN MySQLの接続情報を作成します
+func (d *DB) mysqlDSN() string {
 	connectionInfo := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s",
 		d.Username,
 		d.Password,
@@ -48,29 +100,31 @@ func (d *DB) NewMysqlConnection() *gorm.DB {
 	option.Add("parseTime", "True")
 	option.Add("loc", "Local")
 
-	connection := fmt.Sprintf("%s?%s", connectionInfo, option.Encode())
+	return fmt.Sprintf("%s?%s", connectionInfo, option.Encode())
+}
 
-	// MySQLに接続
-	db, err := gorm.Open("mysql", connection)
+// NewMongoDBConnection DBオブジェクトからMongoDBの接続を作成します
+func (d *DB) NewMongoDBConnection() (*mongo.Database, context.Context, context.CancelFunc) {
+	// MongoDBの接続情報を作成
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(d.mongoDBURI()))
 	if err != nil {
 		panic(err.Error())
 	}
+	return client.Database(d.DBName), ctx, cancel
+}
 
-	return db
+// NewMongoDBClient 有効期限のないMongoDBの接続を作成します(変更の監視等、長時間の接続に使用します)
+// 使用後はDisconnectする必要があります
+func (d *DB) NewMongoDBClient(ctx context.Context) (*mongo.Client, error) {
+	return mongo.Connect(ctx, options.Client().ApplyURI(d.mongoDBURI()))
 }
 
-// NewMongoDBConnection DBオブジェクトからMongoDBの接続を作成します
-func (d *DB) NewMongoDBConnection() (*mongo.Database, context.Context, context.CancelFunc) {
-	// MongoDBの接続情報を作成
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	uri := fmt.Sprintf("mongodb://%s:%s@%s:%s",
+// mongoDBURI MongoDBの接続情報を作成します
+func (d *DB) mongoDBURI() string {
+	return fmt.Sprintf("mongodb://%s:%s@%s:%s",
 		d.Username,
 		d.Password,
 		d.Host,
 		d.Port)
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
-	if err != nil {
-		panic(err.Error())
-	}
-	return client.Database(d.DBName), ctx, cancel
 }
@@ -0,0 +1,203 @@
+// Package memory コンテナを使用せずに実行するための、メモリ上(またはファイル)のデータストア
+package memory
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Tables 管理画面のテーブル(migrationで作成するテーブルに対応します)
+type Tables struct {
+	APIs                []domain.API                `json:"apis"`
+	APIAliases          []domain.APIAlias           `json:"apiAliases"`
+	Methods             []domain.Method             `json:"methods"`
+	Models              []domain.Model              `json:"models"`
+	Fixtures            []domain.Fixture            `json:"fixtures"`
+	Webhooks            []domain.Webhook            `json:"webhooks"`
+	WebhookDeliveries   []domain.WebhookDelivery    `json:"webhookDeliveries"`
+	WebhookDeliveryLogs []domain.WebhookDeliveryLog `json:"webhookDeliveryLogs"`
+	AuditLogs           []domain.AuditLog           `json:"auditLogs"`
+	ChangeEvents        []domain.ChangeEvent        `json:"changeEvents"`
+}
+
+// clone スライスを複製します(要素は値のため、複製したTablesを変更しても元のTablesは変更されません)
+func (t Tables) clone() Tables {
+	return Tables{
+		APIs:                append([]domain.API(nil), t.APIs...),
+		APIAliases:          append([]domain.APIAlias(nil), t.APIAliases...),
+		Methods:             append([]domain.Method(nil), t.Methods...),
+		Models:              append([]domain.Model(nil), t.Models...),
+		Fixtures:            append([]domain.Fixture(nil), t.Fixtures...),
+		Webhooks:            append([]domain.Webhook(nil), t.Webhooks...),
+		WebhookDeliveries:   append([]domain.WebhookDelivery(nil), t.WebhookDeliveries...),
+		WebhookDeliveryLogs: append([]domain.WebhookDeliveryLog(nil), t.WebhookDeliveryLogs...),
+		AuditLogs:           append([]domain.AuditLog(nil), t.AuditLogs...),
+		ChangeEvents:        append([]domain.ChangeEvent(nil), t.ChangeEvents...),
+	}
+}
+
+// Collections コレクション名ごとのドキュメント(登録順)
+type Collections map[string][]bson.M
+
+// file 保存するファイルの形式
+// ドキュメントは型を保持するため、Canonical Extended JSONで保存します
+type file struct {
+	Tables      Tables                       `json:"tables"`
+	Collections map[string][]json.RawMessage `json:"collections"`
+}
+
+// Store 管理画面のテーブルとコレクションを保持します
+// pathを指定した場合は、変更のたびにファイルへ保存し、次回の起動時に読み込みます
+type Store struct {
+	mu          sync.RWMutex
+	path        string
+	tables      Tables
+	collections Collections
+}
+
+// NewStore Storeを作成します。pathが空の場合はメモリ上のみで保持します
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path, collections: Collections{}}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	s.tables = f.Tables
+	for name, raws := range f.Collections {
+		documents := make([]bson.M, 0, len(raws))
+		for _, raw := range raws {
+			var document bson.M
+			if err := bson.UnmarshalExtJSON(raw, true, &document); err != nil {
+				return nil, err
+			}
+			documents = append(documents, document)
+		}
+		s.collections[name] = documents
+	}
+	return s, nil
+}
+
+// View テーブルを参照します。fnの中でテーブルを変更してはいけません
+func (s *Store) View(fn func(t *Tables)) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	fn(&s.tables)
+}
+
+// Update テーブルを変更します
+// fnはテーブルの複製を変更し、エラーを返却しなかった場合のみ反映します(トランザクションと同じ)
+func (s *Store) Update(fn func(t *Tables) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tables := s.tables.clone()
+	if err := fn(&tables); err != nil {
+		return err
+	}
+	s.tables = tables
+	return s.save()
+}
+
+// ViewCollections コレクションを参照します。fnの中でコレクションを変更してはいけません
+func (s *Store) ViewCollections(fn func(c Collections)) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	fn(s.collections)
+}
+
+// UpdateCollections コレクションを変更します
+// fnがエラーを返却した場合は保存しないため、fnは変更する前に検証してください
+func (s *Store) UpdateCollections(fn func(c Collections) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := fn(s.collections); err != nil {
+		return err
+	}
+	return s.save()
+}
+
+// save ファイルへ保存します(書き込み途中のファイルを読み込まないよう、一時ファイルから置き換えます)
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	f := file{Tables: s.tables, Collections: map[string][]json.RawMessage{}}
+	for name, documents := range s.collections {
+		raws := make([]json.RawMessage, 0, len(documents))
+		for _, document := range documents {
+			raw, err := bson.MarshalExtJSON(document, true, false)
+			if err != nil {
+				return err
+			}
+			raws = append(raws, raw)
+		}
+		f.Collections[name] = raws
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), ".store-*.json")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// ErrDuplicateKey "duplicate primary key"
+var ErrDuplicateKey = errors.New("duplicate primary key")
+
+// Created 追加時の作成日時、更新日時を設定します(gormのCreateと同じ)
+func Created(c *domain.CommonColumn) {
+	now := time.Now()
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = now
+	}
+	if c.UpdatedAt.IsZero() {
+		c.UpdatedAt = now
+	}
+}
+
+// Saved 更新時の更新日時を設定します。作成日時が未指定の場合は更新前の作成日時を引き継ぎます
+func Saved(c *domain.CommonColumn, before domain.CommonColumn) {
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = before.CreatedAt
+	}
+	c.UpdatedAt = time.Now()
+}
@@ -0,0 +1,93 @@
+package memory_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/memory"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdate(t *testing.T) {
+	store, err := memory.NewStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("反映", func(t *testing.T) {
+		err := store.Update(func(tables *memory.Tables) error {
+			tables.APIs = append(tables.APIs, domain.API{ID: "1"})
+			return nil
+		})
+
+		assert.NoError(t, err)
+		store.View(func(tables *memory.Tables) {
+			assert.Len(t, tables.APIs, 1)
+		})
+	})
+	t.Run("エラーの場合は反映しない", func(t *testing.T) {
+		err := store.Update(func(tables *memory.Tables) error {
+			tables.APIs[0].Name = "changed"
+			tables.Methods = append(tables.Methods, domain.Method{ID: "1"})
+			return errors.New("error")
+		})
+
+		assert.Error(t, err)
+		store.View(func(tables *memory.Tables) {
+			assert.Equal(t, "", tables.APIs[0].Name)
+			assert.Len(t, tables.Methods, 0)
+		})
+	})
+}
+
+func TestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "memory-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "store.json")
+
+	// ファイルが存在しない場合は空で作成する
+	store, err := memory.NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Update(func(tables *memory.Tables) error {
+		tables.APIs = append(tables.APIs, domain.API{ID: "1", URL: "users"})
+		return nil
+	})
+	assert.NoError(t, err)
+	err = store.UpdateCollections(func(c memory.Collections) error {
+		c["users"] = []bson.M{{"id": int64(1), "name": "foo"}}
+		return nil
+	})
+	assert.NoError(t, err)
+
+	reopened, err := memory.NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reopened.View(func(tables *memory.Tables) {
+		assert.Equal(t, "users", tables.APIs[0].URL)
+	})
+	reopened.ViewCollections(func(c memory.Collections) {
+		// 数値の型も保存される
+		assert.Equal(t, []bson.M{{"id": int64(1), "name": "foo"}}, c["users"])
+	})
+
+	t.Run("不正なファイル", func(t *testing.T) {
+		invalid := filepath.Join(dir, "invalid.json")
+		ioutil.WriteFile(invalid, []byte("{"), 0644)
+
+		_, err := memory.NewStore(invalid)
+
+		assert.Error(t, err)
+	})
+}
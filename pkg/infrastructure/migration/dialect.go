@@ -0,0 +1,350 @@
+package migration
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ColumnType データベースに依存しない列の型
+type ColumnType int
+
+const (
+	// TypeString 長さを指定する文字列(VARCHAR)
+	TypeString ColumnType = iota
+	// TypeText 長い文字列
+	TypeText
+	// TypeLongText 非常に長い文字列(JSONのドキュメントなど)
+	TypeLongText
+	// TypeBool 真偽値
+	TypeBool
+	// TypeInt 整数
+	TypeInt
+	// TypeBigInt 64ビットの整数
+	TypeBigInt
+	// TypeTime 日時
+	TypeTime
+)
+
+// Column 列の定義
+type Column struct {
+	Name string
+	Type ColumnType
+	// Size TypeStringの長さ
+	Size int
+	// NotNull trueの場合はNOT NULL
+	NotNull bool
+	// Default 既定値(SQLの式をそのまま使用します。例："''"、"0"、"false"、"CURRENT_TIMESTAMP")
+	Default string
+}
+
+// Index インデックスの定義
+type Index struct {
+	Name    string
+	Columns []string
+	// Unique trueの場合は一意のインデックス
+	Unique bool
+}
+
+// ForeignKey 外部キーの定義
+type ForeignKey struct {
+	Column    string
+	RefTable  string
+	RefColumn string
+}
+
+// Table テーブルの定義
+type Table struct {
+	Name        string
+	Columns     []Column
+	PrimaryKey  []string
+	Indexes     []Index
+	ForeignKeys []ForeignKey
+}
+
+// Dialect データベースごとに異なるSQLを作成します
+type Dialect interface {
+	// Name gormのdialect名("mysql"、"postgres"、"sqlite3")
+	Name() string
+	// Quote 識別子をクォートします
+	Quote(name string) string
+	// Placeholder n番目(1から)のパラメータのプレースホルダ
+	Placeholder(n int) string
+	// CreateTable テーブルが存在しない場合に作成するSQL(インデックスを含む)
+	CreateTable(table Table) []string
+	// DropTable テーブルを削除するSQL
+	DropTable(name string) string
+	// AddColumn テーブルに列を追加するSQL
+	AddColumn(table string, column Column) string
+	// DropColumns テーブルから列を削除するSQL(tableは列を削除した後のテーブルの定義)
+	DropColumns(table Table, columns []string) []string
+	// CreateIndex 既存のテーブルにインデックスを作成するSQL
+	CreateIndex(table string, index Index) string
+	// DropIndex テーブルのインデックスを削除するSQL
+	DropIndex(table string, name string) string
+}
+
+// NewDialect gormのdialect名からDialectを作成します
+func NewDialect(name string) (Dialect, error) {
+	switch name {
+	case "mysql":
+		return mysqlDialect{}, nil
+	case "postgres":
+		return postgresDialect{}, nil
+	case "sqlite3":
+		return sqliteDialect{}, nil
+	}
+	return nil, fmt.Errorf("unsupported database driver %s", name)
+}
+
+// columnTypes 列の型をデータベースの型にします
+type columnTypes map[ColumnType]string
+
+// columnSQL 列の定義を作成します
+func columnSQL(c Column, quote func(string) string, types columnTypes) string {
+	t := types[c.Type]
+	if c.Type == TypeString {
+		t = fmt.Sprintf(t, c.Size)
+	}
+	definition := quote(c.Name) + " " + t
+	if c.NotNull {
+		definition += " NOT NULL"
+	}
+	if c.Default != "" {
+		definition += " DEFAULT " + c.Default
+	}
+	return definition
+}
+
+// columnsSQL 列と主キー、外部キーの定義を作成します
+func columnsSQL(table Table, quote func(string) string, types columnTypes) []string {
+	definitions := []string{}
+	for _, c := range table.Columns {
+		definitions = append(definitions, columnSQL(c, quote, types))
+	}
+	if len(table.PrimaryKey) > 0 {
+		definitions = append(definitions, "PRIMARY KEY ("+quoteAll(table.PrimaryKey, quote)+")")
+	}
+	for _, fk := range table.ForeignKeys {
+		definitions = append(definitions, fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s(%s)", quote(fk.Column), quote(fk.RefTable), quote(fk.RefColumn)))
+	}
+	return definitions
+}
+
+// addColumn 列を追加するSQLを作成します
+func addColumn(table string, column Column, quote func(string) string, types columnTypes) string {
+	return "ALTER TABLE " + quote(table) + " ADD COLUMN " + columnSQL(column, quote, types)
+}
+
+// dropColumns 列を1つずつ削除するSQLを作成します(MySQL、PostgreSQL)
+func dropColumns(table string, columns []string, quote func(string) string) []string {
+	statements := []string{}
+	for _, column := range columns {
+		statements = append(statements, "ALTER TABLE "+quote(table)+" DROP COLUMN "+quote(column))
+	}
+	return statements
+}
+
+// quoteAll 識別子をクォートし、カンマで連結します
+func quoteAll(names []string, quote func(string) string) string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, quote(name))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// mysqlDialect MySQL
+type mysqlDialect struct{}
+
+var mysqlTypes = columnTypes{
+	TypeString:   "varchar(%d)",
+	TypeText:     "text",
+	TypeLongText: "longtext",
+	TypeBool:     "boolean",
+	TypeInt:      "int",
+	TypeBigInt:   "bigint",
+	TypeTime:     "datetime",
+}
+
+func (mysqlDialect) Name() string {
+	return "mysql"
+}
+
+func (mysqlDialect) Quote(name string) string {
+	return "`" + strings.Replace(name, "`", "``", -1) + "`"
+}
+
+func (mysqlDialect) Placeholder(n int) string {
+	return "?"
+}
+
+func (d mysqlDialect) CreateTable(table Table) []string {
+	// MySQLはCREATE INDEX IF NOT EXISTSがないため、インデックスはテーブルと同時に作成する
+	definitions := columnsSQL(table, d.Quote, mysqlTypes)
+	for _, index := range table.Indexes {
+		key := "KEY "
+		if index.Unique {
+			key = "UNIQUE KEY "
+		}
+		definitions = append(definitions, key+d.Quote(index.Name)+" ("+quoteAll(index.Columns, d.Quote)+")")
+	}
+	return []string{
+		"CREATE TABLE IF NOT EXISTS " + d.Quote(table.Name) + " (\n  " + strings.Join(definitions, ",\n  ") + "\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4",
+	}
+}
+
+func (d mysqlDialect) DropTable(name string) string {
+	return "DROP TABLE IF EXISTS " + d.Quote(name)
+}
+
+func (d mysqlDialect) AddColumn(table string, column Column) string {
+	return addColumn(table, column, d.Quote, mysqlTypes)
+}
+
+func (d mysqlDialect) DropColumns(table Table, columns []string) []string {
+	return dropColumns(table.Name, columns, d.Quote)
+}
+
+func (d mysqlDialect) CreateIndex(table string, index Index) string {
+	return createIndex(table, index, "", d.Quote)
+}
+
+func (d mysqlDialect) DropIndex(table string, name string) string {
+	return "DROP INDEX " + d.Quote(name) + " ON " + d.Quote(table)
+}
+
+// postgresDialect PostgreSQL
+type postgresDialect struct{}
+
+var postgresTypes = columnTypes{
+	TypeString:   "varchar(%d)",
+	TypeText:     "text",
+	TypeLongText: "text",
+	TypeBool:     "boolean",
+	TypeInt:      "integer",
+	TypeBigInt:   "bigint",
+	TypeTime:     "timestamp",
+}
+
+func (postgresDialect) Name() string {
+	return "postgres"
+}
+
+func (postgresDialect) Quote(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+func (postgresDialect) Placeholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+func (d postgresDialect) CreateTable(table Table) []string {
+	return createTableWithIndexes(table, d.Quote, postgresTypes)
+}
+
+func (d postgresDialect) DropTable(name string) string {
+	return "DROP TABLE IF EXISTS " + d.Quote(name)
+}
+
+func (d postgresDialect) AddColumn(table string, column Column) string {
+	return addColumn(table, column, d.Quote, postgresTypes)
+}
+
+func (d postgresDialect) DropColumns(table Table, columns []string) []string {
+	return dropColumns(table.Name, columns, d.Quote)
+}
+
+func (d postgresDialect) CreateIndex(table string, index Index) string {
+	return createIndex(table, index, "IF NOT EXISTS ", d.Quote)
+}
+
+func (d postgresDialect) DropIndex(table string, name string) string {
+	return "DROP INDEX IF EXISTS " + d.Quote(name)
+}
+
+// sqliteDialect SQLite
+type sqliteDialect struct{}
+
+// sqliteTypes go-sqlite3はdatetimeの列をtime.Timeとして読み込みます
+var sqliteTypes = columnTypes{
+	TypeString:   "varchar(%d)",
+	TypeText:     "text",
+	TypeLongText: "text",
+	TypeBool:     "boolean",
+	TypeInt:      "integer",
+	TypeBigInt:   "bigint",
+	TypeTime:     "datetime",
+}
+
+func (sqliteDialect) Name() string {
+	return "sqlite3"
+}
+
+func (sqliteDialect) Quote(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+func (sqliteDialect) Placeholder(n int) string {
+	return "?"
+}
+
+func (d sqliteDialect) CreateTable(table Table) []string {
+	return createTableWithIndexes(table, d.Quote, sqliteTypes)
+}
+
+func (d sqliteDialect) DropTable(name string) string {
+	return "DROP TABLE IF EXISTS " + d.Quote(name)
+}
+
+func (d sqliteDialect) AddColumn(table string, column Column) string {
+	return addColumn(table, column, d.Quote, sqliteTypes)
+}
+
+// DropColumns SQLite(3.35より前)はDROP COLUMNがないため、列を削除した定義でテーブルを作り直します
+func (d sqliteDialect) DropColumns(table Table, columns []string) []string {
+	names := make([]string, 0, len(table.Columns))
+	for _, c := range table.Columns {
+		names = append(names, c.Name)
+	}
+	tmp := Table{Name: table.Name + "_tmp", Columns: table.Columns, PrimaryKey: table.PrimaryKey, ForeignKeys: table.ForeignKeys}
+	statements := []string{
+		d.DropTable(tmp.Name),
+	}
+	statements = append(statements, d.CreateTable(tmp)...)
+	statements = append(statements,
+		"INSERT INTO "+d.Quote(tmp.Name)+" ("+quoteAll(names, d.Quote)+") SELECT "+quoteAll(names, d.Quote)+" FROM "+d.Quote(table.Name),
+		d.DropTable(table.Name),
+		"ALTER TABLE "+d.Quote(tmp.Name)+" RENAME TO "+d.Quote(table.Name),
+	)
+	// インデックスはテーブルと共に削除されるため、作り直したテーブルに作成する
+	return append(statements, d.CreateTable(table)[1:]...)
+}
+
+func (d sqliteDialect) CreateIndex(table string, index Index) string {
+	return createIndex(table, index, "IF NOT EXISTS ", d.Quote)
+}
+
+func (d sqliteDialect) DropIndex(table string, name string) string {
+	return "DROP INDEX IF EXISTS " + d.Quote(name)
+}
+
+// createIndex インデックスを作成するSQLを作成します(ifNotExistsはMySQL以外で"IF NOT EXISTS "を指定します)
+func createIndex(table string, index Index, ifNotExists string, quote func(string) string) string {
+	create := "CREATE INDEX "
+	if index.Unique {
+		create = "CREATE UNIQUE INDEX "
+	}
+	return create + ifNotExists + quote(index.Name) + " ON " + quote(table) + " (" + quoteAll(index.Columns, quote) + ")"
+}
+
+// createTableWithIndexes テーブルと、インデックスを作成するSQLを作成します(PostgreSQL、SQLite)
+func createTableWithIndexes(table Table, quote func(string) string, types columnTypes) []string {
+	definitions := columnsSQL(table, quote, types)
+	statements := []string{
+		"CREATE TABLE IF NOT EXISTS " + quote(table.Name) + " (\n  " + strings.Join(definitions, ",\n  ") + "\n)",
+	}
+	for _, index := range table.Indexes {
+		statements = append(statements, createIndex(table.Name, index, "IF NOT EXISTS ", quote))
+	}
+	return statements
+}
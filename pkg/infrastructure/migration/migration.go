@@ -0,0 +1,382 @@
+// Package migration 管理画面のテーブルを、バージョンごとのマイグレーションで作成、変更します
+// MySQL、PostgreSQL、SQLiteで同じマイグレーションを使用できるよう、テーブルはTableで定義します
+package migration
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// TableName 適用したマイグレーションを記録するテーブル
+const TableName = "schema_migrations"
+
+// Migration バージョンごとのスキーマの変更
+// 適用したマイグレーションは変更せず(チェックサムで検出します)、変更が必要な場合は新しいバージョンを追加します
+type Migration struct {
+	// Version 適用する順序(適用済みのバージョンより大きい値を使用します)
+	Version int64
+	Name    string
+	// Up 適用するSQL
+	Up func(d Dialect) []string
+	// Ensure 存在しない場合のみ追加する列(Upの後に追加します)
+	// 以前のバージョンで作成したテーブルに、列があるか分からない場合に使用します
+	Ensure func() []TableColumn
+	// Down Upを取り消すSQL(nilの場合は取り消せません)
+	Down func(d Dialect) []string
+}
+
+// TableColumn テーブルと、そのテーブルに追加する列
+type TableColumn struct {
+	Table  string
+	Column Column
+}
+
+// Checksum Upで適用するSQL(Ensureの列はすべて追加する場合のSQL)のチェックサム(SHA-256)を返却します
+// データベースの状態によらず、同じマイグレーションは同じチェックサムになります
+func (m Migration) Checksum(d Dialect) string {
+	all := func(table, column string) bool { return true }
+	sum := sha256.Sum256([]byte(strings.Join(m.statements(d, all), ";\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// statements 適用するSQLを返却します。Ensureの列は、missingで存在しないと判定した列のみ追加します
+func (m Migration) statements(d Dialect, missing func(table, column string) bool) []string {
+	statements := []string{}
+	if m.Up != nil {
+		statements = append(statements, m.Up(d)...)
+	}
+	if m.Ensure == nil {
+		return statements
+	}
+	for _, c := range m.Ensure() {
+		if missing(c.Table, c.Column.Name) {
+			statements = append(statements, d.AddColumn(c.Table, c.Column))
+		}
+	}
+	return statements
+}
+
+// Status マイグレーションの適用状況
+type Status struct {
+	Version int64
+	Name    string
+	Applied bool
+	// AppliedAt 適用した日時(未適用の場合はゼロ値)
+	AppliedAt time.Time
+	// Modified 適用後にマイグレーションが変更された(チェックサムが一致しない)
+	Modified bool
+	// Unknown 適用済みだが、このバージョンのプログラムに存在しない(データベースの方が新しい)
+	Unknown bool
+}
+
+// migrationsTable 適用したマイグレーションを記録するテーブルの定義
+var migrationsTable = Table{
+	Name: TableName,
+	Columns: []Column{
+		{Name: "version", Type: TypeBigInt, NotNull: true},
+		{Name: "name", Type: TypeString, Size: 255, NotNull: true, Default: "''"},
+		{Name: "checksum", Type: TypeString, Size: 64, NotNull: true, Default: "''"},
+		{Name: "applied_at", Type: TypeTime, NotNull: true, Default: "CURRENT_TIMESTAMP"},
+	},
+	PrimaryKey: []string{"version"},
+}
+
+// record 適用したマイグレーションの記録
+type record struct {
+	version   int64
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// migrator 1つのデータベースに対してマイグレーションを実行します
+type migrator struct {
+	db         *gorm.DB
+	conn       *sql.DB
+	dialect    Dialect
+	migrations []Migration
+}
+
+// newMigrator マイグレーションを検証し、記録するテーブルを作成します
+func newMigrator(db *gorm.DB, migrations []Migration) (*migrator, error) {
+	m, err := openMigrator(db, migrations)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// openMigrator マイグレーションを検証します(記録するテーブルは作成しません)
+func openMigrator(db *gorm.DB, migrations []Migration) (*migrator, error) {
+	d, err := NewDialect(db.Dialect().GetName())
+	if err != nil {
+		return nil, err
+	}
+	sorted, err := sortMigrations(migrations)
+	if err != nil {
+		return nil, err
+	}
+	return &migrator{db: db, conn: db.DB(), dialect: d, migrations: sorted}, nil
+}
+
+// Up 未適用のマイグレーションをバージョン順に適用し、適用したマイグレーションを返却します
+// 適用済みのマイグレーションが変更されている場合や、データベースの方が新しい場合は適用しません
+// マイグレーションは1つずつトランザクションで適用します
+// (MySQLはテーブルの作成、変更をロールバックできないため、途中で失敗した場合は手動で戻す必要があります)
+func Up(db *gorm.DB, migrations []Migration) ([]Migration, error) {
+	m, err := newMigrator(db, migrations)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := m.status()
+	if err != nil {
+		return nil, err
+	}
+	if err := verify(statuses); err != nil {
+		return nil, err
+	}
+
+	done := []Migration{}
+	for i, s := range statuses {
+		if s.Applied {
+			continue
+		}
+		migration := m.migrations[i]
+		if err := m.apply(migration); err != nil {
+			return done, fmt.Errorf("migration %d_%s: %s", migration.Version, migration.Name, err.Error())
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down 適用済みのマイグレーションを新しい順にsteps件取り消し、取り消したマイグレーションを返却します
+func Down(db *gorm.DB, migrations []Migration, steps int) ([]Migration, error) {
+	m, err := newMigrator(db, migrations)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := m.status()
+	if err != nil {
+		return nil, err
+	}
+	if err := verify(statuses); err != nil {
+		return nil, err
+	}
+
+	done := []Migration{}
+	for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		if !statuses[i].Applied {
+			continue
+		}
+		migration := m.migrations[i]
+		if migration.Down == nil {
+			return done, fmt.Errorf("migration %d_%s is irreversible", migration.Version, migration.Name)
+		}
+		if err := m.revert(migration); err != nil {
+			return done, fmt.Errorf("migration %d_%s: %s", migration.Version, migration.Name, err.Error())
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// GetStatus マイグレーションの適用状況をバージョン順に返却します
+// プログラムに存在しないバージョンが適用されている場合は、Unknownとして末尾に含めます
+func GetStatus(db *gorm.DB, migrations []Migration) ([]Status, error) {
+	m, err := newMigrator(db, migrations)
+	if err != nil {
+		return nil, err
+	}
+	return m.status()
+}
+
+// Check データベースのバージョンがプログラムと一致するか確認します
+// 未適用のマイグレーションがある場合、適用済みのマイグレーションが変更されている場合、
+// データベースの方が新しい場合はエラーを返却します(起動時に確認し、異なるバージョンでは実行しません)
+// データベースは変更せず、記録するテーブルがない場合はどのマイグレーションも適用していないものとします
+func Check(db *gorm.DB, migrations []Migration) error {
+	m, err := openMigrator(db, migrations)
+	if err != nil {
+		return err
+	}
+	statuses, err := m.status()
+	if err != nil {
+		return err
+	}
+	if err := verify(statuses); err != nil {
+		return err
+	}
+
+	pending := []string{}
+	for _, s := range statuses {
+		if !s.Applied {
+			pending = append(pending, fmt.Sprintf("%d_%s", s.Version, s.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("database has pending migrations (%s); run \"api-creator-admin migrate up\"", strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// verify 適用済みのマイグレーションが変更されていないか、データベースの方が新しくないか確認します
+func verify(statuses []Status) error {
+	for _, s := range statuses {
+		if s.Unknown {
+			return fmt.Errorf("database has migration %d_%s which is unknown to this version; upgrade api-creator", s.Version, s.Name)
+		}
+		if s.Modified {
+			return fmt.Errorf("migration %d_%s has been modified after it was applied (checksum mismatch)", s.Version, s.Name)
+		}
+	}
+	return nil
+}
+
+// status マイグレーションの適用状況を返却します
+func (m *migrator) status() ([]Status, error) {
+	records, err := m.records()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []Status{}
+	for _, migration := range m.migrations {
+		s := Status{Version: migration.Version, Name: migration.Name}
+		if r, ok := records[migration.Version]; ok {
+			s.Applied = true
+			s.AppliedAt = r.appliedAt
+			// チェックサムを記録する前に適用したもの(ensureTableで記録する前)は、変更されていないものとする
+			s.Modified = r.checksum != "" && r.checksum != migration.Checksum(m.dialect)
+			delete(records, migration.Version)
+		}
+		statuses = append(statuses, s)
+	}
+
+	unknown := []Status{}
+	for _, r := range records {
+		unknown = append(unknown, Status{Version: r.version, Name: r.name, Applied: true, AppliedAt: r.appliedAt, Unknown: true})
+	}
+	sort.Slice(unknown, func(i, j int) bool {
+		return unknown[i].Version < unknown[j].Version
+	})
+	return append(statuses, unknown...), nil
+}
+
+// apply マイグレーションを適用し、記録します
+func (m *migrator) apply(migration Migration) error {
+	d := m.dialect
+	query := fmt.Sprintf("INSERT INTO %s (%s, %s, %s, %s) VALUES (%s, %s, %s, %s)",
+		d.Quote(TableName), d.Quote("version"), d.Quote("name"), d.Quote("checksum"), d.Quote("applied_at"),
+		d.Placeholder(1), d.Placeholder(2), d.Placeholder(3), d.Placeholder(4))
+	missing := func(table, column string) bool {
+		return !m.db.Dialect().HasColumn(table, column)
+	}
+	return m.transaction(migration.statements(d, missing), query, migration.Version, migration.Name, migration.Checksum(d), time.Now())
+}
+
+// revert マイグレーションを取り消し、記録を削除します
+func (m *migrator) revert(migration Migration) error {
+	d := m.dialect
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s = %s", d.Quote(TableName), d.Quote("version"), d.Placeholder(1))
+	return m.transaction(migration.Down(d), query, migration.Version)
+}
+
+// transaction statementsと、記録を変更するqueryを1つのトランザクションで実行します
+func (m *migrator) transaction(statements []string, query string, args ...interface{}) error {
+	tx, err := m.conn.Begin()
+	if err != nil {
+		return err
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if _, err := tx.Exec(query, args...); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// ensureTable 適用したマイグレーションを記録するテーブルを作成します
+// チェックサムの列がない(チェックサムを記録する前に作成した)場合は追加し、現在のチェックサムを記録します
+func (m *migrator) ensureTable() error {
+	d := m.dialect
+	for _, statement := range d.CreateTable(migrationsTable) {
+		if _, err := m.conn.Exec(statement); err != nil {
+			return err
+		}
+	}
+	if m.db.Dialect().HasColumn(TableName, "checksum") {
+		return nil
+	}
+
+	if _, err := m.conn.Exec("ALTER TABLE " + d.Quote(TableName) + " ADD COLUMN " + d.Quote("checksum") + " varchar(64) NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	query := fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s = %s",
+		d.Quote(TableName), d.Quote("checksum"), d.Placeholder(1), d.Quote("version"), d.Placeholder(2))
+	for _, migration := range m.migrations {
+		if _, err := m.conn.Exec(query, migration.Checksum(d), migration.Version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// records 適用したマイグレーションの記録を取得します
+// 記録するテーブルがない場合は空、チェックサムの列がない場合はチェックサムを空として返却します
+func (m *migrator) records() (map[int64]record, error) {
+	records := map[int64]record{}
+	if !m.db.Dialect().HasTable(TableName) {
+		return records, nil
+	}
+
+	d := m.dialect
+	checksum := "''"
+	if m.db.Dialect().HasColumn(TableName, "checksum") {
+		checksum = d.Quote("checksum")
+	}
+	rows, err := m.conn.Query(fmt.Sprintf("SELECT %s, %s, %s, %s FROM %s",
+		d.Quote("version"), d.Quote("name"), checksum, d.Quote("applied_at"), d.Quote(TableName)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r record
+		if err := rows.Scan(&r.version, &r.name, &r.checksum, &r.appliedAt); err != nil {
+			return nil, err
+		}
+		records[r.version] = r
+	}
+	return records, rows.Err()
+}
+
+// sortMigrations マイグレーションをバージョン順に並べ替えます。バージョンが重複する場合はエラーです
+func sortMigrations(migrations []Migration) ([]Migration, error) {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Version == sorted[i-1].Version {
+			return nil, fmt.Errorf("duplicate migration version %d", sorted[i].Version)
+		}
+	}
+	return sorted, nil
+}
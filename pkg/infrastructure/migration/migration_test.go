@@ -0,0 +1,608 @@
+package migration_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	_apiRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/api/repository"
+	_auditRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/audit/repository"
+	_historyRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/history/repository"
+	_methodRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/method/repository"
+	_modelRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/model/repository"
+	_webhookRepository "github.com/Hajime3778/api-creator-backend/pkg/admin/webhook/repository"
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/database"
+	"github.com/Hajime3778/api-creator-backend/pkg/infrastructure/migration"
+	"github.com/jinzhu/gorm"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// SQLite以外のDBは、環境変数で接続先が指定された場合のみ検証します
+// 例：ADMIN_TEST_POSTGRES_DSN="host=localhost user=user password=password dbname=test sslmode=disable" go test ./pkg/infrastructure/migration/
+
+// openSQLite 一時ディレクトリのSQLiteに接続します
+func openSQLite(t *testing.T) *gorm.DB {
+	dir, err := ioutil.TempDir("", "migration-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	db := &database.DB{Driver: database.DriverSQLite, DSN: filepath.Join(dir, "admin.db")}
+	conn := db.NewConnection()
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestSQLite(t *testing.T) {
+	runRepositories(t, openSQLite(t))
+}
+
+func TestPostgres(t *testing.T) {
+	dsn := os.Getenv("ADMIN_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("ADMIN_TEST_POSTGRES_DSN is not set")
+	}
+	db := &database.DB{Driver: database.DriverPostgres, DSN: dsn}
+	runRepositories(t, db.NewConnection())
+}
+
+func TestMySQL(t *testing.T) {
+	dsn := os.Getenv("ADMIN_TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("ADMIN_TEST_MYSQL_DSN is not set")
+	}
+	db := &database.DB{Driver: database.DriverMySQL, DSN: dsn}
+	runRepositories(t, db.NewConnection())
+}
+
+// runRepositories マイグレーションで作成したテーブルで、管理画面のリポジトリが動作することを検証します
+func runRepositories(t *testing.T, conn *gorm.DB) {
+	applied, err := migration.Up(conn, migration.Migrations)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Len(t, applied, len(migration.Migrations))
+	assert.NoError(t, migration.Check(conn, migration.Migrations))
+
+	// 適用済みのマイグレーションは再度適用しない
+	applied, err = migration.Up(conn, migration.Migrations)
+	assert.NoError(t, err)
+	assert.Len(t, applied, 0)
+
+	apiRepository := _apiRepository.NewAPIRepository(conn)
+	methodRepository := _methodRepository.NewMethodRepository(conn)
+	modelRepository := _modelRepository.NewModelRepository(conn)
+	webhookRepository := _webhookRepository.NewWebhookRepository(conn)
+	auditRepository := _auditRepository.NewAuditRepository(conn)
+	historyRepository := _historyRepository.NewHistoryRepository(conn)
+
+	api := domain.API{ID: "api-1", Name: "Users", URL: "my-project/api/users", StreamEnabled: true, RateLimit: 60, MonthlyQuota: 1000}
+	model := domain.Model{ID: "model-1", APIID: api.ID, Name: "User", Schema: `{"type": "object"}`, CollectionName: "model-1"}
+	method := domain.Method{ID: "method-1", APIID: api.ID, Type: "GET", URL: "/{id}", RequestParameter: "id", ResponseModelID: model.ID, IsArray: true}
+	err = apiRepository.ImportAll([]domain.ImportPlan{{
+		API:           api,
+		CreateAPI:     true,
+		CreateModels:  []domain.Model{model},
+		CreateMethods: []domain.Method{method},
+	}})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	got, err := apiRepository.GetByURL("my-project/api/users/1")
+	assert.NoError(t, err)
+	assert.Equal(t, api.ID, got.ID)
+	assert.True(t, got.StreamEnabled)
+	assert.Equal(t, 60, got.RateLimit)
+	assert.Equal(t, 1000, got.MonthlyQuota)
+	assert.False(t, got.CreatedAt.IsZero())
+
+	methods, err := methodRepository.GetListByAPIIDAndType(api.ID, "GET")
+	assert.NoError(t, err)
+	if assert.Len(t, methods, 1) {
+		assert.True(t, methods[0].IsArray)
+		assert.Equal(t, "id", methods[0].RequestParameter)
+	}
+
+	// schemaはMySQLの予約語
+	gotModel, err := modelRepository.GetByAPIID(api.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.Schema, gotModel.Schema)
+
+	err = apiRepository.ChangeURL(domain.API{ID: api.ID, URL: "my-project/api/members"}, &domain.APIAlias{ID: "alias-1", APIID: api.ID, URL: api.URL})
+	assert.NoError(t, err)
+	alias, err := apiRepository.GetAliasByURL("my-project/api/users/1")
+	assert.NoError(t, err)
+	assert.Equal(t, api.ID, alias.APIID)
+
+	now := time.Now()
+	_, err = webhookRepository.Create(domain.Webhook{ID: "webhook-1", APIID: api.ID, URL: "http://localhost", Events: "*"})
+	assert.NoError(t, err)
+	err = webhookRepository.CreateDeliveries([]domain.WebhookDelivery{
+		{ID: "delivery-1", WebhookID: "webhook-1", EventID: "event-1", Status: domain.DeliveryStatusPending, NextAttemptAt: now.Add(-time.Minute)},
+		{ID: "delivery-2", WebhookID: "webhook-1", EventID: "event-2", Status: domain.DeliveryStatusPending, NextAttemptAt: now.Add(time.Hour)},
+	})
+	assert.NoError(t, err)
+	deliveries, err := webhookRepository.GetPendingDeliveries(now, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, deliveries, 1) {
+		assert.Equal(t, "delivery-1", deliveries[0].ID)
+	}
+
+	err = auditRepository.Create(domain.AuditLog{ID: "audit-1", APIID: api.ID, HTTPMethod: "PUT", Key: "1", Caller: "key:k1", Before: `{"id":"1"}`, After: `{"id":"1"}`, Status: 200})
+	assert.NoError(t, err)
+	logs, err := auditRepository.Search(domain.AuditQuery{APIID: api.ID, Key: "1", From: now.Add(-time.Minute), Limit: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, logs, 1) {
+		assert.Equal(t, `{"id":"1"}`, logs[0].Before)
+	}
+	deleted, err := auditRepository.DeleteBefore(now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	// 変更の履歴は対象ごとにバージョンを採番する
+	for _, id := range []string{"event-1", "event-2"} {
+		_, err = historyRepository.Create(domain.ChangeEvent{ID: id, EntityType: domain.EntityTypeMethod, EntityID: method.ID, APIID: api.ID, Action: domain.ChangeActionUpdate, After: `{"id":"method-1"}`})
+		assert.NoError(t, err)
+	}
+	event, err := historyRepository.GetVersion(domain.EntityTypeMethod, method.ID, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "event-2", event.ID)
+
+	err = apiRepository.ImportAll([]domain.ImportPlan{{
+		API:           domain.API{ID: api.ID},
+		DeleteAPI:     true,
+		DeleteModels:  []domain.Model{model},
+		DeleteMethods: []domain.Method{method},
+	}})
+	assert.NoError(t, err)
+	apis, err := apiRepository.GetAll()
+	assert.NoError(t, err)
+	assert.Len(t, apis, 0)
+}
+
+func TestUp(t *testing.T) {
+	t.Run("追加したバージョンのみ適用する", func(t *testing.T) {
+		conn := openSQLite(t)
+		first := []migration.Migration{
+			{Version: 1, Name: "create_items", Up: func(d migration.Dialect) []string {
+				return d.CreateTable(migration.Table{
+					Name:       "items",
+					Columns:    []migration.Column{{Name: "id", Type: migration.TypeString, Size: 36, NotNull: true}},
+					PrimaryKey: []string{"id"},
+				})
+			}},
+		}
+		applied, err := migration.Up(conn, first)
+		assert.NoError(t, err)
+		assert.Len(t, applied, 1)
+
+		second := append(first, migration.Migration{Version: 2, Name: "add_items_name", Up: func(d migration.Dialect) []string {
+			return []string{"ALTER TABLE " + d.Quote("items") + " ADD COLUMN " + d.Quote("name") + " text"}
+		}})
+		applied, err = migration.Up(conn, second)
+		assert.NoError(t, err)
+		if assert.Len(t, applied, 1) {
+			assert.Equal(t, int64(2), applied[0].Version)
+		}
+		assert.True(t, conn.Dialect().HasColumn("items", "name"))
+	})
+	t.Run("失敗したマイグレーションは記録しない", func(t *testing.T) {
+		conn := openSQLite(t)
+		migrations := []migration.Migration{
+			{Version: 1, Name: "invalid", Up: func(d migration.Dialect) []string {
+				return []string{"CREATE TABLE " + d.Quote("items") + " (" + d.Quote("id") + " text)", "INVALID SQL"}
+			}},
+		}
+
+		_, err := migration.Up(conn, migrations)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "1_invalid")
+		assert.False(t, conn.HasTable("items"))
+		var count int
+		conn.Table(migration.TableName).Count(&count)
+		assert.Equal(t, 0, count)
+	})
+	t.Run("CORSの設定を追加する前のAPIは、すべてのオリジンを許可する", func(t *testing.T) {
+		conn := openSQLite(t)
+		_, err := migration.Up(conn, migration.Migrations[:2])
+		assert.NoError(t, err)
+		assert.NoError(t, conn.Exec("INSERT INTO apis (id, name, url) VALUES ('api-1', 'Users', 'users')").Error)
+
+		_, err = migration.Up(conn, migration.Migrations)
+
+		assert.NoError(t, err)
+		api, err := _apiRepository.NewAPIRepository(conn).GetByID("api-1")
+		assert.NoError(t, err)
+		assert.Equal(t, domain.CORSAllowAll, api.CORSAllowedOrigins)
+		assert.False(t, api.CORSAllowCredentials)
+	})
+	t.Run("init.sqlで作成したデータベースに、不足している列を追加する", func(t *testing.T) {
+		conn := openSQLite(t)
+		// docker/mysql/init.sqlで作成していたテーブル(SQLiteで作成できるよう、MySQL固有の指定を除く)
+		for _, statement := range initSQL {
+			if !assert.NoError(t, conn.Exec(statement).Error) {
+				t.FailNow()
+			}
+		}
+
+		applied, err := migration.Up(conn, migration.Migrations)
+
+		assert.NoError(t, err)
+		assert.Len(t, applied, len(migration.Migrations))
+		assert.NoError(t, migration.Check(conn, migration.Migrations))
+		for table, columns := range map[string][]string{
+			"apis":    {"stream_enabled", "rate_limit", "cors_allowed_origins"},
+			"methods": {"mode", "mock_response", "mock_status", "mock_latency", "before_script", "after_script", "rate_limit"},
+			"models":  {"collection_name"},
+		} {
+			for _, column := range columns {
+				assert.True(t, conn.Dialect().HasColumn(table, column), table+"."+column)
+			}
+		}
+
+		api, err := _apiRepository.NewAPIRepository(conn).GetByURL("my-project/api/users")
+		assert.NoError(t, err)
+		assert.False(t, api.StreamEnabled)
+		methods, err := _methodRepository.NewMethodRepository(conn).GetListByAPIIDAndType(api.ID, "GET")
+		assert.NoError(t, err)
+		if assert.Len(t, methods, 1) {
+			assert.Equal(t, domain.MethodModeLive, methods[0].Mode)
+		}
+		// コレクション名が保存されていないModelは、以前と同じくModel名のコレクションを使用する
+		model, err := _modelRepository.NewModelRepository(conn).GetByAPIID(api.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "", model.CollectionName)
+		assert.Equal(t, "User", model.GetCollectionName())
+	})
+	t.Run("新しく作成したデータベースでは、列を追加しない", func(t *testing.T) {
+		conn := openSQLite(t)
+		_, err := migration.Up(conn, migration.Migrations[:5])
+		assert.NoError(t, err)
+
+		applied, err := migration.Up(conn, migration.Migrations[:6])
+
+		assert.NoError(t, err)
+		if assert.Len(t, applied, 1) {
+			assert.Equal(t, "add_init_sql_missing_columns", applied[0].Name)
+		}
+		assert.NoError(t, migration.Check(conn, migration.Migrations[:6]))
+	})
+	t.Run("変更の履歴は対象ごとにバージョンが重複しない", func(t *testing.T) {
+		conn := openSQLite(t)
+		_, err := migration.Up(conn, migration.Migrations)
+		assert.NoError(t, err)
+		event := domain.ChangeEvent{ID: "event-1", EntityType: domain.EntityTypeMethod, EntityID: "method-1", Version: 1, Action: domain.ChangeActionCreate}
+		assert.NoError(t, conn.Create(&event).Error)
+
+		event.ID = "event-2"
+		assert.Error(t, conn.Create(&event).Error)
+		event.EntityID = "method-2"
+		assert.NoError(t, conn.Create(&event).Error)
+	})
+	t.Run("バージョンの重複", func(t *testing.T) {
+		conn := openSQLite(t)
+		noop := func(d migration.Dialect) []string { return nil }
+
+		_, err := migration.Up(conn, []migration.Migration{{Version: 1, Up: noop}, {Version: 1, Up: noop}})
+
+		assert.Error(t, err)
+	})
+}
+
+// initSQL docker/mysql/init.sqlで作成していたテーブルとデータ
+var initSQL = []string{
+	`CREATE TABLE apis (
+		id varchar(36) NOT NULL,
+		name varchar(64) NOT NULL DEFAULT '',
+		url varchar(64) NOT NULL DEFAULT '',
+		description varchar(255) NOT NULL DEFAULT '',
+		created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id)
+	)`,
+	`CREATE TABLE methods (
+		id varchar(36) NOT NULL,
+		api_id varchar(36) NOT NULL,
+		type varchar(8) NOT NULL DEFAULT '',
+		url varchar(64) NOT NULL DEFAULT '',
+		description varchar(255) NOT NULL DEFAULT '',
+		request_parameter varchar(64) NOT NULL DEFAULT '',
+		request_model_id varchar(36) NOT NULL DEFAULT '',
+		response_model_id varchar(36) NOT NULL DEFAULT '',
+		is_array boolean,
+		created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id),
+		FOREIGN KEY (api_id) REFERENCES apis(id)
+	)`,
+	`CREATE TABLE models (
+		id varchar(36) NOT NULL,
+		api_id varchar(36) NOT NULL,
+		name varchar(64) NOT NULL DEFAULT '',
+		description varchar(255) NOT NULL DEFAULT '',
+		schema text,
+		created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id)
+	)`,
+	`INSERT INTO apis (id, name, url, description) VALUES ('api-1', 'Users', 'my-project/api/users', 'ユーザーに関する操作をするAPIです')`,
+	`INSERT INTO methods (id, api_id, type, url, description, request_parameter, request_model_id, response_model_id, is_array) VALUES
+		('method-1', 'api-1', 'GET', '', 'すべてのユーザーを取得します。', '', '', 'model-1', true)`,
+	`INSERT INTO models (id, api_id, name, description, schema) VALUES ('model-1', 'api-1', 'User', 'ユーザーを定義するモデルです。', '{"type": "object"}')`,
+}
+
+// items テスト用のマイグレーション(バージョン1でテーブルを作成し、バージョン2で列を追加する)
+func items() []migration.Migration {
+	return []migration.Migration{
+		{
+			Version: 1,
+			Name:    "create_items",
+			Up: func(d migration.Dialect) []string {
+				return d.CreateTable(migration.Table{
+					Name:       "items",
+					Columns:    []migration.Column{{Name: "id", Type: migration.TypeString, Size: 36, NotNull: true}},
+					PrimaryKey: []string{"id"},
+				})
+			},
+			Down: func(d migration.Dialect) []string {
+				return []string{d.DropTable("items")}
+			},
+		},
+		{
+			Version: 2,
+			Name:    "create_tags",
+			Up: func(d migration.Dialect) []string {
+				return d.CreateTable(migration.Table{Name: "tags", Columns: []migration.Column{{Name: "name", Type: migration.TypeText}}})
+			},
+			Down: func(d migration.Dialect) []string {
+				return []string{d.DropTable("tags")}
+			},
+		},
+	}
+}
+
+func TestDown(t *testing.T) {
+	t.Run("新しいバージョンから取り消す", func(t *testing.T) {
+		conn := openSQLite(t)
+		_, err := migration.Up(conn, items())
+		assert.NoError(t, err)
+
+		reverted, err := migration.Down(conn, items(), 1)
+
+		assert.NoError(t, err)
+		if assert.Len(t, reverted, 1) {
+			assert.Equal(t, int64(2), reverted[0].Version)
+		}
+		assert.False(t, conn.HasTable("tags"))
+		assert.True(t, conn.HasTable("items"))
+
+		// 取り消したマイグレーションは再度適用できる
+		applied, err := migration.Up(conn, items())
+		assert.NoError(t, err)
+		assert.Len(t, applied, 1)
+		assert.True(t, conn.HasTable("tags"))
+	})
+	t.Run("管理画面のテーブルをすべて取り消す", func(t *testing.T) {
+		conn := openSQLite(t)
+		_, err := migration.Up(conn, migration.Migrations)
+		assert.NoError(t, err)
+
+		reverted, err := migration.Down(conn, migration.Migrations, len(migration.Migrations))
+
+		assert.NoError(t, err)
+		assert.Len(t, reverted, len(migration.Migrations))
+		assert.False(t, conn.HasTable("apis"))
+		assert.False(t, conn.HasTable("methods"))
+	})
+	t.Run("追加した列を削除し、データを残す", func(t *testing.T) {
+		conn := openSQLite(t)
+		_, err := migration.Up(conn, migration.Migrations)
+		assert.NoError(t, err)
+		assert.NoError(t, conn.Exec("INSERT INTO apis (id, name, url, rate_limit) VALUES ('api-1', 'Users', 'users', 10)").Error)
+
+		// 管理画面のテーブルを作成したバージョンまで戻す
+		steps := len(migration.Migrations) - 1
+		reverted, err := migration.Down(conn, migration.Migrations, steps)
+
+		assert.NoError(t, err)
+		if assert.Len(t, reverted, steps) {
+			assert.Equal(t, "add_change_events_actor_ip", reverted[0].Name)
+			assert.Equal(t, "add_rate_limits", reverted[steps-1].Name)
+		}
+		assert.False(t, conn.HasTable("audit_logs"))
+		assert.False(t, conn.HasTable("change_events"))
+		assert.False(t, conn.Dialect().HasColumn("apis", "cors_allowed_origins"))
+		assert.False(t, conn.Dialect().HasColumn("apis", "rate_limit"))
+		assert.False(t, conn.Dialect().HasColumn("methods", "rate_burst"))
+		var count int
+		conn.Table("apis").Where("id = ? AND url = ?", "api-1", "users").Count(&count)
+		assert.Equal(t, 1, count)
+
+		// 再度適用すると、既定値の列を追加する
+		_, err = migration.Up(conn, migration.Migrations)
+		assert.NoError(t, err)
+		assert.True(t, conn.Dialect().HasColumn("apis", "rate_limit"))
+	})
+	t.Run("取り消せないマイグレーション", func(t *testing.T) {
+		conn := openSQLite(t)
+		migrations := items()
+		migrations[1].Down = nil
+		_, err := migration.Up(conn, migrations)
+		assert.NoError(t, err)
+
+		reverted, err := migration.Down(conn, migrations, 2)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "2_create_tags")
+		assert.Len(t, reverted, 0)
+		assert.True(t, conn.HasTable("items"))
+	})
+}
+
+func TestCheck(t *testing.T) {
+	t.Run("未適用のマイグレーション", func(t *testing.T) {
+		conn := openSQLite(t)
+		_, err := migration.Up(conn, items()[:1])
+		assert.NoError(t, err)
+
+		err = migration.Check(conn, items())
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "2_create_tags")
+	})
+	t.Run("データベースの方が新しい", func(t *testing.T) {
+		conn := openSQLite(t)
+		_, err := migration.Up(conn, items())
+		assert.NoError(t, err)
+
+		err = migration.Check(conn, items()[:1])
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "2_create_tags")
+
+		// 古いバージョンでは適用も取り消しもしない
+		_, err = migration.Up(conn, items()[:1])
+		assert.Error(t, err)
+		_, err = migration.Down(conn, items()[:1], 1)
+		assert.Error(t, err)
+		assert.True(t, conn.HasTable("items"))
+	})
+	t.Run("適用後に変更されたマイグレーション", func(t *testing.T) {
+		conn := openSQLite(t)
+		_, err := migration.Up(conn, items())
+		assert.NoError(t, err)
+		modified := items()
+		modified[0].Up = func(d migration.Dialect) []string {
+			return []string{"CREATE TABLE " + d.Quote("items") + " (" + d.Quote("id") + " text)"}
+		}
+
+		err = migration.Check(conn, modified)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "checksum")
+	})
+	t.Run("一致する", func(t *testing.T) {
+		conn := openSQLite(t)
+		_, err := migration.Up(conn, items())
+		assert.NoError(t, err)
+
+		assert.NoError(t, migration.Check(conn, items()))
+	})
+	t.Run("記録するテーブルがない場合はバージョン0とし、テーブルを作成しない", func(t *testing.T) {
+		conn := openSQLite(t)
+
+		err := migration.Check(conn, items())
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "1_create_items")
+		assert.False(t, conn.HasTable(migration.TableName))
+		assert.NoError(t, migration.Check(conn, nil))
+		assert.False(t, conn.HasTable(migration.TableName))
+	})
+	t.Run("チェックサムを記録する前に適用したマイグレーションは、列を追加せずに確認する", func(t *testing.T) {
+		conn := openSQLite(t)
+		conn.Exec(`CREATE TABLE "schema_migrations" ("version" bigint NOT NULL, "name" varchar(255) NOT NULL DEFAULT '', "applied_at" datetime NOT NULL DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY ("version"))`)
+		conn.Exec(`INSERT INTO "schema_migrations" ("version", "name") VALUES (1, 'create_items')`)
+
+		assert.NoError(t, migration.Check(conn, items()[:1]))
+		assert.False(t, conn.Dialect().HasColumn(migration.TableName, "checksum"))
+	})
+}
+
+func TestGetStatus(t *testing.T) {
+	t.Run("適用状況", func(t *testing.T) {
+		conn := openSQLite(t)
+		_, err := migration.Up(conn, items()[:1])
+		assert.NoError(t, err)
+
+		statuses, err := migration.GetStatus(conn, items())
+
+		assert.NoError(t, err)
+		if assert.Len(t, statuses, 2) {
+			assert.True(t, statuses[0].Applied)
+			assert.False(t, statuses[0].AppliedAt.IsZero())
+			assert.False(t, statuses[0].Modified)
+			assert.False(t, statuses[1].Applied)
+		}
+	})
+	t.Run("チェックサムを記録する前に適用したマイグレーション", func(t *testing.T) {
+		conn := openSQLite(t)
+		// チェックサムの列がない記録用のテーブル
+		conn.Exec(`CREATE TABLE "schema_migrations" ("version" bigint NOT NULL, "name" varchar(255) NOT NULL DEFAULT '', "applied_at" datetime NOT NULL DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY ("version"))`)
+		conn.Exec(`INSERT INTO "schema_migrations" ("version", "name") VALUES (1, 'create_items')`)
+
+		statuses, err := migration.GetStatus(conn, items())
+
+		assert.NoError(t, err)
+		if assert.Len(t, statuses, 2) {
+			assert.True(t, statuses[0].Applied)
+			assert.False(t, statuses[0].Modified)
+		}
+		assert.True(t, conn.Dialect().HasColumn(migration.TableName, "checksum"))
+	})
+}
+
+func TestCreateTable(t *testing.T) {
+	table := migration.Table{
+		Name: "models",
+		Columns: []migration.Column{
+			{Name: "id", Type: migration.TypeString, Size: 36, NotNull: true},
+			{Name: "schema", Type: migration.TypeText},
+			{Name: "created_at", Type: migration.TypeTime, NotNull: true, Default: "CURRENT_TIMESTAMP"},
+		},
+		PrimaryKey: []string{"id"},
+		Indexes:    []migration.Index{{Name: "idx_models_schema", Columns: []string{"schema"}}},
+	}
+
+	t.Run("MySQL", func(t *testing.T) {
+		d, _ := migration.NewDialect("mysql")
+		statements := d.CreateTable(table)
+
+		if assert.Len(t, statements, 1) {
+			assert.Contains(t, statements[0], "CREATE TABLE IF NOT EXISTS `models`")
+			assert.Contains(t, statements[0], "`schema` text")
+			assert.Contains(t, statements[0], "`created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP")
+			assert.Contains(t, statements[0], "KEY `idx_models_schema` (`schema`)")
+		}
+	})
+	t.Run("PostgreSQL", func(t *testing.T) {
+		d, _ := migration.NewDialect("postgres")
+		statements := d.CreateTable(table)
+
+		if assert.Len(t, statements, 2) {
+			assert.Contains(t, statements[0], `"created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP`)
+			assert.False(t, strings.Contains(statements[0], "`"))
+			assert.Equal(t, `CREATE INDEX IF NOT EXISTS "idx_models_schema" ON "models" ("schema")`, statements[1])
+		}
+	})
+	t.Run("列の追加と削除", func(t *testing.T) {
+		column := migration.Column{Name: "rate_limit", Type: migration.TypeInt, NotNull: true, Default: "0"}
+		mysql, _ := migration.NewDialect("mysql")
+		postgres, _ := migration.NewDialect("postgres")
+
+		assert.Equal(t, "ALTER TABLE `models` ADD COLUMN `rate_limit` int NOT NULL DEFAULT 0", mysql.AddColumn("models", column))
+		assert.Equal(t, []string{`ALTER TABLE "models" DROP COLUMN "schema"`}, postgres.DropColumns(table, []string{"schema"}))
+	})
+	t.Run("一意のインデックスの作成と削除", func(t *testing.T) {
+		index := migration.Index{Name: "idx_models_name", Columns: []string{"api_id", "name"}, Unique: true}
+		mysql, _ := migration.NewDialect("mysql")
+		postgres, _ := migration.NewDialect("postgres")
+
+		assert.Equal(t, "CREATE UNIQUE INDEX `idx_models_name` ON `models` (`api_id`, `name`)", mysql.CreateIndex("models", index))
+		assert.Equal(t, "DROP INDEX `idx_models_name` ON `models`", mysql.DropIndex("models", index.Name))
+		assert.Equal(t, `CREATE UNIQUE INDEX IF NOT EXISTS "idx_models_name" ON "models" ("api_id", "name")`, postgres.CreateIndex("models", index))
+		assert.Equal(t, `DROP INDEX IF EXISTS "idx_models_name"`, postgres.DropIndex("models", index.Name))
+	})
+	t.Run("未対応のドライバ", func(t *testing.T) {
+		_, err := migration.NewDialect("oracle")
+
+		assert.Error(t, err)
+	})
+}
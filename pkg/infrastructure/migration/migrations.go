@@ -0,0 +1,387 @@
+package migration
+
+// Migrations 管理画面のマイグレーション(バージョン順)
+var Migrations = []Migration{
+	{Version: 1, Name: "create_admin_tables", Up: createAdminTables, Down: dropAdminTables},
+	{Version: 2, Name: "add_rate_limits", Up: addRateLimits, Down: dropRateLimits},
+	{Version: 3, Name: "add_api_cors", Up: addAPICORS, Down: dropAPICORS},
+	{Version: 4, Name: "create_audit_logs", Up: createAuditLogs, Down: dropAuditLogs},
+	{Version: 5, Name: "create_change_events", Up: createChangeEvents, Down: dropChangeEvents},
+	{Version: 6, Name: "add_init_sql_missing_columns", Ensure: initSQLMissingColumns, Down: keepInitSQLMissingColumns},
+	{Version: 7, Name: "add_change_events_version_unique", Up: addChangeEventsVersionUnique, Down: dropChangeEventsVersionUnique},
+	{Version: 8, Name: "add_change_events_actor_ip", Up: addChangeEventsActorIP, Down: dropChangeEventsActorIP},
+}
+
+// timestamps すべてのテーブルに共通する列(domain.CommonColumn)
+func timestamps() []Column {
+	return []Column{
+		{Name: "created_at", Type: TypeTime, NotNull: true, Default: "CURRENT_TIMESTAMP"},
+		{Name: "updated_at", Type: TypeTime, NotNull: true, Default: "CURRENT_TIMESTAMP"},
+	}
+}
+
+// id 主キーの列(UUID)
+func id() Column {
+	return Column{Name: "id", Type: TypeString, Size: 36, NotNull: true}
+}
+
+// createAdminTables 管理画面のテーブルを作成します(以前はdocker/mysql/init.sqlで作成していたテーブル)
+// init.sqlで作成済みのデータベースでも適用できるよう、存在するテーブルは作成しません
+// (存在するテーブルに不足している列は、バージョン6で追加します)
+func createAdminTables(d Dialect) []string {
+	statements := []string{}
+	for _, table := range adminTables() {
+		statements = append(statements, d.CreateTable(table)...)
+	}
+	return statements
+}
+
+// dropAdminTables 管理画面のテーブルを削除します(外部キーで参照するテーブルから削除します)
+func dropAdminTables(d Dialect) []string {
+	tables := adminTables()
+	statements := []string{}
+	for i := len(tables) - 1; i >= 0; i-- {
+		statements = append(statements, d.DropTable(tables[i].Name))
+	}
+	return statements
+}
+
+// adminTables 管理画面のテーブル(バージョン1)
+func adminTables() []Table {
+	return []Table{
+		{
+			Name: "apis",
+			Columns: append([]Column{
+				id(),
+				{Name: "name", Type: TypeString, Size: 64, NotNull: true, Default: "''"},
+				{Name: "url", Type: TypeString, Size: 64, NotNull: true, Default: "''"},
+				{Name: "description", Type: TypeString, Size: 255, NotNull: true, Default: "''"},
+				{Name: "stream_enabled", Type: TypeBool, NotNull: true, Default: "false"},
+			}, timestamps()...),
+			PrimaryKey: []string{"id"},
+		},
+		{
+			Name: "api_aliases",
+			Columns: append([]Column{
+				id(),
+				{Name: "api_id", Type: TypeString, Size: 36, NotNull: true},
+				{Name: "url", Type: TypeString, Size: 64, NotNull: true, Default: "''"},
+			}, timestamps()...),
+			PrimaryKey:  []string{"id"},
+			ForeignKeys: []ForeignKey{{Column: "api_id", RefTable: "apis", RefColumn: "id"}},
+		},
+		{
+			Name: "methods",
+			Columns: append([]Column{
+				id(),
+				{Name: "api_id", Type: TypeString, Size: 36, NotNull: true},
+				{Name: "type", Type: TypeString, Size: 8, NotNull: true, Default: "''"},
+				{Name: "url", Type: TypeString, Size: 64, NotNull: true, Default: "''"},
+				{Name: "description", Type: TypeString, Size: 255, NotNull: true, Default: "''"},
+				{Name: "request_parameter", Type: TypeString, Size: 64, NotNull: true, Default: "''"},
+				{Name: "request_model_id", Type: TypeString, Size: 36, NotNull: true, Default: "''"},
+				{Name: "response_model_id", Type: TypeString, Size: 36, NotNull: true, Default: "''"},
+				{Name: "is_array", Type: TypeBool, NotNull: true, Default: "false"},
+				{Name: "mode", Type: TypeString, Size: 8, NotNull: true, Default: "'live'"},
+				{Name: "mock_response", Type: TypeText},
+				{Name: "mock_status", Type: TypeInt, NotNull: true, Default: "0"},
+				{Name: "mock_latency", Type: TypeInt, NotNull: true, Default: "0"},
+				{Name: "before_script", Type: TypeText},
+				{Name: "after_script", Type: TypeText},
+			}, timestamps()...),
+			PrimaryKey:  []string{"id"},
+			ForeignKeys: []ForeignKey{{Column: "api_id", RefTable: "apis", RefColumn: "id"}},
+		},
+		{
+			Name: "models",
+			Columns: append([]Column{
+				id(),
+				{Name: "api_id", Type: TypeString, Size: 36, NotNull: true},
+				{Name: "name", Type: TypeString, Size: 64, NotNull: true, Default: "''"},
+				{Name: "description", Type: TypeString, Size: 255, NotNull: true, Default: "''"},
+				{Name: "schema", Type: TypeText},
+				{Name: "collection_name", Type: TypeString, Size: 64, NotNull: true, Default: "''"},
+			}, timestamps()...),
+			PrimaryKey: []string{"id"},
+		},
+		{
+			Name: "fixtures",
+			Columns: append([]Column{
+				id(),
+				{Name: "model_id", Type: TypeString, Size: 36, NotNull: true},
+				{Name: "name", Type: TypeString, Size: 64, NotNull: true, Default: "''"},
+				{Name: "description", Type: TypeString, Size: 255, NotNull: true, Default: "''"},
+				{Name: "data", Type: TypeLongText},
+			}, timestamps()...),
+			PrimaryKey: []string{"id"},
+		},
+		{
+			Name: "webhooks",
+			Columns: append([]Column{
+				id(),
+				{Name: "api_id", Type: TypeString, Size: 36, NotNull: true},
+				{Name: "url", Type: TypeString, Size: 2048, NotNull: true, Default: "''"},
+				{Name: "events", Type: TypeString, Size: 64, NotNull: true, Default: "''"},
+				{Name: "secret", Type: TypeString, Size: 255, NotNull: true, Default: "''"},
+				{Name: "disabled", Type: TypeBool, NotNull: true, Default: "false"},
+			}, timestamps()...),
+			PrimaryKey: []string{"id"},
+			Indexes:    []Index{{Name: "idx_webhooks_api_id", Columns: []string{"api_id"}}},
+		},
+		{
+			Name: "webhook_deliveries",
+			Columns: append([]Column{
+				id(),
+				{Name: "webhook_id", Type: TypeString, Size: 36, NotNull: true},
+				{Name: "event_id", Type: TypeString, Size: 36, NotNull: true},
+				{Name: "event_type", Type: TypeString, Size: 16, NotNull: true, Default: "''"},
+				{Name: "payload", Type: TypeLongText},
+				{Name: "status", Type: TypeString, Size: 16, NotNull: true, Default: "'pending'"},
+				{Name: "attempts", Type: TypeInt, NotNull: true, Default: "0"},
+				{Name: "next_attempt_at", Type: TypeTime, NotNull: true, Default: "CURRENT_TIMESTAMP"},
+				{Name: "last_error", Type: TypeString, Size: 255, NotNull: true, Default: "''"},
+			}, timestamps()...),
+			PrimaryKey: []string{"id"},
+			Indexes: []Index{
+				{Name: "idx_webhook_deliveries_webhook_id", Columns: []string{"webhook_id"}},
+				{Name: "idx_webhook_deliveries_status", Columns: []string{"status", "next_attempt_at"}},
+			},
+		},
+		{
+			Name: "webhook_delivery_logs",
+			Columns: append([]Column{
+				id(),
+				{Name: "delivery_id", Type: TypeString, Size: 36, NotNull: true},
+				{Name: "attempt", Type: TypeInt, NotNull: true, Default: "0"},
+				{Name: "status_code", Type: TypeInt, NotNull: true, Default: "0"},
+				{Name: "error", Type: TypeString, Size: 255, NotNull: true, Default: "''"},
+				{Name: "duration", Type: TypeInt, NotNull: true, Default: "0"},
+			}, timestamps()...),
+			PrimaryKey: []string{"id"},
+			Indexes:    []Index{{Name: "idx_webhook_delivery_logs_delivery_id", Columns: []string{"delivery_id"}}},
+		},
+	}
+}
+
+// adminTable 管理画面のテーブル(バージョン1)を名前で取得します
+func adminTable(name string) Table {
+	for _, table := range adminTables() {
+		if table.Name == name {
+			return table
+		}
+	}
+	panic("unknown table " + name)
+}
+
+// adminColumn 管理画面のテーブル(バージョン1)の列を名前で取得します
+func adminColumn(table, name string) TableColumn {
+	for _, column := range adminTable(table).Columns {
+		if column.Name == name {
+			return TableColumn{Table: table, Column: column}
+		}
+	}
+	panic("unknown column " + table + "." + name)
+}
+
+// initSQLMissingColumns バージョン1で定義しているが、docker/mysql/init.sqlで作成したテーブルにない列
+// init.sqlで作成済みのテーブルはバージョン1で作成しないため、存在しない列のみ追加します
+func initSQLMissingColumns() []TableColumn {
+	return []TableColumn{
+		adminColumn("apis", "stream_enabled"),
+		adminColumn("methods", "mode"),
+		adminColumn("methods", "mock_response"),
+		adminColumn("methods", "mock_status"),
+		adminColumn("methods", "mock_latency"),
+		adminColumn("methods", "before_script"),
+		adminColumn("methods", "after_script"),
+		adminColumn("models", "collection_name"),
+	}
+}
+
+// keepInitSQLMissingColumns 追加した列はバージョン1の定義に含まれるため、取り消しても削除しません
+func keepInitSQLMissingColumns(d Dialect) []string {
+	return nil
+}
+
+// apiRateLimitColumns apisに追加する、リクエスト数の制限の列
+func apiRateLimitColumns() []Column {
+	return []Column{
+		{Name: "rate_limit", Type: TypeInt, NotNull: true, Default: "0"},
+		{Name: "rate_burst", Type: TypeInt, NotNull: true, Default: "0"},
+		{Name: "monthly_quota", Type: TypeInt, NotNull: true, Default: "0"},
+	}
+}
+
+// methodRateLimitColumns methodsに追加する、リクエスト数の制限の列
+func methodRateLimitColumns() []Column {
+	return []Column{
+		{Name: "rate_limit", Type: TypeInt, NotNull: true, Default: "0"},
+		{Name: "rate_burst", Type: TypeInt, NotNull: true, Default: "0"},
+	}
+}
+
+// addRateLimits API、Methodにリクエスト数の制限の列を追加します
+func addRateLimits(d Dialect) []string {
+	statements := []string{}
+	for _, column := range apiRateLimitColumns() {
+		statements = append(statements, d.AddColumn("apis", column))
+	}
+	for _, column := range methodRateLimitColumns() {
+		statements = append(statements, d.AddColumn("methods", column))
+	}
+	return statements
+}
+
+// dropRateLimits API、Methodからリクエスト数の制限の列を削除します(バージョン1の定義に戻します)
+func dropRateLimits(d Dialect) []string {
+	statements := d.DropColumns(adminTable("apis"), columnNames(apiRateLimitColumns()))
+	return append(statements, d.DropColumns(adminTable("methods"), columnNames(methodRateLimitColumns()))...)
+}
+
+func columnNames(columns []Column) []string {
+	names := make([]string, 0, len(columns))
+	for _, c := range columns {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// apiCORSColumns apisに追加する、APIごとのCORSの設定の列
+func apiCORSColumns() []Column {
+	return []Column{
+		{Name: "cors_allowed_origins", Type: TypeString, Size: 1024, NotNull: true, Default: "''"},
+		{Name: "cors_allowed_methods", Type: TypeString, Size: 255, NotNull: true, Default: "''"},
+		{Name: "cors_allowed_headers", Type: TypeString, Size: 1024, NotNull: true, Default: "''"},
+		{Name: "cors_allow_credentials", Type: TypeBool, NotNull: true, Default: "false"},
+		{Name: "cors_max_age", Type: TypeInt, NotNull: true, Default: "0"},
+	}
+}
+
+// addAPICORS APIにCORSの設定の列を追加します
+// 追加前のAPIは、以前と同じくすべてのオリジンを許可します
+func addAPICORS(d Dialect) []string {
+	statements := []string{}
+	for _, column := range apiCORSColumns() {
+		statements = append(statements, d.AddColumn("apis", column))
+	}
+	return append(statements, "UPDATE "+d.Quote("apis")+" SET "+d.Quote("cors_allowed_origins")+" = '*'")
+}
+
+// dropAPICORS APIからCORSの設定の列を削除します(バージョン2の定義に戻します)
+func dropAPICORS(d Dialect) []string {
+	apis := adminTable("apis")
+	apis.Columns = append(apis.Columns, apiRateLimitColumns()...)
+	return d.DropColumns(apis, columnNames(apiCORSColumns()))
+}
+
+// auditLogsTable APIサーバーの書き込みの監査ログ
+func auditLogsTable() Table {
+	return Table{
+		Name: "audit_logs",
+		Columns: append([]Column{
+			id(),
+			{Name: "api_id", Type: TypeString, Size: 36, NotNull: true},
+			{Name: "method_id", Type: TypeString, Size: 36, NotNull: true, Default: "''"},
+			{Name: "http_method", Type: TypeString, Size: 16, NotNull: true, Default: "''"},
+			{Name: "url", Type: TypeString, Size: 2048, NotNull: true, Default: "''"},
+			{Name: "document_key", Type: TypeString, Size: 255, NotNull: true, Default: "''"},
+			{Name: "caller", Type: TypeString, Size: 255, NotNull: true, Default: "''"},
+			{Name: "before_document", Type: TypeLongText},
+			{Name: "after_document", Type: TypeLongText},
+			{Name: "status", Type: TypeInt, NotNull: true, Default: "0"},
+			{Name: "error", Type: TypeString, Size: 255, NotNull: true, Default: "''"},
+			{Name: "latency", Type: TypeInt, NotNull: true, Default: "0"},
+		}, timestamps()...),
+		PrimaryKey: []string{"id"},
+		Indexes: []Index{
+			{Name: "idx_audit_logs_api_id", Columns: []string{"api_id", "created_at"}},
+			{Name: "idx_audit_logs_document_key", Columns: []string{"api_id", "document_key"}},
+			{Name: "idx_audit_logs_caller", Columns: []string{"caller", "created_at"}},
+			{Name: "idx_audit_logs_created_at", Columns: []string{"created_at"}},
+		},
+	}
+}
+
+// createAuditLogs 監査ログのテーブルを作成します
+func createAuditLogs(d Dialect) []string {
+	return d.CreateTable(auditLogsTable())
+}
+
+// dropAuditLogs 監査ログのテーブルを削除します
+func dropAuditLogs(d Dialect) []string {
+	return []string{d.DropTable("audit_logs")}
+}
+
+// changeEventsTable 管理画面での設定の変更の履歴(domain.ChangeEvent)のテーブル
+func changeEventsTable() Table {
+	return Table{
+		Name: "change_events",
+		Columns: append([]Column{
+			id(),
+			{Name: "entity_type", Type: TypeString, Size: 16, NotNull: true},
+			{Name: "entity_id", Type: TypeString, Size: 36, NotNull: true},
+			{Name: "api_id", Type: TypeString, Size: 36, NotNull: true, Default: "''"},
+			{Name: "version", Type: TypeInt, NotNull: true},
+			{Name: "action", Type: TypeString, Size: 16, NotNull: true},
+			{Name: "reverted_version", Type: TypeInt, NotNull: true, Default: "0"},
+			{Name: "actor", Type: TypeString, Size: 255, NotNull: true, Default: "''"},
+			{Name: "before_value", Type: TypeLongText},
+			{Name: "after_value", Type: TypeLongText},
+		}, timestamps()...),
+		PrimaryKey: []string{"id"},
+		Indexes: []Index{
+			{Name: "idx_change_events_entity", Columns: []string{"entity_type", "entity_id", "version"}},
+			{Name: "idx_change_events_api_id", Columns: []string{"api_id", "created_at"}},
+		},
+	}
+}
+
+// createChangeEvents 設定の変更の履歴のテーブルを作成します
+func createChangeEvents(d Dialect) []string {
+	return d.CreateTable(changeEventsTable())
+}
+
+// dropChangeEvents 設定の変更の履歴のテーブルを削除します
+func dropChangeEvents(d Dialect) []string {
+	return []string{d.DropTable("change_events")}
+}
+
+// changeEventsVersionIndex 対象ごとのバージョンを一意にするインデックス
+// 同時に変更した場合に同じバージョンを採番しないよう、重複する履歴の追加を拒否します
+func changeEventsVersionIndex() Index {
+	return Index{Name: "idx_change_events_version", Columns: []string{"entity_type", "entity_id", "version"}, Unique: true}
+}
+
+// addChangeEventsVersionUnique 変更の履歴のバージョンのインデックスを、一意のインデックスに置き換えます
+// 既に同じバージョンの履歴が重複している場合は失敗するため、重複を解消してから適用します
+func addChangeEventsVersionUnique(d Dialect) []string {
+	return []string{
+		d.CreateIndex("change_events", changeEventsVersionIndex()),
+		d.DropIndex("change_events", "idx_change_events_entity"),
+	}
+}
+
+// dropChangeEventsVersionUnique 一意のインデックスを、バージョン5のインデックスに戻します
+func dropChangeEventsVersionUnique(d Dialect) []string {
+	return []string{
+		d.CreateIndex("change_events", changeEventsTable().Indexes[0]),
+		d.DropIndex("change_events", changeEventsVersionIndex().Name),
+	}
+}
+
+// changeEventsActorIPColumn 変更の履歴に追加する、操作者のIPアドレスの列
+func changeEventsActorIPColumn() Column {
+	return Column{Name: "actor_ip", Type: TypeString, Size: 64, NotNull: true, Default: "''"}
+}
+
+// addChangeEventsActorIP 変更の履歴に操作者のIPアドレスの列を追加します
+func addChangeEventsActorIP(d Dialect) []string {
+	return []string{d.AddColumn("change_events", changeEventsActorIPColumn())}
+}
+
+// dropChangeEventsActorIP 変更の履歴から操作者のIPアドレスの列を削除します(バージョン7の定義に戻します)
+func dropChangeEventsActorIP(d Dialect) []string {
+	changeEvents := changeEventsTable()
+	changeEvents.Indexes = []Index{changeEventsVersionIndex(), changeEvents.Indexes[1]}
+	return d.DropColumns(changeEvents, []string{changeEventsActorIPColumn().Name})
+}
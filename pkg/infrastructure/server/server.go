@@ -18,7 +18,7 @@ type Server struct {
 
 // NewServer Serverを初期化します
 func NewServer(c *config.Config) *Server {
-	r := NewRouter()
+	r := NewRouter(c)
 	s := newServer(c, r)
 	return &Server{
 		Router: r,
@@ -37,19 +37,38 @@ func newServer(c *config.Config, router *gin.Engine) *http.Server {
 }
 
 // NewRouter 新規でデフォルト設定のルーターを作成します
-func NewRouter() *gin.Engine {
+// 設定ファイルでCORSの許可するオリジンを指定した場合のみ、クロスオリジンのリクエストを許可します
+// (APIサーバーはAPIごとのCORSの設定を適用するため、ここでは指定しません)
+func NewRouter(c *config.Config) *gin.Engine {
 	router := gin.Default()
 
-	// アクセス許可設定
-	config := cors.DefaultConfig()
-	config.AllowAllOrigins = true
-	config.AllowHeaders = []string{"Content-Type"}
-
-	router.Use(cors.New(config))
+	if len(c.CORS.AllowedOrigins) > 0 {
+		router.Use(cors.New(corsConfig(c)))
+	}
 
 	return router
 }
 
+// corsConfig 設定ファイルのCORSの設定を、アクセス許可設定にします
+func corsConfig(c *config.Config) cors.Config {
+	config := cors.Config{
+		AllowOrigins:     c.CORS.AllowedOrigins,
+		AllowMethods:     c.CORS.AllowedMethods,
+		AllowHeaders:     c.CORS.AllowedHeaders,
+		AllowCredentials: c.CORS.AllowCredentials,
+		// ダウンロードのファイル名を参照できるようにする
+		ExposeHeaders: []string{"Content-Disposition"},
+		MaxAge:        time.Duration(c.CORS.MaxAge) * time.Second,
+	}
+	if len(config.AllowMethods) == 0 {
+		config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE"}
+	}
+	if len(config.AllowHeaders) == 0 {
+		config.AllowHeaders = []string{"Content-Type"}
+	}
+	return config
+}
+
 // Run サーバーを実行します
 func (s *Server) Run() {
 	s.server.ListenAndServe()
@@ -0,0 +1,295 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+)
+
+// Version 作成するOpenAPIのバージョン
+const Version = "3.0.3"
+
+// errorSchemaName エラーレスポンスのスキーマ名
+const errorSchemaName = "ErrorResponse"
+
+var (
+	pathParamPattern = regexp.MustCompile(`\{([^{}]+)\}`)
+	// invalidNamePattern componentsのキーに使用できない文字
+	invalidNamePattern = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+	// ignoreSchemaKeys OpenAPIのSchemaに含めない、api-creatorとJSON Schemaのキーワード
+	ignoreSchemaKeys = []string{"keys", "$schema", "$id"}
+)
+
+// Definition OpenAPIを作成するAPIの定義(Modelが未定義の場合、Model.IDは空)
+type Definition struct {
+	API     domain.API
+	Model   domain.Model
+	Methods []domain.Method
+}
+
+// Document OpenAPIのドキュメント
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers,omitempty"`
+	Tags       []Tag               `json:"tags,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info ドキュメントの情報
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Server APIのサーバー
+type Server struct {
+	URL string `json:"url"`
+}
+
+// Tag 操作の分類(APIごと)
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem パスに対する、小文字のHTTPメソッドごとの操作
+type PathItem map[string]*Operation
+
+// Operation 操作(Method)
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// Parameter パスパラメータ
+type Parameter struct {
+	Name     string                 `json:"name"`
+	In       string                 `json:"in"`
+	Required bool                   `json:"required"`
+	Schema   map[string]interface{} `json:"schema"`
+}
+
+// RequestBody リクエストボディ
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response レスポンス
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType Content-Typeごとの内容
+type MediaType struct {
+	Schema map[string]interface{} `json:"schema"`
+}
+
+// Components 再利用するスキーマ
+type Components struct {
+	Schemas map[string]interface{} `json:"schemas"`
+}
+
+// Generate APIの定義からOpenAPIのドキュメントを作成します
+// serverURLはAPIServerのベースURLで、空の場合はserversを省略します
+func Generate(info Info, serverURL string, definitions []Definition) (*Document, error) {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Schemas: map[string]interface{}{
+				errorSchemaName: map[string]interface{}{
+					"type":     "object",
+					"required": []string{"error"},
+					"properties": map[string]interface{}{
+						"error": map[string]interface{}{"type": "string"},
+					},
+				},
+			},
+		},
+	}
+	if serverURL != "" {
+		doc.Servers = []Server{{URL: strings.TrimRight(serverURL, "/")}}
+	}
+
+	for _, definition := range definitions {
+		if err := doc.addAPI(definition); err != nil {
+			return nil, fmt.Errorf("%s: %s", definition.API.URL, err.Error())
+		}
+	}
+	return doc, nil
+}
+
+// addAPI APIのModelをスキーマに、Methodを操作に追加します
+func (doc *Document) addAPI(definition Definition) error {
+	api := definition.API
+	doc.Tags = append(doc.Tags, Tag{Name: api.Name, Description: api.Description})
+
+	var schemaRef map[string]interface{}
+	var properties map[string]interface{}
+	if definition.Model.ID != "" {
+		schema, err := toSchema(definition.Model)
+		if err != nil {
+			return err
+		}
+		properties, _ = schema["properties"].(map[string]interface{})
+		name := doc.schemaName(definition.Model.Name)
+		doc.Components.Schemas[name] = schema
+		schemaRef = ref(name)
+	} else {
+		// Modelが未定義の場合は任意のオブジェクトとする
+		schemaRef = map[string]interface{}{"type": "object"}
+	}
+
+	for _, method := range definition.Methods {
+		switch method.Type {
+		case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete:
+		default:
+			continue
+		}
+		httpMethod := strings.ToLower(method.Type)
+
+		path := "/" + strings.Trim(api.URL, "/") + method.URL
+		operation := &Operation{
+			OperationID: operationID(httpMethod, path),
+			Summary:     method.Description,
+			Tags:        []string{api.Name},
+			Responses: map[string]Response{
+				"default": {
+					Description: "Error",
+					Content:     jsonContent(ref(errorSchemaName)),
+				},
+			},
+		}
+
+		for _, match := range pathParamPattern.FindAllStringSubmatch(method.URL, -1) {
+			operation.Parameters = append(operation.Parameters, Parameter{
+				Name:     match[1],
+				In:       "path",
+				Required: true,
+				Schema:   paramSchema(properties, match[1]),
+			})
+		}
+
+		if method.Type == http.MethodPost || method.Type == http.MethodPut {
+			operation.RequestBody = &RequestBody{Required: true, Content: jsonContent(schemaRef)}
+		}
+
+		status, response := successResponse(method, schemaRef)
+		operation.Responses[status] = response
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = PathItem{}
+		}
+		doc.Paths[path][httpMethod] = operation
+	}
+	return nil
+}
+
+// schemaName Model名をcomponentsのキーに変換します(重複する場合は連番を付けます)
+func (doc *Document) schemaName(modelName string) string {
+	name := invalidNamePattern.ReplaceAllString(modelName, "_")
+	if name == "" {
+		name = "Model"
+	}
+	candidate := name
+	for i := 2; ; i++ {
+		if _, ok := doc.Components.Schemas[candidate]; !ok {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s%d", name, i)
+	}
+}
+
+// toSchema ModelのJSON Schemaを、OpenAPIのSchemaに変換します
+func toSchema(model domain.Model) (map[string]interface{}, error) {
+	schema := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(model.Schema), &schema); err != nil {
+		return nil, err
+	}
+	for _, key := range ignoreSchemaKeys {
+		delete(schema, key)
+	}
+	if _, ok := schema["description"]; !ok && model.Description != "" {
+		schema["description"] = model.Description
+	}
+	return schema, nil
+}
+
+// successResponse Methodの成功時のステータスとレスポンスを返却します
+func successResponse(method domain.Method, schemaRef map[string]interface{}) (string, Response) {
+	status := http.StatusOK
+	switch method.Type {
+	case http.MethodPost:
+		status = http.StatusCreated
+	case http.MethodDelete:
+		status = http.StatusNoContent
+	}
+	if method.IsMockMode() && method.MockStatus != 0 {
+		status = method.MockStatus
+	}
+
+	response := Response{Description: http.StatusText(status)}
+	if response.Description == "" {
+		response.Description = "Success"
+	}
+	if status == http.StatusNoContent {
+		return fmt.Sprint(status), response
+	}
+
+	schema := schemaRef
+	if method.Type == http.MethodGet && method.IsArray {
+		schema = map[string]interface{}{"type": "array", "items": schemaRef}
+	}
+	response.Content = jsonContent(schema)
+	return fmt.Sprint(status), response
+}
+
+// paramSchema パスパラメータと同じ名前のプロパティの型を返却します(存在しない場合は文字列)
+func paramSchema(properties map[string]interface{}, name string) map[string]interface{} {
+	property, _ := properties[name].(map[string]interface{})
+	switch property["type"] {
+	case "integer", "number", "boolean":
+		return map[string]interface{}{"type": property["type"]}
+	}
+	return map[string]interface{}{"type": "string"}
+}
+
+// operationID HTTPメソッドとパスから操作のIDを作成します(例："get" "/v1/users/{id}" → "getV1UsersById")
+func operationID(httpMethod string, path string) string {
+	id := httpMethod
+	segments := strings.FieldsFunc(path, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '{')
+	})
+	for _, segment := range segments {
+		if strings.HasPrefix(segment, "{") {
+			id += "By"
+			segment = strings.TrimPrefix(segment, "{")
+		}
+		if segment != "" {
+			id += strings.ToUpper(segment[:1]) + segment[1:]
+		}
+	}
+	return id
+}
+
+func ref(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+func jsonContent(schema map[string]interface{}) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: schema}}
+}
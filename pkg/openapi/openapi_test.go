@@ -0,0 +1,150 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/openapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var usersDefinition = openapi.Definition{
+	API: domain.API{ID: "api", Name: "users", URL: "v1/users", Description: "ユーザー"},
+	Model: domain.Model{
+		ID:     "model",
+		Name:   "User",
+		Schema: `{"$schema": "http://json-schema.org/draft-07/schema#", "type": "object", "keys": ["id"], "required": ["id"], "properties": {"id": {"type": "integer"}, "name": {"type": "string"}}}`,
+	},
+	Methods: []domain.Method{
+		{Type: "GET", IsArray: true, Description: "一覧"},
+		{Type: "GET", URL: "/{id}"},
+		{Type: "POST"},
+		{Type: "PUT"},
+		{Type: "DELETE", URL: "/{id}"},
+		{Type: "GET", URL: "/search/{name}", Mode: domain.MethodModeMock, MockStatus: 202},
+		{Type: "PATCH"},
+	},
+}
+
+func TestGenerate(t *testing.T) {
+	doc, err := openapi.Generate(openapi.Info{Title: "api-creator", Version: "1"}, "http://localhost:9000/", []openapi.Definition{
+		usersDefinition,
+		{API: domain.API{Name: "items", URL: "items"}, Methods: []domain.Method{{Type: "POST"}}},
+	})
+	assert.NoError(t, err)
+
+	b, err := json.Marshal(doc)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"openapi": "3.0.3",
+		"info": {"title": "api-creator", "version": "1"},
+		"servers": [{"url": "http://localhost:9000"}],
+		"tags": [{"name": "users", "description": "ユーザー"}, {"name": "items"}],
+		"paths": {
+			"/v1/users": {
+				"get": {
+					"operationId": "getV1Users",
+					"summary": "一覧",
+					"tags": ["users"],
+					"responses": {
+						"200": {"description": "OK", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/User"}}}}},
+						"default": {"description": "Error", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/ErrorResponse"}}}}
+					}
+				},
+				"post": {
+					"operationId": "postV1Users",
+					"tags": ["users"],
+					"requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}},
+					"responses": {
+						"201": {"description": "Created", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}},
+						"default": {"description": "Error", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/ErrorResponse"}}}}
+					}
+				},
+				"put": {
+					"operationId": "putV1Users",
+					"tags": ["users"],
+					"requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}},
+					"responses": {
+						"200": {"description": "OK", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}},
+						"default": {"description": "Error", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/ErrorResponse"}}}}
+					}
+				}
+			},
+			"/v1/users/{id}": {
+				"get": {
+					"operationId": "getV1UsersById",
+					"tags": ["users"],
+					"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+					"responses": {
+						"200": {"description": "OK", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}},
+						"default": {"description": "Error", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/ErrorResponse"}}}}
+					}
+				},
+				"delete": {
+					"operationId": "deleteV1UsersById",
+					"tags": ["users"],
+					"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+					"responses": {
+						"204": {"description": "No Content"},
+						"default": {"description": "Error", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/ErrorResponse"}}}}
+					}
+				}
+			},
+			"/v1/users/search/{name}": {
+				"get": {
+					"operationId": "getV1UsersSearchByName",
+					"tags": ["users"],
+					"parameters": [{"name": "name", "in": "path", "required": true, "schema": {"type": "string"}}],
+					"responses": {
+						"202": {"description": "Accepted", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}},
+						"default": {"description": "Error", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/ErrorResponse"}}}}
+					}
+				}
+			},
+			"/items": {
+				"post": {
+					"operationId": "postItems",
+					"tags": ["items"],
+					"requestBody": {"required": true, "content": {"application/json": {"schema": {"type": "object"}}}},
+					"responses": {
+						"201": {"description": "Created", "content": {"application/json": {"schema": {"type": "object"}}}},
+						"default": {"description": "Error", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/ErrorResponse"}}}}
+					}
+				}
+			}
+		},
+		"components": {
+			"schemas": {
+				"ErrorResponse": {"type": "object", "required": ["error"], "properties": {"error": {"type": "string"}}},
+				"User": {"type": "object", "required": ["id"], "properties": {"id": {"type": "integer"}, "name": {"type": "string"}}}
+			}
+		}
+	}`, string(b))
+}
+
+func TestGenerateSchemaName(t *testing.T) {
+	definitions := []openapi.Definition{
+		{API: domain.API{URL: "a"}, Model: domain.Model{ID: "1", Name: "ユーザー", Schema: `{"type": "object"}`}},
+		{API: domain.API{URL: "b"}, Model: domain.Model{ID: "2", Name: "ErrorResponse", Schema: `{"type": "object"}`, Description: "説明"}},
+	}
+
+	doc, err := openapi.Generate(openapi.Info{}, "", definitions)
+
+	assert.NoError(t, err)
+	assert.Nil(t, doc.Servers)
+	assert.Contains(t, doc.Components.Schemas, "____")
+	assert.Equal(t, map[string]interface{}{"type": "object", "description": "説明"}, doc.Components.Schemas["ErrorResponse2"])
+}
+
+func TestGenerateError(t *testing.T) {
+	definitions := []openapi.Definition{
+		{API: domain.API{URL: "users"}, Model: domain.Model{ID: "1", Name: "User", Schema: `{`}},
+	}
+
+	_, err := openapi.Generate(openapi.Info{}, "", definitions)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "users: ")
+}
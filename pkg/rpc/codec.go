@@ -0,0 +1,179 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const (
+	// CodecProto protobufのバイナリ形式("application/proto")
+	CodecProto = "proto"
+	// CodecJSON protobufのJSON形式("application/json")
+	CodecJSON = "json"
+)
+
+// wellKnownPackage google.protobuf.Struct等の、JSONの値として扱う型のパッケージ
+const wellKnownPackage = "google.protobuf"
+
+// ErrUnsupportedCodec "unsupported codec"
+var ErrUnsupportedCodec = errors.New("unsupported codec")
+
+// Unmarshal リクエストを、メッセージの定義に従って読み込みます
+func Unmarshal(codec string, data []byte, desc protoreflect.MessageDescriptor) (protoreflect.Message, error) {
+	message := dynamicpb.NewMessage(desc)
+	switch codec {
+	case CodecProto:
+		return message, proto.Unmarshal(data, message)
+	case CodecJSON:
+		if len(data) == 0 {
+			return message, nil
+		}
+		return message, protojson.Unmarshal(data, message)
+	default:
+		return nil, ErrUnsupportedCodec
+	}
+}
+
+// Marshal メッセージを指定された形式で出力します
+func Marshal(codec string, message protoreflect.Message) ([]byte, error) {
+	switch codec {
+	case CodecProto:
+		return proto.Marshal(message.Interface())
+	case CodecJSON:
+		return protojson.Marshal(message.Interface())
+	default:
+		return nil, ErrUnsupportedCodec
+	}
+}
+
+// ToDocument メッセージを、項目のJSONの名前をキーとするドキュメントに変換します
+// int64はJSONの文字列ではなく数値として変換します
+func ToDocument(message protoreflect.Message) (map[string]interface{}, error) {
+	document := map[string]interface{}{}
+	var err error
+	message.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		var value interface{}
+		if fd.IsList() {
+			list := v.List()
+			values := make([]interface{}, list.Len())
+			for i := 0; i < list.Len(); i++ {
+				if values[i], err = toValue(fd, list.Get(i)); err != nil {
+					return false
+				}
+			}
+			value = values
+		} else if value, err = toValue(fd, v); err != nil {
+			return false
+		}
+		document[fd.JSONName()] = value
+		return true
+	})
+	return document, err
+}
+
+// FromDocument ドキュメントを、メッセージの定義に従って変換します
+// メッセージに定義されていない項目("_id"等)は無視します
+func FromDocument(document map[string]interface{}, desc protoreflect.MessageDescriptor) (protoreflect.Message, error) {
+	message := dynamicpb.NewMessage(desc)
+	return message, setDocument(message, document)
+}
+
+func setDocument(message protoreflect.Message, document map[string]interface{}) error {
+	fields := message.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		value, ok := document[fd.JSONName()]
+		if !ok || value == nil {
+			continue
+		}
+
+		if !fd.IsList() {
+			v, err := fromValue(fd, value, message.NewField(fd))
+			if err != nil {
+				return err
+			}
+			message.Set(fd, v)
+			continue
+		}
+
+		values, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: array is expected", fd.JSONName())
+		}
+		list := message.Mutable(fd).List()
+		for _, item := range values {
+			v, err := fromValue(fd, item, list.NewElement())
+			if err != nil {
+				return err
+			}
+			list.Append(v)
+		}
+	}
+	return nil
+}
+
+// toValue 項目の値をJSONの値に変換します
+func toValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) (interface{}, error) {
+	switch fd.Kind() {
+	case protoreflect.MessageKind:
+		if fd.Message().FullName().Parent() != wellKnownPackage {
+			return ToDocument(v.Message())
+		}
+		b, err := protojson.Marshal(v.Message().Interface())
+		if err != nil {
+			return nil, err
+		}
+		var value interface{}
+		return value, json.Unmarshal(b, &value)
+	case protoreflect.Int64Kind:
+		return v.Int(), nil
+	case protoreflect.DoubleKind:
+		return v.Float(), nil
+	default:
+		return v.Interface(), nil
+	}
+}
+
+// fromValue JSONの値を項目の値に変換します。メッセージの場合はzeroに値を設定して返却します
+func fromValue(fd protoreflect.FieldDescriptor, value interface{}, zero protoreflect.Value) (protoreflect.Value, error) {
+	invalid := fmt.Errorf("%s: %T is not %s", fd.JSONName(), value, fd.Kind())
+
+	switch fd.Kind() {
+	case protoreflect.MessageKind:
+		if fd.Message().FullName().Parent() == wellKnownPackage {
+			b, err := json.Marshal(value)
+			if err != nil {
+				return zero, err
+			}
+			return zero, protojson.Unmarshal(b, zero.Message().Interface())
+		}
+		document, ok := value.(map[string]interface{})
+		if !ok {
+			return zero, invalid
+		}
+		return zero, setDocument(zero.Message(), document)
+	case protoreflect.StringKind:
+		if s, ok := value.(string); ok {
+			return protoreflect.ValueOfString(s), nil
+		}
+	case protoreflect.Int64Kind:
+		if n, ok := value.(float64); ok {
+			return protoreflect.ValueOfInt64(int64(n)), nil
+		}
+	case protoreflect.DoubleKind:
+		if n, ok := value.(float64); ok {
+			return protoreflect.ValueOfFloat64(n), nil
+		}
+	case protoreflect.BoolKind:
+		if b, ok := value.(bool); ok {
+			return protoreflect.ValueOfBool(b), nil
+		}
+	}
+	return zero, invalid
+}
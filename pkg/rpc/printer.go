@@ -0,0 +1,127 @@
+package rpc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// scalarNames protoのスカラー型の名前
+var scalarNames = map[descriptorpb.FieldDescriptorProto_Type]string{
+	descriptorpb.FieldDescriptorProto_TYPE_STRING: "string",
+	descriptorpb.FieldDescriptorProto_TYPE_INT64:  "int64",
+	descriptorpb.FieldDescriptorProto_TYPE_DOUBLE: "double",
+	descriptorpb.FieldDescriptorProto_TYPE_BOOL:   "bool",
+}
+
+// Proto protoの定義を、.protoファイルの形式で返却します
+func (s *Service) Proto() string {
+	p := &printer{pkg: s.file.GetPackage()}
+
+	p.comment(0, s.api.Name+"\n"+s.api.Description)
+	p.line(0, "")
+	p.line(0, `syntax = "proto3";`)
+	p.line(0, "")
+	p.line(0, "package %s;", s.file.GetPackage())
+	if len(s.file.Dependency) > 0 {
+		p.line(0, "")
+	}
+	for _, dependency := range s.file.Dependency {
+		p.line(0, "import %q;", dependency)
+	}
+
+	for _, service := range s.file.Service {
+		p.line(0, "")
+		p.line(0, "service %s {", service.GetName())
+		for i, method := range service.Method {
+			if i > 0 {
+				p.line(0, "")
+			}
+			p.comment(1, s.comments[method.GetName()])
+			p.line(1, "rpc %s(%s) returns (%s);", method.GetName(), p.typeName(method.GetInputType()), p.typeName(method.GetOutputType()))
+		}
+		p.line(0, "}")
+	}
+
+	for _, message := range s.file.MessageType {
+		p.line(0, "")
+		p.message(0, message)
+	}
+	return p.String()
+}
+
+type printer struct {
+	strings.Builder
+	pkg string
+}
+
+func (p *printer) line(indent int, format string, args ...interface{}) {
+	if format != "" {
+		p.WriteString(strings.Repeat("  ", indent))
+		fmt.Fprintf(p, format, args...)
+	}
+	p.WriteString("\n")
+}
+
+// comment 空行を除いて、行ごとにコメントを出力します
+func (p *printer) comment(indent int, text string) {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			p.line(indent, "// %s", line)
+		}
+	}
+}
+
+func (p *printer) message(indent int, message *descriptorpb.DescriptorProto) {
+	p.line(indent, "message %s {", message.GetName())
+	for _, field := range message.Field {
+		label := ""
+		if field.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED {
+			label = "repeated "
+		} else if field.GetProto3Optional() {
+			label = "optional "
+		}
+
+		fieldType := scalarNames[field.GetType()]
+		if field.GetType() == descriptorpb.FieldDescriptorProto_TYPE_MESSAGE {
+			fieldType = p.typeName(field.GetTypeName())
+		}
+
+		option := ""
+		if field.GetJsonName() != jsonCamelCase(field.GetName()) {
+			option = fmt.Sprintf(" [json_name = %s]", strconv.Quote(field.GetJsonName()))
+		}
+		p.line(indent+1, "%s%s %s = %d%s;", label, fieldType, field.GetName(), field.GetNumber(), option)
+	}
+	for _, nested := range message.NestedType {
+		p.line(0, "")
+		p.message(indent+1, nested)
+	}
+	p.line(indent, "}")
+}
+
+// typeName 完全修飾名から、同じパッケージの型はパッケージ名を省略して返却します
+func (p *printer) typeName(fullName string) string {
+	fullName = strings.TrimPrefix(fullName, ".")
+	return strings.TrimPrefix(fullName, p.pkg+".")
+}
+
+// jsonCamelCase protocが項目名から作成するJSONの名前を返却します("user_id" → "userId")
+func jsonCamelCase(name string) string {
+	var b strings.Builder
+	upper := false
+	for _, r := range name {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper && 'a' <= r && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		upper = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
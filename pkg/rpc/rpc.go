@@ -0,0 +1,430 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	// protoの定義から参照するgoogle.protobuf.Empty、Struct、Value、ListValueを登録する
+	_ "google.golang.org/protobuf/types/known/emptypb"
+	_ "google.golang.org/protobuf/types/known/structpb"
+)
+
+// PackagePrefix 作成するprotoのパッケージ名の接頭辞
+const PackagePrefix = "apicreator"
+
+// maxDepth 入れ子のオブジェクトをメッセージにする深さの上限
+const maxDepth = 8
+
+const (
+	emptyType     = ".google.protobuf.Empty"
+	structType    = ".google.protobuf.Struct"
+	valueType     = ".google.protobuf.Value"
+	listValueType = ".google.protobuf.ListValue"
+
+	emptyFile  = "google/protobuf/empty.proto"
+	structFile = "google/protobuf/struct.proto"
+)
+
+var (
+	// invalidNameChars protoの名前に使用できない文字
+	invalidNameChars = regexp.MustCompile(`[^_0-9A-Za-z]`)
+	// pathParamPattern MethodのURLのパラメータ("{id}")
+	pathParamPattern = regexp.MustCompile(`\{(.+?)\}`)
+
+	// ErrModelNotDeclare "model not declare"
+	ErrModelNotDeclare = errors.New("model not declare")
+
+	// methodOrder RPCを定義する順序
+	methodOrder = map[string]int{"GET": 0, "POST": 1, "PUT": 2, "DELETE": 3}
+)
+
+// Procedure Methodに対応するRPC
+type Procedure struct {
+	Name string
+	// Path Connectで呼び出すパス("/<パッケージ名>.<サービス名>/<RPC名>")
+	Path   string
+	Method domain.Method
+	// Params MethodのURLのパラメータ名。リクエストのメッセージの同名の項目から値を設定します
+	Params []string
+	Input  protoreflect.MessageDescriptor
+	Output protoreflect.MessageDescriptor
+	// List trueの場合、ドキュメントの配列をレスポンスの"items"に設定します
+	List bool
+}
+
+// Service APIのModelとMethodから作成したprotoの定義
+type Service struct {
+	File       protoreflect.FileDescriptor
+	api        domain.API
+	file       *descriptorpb.FileDescriptorProto
+	comments   map[string]string
+	procedures []*Procedure
+}
+
+// NewService APIのModelとMethodからprotoの定義を作成します
+// ModelのJSON Schemaのpropertiesからメッセージを、Methodごとに1つのRPCを定義します
+func NewService(api domain.API, model domain.Model, methods []domain.Method) (*Service, error) {
+	if model.ID == "" {
+		return nil, ErrModelNotDeclare
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(model.Schema), &schema); err != nil {
+		return nil, err
+	}
+
+	pkg := PackageName(api)
+	b := &builder{
+		pkg:      pkg,
+		apiURL:   api.URL,
+		schema:   schema,
+		model:    toTypeName(model.Name),
+		file:     &descriptorpb.FileDescriptorProto{Name: proto.String(strings.Replace(pkg, ".", "/", -1) + ".proto"), Package: proto.String(pkg), Syntax: proto.String("proto3")},
+		names:    map[string]bool{},
+		rpcNames: map[string]bool{},
+		imports:  map[string]bool{},
+		comments: map[string]string{},
+	}
+	b.names[b.model] = true
+	b.file.MessageType = append(b.file.MessageType, b.message(b.model, pkg+"."+b.model, schema, 0))
+
+	service := &descriptorpb.ServiceDescriptorProto{Name: proto.String(b.model + "Service")}
+	sorted := append([]domain.Method{}, methods...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Type != sorted[j].Type {
+			return methodOrder[sorted[i].Type] < methodOrder[sorted[j].Type]
+		}
+		return sorted[i].URL < sorted[j].URL
+	})
+	procedures := []*Procedure{}
+	for _, method := range sorted {
+		if procedure, rpc := b.procedure(method); rpc != nil {
+			service.Method = append(service.Method, rpc)
+			procedures = append(procedures, procedure)
+		}
+	}
+	b.file.Service = append(b.file.Service, service)
+
+	for name := range b.imports {
+		b.file.Dependency = append(b.file.Dependency, name)
+	}
+	sort.Strings(b.file.Dependency)
+
+	file, err := protodesc.NewFile(b.file, protoregistry.GlobalFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	sd := file.Services().Get(0)
+	for _, procedure := range procedures {
+		md := sd.Methods().ByName(protoreflect.Name(procedure.Name))
+		procedure.Path = fmt.Sprintf("/%s/%s", sd.FullName(), md.Name())
+		procedure.Input = md.Input()
+		procedure.Output = md.Output()
+	}
+
+	return &Service{
+		File:       file,
+		api:        api,
+		file:       b.file,
+		comments:   b.comments,
+		procedures: procedures,
+	}, nil
+}
+
+// Procedures すべてのRPCを返却します
+func (s *Service) Procedures() []*Procedure {
+	return s.procedures
+}
+
+// Procedure Connectで呼び出すパスからRPCを返却します
+func (s *Service) Procedure(path string) (*Procedure, bool) {
+	for _, procedure := range s.procedures {
+		if procedure.Path == path {
+			return procedure, true
+		}
+	}
+	return nil, false
+}
+
+// FileName protoのファイル名を返却します
+func (s *Service) FileName() string {
+	return s.file.GetName()
+}
+
+// PackageName APIのURLから、protoのパッケージ名を作成します(例："v1/users" → "apicreator.v1.users")
+func PackageName(api domain.API) string {
+	segments := []string{PackagePrefix}
+	for _, segment := range strings.Split(api.URL, "/") {
+		if segment == "" {
+			continue
+		}
+		segments = append(segments, strings.ToLower(toFieldName(segment)))
+	}
+	return strings.Join(segments, ".")
+}
+
+type builder struct {
+	pkg      string
+	apiURL   string
+	schema   map[string]interface{}
+	model    string
+	file     *descriptorpb.FileDescriptorProto
+	names    map[string]bool
+	rpcNames map[string]bool
+	imports  map[string]bool
+	comments map[string]string
+}
+
+// procedure MethodからRPCを作成します。対応していないHTTPメソッドの場合はnilを返却します
+func (b *builder) procedure(method domain.Method) (*Procedure, *descriptorpb.MethodDescriptorProto) {
+	var verb string
+	switch method.Type {
+	case "GET":
+		verb = "Get"
+		if method.IsArray {
+			verb = "List"
+		}
+	case "POST":
+		verb = "Create"
+	case "PUT":
+		verb = "Update"
+	case "DELETE":
+		verb = "Delete"
+	default:
+		return nil, nil
+	}
+
+	params := []string{}
+	for _, match := range pathParamPattern.FindAllStringSubmatch(method.URL, -1) {
+		params = append(params, match[1])
+	}
+
+	// 名前が重複する場合は、パラメータ名を付けて区別する(例："GetUserByName")
+	name := verb + b.model + literalSuffix(method.URL)
+	if b.rpcNames[name] {
+		for _, param := range params {
+			name += "By" + toTypeName(param)
+		}
+	}
+	name = uniqueName(name, b.rpcNames, "")
+
+	procedure := &Procedure{Name: name, Method: method, Params: params}
+	rpc := &descriptorpb.MethodDescriptorProto{Name: proto.String(name)}
+	modelType := "." + b.pkg + "." + b.model
+
+	switch method.Type {
+	case "POST", "PUT":
+		rpc.InputType = proto.String(modelType)
+	default:
+		rpc.InputType = proto.String(b.requestMessage(name, params))
+	}
+
+	switch {
+	case method.Type == "DELETE":
+		b.imports[emptyFile] = true
+		rpc.OutputType = proto.String(emptyType)
+	case method.Type == "GET" && method.IsArray:
+		procedure.List = true
+		rpc.OutputType = proto.String(b.listMessage(name, modelType))
+	default:
+		rpc.OutputType = proto.String(modelType)
+	}
+
+	comment := fmt.Sprintf("%s /%s%s", method.Type, b.apiURL, method.URL)
+	if method.Description != "" {
+		comment += "\n" + method.Description
+	}
+	b.comments[name] = comment
+
+	return procedure, rpc
+}
+
+// requestMessage URLのパラメータを項目とする"<RPC名>Request"を作成します
+func (b *builder) requestMessage(rpcName string, params []string) string {
+	name := uniqueName(rpcName+"Request", b.names, "")
+	message := &descriptorpb.DescriptorProto{Name: proto.String(name)}
+	properties, _ := b.schema["properties"].(map[string]interface{})
+	fieldNames := map[string]bool{}
+	for i, param := range params {
+		property, _ := properties[param].(map[string]interface{})
+		field := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(uniqueName(toFieldName(param), fieldNames, "_")),
+			JsonName: proto.String(param),
+			Number:   proto.Int32(int32(i + 1)),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     scalarType(property).Enum(),
+		}
+		message.Field = append(message.Field, field)
+	}
+	b.file.MessageType = append(b.file.MessageType, message)
+	return "." + b.pkg + "." + name
+}
+
+// listMessage ドキュメントの配列を"items"に持つ"<RPC名>Response"を作成します
+func (b *builder) listMessage(rpcName string, itemType string) string {
+	name := uniqueName(rpcName+"Response", b.names, "")
+	b.file.MessageType = append(b.file.MessageType, &descriptorpb.DescriptorProto{
+		Name: proto.String(name),
+		Field: []*descriptorpb.FieldDescriptorProto{{
+			Name:     proto.String("items"),
+			JsonName: proto.String("items"),
+			Number:   proto.Int32(1),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+			TypeName: proto.String(itemType),
+		}},
+	})
+	return "." + b.pkg + "." + name
+}
+
+// message JSON Schemaのpropertiesからメッセージを作成します
+// 項目番号はプロパティ名の順に1から割り当てるため、プロパティを追加、削除すると番号が変わります
+func (b *builder) message(name string, fullName string, schema map[string]interface{}, depth int) *descriptorpb.DescriptorProto {
+	message := &descriptorpb.DescriptorProto{Name: proto.String(name)}
+	properties, _ := schema["properties"].(map[string]interface{})
+	fieldNames := map[string]bool{}
+	nestedNames := map[string]bool{}
+
+	for i, propertyName := range sortedKeys(properties) {
+		property, _ := properties[propertyName].(map[string]interface{})
+		fieldName := uniqueName(toFieldName(propertyName), fieldNames, "_")
+		field := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(fieldName),
+			JsonName: proto.String(propertyName),
+			Number:   proto.Int32(int32(i + 1)),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		}
+		b.setFieldType(message, fullName, nestedNames, propertyName, field, property, depth)
+
+		// 未指定と0、空文字、falseを区別するため、スカラー型はoptionalにする
+		if field.GetLabel() != descriptorpb.FieldDescriptorProto_LABEL_REPEATED && field.GetType() != descriptorpb.FieldDescriptorProto_TYPE_MESSAGE {
+			field.Proto3Optional = proto.Bool(true)
+			field.OneofIndex = proto.Int32(int32(len(message.OneofDecl)))
+			message.OneofDecl = append(message.OneofDecl, &descriptorpb.OneofDescriptorProto{Name: proto.String("_" + fieldName)})
+		}
+		message.Field = append(message.Field, field)
+	}
+	return message
+}
+
+// setFieldType JSON Schemaのtypeから項目の型を設定します
+// 入れ子のオブジェクトは入れ子のメッセージ、型を特定できない値はgoogle.protobuf.Value等になります
+func (b *builder) setFieldType(parent *descriptorpb.DescriptorProto, parentName string, nestedNames map[string]bool, propertyName string, field *descriptorpb.FieldDescriptorProto, property map[string]interface{}, depth int) {
+	setMessage := func(typeName string) {
+		field.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+		field.TypeName = proto.String(typeName)
+	}
+
+	switch propertyType(property) {
+	case "string", "integer", "number", "boolean":
+		field.Type = scalarType(property).Enum()
+	case "object":
+		properties, _ := property["properties"].(map[string]interface{})
+		if len(properties) == 0 || depth >= maxDepth {
+			b.imports[structFile] = true
+			setMessage(structType)
+			return
+		}
+		name := uniqueName(toTypeName(propertyName), nestedNames, "")
+		parent.NestedType = append(parent.NestedType, b.message(name, parentName+"."+name, property, depth+1))
+		setMessage("." + parentName + "." + name)
+	case "array":
+		items, _ := property["items"].(map[string]interface{})
+		if itemType := propertyType(items); itemType == "" || itemType == "array" {
+			b.imports[structFile] = true
+			setMessage(listValueType)
+			return
+		}
+		b.setFieldType(parent, parentName, nestedNames, propertyName, field, items, depth)
+		field.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	default:
+		b.imports[structFile] = true
+		setMessage(valueType)
+	}
+}
+
+// propertyType JSON Schemaのtypeを返却します。複数の型が指定されている場合は空文字を返却します
+func propertyType(property map[string]interface{}) string {
+	t, _ := property["type"].(string)
+	switch t {
+	case "string", "integer", "number", "boolean", "object", "array":
+		return t
+	default:
+		return ""
+	}
+}
+
+// scalarType JSON Schemaのtypeに対応するスカラー型を返却します(不明な場合はstring)
+func scalarType(property map[string]interface{}) descriptorpb.FieldDescriptorProto_Type {
+	switch propertyType(property) {
+	case "integer":
+		return descriptorpb.FieldDescriptorProto_TYPE_INT64
+	case "number":
+		return descriptorpb.FieldDescriptorProto_TYPE_DOUBLE
+	case "boolean":
+		return descriptorpb.FieldDescriptorProto_TYPE_BOOL
+	default:
+		return descriptorpb.FieldDescriptorProto_TYPE_STRING
+	}
+}
+
+// literalSuffix MethodのURLのパラメータ以外の部分を、RPC名の接尾辞にします(例："/search/{name}" → "Search")
+func literalSuffix(methodURL string) string {
+	suffix := ""
+	for _, segment := range strings.Split(pathParamPattern.ReplaceAllString(methodURL, ""), "/") {
+		if segment == "" {
+			continue
+		}
+		suffix += toTypeName(segment)
+	}
+	return suffix
+}
+
+// uniqueName 使用済みの名前と重複しないように、連番を付けた名前を返却します
+func uniqueName(name string, used map[string]bool, separator string) string {
+	unique := name
+	for i := 2; used[unique]; i++ {
+		unique = fmt.Sprintf("%s%s%d", name, separator, i)
+	}
+	used[unique] = true
+	return unique
+}
+
+// toTypeName 名前をprotoのメッセージ名(先頭が大文字)に変換します
+func toTypeName(name string) string {
+	name = toFieldName(name)
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// toFieldName 名前をprotoの項目名に変換します
+func toFieldName(name string) string {
+	name = invalidNameChars.ReplaceAllString(name, "_")
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "_" + name
+	}
+	return name
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
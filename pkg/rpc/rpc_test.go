@@ -0,0 +1,160 @@
+package rpc_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/rpc"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testAPI = domain.API{ID: "api", Name: "ユーザー", URL: "v1/users", Description: "ユーザーを管理します"}
+
+var testModel = domain.Model{
+	ID:   "model",
+	Name: "User",
+	Schema: `{
+		"type": "object",
+		"keys": ["id"],
+		"properties": {
+			"id": {"type": "string"},
+			"name": {"type": "string"},
+			"age": {"type": "integer"},
+			"score": {"type": "number"},
+			"active": {"type": "boolean"},
+			"profile": {"type": "object", "properties": {"bio": {"type": "string"}}},
+			"meta": {"type": "object"},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"user-code": {"type": "string"}
+		}
+	}`,
+}
+
+var testMethods = []domain.Method{
+	{ID: "1", Type: "DELETE", URL: "/{id}"},
+	{ID: "2", Type: "GET", URL: "", IsArray: true},
+	{ID: "3", Type: "GET", URL: "/{id}", Description: "IDで取得します"},
+	{ID: "4", Type: "POST", URL: ""},
+	{ID: "5", Type: "PUT", URL: ""},
+	{ID: "6", Type: "GET", URL: "/search/{name}", IsArray: true},
+}
+
+func TestNewService(t *testing.T) {
+	t.Run("正常系", func(t *testing.T) {
+		service, err := rpc.NewService(testAPI, testModel, testMethods)
+		assert.NoError(t, err)
+		assert.Equal(t, "apicreator/v1/users.proto", service.FileName())
+
+		paths := []string{}
+		for _, procedure := range service.Procedures() {
+			paths = append(paths, procedure.Path)
+		}
+		assert.Equal(t, []string{
+			"/apicreator.v1.users.UserService/ListUser",
+			"/apicreator.v1.users.UserService/ListUserSearch",
+			"/apicreator.v1.users.UserService/GetUser",
+			"/apicreator.v1.users.UserService/CreateUser",
+			"/apicreator.v1.users.UserService/UpdateUser",
+			"/apicreator.v1.users.UserService/DeleteUser",
+		}, paths)
+
+		procedure, ok := service.Procedure("/apicreator.v1.users.UserService/ListUserSearch")
+		assert.True(t, ok)
+		assert.Equal(t, "6", procedure.Method.ID)
+		assert.Equal(t, []string{"name"}, procedure.Params)
+		assert.True(t, procedure.List)
+		assert.Equal(t, "apicreator.v1.users.ListUserSearchRequest", string(procedure.Input.FullName()))
+
+		_, ok = service.Procedure("/apicreator.v1.users.UserService/Unknown")
+		assert.False(t, ok)
+	})
+	t.Run("Modelが未定義", func(t *testing.T) {
+		_, err := rpc.NewService(testAPI, domain.Model{}, testMethods)
+		assert.Equal(t, rpc.ErrModelNotDeclare, err)
+	})
+	t.Run("RPC名の重複", func(t *testing.T) {
+		methods := []domain.Method{
+			{ID: "1", Type: "GET", URL: "/{id}"},
+			{ID: "2", Type: "GET", URL: "/{name}"},
+		}
+		service, err := rpc.NewService(testAPI, testModel, methods)
+		assert.NoError(t, err)
+		assert.Equal(t, "GetUser", service.Procedures()[0].Name)
+		assert.Equal(t, "GetUserByName", service.Procedures()[1].Name)
+	})
+}
+
+func TestProto(t *testing.T) {
+	service, err := rpc.NewService(testAPI, testModel, testMethods)
+	assert.NoError(t, err)
+
+	proto := service.Proto()
+	for _, expected := range []string{
+		"// ユーザー\n// ユーザーを管理します\n\nsyntax = \"proto3\";\n\npackage apicreator.v1.users;\n",
+		"import \"google/protobuf/empty.proto\";\nimport \"google/protobuf/struct.proto\";\n",
+		"  // GET /v1/users/{id}\n  // IDで取得します\n  rpc GetUser(GetUserRequest) returns (User);\n",
+		"  rpc ListUser(ListUserRequest) returns (ListUserResponse);\n",
+		"  rpc DeleteUser(DeleteUserRequest) returns (google.protobuf.Empty);\n",
+		"  optional int64 age = 2;\n",
+		"  google.protobuf.Struct meta = 4;\n",
+		"  User.Profile profile = 6;\n",
+		"  repeated string tags = 8;\n",
+		"  optional string user_code = 9 [json_name = \"user-code\"];\n",
+		"  message Profile {\n    optional string bio = 1;\n  }\n",
+		"message ListUserResponse {\n  repeated User items = 1;\n}\n",
+		"message GetUserRequest {\n  string id = 1;\n}\n",
+	} {
+		assert.True(t, strings.Contains(proto, expected), expected)
+	}
+}
+
+func TestCodec(t *testing.T) {
+	service, err := rpc.NewService(testAPI, testModel, testMethods)
+	assert.NoError(t, err)
+	procedure, _ := service.Procedure("/apicreator.v1.users.UserService/CreateUser")
+
+	document := map[string]interface{}{
+		"_id":       "5f0000000000000000000000",
+		"id":        "1",
+		"age":       float64(0),
+		"active":    false,
+		"profile":   map[string]interface{}{"bio": "hello"},
+		"meta":      map[string]interface{}{"any": []interface{}{float64(1), "a"}},
+		"tags":      []interface{}{"a", "b"},
+		"user-code": "A-1",
+	}
+
+	for _, codec := range []string{rpc.CodecProto, rpc.CodecJSON} {
+		message, err := rpc.FromDocument(document, procedure.Output)
+		assert.NoError(t, err)
+		b, err := rpc.Marshal(codec, message)
+		assert.NoError(t, err)
+
+		message, err = rpc.Unmarshal(codec, b, procedure.Input)
+		assert.NoError(t, err)
+		actual, err := rpc.ToDocument(message)
+		assert.NoError(t, err)
+
+		// 定義されていない"_id"は除かれ、0やfalseは値として残る
+		assert.Equal(t, map[string]interface{}{
+			"id":        "1",
+			"age":       int64(0),
+			"active":    false,
+			"profile":   map[string]interface{}{"bio": "hello"},
+			"meta":      map[string]interface{}{"any": []interface{}{float64(1), "a"}},
+			"tags":      []interface{}{"a", "b"},
+			"user-code": "A-1",
+		}, actual, codec)
+	}
+
+	t.Run("型が異なる", func(t *testing.T) {
+		_, err := rpc.FromDocument(map[string]interface{}{"age": "20"}, procedure.Output)
+		assert.Error(t, err)
+	})
+	t.Run("不正なJSON", func(t *testing.T) {
+		_, err := rpc.Unmarshal(rpc.CodecJSON, []byte(`{"unknown": 1}`), procedure.Input)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,499 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+)
+
+const (
+	// LanguageGo Goのパッケージ
+	LanguageGo = "go"
+	// LanguageTypeScript TypeScriptのモジュール
+	LanguageTypeScript = "typescript"
+)
+
+// maxDepth 入れ子のオブジェクトを型にする深さの上限
+const maxDepth = 8
+
+var (
+	// ErrUnsupportedLanguage "unsupported language"
+	ErrUnsupportedLanguage = errors.New("unsupported language")
+	// ErrModelNotDeclare "model not declare"
+	ErrModelNotDeclare = errors.New("model not declare")
+
+	// nameSeparator 名前を単語に区切る文字
+	nameSeparator = regexp.MustCompile(`[^0-9A-Za-z]+`)
+	// pathParamPattern MethodのURLのパラメータ("{id}")
+	pathParamPattern = regexp.MustCompile(`\{(.+?)\}`)
+	// tsIdentifier TypeScriptでクォートせずに使用できるプロパティ名
+	tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][0-9A-Za-z_$]*$`)
+
+	// initialisms Goの名前で大文字にする略語
+	initialisms = map[string]bool{"id": true, "url": true, "uri": true, "api": true, "http": true, "json": true, "uuid": true, "ip": true}
+	// reservedNames クライアントの共通部分で使用している名前
+	reservedNames = []string{"Client", "NewClient", "Error", "ErrorResponse", "ApiError", "ClientOptions"}
+	// methodOrder 関数を定義する順序
+	methodOrder = map[string]int{"GET": 0, "POST": 1, "PUT": 2, "DELETE": 3}
+)
+
+// Definition SDKを作成するAPIの定義
+type Definition struct {
+	API     domain.API
+	Model   domain.Model
+	Methods []domain.Method
+}
+
+// Generate APIの定義からSDKのファイルを作成します
+// Goはpackageディレクトリ以下のファイル、TypeScriptは1つのモジュールを作成します
+func Generate(language string, packageName string, definitions []Definition) ([]domain.GeneratedFile, error) {
+	p, err := newProject(packageName, definitions)
+	if err != nil {
+		return nil, err
+	}
+
+	switch language {
+	case LanguageGo:
+		files := []domain.GeneratedFile{}
+		client, err := render(goClientTemplate, p, true)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, domain.GeneratedFile{Name: path.Join(p.Package, "client.go"), Content: client})
+		for _, a := range p.APIs {
+			content, err := render(goAPITemplate, struct {
+				Package string
+				*api
+			}{p.Package, a}, true)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, domain.GeneratedFile{Name: path.Join(p.Package, a.FileName+".go"), Content: content})
+		}
+		return files, nil
+
+	case LanguageTypeScript:
+		content, err := render(tsTemplate, p, false)
+		if err != nil {
+			return nil, err
+		}
+		return []domain.GeneratedFile{{Name: p.Package + ".ts", Content: content}}, nil
+
+	default:
+		return nil, ErrUnsupportedLanguage
+	}
+}
+
+// render テンプレートを出力します。Goの場合はgofmtで整形します
+func render(t *template.Template, data interface{}, gofmt bool) ([]byte, error) {
+	var b bytes.Buffer
+	if err := t.Execute(&b, data); err != nil {
+		return nil, err
+	}
+	if !gofmt {
+		return b.Bytes(), nil
+	}
+	return format.Source(b.Bytes())
+}
+
+// project テンプレートに渡すSDK全体の定義
+type project struct {
+	Package string
+	APIs    []*api
+}
+
+type api struct {
+	Name        string
+	Description string
+	FileName    string
+	Types       []*typeDef
+	Operations  []*operation
+	// HasParams URLのパラメータを持つ関数があるか(Goのimportの判定に使用します)
+	HasParams bool
+}
+
+type typeDef struct {
+	Name        string
+	Description string
+	Fields      []*field
+}
+
+type field struct {
+	GoName      string
+	TSName      string
+	JSONName    string
+	GoType      string
+	TSType      string
+	Required    bool
+	Description string
+}
+
+type operation struct {
+	GoName string
+	TSName string
+	// Comment "GET /users/{id}"とMethodの説明
+	Comment    string
+	HTTPMethod string
+	GoParams   string
+	TSParams   string
+	GoPath     string
+	TSPath     string
+	// Body リクエストBodyの型名(Bodyがない場合は空文字)
+	Body string
+	// Result レスポンスの型名(レスポンスがない場合は空文字)
+	Result string
+	List   bool
+}
+
+type builder struct {
+	typeNames map[string]bool
+	funcNames map[string]bool
+	fileNames map[string]bool
+	api       *api
+}
+
+// newProject APIの定義から、テンプレートに渡す型と関数の定義を作成します
+func newProject(packageName string, definitions []Definition) (*project, error) {
+	b := &builder{
+		typeNames: map[string]bool{},
+		funcNames: map[string]bool{},
+		fileNames: map[string]bool{"client": true},
+	}
+	for _, name := range reservedNames {
+		b.typeNames[name] = true
+		b.funcNames[name] = true
+	}
+
+	p := &project{Package: PackageName(packageName)}
+	for _, definition := range definitions {
+		a, err := b.addAPI(definition)
+		if err != nil {
+			return nil, fmt.Errorf("api %s: %s", definition.API.Name, err)
+		}
+		p.APIs = append(p.APIs, a)
+	}
+	return p, nil
+}
+
+func (b *builder) addAPI(definition Definition) (*api, error) {
+	if definition.Model.ID == "" {
+		return nil, ErrModelNotDeclare
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(definition.Model.Schema), &schema); err != nil {
+		return nil, err
+	}
+
+	b.api = &api{
+		Name:        definition.API.Name,
+		Description: definition.API.Description,
+		FileName:    uniqueName(strings.ToLower(toExportedName(definition.API.URL)), b.fileNames, "_"),
+	}
+	modelName := b.addType(toExportedName(definition.Model.Name), definition.Model.Description, schema, 0)
+
+	methods := append([]domain.Method{}, definition.Methods...)
+	sort.SliceStable(methods, func(i, j int) bool {
+		if methods[i].Type != methods[j].Type {
+			return methodOrder[methods[i].Type] < methodOrder[methods[j].Type]
+		}
+		return methods[i].URL < methods[j].URL
+	})
+	for _, method := range methods {
+		if o := b.operation(definition.API, method, modelName, schema); o != nil {
+			b.api.Operations = append(b.api.Operations, o)
+		}
+	}
+	return b.api, nil
+}
+
+// addType JSON Schemaのpropertiesから型を追加し、型名を返却します
+func (b *builder) addType(name string, typeDescription string, schema map[string]interface{}, depth int) string {
+	t := &typeDef{Name: uniqueName(name, b.typeNames, ""), Description: typeDescription}
+	b.api.Types = append(b.api.Types, t)
+
+	required := map[string]bool{}
+	if list, ok := schema["required"].([]interface{}); ok {
+		for _, r := range list {
+			if s, ok := r.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	fieldNames := map[string]bool{}
+	for _, propertyName := range sortedKeys(properties) {
+		property, _ := properties[propertyName].(map[string]interface{})
+		goType, tsType := b.fieldType(t.Name+toExportedName(propertyName), property, depth)
+		f := &field{
+			GoName:      uniqueName(toExportedName(propertyName), fieldNames, "_"),
+			TSName:      propertyName,
+			JSONName:    propertyName,
+			GoType:      goType,
+			TSType:      tsType,
+			Required:    required[propertyName],
+			Description: description(property),
+		}
+		if !tsIdentifier.MatchString(f.TSName) {
+			f.TSName = strconv.Quote(f.TSName)
+		}
+		// 必須でない項目は、未指定とゼロ値を区別するためポインタにする
+		if !f.Required && !strings.HasPrefix(goType, "[]") && !strings.HasPrefix(goType, "map[") && goType != "interface{}" {
+			f.GoType = "*" + goType
+		}
+		t.Fields = append(t.Fields, f)
+	}
+	return t.Name
+}
+
+// fieldType JSON Schemaのtypeから、GoとTypeScriptの型を返却します
+func (b *builder) fieldType(typeName string, property map[string]interface{}, depth int) (string, string) {
+	propertyType, _ := property["type"].(string)
+	switch propertyType {
+	case "string":
+		return "string", "string"
+	case "integer":
+		return "int64", "number"
+	case "number":
+		return "float64", "number"
+	case "boolean":
+		return "bool", "boolean"
+	case "object":
+		properties, _ := property["properties"].(map[string]interface{})
+		if len(properties) == 0 || depth >= maxDepth {
+			return "map[string]interface{}", "Record<string, unknown>"
+		}
+		name := b.addType(typeName, description(property), property, depth+1)
+		return name, name
+	case "array":
+		items, ok := property["items"].(map[string]interface{})
+		if !ok {
+			return "[]interface{}", "unknown[]"
+		}
+		goType, tsType := b.fieldType(typeName+"Item", items, depth)
+		if strings.ContainsAny(tsType, " <") {
+			tsType = "Array<" + tsType + ">"
+		} else {
+			tsType += "[]"
+		}
+		return "[]" + goType, tsType
+	default:
+		return "interface{}", "unknown"
+	}
+}
+
+// operation Methodから関数を作成します。対応していないHTTPメソッドの場合はnilを返却します
+func (b *builder) operation(a domain.API, method domain.Method, modelName string, schema map[string]interface{}) *operation {
+	var verb string
+	switch method.Type {
+	case "GET":
+		verb = "Get"
+		if method.IsArray {
+			verb = "List"
+		}
+	case "POST":
+		verb = "Create"
+	case "PUT":
+		verb = "Update"
+	case "DELETE":
+		verb = "Delete"
+	default:
+		return nil
+	}
+
+	o := &operation{HTTPMethod: method.Type}
+
+	// URLのパラメータを関数の引数にする
+	properties, _ := schema["properties"].(map[string]interface{})
+	paramNames := map[string]bool{"ctx": true, "c": true, "body": true}
+	idents := map[string]string{}
+	goParams, tsParams := []string{}, []string{}
+	suffix := ""
+	for _, match := range pathParamPattern.FindAllStringSubmatch(method.URL, -1) {
+		param := match[1]
+		ident := uniqueName(toParamName(param), paramNames, "")
+		idents[param] = ident
+		property, _ := properties[param].(map[string]interface{})
+		goType, tsType := "string", "string"
+		switch property["type"] {
+		case "integer":
+			goType, tsType = "int64", "number"
+		case "number":
+			goType, tsType = "float64", "number"
+		}
+		goParams = append(goParams, ident+" "+goType)
+		tsParams = append(tsParams, ident+": "+tsType)
+		suffix += "By" + toExportedName(param)
+	}
+	if len(idents) > 0 {
+		b.api.HasParams = true
+	}
+
+	switch method.Type {
+	case "POST", "PUT":
+		o.Body = modelName
+		goParams = append(goParams, "body *"+modelName)
+		tsParams = append(tsParams, "body: "+modelName)
+	}
+	switch {
+	case method.Type == "DELETE":
+	case method.Type == "GET" && method.IsArray:
+		o.Result, o.List = modelName, true
+	default:
+		o.Result = modelName
+	}
+	o.GoParams = strings.Join(goParams, ", ")
+	o.TSParams = strings.Join(tsParams, ", ")
+
+	// 名前が重複する場合は、パラメータ名を付けて区別する(例："GetUserByName")
+	name := verb + modelName + literalSuffix(method.URL)
+	if b.funcNames[name] {
+		name += suffix
+	}
+	o.GoName = uniqueName(name, b.funcNames, "")
+	o.TSName = lowerFirst(o.GoName)
+
+	fullPath := "/" + strings.Trim(a.URL, "/") + method.URL
+	o.GoPath, o.TSPath = paths(fullPath, idents)
+	o.Comment = fmt.Sprintf("%s %s", method.Type, fullPath)
+	if method.Description != "" {
+		o.Comment += "\n" + method.Description
+	}
+	return o
+}
+
+// paths URLをGoの式とTypeScriptのテンプレートリテラルに変換します。パラメータはエスケープして埋め込みます
+func paths(fullPath string, idents map[string]string) (string, string) {
+	goParts := []string{}
+	ts := "`"
+	last := 0
+	for _, match := range pathParamPattern.FindAllStringSubmatchIndex(fullPath, -1) {
+		literal := fullPath[last:match[0]]
+		ident := idents[fullPath[match[2]:match[3]]]
+		if literal != "" {
+			goParts = append(goParts, strconv.Quote(literal))
+		}
+		goParts = append(goParts, fmt.Sprintf("url.PathEscape(fmt.Sprint(%s))", ident))
+		ts += tsLiteral(literal) + fmt.Sprintf("${encodeURIComponent(String(%s))}", ident)
+		last = match[1]
+	}
+	if literal := fullPath[last:]; literal != "" || len(goParts) == 0 {
+		goParts = append(goParts, strconv.Quote(literal))
+		ts += tsLiteral(literal)
+	}
+	return strings.Join(goParts, " + "), ts + "`"
+}
+
+// tsLiteral テンプレートリテラルで特別な意味を持つ文字をエスケープします
+func tsLiteral(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "`", "\\`", "${", "\\${").Replace(s)
+}
+
+// literalSuffix MethodのURLのパラメータ以外の部分を、関数名の接尾辞にします(例："/search/{name}" → "Search")
+func literalSuffix(methodURL string) string {
+	literal := pathParamPattern.ReplaceAllString(methodURL, "")
+	if nameSeparator.ReplaceAllString(literal, "") == "" {
+		return ""
+	}
+	return toExportedName(literal)
+}
+
+// toExportedName 名前をGoの公開された名前に変換します(例："user_id" → "UserID")
+func toExportedName(name string) string {
+	var b strings.Builder
+	for _, word := range nameSeparator.Split(name, -1) {
+		if word == "" {
+			continue
+		}
+		if initialisms[strings.ToLower(word)] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	s := b.String()
+	if s == "" || unicode.IsDigit(rune(s[0])) {
+		s = "X" + s
+	}
+	return s
+}
+
+// toParamName 名前を関数の引数名に変換します(例："ID" → "id"、"UserID" → "userID")
+func toParamName(name string) string {
+	exported := toExportedName(name)
+	if strings.ToUpper(exported) == exported {
+		exported = strings.ToLower(exported)
+	} else {
+		exported = lowerFirst(exported)
+	}
+	if token.IsKeyword(exported) || isTSReserved(exported) {
+		exported += "Param"
+	}
+	return exported
+}
+
+// isTSReserved TypeScriptで引数名に使用できない予約語か判定します
+func isTSReserved(name string) bool {
+	switch name {
+	case "delete", "new", "this", "typeof", "void", "with", "class", "enum", "export", "extends", "function", "in", "instanceof", "let", "super", "throw", "try", "catch", "while", "yield", "await", "null", "true", "false":
+		return true
+	}
+	return false
+}
+
+// PackageName 名前をGoのパッケージ名、TypeScriptのファイル名に変換します
+func PackageName(name string) string {
+	name = strings.ToLower(nameSeparator.ReplaceAllString(name, ""))
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "api" + name
+	}
+	if token.IsKeyword(name) {
+		name += "api"
+	}
+	return name
+}
+
+func lowerFirst(name string) string {
+	runes := []rune(name)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+// uniqueName 使用済みの名前と重複しないように、連番を付けた名前を返却します
+func uniqueName(name string, used map[string]bool, separator string) string {
+	unique := name
+	for i := 2; used[unique]; i++ {
+		unique = fmt.Sprintf("%s%s%d", name, separator, i)
+	}
+	used[unique] = true
+	return unique
+}
+
+// description JSON Schemaのdescriptionを返却します
+func description(property map[string]interface{}) string {
+	d, _ := property["description"].(string)
+	return d
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,167 @@
+package sdk_test
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+	"github.com/Hajime3778/api-creator-backend/pkg/sdk"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testDefinition = sdk.Definition{
+	API: domain.API{ID: "api", Name: "ユーザー", URL: "v1/users", Description: "ユーザーを管理します"},
+	Model: domain.Model{
+		ID:          "model",
+		Name:        "user",
+		Description: "ユーザー",
+		Schema: `{
+			"type": "object",
+			"keys": ["id"],
+			"required": ["id", "name"],
+			"properties": {
+				"id": {"type": "integer", "description": "ユーザーID"},
+				"name": {"type": "string"},
+				"score": {"type": "number"},
+				"active": {"type": "boolean"},
+				"profile": {"type": "object", "properties": {"bio": {"type": "string"}}},
+				"meta": {"type": "object"},
+				"tags": {"type": "array", "items": {"type": "string"}},
+				"histories": {"type": "array", "items": {"type": "object", "properties": {"at": {"type": "string"}}}},
+				"user-code": {"type": "string"}
+			}
+		}`,
+	},
+	Methods: []domain.Method{
+		{ID: "1", Type: "DELETE", URL: "/{id}"},
+		{ID: "2", Type: "GET", URL: "", IsArray: true},
+		{ID: "3", Type: "GET", URL: "/{id}", Description: "IDで取得します"},
+		{ID: "4", Type: "POST", URL: ""},
+		{ID: "5", Type: "PUT", URL: ""},
+		{ID: "6", Type: "GET", URL: "/search/{name}", IsArray: true},
+	},
+}
+
+// typeCheck 作成されたGoのパッケージをコンパイルできるか検証します
+func typeCheck(t *testing.T, files []domain.GeneratedFile) *types.Package {
+	fset := token.NewFileSet()
+	parsed := []*ast.File{}
+	for _, file := range files {
+		f, err := parser.ParseFile(fset, file.Name, file.Content, parser.ParseComments)
+		assert.NoError(t, err)
+		parsed = append(parsed, f)
+	}
+	config := types.Config{Importer: importer.Default()}
+	pkg, err := config.Check(files[0].Name, fset, parsed, nil)
+	assert.NoError(t, err)
+	return pkg
+}
+
+func TestGenerateGo(t *testing.T) {
+	files, err := sdk.Generate(sdk.LanguageGo, "users", []sdk.Definition{testDefinition})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(files))
+	assert.Equal(t, "users/client.go", files[0].Name)
+	assert.Equal(t, "users/v1users.go", files[1].Name)
+
+	pkg := typeCheck(t, files)
+	assert.Equal(t, "users", pkg.Name())
+
+	// Modelの型(必須でない項目はポインタ)
+	user := pkg.Scope().Lookup("User").Type().Underlying().(*types.Struct)
+	fields := map[string]string{}
+	for i := 0; i < user.NumFields(); i++ {
+		fields[user.Field(i).Name()] = user.Field(i).Type().String() + " " + user.Tag(i)
+	}
+	assert.Equal(t, map[string]string{
+		"Active":    `*bool json:"active,omitempty"`,
+		"Histories": `[]users/client.go.UserHistoriesItem json:"histories,omitempty"`,
+		"ID":        `int64 json:"id"`,
+		"Meta":      `map[string]interface{} json:"meta,omitempty"`,
+		"Name":      `string json:"name"`,
+		"Profile":   `*users/client.go.UserProfile json:"profile,omitempty"`,
+		"Score":     `*float64 json:"score,omitempty"`,
+		"Tags":      `[]string json:"tags,omitempty"`,
+		"UserCode":  `*string json:"user-code,omitempty"`,
+	}, fields)
+
+	// Methodごとの関数
+	client := types.NewPointer(pkg.Scope().Lookup("Client").Type())
+	signatures := map[string]string{}
+	methods := types.NewMethodSet(client)
+	for i := 0; i < methods.Len(); i++ {
+		f := methods.At(i).Obj()
+		if f.Exported() {
+			signatures[f.Name()] = types.TypeString(f.Type(), types.RelativeTo(pkg))
+		}
+	}
+	assert.Equal(t, map[string]string{
+		"ListUser":       "func(ctx context.Context) ([]User, error)",
+		"ListUserSearch": "func(ctx context.Context, name string) ([]User, error)",
+		"GetUser":        "func(ctx context.Context, id int64) (*User, error)",
+		"CreateUser":     "func(ctx context.Context, body *User) (*User, error)",
+		"UpdateUser":     "func(ctx context.Context, body *User) (*User, error)",
+		"DeleteUser":     "func(ctx context.Context, id int64) error",
+	}, signatures)
+
+	assert.Contains(t, string(files[1].Content), `"/v1/users/"+url.PathEscape(fmt.Sprint(id))`)
+	assert.Contains(t, string(files[1].Content), "// GetUser GET /v1/users/{id}\n// IDで取得します\n")
+}
+
+func TestGenerateTypeScript(t *testing.T) {
+	files, err := sdk.Generate(sdk.LanguageTypeScript, "users", []sdk.Definition{testDefinition})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(files))
+	assert.Equal(t, "users.ts", files[0].Name)
+
+	module := string(files[0].Content)
+	for _, expected := range []string{
+		"export interface ErrorResponse {\n  error: string;\n}\n",
+		"export class ApiError extends Error {",
+		"  /**\n   * ユーザーID\n   */\n  id: number;\n",
+		"  name: string;\n",
+		"  histories?: UserHistoriesItem[];\n",
+		"  meta?: Record<string, unknown>;\n",
+		"  \"user-code\"?: string;\n",
+		"export interface UserProfile {\n  bio?: string;\n}\n",
+		"  async getUser(id: number): Promise<User> {\n    return this.request<User>(\"GET\", `/v1/users/${encodeURIComponent(String(id))}`);\n  }\n",
+		"  async listUser(): Promise<User[]> {",
+		"  async createUser(body: User): Promise<User> {\n    return this.request<User>(\"POST\", `/v1/users`, body);\n  }\n",
+		"  async deleteUser(id: number): Promise<void> {\n    await this.request<void>(\"DELETE\", `/v1/users/${encodeURIComponent(String(id))}`);\n  }\n",
+		"throw new ApiError(res.status, message);",
+	} {
+		assert.True(t, strings.Contains(module, expected), expected)
+	}
+}
+
+func TestGenerateProject(t *testing.T) {
+	// 別のAPIで同じ名前のModel、同じURLのMethodがある場合
+	other := sdk.Definition{
+		API:     domain.API{ID: "other", Name: "other", URL: "v2/users"},
+		Model:   domain.Model{ID: "other-model", Name: "User", Schema: `{"type": "object", "keys": ["id"], "properties": {"id": {"type": "string"}}}`},
+		Methods: []domain.Method{{ID: "7", Type: "GET", URL: "/{id}"}},
+	}
+
+	files, err := sdk.Generate(sdk.LanguageGo, "api-creator", []sdk.Definition{testDefinition, other})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"apicreator/client.go", "apicreator/v1users.go", "apicreator/v2users.go"}, []string{files[0].Name, files[1].Name, files[2].Name})
+
+	pkg := typeCheck(t, files)
+	assert.NotNil(t, pkg.Scope().Lookup("User2"))
+	getUser2, _, _ := types.LookupFieldOrMethod(pkg.Scope().Lookup("Client").Type(), true, pkg, "GetUser2")
+	assert.NotNil(t, getUser2)
+}
+
+func TestGenerateError(t *testing.T) {
+	_, err := sdk.Generate("java", "users", []sdk.Definition{testDefinition})
+	assert.Equal(t, sdk.ErrUnsupportedLanguage, err)
+
+	_, err = sdk.Generate(sdk.LanguageGo, "users", []sdk.Definition{{API: domain.API{Name: "users"}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,244 @@
+package sdk
+
+import (
+	"strings"
+	"text/template"
+)
+
+var funcs = template.FuncMap{
+	// comment 行ごとに"// "を付けたコメントを返却します
+	"comment": func(indent string, text string) string {
+		lines := []string{}
+		for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+			lines = append(lines, strings.TrimRight(indent+"// "+strings.TrimSpace(line), " "))
+		}
+		return strings.Join(lines, "\n")
+	},
+	// jsdoc JSDoc形式のコメントを返却します
+	"jsdoc": func(indent string, text string) string {
+		lines := []string{indent + "/**"}
+		for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+			lines = append(lines, strings.TrimRight(indent+" * "+strings.Replace(strings.TrimSpace(line), "*/", "*\\/", -1), " "))
+		}
+		return strings.Join(append(lines, indent+" */"), "\n")
+	},
+}
+
+var goClientTemplate = template.Must(template.New("client.go").Funcs(funcs).Parse(`// Code generated by api-creator. DO NOT EDIT.
+
+// Package {{.Package}} api-creatorで作成したAPIのクライアントです
+package {{.Package}}
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// ErrorResponse APIのエラーレスポンス
+type ErrorResponse struct {
+	Error string ` + "`json:\"error\"`" + `
+}
+
+// Error APIがエラーのステータスを返却した場合のエラー
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Client APIのクライアント
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	// Header すべてのリクエストに付与するヘッダー
+	Header http.Header
+}
+
+// NewClient APIのベースURL(例："http://localhost:9000")からClientを作成します
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+		Header:     http.Header{},
+	}
+}
+
+// do リクエストを送信し、レスポンスをresultに読み込みます
+func (c *Client) do(ctx context.Context, method string, path string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, c.BaseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	for key, values := range c.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode >= http.StatusBadRequest {
+		apiErr := &Error{StatusCode: res.StatusCode}
+		var errorResponse ErrorResponse
+		if json.Unmarshal(b, &errorResponse) == nil {
+			apiErr.Message = errorResponse.Error
+		}
+		return apiErr
+	}
+	if result == nil || len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, result)
+}
+`))
+
+var goAPITemplate = template.Must(template.New("api.go").Funcs(funcs).Parse(`// Code generated by api-creator. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- if .Operations}}
+	"context"
+{{- end}}
+{{- if .HasParams}}
+	"fmt"
+	"net/url"
+{{- end}}
+)
+
+{{if .Name}}{{comment "" (print .Name "\n" .Description)}}
+{{end}}
+{{- range .Types}}
+{{comment "" (print .Name " " .Description)}}
+type {{.Name}} struct {
+{{- range .Fields}}
+{{- if .Description}}
+{{comment "\t" .Description}}
+{{- end}}
+	{{.GoName}} {{.GoType}} ` + "`" + `json:"{{.JSONName}}{{if not .Required}},omitempty{{end}}"` + "`" + `
+{{- end}}
+}
+{{end}}
+{{- range .Operations}}
+{{comment "" (print .GoName " " .Comment)}}
+func (c *Client) {{.GoName}}(ctx context.Context{{if .GoParams}}, {{.GoParams}}{{end}}) ({{if .Result}}{{if .List}}[]{{.Result}}{{else}}*{{.Result}}{{end}}, {{end}}error) {
+{{- if .Result}}
+	var result {{if .List}}[]{{end}}{{.Result}}
+	if err := c.do(ctx, "{{.HTTPMethod}}", {{.GoPath}}, {{if .Body}}body{{else}}nil{{end}}, &result); err != nil {
+		return nil, err
+	}
+	return {{if not .List}}&{{end}}result, nil
+{{- else}}
+	return c.do(ctx, "{{.HTTPMethod}}", {{.GoPath}}, {{if .Body}}body{{else}}nil{{end}}, nil)
+{{- end}}
+}
+{{end}}`))
+
+var tsTemplate = template.Must(template.New("module.ts").Funcs(funcs).Parse(`// Code generated by api-creator. DO NOT EDIT.
+
+{{jsdoc "" "APIのエラーレスポンス"}}
+export interface ErrorResponse {
+  error: string;
+}
+
+{{jsdoc "" "APIがエラーのステータスを返却した場合のエラー"}}
+export class ApiError extends Error {
+  readonly status: number;
+
+  constructor(status: number, message: string) {
+    super(message);
+    this.name = "ApiError";
+    this.status = status;
+  }
+}
+
+export interface ClientOptions {
+  {{- "\n"}}{{jsdoc "  " "すべてのリクエストに付与するヘッダー"}}
+  headers?: Record<string, string>;
+  {{- "\n"}}{{jsdoc "  " "リクエストに使用するfetch(未指定の場合はグローバルのfetch)"}}
+  fetch?: typeof fetch;
+}
+{{range .APIs}}{{range .Types}}
+{{jsdoc "" (print .Name " " .Description)}}
+export interface {{.Name}} {
+{{- range .Fields}}
+{{- if .Description}}
+{{jsdoc "  " .Description}}
+{{- end}}
+  {{.TSName}}{{if not .Required}}?{{end}}: {{.TSType}};
+{{- end}}
+}
+{{end}}{{end}}
+{{jsdoc "" "APIのクライアント"}}
+export class Client {
+  private readonly baseUrl: string;
+  private readonly options: ClientOptions;
+
+  {{- "\n\n"}}{{jsdoc "  " "APIのベースURL(例：\"http://localhost:9000\")からClientを作成します"}}
+  constructor(baseUrl: string, options: ClientOptions = {}) {
+    this.baseUrl = baseUrl.replace(/\/+$/, "");
+    this.options = options;
+  }
+{{range .APIs}}{{range .Operations}}
+{{jsdoc "  " .Comment}}
+  async {{.TSName}}({{.TSParams}}): Promise<{{if .Result}}{{.Result}}{{if .List}}[]{{end}}{{else}}void{{end}}> {
+    {{if .Result}}return this.request<{{.Result}}{{if .List}}[]{{end}}>{{else}}await this.request<void>{{end}}("{{.HTTPMethod}}", {{.TSPath}}{{if .Body}}, body{{end}});
+  }
+{{end}}{{end}}
+  private async request<T>(method: string, path: string, body?: unknown): Promise<T> {
+    const fetchFn = this.options.fetch ?? fetch;
+    const headers: Record<string, string> = { Accept: "application/json", ...this.options.headers };
+    if (body !== undefined) {
+      headers["Content-Type"] = "application/json";
+    }
+
+    const res = await fetchFn(this.baseUrl + path, {
+      method,
+      headers,
+      body: body === undefined ? undefined : JSON.stringify(body),
+    });
+    const text = await res.text();
+    if (!res.ok) {
+      let message = "";
+      try {
+        message = (JSON.parse(text) as ErrorResponse).error;
+      } catch {
+        message = text;
+      }
+      throw new ApiError(res.status, message);
+    }
+    return (text ? JSON.parse(text) : undefined) as T;
+  }
+}
+`))
@@ -0,0 +1,98 @@
+package yamljson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ToJSON YAMLをJSONに変換します
+// jsonタグを持つ構造体へ、YAMLをJSONと同じ項目名で読み込むために使用します
+func ToJSON(data []byte) ([]byte, error) {
+	var value interface{}
+	if err := yaml.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	value, err := toJSONValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// FromJSON JSONをYAMLに変換します(オブジェクトの項目は名前順になります)
+func FromJSON(data []byte) ([]byte, error) {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal YAMLを、jsonタグに従ってvに読み込みます
+func Unmarshal(data []byte, v interface{}) error {
+	b, err := ToJSON(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// Marshal vをjsonタグに従ってYAMLに変換します
+func Marshal(v interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return FromJSON(b)
+}
+
+// toJSONValue 文字列以外のキーを持つマップを、JSONに変換できるマップに変換します
+func toJSONValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			converted, err := toJSONValue(item)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = converted
+		}
+		return v, nil
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted, err := toJSONValue(item)
+			if err != nil {
+				return nil, err
+			}
+			switch key.(type) {
+			case map[string]interface{}, map[interface{}]interface{}, []interface{}:
+				return nil, fmt.Errorf("unsupported key %v", key)
+			}
+			m[fmt.Sprint(key)] = converted
+		}
+		return m, nil
+	case []interface{}:
+		for i, item := range v {
+			converted, err := toJSONValue(item)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = converted
+		}
+		return v, nil
+	}
+	return value, nil
+}
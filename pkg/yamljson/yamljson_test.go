@@ -0,0 +1,42 @@
+package yamljson_test
+
+import (
+	"testing"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/yamljson"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type item struct {
+	ID       string   `json:"id"`
+	IsArray  bool     `json:"isArray"`
+	Count    int      `json:"count"`
+	Tags     []string `json:"tags"`
+	Optional string   `json:"optional,omitempty"`
+}
+
+func TestToJSON(t *testing.T) {
+	t.Run("正常系", func(t *testing.T) {
+		b, err := yamljson.ToJSON([]byte("name: users\nitems:\n  - 1: one\n    nested: {a: true}\n"))
+
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"name": "users", "items": [{"1": "one", "nested": {"a": true}}]}`, string(b))
+	})
+	t.Run("不正なYAML", func(t *testing.T) {
+		_, err := yamljson.ToJSON([]byte("name: [users"))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestMarshal(t *testing.T) {
+	b, err := yamljson.Marshal(item{ID: "id", IsArray: true, Count: 2, Tags: []string{"a"}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "count: 2\nid: id\nisArray: true\ntags:\n  - a\n", string(b))
+
+	var result item
+	assert.NoError(t, yamljson.Unmarshal(b, &result))
+	assert.Equal(t, item{ID: "id", IsArray: true, Count: 2, Tags: []string{"a"}}, result)
+}
@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/Hajime3778/api-creator-backend/pkg/domain"
@@ -26,23 +27,35 @@ func (_m *APIUsecase) GetByID(id string) (domain.API, error) {
 }
 
 // Create is mock function
-func (_m *APIUsecase) Create(api domain.API) (int, string, error) {
+func (_m *APIUsecase) Create(ctx context.Context, api domain.API) (int, string, error) {
 	ret := _m.Called(api)
 	return http.StatusCreated, api.ID, ret.Error(0)
 }
 
 // Update is mock function
-func (_m *APIUsecase) Update(api domain.API) (int, error) {
+func (_m *APIUsecase) Update(ctx context.Context, api domain.API) (int, error) {
 	ret := _m.Called(api)
 	return http.StatusOK, ret.Error(0)
 }
 
+// ChangeURL is mock function
+func (_m *APIUsecase) ChangeURL(ctx context.Context, id string, request domain.ChangeURLRequest) (int, error) {
+	ret := _m.Called(id, request)
+	return http.StatusOK, ret.Error(0)
+}
+
 // Delete is mock function
-func (_m *APIUsecase) Delete(id string) (int, error) {
+func (_m *APIUsecase) Delete(ctx context.Context, id string) (int, error) {
 	ret := _m.Called(id)
 	return http.StatusNoContent, ret.Error(0)
 }
 
+// GetProto is mock function
+func (_m *APIUsecase) GetProto(id string) (domain.GeneratedFile, int, error) {
+	ret := _m.Called(id)
+	return ret.Get(0).(domain.GeneratedFile), ret.Int(1), ret.Error(2)
+}
+
 // APIRepository is mock
 type APIRepository struct {
 	mock.Mock
@@ -66,6 +79,12 @@ func (_m *APIRepository) GetByURL(url string) (domain.API, error) {
 	return ret.Get(0).(domain.API), ret.Error(1)
 }
 
+// GetAliasByURL is mock function
+func (_m *APIRepository) GetAliasByURL(url string) (domain.APIAlias, error) {
+	ret := _m.Called(url)
+	return ret.Get(0).(domain.APIAlias), ret.Error(1)
+}
+
 // Create is mock function
 func (_m *APIRepository) Create(api domain.API) (string, error) {
 	ret := _m.Called(api)
@@ -78,8 +97,26 @@ func (_m *APIRepository) Update(api domain.API) error {
 	return ret.Error(0)
 }
 
+// ChangeURL is mock function
+func (_m *APIRepository) ChangeURL(api domain.API, alias *domain.APIAlias) error {
+	ret := _m.Called(api, alias)
+	return ret.Error(0)
+}
+
 // Delete is mock function
 func (_m *APIRepository) Delete(id string, methods []domain.Method, model domain.Model) error {
 	ret := _m.Called(id)
 	return ret.Error(0)
 }
+
+// Import is mock function
+func (_m *APIRepository) Import(plan domain.ImportPlan) error {
+	ret := _m.Called(plan)
+	return ret.Error(0)
+}
+
+// ImportAll is mock function
+func (_m *APIRepository) ImportAll(plans []domain.ImportPlan) error {
+	ret := _m.Called(plans)
+	return ret.Error(0)
+}
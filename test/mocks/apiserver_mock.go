@@ -0,0 +1,154 @@
+package mocks
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// APIServerRepository is mock
+type APIServerRepository struct {
+	mock.Mock
+}
+
+// Get is mock function
+func (_m *APIServerRepository) Get(modelName string, key string, param interface{}) (interface{}, int, error) {
+	ret := _m.Called(modelName, key, param)
+	return ret.Get(0), ret.Int(1), ret.Error(2)
+}
+
+// GetList is mock function
+func (_m *APIServerRepository) GetList(modelName string, key string, param interface{}) (interface{}, int, error) {
+	ret := _m.Called(modelName, key, param)
+	return ret.Get(0), ret.Int(1), ret.Error(2)
+}
+
+// Count is mock function
+func (_m *APIServerRepository) Count(modelName string, filter map[string]interface{}) (int64, int, error) {
+	ret := _m.Called(modelName, filter)
+	return ret.Get(0).(int64), ret.Int(1), ret.Error(2)
+}
+
+// Each is mock function
+// 1番目の返却値のドキュメント([]bson.M)を順にfnに渡します
+func (_m *APIServerRepository) Each(modelName string, filter map[string]interface{}, fn func(document bson.M) error) (int, error) {
+	ret := _m.Called(modelName, filter)
+	documents, _ := ret.Get(0).([]bson.M)
+	for _, document := range documents {
+		if err := fn(document); err != nil {
+			return http.StatusInternalServerError, err
+		}
+	}
+	return ret.Int(1), ret.Error(2)
+}
+
+// Create is mock function
+func (_m *APIServerRepository) Create(modelName string, key string, body []byte) (interface{}, int, error) {
+	ret := _m.Called(modelName, key, body)
+	return ret.Get(0), ret.Int(1), ret.Error(2)
+}
+
+// InsertMany is mock function
+func (_m *APIServerRepository) InsertMany(modelName string, keyName string, documents []bson.M) (int, error) {
+	ret := _m.Called(modelName, keyName, documents)
+	return ret.Int(0), ret.Error(1)
+}
+
+// Update is mock function
+func (_m *APIServerRepository) Update(modelName string, key string, body []byte) (interface{}, int, error) {
+	ret := _m.Called(modelName, key, body)
+	return ret.Get(0), ret.Int(1), ret.Error(2)
+}
+
+// Delete is mock function
+func (_m *APIServerRepository) Delete(modelName string, key string, param interface{}) (interface{}, int, error) {
+	ret := _m.Called(modelName, key, param)
+	return ret.Get(0), ret.Int(1), ret.Error(2)
+}
+
+// RemoveCollection is mock function
+func (_m *APIServerRepository) RemoveCollection(modelName string) (interface{}, int, error) {
+	ret := _m.Called(modelName)
+	return ret.Get(0), ret.Int(1), ret.Error(2)
+}
+
+// RenameCollection is mock function
+func (_m *APIServerRepository) RenameCollection(modelName string, newModelName string) (interface{}, int, error) {
+	ret := _m.Called(modelName, newModelName)
+	return ret.Get(0), ret.Int(1), ret.Error(2)
+}
+
+// ReplaceCollection is mock function
+func (_m *APIServerRepository) ReplaceCollection(modelName string, newModelName string) (int, error) {
+	ret := _m.Called(modelName, newModelName)
+	return ret.Int(0), ret.Error(1)
+}
+
+// Watch is mock function
+func (_m *APIServerRepository) Watch(ctx context.Context, modelName string, keyName string, resumeToken string) (<-chan domain.Event, error) {
+	ret := _m.Called(ctx, modelName, keyName, resumeToken)
+	events, _ := ret.Get(0).(chan domain.Event)
+	return events, ret.Error(1)
+}
+
+// APIServerUsecase is mock
+type APIServerUsecase struct {
+	mock.Mock
+}
+
+// RequestDocumentServer is mock function
+func (_m *APIServerUsecase) RequestDocumentServer(ctx context.Context, httpMethod string, url string, body []byte) (interface{}, int, error) {
+	ret := _m.Called(httpMethod, url, body)
+	return ret.Get(0), ret.Int(1), ret.Error(2)
+}
+
+// Subscribe is mock function
+func (_m *APIServerUsecase) Subscribe(ctx context.Context, url string, lastEventID string, filter domain.EventFilter) (<-chan domain.Event, int, error) {
+	ret := _m.Called(url, lastEventID, filter)
+	events, _ := ret.Get(0).(chan domain.Event)
+	return events, ret.Int(1), ret.Error(2)
+}
+
+// ResolveMethod is mock function
+func (_m *APIServerUsecase) ResolveMethod(httpMethod string, url string) (domain.API, domain.Method, error) {
+	ret := _m.Called(httpMethod, url)
+	return ret.Get(0).(domain.API), ret.Get(1).(domain.Method), ret.Error(2)
+}
+
+// GraphQLUsecase is mock
+type GraphQLUsecase struct {
+	mock.Mock
+}
+
+// Execute is mock function
+func (_m *GraphQLUsecase) Execute(ctx context.Context, request domain.GraphQLRequest) (interface{}, int, error) {
+	ret := _m.Called(request)
+	return ret.Get(0), ret.Int(1), ret.Error(2)
+}
+
+// GraphQLWriter is mock
+type GraphQLWriter struct {
+	mock.Mock
+}
+
+// Write is mock function
+func (_m *GraphQLWriter) Write(ctx context.Context, model domain.Model, httpMethod string, key interface{}, body []byte) (interface{}, int, error) {
+	ret := _m.Called(model.ID, httpMethod, key, body)
+	return ret.Get(0), ret.Int(1), ret.Error(2)
+}
+
+// RPCUsecase is mock
+type RPCUsecase struct {
+	mock.Mock
+}
+
+// Invoke is mock function
+func (_m *RPCUsecase) Invoke(ctx context.Context, procedure string, codec string, body []byte) ([]byte, int, error) {
+	ret := _m.Called(procedure, codec, body)
+	response, _ := ret.Get(0).([]byte)
+	return response, ret.Int(1), ret.Error(2)
+}
@@ -0,0 +1,56 @@
+package mocks
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// AuditUsecase is mock
+type AuditUsecase struct {
+	mock.Mock
+}
+
+// GetByID is mock function
+func (_m *AuditUsecase) GetByID(id string) (domain.AuditLog, error) {
+	ret := _m.Called(id)
+	return ret.Get(0).(domain.AuditLog), ret.Error(1)
+}
+
+// Search is mock function
+func (_m *AuditUsecase) Search(query domain.AuditQuery) ([]domain.AuditLog, int, error) {
+	ret := _m.Called(query)
+	return ret.Get(0).([]domain.AuditLog), http.StatusOK, ret.Error(1)
+}
+
+// AuditRepository is mock
+type AuditRepository struct {
+	mock.Mock
+}
+
+// GetByID is mock function
+func (_m *AuditRepository) GetByID(id string) (domain.AuditLog, error) {
+	ret := _m.Called(id)
+	return ret.Get(0).(domain.AuditLog), ret.Error(1)
+}
+
+// Search is mock function
+func (_m *AuditRepository) Search(query domain.AuditQuery) ([]domain.AuditLog, error) {
+	ret := _m.Called(query)
+	return ret.Get(0).([]domain.AuditLog), ret.Error(1)
+}
+
+// Create is mock function
+func (_m *AuditRepository) Create(log domain.AuditLog) error {
+	ret := _m.Called(log)
+	return ret.Error(0)
+}
+
+// DeleteBefore is mock function
+func (_m *AuditRepository) DeleteBefore(before time.Time) (int64, error) {
+	ret := _m.Called(before)
+	return ret.Get(0).(int64), ret.Error(1)
+}
@@ -0,0 +1,71 @@
+package mocks
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// BackupUsecase is mock
+type BackupUsecase struct {
+	mock.Mock
+}
+
+// Create is mock function
+func (_m *BackupUsecase) Create(request domain.BackupRequest) (domain.BackupInfo, int, error) {
+	ret := _m.Called(request)
+	return ret.Get(0).(domain.BackupInfo), ret.Int(1), ret.Error(2)
+}
+
+// GetAll is mock function
+func (_m *BackupUsecase) GetAll() ([]domain.BackupInfo, int, error) {
+	ret := _m.Called()
+	return ret.Get(0).([]domain.BackupInfo), ret.Int(1), ret.Error(2)
+}
+
+// Get is mock function
+func (_m *BackupUsecase) Get(name string) (domain.BackupInfo, int, error) {
+	ret := _m.Called(name)
+	return ret.Get(0).(domain.BackupInfo), ret.Int(1), ret.Error(2)
+}
+
+// Open is mock function
+// 1番目の返却値の文字列をファイルの内容とします
+func (_m *BackupUsecase) Open(name string) (io.ReadCloser, int, error) {
+	ret := _m.Called(name)
+	var file io.ReadCloser
+	if data := ret.String(0); data != "" {
+		file = ioutil.NopCloser(strings.NewReader(data))
+	}
+	return file, ret.Int(1), ret.Error(2)
+}
+
+// Save is mock function
+// 受け取った内容は文字列としてCalledに渡します
+func (_m *BackupUsecase) Save(name string, r io.Reader) (domain.BackupInfo, int, error) {
+	b, _ := ioutil.ReadAll(r)
+	ret := _m.Called(name, string(b))
+	return ret.Get(0).(domain.BackupInfo), ret.Int(1), ret.Error(2)
+}
+
+// Delete is mock function
+func (_m *BackupUsecase) Delete(name string) (int, error) {
+	ret := _m.Called(name)
+	return ret.Int(0), ret.Error(1)
+}
+
+// Restore is mock function
+func (_m *BackupUsecase) Restore(name string, request domain.RestoreRequest) (domain.RestoreResult, int, error) {
+	ret := _m.Called(name, request)
+	return ret.Get(0).(domain.RestoreResult), ret.Int(1), ret.Error(2)
+}
+
+// RestoreFile is mock function
+func (_m *BackupUsecase) RestoreFile(path string, request domain.RestoreRequest) (domain.RestoreResult, int, error) {
+	ret := _m.Called(path, request)
+	return ret.Get(0).(domain.RestoreResult), ret.Int(1), ret.Error(2)
+}
@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// BundleUsecase is mock
+type BundleUsecase struct {
+	mock.Mock
+}
+
+// Export is mock function
+func (_m *BundleUsecase) Export(id string, withDocuments bool) (domain.Bundle, int, error) {
+	ret := _m.Called(id, withDocuments)
+	return ret.Get(0).(domain.Bundle), ret.Int(1), ret.Error(2)
+}
+
+// Import is mock function
+func (_m *BundleUsecase) Import(bundle domain.Bundle, request domain.ImportRequest) (domain.ImportResult, int, error) {
+	ret := _m.Called(bundle, request)
+	return ret.Get(0).(domain.ImportResult), ret.Int(1), ret.Error(2)
+}
+
+// Sync is mock function
+func (_m *BundleUsecase) Sync(bundles []domain.Bundle, request domain.SyncRequest) ([]domain.ImportResult, int, error) {
+	ret := _m.Called(bundles, request)
+	return ret.Get(0).([]domain.ImportResult), ret.Int(1), ret.Error(2)
+}
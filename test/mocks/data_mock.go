@@ -0,0 +1,63 @@
+package mocks
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// DataUsecase is mock
+type DataUsecase struct {
+	mock.Mock
+}
+
+// Import is mock function
+// 受け取ったボディは文字列としてCalledに渡します
+func (_m *DataUsecase) Import(modelID string, request domain.DataImportRequest, body io.Reader, size int64) (domain.DataJob, int, error) {
+	b, _ := ioutil.ReadAll(body)
+	ret := _m.Called(modelID, request, string(b))
+	return ret.Get(0).(domain.DataJob), ret.Int(1), ret.Error(2)
+}
+
+// Export is mock function
+// 2番目の返却値の文字列をwに書き込みます
+func (_m *DataUsecase) Export(modelID string, request domain.DataExportRequest, w io.Writer) (domain.DataJob, int, error) {
+	ret := _m.Called(modelID, request)
+	if data := ret.String(1); data != "" {
+		io.WriteString(w, data)
+	}
+	return ret.Get(0).(domain.DataJob), ret.Int(2), ret.Error(3)
+}
+
+// StartExport is mock function
+func (_m *DataUsecase) StartExport(modelID string, request domain.DataExportRequest) (domain.DataJob, int, error) {
+	ret := _m.Called(modelID, request)
+	return ret.Get(0).(domain.DataJob), ret.Int(1), ret.Error(2)
+}
+
+// GetJobs is mock function
+func (_m *DataUsecase) GetJobs() []domain.DataJob {
+	ret := _m.Called()
+	return ret.Get(0).([]domain.DataJob)
+}
+
+// GetJob is mock function
+func (_m *DataUsecase) GetJob(id string) (domain.DataJob, int, error) {
+	ret := _m.Called(id)
+	return ret.Get(0).(domain.DataJob), ret.Int(1), ret.Error(2)
+}
+
+// OpenJobFile is mock function
+// 1番目の返却値の文字列をファイルの内容とします
+func (_m *DataUsecase) OpenJobFile(id string) (io.ReadCloser, string, domain.DataJob, int, error) {
+	ret := _m.Called(id)
+	var file io.ReadCloser
+	if data := ret.String(0); data != "" {
+		file = ioutil.NopCloser(strings.NewReader(data))
+	}
+	return file, ret.String(1), ret.Get(2).(domain.DataJob), ret.Int(3), ret.Error(4)
+}
@@ -0,0 +1,109 @@
+package mocks
+
+import (
+	"net/http"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// FixtureUsecase is mock
+type FixtureUsecase struct {
+	mock.Mock
+}
+
+// GetAll is mock function
+func (_m *FixtureUsecase) GetAll() ([]domain.Fixture, error) {
+	ret := _m.Called()
+	return ret.Get(0).([]domain.Fixture), ret.Error(1)
+}
+
+// GetByID is mock function
+func (_m *FixtureUsecase) GetByID(id string) (domain.Fixture, error) {
+	ret := _m.Called(id)
+	return ret.Get(0).(domain.Fixture), ret.Error(1)
+}
+
+// GetListByModelID is mock function
+func (_m *FixtureUsecase) GetListByModelID(modelID string) ([]domain.Fixture, error) {
+	ret := _m.Called(modelID)
+	return ret.Get(0).([]domain.Fixture), ret.Error(1)
+}
+
+// Create is mock function
+func (_m *FixtureUsecase) Create(fixture domain.Fixture) (int, string, error) {
+	ret := _m.Called(fixture)
+	return http.StatusCreated, fixture.ID, ret.Error(0)
+}
+
+// Update is mock function
+func (_m *FixtureUsecase) Update(fixture domain.Fixture) (int, error) {
+	ret := _m.Called(fixture)
+	return http.StatusOK, ret.Error(0)
+}
+
+// Delete is mock function
+func (_m *FixtureUsecase) Delete(id string) error {
+	ret := _m.Called(id)
+	return ret.Error(0)
+}
+
+// Load is mock function
+func (_m *FixtureUsecase) Load(id string) (int, domain.FixtureLoadResult, error) {
+	ret := _m.Called(id)
+	return http.StatusOK, ret.Get(0).(domain.FixtureLoadResult), ret.Error(1)
+}
+
+// Reset is mock function
+func (_m *FixtureUsecase) Reset(id string) (int, domain.FixtureLoadResult, error) {
+	ret := _m.Called(id)
+	return http.StatusOK, ret.Get(0).(domain.FixtureLoadResult), ret.Error(1)
+}
+
+// Snapshot is mock function
+func (_m *FixtureUsecase) Snapshot(modelID string, request domain.SnapshotRequest) (int, string, error) {
+	ret := _m.Called(modelID, request)
+	return http.StatusCreated, ret.String(0), ret.Error(1)
+}
+
+// FixtureRepository is mock
+type FixtureRepository struct {
+	mock.Mock
+}
+
+// GetAll is mock function
+func (_m *FixtureRepository) GetAll() ([]domain.Fixture, error) {
+	ret := _m.Called()
+	return ret.Get(0).([]domain.Fixture), ret.Error(1)
+}
+
+// GetByID is mock function
+func (_m *FixtureRepository) GetByID(id string) (domain.Fixture, error) {
+	ret := _m.Called(id)
+	return ret.Get(0).(domain.Fixture), ret.Error(1)
+}
+
+// GetListByModelID is mock function
+func (_m *FixtureRepository) GetListByModelID(modelID string) ([]domain.Fixture, error) {
+	ret := _m.Called(modelID)
+	return ret.Get(0).([]domain.Fixture), ret.Error(1)
+}
+
+// Create is mock function
+func (_m *FixtureRepository) Create(fixture domain.Fixture) (string, error) {
+	ret := _m.Called(fixture)
+	return fixture.ID, ret.Error(0)
+}
+
+// Update is mock function
+func (_m *FixtureRepository) Update(fixture domain.Fixture) error {
+	ret := _m.Called(fixture)
+	return ret.Error(0)
+}
+
+// Delete is mock function
+func (_m *FixtureRepository) Delete(id string) error {
+	ret := _m.Called(id)
+	return ret.Error(0)
+}
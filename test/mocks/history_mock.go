@@ -0,0 +1,74 @@
+package mocks
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// HistoryUsecase is mock
+type HistoryUsecase struct {
+	mock.Mock
+}
+
+// GetByID is mock function
+func (_m *HistoryUsecase) GetByID(id string) (domain.ChangeEvent, error) {
+	ret := _m.Called(id)
+	return ret.Get(0).(domain.ChangeEvent), ret.Error(1)
+}
+
+// GetListByEntity is mock function
+func (_m *HistoryUsecase) GetListByEntity(entityType string, entityID string) ([]domain.ChangeEvent, error) {
+	ret := _m.Called(entityType, entityID)
+	return ret.Get(0).([]domain.ChangeEvent), ret.Error(1)
+}
+
+// GetListByAPIID is mock function
+func (_m *HistoryUsecase) GetListByAPIID(apiID string) ([]domain.ChangeEvent, error) {
+	ret := _m.Called(apiID)
+	return ret.Get(0).([]domain.ChangeEvent), ret.Error(1)
+}
+
+// Revert is mock function
+func (_m *HistoryUsecase) Revert(ctx context.Context, entityType string, entityID string, version int) (int, error) {
+	ret := _m.Called(entityType, entityID, version)
+	return http.StatusOK, ret.Error(0)
+}
+
+// HistoryRepository is mock
+type HistoryRepository struct {
+	mock.Mock
+}
+
+// GetByID is mock function
+func (_m *HistoryRepository) GetByID(id string) (domain.ChangeEvent, error) {
+	ret := _m.Called(id)
+	return ret.Get(0).(domain.ChangeEvent), ret.Error(1)
+}
+
+// GetListByEntity is mock function
+func (_m *HistoryRepository) GetListByEntity(entityType string, entityID string) ([]domain.ChangeEvent, error) {
+	ret := _m.Called(entityType, entityID)
+	return ret.Get(0).([]domain.ChangeEvent), ret.Error(1)
+}
+
+// GetListByAPIID is mock function
+func (_m *HistoryRepository) GetListByAPIID(apiID string) ([]domain.ChangeEvent, error) {
+	ret := _m.Called(apiID)
+	return ret.Get(0).([]domain.ChangeEvent), ret.Error(1)
+}
+
+// GetVersion is mock function
+func (_m *HistoryRepository) GetVersion(entityType string, entityID string, version int) (domain.ChangeEvent, error) {
+	ret := _m.Called(entityType, entityID, version)
+	return ret.Get(0).(domain.ChangeEvent), ret.Error(1)
+}
+
+// Create is mock function
+func (_m *HistoryRepository) Create(event domain.ChangeEvent) (domain.ChangeEvent, error) {
+	ret := _m.Called(event)
+	return event, ret.Error(0)
+}
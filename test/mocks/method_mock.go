@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/Hajime3778/api-creator-backend/pkg/domain"
@@ -32,25 +33,31 @@ func (_m *MethodUsecase) GetListByAPIID(apiID string) ([]domain.Method, error) {
 }
 
 // Create is mock function
-func (_m *MethodUsecase) Create(method domain.Method) (int, string, error) {
+func (_m *MethodUsecase) Create(ctx context.Context, method domain.Method) (int, string, error) {
 	ret := _m.Called(method)
 	return http.StatusCreated, method.ID, ret.Error(0)
 }
 
 // CreateDefaultMethods is mock function
-func (_m *MethodUsecase) CreateDefaultMethods(apiID string) (int, []domain.Method, error) {
+func (_m *MethodUsecase) CreateDefaultMethods(ctx context.Context, apiID string) (int, []domain.Method, error) {
 	ret := _m.Called(apiID)
 	return http.StatusCreated, ret.Get(0).([]domain.Method), ret.Error(1)
 }
 
 // Update is mock function
-func (_m *MethodUsecase) Update(method domain.Method) (int, error) {
+func (_m *MethodUsecase) Update(ctx context.Context, method domain.Method) (int, error) {
 	ret := _m.Called(method)
 	return http.StatusOK, ret.Error(0)
 }
 
+// ChangeMode is mock function
+func (_m *MethodUsecase) ChangeMode(ctx context.Context, id string, request domain.ChangeModeRequest) (int, error) {
+	ret := _m.Called(id, request)
+	return http.StatusOK, ret.Error(0)
+}
+
 // Delete is mock function
-func (_m *MethodUsecase) Delete(id string) error {
+func (_m *MethodUsecase) Delete(ctx context.Context, id string) error {
 	ret := _m.Called(id)
 	return ret.Error(0)
 }
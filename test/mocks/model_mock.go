@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/Hajime3778/api-creator-backend/pkg/domain"
@@ -32,19 +33,37 @@ func (_m *ModelUsecase) GetByAPIID(id string) (domain.Model, error) {
 }
 
 // Create is mock function
-func (_m *ModelUsecase) Create(model domain.Model) (int, string, error) {
+func (_m *ModelUsecase) Create(ctx context.Context, model domain.Model) (int, string, error) {
 	ret := _m.Called(model)
 	return http.StatusCreated, model.ID, ret.Error(0)
 }
 
+// Restore is mock function
+func (_m *ModelUsecase) Restore(ctx context.Context, model domain.Model) (int, error) {
+	ret := _m.Called(model)
+	return http.StatusCreated, ret.Error(0)
+}
+
 // Update is mock function
-func (_m *ModelUsecase) Update(model domain.Model) (int, error) {
+func (_m *ModelUsecase) Update(ctx context.Context, model domain.Model) (int, error) {
 	ret := _m.Called(model)
 	return http.StatusOK, ret.Error(0)
 }
 
+// Rename is mock function
+func (_m *ModelUsecase) Rename(ctx context.Context, id string, request domain.RenameModelRequest) (int, error) {
+	ret := _m.Called(id, request)
+	return http.StatusOK, ret.Error(0)
+}
+
+// Generate is mock function
+func (_m *ModelUsecase) Generate(id string, request domain.GenerateRequest) (int, domain.GenerateResponse, error) {
+	ret := _m.Called(id, request)
+	return http.StatusOK, ret.Get(0).(domain.GenerateResponse), ret.Error(1)
+}
+
 // Delete is mock function
-func (_m *ModelUsecase) Delete(id string) error {
+func (_m *ModelUsecase) Delete(ctx context.Context, id string) error {
 	ret := _m.Called(id)
 	return ret.Error(0)
 }
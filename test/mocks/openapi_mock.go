@@ -0,0 +1,26 @@
+package mocks
+
+import (
+	"github.com/Hajime3778/api-creator-backend/pkg/openapi"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// OpenAPIUsecase is mock
+type OpenAPIUsecase struct {
+	mock.Mock
+}
+
+// GetAll is mock function
+func (_m *OpenAPIUsecase) GetAll() (*openapi.Document, int, error) {
+	ret := _m.Called()
+	doc, _ := ret.Get(0).(*openapi.Document)
+	return doc, ret.Int(1), ret.Error(2)
+}
+
+// GetByAPIID is mock function
+func (_m *OpenAPIUsecase) GetByAPIID(id string) (*openapi.Document, int, error) {
+	ret := _m.Called(id)
+	doc, _ := ret.Get(0).(*openapi.Document)
+	return doc, ret.Int(1), ret.Error(2)
+}
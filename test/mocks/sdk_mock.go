@@ -0,0 +1,24 @@
+package mocks
+
+import (
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// SDKUsecase is mock
+type SDKUsecase struct {
+	mock.Mock
+}
+
+// GetByAPIID is mock function
+func (_m *SDKUsecase) GetByAPIID(id string, language string, packageName string) (domain.GeneratedFile, int, error) {
+	ret := _m.Called(id, language, packageName)
+	return ret.Get(0).(domain.GeneratedFile), ret.Int(1), ret.Error(2)
+}
+
+// GetAll is mock function
+func (_m *SDKUsecase) GetAll(language string, packageName string) (domain.GeneratedFile, int, error) {
+	ret := _m.Called(language, packageName)
+	return ret.Get(0).(domain.GeneratedFile), ret.Int(1), ret.Error(2)
+}
@@ -0,0 +1,152 @@
+package mocks
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Hajime3778/api-creator-backend/pkg/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// WebhookUsecase is mock
+type WebhookUsecase struct {
+	mock.Mock
+}
+
+// GetByID is mock function
+func (_m *WebhookUsecase) GetByID(id string) (domain.Webhook, error) {
+	ret := _m.Called(id)
+	return ret.Get(0).(domain.Webhook), ret.Error(1)
+}
+
+// GetListByAPIID is mock function
+func (_m *WebhookUsecase) GetListByAPIID(apiID string) ([]domain.Webhook, error) {
+	ret := _m.Called(apiID)
+	return ret.Get(0).([]domain.Webhook), ret.Error(1)
+}
+
+// Create is mock function
+func (_m *WebhookUsecase) Create(webhook domain.Webhook) (int, string, error) {
+	ret := _m.Called(webhook)
+	return http.StatusCreated, webhook.ID, ret.Error(0)
+}
+
+// Update is mock function
+func (_m *WebhookUsecase) Update(webhook domain.Webhook) (int, error) {
+	ret := _m.Called(webhook)
+	return http.StatusOK, ret.Error(0)
+}
+
+// Delete is mock function
+func (_m *WebhookUsecase) Delete(id string) error {
+	ret := _m.Called(id)
+	return ret.Error(0)
+}
+
+// GetDeliveries is mock function
+func (_m *WebhookUsecase) GetDeliveries(webhookID string) ([]domain.WebhookDelivery, error) {
+	ret := _m.Called(webhookID)
+	return ret.Get(0).([]domain.WebhookDelivery), ret.Error(1)
+}
+
+// GetDeadLetters is mock function
+func (_m *WebhookUsecase) GetDeadLetters() ([]domain.WebhookDelivery, error) {
+	ret := _m.Called()
+	return ret.Get(0).([]domain.WebhookDelivery), ret.Error(1)
+}
+
+// GetDeliveryLogs is mock function
+func (_m *WebhookUsecase) GetDeliveryLogs(deliveryID string) ([]domain.WebhookDeliveryLog, error) {
+	ret := _m.Called(deliveryID)
+	return ret.Get(0).([]domain.WebhookDeliveryLog), ret.Error(1)
+}
+
+// Retry is mock function
+func (_m *WebhookUsecase) Retry(deliveryID string) (int, error) {
+	ret := _m.Called(deliveryID)
+	return http.StatusOK, ret.Error(0)
+}
+
+// WebhookRepository is mock
+type WebhookRepository struct {
+	mock.Mock
+}
+
+// GetByID is mock function
+func (_m *WebhookRepository) GetByID(id string) (domain.Webhook, error) {
+	ret := _m.Called(id)
+	return ret.Get(0).(domain.Webhook), ret.Error(1)
+}
+
+// GetListByAPIID is mock function
+func (_m *WebhookRepository) GetListByAPIID(apiID string) ([]domain.Webhook, error) {
+	ret := _m.Called(apiID)
+	return ret.Get(0).([]domain.Webhook), ret.Error(1)
+}
+
+// Create is mock function
+func (_m *WebhookRepository) Create(webhook domain.Webhook) (string, error) {
+	ret := _m.Called(webhook)
+	return webhook.ID, ret.Error(0)
+}
+
+// Update is mock function
+func (_m *WebhookRepository) Update(webhook domain.Webhook) error {
+	ret := _m.Called(webhook)
+	return ret.Error(0)
+}
+
+// Delete is mock function
+func (_m *WebhookRepository) Delete(id string) error {
+	ret := _m.Called(id)
+	return ret.Error(0)
+}
+
+// GetDeliveryByID is mock function
+func (_m *WebhookRepository) GetDeliveryByID(id string) (domain.WebhookDelivery, error) {
+	ret := _m.Called(id)
+	return ret.Get(0).(domain.WebhookDelivery), ret.Error(1)
+}
+
+// GetDeliveriesByWebhookID is mock function
+func (_m *WebhookRepository) GetDeliveriesByWebhookID(webhookID string) ([]domain.WebhookDelivery, error) {
+	ret := _m.Called(webhookID)
+	return ret.Get(0).([]domain.WebhookDelivery), ret.Error(1)
+}
+
+// GetDeliveriesByStatus is mock function
+func (_m *WebhookRepository) GetDeliveriesByStatus(status string) ([]domain.WebhookDelivery, error) {
+	ret := _m.Called(status)
+	return ret.Get(0).([]domain.WebhookDelivery), ret.Error(1)
+}
+
+// GetPendingDeliveries is mock function
+func (_m *WebhookRepository) GetPendingDeliveries(now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	ret := _m.Called(now, limit)
+	return ret.Get(0).([]domain.WebhookDelivery), ret.Error(1)
+}
+
+// ClaimDelivery is mock function
+func (_m *WebhookRepository) ClaimDelivery(delivery domain.WebhookDelivery, until time.Time) (bool, error) {
+	ret := _m.Called(delivery, until)
+	return ret.Bool(0), ret.Error(1)
+}
+
+// CreateDeliveries is mock function
+func (_m *WebhookRepository) CreateDeliveries(deliveries []domain.WebhookDelivery) error {
+	ret := _m.Called(deliveries)
+	return ret.Error(0)
+}
+
+// UpdateDelivery is mock function
+func (_m *WebhookRepository) UpdateDelivery(delivery domain.WebhookDelivery, log domain.WebhookDeliveryLog) error {
+	ret := _m.Called(delivery, log)
+	return ret.Error(0)
+}
+
+// GetDeliveryLogs is mock function
+func (_m *WebhookRepository) GetDeliveryLogs(deliveryID string) ([]domain.WebhookDeliveryLog, error) {
+	ret := _m.Called(deliveryID)
+	return ret.Get(0).([]domain.WebhookDeliveryLog), ret.Error(1)
+}